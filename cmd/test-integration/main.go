@@ -58,30 +58,14 @@ func main() {
 			continue
 		}
 
-		// Capture item ID for delete function
-		itemID := item.ID
-
 		tuiItem := &tui.StackItem{
-			ID:       fmt.Sprintf("%d", item.ID),
+			ID:       item.ID,
 			Content:  contentReader,
 			Preview:  item.Title,
 			ViewPos:  0,
 			IsBinary: item.IsBinary,
 			Size:     item.Size,
 			SHA256:   item.SHA256,
-			DeleteFunc: func() error {
-				// Find index and delete
-				allItems, err := qm.List()
-				if err != nil {
-					return err
-				}
-				for idx, itm := range allItems {
-					if itm.ID == itemID {
-						return qm.Delete(idx)
-					}
-				}
-				return fmt.Errorf("item not found")
-			},
 		}
 		tuiItems = append(tuiItems, tuiItem)
 	}
@@ -89,6 +73,7 @@ func main() {
 	// Create model with specific dimensions
 	clip := mockboard.New()
 	model := tui.NewModel(tuiItems, clip)
+	model.SetItemOps(qm.ByID())
 	model.UpdateMockSize(120, 20)
 
 	// Render the view
@@ -2,30 +2,150 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yiblet/rem/internal/filter"
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/store"
 )
 
 // Args represents the top-level command structure
 type Args struct {
-	Store  *StoreCmd  `arg:"subcommand:store" help:"Push content to the queue"`
-	Get    *GetCmd    `arg:"subcommand:get" help:"Access content from the queue"`
-	Config *ConfigCmd `arg:"subcommand:config" help:"Manage rem configuration"`
-	Clear  *ClearCmd  `arg:"subcommand:clear" help:"Clear all history from the queue"`
-	Search *SearchCmd `arg:"subcommand:search" help:"Search history for content matching a regex pattern"`
-	DBPath *string    `arg:"--db-path,env:REM_DB_PATH" help:"Custom database path (overrides default ~/.config/rem/rem.db)"`
+	Store        *StoreCmd       `arg:"subcommand:store" help:"Push content to the queue"`
+	Get          *GetCmd         `arg:"subcommand:get" help:"Access content from the queue"`
+	Config       *ConfigCmd      `arg:"subcommand:config" help:"Manage rem configuration"`
+	Clear        *ClearCmd       `arg:"subcommand:clear" help:"Clear all history from the queue"`
+	Search       *SearchCmd      `arg:"subcommand:search" help:"Search history for content matching a regex pattern"`
+	List         *ListCmd        `arg:"subcommand:list" help:"List items with their index, time, and title"`
+	Title        *TitleCmd       `arg:"subcommand:title" help:"Change the title of a stored item"`
+	Note         *NoteCmd        `arg:"subcommand:note" help:"Set or clear the note of a stored item"`
+	Sync         *SyncCmd        `arg:"subcommand:sync" help:"Copy items to or from another rem database"`
+	Backup       *BackupCmd      `arg:"subcommand:backup" help:"Create, list, and restore database backups"`
+	Stats        *StatsCmd       `arg:"subcommand:stats" help:"Show how much content is stored and the space it takes"`
+	Maintenance  *MaintenanceCmd `arg:"subcommand:maintenance" help:"Run database maintenance tasks"`
+	Info         *InfoCmd        `arg:"subcommand:info" help:"Show the metadata of a stored item"`
+	Bump         *BumpCmd        `arg:"subcommand:bump" help:"Move a stored item to the top of the queue"`
+	Version      *VersionCmd     `arg:"subcommand:version" help:"Show build information and a report on the database"`
+	Doctor       *DoctorCmd      `arg:"subcommand:doctor" help:"Check the database, config, clipboard, and terminal for problems"`
+	Edit         *EditCmd        `arg:"subcommand:edit" help:"Search and replace in stored content"`
+	Dedupe       *DedupeCmd      `arg:"subcommand:dedupe" help:"Delete back-to-back copies of the same content stored moments apart"`
+	Tail         *TailCmd        `arg:"subcommand:tail" help:"Print new items as they are stored, until interrupted"`
+	Meta         *MetaCmd        `arg:"subcommand:meta" help:"Set, show, and delete key-value metadata on stored items"`
+	Audit        *AuditCmd       `arg:"subcommand:audit" help:"Show when items were deleted, evicted, or cleared, and by what command"`
+	ShowVersion  bool            `arg:"--version" help:"Show build information and exit (same as 'rem version')"`
+	DBPath       *string         `arg:"--db-path,env:REM_DB_PATH" help:"Custom database path (overrides the default; see rem config get db_path), or :memory: for one that lasts only this command"`
+	UnsafeDBPath bool            `arg:"--unsafe-db-path" help:"Allow a relative --db-path that leads out of the current directory with .."`
+	ReadOnly     bool            `arg:"--read-only" help:"Open the database read-only (allows databases from newer rem versions)"`
+	UTC          bool            `arg:"--utc" help:"Print times in UTC instead of the timezone setting or the local time zone"`
+
+	ExpectGeneration *uint64 `arg:"--expect-generation" help:"Fail with exit code 4 unless the queue is still at this generation (from rem list --json), so indexes listed earlier still mean the same items"`
+
+	argv []string // the arguments parsed, recorded in the audit log
 }
 
 // StoreCmd represents the 'rem store' command (pushes to top of queue)
 type StoreCmd struct {
-	Files     []string `arg:"positional" help:"Files to read from (optional)"`
-	Clipboard bool     `arg:"-c,--clipboard" help:"Read from clipboard"`
-	Title     *string  `arg:"-t,--title" help:"Optional title for the stored item (max 80 chars)"`
+	Files      []string `arg:"positional" help:"Files to read from (optional)"`
+	Clipboard  bool     `arg:"-c,--clipboard" help:"Read from clipboard"`
+	Title      *string  `arg:"-t,--title" help:"Optional title for the stored item (max 80 columns; wide characters take two)"`
+	AllowEmpty bool     `arg:"--allow-empty" help:"Store empty stdin or clipboard content instead of failing"`
+	Settle     *string  `arg:"--settle" help:"With -c, wait until the clipboard has held the same content this long, and replace the newest item if it is a clipboard capture stored this recently that the content extends (default 300ms; 0 turns both off)"`
+	MinLength  int      `arg:"--min-length" help:"With -c, skip clipboard content shorter than this many bytes"`
+	Filters    []string `arg:"--filter,separate" help:"Transform content before storing (repeatable): strip-ansi, expand-tabs[=N], dos2unix, trim-trailing"`
+	Tee        bool     `arg:"--tee" help:"Also copy stdin to stdout unchanged, printing the confirmation to stderr"`
+	DryRun     bool     `arg:"--dry-run" help:"Read the input and report the title, size, SHA256, filters, and any repeat or eviction storing it would cause, without storing it"`
+	Format     *string  `arg:"--format" help:"Go template for the confirmation line, e.g. '{{.ID}}'; fields: .ID, .Index, .Title, .Size, .SHA256, .Timestamp"`
+	Quiet      bool     `arg:"-q,--quiet" help:"Print no confirmation"`
+
+	TitleFile          *string `arg:"--title-file" help:"Title the item with the first non-empty line of this file"`
+	TitleFromClipboard bool    `arg:"--title-from-clipboard" help:"Title the item with the clipboard's text, first non-empty line; the content comes from files or stdin"`
+	TitleLine          *int    `arg:"--title-line" help:"Generate the title from this line of the content, counting from 1, instead of by title_strategy (an empty or missing line falls back to the first)"`
+
+	TitleTemplate *string `arg:"--title-template" help:"Go template for the title when --title is not given; fields: .Now, .Hostname, .Source, .FirstLine"`
+	SectionMarker *string `arg:"--section-marker" help:"Regex for lines that start a section; sections are named by its first capture group, or numbered (see rem get --section)"`
+
+	Recursive      bool     `arg:"-r,--recursive" help:"Store every file under the given directories as its own item, titled with its relative path"`
+	Include        []string `arg:"--include,separate" help:"With --recursive, only store files matching this glob (repeatable; matched against the relative path and the file name)"`
+	Exclude        []string `arg:"--exclude,separate" help:"With --recursive, skip files and directories matching this glob (repeatable)"`
+	MaxFileSize    *string  `arg:"--max-file-size" help:"With --recursive, skip files larger than this size (default 10MB)"`
+	FollowSymlinks bool     `arg:"--follow-symlinks" help:"With --recursive, follow symbolic links"`
+}
+
+// ItemArg is a positional item argument: a queue index such as 3, or an
+// @reference such as @0k3f naming the item by ID (see internal/ref)
+type ItemArg struct {
+	Index int
+	Ref   *uint // the ID an @reference decodes to; nil for an index
+}
+
+// UnmarshalText parses an index or an @reference
+func (a *ItemArg) UnmarshalText(text []byte) error {
+	arg, err := parseItemArg(string(text))
+	if err != nil {
+		return err
+	}
+	*a = arg
+	return nil
+}
+
+// invalidIndexPrefix starts the error for an argument that is neither an
+// index nor an @reference, which explainParseError looks for
+const invalidIndexPrefix = "invalid index "
+
+// parseItemArg parses an index or an @reference
+func parseItemArg(s string) (ItemArg, error) {
+	if token, ok := strings.CutPrefix(s, "@"); ok {
+		id, err := ref.Decode(token)
+		if err != nil {
+			return ItemArg{}, err
+		}
+		return ItemArg{Ref: &id}, nil
+	}
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return ItemArg{}, fmt.Errorf("%s'%s'", invalidIndexPrefix, s)
+	}
+	return ItemArg{Index: index}, nil
+}
+
+// validate rejects a negative index
+func (a *ItemArg) validate() error {
+	if a != nil && a.Ref == nil && a.Index < 0 {
+		return fmt.Errorf("index must be non-negative")
+	}
+	return nil
 }
 
 // GetCmd represents the 'rem get' command (accesses queue by index)
 type GetCmd struct {
-	Index     *int    `arg:"positional" help:"Queue index to retrieve (0=top, optional, opens TUI if not provided)"`
-	File      *string `arg:"positional" help:"Output file (optional)"`
-	Clipboard bool    `arg:"-c,--clipboard" help:"Copy to clipboard"`
+	Index     *ItemArg `arg:"positional" help:"Queue index (0=top) or @reference to retrieve (optional, opens TUI if not provided)"`
+	File      *string  `arg:"positional" help:"Output file (optional; - for stdout)"`
+	Clipboard bool     `arg:"-c,--clipboard" help:"Copy to clipboard"`
+	Force     bool     `arg:"-f,--force" help:"With -c, overwrite the clipboard without asking (see clipboard_overwrite_confirm)"`
+	JSON      bool     `arg:"--json" help:"Print metadata and content as a JSON object"`
+	Verbose   bool     `arg:"-v,--verbose" help:"Print notes about the item (e.g. that it is empty) to stderr"`
+	MaxBytes  *int64   `arg:"--max-bytes" help:"With --json, omit content larger than this many bytes (default 1MB)"`
+
+	Match        *string `arg:"-m,--match" help:"Get the newest item whose title, note, or content matches this regex instead of an index"`
+	MatchTitle   bool    `arg:"--match-title" help:"With --match, match titles only"`
+	MatchContent bool    `arg:"--match-content" help:"With --match, match content only"`
+	Output       *string `arg:"-o,--output" help:"Output file, or - for stdout (use instead of the positional file with --match)"`
+
+	NoTUI bool `arg:"--no-tui,env:REM_NO_TUI" help:"Without an index, pick an item from a numbered list instead of the TUI (automatic when stdout is not a capable terminal but stdin is)"`
+
+	Restore bool `arg:"--restore" help:"Write a stored file back to its original name in the current directory, restoring its mode and modification time"`
+
+	Section *string `arg:"--section" help:"Only output this section (by name, or 1-based number) of an item stored with --section-marker"`
+
+	Open bool `arg:"--open" help:"Open an item whose content is a single http(s) URL in the default browser"`
+
+	Format *string `arg:"--format" help:"Go template for the confirmation printed with -c, a file, --restore, or --open; fields: .ID, .Index, .Title, .Size, .SHA256, .Timestamp"`
+	Quiet  bool    `arg:"-q,--quiet" help:"Print no confirmation with -c, a file, --restore, or --open"`
 }
 
 // ConfigCmd represents the 'rem config' command (manages configuration)
@@ -37,32 +157,325 @@ type ConfigCmd struct {
 
 // ConfigGetCmd represents the 'rem config get' command
 type ConfigGetCmd struct {
-	Key string `arg:"positional,required" help:"Configuration key to get (history_limit, show_binary, db_version)"`
+	Key string `arg:"positional,required" help:"Configuration key to get, such as history_limit, generation for --expect-generation, or db_path (an unknown key's error lists every key; hyphens also accepted)"`
 }
 
 // ConfigSetCmd represents the 'rem config set' command
 type ConfigSetCmd struct {
-	Key   string `arg:"positional,required" help:"Configuration key to set (history_limit, show_binary)"`
+	Key   string `arg:"positional,required" help:"Configuration key to set, such as history_limit (an unknown key's error lists every key; hyphens also accepted)"`
 	Value string `arg:"positional,required" help:"Configuration value to set"`
 }
 
 // ConfigListCmd represents the 'rem config list' command
 type ConfigListCmd struct {
+	Verbose bool `arg:"-v,--verbose" help:"Also show when each value was last changed"`
 }
 
 // ClearCmd represents the 'rem clear' command (clears all history)
 type ClearCmd struct {
-	Force bool `arg:"-f,--force" help:"Skip confirmation prompt"`
+	Force    bool `arg:"-f,--force" help:"Skip confirmation prompt"`
+	ResetIDs bool `arg:"--reset-ids" help:"Start item IDs, and so @references, from 1 again"`
 }
 
 // SearchCmd represents the 'rem search' command (searches history)
 type SearchCmd struct {
-	Pattern       string `arg:"positional,required" help:"Regex pattern to search for"`
-	IndexOnly     bool   `arg:"-i,--index-only" help:"Output only the index of the first match"`
-	AllMatches    bool   `arg:"-a,--all" help:"Show all matching items (not just the first)"`
-	SearchTitle   bool   `arg:"--title" help:"Search in titles only"`
-	SearchContent bool   `arg:"--content" help:"Search in content only"`
-	CaseSensitive bool   `arg:"-s,--case-sensitive" help:"Case-sensitive search"`
+	Pattern       string   `arg:"positional" help:"Regex pattern to search for (optional with --since, --until, or --meta)"`
+	IndexOnly     bool     `arg:"-i,--index-only" help:"Output only the index of the first match"`
+	AllMatches    bool     `arg:"-a,--all" help:"Show all matching items (not just the first)"`
+	SearchTitle   bool     `arg:"--title" help:"Search in titles only"`
+	SearchContent bool     `arg:"--content" help:"Search in content only"`
+	SearchNotes   bool     `arg:"--notes" help:"Search in notes only"`
+	CaseSensitive bool     `arg:"-s,--case-sensitive" help:"Case-sensitive search"`
+	ExactAccents  bool     `arg:"--exact-accents" help:"Match accents and compatibility characters exactly (by default 'uber' matches 'Über')"`
+	Order         string   `arg:"--order" help:"Result order: newest (default) or oldest"`
+	Count         bool     `arg:"--count" help:"Output index, matching line count, and title of each match"`
+	TitlesOnly    bool     `arg:"--titles-only" help:"Output index and title of each match without content"`
+	Since         *string  `arg:"--since" help:"Only match items stored at or after this time (2024-05-01, RFC3339, or an age like 24h, 7d)"`
+	Until         *string  `arg:"--until" help:"Only match items stored before this time (same formats as --since)"`
+	TUI           bool     `arg:"--tui" help:"Browse every match in the TUI, with the pattern highlighted, instead of printing"`
+	Meta          []string `arg:"--meta,separate" help:"Only match items whose metadata has key=value (repeatable; all must match)"`
+}
+
+// metaEquals returns the parsed --meta filters, or nil without any
+func (s *SearchCmd) metaEquals() (map[string]string, error) {
+	if len(s.Meta) == 0 {
+		return nil, nil
+	}
+	want := make(map[string]string, len(s.Meta))
+	for _, pair := range s.Meta {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --meta value '%s' (expected key=value)", pair)
+		}
+		if err := store.ValidateMeta(key, value); err != nil {
+			return nil, err
+		}
+		want[key] = value
+	}
+	return want, nil
+}
+
+// ListCmd represents the 'rem list' command (prints the queue)
+type ListCmd struct {
+	JSON  bool    `arg:"--json" help:"Print each item's metadata as a JSON object, one per line"`
+	Since *string `arg:"--since" help:"Only list items stored at or after this time (2024-05-01, RFC3339, or an age like 24h, 7d)"`
+	Until *string `arg:"--until" help:"Only list items stored before this time (same formats as --since)"`
+
+	Sort    *string `arg:"--sort" help:"Order by age (newest first, the default), size (largest first), or title (A to Z); indexes stay the queue's"`
+	Reverse bool    `arg:"--reverse" help:"Reverse the order"`
+	Size    bool    `arg:"--size" help:"Show each item's size (shown anyway with --sort size)"`
+	MinSize *string `arg:"--min-size" help:"Only list items at least this large (e.g. 1MB)"`
+	Preview bool    `arg:"--preview" help:"Show the first line of each item's content under its title"`
+}
+
+// TitleCmd represents the 'rem title' command (renames an item)
+type TitleCmd struct {
+	Args        []string `arg:"positional" help:"<index or @reference> <title>, or just <title> with --id"`
+	ID          *uint    `arg:"--id" help:"Select the item by ID instead of index"`
+	FromContent bool     `arg:"--from-content" help:"Regenerate the title from the item's content"`
+}
+
+// target returns the selected index or @reference (nil when --id is used)
+// and the new title. With --id every argument is the title, even one starting with @.
+func (t *TitleCmd) target() (*ItemArg, string, error) {
+	args := t.Args
+	var index *ItemArg
+	if t.ID == nil {
+		if len(args) == 0 {
+			return nil, "", fmt.Errorf("an index, @reference, or --id is required")
+		}
+		arg, err := parseItemArg(args[0])
+		if err != nil {
+			return nil, "", err
+		}
+		index = &arg
+		args = args[1:]
+	}
+
+	switch {
+	case t.FromContent && len(args) > 0:
+		return nil, "", fmt.Errorf("cannot specify both a title and --from-content")
+	case t.FromContent:
+		return index, "", nil
+	case len(args) == 0:
+		return nil, "", fmt.Errorf("a new title or --from-content is required")
+	case len(args) > 1:
+		return nil, "", fmt.Errorf("too many arguments; quote the title if it contains spaces")
+	}
+	return index, args[0], nil
+}
+
+// NoteCmd represents the 'rem note' command (annotates an item)
+type NoteCmd struct {
+	Args []string `arg:"positional" help:"<index or @reference> <note>, or just <note> with --id; an empty note clears it"`
+	ID   *uint    `arg:"--id" help:"Select the item by ID instead of index"`
+}
+
+// target returns the selected index or @reference (nil when --id is used)
+// and the new note. With --id every argument is the note, even one starting with @.
+func (n *NoteCmd) target() (*ItemArg, string, error) {
+	args := n.Args
+	var index *ItemArg
+	if n.ID == nil {
+		if len(args) == 0 {
+			return nil, "", fmt.Errorf("an index, @reference, or --id is required")
+		}
+		arg, err := parseItemArg(args[0])
+		if err != nil {
+			return nil, "", err
+		}
+		index = &arg
+		args = args[1:]
+	}
+
+	switch {
+	case len(args) == 0:
+		return nil, "", fmt.Errorf(`a note is required; pass "" to clear it`)
+	case len(args) > 1:
+		return nil, "", fmt.Errorf("too many arguments; quote the note if it contains spaces")
+	}
+	return index, args[0], nil
+}
+
+// SyncCmd represents the 'rem sync' command (copies items between databases)
+type SyncCmd struct {
+	To     *string `arg:"--to" help:"Copy items from this database to the database at this path"`
+	From   *string `arg:"--from" help:"Copy items from the database at this path into this database"`
+	Since  *string `arg:"--since" help:"Only sync items stored at or after this time (2024-05-01, RFC3339, or an age like 24h, 7d)"`
+	Match  *string `arg:"-m,--match" help:"Only sync items whose title or note matches this regex, ignoring case and accents"`
+	DryRun bool    `arg:"--dry-run" help:"List the items that would be copied without copying them"`
+
+	OnDuplicate string `arg:"--on-duplicate" help:"For content already in the destination under another title, note, or timestamp: keep (default), replace, newest, or ask"`
+}
+
+// BackupCmd represents the 'rem backup' command (manages database backups)
+type BackupCmd struct {
+	Create  *BackupCreateCmd  `arg:"subcommand:create" help:"Back up the database now"`
+	List    *BackupListCmd    `arg:"subcommand:list" help:"List backups, newest first"`
+	Restore *BackupRestoreCmd `arg:"subcommand:restore" help:"Replace the database with a backup"`
+}
+
+// BackupCreateCmd represents the 'rem backup create' command
+type BackupCreateCmd struct {
+}
+
+// BackupListCmd represents the 'rem backup list' command
+type BackupListCmd struct {
+}
+
+// BackupRestoreCmd represents the 'rem backup restore' command
+type BackupRestoreCmd struct {
+	File  string `arg:"positional,required" help:"Backup file to restore (a path, or a name from 'rem backup list')"`
+	Force bool   `arg:"-f,--force" help:"Confirm replacing the current database"`
+}
+
+// StatsCmd represents the 'rem stats' command (reports storage use)
+type StatsCmd struct {
+}
+
+// InfoCmd represents the 'rem info' command
+type InfoCmd struct {
+	Index *ItemArg `arg:"positional" help:"Queue index (0=top) or @reference of the item"`
+	ID    *uint    `arg:"--id" help:"Select the item by ID instead of index"`
+}
+
+// BumpCmd represents the 'rem bump' command
+type BumpCmd struct {
+	Index *ItemArg `arg:"positional" help:"Queue index (0=top) or @reference of the item"`
+	ID    *uint    `arg:"--id" help:"Select the item by ID instead of index"`
+}
+
+// VersionCmd represents the 'rem version' command
+type VersionCmd struct {
+	JSON bool `arg:"--json" help:"Print the version report as JSON"`
+}
+
+// DoctorCmd represents the 'rem doctor' command
+type DoctorCmd struct {
+	JSON bool `arg:"--json" help:"Print the checks as JSON"`
+}
+
+// EditCmd represents the 'rem edit' command (search and replace in stored content)
+type EditCmd struct {
+	Pattern string  `arg:"--pattern,required" help:"Regex to replace; it is matched one line at a time, so it can't span lines"`
+	Replace *string `arg:"--replace,required" help:"Replacement text; $1 or ${name} inserts a capture group"`
+	Match   *string `arg:"-m,--match" help:"Only edit items whose title, note, or content matches this regex"`
+	DryRun  bool    `arg:"--dry-run" help:"Show how many replacements each item would get without changing it"`
+	Backup  bool    `arg:"--backup" help:"Store each item's original content as a new item before changing it"`
+}
+
+// DedupeCmd represents the 'rem dedupe' command (deletes repeated stores)
+type DedupeCmd struct {
+	Window *string `arg:"--window" help:"Longest gap between two copies for the older to be deleted, such as 10s or 2m (default 10s)"`
+	DryRun bool    `arg:"--dry-run" help:"List the items that would be deleted without deleting them"`
+}
+
+// defaultDedupeWindow is the --window used when none is given
+const defaultDedupeWindow = 10 * time.Second
+
+// window returns the parsed --window
+func (d *DedupeCmd) window() (time.Duration, error) {
+	if d.Window == nil {
+		return defaultDedupeWindow, nil
+	}
+	window, err := time.ParseDuration(*d.Window)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("invalid --window value '%s' (examples: 500ms, 10s, 2m)", *d.Window)
+	}
+	return window, nil
+}
+
+// TailCmd represents the 'rem tail' command (follows new items)
+type TailCmd struct {
+	Interval *string `arg:"--interval" help:"How often to check for new items, such as 500ms or 5s (default 1s)"`
+	Content  bool    `arg:"--content" help:"Print each new item's content after its summary"`
+	Grep     *string `arg:"--grep" help:"Only print new items whose title or content matches this regex"`
+}
+
+// defaultTailInterval is the --interval used when none is given
+const defaultTailInterval = time.Second
+
+// interval returns the parsed --interval
+func (t *TailCmd) interval() (time.Duration, error) {
+	if t.Interval == nil {
+		return defaultTailInterval, nil
+	}
+	interval, err := time.ParseDuration(*t.Interval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid --interval value '%s' (examples: 500ms, 1s, 5s)", *t.Interval)
+	}
+	return interval, nil
+}
+
+// pattern returns the compiled --grep, or nil without one
+func (t *TailCmd) pattern() (*regexp.Regexp, error) {
+	if t.Grep == nil {
+		return nil, nil
+	}
+	re, err := regexp.Compile(*t.Grep)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --grep pattern: %w", err)
+	}
+	return re, nil
+}
+
+// AuditCmd represents the 'rem audit' command
+type AuditCmd struct {
+	List *AuditListCmd `arg:"subcommand:list" help:"List audit entries, newest first"`
+}
+
+// AuditListCmd represents the 'rem audit list' command
+type AuditListCmd struct {
+	Limit int  `arg:"-n,--limit" help:"Show at most this many entries (default: all)"`
+	JSON  bool `arg:"--json" help:"Print one JSON object per entry"`
+}
+
+// MetaCmd represents the 'rem meta' command
+type MetaCmd struct {
+	Set *MetaSetCmd `arg:"subcommand:set" help:"Set a metadata key on an item"`
+	Get *MetaGetCmd `arg:"subcommand:get" help:"Show an item's metadata, or the value of one key"`
+	Del *MetaDelCmd `arg:"subcommand:del" help:"Delete a metadata key from an item"`
+}
+
+// MetaSetCmd represents the 'rem meta set' command
+type MetaSetCmd struct {
+	Index *ItemArg `arg:"positional,required" help:"Queue index (0=top) or @reference of the item"`
+	Key   string   `arg:"positional,required" help:"Metadata key (lowercase letters, digits, '_', '.' or '-')"`
+	Value string   `arg:"positional,required" help:"Value to set the key to (max 4096 bytes)"`
+}
+
+// MetaGetCmd represents the 'rem meta get' command
+type MetaGetCmd struct {
+	Index *ItemArg `arg:"positional,required" help:"Queue index (0=top) or @reference of the item"`
+	Key   *string  `arg:"positional" help:"Only print the value of this key"`
+}
+
+// MetaDelCmd represents the 'rem meta del' command
+type MetaDelCmd struct {
+	Index *ItemArg `arg:"positional,required" help:"Queue index (0=top) or @reference of the item"`
+	Key   string   `arg:"positional,required" help:"Metadata key to delete"`
+}
+
+// MaintenanceCmd represents the 'rem maintenance' command
+type MaintenanceCmd struct {
+	Recompress     *MaintenanceRecompressCmd     `arg:"subcommand:recompress" help:"Compress or decompress stored items to match compress_min_bytes"`
+	FixPermissions *MaintenanceFixPermissionsCmd `arg:"subcommand:fix-permissions" help:"Make the database, its directory, and backups readable only by you"`
+	Verify         *MaintenanceVerifyCmd         `arg:"subcommand:verify" help:"Check every item's content against its recorded size and SHA256"`
+}
+
+// MaintenanceRecompressCmd represents the 'rem maintenance recompress' command
+type MaintenanceRecompressCmd struct {
+	ResumeFrom *uint `arg:"--resume-from" help:"Skip the items older than this ID, continuing an interrupted run"`
+}
+
+// MaintenanceVerifyCmd represents the 'rem maintenance verify' command
+type MaintenanceVerifyCmd struct {
+	Jobs       int   `arg:"--jobs" help:"Items to verify at once (default: the number of CPUs, at most 8)"`
+	ResumeFrom *uint `arg:"--resume-from" help:"Skip the items older than this ID, continuing an interrupted run"`
+}
+
+// MaintenanceFixPermissionsCmd represents the 'rem maintenance fix-permissions' command
+type MaintenanceFixPermissionsCmd struct {
 }
 
 // Description returns the program description
@@ -70,11 +483,6 @@ func (Args) Description() string {
 	return "rem - Enhanced clipboard queue manager with persistent LIFO queue"
 }
 
-// Version returns the program version
-func (Args) Version() string {
-	return "rem 0.1.0"
-}
-
 // Epilogue returns additional help text
 func (Args) Epilogue() string {
 	return `Examples:
@@ -83,21 +491,43 @@ func (Args) Epilogue() string {
   rem store --title "My Note" file.txt        # Store from file with custom title
   rem store -t "Important" file1.txt file2.txt # Store multiple files with title
   rem store -c                                # Store from clipboard
+  rem store -c --settle 1s --min-length 3     # Wait for a selection to settle; skip tiny ones
+  make 2>&1 | rem store --title-line 3        # Title the item with its third line
+  rem store --title-file CHANGELOG.md notes.txt  # Title from the first line of another file
+  ls --color | rem store --filter strip-ansi  # Strip color codes before storing
+  rem store -r --include "*.conf" /etc/nginx  # Store each matching file as its own item
+  make 2>&1 | rem store --tee | grep error    # Store output and pass it on unchanged
+  pg_dump mydb | rem store --dry-run          # Report what would be stored, storing nothing
+  id=$(date | rem store --format '{{.ID}}')    # Print only the new item's ID
+  make 2>&1 | rem store --title-template '{{.Hostname}}: {{.FirstLine}}'  # Title from a template
+  tail -n +1 *.conf | rem store --section-marker '^==> (.*) <==$'  # Store one item, marking a section per file
 
   # Get operations
   rem get                          # Interactive TUI browser
+  rem get --no-tui                 # Pick from a numbered list (for dumb terminals)
   rem get 0                        # Output first item to stdout
   rem get -c 1                     # Copy second item to clipboard
   rem get 2 output.txt             # Save third item to file
+  rem get 0 --json                 # Print first item with metadata as JSON
+  rem get -c --match 'ticket-\d+'  # Copy the newest matching item to clipboard
+  rem get 0 --section b.conf       # Output only a section recorded by --section-marker
+  rem get 0 --restore              # Recreate a stored file with its name, mode, and mtime
+  rem get 0 --open                 # Open an item that is just a URL in the browser
+  rem get -c 0 -q                  # Copy without printing a confirmation
+  rem info 0                       # Show an item's metadata, including the file it came from
+  rem get @0k3f                    # Print the item with this short reference (see rem list)
 
   # Configuration operations
   rem config list                  # List all configuration values
+  rem config list -v               # ...with when each was last changed
   rem config get history_limit     # Get specific configuration value
   rem config set history_limit 50  # Set configuration value
 
   # History management
   rem clear                        # Clear all history (with confirmation)
   rem clear --force                # Clear all history without confirmation
+  rem clear --reset-ids            # ...and number new items from 1 again
+  rem audit list --limit 20        # When items were deleted, evicted, or cleared, and by what
   rem search 'error.*log'          # Search for regex pattern (first match content)
   rem search -i 'pattern'          # Output only the index of first match
   rem search -a 'pattern'          # Concatenate all matching items
@@ -105,6 +535,70 @@ func (Args) Epilogue() string {
   rem search --title 'config'      # Search titles only
   rem search --content 'password'  # Search content only
   rem search -s 'CaseSensitive'    # Case-sensitive search
+  rem search --order oldest 'todo' # Oldest match first
+  rem search -a --count 'TODO'     # Index, matching line count, and title of every match
+  rem search -a --titles-only 'x'  # Index and title of every match
+  rem search -a --since 24h 'TODO' # Only items from the last day
+  rem search --tui 'panic'         # Browse every match in the TUI
+  rem search -a --since 2024-05-01 --until 2024-05-02 --titles-only  # Everything from one day
+  rem list                         # Index, reference, time, and title of every item
+  rem list --since 7d              # Items from the last week
+  rem list --json                  # Metadata of every item, one JSON object per line
+  rem list --sort size --min-size 1MB  # Largest items first, only those over 1MB
+  rem list --preview               # The first line of each item under its title
+  rem --utc list                   # Every item, with times in UTC
+
+  # Titles
+  rem title 3 "prod nginx config"  # Rename the item at index 3
+  rem title --id 42 "release notes" # Rename by ID
+  rem title 0 --from-content       # Regenerate title from the first line
+
+  # Reordering
+  rem bump 5                       # Move the item at index 5 to the top
+  rem bump --id 42                 # Move item 42 to the top
+
+  # Notes
+  rem note 0 "staging deploy key"  # Describe the newest item
+  rem note --id 42 ""              # Clear the note of item 42
+  rem search --notes 'staging'     # Search notes only
+
+  # Search and replace in stored content
+  rem edit --pattern 'old\.host' --replace 'new.host' --dry-run
+
+  # Duplicates
+  rem dedupe --dry-run             # List copies stored within 10s of an identical item
+  rem dedupe --window 2s           # Delete copies stored within 2s of an identical item
+
+  # Following new items
+  rem tail                         # Print a line for each item stored from now on
+  rem tail --content --grep 'http' # Print new items mentioning http, with their content
+
+  # Metadata
+  rem meta set 0 ticket OPS-12     # Tag the top item with ticket=OPS-12
+  rem meta get 0                   # Show the top item's metadata
+  rem meta del 0 ticket            # Remove the ticket key
+  rem search --meta ticket=OPS-12  # Find items tagged ticket=OPS-12
+
+  # Sync between databases
+  rem sync --to ~/home.db --since 24h  # Copy the last day's items to another database
+  rem sync --from ~/work.db --dry-run  # Show what would be copied from another database
+  rem sync --from ~/work.db --on-duplicate ask  # Choose titles for content both databases have
+
+  # Backups (taken automatically before clear and schema upgrades)
+  rem backup create                # Back up the database now
+  rem backup list                  # List backups, newest first
+  rem backup restore --force rem-20240501-120000.000000.db  # Replace the database with a backup
+
+  # Storage
+  rem stats                        # Show logical and physical bytes stored
+  rem maintenance recompress       # Apply compress_min_bytes to existing items
+  rem maintenance fix-permissions  # Make the database and backups private to you
+  rem maintenance verify           # Check stored content against its SHA256 hashes
+
+  # Bug reports
+  rem --version                    # Version, commit, Go version, and database report
+  rem version --json               # The same, as JSON
+  rem doctor                       # Check the database, config, clipboard, and terminal
 
   # Database path
   rem --db-path /custom/rem.db store file.txt  # Use custom database location
@@ -115,6 +609,11 @@ For more information, visit: https://github.com/yiblet/rem`
 
 // Validate performs validation on the parsed arguments
 func (args *Args) Validate() error {
+	if args.ReadOnly {
+		if err := args.validateReadOnly(); err != nil {
+			return err
+		}
+	}
 	if args.Store != nil {
 		return args.Store.Validate()
 	}
@@ -130,6 +629,86 @@ func (args *Args) Validate() error {
 	if args.Search != nil {
 		return args.Search.Validate()
 	}
+	if args.List != nil {
+		return args.List.Validate()
+	}
+	if args.Title != nil {
+		return args.Title.Validate()
+	}
+	if args.Note != nil {
+		return args.Note.Validate()
+	}
+	if args.Sync != nil {
+		return args.Sync.Validate()
+	}
+	if args.Backup != nil {
+		return args.Backup.Validate()
+	}
+	if args.Maintenance != nil {
+		return args.Maintenance.Validate()
+	}
+	if args.Info != nil {
+		return args.Info.Validate()
+	}
+	if args.Bump != nil {
+		return args.Bump.Validate()
+	}
+	if args.Edit != nil {
+		return args.Edit.Validate()
+	}
+	if args.Dedupe != nil {
+		return args.Dedupe.Validate()
+	}
+	if args.Tail != nil {
+		return args.Tail.Validate()
+	}
+	if args.Meta != nil {
+		return args.Meta.Validate()
+	}
+	if args.Audit != nil {
+		return args.Audit.Validate()
+	}
+	return nil
+}
+
+// HasSubcommand reports whether any subcommand was given
+func (args *Args) HasSubcommand() bool {
+	return args.Store != nil || args.Get != nil || args.Config != nil || args.Clear != nil ||
+		args.Search != nil || args.List != nil || args.Title != nil || args.Note != nil ||
+		args.Sync != nil || args.Backup != nil || args.Stats != nil || args.Maintenance != nil ||
+		args.Info != nil || args.Bump != nil || args.Version != nil || args.Doctor != nil ||
+		args.Edit != nil || args.Dedupe != nil || args.Tail != nil || args.Meta != nil ||
+		args.Audit != nil
+}
+
+// validateReadOnly rejects commands that modify the database
+func (args *Args) validateReadOnly() error {
+	switch {
+	case args.Store != nil && !args.Store.DryRun:
+		return fmt.Errorf("cannot store items with --read-only")
+	case args.Clear != nil:
+		return fmt.Errorf("cannot clear history with --read-only")
+	case args.Title != nil:
+		return fmt.Errorf("cannot rename items with --read-only")
+	case args.Note != nil:
+		return fmt.Errorf("cannot change notes with --read-only")
+	case args.Bump != nil:
+		return fmt.Errorf("cannot reorder items with --read-only")
+	case args.Edit != nil && !args.Edit.DryRun:
+		return fmt.Errorf("cannot edit items with --read-only")
+	case args.Dedupe != nil && !args.Dedupe.DryRun:
+		return fmt.Errorf("cannot delete duplicates with --read-only")
+	case args.Sync != nil && args.Sync.From != nil && !args.Sync.DryRun:
+		return fmt.Errorf("cannot sync into this database with --read-only")
+	case args.Config != nil && args.Config.Set != nil:
+		return fmt.Errorf("cannot set configuration with --read-only")
+	case args.Meta != nil && args.Meta.Get == nil:
+		return fmt.Errorf("cannot change metadata with --read-only")
+	case args.Backup != nil && args.Backup.Restore != nil:
+		return fmt.Errorf("cannot restore a backup with --read-only")
+	case args.Maintenance != nil && args.Maintenance.Verify == nil:
+		return fmt.Errorf("cannot run maintenance with --read-only")
+	}
 	return nil
 }
 
@@ -138,20 +717,403 @@ func (s *StoreCmd) Validate() error {
 	if len(s.Files) > 0 && s.Clipboard {
 		return fmt.Errorf("cannot specify both file and clipboard input")
 	}
+	if s.Tee && (len(s.Files) > 0 || s.Clipboard) {
+		return fmt.Errorf("--tee only works with stdin")
+	}
+	if (s.Title != nil && s.TitleFile != nil) || (s.Title != nil && s.TitleFromClipboard) || (s.TitleFile != nil && s.TitleFromClipboard) {
+		return fmt.Errorf("only one of --title, --title-file, and --title-from-clipboard can be given")
+	}
+	if s.TitleFromClipboard && s.Clipboard {
+		return fmt.Errorf("cannot use --title-from-clipboard with -c; the clipboard is already the content")
+	}
+	if s.TitleLine != nil && (s.Title != nil || s.TitleFile != nil || s.TitleFromClipboard || s.Recursive) {
+		return fmt.Errorf("--title-line picks the line of a generated title; it cannot be combined with --title, --title-file, --title-from-clipboard, or --recursive")
+	}
+	if s.TitleLine != nil && *s.TitleLine < 1 {
+		return fmt.Errorf("--title-line counts from 1")
+	}
+	if (s.Settle != nil || s.MinLength != 0) && !s.Clipboard {
+		return fmt.Errorf("--settle and --min-length only work with -c")
+	}
+	if s.MinLength < 0 {
+		return fmt.Errorf("--min-length must not be negative")
+	}
+	if _, err := s.settle(); err != nil {
+		return err
+	}
+	if s.DryRun && (s.Tee || s.Recursive) {
+		return fmt.Errorf("--dry-run cannot be combined with --tee or --recursive")
+	}
+	if s.DryRun && (s.Format != nil || s.Quiet) {
+		return fmt.Errorf("--dry-run cannot be combined with --format or --quiet")
+	}
+	if s.Format != nil {
+		if _, err := parseStatusFormat(*s.Format); err != nil {
+			return err
+		}
+	}
+	if _, err := filter.ParseChain(s.Filters); err != nil {
+		return err
+	}
+	if s.TitleTemplate != nil {
+		if _, err := parseTitleTemplate(*s.TitleTemplate); err != nil {
+			return err
+		}
+	}
+	if _, err := s.sectionMarker(); err != nil {
+		return err
+	}
+	if !s.Recursive {
+		if len(s.Include) > 0 || len(s.Exclude) > 0 || s.MaxFileSize != nil || s.FollowSymlinks {
+			return fmt.Errorf("--include, --exclude, --max-file-size, and --follow-symlinks require --recursive")
+		}
+		return nil
+	}
+	if len(s.Files) == 0 {
+		return fmt.Errorf("--recursive requires a directory")
+	}
+	if s.Title != nil || s.TitleTemplate != nil || s.TitleFile != nil || s.TitleFromClipboard {
+		return fmt.Errorf("cannot set a title with --recursive; items are titled with their paths")
+	}
+	if s.SectionMarker != nil {
+		return fmt.Errorf("cannot use --section-marker with --recursive; each file is already its own item")
+	}
+	for _, pattern := range append(slices.Clone(s.Include), s.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	if s.MaxFileSize != nil {
+		if _, err := parseSize(*s.MaxFileSize); err != nil {
+			return fmt.Errorf("invalid --max-file-size: %w", err)
+		}
+	}
 	return nil
 }
 
+// defaultSettle is the --settle used when none is given
+const defaultSettle = 300 * time.Millisecond
+
+// settle returns the parsed --settle
+func (s *StoreCmd) settle() (time.Duration, error) {
+	if s.Settle == nil {
+		return defaultSettle, nil
+	}
+	settle, err := time.ParseDuration(*s.Settle)
+	if err != nil || settle < 0 {
+		return 0, fmt.Errorf("invalid --settle value '%s' (examples: 0, 300ms, 1s)", *s.Settle)
+	}
+	return settle, nil
+}
+
+// sectionMarker returns the compiled --section-marker, or nil without one
+func (s *StoreCmd) sectionMarker() (*regexp.Regexp, error) {
+	if s.SectionMarker == nil {
+		return nil, nil
+	}
+	re, err := regexp.Compile(*s.SectionMarker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --section-marker pattern: %w", err)
+	}
+	return re, nil
+}
+
+// StdoutFile is the output file name that means stdout
+const StdoutFile = "-"
+
+// outputFile returns the file to write to, given positionally or with
+// --output, or nil for stdout
+func (g *GetCmd) outputFile() *string {
+	file := g.Output
+	if file == nil {
+		file = g.File
+	}
+	if file != nil && *file == StdoutFile {
+		return nil
+	}
+	return file
+}
+
+// toStdout reports whether stdout was asked for explicitly, with -
+func (g *GetCmd) toStdout() bool {
+	return (g.Output != nil && *g.Output == StdoutFile) || (g.File != nil && *g.File == StdoutFile)
+}
+
 // Validate validates get command arguments
 func (g *GetCmd) Validate() error {
-	if g.Index != nil && *g.Index < 0 {
-		return fmt.Errorf("index must be non-negative")
+	if err := g.Index.validate(); err != nil {
+		return err
 	}
-	if g.File != nil && g.Clipboard {
+	if g.Match != nil && g.Index != nil {
+		return fmt.Errorf("cannot specify both an index and --match")
+	}
+	if (g.MatchTitle || g.MatchContent) && g.Match == nil {
+		return fmt.Errorf("--match-title and --match-content require --match")
+	}
+	if g.MatchTitle && g.MatchContent {
+		return fmt.Errorf("cannot specify both --match-title and --match-content")
+	}
+	if g.Output != nil && g.File != nil {
+		return fmt.Errorf("cannot specify both a positional file and --output")
+	}
+	if g.outputFile() != nil && g.Clipboard {
 		return fmt.Errorf("cannot specify both file and clipboard output")
 	}
+	if g.toStdout() && (g.Clipboard || g.Restore) {
+		return fmt.Errorf("cannot write to stdout (-) with -c or --restore, which write elsewhere")
+	}
+	if g.Section != nil {
+		if g.Index == nil && g.Match == nil {
+			return fmt.Errorf("--section requires an index or --match")
+		}
+		if g.JSON || g.Restore {
+			return fmt.Errorf("--section cannot be combined with --json or --restore")
+		}
+	}
+	if g.Force && !g.Clipboard {
+		return fmt.Errorf("--force only applies to clipboard output (-c)")
+	}
+	if g.Format != nil {
+		if _, err := parseStatusFormat(*g.Format); err != nil {
+			return err
+		}
+	}
+	if g.JSON {
+		if g.Index == nil && g.Match == nil {
+			return fmt.Errorf("--json requires an index or --match")
+		}
+		if g.outputFile() != nil || g.Clipboard {
+			return fmt.Errorf("--json cannot be combined with file or clipboard output")
+		}
+	}
+	if g.Restore {
+		if g.Index == nil && g.Match == nil {
+			return fmt.Errorf("--restore requires an index or --match")
+		}
+		if g.outputFile() != nil || g.Clipboard || g.JSON {
+			return fmt.Errorf("--restore cannot be combined with file, clipboard, or JSON output")
+		}
+	}
+	if g.Open {
+		if g.Index == nil && g.Match == nil {
+			return fmt.Errorf("--open requires an index or --match")
+		}
+		if g.outputFile() != nil || g.toStdout() || g.Clipboard || g.JSON || g.Restore || g.Section != nil {
+			return fmt.Errorf("--open cannot be combined with other output options")
+		}
+	}
+	if g.MaxBytes != nil {
+		if !g.JSON {
+			return fmt.Errorf("--max-bytes requires --json")
+		}
+		if *g.MaxBytes < 0 {
+			return fmt.Errorf("--max-bytes must be non-negative")
+		}
+	}
+	return nil
+}
+
+// Validate validates title command arguments
+func (t *TitleCmd) Validate() error {
+	index, _, err := t.target()
+	if err != nil {
+		return err
+	}
+	return index.validate()
+}
+
+// Validate validates note command arguments
+func (n *NoteCmd) Validate() error {
+	index, _, err := n.target()
+	if err != nil {
+		return err
+	}
+	return index.validate()
+}
+
+// Validate validates info command arguments
+func (i *InfoCmd) Validate() error {
+	if (i.Index == nil) == (i.ID == nil) {
+		return fmt.Errorf("specify exactly one of an index, @reference, or --id")
+	}
+	return i.Index.validate()
+}
+
+// Validate validates meta command arguments
+func (m *MetaCmd) Validate() error {
+	switch {
+	case m.Set != nil:
+		if err := m.Set.Index.validate(); err != nil {
+			return err
+		}
+		return store.ValidateMeta(m.Set.Key, m.Set.Value)
+	case m.Get != nil:
+		if err := m.Get.Index.validate(); err != nil {
+			return err
+		}
+		if m.Get.Key != nil {
+			return store.ValidateMetaKey(*m.Get.Key)
+		}
+		return nil
+	case m.Del != nil:
+		if err := m.Del.Index.validate(); err != nil {
+			return err
+		}
+		return store.ValidateMetaKey(m.Del.Key)
+	}
+	return fmt.Errorf("specify a meta subcommand: set, get, or del")
+}
+
+// Validate validates audit command arguments
+func (a *AuditCmd) Validate() error {
+	if a.List == nil {
+		return fmt.Errorf("specify an audit subcommand: list")
+	}
+	if a.List.Limit < 0 {
+		return fmt.Errorf("--limit must be non-negative")
+	}
+	return nil
+}
+
+// Validate validates bump command arguments
+func (b *BumpCmd) Validate() error {
+	if (b.Index == nil) == (b.ID == nil) {
+		return fmt.Errorf("specify exactly one of an index, @reference, or --id")
+	}
+	return b.Index.validate()
+}
+
+// Validate validates edit command arguments
+func (e *EditCmd) Validate() error {
+	if _, err := regexp.Compile(e.Pattern); err != nil {
+		return fmt.Errorf("invalid --pattern: %w", err)
+	}
+	if e.Match != nil {
+		if _, err := regexp.Compile(*e.Match); err != nil {
+			return fmt.Errorf("invalid --match: %w", err)
+		}
+	}
+	if e.Backup && e.DryRun {
+		return fmt.Errorf("--backup has no effect with --dry-run")
+	}
 	return nil
 }
 
+// Validate validates dedupe command arguments
+func (d *DedupeCmd) Validate() error {
+	_, err := d.window()
+	return err
+}
+
+// Validate validates tail command arguments
+func (t *TailCmd) Validate() error {
+	if _, err := t.interval(); err != nil {
+		return err
+	}
+	_, err := t.pattern()
+	return err
+}
+
+// Validate validates sync command arguments
+func (s *SyncCmd) Validate() error {
+	if (s.To == nil) == (s.From == nil) {
+		return fmt.Errorf("specify exactly one of --to or --from")
+	}
+	switch s.OnDuplicate {
+	case "", duplicateKeep, duplicateReplace, duplicateNewest, duplicateAsk:
+	default:
+		return fmt.Errorf("invalid --on-duplicate %q: expected keep, replace, newest, or ask", s.OnDuplicate)
+	}
+	if _, err := s.matcher(); err != nil {
+		return err
+	}
+	_, _, err := parseTimeWindow(s.Since, nil, time.Now())
+	return err
+}
+
+// matcher returns a function reporting whether an item's title or note
+// matches --match, which every item does without it
+func (s *SyncCmd) matcher() (func(*store.HistoryItem) bool, error) {
+	if s.Match == nil {
+		return func(*store.HistoryItem) bool { return true }, nil
+	}
+	query := &store.SearchQuery{Pattern: *s.Match, Normalize: true}
+	regex, err := store.CompilePattern(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --match pattern: %w", err)
+	}
+	return func(item *store.HistoryItem) bool {
+		return regex.MatchString(store.MatchText(query, item.Title)) || regex.MatchString(store.MatchText(query, item.Note))
+	}, nil
+}
+
+// Validate validates backup command arguments
+func (b *BackupCmd) Validate() error {
+	subCmdCount := 0
+	for _, set := range []bool{b.Create != nil, b.List != nil, b.Restore != nil} {
+		if set {
+			subCmdCount++
+		}
+	}
+	if subCmdCount != 1 {
+		return fmt.Errorf("specify exactly one backup subcommand: create, list, or restore")
+	}
+	if b.Restore != nil && !b.Restore.Force {
+		return fmt.Errorf("restoring replaces the current database; pass --force to confirm")
+	}
+	return nil
+}
+
+// Validate validates maintenance command arguments
+func (m *MaintenanceCmd) Validate() error {
+	if m.Recompress == nil && m.FixPermissions == nil && m.Verify == nil {
+		return fmt.Errorf("specify a maintenance subcommand: recompress, fix-permissions, verify")
+	}
+	if m.Verify != nil && (m.Verify.Jobs < 0 || m.Verify.Jobs > maxJobs) {
+		return fmt.Errorf("--jobs must be between 1 and %d", maxJobs)
+	}
+	return nil
+}
+
+// Validate validates list command arguments
+func (l *ListCmd) Validate() error {
+	if _, _, err := parseTimeWindow(l.Since, l.Until, time.Now()); err != nil {
+		return err
+	}
+	if _, err := l.order(); err != nil {
+		return err
+	}
+	if _, err := l.minSize(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// order returns the order given with --sort and --reverse
+func (l *ListCmd) order() (store.OrderSpec, error) {
+	var key string
+	if l.Sort != nil {
+		key = *l.Sort
+	}
+	sortKey, err := store.ParseSortKey(key)
+	if err != nil {
+		return store.OrderSpec{}, err
+	}
+	return store.OrderSpec{Key: sortKey, Reverse: l.Reverse}, nil
+}
+
+// minSize returns --min-size in bytes, or 0 without it
+func (l *ListCmd) minSize() (int64, error) {
+	if l.MinSize == nil {
+		return 0, nil
+	}
+	size, err := parseSize(*l.MinSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --min-size: %w", err)
+	}
+	return size, nil
+}
+
 // Validate validates config command arguments
 func (c *ConfigCmd) Validate() error {
 	// Exactly one subcommand must be provided
@@ -189,24 +1151,20 @@ func (c *ConfigCmd) Validate() error {
 
 // Validate validates config get command arguments
 func (g *ConfigGetCmd) Validate() error {
-	validKeys := []string{"history_limit", "show_binary", "db_version"}
-	for _, validKey := range validKeys {
-		if g.Key == validKey {
-			return nil
-		}
-	}
-	return fmt.Errorf("invalid configuration key '%s', valid keys are: %v", g.Key, validKeys)
+	_, err := lookupConfigKey(g.Key)
+	return err
 }
 
 // Validate validates config set command arguments
 func (s *ConfigSetCmd) Validate() error {
-	validKeys := []string{"history_limit", "show_binary"}
-	for _, validKey := range validKeys {
-		if s.Key == validKey {
-			return nil
-		}
+	key, err := lookupConfigKey(s.Key)
+	if err != nil {
+		return err
 	}
-	return fmt.Errorf("invalid configuration key '%s', valid keys are: %v", s.Key, validKeys)
+	if key.readOnly {
+		return fmt.Errorf("%s is read-only", key.name)
+	}
+	return nil
 }
 
 // Validate validates config list command arguments
@@ -223,8 +1181,29 @@ func (c *ClearCmd) Validate() error {
 
 // Validate validates search command arguments
 func (s *SearchCmd) Validate() error {
-	if s.Pattern == "" {
+	if s.Pattern == "" && s.Since == nil && s.Until == nil && len(s.Meta) == 0 {
 		return fmt.Errorf("search pattern cannot be empty")
 	}
+	if _, err := s.metaEquals(); err != nil {
+		return err
+	}
+	if _, _, err := parseTimeWindow(s.Since, s.Until, time.Now()); err != nil {
+		return err
+	}
+	if _, err := store.ParseSearchOrder(s.Order); err != nil {
+		return err
+	}
+	formats := 0
+	for _, set := range []bool{s.IndexOnly, s.Count, s.TitlesOnly} {
+		if set {
+			formats++
+		}
+	}
+	if formats > 1 {
+		return fmt.Errorf("--index-only, --count, and --titles-only cannot be combined")
+	}
+	if s.TUI && formats > 0 {
+		return fmt.Errorf("--tui cannot be combined with --index-only, --count, or --titles-only")
+	}
 	return nil
 }
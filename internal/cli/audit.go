@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// maxAuditSourceBytes bounds the command line recorded with an audit entry
+const maxAuditSourceBytes = 200
+
+// auditSourceTUI is the source of audit entries for items removed in the
+// viewer
+const auditSourceTUI = "tui"
+
+// auditEntryJSON is one line of 'rem audit list --json'
+type auditEntryJSON struct {
+	ID         uint      `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Operation  string    `json:"operation"`
+	ItemID     *uint     `json:"item_id"`
+	ItemRef    string    `json:"item_ref,omitempty"`
+	ItemTitle  string    `json:"item_title"`
+	ItemSHA256 string    `json:"item_sha256"`
+	Source     string    `json:"source"`
+}
+
+// auditSource summarizes the command line for the audit log: rem and its
+// arguments, quoting those with spaces, cut to maxAuditSourceBytes
+func (args *Args) auditSource() string {
+	parts := []string{"rem"}
+	for _, arg := range args.argv {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	source := strings.Join(parts, " ")
+	if len(source) > maxAuditSourceBytes {
+		source = strings.ToValidUTF8(source[:maxAuditSourceBytes-3], "") + "..."
+	}
+	return source
+}
+
+// setAuditSource sets what the store records as removing items from now on,
+// if it keeps an audit log
+func (c *CLI) setAuditSource(source string) {
+	if auditor, ok := c.store.(store.Auditor); ok {
+		auditor.SetAuditSource(source)
+	}
+}
+
+// warnAudit reports an audit entry that couldn't be written; the removal it
+// records has already happened
+func warnAudit(err error) {
+	fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+}
+
+// executeAudit handles the 'rem audit' command
+func (c *CLI) executeAudit(cmd *AuditCmd) error {
+	switch {
+	case cmd.List != nil:
+		return c.executeAuditList(cmd.List)
+	default:
+		return fmt.Errorf("no audit subcommand specified")
+	}
+}
+
+// executeAuditList handles the 'rem audit list' command, printing the
+// newest entries first
+func (c *CLI) executeAuditList(cmd *AuditListCmd) error {
+	auditor, ok := c.store.(store.Auditor)
+	if !ok {
+		return fmt.Errorf("this database keeps no audit log")
+	}
+	entries, err := auditor.AuditLog(cmd.Limit)
+	if err != nil {
+		return err
+	}
+
+	if cmd.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, entry := range entries {
+			line := auditEntryJSON{
+				ID: entry.ID, Timestamp: entry.Timestamp, Operation: entry.Operation,
+				ItemID: entry.ItemID, ItemTitle: entry.ItemTitle, ItemSHA256: entry.ItemSHA256, Source: entry.Source,
+			}
+			if entry.ItemID != nil {
+				line.ItemRef = "@" + ref.Encode(*entry.ItemID)
+			}
+			if err := encoder.Encode(line); err != nil {
+				return fmt.Errorf("failed to encode audit entry: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		if store.AuditKeepDays(c.store.Config()) == 0 {
+			fmt.Println("No audit entries; auditing is off (audit_keep_days = 0).")
+		} else {
+			fmt.Println("No audit entries.")
+		}
+		return nil
+	}
+	for _, entry := range entries {
+		item, title := "-", entry.ItemTitle
+		if entry.ItemID != nil {
+			item = "@" + ref.Encode(*entry.ItemID)
+		} else {
+			title = "(no items)"
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\t%s\n", c.formatTime(entry.Timestamp, timefmt.Second), entry.Operation, item, title, entry.Source)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/ref"
+)
+
+func TestAuditList(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "audit-test.db")
+	var args Args
+	Parse(&args, []string{"--db-path", dbPath, "clear", "--force"})
+	cli, err := NewWithArgs(&args)
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	var ids []uint
+	for _, content := range []string{"one", "two"} {
+		item, err := cli.queueManager.Enqueue(strings.NewReader(content), content)
+		if err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	withStdout(t, func() {
+		if err := cli.Execute(&args); err != nil {
+			t.Fatalf("rem clear failed: %v", err)
+		}
+	})
+	list := func(cmd *AuditListCmd) string {
+		return withStdout(t, func() {
+			if err := cli.executeAuditList(cmd); err != nil {
+				t.Fatalf("rem audit list failed: %v", err)
+			}
+		})
+	}
+
+	// Newest first, each with the command that removed it
+	out := list(&AuditListCmd{})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	source := "rem --db-path " + dbPath + " clear --force"
+	if len(lines) != 2 || !strings.Contains(lines[0], "\tclear\t@"+ref.Encode(ids[1])+"\ttwo\t"+source) || !strings.Contains(lines[1], "\t@"+ref.Encode(ids[0])+"\tone\t") {
+		t.Errorf("unexpected audit list:\n%s", out)
+	}
+	if out := list(&AuditListCmd{Limit: 1}); strings.Count(out, "\n") != 1 {
+		t.Errorf("expected one entry with --limit 1, got:\n%s", out)
+	}
+
+	var entry auditEntryJSON
+	if err := json.Unmarshal([]byte(strings.Split(list(&AuditListCmd{JSON: true}), "\n")[0]), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if entry.Operation != "clear" || entry.ItemID == nil || *entry.ItemID != ids[1] || entry.ItemSHA256 == "" || entry.Source != source {
+		t.Errorf("unexpected JSON entry %+v", entry)
+	}
+
+	// With auditing off, nothing more is recorded
+	if err := cli.store.Config().Set("audit_keep_days", "0"); err != nil {
+		t.Fatalf("Failed to set audit_keep_days: %v", err)
+	}
+	item, _ := cli.queueManager.Enqueue(strings.NewReader("three"), "three")
+	if err := cli.queueManager.DeleteByID(item.ID); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if out := list(&AuditListCmd{}); strings.Count(out, "\n") != 2 {
+		t.Errorf("expected no new entry with audit_keep_days 0, got:\n%s", out)
+	}
+
+	if err := (&AuditCmd{List: &AuditListCmd{Limit: -1}}).Validate(); err == nil {
+		t.Error("expected a negative --limit to be rejected")
+	}
+}
+
+func TestAuditSource(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want string
+	}{
+		{nil, "rem"},
+		{[]string{"clear", "--force"}, "rem clear --force"},
+		{[]string{"title", "0", "new title"}, `rem title 0 "new title"`},
+	}
+	for _, tt := range tests {
+		if got := (&Args{argv: tt.argv}).auditSource(); got != tt.want {
+			t.Errorf("auditSource(%q) = %q, want %q", tt.argv, got, tt.want)
+		}
+	}
+
+	long := (&Args{argv: []string{strings.Repeat("x", 500)}}).auditSource()
+	if len(long) != maxAuditSourceBytes || !strings.HasSuffix(long, "...") {
+		t.Errorf("expected a long command line cut to %d bytes, got %d", maxAuditSourceBytes, len(long))
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/yiblet/rem/internal/store/dbstore"
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// backupDir returns the directory backups of the database at dbPath go in
+func backupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "backups")
+}
+
+// executeBackup handles the 'rem backup' command
+func (c *CLI) executeBackup(cmd *BackupCmd) error {
+	switch {
+	case cmd.Create != nil:
+		return c.executeBackupCreate()
+	case cmd.List != nil:
+		return c.executeBackupList()
+	case cmd.Restore != nil:
+		return c.executeBackupRestore(cmd.Restore)
+	default:
+		return fmt.Errorf("no backup subcommand specified")
+	}
+}
+
+// executeBackupCreate handles the 'rem backup create' command. Explicit
+// backups ignore backup_max_bytes but count toward backup_keep.
+func (c *CLI) executeBackupCreate() error {
+	db, err := c.sqliteStore()
+	if err != nil {
+		return err
+	}
+	path, err := db.CreateBackup(backupDir(c.dbPath), max(c.backupKeep(), 1))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up to %s\n", path)
+	return nil
+}
+
+// executeBackupList handles the 'rem backup list' command
+func (c *CLI) executeBackupList() error {
+	backups, err := dbstore.ListBackups(backupDir(c.dbPath))
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Println("No backups.")
+		return nil
+	}
+	for _, backup := range backups {
+		fmt.Printf("%s\t%s\t%d bytes\n", filepath.Base(backup.Path), c.formatTime(backup.Created, timefmt.Second), backup.Size)
+	}
+	return nil
+}
+
+// executeBackupRestore handles the 'rem backup restore' command. The current
+// database is backed up first, so a restore can itself be undone.
+func (c *CLI) executeBackupRestore(cmd *BackupRestoreCmd) error {
+	src := c.resolveBackup(cmd.File)
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("backup %s not found", cmd.File)
+	}
+
+	db, err := c.sqliteStore()
+	if err != nil {
+		return err
+	}
+	dir := backupDir(c.dbPath)
+	keep := c.backupKeep()
+	current := dbstore.BackupPath(dir, time.Now())
+	if err := db.Backup(current); err != nil {
+		return fmt.Errorf("failed to back up the current database: %w", err)
+	}
+
+	// Our own connection must be closed before the file is replaced
+	if err := c.store.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	if err := dbstore.RestoreBackup(src, c.dbPath); err != nil {
+		return err
+	}
+	if err := dbstore.PruneBackups(dir, max(keep, 1)); err != nil {
+		return err
+	}
+
+	fmt.Printf("Restored %s (previous database saved to %s)\n", src, current)
+	return nil
+}
+
+// resolveBackup returns the path of a backup given as a path or as a file
+// name in the backup directory
+func (c *CLI) resolveBackup(file string) string {
+	if _, err := os.Stat(file); err == nil || filepath.Base(file) != file {
+		return file
+	}
+	return filepath.Join(backupDir(c.dbPath), file)
+}
+
+// backupKeep returns the configured number of backups to keep
+func (c *CLI) backupKeep() int {
+	if value, err := c.store.Config().Get("backup_keep"); err == nil {
+		if keep, err := strconv.Atoi(value); err == nil && keep >= 0 {
+			return keep
+		}
+	}
+	return dbstore.DefaultBackupKeep
+}
+
+// sqliteStore returns the SQLite store backing the CLI
+func (c *CLI) sqliteStore() (*dbstore.SQLiteStore, error) {
+	db, ok := c.store.(*dbstore.SQLiteStore)
+	if !ok {
+		return nil, fmt.Errorf("backups require a SQLite database")
+	}
+	return db, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// captureMetaKey is the metadata key marking items stored from the
+// clipboard, the only ones a later capture may replace
+const captureMetaKey = "rem.source"
+
+// captureMetaValue is captureMetaKey's value on a clipboard capture
+const captureMetaValue = "clipboard"
+
+// settleLimit bounds how many settle periods settledClipboard waits for a
+// clipboard that keeps changing
+const settleLimit = 10
+
+// settledClipboard reads the clipboard once it has held the same content
+// for settle. Some Linux desktops publish a selection at every step of
+// making it ("fo", "foo", "foob"), and only the finished one is wanted. A
+// clipboard still changing after settleLimit periods is read as it is;
+// settle 0 reads it at once.
+func (c *CLI) settledClipboard(settle time.Duration) ([]byte, error) {
+	data, err := c.readClipboard()
+	if err != nil || settle <= 0 {
+		return data, err
+	}
+
+	poll := max(settle/5, 10*time.Millisecond)
+	started := time.Now()
+	stableSince := started
+	for time.Since(stableSince) < settle && time.Since(started) < settleLimit*settle {
+		time.Sleep(poll)
+		next, err := c.readClipboard()
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(next, data) {
+			data, stableSince = next, time.Now()
+		}
+	}
+	return data, nil
+}
+
+// supersededCapture returns the newest item when it is a non-empty
+// clipboard capture stored after since and content, about to be stored
+// from the clipboard, starts with or equals it: an earlier step of the same
+// selection, stored by another rem run, which content replaces. It returns
+// nil otherwise.
+func (c *CLI) supersededCapture(content []byte, since time.Time) (*store.HistoryItem, error) {
+	items, err := c.queueManager.ListWith(queue.ListOptions{Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	newest := items[0]
+	if newest.IsBinary || newest.Size == 0 || newest.CreatedAt.Before(since) || newest.Size > int64(len(content)) {
+		return nil, nil
+	}
+	meta, err := c.store.History().GetMeta(newest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata of item %d: %w", newest.ID, err)
+	}
+	if meta[captureMetaKey] != captureMetaValue {
+		return nil, nil
+	}
+	prefix, err := c.store.History().GetContentPrefix(newest.ID, int(newest.Size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item %d: %w", newest.ID, err)
+	}
+	if int64(len(prefix)) != newest.Size || !bytes.HasPrefix(content, prefix) {
+		return nil, nil
+	}
+	return newest, nil
+}
+
+// markCapture records that item, stored at or after stored, came from the
+// clipboard. An item coalesced into an older one keeps that one's source.
+func (c *CLI) markCapture(item *store.HistoryItem, stored time.Time) error {
+	if item.CreatedAt.Before(stored) {
+		return nil
+	}
+	if err := c.store.History().SetMeta(item.ID, captureMetaKey, captureMetaValue); err != nil {
+		return fmt.Errorf("failed to record the item's source: %w", err)
+	}
+	return nil
+}
+
+// replaceCapture deletes superseded now that item, which extends it, is
+// stored, and returns a note for the confirmation line
+func (c *CLI) replaceCapture(superseded, item *store.HistoryItem) (string, error) {
+	if superseded == nil || superseded.ID == item.ID {
+		// An identical capture may have been coalesced into the item itself
+		return "", nil
+	}
+	if err := c.queueManager.DeleteByID(superseded.ID); err != nil {
+		return "", fmt.Errorf("failed to delete replaced item %d: %w", superseded.ID, err)
+	}
+	return fmt.Sprintf(" (replaced %s)", superseded.Title), nil
+}
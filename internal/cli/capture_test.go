@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+)
+
+// typingClipboard is a clipboard whose content grows with each read, like
+// a selection some Linux desktops publish at every step of making it
+type typingClipboard struct {
+	*mockboard.MockClipboard
+	steps []string
+}
+
+func (c *typingClipboard) Read() (io.ReadCloser, error) {
+	if len(c.steps) > 0 {
+		c.SetData([]byte(c.steps[0]))
+		c.steps = c.steps[1:]
+	}
+	return c.MockClipboard.Read()
+}
+
+func TestStoreClipboard_Settle(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	settle := "50ms"
+	cli.clipboard = &typingClipboard{MockClipboard: mockboard.New(), steps: []string{"fo", "foo", "foob", "foobar"}}
+	output := withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Clipboard: true, Settle: &settle}); err != nil {
+			t.Errorf("rem store -c failed: %v", err)
+		}
+	})
+	if output != "Stored from clipboard (6 bytes): foobar\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	items, _ := cli.queueManager.List()
+	if len(items) != 1 || items[0].Title != "foobar" {
+		t.Fatalf("expected only the settled value stored, got %d items", len(items))
+	}
+}
+
+func TestStoreClipboard_ReplacesEarlierCapture(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	mock := mockboard.New()
+	cli.clipboard = mock
+	capture := func(content, settle string) string {
+		mock.SetData([]byte(content))
+		return withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{Clipboard: true, Settle: &settle}); err != nil {
+				t.Errorf("rem store -c failed: %v", err)
+			}
+		})
+	}
+
+	// Another run stored an earlier step of the same selection
+	capture("foo", "50ms")
+	if output := capture("foobar", "50ms"); output != "Stored from clipboard (6 bytes): foobar (replaced foo)\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	capture("foobar", "50ms")
+
+	// Content that doesn't extend the newest item, or --settle 0, adds one
+	capture("other", "50ms")
+	capture("other text", "0")
+
+	items, _ := cli.queueManager.List()
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "other text, other, foobar" {
+		t.Errorf("items = %s", got)
+	}
+
+	// Items that didn't come from the clipboard, or are empty, are kept
+	for _, content := range []string{"piped", ""} {
+		item, err := cli.queueManager.Enqueue(strings.NewReader(content), "not a capture")
+		if err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		if content == "" {
+			if err := cli.store.History().SetMeta(item.ID, captureMetaKey, captureMetaValue); err != nil {
+				t.Fatalf("Failed to set metadata: %v", err)
+			}
+		}
+		capture(content+" and more", "50ms")
+	}
+	if items, _ := cli.queueManager.List(); len(items) != 7 {
+		t.Errorf("expected both items kept alongside the captures, got %d items", len(items))
+	}
+}
+
+func TestStoreClipboard_MinLength(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	mock := mockboard.New()
+	mock.SetData([]byte("ab"))
+	cli.clipboard = mock
+	settle := "0"
+	stderr := withStderr(t, func() {
+		if err := cli.executeStore(&StoreCmd{Clipboard: true, Settle: &settle, MinLength: 5}); err != nil {
+			t.Errorf("rem store -c failed: %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "shorter than --min-length 5") {
+		t.Errorf("unexpected stderr %q", stderr)
+	}
+	if n, _ := cli.queueManager.Size(); n != 0 {
+		t.Errorf("expected nothing stored, got %d items", n)
+	}
+
+	bad := "soon"
+	for _, cmd := range []*StoreCmd{{Settle: &settle}, {MinLength: 3}, {Clipboard: true, Settle: &bad}, {Clipboard: true, MinLength: -1}} {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", cmd)
+		}
+	}
+}
@@ -1,19 +1,36 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"text/tabwriter"
+	"text/template"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yiblet/rem/internal/clipboard"
 	"github.com/yiblet/rem/internal/clipboard/sysboard"
+	"github.com/yiblet/rem/internal/config"
+	"github.com/yiblet/rem/internal/filter"
+	"github.com/yiblet/rem/internal/opener"
 	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/ref"
 	"github.com/yiblet/rem/internal/store"
 	"github.com/yiblet/rem/internal/store/dbstore"
+	"github.com/yiblet/rem/internal/store/memstore"
+	"github.com/yiblet/rem/internal/timefmt"
 	"github.com/yiblet/rem/internal/tui"
 )
 
@@ -22,6 +39,12 @@ type CLI struct {
 	queueManager *queue.QueueManager
 	store        store.Store
 	clipboard    clipboard.Clipboard
+	opener       *opener.Opener
+	dbPath       string
+	ownsDBDir    bool // the database's directory is rem's own, not one given with --db-path
+
+	utc      bool           // --utc: print times in UTC
+	location *time.Location // zone times are printed in, once looked up
 }
 
 // New creates a new CLI instance
@@ -32,35 +55,83 @@ func New() (*CLI, error) {
 // NewWithArgs creates a new CLI instance with custom arguments for database path
 func NewWithArgs(args *Args) (*CLI, error) {
 	// Determine database path (precedence: flag > env var > default)
-	var dbPath string
-	if args != nil && args.DBPath != nil {
-		dbPath = *args.DBPath
-	} else {
-		// Use default path
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	readOnly := args != nil && args.ReadOnly
+
+	dbPath, source, err := chooseDBPath(args, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	ownsDBDir := false
+	switch source {
+	case sourceFlag, sourceFlagEnv, sourceEnv:
+		if dbPath == MemoryDBPath {
+			return newMemoryCLI()
 		}
-		dbPath = filepath.Join(homeDir, ".config", "rem", "rem.db")
+		if err := checkDBPath(dbPath, args != nil && args.UnsafeDBPath); err != nil {
+			return nil, err
+		}
+	case sourceFallback:
+		ownsDBDir = true
+		fmt.Fprintf(os.Stderr, "Warning: HOME is not set, so the database is at %s, which may not survive a reboot; set REM_DB or pass --db-path to keep it elsewhere\n", dbPath)
+	default:
+		ownsDBDir = true
 	}
 
-	// Ensure directory exists
+	// Ensure directory exists, saying so when it's one --db-path named
 	dbDir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	if !readOnly {
+		_, statErr := os.Stat(dbDir)
+		if err := os.MkdirAll(dbDir, 0700); err != nil {
+			return nil, unwritableDBDir(dbDir, err)
+		}
+		if os.IsNotExist(statErr) && !ownsDBDir {
+			fmt.Fprintf(os.Stderr, "Created directory %s for the database\n", dbDir)
+		}
+	}
+	// The fallback sits in a world-writable directory, where another user
+	// could have made it first
+	if source == sourceFallback {
+		if _, statErr := os.Lstat(dbDir); !readOnly || statErr == nil {
+			if err := checkPrivateDir(dbDir); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Create SQLite store
-	sqliteStore, err := dbstore.NewSQLiteStore(dbPath)
+	sqliteStore, err := dbstore.NewSQLiteStoreWithOptions(dbPath, dbstore.Options{
+		ReadOnly: readOnly,
+		Backup: dbstore.BackupPolicy{
+			Dir:      backupDir(dbPath),
+			Keep:     dbstore.DefaultBackupKeep,
+			MaxBytes: dbstore.DefaultBackupMaxBytes,
+		},
+		OnAuditError: warnAudit,
+	})
 	if err != nil {
+		// SQLite only says it can't open the file, so say why when it's the directory
+		if werr := dirWritable(dbDir); werr != nil && !readOnly {
+			return nil, unwritableDBDir(dbDir, werr)
+		}
 		return nil, fmt.Errorf("failed to create database store: %w", err)
 	}
 
+	// Warn early when an enqueue is likely to run out of space
+	if !readOnly {
+		if warning := lowSpaceWarning(sqliteStore, filepath.Dir(dbPath)); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+		fixing := args != nil && args.Maintenance != nil && args.Maintenance.FixPermissions != nil
+		if warning := permissionsWarning(sqliteStore, dbPath, ownsDBDir); warning != "" && !fixing {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
+
 	// Load history limit from config store
 	historyLimit := queue.DefaultMaxQueueSize
 	if limitStr, err := sqliteStore.Config().Get("history_limit"); err == nil {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			historyLimit = limit
+		if limit, err := parseCount(limitStr); err == nil && limit > 0 {
+			historyLimit = int(limit)
 		}
 	}
 
@@ -70,6 +141,21 @@ func NewWithArgs(args *Args) (*CLI, error) {
 		sqliteStore.Close()
 		return nil, fmt.Errorf("failed to create queue manager: %w", err)
 	}
+	if msStr, err := sqliteStore.Config().Get("coalesce_window_ms"); err == nil {
+		if ms, err := strconv.Atoi(msStr); err == nil && ms > 0 {
+			qm.SetCoalesceWindow(time.Duration(ms) * time.Millisecond)
+		}
+	}
+	if name, err := sqliteStore.Config().Get("title_strategy"); err == nil {
+		if pick, err := queue.ParseTitlePick(name); err == nil {
+			qm.SetTitleStrategy(queue.TitleStrategy{Pick: pick})
+		}
+	}
+	if value, err := sqliteStore.Config().Get("title_sample_bytes"); err == nil {
+		if n, err := parseTitleSampleBytes(value); err == nil {
+			qm.SetTitleSampleBytes(n)
+		}
+	}
 
 	// Create system clipboard
 	clip := sysboard.New()
@@ -78,14 +164,155 @@ func NewWithArgs(args *Args) (*CLI, error) {
 		queueManager: qm,
 		store:        sqliteStore,
 		clipboard:    clip,
+		opener:       opener.New(),
+		dbPath:       dbPath,
+		ownsDBDir:    ownsDBDir,
 	}, nil
 }
 
+// MemoryDBPath is the --db-path of a database kept in memory, which is gone
+// when the command exits
+const MemoryDBPath = ":memory:"
+
+// MemoryDBMaxBytes is the most content a :memory: database holds, so a large
+// store fails cleanly rather than exhausting memory
+const MemoryDBMaxBytes = 500 * 1000 * 1000
+
+// newMemoryCLI returns a CLI backed by a fresh in-memory store
+func newMemoryCLI() (*CLI, error) {
+	st := memstore.NewMemoryStoreWithLimit(MemoryDBMaxBytes)
+	qm, err := queue.NewQueueManagerWithConfig(st, queue.DefaultMaxQueueSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create queue manager: %w", err)
+	}
+	return &CLI{
+		queueManager: qm,
+		store:        st,
+		clipboard:    sysboard.New(),
+		opener:       opener.New(),
+		dbPath:       MemoryDBPath,
+	}, nil
+}
+
+// checkDBPath rejects a relative --db-path that climbs out of the current
+// directory, which is more often a mistake than meant, unless unsafe is set
+func checkDBPath(dbPath string, unsafe bool) error {
+	if unsafe || filepath.IsAbs(dbPath) {
+		return nil
+	}
+	clean := filepath.Clean(dbPath)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("database path %s leads out of the current directory; use an absolute path or --unsafe-db-path", dbPath)
+	}
+	return nil
+}
+
+// RemDBEnv names the environment variable that replaces the default database
+// path. REM_DB_PATH sets --db-path itself, so it wins over REM_DB.
+const RemDBEnv = "REM_DB"
+
+// dbPathSource says where the database path came from
+type dbPathSource string
+
+const (
+	sourceFlag     dbPathSource = "--db-path"
+	sourceFlagEnv  dbPathSource = "REM_DB_PATH"
+	sourceEnv      dbPathSource = RemDBEnv
+	sourceDefault  dbPathSource = "default"
+	sourceFallback dbPathSource = "fallback, HOME is not set"
+)
+
+// chooseDBPath returns the database path and where it came from: --db-path
+// (or REM_DB_PATH), then REM_DB, then the platform default. Without a home
+// directory to find the default in, it falls back to a directory of rem's
+// under the system temporary directory.
+func chooseDBPath(args *Args, readOnly bool) (string, dbPathSource, error) {
+	if args != nil && args.DBPath != nil {
+		if env := os.Getenv("REM_DB_PATH"); env != "" && env == *args.DBPath {
+			return *args.DBPath, sourceFlagEnv, nil
+		}
+		return *args.DBPath, sourceFlag, nil
+	}
+	if path := os.Getenv(RemDBEnv); path != "" {
+		return path, sourceEnv, nil
+	}
+
+	// Use the platform default, moving an old database there if needed
+	dbPath, err := defaultDBPath(readOnly)
+	if err != nil {
+		if _, homeErr := os.UserHomeDir(); homeErr != nil {
+			return fallbackDBPath(), sourceFallback, nil
+		}
+		return "", "", err
+	}
+	return dbPath, sourceDefault, nil
+}
+
+// fallbackDBPath is the database used when there is no home directory, as
+// for a systemd service or in a minimal container
+func fallbackDBPath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("rem-%d", os.Getuid()), "rem.db")
+}
+
+// unwritableDBDir explains that the database can't be created in dir, and
+// how to put it somewhere else
+func unwritableDBDir(dir string, err error) error {
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		err = fmt.Errorf("%w (errno %d)", err, int(errno))
+	}
+	return fmt.Errorf("database directory %s is not writable: %w; choose another location with --db-path or %s", dir, err, RemDBEnv)
+}
+
+// defaultDBPath returns the platform default database path, first moving a
+// database from the legacy ~/.config/rem location to it. If the move fails,
+// or in read-only mode, a legacy database stays in use where it is.
+func defaultDBPath(readOnly bool) (string, error) {
+	dbPath, err := config.DefaultDBPath()
+	if err != nil {
+		return "", err
+	}
+	legacyDir, err := config.LegacyDir()
+	if err != nil {
+		// Without a home directory there is no legacy database to move
+		return dbPath, nil
+	}
+	legacyPath := filepath.Join(legacyDir, "rem.db")
+
+	if readOnly {
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			if _, err := os.Stat(legacyPath); err == nil {
+				return legacyPath, nil
+			}
+		}
+		return dbPath, nil
+	}
+
+	moved, err := config.MigrateLegacyDB(dbPath)
+	switch {
+	case err != nil && !moved:
+		fmt.Fprintf(os.Stderr, "Warning: could not move the database to %s: %v\n", dbPath, err)
+		return legacyPath, nil
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	case moved:
+		fmt.Fprintf(os.Stderr, "Moved database from %s to %s\n", legacyPath, dbPath)
+	}
+	return dbPath, nil
+}
+
 // Execute runs the CLI command based on parsed arguments
 func (c *CLI) Execute(args *Args) error {
 	if err := args.Validate(); err != nil {
 		return err
 	}
+	if args.ExpectGeneration != nil {
+		if err := c.checkGeneration(*args.ExpectGeneration); err != nil {
+			return err
+		}
+	}
+	c.utc, c.location = args.UTC, nil
+	c.setAuditSource(args.auditSource())
 
 	switch {
 	case args.Store != nil:
@@ -98,6 +325,38 @@ func (c *CLI) Execute(args *Args) error {
 		return c.executeClear(args.Clear)
 	case args.Search != nil:
 		return c.executeSearch(args.Search)
+	case args.List != nil:
+		return c.executeList(args.List)
+	case args.Title != nil:
+		return c.executeTitle(args.Title)
+	case args.Note != nil:
+		return c.executeNote(args.Note)
+	case args.Edit != nil:
+		return c.executeEdit(args.Edit)
+	case args.Dedupe != nil:
+		return c.executeDedupe(args.Dedupe)
+	case args.Tail != nil:
+		return c.executeTail(args.Tail)
+	case args.Meta != nil:
+		return c.executeMeta(args.Meta)
+	case args.Audit != nil:
+		return c.executeAudit(args.Audit)
+	case args.Sync != nil:
+		return c.executeSync(args.Sync)
+	case args.Backup != nil:
+		return c.executeBackup(args.Backup)
+	case args.Stats != nil:
+		return c.executeStats(args.Stats)
+	case args.Info != nil:
+		return c.executeInfo(args.Info)
+	case args.Bump != nil:
+		return c.executeBump(args.Bump)
+	case args.Version != nil:
+		return ExecuteVersion(args, os.Stdout)
+	case args.Doctor != nil:
+		return ExecuteDoctor(args, os.Stdout)
+	case args.Maintenance != nil:
+		return c.executeMaintenance(args.Maintenance)
 	default:
 		// Default behavior: launch TUI
 		return c.launchTUI()
@@ -106,70 +365,413 @@ func (c *CLI) Execute(args *Args) error {
 
 // executeStore handles the 'rem store' command
 func (c *CLI) executeStore(cmd *StoreCmd) error {
-	// Get title if provided
-	var title string
-	if cmd.Title != nil {
-		title = *cmd.Title
+	// Read before the content, so a missing title source consumes no input
+	title, err := c.storeTitle(cmd)
+	if err != nil {
+		return err
+	}
+
+	filters, err := c.storeFilters(cmd)
+	if err != nil {
+		return err
+	}
+	var tmpl *template.Template
+	if !cmd.Recursive {
+		// Parsed before reading, so a bad template consumes no input
+		if tmpl, err = c.titleTemplate(cmd); err != nil {
+			return err
+		}
+	}
+	marker, err := cmd.sectionMarker()
+	if err != nil {
+		return err
+	}
+	if cmd.TitleLine != nil {
+		c.queueManager.SetTitleStrategy(queue.TitleStrategy{Pick: queue.PickLine, Line: *cmd.TitleLine})
+	}
+	// With --tee, stdout carries the input, so the confirmation goes to stderr
+	statusOut := os.Stdout
+	if cmd.Tee {
+		statusOut = os.Stderr
+	}
+	status, err := newStatusLine(statusOut, cmd.Format, cmd.Quiet)
+	if err != nil {
+		return err
 	}
 
 	switch {
 	case cmd.Clipboard:
-		// Read from clipboard
-		content, err := c.readFromClipboard()
+		// Read from clipboard, once a selection still being made settles
+		settle, err := cmd.settle()
+		if err != nil {
+			return err
+		}
+		started := time.Now()
+		data, err := c.readFromClipboard(cmd.AllowEmpty, settle)
 		if err != nil {
 			return fmt.Errorf("failed to read content: %w", err)
 		}
-		item, err := c.queueManager.Enqueue(content, title)
+		if len(data) < cmd.MinLength {
+			fmt.Fprintf(os.Stderr, "Skipped: clipboard content is %d bytes, shorter than --min-length %d\n", len(data), cmd.MinLength)
+			return nil
+		}
+		// Filtered up front, to compare with what an earlier capture stored
+		content, err := io.ReadAll(filter.Chain(bytes.NewReader(data), filters))
 		if err != nil {
-			return fmt.Errorf("failed to store content: %w", err)
+			return fmt.Errorf("failed to read content: %w", err)
 		}
-		fmt.Printf("Stored: %s\n", item.Title)
-		return nil
+		input, sections := withSections(bytes.NewReader(content), marker)
+		if cmd.DryRun {
+			return c.previewStore(input, sections, title, tmpl, "clipboard", filters)
+		}
+		var superseded *store.HistoryItem
+		if settle > 0 {
+			if superseded, err = c.supersededCapture(content, started.Add(-settle)); err != nil {
+				return err
+			}
+		}
+		stored := time.Now()
+		result, err := c.enqueue(input, title, tmpl, "clipboard", nil)
+		if err != nil {
+			return fmt.Errorf("failed to store content: %w", withStoreHint(err))
+		}
+		if err := c.saveSections(result.Item, sections); err != nil {
+			return err
+		}
+		if err := c.markCapture(result.Item, stored); err != nil {
+			return err
+		}
+		replaced, err := c.replaceCapture(superseded, result.Item)
+		if err != nil {
+			return err
+		}
+		return status.print(result.Item, 0, "Stored from clipboard (%d bytes): %s%s%s\n", result.Item.Size, result.Item.Title, replaced, evictionNote(result.Evicted))
+
+	case cmd.Recursive:
+		return c.executeStoreRecursive(cmd, filters, status)
 
 	case len(cmd.Files) > 0:
 		// Read from files
-		for _, filename := range cmd.Files {
+		for i, filename := range cmd.Files {
 			content, err := c.readFromFile(filename)
 			if err != nil {
 				return fmt.Errorf("failed to read file %s: %w", filename, err)
 			}
-			item, err := c.queueManager.Enqueue(content, title)
+			if cmd.DryRun {
+				if i > 0 {
+					fmt.Println()
+				}
+				input, sections := withSections(filter.Chain(content, filters), marker)
+				err := c.previewStore(input, sections, title, tmpl, filename, filters)
+				content.Close()
+				if err != nil {
+					return fmt.Errorf("failed to read file %s: %w", filename, err)
+				}
+				continue
+			}
+			// Recorded so 'rem get --restore' can recreate the file
+			info, err := content.Stat()
+			if err != nil {
+				content.Close()
+				return fmt.Errorf("failed to read file %s: %w", filename, err)
+			}
+			input, sections := withSections(filter.Chain(content, filters), marker)
+			result, err := c.enqueue(input, title, tmpl, filename, info)
 			content.Close() // Close file handle after enqueue
 			if err != nil {
-				return fmt.Errorf("failed to store content from %s: %w", filename, err)
+				return fmt.Errorf("failed to store content from %s: %w", filename, withStoreHint(err))
+			}
+			if err := c.saveSections(result.Item, sections); err != nil {
+				return err
+			}
+			if err := status.print(result.Item, 0, "Stored from %s: %s%s\n", filename, result.Item.Title, evictionNote(result.Evicted)); err != nil {
+				return err
 			}
-			fmt.Printf("Stored from %s: %s\n", filename, item.Title)
 		}
 		return nil
 
+	case cmd.Tee:
+		return c.executeStoreTee(cmd, title, tmpl, filters, marker, status)
+
 	default:
 		// Read from stdin
-		content, err := c.readFromStdin()
+		content, err := c.readFromStdin(cmd.AllowEmpty)
 		if err != nil {
 			return fmt.Errorf("failed to read content: %w", err)
 		}
-		item, err := c.queueManager.Enqueue(content, title)
+		input, sections := withSections(filter.Chain(content, filters), marker)
+		if cmd.DryRun {
+			return c.previewStore(input, sections, title, tmpl, "stdin", filters)
+		}
+		result, err := c.enqueue(input, title, tmpl, "stdin", nil)
 		if err != nil {
-			return fmt.Errorf("failed to store content: %w", err)
+			return fmt.Errorf("failed to store content: %w", withStoreHint(err))
 		}
-		fmt.Printf("Stored: %s\n", item.Title)
-		return nil
+		if err := c.saveSections(result.Item, sections); err != nil {
+			return err
+		}
+		return status.print(result.Item, 0, "Stored: %s%s\n", result.Item.Title, evictionNote(result.Evicted))
+	}
+}
+
+// executeStoreTee stores stdin while copying it to stdout as it is read.
+// Filters apply to the stored copy only, and the confirmation goes to
+// stderr so stdout carries exactly the input.
+func (c *CLI) executeStoreTee(cmd *StoreCmd, title string, tmpl *template.Template, filters []filter.Filter, marker *regexp.Regexp, status statusLine) error {
+	echo := &echoWriter{w: os.Stdout}
+	input := bufio.NewReader(io.TeeReader(os.Stdin, echo))
+	if _, err := input.Peek(1); err == io.EOF && !cmd.AllowEmpty {
+		return fmt.Errorf("failed to read content: no input provided (use --allow-empty to store empty content)")
+	}
+
+	content, sections := withSections(filter.Chain(input, filters), marker)
+	result, err := c.enqueue(content, title, tmpl, "stdin", nil)
+	if err != nil {
+		// Pass the rest through anyway, so the pipeline still gets it all
+		io.Copy(io.Discard, input)
+		return fmt.Errorf("failed to store content: %w", withStoreHint(err))
+	}
+	if err := c.saveSections(result.Item, sections); err != nil {
+		return err
+	}
+	return status.print(result.Item, 0, "Stored: %s%s\n", result.Item.Title, evictionNote(result.Evicted))
+}
+
+// evictionListMax is how many evicted titles a store confirmation names
+const evictionListMax = 3
+
+// evictionNote describes the items a store pushed out of the queue, such
+// as " (evicted 1 oldest item: notes)", or is empty if there were none
+func evictionNote(evicted []queue.EvictedInfo) string {
+	if len(evicted) == 0 {
+		return ""
+	}
+	return " (evicted " + evictionList(evicted) + ")"
+}
+
+// evictionList describes evicted items, such as "1 oldest item: notes"
+func evictionList(evicted []queue.EvictedInfo) string {
+	noun := "items"
+	if len(evicted) == 1 {
+		noun = "item"
 	}
+	titles := make([]string, 0, evictionListMax)
+	for _, info := range evicted[:min(len(evicted), evictionListMax)] {
+		titles = append(titles, info.Title)
+	}
+	list := strings.Join(titles, ", ")
+	if len(evicted) > evictionListMax {
+		list += fmt.Sprintf(", and %d more", len(evicted)-evictionListMax)
+	}
+	return fmt.Sprintf("%d oldest %s: %s", len(evicted), noun, list)
+}
+
+// echoWriter writes to w until a write fails, after which it discards
+// everything, so a reader that exits early doesn't stop the store
+type echoWriter struct {
+	w   io.Writer
+	err error
 }
 
+func (e *echoWriter) Write(p []byte) (int, error) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+	return len(p), nil
+}
+
+// storeFilters returns the configured default_filters followed by the
+// filters given with --filter
+func (c *CLI) storeFilters(cmd *StoreCmd) ([]filter.Filter, error) {
+	var filters []filter.Filter
+	if value, err := c.store.Config().Get("default_filters"); err == nil {
+		defaults, err := filter.ParseList(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default_filters (fix with 'rem config set'): %w", err)
+		}
+		filters = append(filters, defaults...)
+	}
+
+	extra, err := filter.ParseChain(cmd.Filters)
+	if err != nil {
+		return nil, err
+	}
+	return append(filters, extra...), nil
+}
+
+// checkGeneration fails with ErrQueueChanged unless the queue is still at
+// generation want. Indexes are only safe to use from a listing of the same
+// generation; another process can still change the queue after the check.
+func (c *CLI) checkGeneration(want uint64) error {
+	generation, err := c.store.History().Generation()
+	if err != nil {
+		return fmt.Errorf("failed to get the queue generation: %w", err)
+	}
+	if generation != want {
+		return fmt.Errorf("%w (generation %d, expected %d); list it again", ErrQueueChanged, generation, want)
+	}
+	return nil
+}
+
+// withStoreHint adds a suggestion for recovering from a failed enqueue
+func withStoreHint(err error) error {
+	if errors.Is(err, store.ErrDiskFull) {
+		return fmt.Errorf("%w; free up disk space or remove old items with 'rem clear' or a lower history_limit", err)
+	}
+	if errors.Is(err, memstore.ErrStoreFull) {
+		return fmt.Errorf("%w; a %s database holds at most %s, so use a file with --db-path for more", err, MemoryDBPath, formatSize(MemoryDBMaxBytes))
+	}
+	return err
+}
+
+// errGetNeedsIndex is returned by 'rem get' without an index when neither
+// stdin nor stdout is a terminal
+var errGetNeedsIndex = errors.New("rem get needs an index when not run in a terminal: 'rem get 0' prints the newest item, and 'rem list' shows the others")
+
 // executeGet handles the 'rem get' command
 func (c *CLI) executeGet(cmd *GetCmd) error {
-	if cmd.Index == nil {
+	if cmd.Index == nil && cmd.Match == nil {
 		// No index specified, launch TUI
+		switch {
+		case cmd.NoTUI:
+			return c.executePlainPicker(cmd)
+		case !isTerminal(os.Stdout) && !isTerminal(os.Stdin):
+			// Nobody is there to pick, and the TUI would garble the output
+			return errGetNeedsIndex
+		case !tuiSupported():
+			return c.executePlainPicker(cmd)
+		}
 		return c.launchTUI()
 	}
 
-	index := *cmd.Index
+	if cmd.Match != nil {
+		item, err := c.findLatestMatch(cmd)
+		if err != nil {
+			return err
+		}
+		return c.writeGetOutput(cmd, item, c.indexOf(item.ID))
+	}
+
+	item, err := c.resolveItem(cmd.Index, nil)
+	if err != nil {
+		return err
+	}
+	index := cmd.Index.Index
+	if cmd.Index.Ref != nil {
+		index = c.indexOf(item.ID)
+	}
+	return c.writeGetOutput(cmd, item, index)
+}
 
-	// Get item from queue
+// resolveItem returns the item selected by id when given, or else by arg:
+// the item an @reference names, or the item at a queue index
+func (c *CLI) resolveItem(arg *ItemArg, id *uint) (*store.HistoryItem, error) {
+	switch {
+	case id != nil:
+		item, err := c.store.History().Get(*id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item %d: %w", *id, err)
+		}
+		return item, nil
+	case arg.Ref != nil:
+		item, err := c.store.History().Get(*arg.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item @%s: %w", ref.Encode(*arg.Ref), err)
+		}
+		return item, nil
+	}
+	return c.resolveIndex(arg.Index)
+}
+
+// indexHintItems is how many of the oldest items an out-of-range index
+// lists as the nearest valid ones
+const indexHintItems = 3
+
+// resolveIndex returns the item at index. An index past the end of the
+// queue is reported with the valid range and, on a terminal, the nearest
+// items; for an empty queue, with how to add some.
+func (c *CLI) resolveIndex(index int) (*store.HistoryItem, error) {
 	item, err := c.queueManager.Get(index)
+	var rangeErr *queue.IndexError
+	switch {
+	case errors.As(err, &rangeErr):
+		if rangeErr.Size == 0 {
+			printEmptyQueueHelp(os.Stderr)
+		} else if isTerminal(os.Stderr) {
+			if items, listErr := c.queueManager.List(); listErr == nil {
+				writeIndexHint(os.Stderr, items)
+			}
+		}
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("failed to get item at index %d: %w", index, err)
+	}
+	return item, nil
+}
+
+// writeIndexHint lists the oldest of items, the ones nearest an index past
+// the end of the queue
+func writeIndexHint(w io.Writer, items []*store.HistoryItem) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Oldest items:")
+	for index := max(len(items)-indexHintItems, 0); index < len(items); index++ {
+		fmt.Fprintf(w, "  %d: %s\n", index, items[index].Title)
+	}
+}
+
+// printEmptyQueueHelp tells the user how to add items to an empty queue
+func printEmptyQueueHelp(w io.Writer) {
+	fmt.Fprintln(w, "Queue is empty!")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "To add items to the queue:")
+	fmt.Fprintf(w, "  echo \"Hello World\" | rem store\n")
+	fmt.Fprintf(w, "  rem store filename.txt\n")
+	fmt.Fprintf(w, "  rem store -c  # from clipboard\n")
+}
+
+// findLatestMatch returns the newest item matching cmd.Match. Callers fetch
+// its content by ID, so items stored after the search don't shift the result.
+func (c *CLI) findLatestMatch(cmd *GetCmd) (*store.HistoryItem, error) {
+	results, err := c.store.History().Search(&store.SearchQuery{
+		Pattern:       *cmd.Match,
+		SearchTitle:   cmd.MatchTitle,
+		SearchContent: cmd.MatchContent,
+		Normalize:     true,
+		Limit:         1,
+		OrderBy:       store.OrderNewest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no items match %q: %w", *cmd.Match, ErrNotFound)
+	}
+	return results[0], nil
+}
+
+// indexOf returns the current queue index of the item with the given ID, or
+// -1 if it is no longer in the queue
+func (c *CLI) indexOf(id uint) int {
+	items, err := c.queueManager.List()
+	if err != nil {
+		return -1
+	}
+	for index, item := range items {
+		if item.ID == id {
+			return index
+		}
+	}
+	return -1
+}
+
+// writeGetOutput writes an item to the destination selected by cmd. The
+// content is read by ID; index is only used for notes and JSON output.
+func (c *CLI) writeGetOutput(cmd *GetCmd, item *store.HistoryItem, index int) error {
+	status, err := newStatusLine(os.Stdout, cmd.Format, cmd.Quiet)
 	if err != nil {
-		return fmt.Errorf("failed to get item at index %d: %w", index, err)
+		return err
 	}
 
 	// Get content reader using ID
@@ -179,29 +781,80 @@ func (c *CLI) executeGet(cmd *GetCmd) error {
 	}
 	defer reader.Close()
 
+	// Empty output is easy to mistake for a missing item
+	if item.Size == 0 && cmd.Verbose {
+		fmt.Fprintf(os.Stderr, "Note: item %d (%s) is empty (0 bytes)\n", index, item.Title)
+	}
+
+	// A section is read straight from its offset in the stored content
+	var content io.Reader = reader
+	size := item.Size
+	if cmd.Section != nil {
+		section, err := c.findSection(item, *cmd.Section)
+		if err != nil {
+			return err
+		}
+		if _, err := reader.Seek(section.Start, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek to section %s: %w", section.Name, err)
+		}
+		content, size = io.LimitReader(reader, section.Size()), section.Size()
+	}
+
 	switch {
+	case cmd.Restore:
+		return restoreFile(item, index, reader, status)
+	case cmd.Open:
+		return c.openItem(item, index, reader, status)
+	case cmd.JSON:
+		maxBytes := defaultJSONMaxBytes
+		if cmd.MaxBytes != nil {
+			maxBytes = *cmd.MaxBytes
+		}
+		return writeItemJSON(os.Stdout, item, index, reader, maxBytes)
 	case cmd.Clipboard:
-		// Copy to clipboard - stream directly without reading into memory
-		return c.writeToClipboard(reader, item.Title)
-	case cmd.File != nil:
+		if !cmd.Force && c.overwriteConfirm() {
+			ok, err := c.confirmClipboardOverwrite(item.SHA256, os.Stdin, isTerminal(os.Stdin))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if !cmd.Quiet {
+					fmt.Println("Kept the clipboard.")
+				}
+				return nil
+			}
+		}
+		// Copy to clipboard, refusing items too large for it
+		written, err := c.writeToClipboard(content, size)
+		if err != nil {
+			return err
+		}
+		// Failing to record the copy only loses its mark in the viewer, as
+		// with --read-only
+		c.recordCopies([]uint{item.ID})
+		return status.print(item, index, "Copied %d bytes to clipboard: %s\n", written, c.truncatePreview(item.Title))
+	case cmd.outputFile() != nil:
 		// Stream to file
-		outFile, err := os.Create(*cmd.File)
+		path := *cmd.outputFile()
+		outFile, err := os.Create(path)
 		if err != nil {
 			return fmt.Errorf("failed to create file: %w", err)
 		}
 		defer outFile.Close()
 
-		_, err = io.Copy(outFile, reader)
+		_, err = io.Copy(outFile, content)
 		if err != nil {
 			return fmt.Errorf("failed to write to file: %w", err)
 		}
 
 		// Display title
-		fmt.Printf("Written to %s: %s\n", *cmd.File, item.Title)
-		return nil
+		return status.print(item, index, "Written to %s: %s\n", path, item.Title)
 	default:
 		// Stream to stdout
-		_, err = io.Copy(os.Stdout, reader)
+		err := copyToStdout(content)
+		if errors.Is(err, ErrOutputClosed) && cmd.Verbose {
+			fmt.Fprintf(os.Stderr, "Note: output closed before item %d (%s) was fully written\n", index, item.Title)
+		}
 		return err
 	}
 }
@@ -222,36 +875,74 @@ func (c *CLI) executeConfig(cmd *ConfigCmd) error {
 
 // executeConfigGet handles the 'rem config get' command
 func (c *CLI) executeConfigGet(cmd *ConfigGetCmd) error {
-	value, err := c.store.Config().Get(cmd.Key)
+	key, err := lookupConfigKey(cmd.Key)
+	if err != nil {
+		return err
+	}
+
+	// db_path is computed, not stored
+	if key.name == "db_path" {
+		fmt.Println(c.dbPath)
+		return nil
+	}
+	// The generation is 0 until something changes, and memory stores keep it elsewhere
+	if key.name == "generation" {
+		generation, err := c.store.History().Generation()
+		if err != nil {
+			return fmt.Errorf("failed to get config value: %w", err)
+		}
+		fmt.Println(generation)
+		return nil
+	}
+
+	value, err := c.store.Config().Get(key.name)
 	if err != nil {
 		return fmt.Errorf("failed to get config value: %w", err)
 	}
 
-	fmt.Printf("%s\n", value)
+	fmt.Printf("%s\n", normalizeConfigValue(key.name, value))
 	return nil
 }
 
 // executeConfigSet handles the 'rem config set' command
 func (c *CLI) executeConfigSet(cmd *ConfigSetCmd) error {
-	// Validate the value based on the key
-	switch cmd.Key {
-	case "history_limit":
-		// Validate it's a positive integer
-		if limit, err := strconv.Atoi(cmd.Value); err != nil || limit <= 0 {
-			return fmt.Errorf("history_limit must be a positive integer")
-		}
-	case "show_binary":
-		// Validate it's a boolean
-		if cmd.Value != "true" && cmd.Value != "false" {
-			return fmt.Errorf("show_binary must be 'true' or 'false'")
-		}
+	key, err := lookupConfigKey(cmd.Key)
+	if err != nil {
+		return err
+	}
+	if err := key.check(c, cmd.Value); err != nil {
+		return err
 	}
 
-	if err := c.store.Config().Set(cmd.Key, cmd.Value); err != nil {
+	value := normalizeConfigValue(key.name, cmd.Value)
+	if err := c.store.Config().Set(key.name, value); err != nil {
 		return fmt.Errorf("failed to set config value: %w", err)
 	}
 
-	fmt.Printf("Set %s = %s\n", cmd.Key, cmd.Value)
+	fmt.Printf("Set %s = %s\n", key.name, value)
+	if key.name == "history_limit" {
+		return c.printHistoryLimitEffect(value)
+	}
+	return nil
+}
+
+// printHistoryLimitEffect reports how many items are stored and how many
+// the new history limit will remove on the next store
+func (c *CLI) printHistoryLimitEffect(value string) error {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	count, err := c.store.History().Count()
+	if err != nil {
+		return fmt.Errorf("failed to count items: %w", err)
+	}
+
+	if excess := count - limit; excess > 0 {
+		fmt.Printf("%d item(s) stored; the oldest %d will be removed on the next store\n", count, excess)
+	} else {
+		fmt.Printf("%d item(s) stored, within the limit\n", count)
+	}
 	return nil
 }
 
@@ -263,21 +954,118 @@ func (c *CLI) executeConfigList(cmd *ConfigListCmd) error {
 	}
 
 	fmt.Printf("Current configuration:\n")
-	for key, value := range values {
-		fmt.Printf("  %s = %s\n", key, value)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range configKeys {
+		value, ok := values[key.name]
+		switch key.name {
+		case "db_path":
+			value, ok = c.dbPath, true
+		case "generation":
+			generation, err := c.store.History().Generation()
+			if err != nil {
+				return fmt.Errorf("failed to get the queue generation: %w", err)
+			}
+			value, ok = strconv.FormatUint(generation, 10), true
+		}
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "  %s = %s\t%s# %s\n", key.name, normalizeConfigValue(key.name, value), c.configChanged(cmd, key.name), key.description)
+		delete(values, key.name)
 	}
-	return nil
+	// Keys stored by other versions of rem, which have no description here
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		fmt.Fprintf(w, "  %s = %s\t%s\n", key, values[key], c.configChanged(cmd, key))
+	}
+	return w.Flush()
+}
+
+// configChanged returns the column rem config list --verbose shows when
+// key was last set, or "" without --verbose
+func (c *CLI) configChanged(cmd *ConfigListCmd, key string) string {
+	if !cmd.Verbose {
+		return ""
+	}
+	_, updatedAt, err := c.store.Config().GetWithMeta(key)
+	if err != nil {
+		// Not stored, like db_path
+		return "\t"
+	}
+	return "changed " + c.formatTime(updatedAt, timefmt.Minute) + "\t"
 }
 
-// launchTUI starts the interactive TUI
+// launchTUI starts the interactive TUI with the newest tui_initial_items
+// items; the rest are loaded a page at a time as the cursor nears them
 func (c *CLI) launchTUI() error {
-	// Get items from queue
-	queueItems, err := c.queueManager.List()
+	pageSize := tui.DefaultPageSize
+	if value, err := c.store.Config().Get("tui_initial_items"); err == nil {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			pageSize = n
+		}
+	}
+	total, err := c.queueManager.CountWith(queue.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("error counting queue items: %w", err)
+	}
+	queueItems, err := c.queueManager.ListPage(nil, min(pageSize, total), queue.ListOptions{})
 	if err != nil {
 		return fmt.Errorf("error listing queue items: %w", err)
 	}
+	tuiItems := c.stackItems(queueItems)
+
+	// If no items in queue, show a helpful message
+	if len(tuiItems) == 0 {
+		printEmptyQueueHelp(os.Stdout)
+		return nil
+	}
 
-	// Convert queue items to TUI items
+	return c.runTUI(tuiItems, TUIOptions{
+		LoadMore: c.loadMore(len(queueItems), total),
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// loadMore returns a tui.LoadMoreFunc paging through the queue after the
+// first loaded items, stopping at total like the history limit does
+func (c *CLI) loadMore(loaded, total int) tui.LoadMoreFunc {
+	return func(afterTimestamp time.Time, afterID uint, limit int) ([]*tui.StackItem, error) {
+		after := &store.IterCursor{Timestamp: afterTimestamp, ID: afterID}
+		queueItems, err := c.queueManager.ListPage(after, min(limit, total-loaded), queue.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		loaded += len(queueItems)
+		// Content is opened through the TUI's ItemOps when an item is shown
+		items := make([]*tui.StackItem, len(queueItems))
+		for i, item := range queueItems {
+			items[i] = stackItem(item, nil)
+		}
+		return items, nil
+	}
+}
+
+// TUIOptions customizes a TUI session started by runTUI
+type TUIOptions struct {
+	Title         string // left pane heading; "" for "Queue"
+	SearchPattern string // pattern already searched for when the TUI opens
+
+	// LoadMore, if set, loads the items after the ones given to runTUI
+	// PageSize at a time; Total is how many items there are in all
+	LoadMore tui.LoadMoreFunc
+	PageSize int
+	Total    int
+}
+
+// runProgram runs the TUI until it quits, restoring the terminal even if it
+// crashes; tests replace it to drive the model without a terminal
+var runProgram = func(model tea.Model) (tea.Model, error) {
+	return tui.Run(model, tea.WithAltScreen())
+}
+
+// stackItems converts queue items to TUI items with open content readers,
+// skipping items whose content can't be opened
+func (c *CLI) stackItems(queueItems []*store.HistoryItem) []*tui.StackItem {
 	var tuiItems []*tui.StackItem
 	for _, item := range queueItems {
 		// Get content reader using ID
@@ -287,71 +1075,157 @@ func (c *CLI) launchTUI() error {
 			continue
 		}
 
-		// Capture ID for closure
-		itemID := item.ID
-
-		tuiItem := &tui.StackItem{
-			ID:       fmt.Sprintf("%d", itemID),
-			Content:  contentReader,
-			Preview:  item.Title, // Use title as preview
-			ViewPos:  0,
-			IsBinary: item.IsBinary,
-			Size:     item.Size,
-			SHA256:   item.SHA256,
-			DeleteFunc: func() error {
-				// Delete by finding index of item with this ID
-				items, err := c.queueManager.List()
-				if err != nil {
-					return err
-				}
-				for idx, itm := range items {
-					if itm.ID == itemID {
-						return c.queueManager.Delete(idx)
-					}
-				}
-				return fmt.Errorf("item %d not found", itemID)
-			},
+		tuiItems = append(tuiItems, stackItem(item, contentReader))
+	}
+	return tuiItems
+}
+
+// stackItem converts a queue item to a TUI item reading content
+func stackItem(item *store.HistoryItem, content io.ReadSeekCloser) *tui.StackItem {
+	return &tui.StackItem{
+		ID:        item.ID,
+		Content:   content,
+		Preview:   item.Title, // Use title as preview
+		Note:      item.Note,
+		Timestamp: item.Timestamp,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+		ViewPos:   0,
+		IsBinary:  item.IsBinary,
+		Size:      item.Size,
+		SHA256:    item.SHA256,
+	}
+}
+
+// runTUI browses items in the TUI, closing their readers when it quits
+func (c *CLI) runTUI(items []*tui.StackItem, opts TUIOptions) error {
+	model := tui.NewModel(items, c.clipboard)
+	defer model.Close()
+	c.setAuditSource(auditSourceTUI)
+
+	// Build key bindings first so a bad config fails before the screen is taken over
+	configValues, err := c.store.Config().List()
+	if err != nil {
+		return fmt.Errorf("failed to list config values: %w", err)
+	}
+	keys, err := tui.NewKeymap(configValues)
+	if err != nil {
+		return fmt.Errorf("%w (fix with 'rem config set')", err)
+	}
+
+	model.SetClipboardMaxBytes(c.clipboardMaxBytes())
+	model.SetClipboardOverwriteConfirm(configValues["clipboard_overwrite_confirm"] == "true")
+	model.SetKeymap(keys)
+	model.SetWrap(configValues["wrap_default"] != "false")
+	model.SetScrollbar(configValues["scrollbar"] != "false")
+	model.SetDiffColors(configValues["diff_colors"] != "false")
+	model.SetGroupByDate(configValues["group_by_date"] == "true")
+	indicators := tui.IndicatorsText
+	if configValues["icons"] == "nerd" {
+		indicators = tui.IndicatorsNerd
+	}
+	if configValues["show_indicators"] == "false" {
+		indicators = tui.IndicatorsOff
+	}
+	model.SetIndicators(indicators)
+	model.SetA11y(configValues["a11y"] == "true" || os.Getenv("REM_A11Y") == "1")
+	model.SetHints(configValues["show_hints"] != "false")
+	if step, err := strconv.Atoi(configValues["hscroll_step"]); err == nil {
+		model.SetHScrollStep(step)
+	}
+	if width, err := strconv.Atoi(configValues["tab_width"]); err == nil {
+		model.SetTabWidth(width)
+	}
+	model.SetAssumeLatin1(configValues["assume_latin1"] == "true")
+	debounce := tui.DefaultPreviewDebounce
+	if ms, err := strconv.Atoi(configValues["preview_debounce_ms"]); err == nil && ms >= 0 {
+		debounce = time.Duration(ms) * time.Millisecond
+	}
+	model.SetPreviewDebounce(debounce)
+	model.SetLocation(c.timeLocation())
+	model.SetIncrementalSearch(configValues["incremental_search"] != "false")
+	model.SetItemOps(c.queueManager.ByID())
+	model.SetOpener(c.opener.Open)
+	if opts.LoadMore != nil {
+		model.SetLoadMore(opts.LoadMore, opts.PageSize, opts.Total)
+	}
+	model.SetTitle(opts.Title)
+	if opts.SearchPattern != "" {
+		if err := model.SetSearchPattern(opts.SearchPattern); err != nil {
+			return fmt.Errorf("invalid search pattern: %w", err)
 		}
-		tuiItems = append(tuiItems, tuiItem)
 	}
 
-	// If no items in queue, show a helpful message
-	if len(tuiItems) == 0 {
-		fmt.Println("Queue is empty!")
-		fmt.Println()
-		fmt.Println("To add items to the queue:")
-		fmt.Printf("  echo \"Hello World\" | rem store\n")
-		fmt.Printf("  rem store filename.txt\n")
-		fmt.Printf("  rem store -c  # from clipboard\n")
+	saveHistory := configValues["save_search_history"] != "false"
+	if saveHistory {
+		// A bad saved history only loses recall, so it doesn't stop the TUI
+		patterns, _ := parseSearchHistory(configValues["search_history"])
+		model.SetSearchHistory(patterns)
+	}
+	// Like the search history, a bad recent_copies only loses the marks
+	recent, _ := c.loadRecentCopies()
+	model.SetRecentCopies(recent, c.recentCopiesLimit())
+
+	lock, access := c.lockTUI(os.Stdin, isTerminal(os.Stdin))
+	if access == tuiQuit {
 		return nil
 	}
+	if lock != nil {
+		defer lock.Release()
+	}
+	model.SetReadOnly(access == tuiReadOnly)
 
-	model := tui.NewModel(tuiItems, c.clipboard)
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	_, err = p.Run()
+	_, err = runProgram(model)
+	err = explainTUIError(err)
+	if c.readOnly() {
+		// The session's searches and copies can't be saved
+		return err
+	}
+	if saveHistory {
+		if saveErr := c.saveSearchHistory(model.SearchHistory()); err == nil {
+			err = saveErr
+		}
+	}
+	if saveErr := c.recordCopies(model.CopiedIDs()); err == nil {
+		err = saveErr
+	}
 	return err
 }
 
-// readFromClipboard reads content from system clipboard
-func (c *CLI) readFromClipboard() (io.ReadSeeker, error) {
+// readOnly reports whether the store was opened with --read-only
+func (c *CLI) readOnly() bool {
+	ro, ok := c.store.(interface{ ReadOnly() bool })
+	return ok && ro.ReadOnly()
+}
+
+// readFromClipboard reads content from system clipboard once it has held
+// the same content for settle. Empty content is an error unless allowEmpty
+// is set.
+func (c *CLI) readFromClipboard(allowEmpty bool, settle time.Duration) ([]byte, error) {
+	data, err := c.settledClipboard(settle)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 && !allowEmpty {
+		return nil, fmt.Errorf("clipboard is empty (use --allow-empty to store it anyway)")
+	}
+	return data, nil
+}
+
+// readClipboard reads all of the system clipboard's content
+func (c *CLI) readClipboard() ([]byte, error) {
 	reader, err := c.clipboard.Read()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read clipboard: %w", err)
 	}
 	defer reader.Close()
 
-	// Read all content into memory to create a ReadSeeker
-	// This is necessary because we need Seek capability for the queue manager
+	// The bytes are kept as they are so binary content survives intact
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read clipboard content: %w", err)
 	}
-
-	if len(data) == 0 {
-		return nil, fmt.Errorf("clipboard is empty")
-	}
-
-	return strings.NewReader(string(data)), nil
+	return data, nil
 }
 
 // readFromFile reads content from a file
@@ -365,28 +1239,61 @@ func (c *CLI) readFromFile(filename string) (*os.File, error) {
 	return file, nil
 }
 
-// readFromStdin reads content from stdin
-func (c *CLI) readFromStdin() (io.ReadSeeker, error) {
+// readFromStdin reads content from stdin.
+// Empty input is an error unless allowEmpty is set.
+func (c *CLI) readFromStdin(allowEmpty bool) (io.ReadSeeker, error) {
 	data, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(data) == 0 {
-		return nil, fmt.Errorf("no input provided")
+	if len(data) == 0 && !allowEmpty {
+		return nil, fmt.Errorf("no input provided (use --allow-empty to store empty content)")
 	}
 
-	return strings.NewReader(string(data)), nil
+	return bytes.NewReader(data), nil
 }
 
-// writeToClipboard writes content to system clipboard from a reader
-func (c *CLI) writeToClipboard(r io.Reader, preview string) error {
-	if err := c.clipboard.Write(r); err != nil {
-		return fmt.Errorf("failed to write to clipboard: %w", err)
+// writeToClipboard writes size bytes of content to the system clipboard from a reader
+// and returns the number of bytes written
+func (c *CLI) writeToClipboard(r io.Reader, size int64) (int64, error) {
+	maxBytes := c.clipboardMaxBytes()
+	if size > maxBytes {
+		return 0, fmt.Errorf("item is too large for the clipboard (%d bytes, limit %d); save it to a file with 'rem get <index> <file>' instead, or raise clipboard_max_bytes", size, maxBytes)
 	}
 
-	fmt.Printf("Copied to clipboard: %s\n", c.truncatePreview(preview))
-	return nil
+	buf, err := bufferClipboardContent(r, size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read content: %w", err)
+	}
+	written := int64(buf.Len())
+
+	if err := c.clipboard.Write(buf); err != nil {
+		return 0, fmt.Errorf("failed to write to clipboard: %w", err)
+	}
+
+	return written, nil
+}
+
+// clipboardMaxBytes returns the configured clipboard size limit
+func (c *CLI) clipboardMaxBytes() int64 {
+	if limitStr, err := c.store.Config().Get("clipboard_max_bytes"); err == nil {
+		if limit, err := parseSize(limitStr); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return tui.DefaultClipboardMaxBytes
+}
+
+// bufferClipboardContent reads r into a buffer pre-sized for size bytes.
+// The extra bytes.MinRead of capacity keeps ReadFrom from growing the buffer
+// when the content is exactly size bytes long.
+func bufferClipboardContent(r io.Reader, size int64) (*bytes.Buffer, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, size+bytes.MinRead))
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf, nil
 }
 
 // writeToFile writes content to a file
@@ -408,7 +1315,18 @@ func (c *CLI) executeClear(cmd *ClearCmd) error {
 		return fmt.Errorf("failed to list items: %w", err)
 	}
 
+	opts := store.ClearOptions{ResetIDs: cmd.ResetIDs}
 	if len(items) == 0 {
+		if cmd.ResetIDs {
+			if err := c.queueManager.ClearWith(opts); err != nil {
+				return fmt.Errorf("failed to reset item IDs: %w", err)
+			}
+			if err := c.clearRecentCopies(); err != nil {
+				return err
+			}
+			fmt.Println("Queue is already empty; item IDs start from 1 again.")
+			return nil
+		}
 		fmt.Println("Queue is already empty.")
 		return nil
 	}
@@ -426,27 +1344,58 @@ func (c *CLI) executeClear(cmd *ClearCmd) error {
 	}
 
 	// Clear the queue
-	if err := c.queueManager.Clear(); err != nil {
+	if err := c.queueManager.ClearWith(opts); err != nil {
 		return fmt.Errorf("failed to clear history: %w", err)
 	}
+	if cmd.ResetIDs {
+		if err := c.clearRecentCopies(); err != nil {
+			return err
+		}
+	}
 
 	fmt.Printf("Cleared %d item(s) from history.\n", len(items))
+	if cmd.ResetIDs {
+		fmt.Println("Item IDs start from 1 again.")
+	}
 	return nil
 }
 
 // executeSearch handles the 'rem search' command
 func (c *CLI) executeSearch(cmd *SearchCmd) error {
+	order, err := store.ParseSearchOrder(cmd.Order)
+	if err != nil {
+		return err
+	}
+	since, until, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	metaEquals, err := cmd.metaEquals()
+	if err != nil {
+		return err
+	}
+
 	// Build search query
 	searchQuery := &store.SearchQuery{
 		Pattern:       cmd.Pattern,
 		SearchTitle:   cmd.SearchTitle,
 		SearchContent: cmd.SearchContent,
+		SearchNotes:   cmd.SearchNotes,
 		CaseSensitive: cmd.CaseSensitive,
+		Normalize:     !cmd.ExactAccents,
 		Limit:         0, // No limit
+		OrderBy:       order,
+		CountMatches:  cmd.Count,
+		Since:         since,
+		Until:         until,
+		MetaEquals:    metaEquals,
+		// Summary lines say why each item matched; without a pattern
+		// every item in the window matches, so there's nothing to say
+		MatchDetails: (cmd.Count || cmd.TitlesOnly) && cmd.Pattern != "",
 	}
 
 	// If AllMatches is false, limit to 1 result
-	if !cmd.AllMatches {
+	if !cmd.AllMatches && !cmd.TUI {
 		searchQuery.Limit = 1
 	}
 
@@ -457,6 +1406,12 @@ func (c *CLI) executeSearch(cmd *SearchCmd) error {
 	}
 
 	if len(results) == 0 {
+		switch {
+		case cmd.Pattern == "" && metaEquals != nil:
+			return fmt.Errorf("no items found with the given metadata")
+		case cmd.Pattern == "":
+			return fmt.Errorf("no items found in the time window")
+		}
 		return fmt.Errorf("no matches found for pattern: %s", cmd.Pattern)
 	}
 
@@ -472,17 +1427,28 @@ func (c *CLI) executeSearch(cmd *SearchCmd) error {
 		idToIndex[item.ID] = idx
 	}
 
+	if cmd.TUI {
+		return c.searchTUI(cmd, results, idToIndex)
+	}
+
 	// Output results
+	seen := make(map[uint]bool)
 	for i, result := range results {
 		index, ok := idToIndex[result.ID]
-		if !ok {
-			// Item was deleted between search and now
+		if !ok || seen[result.ID] {
+			// Item was deleted between search and now, or already printed
 			continue
 		}
+		seen[result.ID] = true
 
-		if cmd.IndexOnly {
+		switch {
+		case cmd.IndexOnly:
 			fmt.Printf("%d\n", index)
-		} else {
+		case cmd.Count:
+			fmt.Printf("%d\t%d\t%s%s\n", index, result.MatchCount, result.Title, matchSummary(result))
+		case cmd.TitlesOnly:
+			fmt.Printf("%d\t%s%s\n", index, result.Title, matchSummary(result))
+		default:
 			if i > 0 {
 				fmt.Println()
 			}
@@ -491,7 +1457,7 @@ func (c *CLI) executeSearch(cmd *SearchCmd) error {
 			if err != nil {
 				return fmt.Errorf("failed to read content for match %d: %w", i, err)
 			}
-			if _, err := io.Copy(os.Stdout, reader); err != nil {
+			if err := copyToStdout(reader); err != nil {
 				reader.Close()
 				return fmt.Errorf("failed to write content for match %d: %w", i, err)
 			}
@@ -502,17 +1468,199 @@ func (c *CLI) executeSearch(cmd *SearchCmd) error {
 	return nil
 }
 
-// truncatePreview creates a truncated preview of content for display
+// matchSummary returns a tab and the fields result matched in, followed by
+// its first content snippet, such as "\tcontent: the «TODO» list"; "" if
+// the search didn't report them
+func matchSummary(result *store.HistoryItem) string {
+	if len(result.MatchedIn) == 0 {
+		return ""
+	}
+	summary := "\t" + strings.Join(result.MatchedIn, ",")
+	if len(result.Snippets) > 0 {
+		summary += ": " + result.Snippets[0]
+	}
+	return summary
+}
+
+// searchTUI handles 'rem search --tui', browsing the matches still in the
+// queue with the pattern already searched for
+func (c *CLI) searchTUI(cmd *SearchCmd, results []*store.HistoryItem, idToIndex map[uint]int) error {
+	var matched []*store.HistoryItem
+	seen := make(map[uint]bool)
+	for _, result := range results {
+		if _, ok := idToIndex[result.ID]; ok && !seen[result.ID] {
+			seen[result.ID] = true
+			matched = append(matched, result)
+		}
+	}
+	items := c.stackItems(matched)
+	if len(items) == 0 {
+		return fmt.Errorf("no matches found for pattern: %s", cmd.Pattern)
+	}
+
+	opts := TUIOptions{Title: fmt.Sprintf("Search: %s (%d results)", cmd.Pattern, len(items))}
+	if cmd.Pattern == "" {
+		opts.Title = fmt.Sprintf("Search (%d results)", len(items))
+	} else if cmd.CaseSensitive {
+		// The TUI searches case-insensitively unless told otherwise
+		opts.SearchPattern = "(?-i)" + cmd.Pattern
+	} else {
+		opts.SearchPattern = cmd.Pattern
+	}
+	return c.runTUI(items, opts)
+}
+
+// executeList handles the 'rem list' command
+func (c *CLI) executeList(cmd *ListCmd) error {
+	since, until, err := parseTimeWindow(cmd.Since, cmd.Until, time.Now())
+	if err != nil {
+		return err
+	}
+	order, err := cmd.order()
+	if err != nil {
+		return err
+	}
+	minSize, err := cmd.minSize()
+	if err != nil {
+		return err
+	}
+
+	// Read first, so a change during the listing makes the generation stale
+	generation, err := c.store.History().Generation()
+	if err != nil {
+		return fmt.Errorf("failed to get the queue generation: %w", err)
+	}
+	listed, err := c.listInOrder(order)
+	if err != nil {
+		return err
+	}
+
+	showSize := cmd.Size || order.Key == store.SortSize
+	encoder := json.NewEncoder(os.Stdout)
+	for _, row := range listed {
+		item, index := row.item, row.index
+		if !store.InTimeWindow(item.Timestamp, since, until) || item.Size < minSize {
+			continue
+		}
+		var preview string
+		if cmd.Preview {
+			if preview, err = c.contentPreview(item); err != nil {
+				return err
+			}
+		}
+		if cmd.JSON {
+			entry := newItemMetaJSON(item, index)
+			entry.Preview = preview
+			entry.Generation = &generation
+			entry.Sort, entry.Reverse = string(order.Key), order.Reverse
+			if entry.Meta, err = c.store.History().GetMeta(item.ID); err != nil {
+				return fmt.Errorf("failed to get metadata of item %d: %w", item.ID, err)
+			}
+			if err := encoder.Encode(entry); err != nil {
+				return fmt.Errorf("failed to encode item: %w", err)
+			}
+			continue
+		}
+		if showSize {
+			fmt.Printf("%d\t@%s\t%s\t%9s\t%s\n", index, ref.Encode(item.ID), c.formatTime(item.Timestamp, timefmt.Minute), formatSize(item.Size), item.Title)
+		} else {
+			fmt.Printf("%d\t@%s\t%s\t%s\n", index, ref.Encode(item.ID), c.formatTime(item.Timestamp, timefmt.Minute), item.Title)
+		}
+		if cmd.Preview {
+			fmt.Printf("    %s\n", preview)
+		}
+	}
+	return nil
+}
+
+// listPreviewBytes is how much of each item rem list --preview reads
+const listPreviewBytes = 1024
+
+// listPreviewWidth is the most display cells a --preview line takes
+const listPreviewWidth = 76
+
+// contentPreview returns the first non-empty line of item's content, or
+// what a title would say instead, such as [binary content]
+func (c *CLI) contentPreview(item *store.HistoryItem) (string, error) {
+	prefix, err := c.store.History().GetContentPrefix(item.ID, listPreviewBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to read item %d: %w", item.ID, err)
+	}
+	return queue.TruncateTitle(queue.GenerateTitle(prefix, item.IsBinary, queue.TitleStrategy{}), listPreviewWidth), nil
+}
+
+// executeTitle handles the 'rem title' command
+func (c *CLI) executeTitle(cmd *TitleCmd) error {
+	index, title, err := cmd.target()
+	if err != nil {
+		return err
+	}
+
+	item, err := c.resolveItem(index, cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.FromContent {
+		title, err = c.queueManager.TitleFromContent(item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to generate title: %w", err)
+		}
+	}
+
+	updated, err := c.queueManager.RenameByID(item.ID, title)
+	if err != nil {
+		return fmt.Errorf("failed to rename item: %w", err)
+	}
+
+	fmt.Printf("Renamed: %s -> %s\n", item.Title, updated.Title)
+	return nil
+}
+
+// executeNote handles the 'rem note' command
+func (c *CLI) executeNote(cmd *NoteCmd) error {
+	index, note, err := cmd.target()
+	if err != nil {
+		return err
+	}
+
+	item, err := c.resolveItem(index, cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := c.queueManager.SetNoteByID(item.ID, note)
+	if err != nil {
+		return fmt.Errorf("failed to set note: %w", err)
+	}
+
+	if updated.Note == "" {
+		fmt.Printf("Cleared note: %s\n", updated.Title)
+	} else {
+		fmt.Printf("Noted: %s: %s\n", updated.Title, updated.Note)
+	}
+	return nil
+}
+
+// executeBump handles the 'rem bump' command
+func (c *CLI) executeBump(cmd *BumpCmd) error {
+	item, err := c.resolveItem(cmd.Index, cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.queueManager.BumpByID(item.ID); err != nil {
+		return fmt.Errorf("failed to bump item: %w", err)
+	}
+	fmt.Printf("Moved to top: %s\n", item.Title)
+	return nil
+}
+
+// truncatePreview creates a preview of content at most 80 display cells wide
 func (c *CLI) truncatePreview(content string) string {
 	const maxLength = 80
 
 	// Replace newlines with spaces for preview
 	preview := strings.ReplaceAll(content, "\n", " ")
-	preview = strings.TrimSpace(preview)
-
-	if len(preview) <= maxLength {
-		return preview
-	}
-
-	return preview[:maxLength-3] + "..."
+	return queue.TruncateTitle(preview, maxLength)
 }
@@ -1,10 +1,37 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/alexflint/go-arg"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/dbstore"
+	"github.com/yiblet/rem/internal/store/memstore"
+	"github.com/yiblet/rem/internal/tui"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestNewWithArgs_DefaultDB(t *testing.T) {
@@ -33,6 +60,44 @@ func TestNewWithArgs_DefaultDB(t *testing.T) {
 	}
 }
 
+func TestNewWithArgs_XDGDataHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// A database in the legacy location moves to $XDG_DATA_HOME/rem
+	legacy, err := NewWithArgs(nil)
+	if err != nil {
+		t.Fatalf("NewWithArgs failed: %v", err)
+	}
+	if _, err := legacy.queueManager.Enqueue(strings.NewReader("kept"), "kept"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	legacy.store.Close()
+
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	cli, err := NewWithArgs(nil)
+	if err != nil {
+		t.Fatalf("NewWithArgs failed: %v", err)
+	}
+	defer cli.store.Close()
+
+	want := filepath.Join(home, "data", "rem", "rem.db")
+	out := withStdout(t, func() {
+		if err := cli.executeConfigGet(&ConfigGetCmd{Key: "db-path"}); err != nil {
+			t.Errorf("config get db-path failed: %v", err)
+		}
+	})
+	if out != want+"\n" {
+		t.Errorf("Expected db-path %s, got %q", want, out)
+	}
+	if size, _ := cli.queueManager.Size(); size != 1 {
+		t.Errorf("Expected the migrated database to keep its item, got %d items", size)
+	}
+	if err := (&ConfigSetCmd{Key: "db-path", Value: "/tmp/x.db"}).Validate(); err == nil {
+		t.Error("Expected db-path to be read-only")
+	}
+}
+
 func TestNewWithArgs_CustomDBPath(t *testing.T) {
 	// Create temporary directory for custom database
 	tempDir := t.TempDir()
@@ -59,6 +124,198 @@ func TestNewWithArgs_CustomDBPath(t *testing.T) {
 	}
 }
 
+func TestNewWithArgs_MemoryDB(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	memory := MemoryDBPath
+	cli, err := NewWithArgs(&Args{DBPath: &memory})
+	if err != nil {
+		t.Fatalf("NewWithArgs(:memory:) failed: %v", err)
+	}
+	defer cli.store.Close()
+
+	item, err := cli.queueManager.Enqueue(strings.NewReader("scratch"), "")
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if got := readItem(t, cli, item.ID); got != "scratch" {
+		t.Errorf("expected the stored item back, got %q", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no files written, found %v", entries)
+	}
+}
+
+func TestNewWithArgs_NoHome(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("REM_DB_PATH", "")
+	t.Setenv(RemDBEnv, "")
+	t.Setenv("TMPDIR", tmp)
+
+	// Without HOME the database goes under the temporary directory, loudly
+	var cli *CLI
+	stderr := withStderr(t, func() {
+		var err error
+		if cli, err = NewWithArgs(nil); err != nil {
+			t.Fatalf("NewWithArgs without HOME failed: %v", err)
+		}
+	})
+	fallback := filepath.Join(tmp, fmt.Sprintf("rem-%d", os.Getuid()), "rem.db")
+	if cli.dbPath != fallback {
+		t.Errorf("Expected the fallback database %s, got %s", fallback, cli.dbPath)
+	}
+	cli.store.Close()
+	if !strings.Contains(stderr, "HOME is not set") || !strings.Contains(stderr, fallback) || !strings.Contains(stderr, "REM_DB") {
+		t.Errorf("Expected a warning naming the fallback, got %q", stderr)
+	}
+
+	// A fallback directory others can reach, or a symlink, is refused
+	if err := os.Chmod(filepath.Dir(fallback), 0755); err != nil {
+		t.Fatalf("chmod failed: %v", err)
+	}
+	withStderr(t, func() {
+		if _, err := NewWithArgs(nil); err == nil || !strings.Contains(err.Error(), "0700") {
+			t.Errorf("Expected a fallback directory with mode 0755 to be refused, got %v", err)
+		}
+	})
+	if err := os.RemoveAll(filepath.Dir(fallback)); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if err := os.Symlink(t.TempDir(), filepath.Dir(fallback)); err != nil {
+		t.Fatalf("symlink failed: %v", err)
+	}
+	withStderr(t, func() {
+		if _, err := NewWithArgs(nil); err == nil || !strings.Contains(err.Error(), "not a directory") {
+			t.Errorf("Expected a symlinked fallback directory to be refused, got %v", err)
+		}
+	})
+
+	// XDG_DATA_HOME needs no home directory, so there's nothing to warn about
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	stderr = withStderr(t, func() {
+		var err error
+		if cli, err = NewWithArgs(nil); err != nil {
+			t.Fatalf("NewWithArgs with XDG_DATA_HOME failed: %v", err)
+		}
+	})
+	if want := filepath.Join(tmp, "data", "rem", "rem.db"); cli.dbPath != want {
+		t.Errorf("Expected %s, got %s", want, cli.dbPath)
+	}
+	cli.store.Close()
+	if stderr != "" {
+		t.Errorf("Expected no warning, got %q", stderr)
+	}
+}
+
+func TestNewWithArgs_RemDBEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", "")
+	t.Setenv("REM_DB_PATH", "")
+	envPath := filepath.Join(dir, "env", "rem.db")
+	t.Setenv(RemDBEnv, envPath)
+
+	cli, err := NewWithArgs(nil)
+	if err != nil {
+		t.Fatalf("NewWithArgs with REM_DB failed: %v", err)
+	}
+	cli.store.Close()
+	if cli.dbPath != envPath {
+		t.Errorf("Expected REM_DB's %s, got %s", envPath, cli.dbPath)
+	}
+
+	// The flag wins over REM_DB
+	flagPath := filepath.Join(dir, "flag.db")
+	if path, source, _ := chooseDBPath(&Args{DBPath: &flagPath}, false); path != flagPath || source != sourceFlag {
+		t.Errorf("chooseDBPath() = %s, %s, want the flag's %s", path, source, flagPath)
+	}
+	if path, source, _ := chooseDBPath(nil, false); path != envPath || source != sourceEnv {
+		t.Errorf("chooseDBPath() = %s, %s, want REM_DB's %s", path, source, envPath)
+	}
+
+	// rem doctor says where the path came from
+	var out bytes.Buffer
+	ExecuteDoctor(&Args{}, &out)
+	if !strings.Contains(out.String(), "(from REM_DB)") {
+		t.Errorf("Expected doctor to name REM_DB as the source:\n%s", out.String())
+	}
+}
+
+func TestNewWithArgs_UnwritableDir(t *testing.T) {
+	// A file where the directory should be fails the same way for root
+	home := filepath.Join(t.TempDir(), "home")
+	if err := os.WriteFile(home, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("REM_DB_PATH", "")
+	t.Setenv(RemDBEnv, "")
+
+	_, err := NewWithArgs(nil)
+	if err == nil {
+		t.Fatal("Expected an error for a database directory under a file")
+	}
+	for _, want := range []string{filepath.Join(home, ".config", "rem"), "not a directory", "errno", "--db-path", "REM_DB"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestNewWithArgs_DBPathChecks(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	// A relative path that climbs out needs --unsafe-db-path
+	escaping := filepath.Join("..", filepath.Base(dir)+"-other", "rem.db")
+	if _, err := NewWithArgs(&Args{DBPath: &escaping}); err == nil || !strings.Contains(err.Error(), "--unsafe-db-path") {
+		t.Errorf("expected a path climbing out with .. to be rejected, got %v", err)
+	}
+	inside := filepath.Join("sub", "..", "rem.db")
+	if cli, err := NewWithArgs(&Args{DBPath: &inside}); err != nil {
+		t.Errorf("expected a .. that stays inside to be allowed, got %v", err)
+	} else {
+		cli.store.Close()
+	}
+	var cli *CLI
+	stderr := withStderr(t, func() {
+		var err error
+		cli, err = NewWithArgs(&Args{DBPath: &escaping, UnsafeDBPath: true})
+		if err != nil {
+			t.Fatalf("expected --unsafe-db-path to allow it, got %v", err)
+		}
+	})
+	cli.store.Close()
+	if want := "Created directory " + filepath.Dir(escaping) + " for the database"; !strings.Contains(stderr, want) {
+		t.Errorf("expected %q, got %q", want, stderr)
+	}
+
+	// An existing directory is used as is
+	existing := filepath.Join(dir, "rem2.db")
+	stderr = withStderr(t, func() {
+		cli, err := NewWithArgs(&Args{DBPath: &existing})
+		if err != nil {
+			t.Fatalf("NewWithArgs failed: %v", err)
+		}
+		cli.store.Close()
+	})
+	if strings.Contains(stderr, "Created directory") {
+		t.Errorf("expected no message for an existing directory, got %q", stderr)
+	}
+
+	// A text file is refused and left alone
+	notes := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notes, []byte("shopping list\n"), 0600)
+	if _, err := NewWithArgs(&Args{DBPath: &notes}); err == nil || !errors.Is(err, dbstore.ErrNotSQLite) {
+		t.Errorf("expected ErrNotSQLite for a text file, got %v", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "shopping list\n" {
+		t.Errorf("expected the text file untouched, got %q", data)
+	}
+}
+
 func TestNewWithArgs_NilArgs(t *testing.T) {
 	// Create temporary home directory
 	tempDir := t.TempDir()
@@ -140,7 +397,7 @@ func TestArgsValidation_ValidCases(t *testing.T) {
 			name: "get with index",
 			args: Args{
 				Get: &GetCmd{
-					Index: intPtr(0),
+					Index: indexArg(0),
 				},
 			},
 		},
@@ -148,7 +405,7 @@ func TestArgsValidation_ValidCases(t *testing.T) {
 			name: "get to file",
 			args: Args{
 				Get: &GetCmd{
-					Index: intPtr(1),
+					Index: indexArg(1),
 					File:  stringPtr("output.txt"),
 				},
 			},
@@ -157,7 +414,7 @@ func TestArgsValidation_ValidCases(t *testing.T) {
 			name: "get to clipboard",
 			args: Args{
 				Get: &GetCmd{
-					Index:     intPtr(2),
+					Index:     indexArg(2),
 					Clipboard: true,
 				},
 			},
@@ -199,7 +456,7 @@ func TestArgsValidation_InvalidCases(t *testing.T) {
 			name: "get both file and clipboard",
 			args: Args{
 				Get: &GetCmd{
-					Index:     intPtr(0),
+					Index:     indexArg(0),
 					File:      stringPtr("output.txt"),
 					Clipboard: true,
 				},
@@ -209,7 +466,7 @@ func TestArgsValidation_InvalidCases(t *testing.T) {
 			name: "get negative index",
 			args: Args{
 				Get: &GetCmd{
-					Index: intPtr(-1),
+					Index: indexArg(-1),
 				},
 			},
 		},
@@ -410,6 +667,8 @@ func TestConfigCommands_Integration(t *testing.T) {
 		}{
 			{"history_limit", "75"},
 			{"show_binary", "true"},
+			{"wrap_default", "false"},
+			{"hscroll_step", "4"},
 		}
 
 		for _, tc := range testCases {
@@ -443,6 +702,8 @@ func TestConfigCommands_Integration(t *testing.T) {
 			{"history_limit", "not-a-number"},
 			{"history_limit", "-5"},
 			{"show_binary", "maybe"},
+			{"wrap_default", "off"},
+			{"hscroll_step", "0"},
 		}
 
 		for _, tc := range testCases {
@@ -560,11 +821,2974 @@ func TestSearchCommand_BasicTitleSearch(t *testing.T) {
 	})
 }
 
-// Helper functions for pointer creation
-func stringPtr(s string) *string {
-	return &s
+func TestSearchCommand_AccentInsensitive(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "accents.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("ride receipt"), "Über"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	out := withStdout(t, func() {
+		if err := cli.executeSearch(&SearchCmd{Pattern: "uber", TitlesOnly: true}); err != nil {
+			t.Errorf("Search failed: %v", err)
+		}
+	})
+	if out != "0\tÜber\ttitle\n" {
+		t.Errorf("Expected 'uber' to match 'Über', got %q", out)
+	}
+	if err := cli.executeSearch(&SearchCmd{Pattern: "uber", ExactAccents: true}); err == nil {
+		t.Error("Expected --exact-accents to require the accent")
+	}
+}
+
+func TestNewWithArgs_ReadOnlyNewerDatabase(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "newer.db")
+
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	if err := cli.store.Config().Set("db_version", "999"); err != nil {
+		t.Fatalf("Failed to set db_version: %v", err)
+	}
+	cli.store.Close()
+
+	// A normal open refuses the newer database
+	if _, err := NewWithArgs(&Args{DBPath: &dbPath}); err == nil || !strings.Contains(err.Error(), "newer version of rem") {
+		t.Fatalf("Expected newer-version error, got %v", err)
+	}
+
+	// --read-only can still read it
+	cli, err = NewWithArgs(&Args{DBPath: &dbPath, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Read-only open failed: %v", err)
+	}
+	defer cli.store.Close()
+	if err := cli.executeConfigGet(&ConfigGetCmd{Key: "history_limit"}); err != nil {
+		t.Errorf("Expected config get to work read-only: %v", err)
+	}
+
+	// Commands that write are rejected up front
+	args := &Args{DBPath: &dbPath, ReadOnly: true, Store: &StoreCmd{}}
+	if err := args.Validate(); err == nil {
+		t.Error("Expected store to be rejected with --read-only")
+	}
+	args = &Args{DBPath: &dbPath, ReadOnly: true, Config: &ConfigCmd{Set: &ConfigSetCmd{Key: "history_limit", Value: "5"}}}
+	if err := args.Validate(); err == nil {
+		t.Error("Expected config set to be rejected with --read-only")
+	}
+}
+
+func TestTitleCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "title-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	older, _ := cli.queueManager.Enqueue(strings.NewReader("older content"), "older")
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("newer content\nmore"), "newer"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	titleOf := func(id uint) string {
+		item, err := cli.store.History().Get(id)
+		if err != nil {
+			t.Fatalf("Failed to get item %d: %v", id, err)
+		}
+		return item.Title
+	}
+
+	// By index
+	if err := cli.executeTitle(&TitleCmd{Args: []string{"1", "prod nginx config"}}); err != nil {
+		t.Fatalf("rem title by index failed: %v", err)
+	}
+	if got := titleOf(older.ID); got != "prod nginx config" {
+		t.Errorf("Expected renamed title, got '%s'", got)
+	}
+
+	// By ID, newline in the title is sanitized
+	if err := cli.executeTitle(&TitleCmd{Args: []string{"two\nlines"}, ID: &older.ID}); err != nil {
+		t.Fatalf("rem title --id failed: %v", err)
+	}
+	if got := titleOf(older.ID); got != "two lines" {
+		t.Errorf("Expected sanitized title 'two lines', got '%s'", got)
+	}
+
+	// Regenerate from content
+	if err := cli.executeTitle(&TitleCmd{Args: []string{"1"}, FromContent: true}); err != nil {
+		t.Fatalf("rem title --from-content failed: %v", err)
+	}
+	if got := titleOf(older.ID); got != "older content" {
+		t.Errorf("Expected title from content, got '%s'", got)
+	}
+}
+
+func TestTitleCommand_Validation(t *testing.T) {
+	id := uint(3)
+	invalid := []*TitleCmd{
+		{},
+		{Args: []string{"0"}},
+		{Args: []string{"x", "title"}},
+		{Args: []string{"-1", "title"}},
+		{Args: []string{"0", "title"}, FromContent: true},
+		{Args: []string{"0", "a", "b"}},
+		{ID: &id},
+	}
+	for _, cmd := range invalid {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", cmd)
+		}
+	}
+
+	valid := []*TitleCmd{
+		{Args: []string{"0", "title"}},
+		{Args: []string{"title"}, ID: &id},
+		{ID: &id, FromContent: true},
+		{Args: []string{"2"}, FromContent: true},
+	}
+	for _, cmd := range valid {
+		if err := cmd.Validate(); err != nil {
+			t.Errorf("Unexpected validation error for %+v: %v", cmd, err)
+		}
+	}
+}
+
+func TestBumpCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "bump-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	oldest, _ := cli.queueManager.Enqueue(strings.NewReader("oldest content"), "oldest")
+	cli.queueManager.Enqueue(strings.NewReader("middle content"), "middle")
+	cli.queueManager.Enqueue(strings.NewReader("newest content"), "newest")
+
+	output := withStdout(t, func() {
+		if err := cli.executeBump(&BumpCmd{Index: indexArg(2)}); err != nil {
+			t.Fatalf("rem bump failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Moved to top: oldest") {
+		t.Errorf("Expected a confirmation, got %q", output)
+	}
+	if top, _ := cli.queueManager.Get(0); top.ID != oldest.ID {
+		t.Errorf("Expected the bumped item at index 0, got %q", top.Title)
+	}
+
+	middle, _ := cli.queueManager.Get(2)
+	if err := cli.executeBump(&BumpCmd{ID: &middle.ID}); err != nil {
+		t.Fatalf("rem bump --id failed: %v", err)
+	}
+	if top, _ := cli.queueManager.Get(0); top.Title != "middle" {
+		t.Errorf("Expected middle at index 0, got %q", top.Title)
+	}
+
+	if err := (&BumpCmd{}).Validate(); err == nil {
+		t.Error("Expected an error without an index or --id")
+	}
+	if err := (&Args{ReadOnly: true, Bump: &BumpCmd{Index: indexArg(0)}}).Validate(); err == nil {
+		t.Error("Expected bump to be rejected with --read-only")
+	}
+}
+
+func TestNoteCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "note-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	older, _ := cli.queueManager.Enqueue(strings.NewReader("older content"), "older")
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("newer content"), "newer"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	// By index, whitespace in the note is collapsed
+	if err := cli.executeNote(&NoteCmd{Args: []string{"1", "staging\ndeploy key"}}); err != nil {
+		t.Fatalf("rem note by index failed: %v", err)
+	}
+	item, _ := cli.store.History().Get(older.ID)
+	if item.Note != "staging deploy key" {
+		t.Errorf("Expected note 'staging deploy key', got '%s'", item.Note)
+	}
+
+	// Notes are searched by default and with --notes
+	for _, cmd := range []*SearchCmd{{Pattern: "staging", IndexOnly: true}, {Pattern: "staging", IndexOnly: true, SearchNotes: true}} {
+		output := withStdout(t, func() {
+			if err := cli.executeSearch(cmd); err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+		})
+		if strings.TrimSpace(output) != "1" {
+			t.Errorf("Expected the noted item at index 1, got %q", output)
+		}
+	}
+	if err := cli.executeSearch(&SearchCmd{Pattern: "staging", SearchTitle: true}); err == nil {
+		t.Error("Expected --title search not to match the note")
+	}
+
+	// The note is part of list --json
+	output := withStdout(t, func() {
+		if err := cli.executeList(&ListCmd{JSON: true}); err != nil {
+			t.Fatalf("list --json failed: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one JSON object per item, got %q", output)
+	}
+	var listed struct {
+		Title string `json:"title"`
+		Note  string `json:"note"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &listed); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", lines[1], err)
+	}
+	if listed.Title != "older" || listed.Note != "staging deploy key" {
+		t.Errorf("Expected older item with its note, got %+v", listed)
+	}
+	if strings.Contains(lines[0], `"note"`) {
+		t.Errorf("Expected no note field for an item without one, got %s", lines[0])
+	}
+
+	// An empty note by ID clears it
+	if err := cli.executeNote(&NoteCmd{Args: []string{""}, ID: &older.ID}); err != nil {
+		t.Fatalf("rem note --id failed: %v", err)
+	}
+	if item, _ := cli.store.History().Get(older.ID); item.Note != "" {
+		t.Errorf("Expected note cleared, got '%s'", item.Note)
+	}
+}
+
+func TestNoteCommand_Validation(t *testing.T) {
+	id := uint(3)
+	invalid := []*NoteCmd{
+		{},
+		{Args: []string{"0"}},
+		{Args: []string{"x", "note"}},
+		{Args: []string{"-1", "note"}},
+		{Args: []string{"0", "a", "b"}},
+		{ID: &id},
+	}
+	for _, cmd := range invalid {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", cmd)
+		}
+	}
+
+	valid := []*NoteCmd{
+		{Args: []string{"0", "note"}},
+		{Args: []string{"0", ""}},
+		{Args: []string{"note"}, ID: &id},
+	}
+	for _, cmd := range valid {
+		if err := cmd.Validate(); err != nil {
+			t.Errorf("Unexpected validation error for %+v: %v", cmd, err)
+		}
+	}
+}
+
+func TestSyncCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "local.db")
+	otherPath := filepath.Join(tempDir, "other", "remote.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for _, content := range []string{"first", "second", "third"} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(content), content); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	remoteTitles := func() []string {
+		other, err := NewWithArgs(&Args{DBPath: &otherPath})
+		if err != nil {
+			t.Fatalf("Failed to open other database: %v", err)
+		}
+		defer other.store.Close()
+		items, err := other.queueManager.List()
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		var titles []string
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		return titles
+	}
+
+	// Dry run copies nothing
+	if err := cli.executeSync(&SyncCmd{To: &otherPath, DryRun: true}); err != nil {
+		t.Fatalf("rem sync --dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(otherPath); !os.IsNotExist(err) {
+		t.Errorf("Dry run should not create the database, stat error = %v", err)
+	}
+
+	if err := cli.executeSync(&SyncCmd{To: &otherPath}); err != nil {
+		t.Fatalf("rem sync --to failed: %v", err)
+	}
+	if got := strings.Join(remoteTitles(), ","); got != "third,second,first" {
+		t.Errorf("Expected queue order to be preserved, got %s", got)
+	}
+
+	// A second sync is a no-op
+	if err := cli.executeSync(&SyncCmd{To: &otherPath}); err != nil {
+		t.Fatalf("Second rem sync failed: %v", err)
+	}
+	if got := len(remoteTitles()); got != 3 {
+		t.Errorf("Expected 3 items after repeated sync, got %d", got)
+	}
+
+	// Pull a new item back with --from
+	other, err := NewWithArgs(&Args{DBPath: &otherPath})
+	if err != nil {
+		t.Fatalf("Failed to open other database: %v", err)
+	}
+	if _, err := other.queueManager.Enqueue(strings.NewReader("remote only"), "remote only"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	other.store.Close()
+
+	if err := cli.executeSync(&SyncCmd{From: &otherPath}); err != nil {
+		t.Fatalf("rem sync --from failed: %v", err)
+	}
+	newest, err := cli.queueManager.Get(0)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	if newest.Title != "remote only" {
+		t.Errorf("Expected pulled item at index 0, got %q", newest.Title)
+	}
+	if size, _ := cli.queueManager.Size(); size != 4 {
+		t.Errorf("Expected 4 local items, got %d", size)
+	}
 }
 
-func intPtr(i int) *int {
-	return &i
+func TestSyncCommand_OnDuplicate(t *testing.T) {
+	tempDir := t.TempDir()
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	create := func(st store.Store, title, content string, timestamp time.Time) {
+		t.Helper()
+		if _, err := st.History().Create(&store.CreateHistoryInput{Title: title, Content: strings.NewReader(content), Timestamp: timestamp}); err != nil {
+			t.Fatalf("Failed to create %q: %v", title, err)
+		}
+	}
+
+	srcPath := filepath.Join(tempDir, "src.db")
+	src, err := NewWithArgs(&Args{DBPath: &srcPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	for i := 1; i <= 4; i++ {
+		create(src.store, fmt.Sprintf("new %d", i), fmt.Sprintf("content %d", i), base.Add(time.Duration(i)*time.Hour))
+	}
+	create(src.store, "same", "content 5", base.Add(5*time.Hour))
+	src.store.Close()
+
+	// sync runs --from src into a fresh destination holding the same
+	// content, items 1-2 older than the incoming ones and 3-4 newer
+	sync := func(name, policy string, resolver *duplicateResolver) ([]string, string) {
+		t.Helper()
+		dbPath := filepath.Join(tempDir, name+".db")
+		cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+		if err != nil {
+			t.Fatalf("Failed to create CLI: %v", err)
+		}
+		defer cli.store.Close()
+		for i := 1; i <= 4; i++ {
+			timestamp := base
+			if i > 2 {
+				timestamp = base.Add(10 * time.Hour)
+			}
+			create(cli.store, fmt.Sprintf("old %d", i), fmt.Sprintf("content %d", i), timestamp)
+		}
+		create(cli.store, "same", "content 5", base.Add(5*time.Hour))
+
+		cmd := &SyncCmd{From: &srcPath, OnDuplicate: policy}
+		out := withStdout(t, func() {
+			var err error
+			if resolver != nil {
+				err = cli.syncWith(cmd, resolver)
+			} else {
+				err = cli.executeSync(cmd)
+			}
+			if err != nil {
+				t.Fatalf("rem sync --on-duplicate %s failed: %v", policy, err)
+			}
+		})
+		items, err := cli.store.History().List(0)
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		var titles []string
+		for _, item := range items {
+			titles = append(titles, item.Title)
+		}
+		slices.Sort(titles)
+		return titles, out
+	}
+
+	for _, tt := range []struct {
+		policy string
+		want   string
+	}{
+		{"", "old 1,old 2,old 3,old 4,same"},
+		{duplicateKeep, "old 1,old 2,old 3,old 4,same"},
+		{duplicateReplace, "new 1,new 2,new 3,new 4,same"},
+		{duplicateNewest, "new 1,new 2,old 3,old 4,same"},
+	} {
+		titles, out := sync("policy-"+tt.policy, tt.policy, nil)
+		if got := strings.Join(titles, ","); got != tt.want {
+			t.Errorf("--on-duplicate %q left %s, want %s", tt.policy, got, tt.want)
+		}
+		if tt.policy == duplicateReplace && !strings.Contains(out, "Copied 0 item(s), updated 4, skipped 1 already present") {
+			t.Errorf("Unexpected summary %q", out)
+		}
+	}
+
+	// Keep the first, replace the second, then replace all the rest; the
+	// item that already agrees is never asked about
+	var prompts bytes.Buffer
+	resolver := &duplicateResolver{policy: duplicateAsk, in: bufio.NewReader(strings.NewReader("n\nmaybe\ny\na\n")), out: &prompts}
+	titles, _ := sync("ask", duplicateAsk, resolver)
+	if got := strings.Join(titles, ","); got != "new 2,new 3,new 4,old 1,same" {
+		t.Errorf("--on-duplicate ask left %s", got)
+	}
+	if n := strings.Count(prompts.String(), "Replace with incoming?"); n != 4 {
+		t.Errorf("Expected 4 prompts (one repeated), got %d:\n%s", n, prompts.String())
+	}
+	if !strings.Contains(prompts.String(), `"old 1"`) || !strings.Contains(prompts.String(), `"new 1"`) {
+		t.Errorf("Expected the prompt to show both titles:\n%s", prompts.String())
+	}
+
+	// Without a terminal to ask on, ask fails before syncing anything
+	withStdin(t, "y\n", func() {
+		if err := src.executeSync(&SyncCmd{From: &srcPath, OnDuplicate: duplicateAsk}); err == nil || !strings.Contains(err.Error(), "terminal") {
+			t.Errorf("Expected --on-duplicate ask to need a terminal, got %v", err)
+		}
+	})
+	if err := (&SyncCmd{From: &srcPath, OnDuplicate: "merge"}).Validate(); err == nil {
+		t.Error("Expected an unknown --on-duplicate to be rejected")
+	}
+}
+
+func TestEditCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "edit-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// The hostname straddles the boundary between the first two chunks
+	filler := strings.Repeat("x", 99) + "\n"
+	prefix := strings.Repeat(filler, dbstore.ChunkSize/len(filler)) + strings.Repeat("y", dbstore.ChunkSize%len(filler)-4)
+	big := prefix + "ssh old.host -p 22\nscp x old.host:\n"
+	for _, input := range []struct{ title, content string }{
+		{"big config", big},
+		{"unrelated", "nothing to change\n"},
+		{"binary", "old.host\x00\x01"},
+		{"small", "curl https://old.host/api"},
+	} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(input.content), input.title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	snapshot := func() map[string]store.HistoryItem {
+		items, err := cli.store.History().List(0)
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+		byTitle := map[string]store.HistoryItem{}
+		for _, item := range items {
+			byTitle[item.Title] = *item
+		}
+		return byTitle
+	}
+	before := snapshot()
+	replace := "new.host"
+
+	out := withStdout(t, func() {
+		if err := cli.executeEdit(&EditCmd{Pattern: `old\.host`, Replace: &replace, DryRun: true}); err != nil {
+			t.Fatalf("rem edit --dry-run failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Would replace 2 match(es) in item") || !strings.Contains(out, "Would edit 2 item(s), 3 replacement(s)") {
+		t.Errorf("Unexpected dry run output:\n%s", out)
+	}
+	for title, item := range snapshot() {
+		if item.SHA256 != before[title].SHA256 || !item.UpdatedAt.Equal(before[title].UpdatedAt) {
+			t.Errorf("Dry run changed %q", title)
+		}
+	}
+
+	withStdout(t, func() {
+		if err := cli.executeEdit(&EditCmd{Pattern: `old\.host`, Replace: &replace, Backup: true}); err != nil {
+			t.Fatalf("rem edit failed: %v", err)
+		}
+	})
+	after := snapshot()
+	want := strings.ReplaceAll(big, "old.host", "new.host")
+	if got := readItem(t, cli, after["big config"].ID); got != want {
+		t.Errorf("Replacement across the chunk boundary went wrong: got %d bytes ending %q", len(got), got[len(got)-40:])
+	}
+	hash := sha256.Sum256([]byte(want))
+	if edited := after["big config"]; edited.SHA256 != hex.EncodeToString(hash[:]) || edited.Size != int64(len(want)) || !edited.Timestamp.Equal(before["big config"].Timestamp) {
+		t.Errorf("Edited item has stale metadata or moved: %+v", edited)
+	}
+	if got := readItem(t, cli, after["small"].ID); got != "curl https://new.host/api" {
+		t.Errorf("Expected a line without a newline edited, got %q", got)
+	}
+	for _, title := range []string{"unrelated", "binary"} {
+		if after[title].SHA256 != before[title].SHA256 || !after[title].UpdatedAt.Equal(before[title].UpdatedAt) {
+			t.Errorf("Expected %q untouched", title)
+		}
+	}
+
+	// --backup kept the originals, which aren't edited themselves
+	originals, err := cli.store.History().FindBySHA256(before["big config"].SHA256)
+	if err != nil || len(originals) != 1 || !strings.HasPrefix(originals[0].Note, "Original of item") {
+		t.Fatalf("Expected one noted backup of the original, got %v, %v", originals, err)
+	}
+	if count, _ := cli.store.History().Count(); count != 6 {
+		t.Errorf("Expected 4 items and 2 backups, got %d items", count)
+	}
+
+	// --match limits the items edited
+	other := "other.host"
+	match := "small"
+	withStdout(t, func() {
+		if err := cli.executeEdit(&EditCmd{Pattern: `new\.host`, Replace: &other, Match: &match}); err != nil {
+			t.Fatalf("rem edit --match failed: %v", err)
+		}
+	})
+	if got := readItem(t, cli, after["small"].ID); got != "curl https://other.host/api" {
+		t.Errorf("Expected the matching item edited, got %q", got)
+	}
+	if got := readItem(t, cli, after["big config"].ID); got != want {
+		t.Error("Expected the item not matching --match left alone")
+	}
+
+	if err := (&EditCmd{Pattern: "(", Replace: &replace}).Validate(); err == nil {
+		t.Error("Expected an invalid pattern to be rejected")
+	}
+	if err := (&Args{ReadOnly: true, Edit: &EditCmd{Pattern: "x", Replace: &replace}}).Validate(); err == nil {
+		t.Error("Expected rem edit to be rejected with --read-only")
+	}
+}
+
+// readItem returns the content of the item with the given ID
+func readItem(t *testing.T, cli *CLI, id uint) string {
+	t.Helper()
+	r, err := cli.store.History().GetContent(id)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read content: %v", err)
+	}
+	return string(data)
+}
+
+func TestSyncCommand_MatchAndDryRunRepeats(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "local.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// "shared" is stored twice, so a sync copies it once; "list" doesn't
+	// match --match
+	for _, spec := range []struct{ content, title string }{
+		{"groceries", "list"}, {"shared", "Work notes"}, {"private", "diary"}, {"shared", "work again"}, {"other", "Café work"},
+	} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(spec.content), spec.title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	diary, _ := cli.queueManager.Get(2)
+	if err := cli.store.History().UpdateNote(diary.ID, "for work"); err != nil {
+		t.Fatalf("Failed to set note: %v", err)
+	}
+
+	for _, policy := range []string{duplicateKeep, duplicateReplace} {
+		otherPath := filepath.Join(tempDir, policy+".db")
+		pattern := "work"
+		cmd := SyncCmd{To: &otherPath, OnDuplicate: policy}
+		dryRun := cmd
+		dryRun.DryRun = true
+		preview := withStdout(t, func() {
+			if err := cli.executeSync(&dryRun); err != nil {
+				t.Fatalf("rem sync --dry-run failed: %v", err)
+			}
+		})
+		real := withStdout(t, func() {
+			if err := cli.executeSync(&cmd); err != nil {
+				t.Fatalf("rem sync failed: %v", err)
+			}
+		})
+		summary := func(output string) string {
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			return strings.TrimPrefix(strings.TrimPrefix(lines[len(lines)-1], "Would copy"), "Copied")
+		}
+		if summary(preview) != summary(real) {
+			t.Errorf("--on-duplicate %s: dry run summary %q, real run %q", policy, preview, real)
+		}
+
+		// --match picks items by title or note, ignoring case and accents
+		matchPath := filepath.Join(tempDir, policy+"-match.db")
+		output := withStdout(t, func() {
+			if err := cli.executeSync(&SyncCmd{To: &matchPath, Match: &pattern, OnDuplicate: policy}); err != nil {
+				t.Fatalf("rem sync --match failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Copied 3 item(s)") {
+			t.Errorf("--on-duplicate %s: expected shared once, the diary by its note, and the café item, got %q", policy, output)
+		}
+	}
+}
+
+func TestSyncCommand_Validation(t *testing.T) {
+	path := "other.db"
+	bad := "soon"
+	since := "24h"
+	badMatch := "("
+	invalid := []*SyncCmd{
+		{},
+		{To: &path, From: &path},
+		{To: &path, Since: &bad},
+		{To: &path, Match: &badMatch},
+	}
+	for _, cmd := range invalid {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", cmd)
+		}
+	}
+
+	if err := (&SyncCmd{From: &path, Since: &since}).Validate(); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+// failingReader fails the test if it is ever read from
+type failingReader struct {
+	t *testing.T
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	r.t.Error("content should not be read for an item over the clipboard limit")
+	return 0, errors.New("unexpected read")
+}
+
+func TestGetClipboard_RefusesOversizedItem(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "clipboard-limit.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	mock := mockboard.New()
+	cli.clipboard = mock
+
+	// A synthetic 1GB item is refused before any content is buffered
+	_, err = cli.writeToClipboard(failingReader{t}, 1<<30)
+	if err == nil {
+		t.Fatal("Expected an error for an item over clipboard_max_bytes")
+	}
+	if !strings.Contains(err.Error(), "rem get <index> <file>") {
+		t.Errorf("Expected error to suggest writing to a file, got: %v", err)
+	}
+	if len(mock.GetData()) != 0 {
+		t.Errorf("Expected clipboard to be untouched, got %d bytes", len(mock.GetData()))
+	}
+
+	// The limit is configurable
+	if err := cli.store.Config().Set("clipboard_max_bytes", "10"); err != nil {
+		t.Fatalf("Failed to set clipboard_max_bytes: %v", err)
+	}
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("more than ten bytes"), "small"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if err := cli.executeGet(&GetCmd{Index: indexArg(0), Clipboard: true}); err == nil {
+		t.Error("Expected rem get -c to refuse an item over the configured limit")
+	}
+}
+
+func TestGetClipboard_WritesExactSize(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "clipboard-copy.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	mock := mockboard.New()
+	cli.clipboard = mock
+
+	content := strings.Repeat("0123456789abcdef", 64*1024) // 1MB
+	if _, err := cli.queueManager.Enqueue(strings.NewReader(content), "large"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if err := cli.executeGet(&GetCmd{Index: indexArg(0), Clipboard: true}); err != nil {
+		t.Fatalf("rem get -c failed: %v", err)
+	}
+	if string(mock.GetData()) != content {
+		t.Errorf("Clipboard content mismatch: got %d bytes, want %d", len(mock.GetData()), len(content))
+	}
+
+	// Buffering an item of known size never grows the buffer
+	size := int64(len(content))
+	buf, err := bufferClipboardContent(strings.NewReader(content), size)
+	if err != nil {
+		t.Fatalf("bufferClipboardContent failed: %v", err)
+	}
+	if int64(buf.Len()) != size {
+		t.Errorf("Expected %d buffered bytes, got %d", size, buf.Len())
+	}
+	if int64(buf.Cap()) != size+bytes.MinRead {
+		t.Errorf("Expected buffer capacity to stay at %d, got %d", size+bytes.MinRead, buf.Cap())
+	}
+
+	n, err := cli.writeToClipboard(strings.NewReader(content), size)
+	if err != nil {
+		t.Fatalf("writeToClipboard failed: %v", err)
+	}
+	if n != size {
+		t.Errorf("Expected %d bytes written, got %d", size, n)
+	}
+}
+
+// Helper functions for pointer creation
+func stringPtr(s string) *string {
+	return &s
+}
+
+func indexArg(i int) *ItemArg {
+	return &ItemArg{Index: i}
+}
+
+func TestWriteItemJSON(t *testing.T) {
+	timestamp := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	decode := func(t *testing.T, item *store.HistoryItem, content string, maxBytes int64) map[string]any {
+		t.Helper()
+		var out bytes.Buffer
+		if err := writeItemJSON(&out, item, 2, strings.NewReader(content), maxBytes); err != nil {
+			t.Fatalf("writeItemJSON failed: %v", err)
+		}
+		if !strings.HasSuffix(out.String(), "}\n") {
+			t.Errorf("Expected a single newline-terminated object, got %q", out.String())
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(out.Bytes(), &obj); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, out.String())
+		}
+		return obj
+	}
+
+	t.Run("text", func(t *testing.T) {
+		content := "line \"one\"\nline two\t✓"
+		item := &store.HistoryItem{ID: 7, Title: "notes", Timestamp: timestamp, Size: int64(len(content)), SHA256: "abc",
+			CreatedAt: timestamp.Add(-time.Hour), UpdatedAt: timestamp.Add(time.Hour)}
+		obj := decode(t, item, content, defaultJSONMaxBytes)
+
+		if obj["id"] != float64(7) || obj["index"] != float64(2) || obj["title"] != "notes" {
+			t.Errorf("Unexpected metadata: %v", obj)
+		}
+		if obj["timestamp"] != "2024-03-01T09:30:00Z" || obj["sha256"] != "abc" || obj["is_binary"] != false {
+			t.Errorf("Unexpected metadata: %v", obj)
+		}
+		if obj["created_at"] != "2024-03-01T08:30:00Z" || obj["updated_at"] != "2024-03-01T10:30:00Z" {
+			t.Errorf("Unexpected created_at/updated_at: %v", obj)
+		}
+		if obj["content"] != content {
+			t.Errorf("content = %q, want %q", obj["content"], content)
+		}
+		if obj["truncated"] != false {
+			t.Errorf("truncated = %v, want false", obj["truncated"])
+		}
+		if _, ok := obj["content_base64"]; ok {
+			t.Error("Text items should not have content_base64")
+		}
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		content := "\x00\x01\x02binary\xff"
+		item := &store.HistoryItem{ID: 8, Title: "blob", Timestamp: timestamp, Size: int64(len(content)), IsBinary: true}
+		obj := decode(t, item, content, defaultJSONMaxBytes)
+
+		encoded, _ := obj["content_base64"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || string(decoded) != content {
+			t.Errorf("content_base64 = %q does not decode to the content (err %v)", encoded, err)
+		}
+		if _, ok := obj["content"]; ok {
+			t.Error("Binary items should not have content")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		content := strings.Repeat("x", 100)
+		item := &store.HistoryItem{ID: 9, Title: "big", Timestamp: timestamp, Size: int64(len(content))}
+		obj := decode(t, item, content, 10)
+
+		if obj["truncated"] != true {
+			t.Errorf("truncated = %v, want true", obj["truncated"])
+		}
+		if _, ok := obj["content"]; ok {
+			t.Error("Content over --max-bytes should be omitted")
+		}
+		if obj["size"] != float64(100) {
+			t.Errorf("size = %v, want 100", obj["size"])
+		}
+	})
+}
+
+func TestGetCommand_JSONValidation(t *testing.T) {
+	index := 0
+	file := "out.txt"
+	maxBytes := int64(10)
+	invalid := []*GetCmd{
+		{JSON: true},
+		{Index: indexArg(index), JSON: true, Clipboard: true},
+		{Index: indexArg(index), File: &file, JSON: true},
+		{Index: indexArg(index), MaxBytes: &maxBytes},
+	}
+	for _, cmd := range invalid {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", cmd)
+		}
+	}
+
+	if err := (&GetCmd{Index: indexArg(index), JSON: true, MaxBytes: &maxBytes}).Validate(); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+func TestGetCommand_Match(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "match.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	mock := mockboard.New()
+	cli.clipboard = mock
+
+	for _, item := range []struct{ title, content string }{
+		{"ticket-1 notes", "old ticket"},
+		{"groceries", "mentions ticket-2 in the body"},
+		{"unrelated", "nothing here"},
+	} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(item.content), item.title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	// Title and content are both searched, newest first
+	out := withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Match: stringPtr(`ticket-\d`)}); err != nil {
+			t.Errorf("rem get --match failed: %v", err)
+		}
+	})
+	if out != "mentions ticket-2 in the body" {
+		t.Errorf("Expected the newest match on stdout, got %q", out)
+	}
+
+	if err := cli.executeGet(&GetCmd{Match: stringPtr("ticket"), MatchTitle: true, Clipboard: true}); err != nil {
+		t.Fatalf("rem get -c --match --match-title failed: %v", err)
+	}
+	if string(mock.GetData()) != "old ticket" {
+		t.Errorf("Expected the title match on the clipboard, got %q", mock.GetData())
+	}
+
+	err = cli.executeGet(&GetCmd{Match: stringPtr("groceries"), MatchContent: true})
+	if !errors.Is(err, ErrNotFound) || ExitCode(err) != ExitNotFound {
+		t.Errorf("Expected a not-found error for a content-only miss, got %v", err)
+	}
+
+	// An item stored between the search and the fetch doesn't change the result
+	item, err := cli.findLatestMatch(&GetCmd{Match: stringPtr("ticket")})
+	if err != nil {
+		t.Fatalf("findLatestMatch failed: %v", err)
+	}
+	if _, err := cli.store.History().Create(&store.CreateHistoryInput{Title: "ticket-3", Content: strings.NewReader("newer ticket")}); err != nil {
+		t.Fatalf("Failed to store item: %v", err)
+	}
+	output := filepath.Join(tempDir, "out.txt")
+	withStdout(t, func() {
+		if err := cli.writeGetOutput(&GetCmd{Output: &output}, item, cli.indexOf(item.ID)); err != nil {
+			t.Errorf("writeGetOutput failed: %v", err)
+		}
+	})
+	if data, _ := os.ReadFile(output); string(data) != "mentions ticket-2 in the body" {
+		t.Errorf("Expected the item found by the search, got %q", data)
+	}
+}
+
+func TestGetCommand_KeepsInvalidUTF8(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "latin1.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Latin-1 text is stored as text, and comes back byte for byte
+	content := "caf\xe9 opened\nna\xefve user logged in\n"
+	item, err := cli.queueManager.Enqueue(strings.NewReader(content), "")
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if item.IsBinary {
+		t.Error("expected mostly-text content stored as text")
+	}
+	var getErr error
+	if out := withStdout(t, func() { getErr = cli.executeGet(&GetCmd{Index: indexArg(0)}) }); getErr != nil || out != content {
+		t.Errorf("get = %q, %v; want the original bytes %q", out, getErr, content)
+	}
+}
+
+func TestGetCommand_IndexOutOfRange(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "range.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// An empty queue gets guidance rather than a range
+	index := 0
+	var getErr error
+	stderr := withStderr(t, func() {
+		getErr = cli.executeGet(&GetCmd{Index: indexArg(index)})
+	})
+	if getErr == nil || getErr.Error() != "queue is empty" || ExitCode(getErr) != ExitNoIndex {
+		t.Errorf("Expected 'queue is empty' with exit code %d, got %v", ExitNoIndex, getErr)
+	}
+	if !strings.Contains(stderr, "rem store") {
+		t.Errorf("Expected how to add items on stderr, got %q", stderr)
+	}
+
+	for _, title := range []string{"one", "two", "three"} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(title), title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	index = 3
+	withStdout(t, func() {
+		getErr = cli.executeGet(&GetCmd{Index: indexArg(index)})
+	})
+	if want := "index 3 out of range (queue has 3 items, valid indexes 0-2)"; getErr == nil || getErr.Error() != want {
+		t.Errorf("Expected %q, got %v", want, getErr)
+	}
+	if !errors.Is(getErr, queue.ErrIndexOutOfRange) || ExitCode(getErr) != ExitNoIndex {
+		t.Errorf("Expected ErrIndexOutOfRange with exit code %d, got %v", ExitNoIndex, getErr)
+	}
+	index = 2
+	if out := withStdout(t, func() { getErr = cli.executeGet(&GetCmd{Index: indexArg(index)}) }); getErr != nil || out != "one" {
+		t.Errorf("Expected the last index to get the oldest item, got %q, %v", out, getErr)
+	}
+
+	items, _ := cli.queueManager.List()
+	var hint bytes.Buffer
+	writeIndexHint(&hint, items)
+	if want := "Oldest items:\n  0: three\n  1: two\n  2: one\n"; hint.String() != want {
+		t.Errorf("hint = %q, want %q", hint.String(), want)
+	}
+}
+
+func TestGetCommand_MatchValidation(t *testing.T) {
+	index := 0
+	file := "out.txt"
+	invalid := []*GetCmd{
+		{Index: indexArg(index), Match: stringPtr("x")},
+		{MatchTitle: true},
+		{Match: stringPtr("x"), MatchTitle: true, MatchContent: true},
+		{Match: stringPtr("x"), File: &file, Output: &file},
+		{Match: stringPtr("x"), Output: &file, Clipboard: true},
+	}
+	for _, cmd := range invalid {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v", cmd)
+		}
+	}
+
+	if err := (&GetCmd{Match: stringPtr("x"), JSON: true}).Validate(); err != nil {
+		t.Errorf("Unexpected validation error: %v", err)
+	}
+}
+
+func TestGetCommand_Positionals(t *testing.T) {
+	// The first positional must be an index, named in a hint when it isn't
+	var args Args
+	parser, err := arg.NewParser(arg.Config{}, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = parser.Parse([]string{"get", "out.txt"})
+	if msg := explainParseError(&args, err); !strings.Contains(msg, "'out.txt' is not an index") || !strings.Contains(msg, "rem get 0 out.txt") {
+		t.Errorf("Expected a hint to put the index first, got %q", msg)
+	}
+	var infoArgs Args
+	infoParser, err := arg.NewParser(arg.Config{}, &infoArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = infoParser.Parse([]string{"info", "x"})
+	if msg := explainParseError(&infoArgs, err); msg != err.Error() {
+		t.Errorf("Expected other commands' errors unchanged, got %q", msg)
+	}
+
+	stdout := StdoutFile
+	file := "out.txt"
+	for _, tc := range []struct {
+		cmd   GetCmd
+		valid bool
+	}{
+		{GetCmd{Index: indexArg(0), File: &file}, true},
+		{GetCmd{Index: indexArg(0), File: &stdout}, true},
+		{GetCmd{Index: indexArg(0), Output: &stdout}, true},
+		{GetCmd{Index: indexArg(0), File: &stdout, JSON: true}, true},
+		{GetCmd{Index: indexArg(0), File: &file, Output: &file}, false},
+		{GetCmd{Index: indexArg(0), File: &stdout, Output: &file}, false},
+		{GetCmd{Index: indexArg(0), File: &stdout, Clipboard: true}, false},
+		{GetCmd{Index: indexArg(0), Output: &stdout, Restore: true}, false},
+	} {
+		if err := tc.cmd.Validate(); (err == nil) != tc.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %v", tc.cmd, err, tc.valid)
+		}
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager.Enqueue(strings.NewReader("content"), "item")
+
+	// - writes to stdout, not to a file named -
+	t.Chdir(t.TempDir())
+	output := withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), File: &stdout}); err != nil {
+			t.Fatalf("get 0 - failed: %v", err)
+		}
+	})
+	if output != "content" {
+		t.Errorf("Expected the content on stdout, got %q", output)
+	}
+	if _, err := os.Stat(StdoutFile); err == nil {
+		t.Error("Expected no file named -")
+	}
+
+	// Without an index or a terminal to pick in, get explains itself
+	withStdin(t, "", func() {
+		withStdout(t, func() {
+			if err := cli.executeGet(&GetCmd{}); !errors.Is(err, errGetNeedsIndex) {
+				t.Errorf("Expected errGetNeedsIndex, got %v", err)
+			}
+		})
+	})
+}
+
+func TestConfigSet_KeyBindings(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "keys-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "key_copy_delete", Value: "X"}); err != nil {
+		t.Fatalf("Failed to remap key: %v", err)
+	}
+
+	// Conflicts with reserved keys and existing bindings are rejected
+	for _, cmd := range []*ConfigSetCmd{
+		{Key: "key_copy", Value: "q"},
+		{Key: "key_delete", Value: "X"},
+	} {
+		if err := cli.executeConfigSet(cmd); err == nil {
+			t.Errorf("Expected %s=%s to be rejected", cmd.Key, cmd.Value)
+		}
+	}
+
+	if value, _ := cli.store.Config().Get("key_delete"); value != "" {
+		t.Errorf("Rejected binding should not be stored, got %q", value)
+	}
+	if err := (&ConfigSetCmd{Key: "key_copy", Value: "y"}).Validate(); err != nil {
+		t.Errorf("key_copy should be a valid config key: %v", err)
+	}
+}
+
+func TestConfigSet_FriendlyValues(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "friendly-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(fmt.Sprintf("item %d", i)), ""); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	out := withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "clipboard_max_bytes", Value: "10MB"}); err != nil {
+			t.Fatalf("Failed to set clipboard_max_bytes: %v", err)
+		}
+	})
+	if !strings.Contains(out, "clipboard_max_bytes = 10000000") {
+		t.Errorf("Expected the normalized value to be reported, got %q", out)
+	}
+	if got := cli.clipboardMaxBytes(); got != 10000000 {
+		t.Errorf("Expected clipboard limit 10000000, got %d", got)
+	}
+
+	out = withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "history_limit", Value: "2"}); err != nil {
+			t.Fatalf("Failed to set history_limit: %v", err)
+		}
+	})
+	if !strings.Contains(out, "3 item(s) stored; the oldest 1 will be removed") {
+		t.Errorf("Expected a trim notice, got %q", out)
+	}
+
+	out = withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "history_limit", Value: "1k"}); err != nil {
+			t.Fatalf("Failed to set history_limit: %v", err)
+		}
+	})
+	if !strings.Contains(out, "within the limit") {
+		t.Errorf("Expected a within-limit notice, got %q", out)
+	}
+
+	// get prints the canonical value, even for values stored by hand
+	if err := cli.store.Config().Set("clipboard_max_bytes", "1KiB"); err != nil {
+		t.Fatalf("Failed to store raw value: %v", err)
+	}
+	for key, want := range map[string]string{"history_limit": "1000\n", "clipboard_max_bytes": "1024\n"} {
+		out := withStdout(t, func() {
+			if err := cli.executeConfigGet(&ConfigGetCmd{Key: key}); err != nil {
+				t.Fatalf("Failed to get %s: %v", key, err)
+			}
+		})
+		if out != want {
+			t.Errorf("config get %s = %q, want %q", key, out, want)
+		}
+	}
+
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "history_limit", Value: "1m"}); err == nil {
+		t.Error("Expected an out-of-range history_limit to be rejected")
+	}
+}
+
+func TestBackupCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "backup-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("keep me"), "kept"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	// clear --force takes a backup first
+	withStdout(t, func() {
+		if err := cli.executeClear(&ClearCmd{Force: true}); err != nil {
+			t.Fatalf("clear failed: %v", err)
+		}
+	})
+	out := withStdout(t, func() {
+		if err := cli.executeBackupList(); err != nil {
+			t.Fatalf("backup list failed: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "rem-") {
+		t.Fatalf("Expected one backup listed, got %q", out)
+	}
+	name := strings.SplitN(lines[0], "\t", 2)[0]
+
+	// restore requires --force
+	if err := (&Args{Backup: &BackupCmd{Restore: &BackupRestoreCmd{File: name}}}).Validate(); err == nil {
+		t.Error("Expected restore without --force to be rejected")
+	}
+
+	// Restoring by name brings the cleared item back
+	withStdout(t, func() {
+		if err := cli.executeBackupRestore(&BackupRestoreCmd{File: name, Force: true}); err != nil {
+			t.Fatalf("backup restore failed: %v", err)
+		}
+	})
+	restored, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to reopen CLI: %v", err)
+	}
+	defer restored.store.Close()
+	items, err := restored.queueManager.List()
+	if err != nil || len(items) != 1 || items[0].Title != "kept" {
+		t.Fatalf("Expected the cleared item to be restored, got %v (%v)", items, err)
+	}
+
+	// The database replaced by the restore was itself backed up
+	backups, err := dbstore.ListBackups(backupDir(dbPath))
+	if err != nil || len(backups) != 2 {
+		t.Errorf("Expected the pre-restore database to be backed up, got %d backups (%v)", len(backups), err)
+	}
+}
+
+// withStdin runs fn with os.Stdin reading from content
+func withStdin(t *testing.T, content string, fn func()) {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatalf("Failed to create stdin file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("Failed to write stdin file: %v", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to rewind stdin file: %v", err)
+	}
+
+	original := os.Stdin
+	os.Stdin = file
+	defer func() { os.Stdin = original }()
+	fn()
+}
+
+func TestLowSpaceMessage(t *testing.T) {
+	const mb = 1024 * 1024
+	if msg := lowSpaceMessage(50*mb, 100, "/data"); !strings.Contains(msg, "only 50 MB free") || !strings.Contains(msg, "/data") {
+		t.Errorf("Expected a low space warning, got %q", msg)
+	}
+	if msg := lowSpaceMessage(200*mb, 100, "/data"); msg != "" {
+		t.Errorf("Expected no warning above the threshold, got %q", msg)
+	}
+	if msg := lowSpaceMessage(0, 0, "/data"); msg != "" {
+		t.Errorf("Expected a zero threshold to disable the warning, got %q", msg)
+	}
+}
+
+func TestStoreDiskFullHint(t *testing.T) {
+	err := withStoreHint(fmt.Errorf("failed to store item: %w", store.ErrDiskFull))
+	if !errors.Is(err, store.ErrDiskFull) {
+		t.Errorf("Hint should keep ErrDiskFull in the chain, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "rem clear") {
+		t.Errorf("Expected the hint to suggest 'rem clear', got %q", err.Error())
+	}
+
+	err = withStoreHint(fmt.Errorf("failed to store item: %w", memstore.ErrStoreFull))
+	if !errors.Is(err, memstore.ErrStoreFull) || !strings.Contains(err.Error(), "--db-path") {
+		t.Errorf("Expected a full memory store to suggest a file, got %v", err)
+	}
+
+	other := errors.New("boom")
+	if withStoreHint(other) != other {
+		t.Error("Other errors should be returned unchanged")
+	}
+
+	if err := (&ConfigSetCmd{Key: "low_space_warn_mb", Value: "50"}).Validate(); err != nil {
+		t.Errorf("low_space_warn_mb should be a valid config key: %v", err)
+	}
+}
+
+// withStdout runs fn and returns what it wrote to os.Stdout
+func withStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatalf("Failed to create stdout file: %v", err)
+	}
+	defer file.Close()
+
+	original := os.Stdout
+	os.Stdout = file
+	fn()
+	os.Stdout = original
+
+	output, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatalf("Failed to read stdout file: %v", err)
+	}
+	return string(output)
+}
+
+func withStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatalf("Failed to create stderr file: %v", err)
+	}
+	defer file.Close()
+
+	original := os.Stderr
+	os.Stderr = file
+	fn()
+	os.Stderr = original
+
+	output, err := os.ReadFile(file.Name())
+	if err != nil {
+		t.Fatalf("Failed to read stderr file: %v", err)
+	}
+	return string(output)
+}
+
+func TestGetCommand_PlainPicker(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "picker.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for i := 0; i < 25; i++ {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(fmt.Sprintf("content %d", i)), fmt.Sprintf("item %d", i)); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	pick := func(cmd *GetCmd, input string) (stdout, stderr string, err error) {
+		t.Helper()
+		withStdin(t, input, func() {
+			stderr = withStderr(t, func() {
+				stdout = withStdout(t, func() { err = cli.executeGet(cmd) })
+			})
+		})
+		return stdout, stderr, err
+	}
+
+	// The first page lists the newest items; the selection is got by index
+	stdout, stderr, err := pick(&GetCmd{NoTUI: true}, "\n3\n")
+	if err != nil {
+		t.Fatalf("Picker failed: %v", err)
+	}
+	if stdout != "content 21" {
+		t.Errorf("Expected index 3 on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "  0  just now    item 24\n") || !strings.Contains(stderr, "more? [y/N]") || strings.Contains(stderr, "item 4\n") {
+		t.Errorf("Expected one page of items followed by a more prompt, got:\n%s", stderr)
+	}
+
+	// "y" shows the next page, and an index at the more prompt selects it
+	stdout, stderr, err = pick(&GetCmd{NoTUI: true}, "y\n22\n")
+	if err != nil || stdout != "content 2" || !strings.Contains(stderr, " 24  just now    item 0\n") {
+		t.Errorf("Expected the second page and index 22, got %q, %v:\n%s", stdout, err, stderr)
+	}
+
+	// Output flags apply to the picked item
+	output := filepath.Join(tempDir, "picked.txt")
+	if _, _, err := pick(&GetCmd{NoTUI: true, Output: &output}, "n\n0\n"); err != nil {
+		t.Fatalf("Picker with --output failed: %v", err)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "content 24" {
+		t.Errorf("Expected the picked item in the output file, got %q", data)
+	}
+
+	// q, empty input, and end of input abort without output
+	for _, input := range []string{"n\nq\n", "n\n\n", ""} {
+		stdout, stderr, err := pick(&GetCmd{NoTUI: true}, input)
+		if err != nil || stdout != "" || !strings.Contains(stderr, "Aborted") {
+			t.Errorf("Expected input %q to abort, got %q, %v", input, stdout, err)
+		}
+	}
+
+	if _, _, err := pick(&GetCmd{NoTUI: true}, "n\nfirst\n"); err == nil {
+		t.Error("Expected a non-numeric selection to be rejected")
+	}
+	if _, _, err := pick(&GetCmd{NoTUI: true}, "n\n99\n"); err == nil {
+		t.Error("Expected an out of range selection to be rejected")
+	}
+}
+
+func TestSearchCommand_CountAndTitlesOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "search-count.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for _, data := range []struct{ content, title string }{
+		{"TODO one\nskip\nTODO two\n", "notes"},
+		{"nothing here", "TODO list"},
+	} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(data.content), data.title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	var searchErr error
+	output := withStdout(t, func() {
+		searchErr = cli.executeSearch(&SearchCmd{Pattern: "TODO", AllMatches: true, Count: true})
+	})
+	if searchErr != nil {
+		t.Fatalf("Search --count failed: %v", searchErr)
+	}
+	if want := "0\t1\tTODO list\ttitle\n1\t2\tnotes\tcontent: «TODO» one\n"; output != want {
+		t.Errorf("Search --count output = %q, want %q", output, want)
+	}
+
+	output = withStdout(t, func() {
+		searchErr = cli.executeSearch(&SearchCmd{Pattern: "TODO", AllMatches: true, TitlesOnly: true})
+	})
+	if searchErr != nil {
+		t.Fatalf("Search --titles-only failed: %v", searchErr)
+	}
+	if want := "0\tTODO list\ttitle\n1\tnotes\tcontent: «TODO» one\n"; output != want {
+		t.Errorf("Search --titles-only output = %q, want %q", output, want)
+	}
+
+	if err := (&SearchCmd{Pattern: "x", Count: true, TitlesOnly: true}).Validate(); err == nil {
+		t.Error("Expected --count with --titles-only to be rejected")
+	}
+}
+
+func TestSearchAndList_TimeWindow(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "time-window.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for _, content := range []string{"older", "newer"} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(content), content); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	recent, future := "1h", "2000-01-01"
+	var searchErr error
+	output := withStdout(t, func() {
+		searchErr = cli.executeSearch(&SearchCmd{Since: &recent, AllMatches: true, TitlesOnly: true})
+	})
+	if searchErr != nil {
+		t.Fatalf("Search with only --since failed: %v", searchErr)
+	}
+	if want := "0\tnewer\n1\tolder\n"; output != want {
+		t.Errorf("Search --since output = %q, want %q", output, want)
+	}
+
+	withStdout(t, func() {
+		searchErr = cli.executeSearch(&SearchCmd{Until: &future, AllMatches: true, TitlesOnly: true})
+	})
+	if searchErr == nil || !strings.Contains(searchErr.Error(), "time window") {
+		t.Errorf("Expected no items before 2000, got %v", searchErr)
+	}
+
+	var listErr error
+	output = withStdout(t, func() {
+		listErr = cli.executeList(&ListCmd{Since: &recent})
+	})
+	if listErr != nil {
+		t.Fatalf("List failed: %v", listErr)
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "0\t") || !strings.HasSuffix(lines[0], "\tnewer") {
+		t.Errorf("Unexpected list output %q", output)
+	}
+
+	output = withStdout(t, func() {
+		listErr = cli.executeList(&ListCmd{Until: &future})
+	})
+	if listErr != nil || output != "" {
+		t.Errorf("Expected an empty list before 2000, got %q (%v)", output, listErr)
+	}
+
+	if err := (&SearchCmd{}).Validate(); err == nil {
+		t.Error("Expected an empty pattern without a time window to be rejected")
+	}
+	if err := (&SearchCmd{Since: &recent}).Validate(); err != nil {
+		t.Errorf("Expected an empty pattern with --since to be allowed: %v", err)
+	}
+	bad := "yesterday"
+	if err := (&ListCmd{Until: &bad}).Validate(); err == nil {
+		t.Error("Expected an invalid --until to be rejected")
+	}
+}
+
+func TestStoreCommand_BinaryClipboard(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "binary-clipboard.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	data := []byte("PNG\x00\x01\xff\xfe not text")
+	mock := mockboard.New()
+	mock.SetData(data)
+	cli.clipboard = mock
+
+	out := withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Clipboard: true}); err != nil {
+			t.Errorf("rem store -c failed: %v", err)
+		}
+	})
+	if want := fmt.Sprintf("Stored from clipboard (%d bytes): [binary content]\n", len(data)); out != want {
+		t.Errorf("Expected %q, got %q", want, out)
+	}
+
+	item, err := cli.queueManager.Get(0)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !item.IsBinary || item.Title != "[binary content]" {
+		t.Errorf("Expected a binary item titled [binary content], got IsBinary=%v title=%q", item.IsBinary, item.Title)
+	}
+	reader, err := cli.queueManager.GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent failed: %v", err)
+	}
+	defer reader.Close()
+	if stored, _ := io.ReadAll(reader); !bytes.Equal(stored, data) {
+		t.Errorf("Expected the raw bytes to round-trip, got %q", stored)
+	}
+}
+
+func TestStoreCommand_Filters(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "filters.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "default_filters", Value: "strip-ansi"}); err != nil {
+		t.Fatalf("Failed to set default_filters: %v", err)
+	}
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "default_filters", Value: "strip-ansi,bogus"}); err == nil {
+		t.Error("Expected an unknown filter in default_filters to be rejected")
+	}
+
+	title := "filtered"
+	withStdin(t, "\x1b[31mred\x1b[0m  \r\n\tindented\r\n", func() {
+		cmd := &StoreCmd{Title: &title, Filters: []string{"dos2unix", "trim-trailing", "expand-tabs=2"}}
+		if err := cli.executeStore(cmd); err != nil {
+			t.Fatalf("Failed to store with filters: %v", err)
+		}
+	})
+
+	want := "red\n  indented\n"
+	item, err := cli.queueManager.Get(0)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	sum := sha256.Sum256([]byte(want))
+	if item.Size != int64(len(want)) || item.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Size/SHA256 should describe the filtered bytes, got %d/%s", item.Size, item.SHA256)
+	}
+
+	reader, err := cli.queueManager.GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	if string(content) != want {
+		t.Errorf("Stored content = %q, want %q", content, want)
+	}
+
+	if err := (&StoreCmd{Filters: []string{"expand-tabs=0"}}).Validate(); err == nil {
+		t.Error("Expected an invalid --filter to be rejected")
+	}
+}
+
+func TestStoreCommand_Tee(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "tee.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Larger than a chunk, with bytes the filters change
+	input := strings.Repeat("line\t\x1b[1mbold\x1b[0m  \r\n", 20000)
+	var stdout, stderr string
+	withStdin(t, input, func() {
+		stderr = withStderr(t, func() {
+			stdout = withStdout(t, func() {
+				cmd := &StoreCmd{Tee: true, Filters: []string{"strip-ansi", "dos2unix", "trim-trailing"}}
+				if err := cli.executeStore(cmd); err != nil {
+					t.Fatalf("Failed to store with --tee: %v", err)
+				}
+			})
+		})
+	})
+
+	if stdout != input {
+		t.Errorf("stdout differs from stdin (%d bytes, want %d)", len(stdout), len(input))
+	}
+	if !strings.HasPrefix(stderr, "Stored: ") {
+		t.Errorf("Expected the confirmation on stderr, got %q", stderr)
+	}
+
+	item, err := cli.queueManager.Get(0)
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	reader, err := cli.queueManager.GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("Failed to get content: %v", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	if want := strings.Repeat("line\tbold\n", 20000); string(content) != want {
+		t.Errorf("Stored %d bytes, want the %d filtered bytes", len(content), len(want))
+	}
+
+	withStdin(t, "", func() {
+		if err := cli.executeStore(&StoreCmd{Tee: true}); err == nil {
+			t.Error("Expected empty stdin to fail without --allow-empty")
+		}
+	})
+	if err := (&StoreCmd{Tee: true, Clipboard: true}).Validate(); err == nil {
+		t.Error("Expected --tee with --clipboard to be rejected")
+	}
+}
+
+func TestStoreCommand_TitleTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "title-template.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	store := func(cmd *StoreCmd, content string) string {
+		t.Helper()
+		withStdin(t, content, func() {
+			if err := cli.executeStore(cmd); err != nil {
+				t.Fatalf("Failed to store: %v", err)
+			}
+		})
+		item, err := cli.queueManager.Get(0)
+		if err != nil {
+			t.Fatalf("Failed to get item: %v", err)
+		}
+		return item.Title
+	}
+
+	hostname, _ := os.Hostname()
+	tmpl := "{{.Source}}@{{.Hostname}}: {{.FirstLine}} ({{.Now.Year}})"
+	got := store(&StoreCmd{TitleTemplate: &tmpl}, "  build failed  \nsecond line\n")
+	want := fmt.Sprintf("stdin@%s: build failed (%d)", hostname, time.Now().Year())
+	if got != want {
+		t.Errorf("Templated title = %q, want %q", got, want)
+	}
+
+	// File sources are named by the file, and rendered titles are still truncated
+	path := filepath.Join(tempDir, "notes.txt")
+	os.WriteFile(path, []byte(strings.Repeat("x", 200)), 0o644)
+	long := "{{.Source}} {{.FirstLine}}"
+	withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Files: []string{path}, TitleTemplate: &long}); err != nil {
+			t.Fatalf("Failed to store file: %v", err)
+		}
+	})
+	item, _ := cli.queueManager.Get(0)
+	if !strings.HasPrefix(item.Title, path+" xxx") || len([]rune(item.Title)) > 80 {
+		t.Errorf("Expected a truncated title naming the file, got %q", item.Title)
+	}
+
+	// The config default applies without flags; an explicit --title wins
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "default-title-template", Value: "log: {{.FirstLine}}"}); err != nil {
+		t.Fatalf("Failed to set default_title_template: %v", err)
+	}
+	if got := store(&StoreCmd{}, "from default\n"); got != "log: from default" {
+		t.Errorf("Default templated title = %q, want %q", got, "log: from default")
+	}
+	explicit := "explicit"
+	if got := store(&StoreCmd{Title: &explicit, TitleTemplate: &tmpl}, "ignored\n"); got != "explicit" {
+		t.Errorf("Expected --title to win over templates, got %q", got)
+	}
+
+	// Invalid templates are rejected before any content is read
+	for _, bad := range []string{"{{.FirstLine", "{{.Missing}}"} {
+		if err := (&StoreCmd{TitleTemplate: &bad}).Validate(); err == nil {
+			t.Errorf("Expected --title-template %q to be rejected", bad)
+		}
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "default_title_template", Value: bad}); err == nil {
+			t.Errorf("Expected default_title_template %q to be rejected", bad)
+		}
+	}
+	if err := cli.store.Config().Set("default_title_template", "{{.Missing}}"); err != nil {
+		t.Fatalf("Failed to set config: %v", err)
+	}
+	withStdin(t, "unread\n", func() {
+		if err := cli.executeStore(&StoreCmd{}); err == nil || !strings.Contains(err.Error(), "default_title_template") {
+			t.Errorf("Expected an invalid default_title_template error, got %v", err)
+		}
+		if rest, _ := io.ReadAll(os.Stdin); string(rest) != "unread\n" {
+			t.Errorf("Expected stdin to be left unread, got %q", rest)
+		}
+	})
+}
+
+func TestStoreCommand_TitleStrategy(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "title-strategy.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "title_strategy", Value: "last-line"}); err != nil {
+		t.Fatalf("Failed to set title_strategy: %v", err)
+	}
+	for key, bad := range map[string]string{"title_strategy": "middle-line", "title_sample_bytes": "2MiB"} {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: key, Value: bad}); err == nil {
+			t.Errorf("Expected %s %q to be rejected", key, bad)
+		}
+	}
+	cli.store.Close()
+
+	// The strategy applies from the next run on
+	cli, err = NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to reopen CLI: %v", err)
+	}
+	defer cli.store.Close()
+	store := func(cmd *StoreCmd, content string) string {
+		t.Helper()
+		withStdin(t, content, func() {
+			withStdout(t, func() {
+				if err := cli.executeStore(cmd); err != nil {
+					t.Fatalf("Failed to store: %v", err)
+				}
+			})
+		})
+		item, err := cli.queueManager.Get(0)
+		if err != nil {
+			t.Fatalf("Failed to get item: %v", err)
+		}
+		return item.Title
+	}
+	output := "go build ./...\ncompiling 12 packages\nBuild succeeded in 42s\n"
+	if got := store(&StoreCmd{}, output); got != "Build succeeded in 42s" {
+		t.Errorf("last-line title = %q", got)
+	}
+	line := 2
+	if got := store(&StoreCmd{TitleLine: &line}, output); got != "compiling 12 packages" {
+		t.Errorf("--title-line 2 title = %q", got)
+	}
+
+	title := "explicit"
+	for _, cmd := range []*StoreCmd{{TitleLine: &line, Title: &title}, {TitleLine: new(int)}} {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cmd)
+		}
+	}
+}
+
+func TestStoreCommand_TitleSources(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "title-sources.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// A title file names every positional file stored with it
+	titleFile := filepath.Join(tempDir, "CHANGELOG.md")
+	os.WriteFile(titleFile, []byte("\n  \n  Release 1.2\tnotes  \nmore\n"), 0o644)
+	a := filepath.Join(tempDir, "a.txt")
+	b := filepath.Join(tempDir, "b.txt")
+	os.WriteFile(a, []byte("alpha\n"), 0o644)
+	os.WriteFile(b, []byte("beta\n"), 0o644)
+	withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Files: []string{a, b}, TitleFile: &titleFile}); err != nil {
+			t.Fatalf("store --title-file failed: %v", err)
+		}
+	})
+	for i := range 2 {
+		item, _ := cli.queueManager.Get(i)
+		if item.Title != "Release 1.2 notes" {
+			t.Errorf("Item %d title = %q, want the title file's first line, sanitized", i, item.Title)
+		}
+	}
+
+	// The clipboard titles stdin content
+	mock := mockboard.New()
+	mock.SetData([]byte("ticket-42: flaky test\nsecond line"))
+	cli.clipboard = mock
+	withStdin(t, "log output\n", func() {
+		withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{TitleFromClipboard: true}); err != nil {
+				t.Fatalf("store --title-from-clipboard failed: %v", err)
+			}
+		})
+	})
+	item, _ := cli.queueManager.Get(0)
+	if item.Title != "ticket-42: flaky test" {
+		t.Errorf("Title = %q, want the clipboard's first line", item.Title)
+	}
+	if content := readItem(t, cli, item.ID); content != "log output\n" {
+		t.Errorf("Content = %q, want stdin", content)
+	}
+
+	// Empty and binary sources fail before the content is read
+	binaryFile := filepath.Join(tempDir, "image.png")
+	os.WriteFile(binaryFile, []byte("PNG\x00\x01"), 0o644)
+	blankFile := filepath.Join(tempDir, "blank.txt")
+	os.WriteFile(blankFile, []byte(" \n\n"), 0o644)
+	for _, tc := range []struct {
+		cmd  *StoreCmd
+		clip string
+		want string
+	}{
+		{&StoreCmd{TitleFromClipboard: true}, "", "the clipboard: it is empty"},
+		{&StoreCmd{TitleFromClipboard: true}, "\x00\x01\x02", "the clipboard: it is binary"},
+		{&StoreCmd{TitleFile: &blankFile}, "", "blank.txt: it is empty"},
+		{&StoreCmd{TitleFile: &binaryFile}, "", "image.png: it is binary"},
+		{&StoreCmd{TitleFile: stringPtr(filepath.Join(tempDir, "missing"))}, "", "failed to read --title-file"},
+	} {
+		mock.SetData([]byte(tc.clip))
+		withStdin(t, "unread\n", func() {
+			if err := cli.executeStore(tc.cmd); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected an error containing %q, got %v", tc.want, err)
+			}
+			if rest, _ := io.ReadAll(os.Stdin); string(rest) != "unread\n" {
+				t.Errorf("Expected stdin to be left unread, got %q", rest)
+			}
+		})
+	}
+
+	// The title flags exclude each other, and the clipboard can't be both
+	for _, cmd := range []*StoreCmd{
+		{Title: stringPtr("x"), TitleFile: &titleFile},
+		{Title: stringPtr("x"), TitleFromClipboard: true},
+		{TitleFile: &titleFile, TitleFromClipboard: true},
+		{Clipboard: true, TitleFromClipboard: true},
+		{Recursive: true, Files: []string{tempDir}, TitleFile: &titleFile},
+	} {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cmd)
+		}
+	}
+	if err := (&StoreCmd{Files: []string{a}, TitleFile: &titleFile}).Validate(); err != nil {
+		t.Errorf("Expected --title-file with files to be valid, got %v", err)
+	}
+}
+
+func TestEmptyItems(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "empty-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Empty stdin is rejected unless --allow-empty is given
+	withStdin(t, "", func() {
+		if err := cli.executeStore(&StoreCmd{}); err == nil {
+			t.Error("Expected empty stdin to be rejected")
+		}
+	})
+	withStdin(t, "", func() {
+		if err := cli.executeStore(&StoreCmd{AllowEmpty: true}); err != nil {
+			t.Errorf("Expected --allow-empty to store empty stdin: %v", err)
+		}
+	})
+
+	// Empty files always store, with a placeholder title
+	emptyFile := filepath.Join(tempDir, "empty.txt")
+	if err := os.WriteFile(emptyFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := cli.executeStore(&StoreCmd{Files: []string{emptyFile}}); err != nil {
+		t.Fatalf("Failed to store empty file: %v", err)
+	}
+
+	items, err := cli.queueManager.List()
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 stored items, got %d", len(items))
+	}
+	for _, item := range items {
+		if item.Title != "[empty]" || item.Size != 0 {
+			t.Errorf("Expected empty item titled [empty], got %q (%d bytes)", item.Title, item.Size)
+		}
+	}
+
+	// Getting an empty item succeeds and writes an empty file
+	index := 0
+	outFile := filepath.Join(tempDir, "out.txt")
+	if err := cli.executeGet(&GetCmd{Index: indexArg(index), File: &outFile, Verbose: true}); err != nil {
+		t.Fatalf("rem get of an empty item failed: %v", err)
+	}
+	if info, err := os.Stat(outFile); err != nil || info.Size() != 0 {
+		t.Errorf("Expected an empty output file, got %v, %v", info, err)
+	}
+}
+
+func TestConfigKeys_AliasesAndSuggestions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "keys-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Hyphenated keys are stored under the canonical name
+	out := withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "history-limit", Value: "30"}); err != nil {
+			t.Fatalf("Failed to set history-limit: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Set history_limit = 30") {
+		t.Errorf("Expected the canonical key to be reported, got %q", out)
+	}
+	if value, _ := cli.store.Config().Get("history_limit"); value != "30" {
+		t.Errorf("Expected history_limit 30, got %q", value)
+	}
+	if err := (&ConfigGetCmd{Key: "db_path"}).Validate(); err != nil {
+		t.Errorf("db_path should be a valid key: %v", err)
+	}
+
+	for _, tt := range []struct {
+		key, value, want string
+	}{
+		{"history-limt", "30", "did you mean 'history_limit'?"},
+		{"scrolbar", "true", "did you mean 'scrollbar'?"},
+		{"show_binary", "yes", "invalid value 'yes' for show_binary (allowed: true, false)"},
+	} {
+		err := cli.executeConfigSet(&ConfigSetCmd{Key: tt.key, Value: tt.value})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("set %s %s: expected error containing %q, got %v", tt.key, tt.value, tt.want, err)
+		}
+	}
+	if err := (&ConfigGetCmd{Key: "colour"}).Validate(); err == nil || !strings.Contains(err.Error(), "valid keys are") {
+		t.Errorf("Expected an unknown key with no close match to list valid keys, got %v", err)
+	}
+}
+
+func TestSearchHistory_Persistence(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "search-history.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Patterns saved on exit are loaded into the next session's model
+	var patterns []string
+	for i := 0; i < tui.MaxSearchHistory+5; i++ {
+		patterns = append(patterns, fmt.Sprintf(`error \d+ "%d"`, i))
+	}
+	if err := cli.saveSearchHistory(patterns); err != nil {
+		t.Fatalf("Failed to save search history: %v", err)
+	}
+	value, err := cli.store.Config().Get("search_history")
+	if err != nil {
+		t.Fatalf("Failed to get search_history: %v", err)
+	}
+	loaded, err := parseSearchHistory(value)
+	if err != nil {
+		t.Fatalf("Failed to parse search_history: %v", err)
+	}
+	if !slices.Equal(loaded, patterns[5:]) {
+		t.Errorf("Expected the newest %d patterns to round-trip, got %v", tui.MaxSearchHistory, loaded)
+	}
+
+	model := tui.NewModel(nil, nil)
+	model.SetSearchHistory(loaded)
+	if got := model.SearchHistory(); !slices.Equal(got, patterns[5:]) {
+		t.Errorf("Expected the model to recall the saved patterns, got %v", got)
+	}
+
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "search_history", Value: "[]"}); err != nil {
+		t.Errorf("Expected [] to clear search_history: %v", err)
+	}
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "search_history", Value: "not json"}); err == nil {
+		t.Error("Expected a search_history that isn't a JSON array to be rejected")
+	}
+}
+
+func TestConfigList_Descriptions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "list-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	out := withStdout(t, func() {
+		if err := cli.executeConfigList(&ConfigListCmd{}); err != nil {
+			t.Fatalf("config list failed: %v", err)
+		}
+	})
+	for _, want := range []string{"history_limit = ", "# number of items kept", "db_path = " + dbPath} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected config list to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "history_limit") > strings.Index(out, "db_version") {
+		t.Errorf("Expected keys in registry order, got:\n%s", out)
+	}
+	if strings.Contains(out, "changed ") {
+		t.Errorf("Expected no change times without --verbose, got:\n%s", out)
+	}
+
+	// --verbose adds when each stored value was last set
+	out = withStdout(t, func() {
+		if err := cli.executeConfigList(&ConfigListCmd{Verbose: true}); err != nil {
+			t.Fatalf("config list --verbose failed: %v", err)
+		}
+	})
+	changed := regexp.MustCompile(`changed \d{4}-\d\d-\d\d \d\d:\d\d`)
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "db_path = ") && strings.Contains(line, "changed ") {
+			t.Errorf("Expected no change time for db_path, which isn't stored: %q", line)
+		}
+		if strings.Contains(line, "history_limit = ") && !changed.MatchString(line) {
+			t.Errorf("Expected a change time for history_limit: %q", line)
+		}
+	}
+}
+
+func TestClearCommand_ResetIDs(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "clear-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	enqueue := func(content string) uint {
+		item, err := cli.queueManager.Enqueue(strings.NewReader(content), content)
+		if err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		return item.ID
+	}
+	runClear := func(cmd *ClearCmd) string {
+		return withStdout(t, func() {
+			if err := cli.executeClear(cmd); err != nil {
+				t.Fatalf("clear failed: %v", err)
+			}
+		})
+	}
+
+	// By default IDs carry on after a clear
+	enqueue("one")
+	enqueue("two")
+	runClear(&ClearCmd{Force: true})
+	if id := enqueue("three"); id != 3 {
+		t.Errorf("Expected ID 3 after clear, got %d", id)
+	}
+
+	if err := cli.recordCopies([]uint{3, 1}); err != nil {
+		t.Fatalf("Failed to record copies: %v", err)
+	}
+	if out := runClear(&ClearCmd{Force: true, ResetIDs: true}); !strings.Contains(out, "Item IDs start from 1 again") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if id := enqueue("four"); id != 1 {
+		t.Errorf("Expected ID 1 after clear --reset-ids, got %d", id)
+	}
+	// The reused ID isn't marked as copied
+	if copies, err := cli.loadRecentCopies(); err != nil || len(copies) != 0 {
+		t.Errorf("Expected no recent copies after clear --reset-ids, got %v (%v)", copies, err)
+	}
+
+	// An empty queue can still be reset
+	runClear(&ClearCmd{Force: true})
+	if out := runClear(&ClearCmd{ResetIDs: true}); !strings.Contains(out, "already empty; item IDs start from 1 again") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	if id := enqueue("five"); id != 1 {
+		t.Errorf("Expected ID 1 after resetting an empty queue, got %d", id)
+	}
+}
+
+func TestStatsAndRecompress(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "stats-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "compress_min_bytes", Value: "0"}); err != nil {
+			t.Fatalf("Failed to set compress_min_bytes: %v", err)
+		}
+	})
+	content := strings.Repeat("the same log line, again and again\n", 5000)
+	if _, err := cli.queueManager.Enqueue(strings.NewReader(content), "log"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	out := withStdout(t, func() {
+		if err := cli.executeStats(&StatsCmd{}); err != nil {
+			t.Fatalf("stats failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, fmt.Sprintf("Logical bytes:  %d", len(content))) || !strings.Contains(out, "(0 compressed)") {
+		t.Errorf("Expected uncompressed stats, got:\n%s", out)
+	}
+
+	withStdout(t, func() {
+		if err := cli.executeConfigSet(&ConfigSetCmd{Key: "compress_min_bytes", Value: "64KiB"}); err != nil {
+			t.Fatalf("Failed to set compress_min_bytes: %v", err)
+		}
+	})
+	out = withStdout(t, func() {
+		if err := cli.executeMaintenance(&MaintenanceCmd{Recompress: &MaintenanceRecompressCmd{}}); err != nil {
+			t.Fatalf("recompress failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Recompressed 1 of 1 item(s)") {
+		t.Errorf("Expected one item recompressed, got %q", out)
+	}
+	out = withStdout(t, func() {
+		cli.executeStats(&StatsCmd{})
+	})
+	if !strings.Contains(out, "(1 compressed)") {
+		t.Errorf("Expected the item to be compressed, got:\n%s", out)
+	}
+
+	if err := (&Args{ReadOnly: true, Maintenance: &MaintenanceCmd{Recompress: &MaintenanceRecompressCmd{}}}).Validate(); err == nil {
+		t.Error("Expected maintenance to be rejected with --read-only")
+	}
+}
+
+// newVerifyTestDB stores n items of size bytes each in a new database
+func newVerifyTestDB(tb testing.TB, n, size int) (*CLI, string) {
+	dbPath := filepath.Join(tb.TempDir(), "verify-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		tb.Fatalf("Failed to create CLI: %v", err)
+	}
+	tb.Cleanup(func() { cli.store.Close() })
+	for i := range n {
+		content := strings.Repeat(fmt.Sprintf("line %d of item %d\n", i, i), size/8)[:size]
+		if _, err := cli.store.History().Create(&store.CreateHistoryInput{Title: fmt.Sprintf("item %d", i), Content: strings.NewReader(content)}); err != nil {
+			tb.Fatalf("Failed to create item: %v", err)
+		}
+	}
+	return cli, dbPath
+}
+
+func TestMaintenanceVerify(t *testing.T) {
+	cli, dbPath := newVerifyTestDB(t, 30, 100<<10)
+	history := cli.store.History()
+	verify := func(jobs int) []string {
+		var problems []string
+		runner := &maintenanceRunner{source: history, jobs: jobs}
+		if _, err := runner.runChecks(context.Background(), verifyCheck(history, &problems)); err != nil {
+			t.Fatalf("verify with %d job(s) failed: %v", jobs, err)
+		}
+		return problems
+	}
+	if problems := verify(4); len(problems) != 0 {
+		t.Fatalf("Expected an intact database to verify, got %v", problems)
+	}
+
+	// Flip bytes in one item's chunk and cut another's short
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	items, _ := history.List(0)
+	if err := db.Exec("UPDATE file_chunks SET data = ? WHERE history_id = ? AND sequence = 1", bytes.Repeat([]byte("x"), 32<<10), items[5].ID).Error; err != nil {
+		t.Fatalf("Failed to corrupt a chunk: %v", err)
+	}
+	if err := db.Exec("DELETE FROM file_chunks WHERE history_id = ? AND sequence = 2", items[20].ID).Error; err != nil {
+		t.Fatalf("Failed to delete a chunk: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	serial, parallel := verify(1), verify(4)
+	if len(serial) != 2 {
+		t.Fatalf("Expected both damaged items reported, got %v", serial)
+	}
+	if !slices.Equal(serial, parallel) {
+		t.Errorf("Parallel verify reported\n%v\nserial reported\n%v", parallel, serial)
+	}
+
+	out := withStdout(t, func() {
+		if err := cli.executeMaintenance(&MaintenanceCmd{Verify: &MaintenanceVerifyCmd{}}); err == nil {
+			t.Error("Expected verify to fail")
+		}
+	})
+	if !strings.Contains(out, "Verified 30 item(s): 2 problem(s)") || !strings.Contains(out, serial[0]) {
+		t.Errorf("Unexpected verify output:\n%s", out)
+	}
+
+	if err := (&Args{ReadOnly: true, Maintenance: &MaintenanceCmd{Verify: &MaintenanceVerifyCmd{}}}).Validate(); err != nil {
+		t.Errorf("Expected verify to be allowed with --read-only, got %v", err)
+	}
+	if err := (&MaintenanceCmd{Verify: &MaintenanceVerifyCmd{Jobs: 9}}).Validate(); err == nil {
+		t.Error("Expected --jobs 9 to be rejected")
+	}
+}
+
+func BenchmarkMaintenanceVerify(b *testing.B) {
+	cli, _ := newVerifyTestDB(b, 64, 256<<10)
+	history := cli.store.History()
+	for _, jobs := range []int{1, 4, maxJobs} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			runner := &maintenanceRunner{source: history, jobs: jobs}
+			for b.Loop() {
+				var problems []string
+				if _, err := runner.runChecks(context.Background(), verifyCheck(history, &problems)); err != nil || len(problems) > 0 {
+					b.Fatalf("verify failed: %v %v", err, problems)
+				}
+			}
+		})
+	}
+}
+
+func TestStoreCommand_Recursive(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "recursive-test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	root := filepath.Join(tempDir, "conf")
+	files := map[string]string{
+		"app.conf":              "port = 80\n",
+		"nginx/site.conf":       "server {}\n",
+		"nginx/deep/extra.conf": "extra\n",
+		"notes.txt":             "excluded by --include\n",
+		"cache/big.conf":        "excluded by --exclude\n",
+		"large.conf":            strings.Repeat("x", 2048),
+		"secret.conf":           "unreadable\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A symlink to another directory, a symlink loop back to the root, and
+	// an unreadable file. Root can read any file, so the unreadable case
+	// only applies to other users.
+	if err := os.Symlink(filepath.Join(root, "nginx", "deep"), filepath.Join(root, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "nginx", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(root, "secret.conf")
+	os.Chmod(secret, 0)
+	unreadable := true
+	if f, err := os.Open(secret); err == nil {
+		f.Close()
+		unreadable = false
+	}
+
+	run := func(cmd *StoreCmd) (string, error) {
+		cmd.Recursive = true
+		cmd.Files = []string{root}
+		cmd.Include = []string{"*.conf"}
+		cmd.Exclude = []string{"cache"}
+		size := "1KB"
+		cmd.MaxFileSize = &size
+		if err := cmd.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+		var runErr error
+		out := withStdout(t, func() { runErr = cli.executeStore(cmd) })
+		return out, runErr
+	}
+
+	out, err := run(&StoreCmd{})
+	wantTitles := []string{"app.conf", "nginx/deep/extra.conf", "nginx/site.conf"}
+	wantSummary := "Stored 3 file(s), skipped 3, failed 1"
+	if !unreadable {
+		wantTitles = append(wantTitles, "secret.conf")
+		wantSummary = "Stored 4 file(s), skipped 3, failed 0"
+	}
+	if !strings.Contains(out, wantSummary) {
+		t.Errorf("Expected summary %q, got:\n%s", wantSummary, out)
+	}
+	if unreadable && err == nil {
+		t.Error("Expected an error when a file cannot be read")
+	}
+
+	items, _ := cli.queueManager.List()
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	slices.Sort(titles)
+	if !slices.Equal(titles, wantTitles) {
+		t.Errorf("Expected items %v, got %v", wantTitles, titles)
+	}
+
+	// Following symlinks stores the linked files but stops at the loop
+	out, _ = run(&StoreCmd{FollowSymlinks: true})
+	if !strings.Contains(out, ": linked/extra.conf") {
+		t.Errorf("Expected files under the followed symlink to be stored, got:\n%s", out)
+	}
+	if strings.Contains(out, "loop/") {
+		t.Errorf("Expected the symlink loop not to be walked, got:\n%s", out)
+	}
+
+	if err := (&StoreCmd{Files: []string{root}, Include: []string{"*.conf"}}).Validate(); err == nil {
+		t.Error("Expected --include without --recursive to be rejected")
+	}
+	if err := (&StoreCmd{Recursive: true, Files: []string{root}, Include: []string{"["}}).Validate(); err == nil {
+		t.Error("Expected an invalid glob to be rejected")
+	}
+}
+
+func TestGetCommand_Restore(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "restore.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	content := []byte("#!/bin/sh\necho restored\n")
+	srcDir := filepath.Join(tempDir, "src")
+	os.Mkdir(srcDir, 0o755)
+	path := filepath.Join(srcDir, "deploy.sh")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o751); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Files: []string{path}}); err != nil {
+			t.Fatalf("Failed to store file: %v", err)
+		}
+	})
+	withStdin(t, "piped", func() {
+		if err := cli.executeStore(&StoreCmd{}); err != nil {
+			t.Fatalf("Failed to store: %v", err)
+		}
+	})
+
+	info := withStdout(t, func() {
+		index := 1
+		if err := cli.executeInfo(&InfoCmd{Index: indexArg(index)}); err != nil {
+			t.Fatalf("Failed to show info: %v", err)
+		}
+	})
+	for _, want := range []string{"File:     deploy.sh", "Mode:     -rwxr-x--x", "Modified: " + modTime.Local().Format(time.RFC3339)} {
+		if !strings.Contains(info, want) {
+			t.Errorf("Expected info to contain %q, got:\n%s", want, info)
+		}
+	}
+
+	outDir := filepath.Join(tempDir, "out")
+	os.Mkdir(outDir, 0o755)
+	t.Chdir(outDir)
+
+	index := 0
+	if err := cli.executeGet(&GetCmd{Index: indexArg(index), Restore: true}); err == nil {
+		t.Error("Expected error restoring an item not stored from a file")
+	}
+
+	index = 1
+	withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(index), Restore: true}); err != nil {
+			t.Fatalf("Failed to restore: %v", err)
+		}
+	})
+
+	restored := filepath.Join(outDir, "deploy.sh")
+	stat, err := os.Stat(restored)
+	if err != nil {
+		t.Fatalf("Expected restored file: %v", err)
+	}
+	if stat.Mode() != 0o751 {
+		t.Errorf("Restored mode = %v, want %v", stat.Mode(), fs.FileMode(0o751))
+	}
+	if !stat.ModTime().Equal(modTime) {
+		t.Errorf("Restored mtime = %v, want %v", stat.ModTime(), modTime)
+	}
+	data, _ := os.ReadFile(restored)
+	if sha256.Sum256(data) != sha256.Sum256(content) {
+		t.Errorf("Restored content hash differs: got %q", data)
+	}
+}
+
+func TestSearchCommand_TUI(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "search-tui.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for _, content := range []string{"first panic here", "all quiet", "second\npanic: nil map", "nothing"} {
+		if _, err := cli.queueManager.Enqueue(strings.NewReader(content), ""); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+
+	defer func(run func(tea.Model) (tea.Model, error)) { runProgram = run }(runProgram)
+	var views []string
+	runProgram = func(model tea.Model) (tea.Model, error) {
+		send := func(msg tea.Msg) {
+			model, _ = model.Update(msg)
+			views = append(views, model.View())
+		}
+		send(tea.WindowSizeMsg{Width: 120, Height: 30})
+		// Delete the second match, the older of the two
+		send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+		send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+		send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		return model, nil
+	}
+
+	if err := cli.executeSearch(&SearchCmd{Pattern: "PANIC", TUI: true}); err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	first := views[0]
+	// The title wraps onto the line under it in the narrow left pane
+	for _, want := range []string{"Search: PANIC (2", "results)", "second", "first panic here", "Pattern: PANIC - Match 1 of 1"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("Expected the TUI to show %q, got:\n%s", want, first)
+		}
+	}
+	for _, unwanted := range []string{"all quiet", "nothing"} {
+		if strings.Contains(first, unwanted) {
+			t.Errorf("Expected only matches in the TUI, found %q", unwanted)
+		}
+	}
+
+	items, err := cli.queueManager.List()
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if want := []string{"nothing", "second", "all quiet"}; !slices.Equal(titles, want) {
+		t.Errorf("Expected the older match to be deleted, leaving %q, got %q", want, titles)
+	}
+}
+
+func TestItemRefs(t *testing.T) {
+	var args Args
+	parser, err := arg.NewParser(arg.Config{}, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.Parse([]string{"get", "@001A"}); err != nil || args.Get.Index.Ref == nil || *args.Get.Index.Ref != 42 {
+		t.Fatalf("Expected get @001A to parse as ID 42, got %+v (%v)", args.Get, err)
+	}
+	if err := parser.Parse([]string{"info", "3"}); err != nil || args.Info.Index.Ref != nil || args.Info.Index.Index != 3 {
+		t.Fatalf("Expected info 3 to parse as index 3, got %+v (%v)", args.Info, err)
+	}
+	for _, argv := range [][]string{{"get", "@0u"}, {"bump", "@"}, {"info", "x"}} {
+		if err := parser.Parse(argv); err == nil {
+			t.Errorf("Expected %q to be rejected", argv)
+		}
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	oldest, _ := cli.queueManager.Enqueue(strings.NewReader("oldest content"), "oldest")
+	middle, _ := cli.queueManager.Enqueue(strings.NewReader("middle content"), "middle")
+	cli.queueManager.Enqueue(strings.NewReader("newest content"), "newest")
+	oldestRef, middleRef := "@"+ref.Encode(oldest.ID), "@"+ref.Encode(middle.ID)
+	refArg := func(s string) *ItemArg {
+		arg, err := parseItemArg(s)
+		if err != nil {
+			t.Fatalf("parseItemArg(%q): %v", s, err)
+		}
+		return &arg
+	}
+
+	// rem list and rem info show each item's reference
+	output := withStdout(t, func() { cli.executeList(&ListCmd{}) })
+	if !strings.HasPrefix(strings.Split(output, "\n")[2], "2\t"+oldestRef+"\t") {
+		t.Errorf("Expected the reference in the second column, got %q", output)
+	}
+	output = withStdout(t, func() { cli.executeInfo(&InfoCmd{Index: indexArg(1)}) })
+	if !strings.Contains(output, "Ref:      "+middleRef+"\n") {
+		t.Errorf("Expected rem info to show %s, got %q", middleRef, output)
+	}
+
+	// A reference names the same item however the queue moves
+	var getErr error
+	output = withStdout(t, func() { getErr = cli.executeGet(&GetCmd{Index: refArg(oldestRef)}) })
+	if getErr != nil || output != "oldest content" {
+		t.Errorf("Expected get %s to print the oldest item, got %q (%v)", oldestRef, output, getErr)
+	}
+	withStdout(t, func() {
+		if err := cli.executeBump(&BumpCmd{Index: refArg(oldestRef)}); err != nil {
+			t.Fatalf("rem bump %s failed: %v", oldestRef, err)
+		}
+	})
+	if top, _ := cli.queueManager.Get(0); top.ID != oldest.ID {
+		t.Errorf("Expected bump %s to move the oldest item to the top, got %q", oldestRef, top.Title)
+	}
+	output = withStdout(t, func() { getErr = cli.executeGet(&GetCmd{Index: refArg(strings.ToUpper(oldestRef)), JSON: true}) })
+	var meta itemJSON
+	if err := json.Unmarshal([]byte(output), &meta); err != nil || meta.Index != 0 || meta.Ref != oldestRef {
+		t.Errorf("Expected JSON with index 0 and ref %s, got %q (%v)", oldestRef, output, err)
+	}
+
+	// Title and note take a reference in place of the index. With --id every
+	// argument is the text, even one that looks like a reference.
+	withStdout(t, func() {
+		if err := cli.executeTitle(&TitleCmd{Args: []string{middleRef, "@home"}}); err != nil {
+			t.Fatalf("rem title %s failed: %v", middleRef, err)
+		}
+		if err := cli.executeNote(&NoteCmd{Args: []string{oldestRef}, ID: &middle.ID}); err != nil {
+			t.Fatalf("rem note --id failed: %v", err)
+		}
+	})
+	if item, _ := cli.store.History().Get(middle.ID); item.Title != "@home" || item.Note != oldestRef {
+		t.Errorf("Expected title @home and note %s, got %q and %q", oldestRef, item.Title, item.Note)
+	}
+	if err := (&TitleCmd{Args: []string{"@home"}}).Validate(); err == nil {
+		t.Error("Expected a lone title starting with @ to need --id")
+	}
+	if err := (&TitleCmd{Args: []string{"@home"}, ID: &middle.ID}).Validate(); err != nil {
+		t.Errorf("Expected a title starting with @ to be allowed with --id: %v", err)
+	}
+
+	// A reference and --id are both ways to name an item by ID, so only one is allowed
+	if err := (&InfoCmd{Index: refArg(middleRef), ID: &middle.ID}).Validate(); err == nil {
+		t.Error("Expected both a reference and --id to be rejected")
+	}
+	if err := (&GetCmd{Index: refArg(middleRef), Match: stringPtr("x")}).Validate(); err == nil {
+		t.Error("Expected both a reference and --match to be rejected")
+	}
+	// Unlike an index, a reference is never negative
+	if err := (&BumpCmd{Index: refArg("@0000")}).Validate(); err != nil {
+		t.Errorf("Expected @0000 to pass validation: %v", err)
+	}
+
+	if err := cli.executeGet(&GetCmd{Index: refArg("@zzzz")}); err == nil || !strings.Contains(err.Error(), "@zzzz") {
+		t.Errorf("Expected an error naming the missing reference, got %v", err)
+	}
+	if _, err := parseItemArg("@hellu"); !errors.Is(err, ref.ErrInvalid) {
+		t.Errorf("Expected @hellu to be an invalid reference, got %v", err)
+	}
+}
+
+func TestFindDuplicates_Window(t *testing.T) {
+	st := memstore.NewMemoryStore()
+	defer st.Close()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	add := func(content string, offset time.Duration) *store.HistoryItem {
+		item, err := st.History().Create(&store.CreateHistoryInput{Title: content, Content: strings.NewReader(content), Timestamp: base.Add(offset)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return item
+	}
+	// Oldest first; each gap is measured to the next newer item
+	edge := add("edge", 0)                       // exactly the window before the next: a copy
+	add("edge", 10*time.Second)                  // just past the window before the next: kept
+	add("edge", 20*time.Second+time.Millisecond) // a different item above it: kept
+	add("other", 30*time.Second)
+	add("edge", 31*time.Second)
+	first := add("run", 40*time.Second) // in a run of three copies,
+	second := add("run", 45*time.Second)
+	add("run", 50*time.Second) // only the newest is kept
+	// Equal timestamps are ordered by ID, so the lower ID is the older copy
+	empty := add("", time.Minute)
+	add("", time.Minute)
+
+	duplicates, scanned, err := findDuplicates(st.History(), 10*time.Second)
+	if err != nil {
+		t.Fatalf("findDuplicates failed: %v", err)
+	}
+	if scanned != 10 {
+		t.Errorf("Expected 10 items scanned, got %d", scanned)
+	}
+	var got []uint
+	for _, item := range duplicates {
+		got = append(got, item.ID)
+	}
+	if want := []uint{empty.ID, second.ID, first.ID, edge.ID}; !slices.Equal(got, want) {
+		t.Errorf("Expected duplicates %v, got %v", want, got)
+	}
+
+	if duplicates, _, _ := findDuplicates(st.History(), 0); len(duplicates) != 1 || duplicates[0].ID != empty.ID {
+		t.Errorf("Expected only same-instant copies with a zero window, got %d", len(duplicates))
+	}
+}
+
+func TestDedupeCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	for _, content := range []string{"ls", "ls", "pwd", "pwd", "ls"} {
+		cli.queueManager.Enqueue(strings.NewReader(content), "")
+	}
+
+	output := withStdout(t, func() {
+		if err := cli.executeDedupe(&DedupeCmd{DryRun: true}); err != nil {
+			t.Fatalf("rem dedupe --dry-run failed: %v", err)
+		}
+	})
+	if !strings.HasSuffix(output, "Would delete 2 duplicate(s) of 5 item(s)\n") || strings.Count(output, "Would delete item") != 2 {
+		t.Errorf("Unexpected dry-run output %q", output)
+	}
+	if n, _ := cli.queueManager.Size(); n != 5 {
+		t.Fatalf("Expected --dry-run to delete nothing, got %d items", n)
+	}
+
+	output = withStdout(t, func() {
+		if err := cli.executeDedupe(&DedupeCmd{}); err != nil {
+			t.Fatalf("rem dedupe failed: %v", err)
+		}
+	})
+	if output != "Deleted 2 duplicate(s) of 5 item(s)\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+	items, _ := cli.queueManager.List()
+	var titles []string
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	if fmt.Sprint(titles) != "[ls pwd ls]" {
+		t.Errorf("Expected [ls pwd ls] left, got %v", titles)
+	}
+
+	bad := "ten seconds"
+	if err := (&DedupeCmd{Window: &bad}).Validate(); err == nil {
+		t.Error("Expected an invalid --window to be rejected")
+	}
+	if err := (&Args{ReadOnly: true, Dedupe: &DedupeCmd{}}).Validate(); err == nil {
+		t.Error("Expected dedupe to be rejected with --read-only")
+	}
+	if err := (&Args{ReadOnly: true, Dedupe: &DedupeCmd{DryRun: true}}).Validate(); err != nil {
+		t.Errorf("Expected dedupe --dry-run to be allowed with --read-only: %v", err)
+	}
+
+	// coalesce_window_ms makes the next CLI absorb repeated stores
+	cli.store.Config().Set("coalesce_window_ms", "60000")
+	cli.store.Close()
+	cli, err = NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to reopen CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager.Enqueue(strings.NewReader("make test"), "")
+	cli.queueManager.Enqueue(strings.NewReader("make test"), "")
+	if n, _ := cli.queueManager.Size(); n != 4 {
+		t.Errorf("Expected the repeated store coalesced into 4 items, got %d", n)
+	}
+}
+
+func TestExpectGeneration(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	for _, title := range []string{"oldest", "middle", "newest"} {
+		cli.queueManager.Enqueue(strings.NewReader(title), title)
+	}
+
+	output := withStdout(t, func() { cli.executeList(&ListCmd{JSON: true}) })
+	var listed struct{ Generation *uint64 }
+	if err := json.Unmarshal([]byte(strings.SplitN(output, "\n", 2)[0]), &listed); err != nil || listed.Generation == nil {
+		t.Fatalf("Expected a generation in %q: %v", output, err)
+	}
+	generation := *listed.Generation
+
+	// Nothing changed yet, so index 1 is still the item listed there
+	output = withStdout(t, func() {
+		if err := cli.Execute(&Args{Get: &GetCmd{Index: indexArg(1)}, ExpectGeneration: &generation}); err != nil {
+			t.Fatalf("get with the current generation error = %v", err)
+		}
+	})
+	if output != "middle" {
+		t.Errorf("Expected the middle item, got %q", output)
+	}
+
+	// A delete in the middle moves the index onto another item
+	if err := cli.queueManager.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	err = cli.Execute(&Args{Get: &GetCmd{Index: indexArg(1)}, ExpectGeneration: &generation})
+	if !errors.Is(err, ErrQueueChanged) || ExitCode(err) != ExitStale {
+		t.Errorf("Expected a stale generation to fail with exit code %d, got %v", ExitStale, err)
+	}
+
+	output = withStdout(t, func() { cli.executeConfigGet(&ConfigGetCmd{Key: "generation"}) })
+	if want := fmt.Sprintf("%d\n", generation+1); output != want {
+		t.Errorf("config get generation = %q, want %q", output, want)
+	}
+	output = withStdout(t, func() { cli.executeConfigList(&ConfigListCmd{}) })
+	if want := fmt.Sprintf("  generation = %d ", generation+1); !strings.Contains(output, want) {
+		t.Errorf("Expected config list to show %q, got:\n%s", want, output)
+	}
+}
+
+func TestMetaCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager.Enqueue(strings.NewReader("deploy script"), "deploy")
+	cli.queueManager.Enqueue(strings.NewReader("nginx config"), "nginx")
+
+	withStdout(t, func() {
+		for _, cmd := range []*MetaSetCmd{
+			{Index: indexArg(1), Key: "ticket", Value: "OPS-12"},
+			{Index: indexArg(1), Key: "lang", Value: "sh"},
+			{Index: indexArg(0), Key: "ticket", Value: "OPS-7"},
+		} {
+			if err := cli.executeMeta(&MetaCmd{Set: cmd}); err != nil {
+				t.Fatalf("meta set %s error = %v", cmd.Key, err)
+			}
+		}
+	})
+
+	output := withStdout(t, func() {
+		if err := cli.executeMeta(&MetaCmd{Get: &MetaGetCmd{Index: indexArg(1)}}); err != nil {
+			t.Fatalf("meta get error = %v", err)
+		}
+	})
+	if output != "lang\tsh\nticket\tOPS-12\n" {
+		t.Errorf("meta get printed %q", output)
+	}
+	output = withStdout(t, func() { cli.executeMeta(&MetaCmd{Get: &MetaGetCmd{Index: indexArg(1), Key: stringPtr("ticket")}}) })
+	if output != "OPS-12\n" {
+		t.Errorf("meta get ticket printed %q", output)
+	}
+	if err := cli.executeMeta(&MetaCmd{Get: &MetaGetCmd{Index: indexArg(0), Key: stringPtr("lang")}}); err == nil {
+		t.Error("Expected meta get of a missing key to fail")
+	}
+
+	// rem list --json and rem info include the metadata
+	output = withStdout(t, func() { cli.executeList(&ListCmd{JSON: true}) })
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	var entry itemMetaJSON
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Failed to decode %q: %v", lines[1], err)
+	}
+	if len(entry.Meta) != 2 || entry.Meta["ticket"] != "OPS-12" || entry.Meta["lang"] != "sh" {
+		t.Errorf("list --json meta = %v", entry.Meta)
+	}
+	output = withStdout(t, func() { cli.executeInfo(&InfoCmd{Index: indexArg(1)}) })
+	if !strings.Contains(output, "Meta:     lang=sh\n          ticket=OPS-12\n") {
+		t.Errorf("Expected rem info to show the metadata, got:\n%s", output)
+	}
+
+	// rem search --meta filters on it, with or without a pattern
+	output = withStdout(t, func() {
+		if err := cli.executeSearch(&SearchCmd{Meta: []string{"ticket=OPS-12"}, AllMatches: true, IndexOnly: true}); err != nil {
+			t.Fatalf("search --meta error = %v", err)
+		}
+	})
+	if output != "1\n" {
+		t.Errorf("search --meta ticket=OPS-12 printed %q", output)
+	}
+	if err := cli.executeSearch(&SearchCmd{Pattern: "nginx", Meta: []string{"ticket=OPS-12"}}); err == nil {
+		t.Error("Expected no match for nginx with ticket=OPS-12")
+	}
+	if err := (&SearchCmd{Meta: []string{"ticket"}}).Validate(); err == nil {
+		t.Error("Expected --meta without = to be rejected")
+	}
+
+	withStdout(t, func() {
+		if err := cli.executeMeta(&MetaCmd{Del: &MetaDelCmd{Index: indexArg(1), Key: "ticket"}}); err != nil {
+			t.Fatalf("meta del error = %v", err)
+		}
+	})
+	if meta, _ := cli.store.History().GetMeta(entry.ID); len(meta) != 1 || meta["lang"] != "sh" {
+		t.Errorf("Expected only lang left after meta del, got %v", meta)
+	}
+
+	// Only meta get works read-only
+	for _, cmd := range []*MetaCmd{
+		{Set: &MetaSetCmd{Index: indexArg(0), Key: "a", Value: "b"}},
+		{Del: &MetaDelCmd{Index: indexArg(0), Key: "a"}},
+	} {
+		if err := (&Args{ReadOnly: true, Meta: cmd}).Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected with --read-only", cmd)
+		}
+	}
+	if err := (&Args{ReadOnly: true, Meta: &MetaCmd{Get: &MetaGetCmd{Index: indexArg(0)}}}).Validate(); err != nil {
+		t.Errorf("meta get with --read-only error = %v", err)
+	}
+	if err := (&MetaCmd{Set: &MetaSetCmd{Index: indexArg(0), Key: "Bad Key", Value: "x"}}).Validate(); err == nil {
+		t.Error("Expected an invalid key to be rejected")
+	}
+}
+
+func TestExecuteStore_ReportsEvictions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager, _ = queue.NewQueueManagerWithConfig(cli.store, 2)
+
+	var outputs []string
+	for _, content := range []string{"first", "second", "third"} {
+		withStdin(t, content, func() {
+			outputs = append(outputs, withStdout(t, func() {
+				if err := cli.executeStore(&StoreCmd{}); err != nil {
+					t.Fatalf("Failed to store %s: %v", content, err)
+				}
+			}))
+		})
+	}
+	if outputs[1] != "Stored: second\n" {
+		t.Errorf("Expected no eviction below the limit, got %q", outputs[1])
+	}
+	if outputs[2] != "Stored: third (evicted 1 oldest item: first)\n" {
+		t.Errorf("Expected the eviction reported, got %q", outputs[2])
+	}
+	items, _ := cli.queueManager.List()
+	if len(items) != 2 || items[1].Title != "second" {
+		t.Errorf("Expected first to be gone, got %v", items)
+	}
+}
+
+func TestEvictionNote(t *testing.T) {
+	evicted := []queue.EvictedInfo{{ID: 1, Title: "a"}, {ID: 2, Title: "b"}, {ID: 3, Title: "c"}, {ID: 4, Title: "d"}, {ID: 5, Title: "e"}}
+	for n, want := range map[int]string{
+		0: "",
+		1: " (evicted 1 oldest item: a)",
+		3: " (evicted 3 oldest items: a, b, c)",
+		5: " (evicted 5 oldest items: a, b, c, and 2 more)",
+	} {
+		if got := evictionNote(evicted[:n]); got != want {
+			t.Errorf("evictionNote(%d items) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestConfirmClipboardOverwrite(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	mock := mockboard.New()
+	cli.clipboard = mock
+	cli.queueManager.Enqueue(strings.NewReader("stored content"), "stored")
+
+	confirm := func(answer string, interactive bool) (bool, string) {
+		t.Helper()
+		var ok bool
+		prompt := withStderr(t, func() {
+			var err error
+			if ok, err = cli.confirmClipboardOverwrite("unrelated", strings.NewReader(answer), interactive); err != nil {
+				t.Fatalf("confirmClipboardOverwrite() error = %v", err)
+			}
+		})
+		return ok, prompt
+	}
+
+	mock.SetData([]byte("from elsewhere"))
+	if ok, prompt := confirm("n\n", true); ok || !strings.Contains(prompt, "Clipboard has 14 bytes of other content") {
+		t.Errorf("foreign content, answered n: ok %v, prompt %q", ok, prompt)
+	}
+	if ok, _ := confirm("y\n", true); !ok {
+		t.Error("foreign content, answered y: expected to overwrite")
+	}
+	if ok, prompt := confirm("", false); !ok || prompt != "" {
+		t.Errorf("without a terminal: ok %v, prompt %q; want no prompt", ok, prompt)
+	}
+
+	// Empty, or content rem holds, is overwritten without asking
+	for _, data := range []string{"", "stored content"} {
+		mock.SetData([]byte(data))
+		if ok, prompt := confirm("", true); !ok || prompt != "" {
+			t.Errorf("clipboard %q: ok %v, prompt %q; want no prompt", data, ok, prompt)
+		}
+	}
+	mock.SetData([]byte("from elsewhere"))
+	sum := sha256.Sum256([]byte("from elsewhere"))
+	var ok bool
+	prompt := withStderr(t, func() {
+		ok, _ = cli.confirmClipboardOverwrite(hex.EncodeToString(sum[:]), strings.NewReader(""), true)
+	})
+	if !ok || prompt != "" {
+		t.Errorf("clipboard already holding the item: ok %v, prompt %q", ok, prompt)
+	}
+
+	// An unreadable clipboard is overwritten with a warning
+	cli.clipboard = brokenClipboard{}
+	if ok, prompt := confirm("", true); !ok || !strings.Contains(prompt, "Warning: couldn't check the clipboard: no display") {
+		t.Errorf("unreadable clipboard: ok %v, stderr %q", ok, prompt)
+	}
 }
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// overwriteConfirm reports whether clipboard_overwrite_confirm is set
+func (c *CLI) overwriteConfirm() bool {
+	value, err := c.store.Config().Get("clipboard_overwrite_confirm")
+	return err == nil && value == "true"
+}
+
+// confirmClipboardOverwrite asks on stderr, reading the answer from in,
+// before 'rem get -c' replaces clipboard content rem didn't put there:
+// anything non-empty that is neither the content about to be copied
+// (hash) nor a stored item. It only asks when interactive; a clipboard that
+// can't be read is warned about and overwritten.
+func (c *CLI) confirmClipboardOverwrite(hash string, in io.Reader, interactive bool) (bool, error) {
+	if !interactive {
+		return true, nil
+	}
+	r, err := c.clipboard.Read()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't check the clipboard: %v\n", err)
+		return true, nil
+	}
+	defer r.Close()
+
+	sum := sha256.New()
+	size, err := io.Copy(sum, r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't check the clipboard: %v\n", err)
+		return true, nil
+	}
+	current := hex.EncodeToString(sum.Sum(nil))
+	if size == 0 || current == hash {
+		return true, nil
+	}
+	stored, err := c.store.History().FindBySHA256(current)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up the clipboard content: %w", err)
+	}
+	if len(stored) > 0 {
+		return true, nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Clipboard has %d bytes of other content — overwrite? [y/N]: ", size)
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes", nil
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yiblet/rem/internal/config"
+	"github.com/yiblet/rem/internal/filter"
+	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/timefmt"
+	"github.com/yiblet/rem/internal/tui"
+)
+
+// configKey describes a key accepted by 'rem config'
+type configKey struct {
+	name        string
+	description string
+	// values lists the allowed values of an enum-valued key
+	values []string
+	// readOnly keys can be read but not set
+	readOnly bool
+	// validate checks a value before it is set; nil accepts any value
+	validate func(c *CLI, key, value string) error
+}
+
+// boolValues are the values accepted by boolean keys
+var boolValues = []string{"true", "false"}
+
+// configKeys is the registry of config keys, in the order 'rem config list'
+// shows them
+var configKeys = append([]configKey{
+	{name: "history_limit", description: "number of items kept, such as 500 or 1k", validate: func(c *CLI, key, value string) error {
+		_, err := parseHistoryLimit(value)
+		return err
+	}},
+	{name: "show_binary", description: "show binary content in the viewer", values: boolValues},
+	{name: "clipboard_max_bytes", description: "largest clipboard content stored, such as 10MB", validate: func(c *CLI, key, value string) error {
+		_, err := parseClipboardMaxBytes(value)
+		return err
+	}},
+	{name: "clipboard_overwrite_confirm", description: "ask before copying over clipboard content rem didn't put there", values: boolValues},
+	{name: "low_space_warn_mb", description: "warn when free disk space drops below this many MB (0 disables)", validate: func(c *CLI, key, value string) error {
+		if mb, err := strconv.ParseInt(value, 10, 64); err != nil || mb < 0 {
+			return fmt.Errorf("low_space_warn_mb must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "default_filters", description: "comma-separated filters applied to stored content", validate: func(c *CLI, key, value string) error {
+		_, err := filter.ParseList(value)
+		return err
+	}},
+	{name: "wrap_default", description: "wrap long lines in the viewer by default", values: boolValues},
+	{name: "hscroll_step", description: "columns scrolled per horizontal step", validate: func(c *CLI, key, value string) error {
+		if step, err := strconv.Atoi(value); err != nil || step <= 0 {
+			return fmt.Errorf("hscroll_step must be a positive integer")
+		}
+		return nil
+	}},
+	{name: "tab_width", description: "columns between tab stops in the viewer", validate: func(c *CLI, key, value string) error {
+		if width, err := strconv.Atoi(value); err != nil || width <= 0 || width > 16 {
+			return fmt.Errorf("tab_width must be an integer from 1 to 16")
+		}
+		return nil
+	}},
+	{name: "assume_latin1", description: "show content that isn't valid UTF-8 as Latin-1 in the viewer, instead of with replacement characters", values: boolValues},
+	{name: "scrollbar", description: "show a scrollbar in the viewer", values: boolValues},
+	{name: "diff_colors", description: "color items that look like unified diffs in the viewer", values: boolValues},
+	{name: "warn_permissions", description: "warn when the database or backups can be read by other users", values: boolValues},
+	{name: "group_by_date", description: "group viewer items under Today/Yesterday/This week/Older headers", values: boolValues},
+	{name: "show_indicators", description: "mark binary items with a glyph at the start of their viewer row", values: boolValues},
+	{name: "icons", description: "glyphs for viewer indicators: text letters, or nerd for Nerd Font icons", values: []string{"text", "nerd"}},
+	{name: "show_hints", description: "show key hints at the bottom of the focused viewer pane", values: boolValues},
+	{name: "a11y", description: "announce viewer state changes in plain text and draw ASCII borders, for screen readers (or set REM_A11Y=1)", values: boolValues},
+	{name: "preview_debounce_ms", description: "milliseconds the viewer cursor must rest before loading an item (0 loads immediately)", validate: func(c *CLI, key, value string) error {
+		if ms, err := strconv.Atoi(value); err != nil || ms < 0 {
+			return fmt.Errorf("preview_debounce_ms must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "coalesce_window_ms", description: "store returns the newest item instead of a copy of it stored within this many milliseconds (0 disables)", validate: func(c *CLI, key, value string) error {
+		if ms, err := strconv.Atoi(value); err != nil || ms < 0 {
+			return fmt.Errorf("coalesce_window_ms must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "tui_initial_items", description: "items the viewer loads at startup and per page as you scroll", validate: func(c *CLI, key, value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return fmt.Errorf("tui_initial_items must be a positive integer")
+		}
+		return nil
+	}},
+	{name: "incremental_search", description: "search the viewed item while a pattern is typed, jumping to the first match", values: boolValues},
+	{name: "save_search_history", description: "remember viewer search patterns between sessions", values: boolValues},
+	{name: "search_history", description: "saved viewer search patterns, oldest first (JSON array; [] clears)", validate: func(c *CLI, key, value string) error {
+		_, err := parseSearchHistory(value)
+		return err
+	}},
+	{name: "recent_copies_limit", description: "number of recently copied items marked in the viewer, for ' to jump to (0 turns this off)", validate: func(c *CLI, key, value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("recent_copies_limit must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "recent_copies", description: "recently copied item IDs, newest first (JSON array; [] clears)", validate: func(c *CLI, key, value string) error {
+		_, err := parseRecentCopies(value)
+		return err
+	}},
+	{name: "timezone", description: "IANA time zone times are shown in, such as Europe/Berlin (default: the local zone; --utc overrides it)", validate: func(c *CLI, key, value string) error {
+		_, err := timefmt.LoadLocation(value)
+		return err
+	}},
+	{name: "backup_keep", description: "number of automatic backups kept (0 disables them)", validate: func(c *CLI, key, value string) error {
+		if keep, err := strconv.Atoi(value); err != nil || keep < 0 {
+			return fmt.Errorf("backup_keep must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "backup_max_bytes", description: "largest database backed up automatically (0 means any size)", validate: func(c *CLI, key, value string) error {
+		if _, err := parseSize(value); err != nil {
+			return fmt.Errorf("backup_max_bytes must be a size such as 268435456 or 256MiB")
+		}
+		return nil
+	}},
+	{name: "compress_min_bytes", description: "text items larger than this are compressed (0 disables; see rem maintenance recompress)", validate: func(c *CLI, key, value string) error {
+		if _, err := parseSize(value); err != nil {
+			return fmt.Errorf("compress_min_bytes must be a size such as 65536 or 64KiB")
+		}
+		return nil
+	}},
+	{name: store.AuditKeepDaysKey, description: "days rem audit list keeps deleted, evicted, and cleared items for (default 90; 0 turns the audit log off)", validate: func(c *CLI, key, value string) error {
+		if days, err := strconv.Atoi(value); err != nil || days < 0 {
+			return fmt.Errorf("audit_keep_days must be a non-negative integer")
+		}
+		return nil
+	}},
+	{name: "default_title_template", description: "title template for items stored without --title (see --title-template; empty disables)", validate: func(c *CLI, key, value string) error {
+		if value == "" {
+			return nil
+		}
+		_, err := parseTitleTemplate(value)
+		return err
+	}},
+	{name: "title_strategy", description: "line of the content a generated title is taken from (see --title-line)", values: queue.TitlePickNames()},
+	{name: "title_sample_bytes", description: "how much of the content a title is generated from, up to 1MiB (default 4KiB)", validate: func(c *CLI, key, value string) error {
+		_, err := parseTitleSampleBytes(value)
+		return err
+	}},
+	{name: "db_version", description: "database schema version", readOnly: true},
+	{name: "generation", description: "changes made to the queue so far (see --expect-generation)", readOnly: true},
+	{name: "db_path", description: "database location (set with --db-path or REM_DB_PATH)", readOnly: true},
+}, keyBindingConfigKeys()...)
+
+// parseTitleSampleBytes parses a title_sample_bytes value
+func parseTitleSampleBytes(value string) (int, error) {
+	n, err := parseSize(value)
+	if err != nil || n <= 0 || n > store.MaxContentPrefix {
+		return 0, fmt.Errorf("title_sample_bytes must be a size from 1 to 1MiB, such as 4096 or 16KiB")
+	}
+	return int(n), nil
+}
+
+// keyBindingConfigKeys returns the registry entries for the TUI key bindings
+func keyBindingConfigKeys() []configKey {
+	var keys []configKey
+	for _, name := range tui.KeyConfigKeys() {
+		action := strings.ReplaceAll(strings.TrimPrefix(name, "key_"), "_", " and ")
+		keys = append(keys, configKey{
+			name:        name,
+			description: fmt.Sprintf("TUI key to %s the selected item", action),
+			validate:    validateKeyBinding,
+		})
+	}
+	return keys
+}
+
+// validateKeyBinding checks a key binding together with the other
+// configured bindings
+func validateKeyBinding(c *CLI, key, value string) error {
+	values, err := c.store.Config().List()
+	if err != nil {
+		return fmt.Errorf("failed to list config values: %w", err)
+	}
+	values[key] = value
+	_, err = tui.NewKeymap(values)
+	return err
+}
+
+// lookupConfigKey returns the registry entry for key, which may use hyphens
+// or underscores as separators
+func lookupConfigKey(key string) (configKey, error) {
+	name := config.CanonicalKey(key)
+	for _, entry := range configKeys {
+		if entry.name == name {
+			return entry, nil
+		}
+	}
+	names := make([]string, len(configKeys))
+	for i, entry := range configKeys {
+		names[i] = entry.name
+	}
+	return configKey{}, config.UnknownKeyError(key, names)
+}
+
+// check validates a value for the key
+func (k configKey) check(c *CLI, value string) error {
+	if k.readOnly {
+		return fmt.Errorf("%s is read-only", k.name)
+	}
+	if k.values != nil && !slices.Contains(k.values, value) {
+		return fmt.Errorf("invalid value '%s' for %s (allowed: %s)", value, k.name, strings.Join(k.values, ", "))
+	}
+	if k.validate != nil {
+		return k.validate(c, k.name, value)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// executeDedupe handles the 'rem dedupe' command, deleting the older item
+// of each back-to-back pair with the same content stored within --window,
+// such as a shell hook that stored every command's output twice
+func (c *CLI) executeDedupe(cmd *DedupeCmd) error {
+	window, err := cmd.window()
+	if err != nil {
+		return err
+	}
+	duplicates, scanned, err := findDuplicates(c.store.History(), window)
+	if err != nil {
+		return err
+	}
+
+	if cmd.DryRun {
+		for _, item := range duplicates {
+			fmt.Printf("Would delete item %d: %s\n", item.ID, item.Title)
+		}
+		fmt.Printf("Would delete %d duplicate(s) of %d item(s)\n", len(duplicates), scanned)
+		return nil
+	}
+	for _, item := range duplicates {
+		if err := c.queueManager.DeleteByID(item.ID); err != nil {
+			return fmt.Errorf("failed to delete item %d: %w", item.ID, err)
+		}
+	}
+	fmt.Printf("Deleted %d duplicate(s) of %d item(s)\n", len(duplicates), scanned)
+	return nil
+}
+
+// findDuplicates walks history newest first and returns each item whose
+// content equals the item just above it, stored at most window before it,
+// along with the number of items scanned. The hashes the store recorded
+// are compared, so no content is read. In a run of copies every one but
+// the newest is returned, as long as each is within window of the next.
+func findDuplicates(history store.HistoryStore, window time.Duration) ([]*store.HistoryItem, int, error) {
+	var duplicates []*store.HistoryItem
+	var prev *store.HistoryItem
+	scanned := 0
+	err := history.Iterate(store.IterOptions{}, func(item *store.HistoryItem) (bool, error) {
+		scanned++
+		if prev != nil && item.SHA256 == prev.SHA256 && prev.Timestamp.Sub(item.Timestamp) <= window {
+			duplicates = append(duplicates, item)
+		}
+		prev = item
+		return false, nil
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to scan items: %w", err)
+	}
+	return duplicates, scanned, nil
+}
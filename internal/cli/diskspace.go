@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// defaultLowSpaceWarnMB is the free-space warning threshold used when none is configured
+const defaultLowSpaceWarnMB = 100
+
+// lowSpaceWarning returns a warning if the filesystem holding dir has less
+// free space than low_space_warn_mb, or "" if there is enough or the free
+// space cannot be determined
+func lowSpaceWarning(st store.Store, dir string) string {
+	thresholdMB := int64(defaultLowSpaceWarnMB)
+	if value, err := st.Config().Get("low_space_warn_mb"); err == nil {
+		if mb, err := strconv.ParseInt(value, 10, 64); err == nil && mb >= 0 {
+			thresholdMB = mb
+		}
+	}
+
+	free, ok := freeSpace(dir)
+	if !ok {
+		return ""
+	}
+	return lowSpaceMessage(free, thresholdMB, dir)
+}
+
+// lowSpaceMessage formats the warning for free bytes against a threshold in MB.
+// A threshold of 0 disables the warning.
+func lowSpaceMessage(free uint64, thresholdMB int64, dir string) string {
+	if thresholdMB <= 0 || free >= uint64(thresholdMB)*1024*1024 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: only %d MB free on the filesystem holding %s (low_space_warn_mb = %d); new items may fail to store",
+		free/(1024*1024), dir, thresholdMB)
+}
@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+	"github.com/yiblet/rem/internal/clipboard"
+	"github.com/yiblet/rem/internal/clipboard/sysboard"
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/dbstore"
+)
+
+// checkStatus is the outcome of one 'rem doctor' check
+type checkStatus string
+
+const (
+	checkPass checkStatus = "pass"
+	checkWarn checkStatus = "warn"
+	checkFail checkStatus = "fail"
+)
+
+// checkResult is one line of the 'rem doctor' report
+type checkResult struct {
+	Name    string      `json:"name"`
+	Status  checkStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// databaseHealth is the part of the SQLite store the database checks use
+type databaseHealth interface {
+	IntegrityCheck() ([]string, error)
+	ForeignKeysEnabled() (bool, error)
+	OrphanedChunks() (int64, error)
+	StaleAuditEntries() (int64, error)
+}
+
+// ExecuteDoctor handles 'rem doctor'. Like ExecuteVersion it needs no CLI,
+// so a database too broken to open normally can still be diagnosed; the
+// database is only opened read-only.
+func ExecuteDoctor(args *Args, w io.Writer) error {
+	results := runDoctorChecks(args)
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == checkFail {
+			failed++
+		}
+	}
+
+	if args.Doctor != nil && args.Doctor.JSON {
+		data, err := json.Marshal(struct {
+			Checks []checkResult `json:"checks"`
+		}{results})
+		if err != nil {
+			return fmt.Errorf("failed to encode doctor report: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			fmt.Fprintf(w, "%-4s  %-15s %s\n", result.Status, result.Name, result.Message)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// runDoctorChecks runs every check in report order
+func runDoctorChecks(args *Args) []checkResult {
+	var results []checkResult
+	path, source, err := resolveDBPath(args)
+	if err != nil {
+		results = append(results, checkResult{Name: "database path", Status: checkFail, Message: err.Error()})
+	} else if path == MemoryDBPath {
+		results = append(results, checkResult{Name: "database path", Status: checkPass, Message: "in memory; nothing to check"})
+		path = ""
+	} else {
+		result := checkDatabasePath(path)
+		result.Message += fmt.Sprintf(" (from %s)", source)
+		if source == sourceFallback && result.Status == checkPass {
+			result.Status = checkWarn
+		}
+		results = append(results, result)
+	}
+
+	thresholdMB := int64(defaultLowSpaceWarnMB)
+	if path != "" {
+		db, result := openForDoctor(path)
+		results = append(results, result)
+		if db != nil {
+			defer db.Close()
+			results = append(results, checkIntegrity(db), checkForeignKeys(db), checkOrphanedChunks(db), checkStaleAudit(db), checkConfig(db))
+			if value, err := db.Config().Get("low_space_warn_mb"); err == nil {
+				if mb, err := strconv.ParseInt(value, 10, 64); err == nil && mb >= 0 {
+					thresholdMB = mb
+				}
+			}
+		}
+	}
+
+	results = append(results, checkClipboard(sysboard.New()), checkTerminal(os.Stdout))
+	if path != "" {
+		results = append(results, checkDiskSpace(filepath.Dir(path), thresholdMB, freeSpace))
+	}
+	return results
+}
+
+// checkDatabasePath checks that the database, or the directory it would be
+// created in, can be written
+func checkDatabasePath(path string) checkResult {
+	result := checkResult{Name: "database path"}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		dir := existingParent(filepath.Dir(path))
+		if err := dirWritable(dir); err != nil {
+			result.Status, result.Message = checkFail, fmt.Sprintf("%s does not exist and %s is not writable: %v", path, dir, err)
+			return result
+		}
+		result.Status, result.Message = checkWarn, fmt.Sprintf("%s does not exist yet; it is created on first use", path)
+		return result
+	case err != nil:
+		result.Status, result.Message = checkFail, err.Error()
+		return result
+	case info.IsDir():
+		result.Status, result.Message = checkFail, fmt.Sprintf("%s is a directory", path)
+		return result
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		result.Status, result.Message = checkFail, fmt.Sprintf("%s is not writable: %v", path, err)
+		return result
+	}
+	f.Close()
+	// SQLite writes its journal next to the database
+	if err := dirWritable(filepath.Dir(path)); err != nil {
+		result.Status, result.Message = checkFail, fmt.Sprintf("%s is not writable: %v", filepath.Dir(path), err)
+		return result
+	}
+	result.Status, result.Message = checkPass, path
+	return result
+}
+
+// resolveDBPath returns the database args point at, and where that came
+// from, without creating or moving it
+func resolveDBPath(args *Args) (string, dbPathSource, error) {
+	return chooseDBPath(args, true)
+}
+
+// existingParent returns dir or its nearest ancestor that exists
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// dirWritable checks that a file can be created in dir
+func dirWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".rem-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// openForDoctor opens the database read-only, or returns nil with the
+// reason it can't be opened
+func openForDoctor(path string) (*dbstore.SQLiteStore, checkResult) {
+	result := checkResult{Name: "sqlite"}
+	if _, err := os.Stat(path); err != nil {
+		result.Status, result.Message = checkWarn, "skipped: no database"
+		return nil, result
+	}
+	db, err := dbstore.NewSQLiteStoreWithOptions(path, dbstore.Options{ReadOnly: true})
+	if err != nil {
+		result.Status, result.Message = checkFail, err.Error()
+		return nil, result
+	}
+	version, err := db.SQLiteVersion()
+	if err != nil {
+		db.Close()
+		result.Status, result.Message = checkFail, err.Error()
+		return nil, result
+	}
+	result.Status, result.Message = checkPass, "SQLite "+version
+	return db, result
+}
+
+// checkIntegrity runs SQLite's integrity check
+func checkIntegrity(db databaseHealth) checkResult {
+	result := checkResult{Name: "integrity"}
+	problems, err := db.IntegrityCheck()
+	switch {
+	case err != nil:
+		result.Status, result.Message = checkFail, err.Error()
+	case len(problems) > 0:
+		result.Status, result.Message = checkFail, fmt.Sprintf("%d problem(s), first: %s", len(problems), problems[0])
+	default:
+		result.Status, result.Message = checkPass, "ok"
+	}
+	return result
+}
+
+// checkForeignKeys checks that deleting an item also deletes its chunks
+func checkForeignKeys(db databaseHealth) checkResult {
+	result := checkResult{Name: "foreign keys"}
+	enabled, err := db.ForeignKeysEnabled()
+	switch {
+	case err != nil:
+		result.Status, result.Message = checkFail, err.Error()
+	case !enabled:
+		result.Status, result.Message = checkWarn, "not enforced; deleting items may leave their chunks behind"
+	default:
+		result.Status, result.Message = checkPass, "enforced"
+	}
+	return result
+}
+
+// checkOrphanedChunks looks for chunks whose item is gone
+func checkOrphanedChunks(db databaseHealth) checkResult {
+	result := checkResult{Name: "orphaned chunks"}
+	count, err := db.OrphanedChunks()
+	switch {
+	case err != nil:
+		result.Status, result.Message = checkFail, err.Error()
+	case count > 0:
+		result.Status, result.Message = checkWarn, fmt.Sprintf("%d chunk(s) belong to no item", count)
+	default:
+		result.Status, result.Message = checkPass, "none"
+	}
+	return result
+}
+
+// checkStaleAudit looks for audit entries whose item is still there, as if
+// it had never been removed
+func checkStaleAudit(db databaseHealth) checkResult {
+	result := checkResult{Name: "audit log"}
+	count, err := db.StaleAuditEntries()
+	switch {
+	case err != nil:
+		result.Status, result.Message = checkFail, err.Error()
+	case count > 0:
+		result.Status, result.Message = checkWarn, fmt.Sprintf("%d entry(s) record removing items that are still stored", count)
+	default:
+		result.Status, result.Message = checkPass, "consistent"
+	}
+	return result
+}
+
+// checkConfig checks that every stored config value would be accepted by
+// 'rem config set'
+func checkConfig(st store.Store) checkResult {
+	result := checkResult{Name: "config"}
+	values, err := st.Config().List()
+	if err != nil {
+		result.Status, result.Message = checkFail, err.Error()
+		return result
+	}
+	c := &CLI{store: st}
+	var bad []string
+	for key, value := range values {
+		entry, err := lookupConfigKey(key)
+		if err != nil || entry.readOnly {
+			continue
+		}
+		if err := entry.check(c, value); err != nil {
+			bad = append(bad, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+	if len(bad) > 0 {
+		slices.Sort(bad)
+		result.Status, result.Message = checkFail, strings.Join(bad, "; ")
+		return result
+	}
+	result.Status, result.Message = checkPass, fmt.Sprintf("%d value(s) set", len(values))
+	return result
+}
+
+// checkClipboard checks that the system clipboard can be read
+func checkClipboard(clip clipboard.Clipboard) checkResult {
+	result := checkResult{Name: "clipboard"}
+	if !clip.IsSupported() {
+		result.Status, result.Message = checkWarn, "not supported on this system; rem store -c and copying from the viewer won't work"
+		return result
+	}
+	r, err := clip.Read()
+	if err != nil {
+		result.Status, result.Message = checkWarn, fmt.Sprintf("failed to read: %v", err)
+		return result
+	}
+	r.Close()
+	result.Status, result.Message = checkPass, "available"
+	return result
+}
+
+// checkTerminal reports whether f is a terminal the viewer can draw on
+func checkTerminal(f *os.File) checkResult {
+	result := checkResult{Name: "terminal"}
+	if !term.IsTerminal(f.Fd()) {
+		result.Status, result.Message = checkWarn, "stdout is not a terminal; rem get falls back to a plain picker"
+		return result
+	}
+	profile := termenv.NewOutput(f).ColorProfile()
+	width, height, err := term.GetSize(f.Fd())
+	size := "unknown size"
+	if err == nil {
+		size = fmt.Sprintf("%dx%d", width, height)
+	}
+	if profile == termenv.Ascii {
+		result.Status, result.Message = checkWarn, fmt.Sprintf("no color support (%s); rem get falls back to a plain picker", size)
+		return result
+	}
+	result.Status, result.Message = checkPass, fmt.Sprintf("%s, %s", colorProfileName(profile), size)
+	return result
+}
+
+// colorProfileName describes a termenv color profile
+func colorProfileName(profile termenv.Profile) string {
+	switch profile {
+	case termenv.TrueColor:
+		return "true color"
+	case termenv.ANSI256:
+		return "256 colors"
+	case termenv.ANSI:
+		return "16 colors"
+	}
+	return "no color"
+}
+
+// checkDiskSpace checks the free space on the filesystem holding dir
+// against thresholdMB
+func checkDiskSpace(dir string, thresholdMB int64, free func(string) (uint64, bool)) checkResult {
+	result := checkResult{Name: "disk space"}
+	dir = existingParent(dir)
+	bytes, ok := free(dir)
+	if !ok {
+		result.Status, result.Message = checkWarn, fmt.Sprintf("could not determine the free space on %s", dir)
+		return result
+	}
+	if warning := lowSpaceMessage(bytes, thresholdMB, dir); warning != "" {
+		result.Status, result.Message = checkWarn, fmt.Sprintf("only %s free on %s (low_space_warn_mb = %d)", formatSize(int64(bytes)), dir, thresholdMB)
+		return result
+	}
+	result.Status, result.Message = checkPass, fmt.Sprintf("%s free", formatSize(int64(bytes)))
+	return result
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+	"github.com/yiblet/rem/internal/store/memstore"
+)
+
+// fakeHealth reports fixed database health results
+type fakeHealth struct {
+	problems []string
+	fkOn     bool
+	orphans  int64
+	stale    int64
+	err      error
+}
+
+func (f fakeHealth) IntegrityCheck() ([]string, error) { return f.problems, f.err }
+func (f fakeHealth) ForeignKeysEnabled() (bool, error) { return f.fkOn, f.err }
+func (f fakeHealth) OrphanedChunks() (int64, error)    { return f.orphans, f.err }
+func (f fakeHealth) StaleAuditEntries() (int64, error) { return f.stale, f.err }
+
+// brokenClipboard is supported but fails to read
+type brokenClipboard struct{}
+
+func (brokenClipboard) Read() (io.ReadCloser, error) { return nil, errors.New("no display") }
+func (brokenClipboard) Write(io.Reader) error        { return errors.New("no display") }
+func (brokenClipboard) IsSupported() bool            { return true }
+
+func TestCheckDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "sub", "rem.db")
+	if got := checkDatabasePath(missing); got.Status != checkWarn {
+		t.Errorf("missing database: got %+v, want a warning", got)
+	}
+
+	path := filepath.Join(dir, "rem.db")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkDatabasePath(path); got.Status != checkPass {
+		t.Errorf("writable database: got %+v, want a pass", got)
+	}
+	if got := checkDatabasePath(dir); got.Status != checkFail {
+		t.Errorf("directory as database: got %+v, want a failure", got)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can write read-only files")
+	}
+	if err := os.Chmod(path, 0400); err != nil {
+		t.Fatal(err)
+	}
+	if got := checkDatabasePath(path); got.Status != checkFail {
+		t.Errorf("read-only database: got %+v, want a failure", got)
+	}
+}
+
+func TestOpenForDoctor_Corrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rem.db")
+	if err := os.WriteFile(path, []byte(strings.Repeat("not a database ", 100)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, got := openForDoctor(path)
+	if db != nil {
+		db.Close()
+	}
+	if got.Status != checkFail {
+		t.Errorf("corrupt database: got %+v, want a failure", got)
+	}
+}
+
+func TestCheckDatabaseHealth(t *testing.T) {
+	healthy := fakeHealth{fkOn: true}
+	for _, check := range []func(databaseHealth) checkResult{checkIntegrity, checkForeignKeys, checkOrphanedChunks, checkStaleAudit} {
+		if got := check(healthy); got.Status != checkPass {
+			t.Errorf("healthy database: got %+v, want a pass", got)
+		}
+		if got := check(fakeHealth{err: errors.New("disk I/O error")}); got.Status != checkFail {
+			t.Errorf("failing query: got %+v, want a failure", got)
+		}
+	}
+
+	if got := checkIntegrity(fakeHealth{problems: []string{"row 3 missing from index", "page 7 unused"}}); got.Status != checkFail || !strings.Contains(got.Message, "row 3") {
+		t.Errorf("integrity problems: got %+v", got)
+	}
+	if got := checkForeignKeys(fakeHealth{}); got.Status != checkWarn {
+		t.Errorf("foreign keys off: got %+v, want a warning", got)
+	}
+	if got := checkOrphanedChunks(fakeHealth{orphans: 4}); got.Status != checkWarn || !strings.Contains(got.Message, "4 chunk") {
+		t.Errorf("orphaned chunks: got %+v", got)
+	}
+	if got := checkStaleAudit(fakeHealth{stale: 2}); got.Status != checkWarn || !strings.Contains(got.Message, "2 entry") {
+		t.Errorf("stale audit entries: got %+v", got)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	st := memstore.NewMemoryStore()
+	defer st.Close()
+	st.Config().Set("history_limit", "1k")
+	st.Config().Set("db_version", "4")
+	if got := checkConfig(st); got.Status != checkPass {
+		t.Errorf("valid config: got %+v, want a pass", got)
+	}
+
+	st.Config().Set("hscroll_step", "-3")
+	got := checkConfig(st)
+	if got.Status != checkFail || !strings.Contains(got.Message, "hscroll_step") {
+		t.Errorf("invalid hscroll_step: got %+v, want a failure naming it", got)
+	}
+}
+
+func TestCheckClipboard(t *testing.T) {
+	if got := checkClipboard(mockboard.New()); got.Status != checkPass {
+		t.Errorf("working clipboard: got %+v, want a pass", got)
+	}
+	if got := checkClipboard(brokenClipboard{}); got.Status != checkWarn || !strings.Contains(got.Message, "no display") {
+		t.Errorf("broken clipboard: got %+v, want a warning with the error", got)
+	}
+}
+
+func TestCheckTerminal_Pipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if got := checkTerminal(w); got.Status != checkWarn {
+		t.Errorf("pipe: got %+v, want a warning", got)
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	plenty := func(string) (uint64, bool) { return 10 << 30, true }
+	scarce := func(string) (uint64, bool) { return 5 << 20, true }
+	unknown := func(string) (uint64, bool) { return 0, false }
+
+	if got := checkDiskSpace(filepath.Join(dir, "missing"), 100, plenty); got.Status != checkPass {
+		t.Errorf("plenty of space: got %+v, want a pass", got)
+	}
+	if got := checkDiskSpace(dir, 100, scarce); got.Status != checkWarn {
+		t.Errorf("low space: got %+v, want a warning", got)
+	}
+	if got := checkDiskSpace(dir, 0, scarce); got.Status != checkPass {
+		t.Errorf("warning disabled: got %+v, want a pass", got)
+	}
+	if got := checkDiskSpace(dir, 100, unknown); got.Status != checkWarn {
+		t.Errorf("unknown space: got %+v, want a warning", got)
+	}
+}
+
+func TestExecuteDoctor_JSON(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "rem.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("failed to create CLI: %v", err)
+	}
+	cli.store.Config().Set("scrollbar", "sometimes")
+	cli.store.Close()
+
+	var out bytes.Buffer
+	err = ExecuteDoctor(&Args{DBPath: &dbPath, Doctor: &DoctorCmd{JSON: true}}, &out)
+	if err == nil {
+		t.Error("expected an error for the invalid config value")
+	}
+	var report struct {
+		Checks []checkResult `json:"checks"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out.String(), err)
+	}
+	statuses := map[string]checkStatus{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	for name, want := range map[string]checkStatus{"database path": checkPass, "sqlite": checkPass, "integrity": checkPass, "orphaned chunks": checkPass, "config": checkFail} {
+		if statuses[name] != want {
+			t.Errorf("%s = %q, want %q in %s", name, statuses[name], want, out.String())
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/yiblet/rem/internal/filter"
+)
+
+// previewStore reports what storing content from source would do, for
+// store --dry-run, without writing anything: the title, size, hash, and
+// filters the item would get, and any repeat or eviction storing it causes
+func (c *CLI) previewStore(content io.Reader, sections *sectionScanner, title string, tmpl *template.Template, source string, filters []filter.Filter) error {
+	title, content, err := templateTitle(content, title, tmpl, source)
+	if err != nil {
+		return err
+	}
+	preview, err := c.queueManager.Preview(content, title)
+	if err != nil {
+		return err
+	}
+
+	kind := "text"
+	if preview.IsBinary {
+		kind = "binary"
+	}
+	names := make([]string, len(filters))
+	for i, f := range filters {
+		names[i] = f.Name()
+	}
+	if len(names) == 0 {
+		names = append(names, "none")
+	}
+
+	fmt.Printf("Would store from %s:\n", source)
+	fmt.Printf("Title:    %s\n", preview.Title)
+	fmt.Printf("Size:     %d bytes (%s)\n", preview.Size, kind)
+	fmt.Printf("SHA256:   %s\n", preview.SHA256)
+	fmt.Printf("Filters:  %s\n", strings.Join(names, ", "))
+	if sections != nil {
+		found, err := sections.sections(preview.Size)
+		if err != nil {
+			return fmt.Errorf("failed to find sections: %w", err)
+		}
+		fmt.Printf("Sections: %d\n", len(found))
+	}
+	if item := preview.Duplicate; item != nil {
+		fmt.Printf("Copy of:  item %d (%s)\n", c.indexOf(item.ID), item.Title)
+	}
+	if preview.Coalesced != nil {
+		fmt.Println("Repeat:   within coalesce_window_ms of the newest item, so it would be kept instead of storing a copy")
+	}
+	if len(preview.Evicted) > 0 {
+		fmt.Printf("Evicts:   %s\n", evictionList(preview.Evicted))
+	}
+	return nil
+}
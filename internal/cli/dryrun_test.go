@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/queue"
+)
+
+// dryRunFields parses the "Label: value" lines of store --dry-run output
+func dryRunFields(output string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if label, value, ok := strings.Cut(line, ":"); ok && !strings.HasPrefix(line, "Would store") {
+			fields[label] = strings.TrimSpace(value)
+		}
+	}
+	return fields
+}
+
+func TestStoreCommand_DryRunMatchesStore(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	for _, tt := range []struct {
+		name    string
+		content string
+		cmd     StoreCmd
+	}{
+		{"generated title", "\n  first line\nsecond\n", StoreCmd{}},
+		{"filtered", "a\r\nb  \r\n", StoreCmd{Filters: []string{"dos2unix", "trim-trailing"}, Title: stringPtr("given\ttitle")}},
+		{"binary", "\x00\x01binary" + strings.Repeat("\x02", 100), StoreCmd{}},
+		{"large", strings.Repeat("0123456789abcdef\n", 10000), StoreCmd{}},
+	} {
+		dry := tt.cmd
+		dry.DryRun = true
+		var output string
+		withStdin(t, tt.content, func() {
+			output = withStdout(t, func() {
+				if err := cli.executeStore(&dry); err != nil {
+					t.Fatalf("%s: dry run error = %v", tt.name, err)
+				}
+			})
+		})
+		if n, _ := cli.queueManager.Size(); n != 0 {
+			t.Fatalf("%s: dry run stored an item", tt.name)
+		}
+
+		withStdin(t, tt.content, func() {
+			withStdout(t, func() {
+				if err := cli.executeStore(&tt.cmd); err != nil {
+					t.Fatalf("%s: store error = %v", tt.name, err)
+				}
+			})
+		})
+		items, err := cli.queueManager.List()
+		if err != nil || len(items) != 1 {
+			t.Fatalf("%s: stored %d items (%v)", tt.name, len(items), err)
+		}
+		item := items[0]
+		kind := "text"
+		if item.IsBinary {
+			kind = "binary"
+		}
+
+		fields := dryRunFields(output)
+		want := map[string]string{
+			"Title":  item.Title,
+			"Size":   fmt.Sprintf("%d bytes (%s)", item.Size, kind),
+			"SHA256": item.SHA256,
+		}
+		for label, value := range want {
+			if fields[label] != value {
+				t.Errorf("%s: dry run %s = %q, store gave %q", tt.name, label, fields[label], value)
+			}
+		}
+		if len(tt.cmd.Filters) > 0 && fields["Filters"] != "dos2unix, trim-trailing" {
+			t.Errorf("%s: dry run Filters = %q", tt.name, fields["Filters"])
+		}
+
+		if err := cli.queueManager.Clear(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStoreCommand_DryRunRepeatsAndEvictions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager, err = queue.NewQueueManagerWithConfig(cli.store, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli.queueManager.Enqueue(strings.NewReader("oldest"), "oldest")
+	cli.queueManager.Enqueue(strings.NewReader("newest"), "newest")
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("oldest"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	output := withStdout(t, func() {
+		if err := cli.executeStore(&StoreCmd{Files: []string{file}, DryRun: true}); err != nil {
+			t.Fatalf("dry run error = %v", err)
+		}
+	})
+	fields := dryRunFields(output)
+	if fields["Copy of"] != "item 1 (oldest)" || fields["Evicts"] != "1 oldest item: oldest" || fields["Repeat"] != "" {
+		t.Errorf("dry run of a copy of the oldest item:\n%s", output)
+	}
+	if !strings.HasPrefix(output, "Would store from "+file+":\n") {
+		t.Errorf("dry run doesn't name its source:\n%s", output)
+	}
+
+	// Within the coalesce window, a repeat of the newest item isn't stored
+	// and so evicts nothing
+	cli.queueManager.SetCoalesceWindow(time.Hour)
+	withStdin(t, "newest", func() {
+		output = withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{DryRun: true}); err != nil {
+				t.Fatalf("dry run error = %v", err)
+			}
+		})
+	})
+	fields = dryRunFields(output)
+	if fields["Copy of"] != "item 0 (newest)" || fields["Repeat"] == "" || fields["Evicts"] != "" {
+		t.Errorf("dry run of a repeat of the newest item:\n%s", output)
+	}
+}
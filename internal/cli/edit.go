@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// executeEdit handles the 'rem edit' command. Each text item's content is
+// streamed through the replacement one line at a time into a temporary
+// file, which replaces the content only if something matched. Items keep
+// their title, note, and place in the queue.
+func (c *CLI) executeEdit(cmd *EditCmd) error {
+	re, err := regexp.Compile(cmd.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid --pattern: %w", err)
+	}
+	items, err := c.editCandidates(cmd)
+	if err != nil {
+		return err
+	}
+
+	edited, replacements := 0, 0
+	for _, item := range items {
+		if item.IsBinary {
+			continue
+		}
+		count, err := c.editItem(item, re, []byte(*cmd.Replace), cmd)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		edited++
+		replacements += count
+		if cmd.DryRun {
+			fmt.Printf("Would replace %d match(es) in item %d: %s\n", count, item.ID, item.Title)
+		} else {
+			fmt.Printf("Replaced %d match(es) in item %d: %s\n", count, item.ID, item.Title)
+		}
+	}
+
+	if cmd.DryRun {
+		fmt.Printf("Would edit %d item(s), %d replacement(s)\n", edited, replacements)
+	} else {
+		fmt.Printf("Edited %d item(s), %d replacement(s)\n", edited, replacements)
+	}
+	return nil
+}
+
+// editCandidates returns the items rem edit considers, oldest first: those
+// matching --match, or every item. They're listed up front so backups
+// stored along the way aren't edited too.
+func (c *CLI) editCandidates(cmd *EditCmd) ([]*store.HistoryItem, error) {
+	if cmd.Match != nil {
+		items, err := c.store.History().Search(&store.SearchQuery{
+			Pattern:   *cmd.Match,
+			Normalize: true,
+			OrderBy:   store.OrderOldest,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("search failed: %w", err)
+		}
+		return items, nil
+	}
+	var items []*store.HistoryItem
+	err := c.store.History().Iterate(store.IterOptions{Order: store.OrderOldest}, func(item *store.HistoryItem) (bool, error) {
+		items = append(items, item)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// editItem applies the replacement to item and returns how many matches it
+// replaced. Nothing is written on a dry run or when nothing matched.
+func (c *CLI) editItem(item *store.HistoryItem, re *regexp.Regexp, repl []byte, cmd *EditCmd) (int, error) {
+	history := c.store.History()
+	content, err := history.GetContent(item.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read item %q: %w", item.Title, err)
+	}
+	defer content.Close()
+
+	if cmd.DryRun {
+		return replaceLines(io.Discard, content, re, repl)
+	}
+
+	tmp, err := os.CreateTemp("", "rem-edit-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	out := bufio.NewWriter(tmp)
+	count, err := replaceLines(out, content, re, repl)
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to edit item %q: %w", item.Title, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to edit item %q: %w", item.Title, err)
+	}
+
+	if cmd.Backup {
+		if err := backupItem(history, item); err != nil {
+			return 0, err
+		}
+	}
+	if err := history.ReplaceContent(item.ID, tmp); err != nil {
+		return 0, fmt.Errorf("failed to rewrite item %q: %w", item.Title, err)
+	}
+	return count, nil
+}
+
+// backupItem stores a copy of item's current content next to it in the
+// queue, noted as the original
+func backupItem(history store.HistoryStore, item *store.HistoryItem) error {
+	content, err := history.GetContent(item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read item %q: %w", item.Title, err)
+	}
+	defer content.Close()
+
+	backup, err := history.Create(&store.CreateHistoryInput{
+		Title:     item.Title,
+		Content:   content,
+		Timestamp: item.Timestamp,
+
+		OriginalName:    item.OriginalName,
+		OriginalModTime: item.OriginalModTime,
+		OriginalMode:    item.OriginalMode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to back up item %q: %w", item.Title, err)
+	}
+	if err := history.UpdateNote(backup.ID, fmt.Sprintf("Original of item %d before rem edit", item.ID)); err != nil {
+		return fmt.Errorf("failed to note backup of item %q: %w", item.Title, err)
+	}
+	return nil
+}
+
+// replaceLines copies r to w one line at a time, replacing re's matches in
+// each line with repl, and returns the number of matches replaced. Lines are
+// matched without their newline, so $ matches at the end of each line.
+func replaceLines(w io.Writer, r io.Reader, re *regexp.Regexp, repl []byte) (int, error) {
+	in := bufio.NewReader(r)
+	count := 0
+	for {
+		line, readErr := in.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return count, readErr
+		}
+		body, newline := bytes.CutSuffix(line, []byte("\n"))
+		if matches := len(re.FindAllIndex(body, -1)); matches > 0 {
+			count += matches
+			body = re.ReplaceAll(body, repl)
+		}
+		if _, err := w.Write(body); err != nil {
+			return count, err
+		}
+		if newline {
+			if _, err := w.Write([]byte("\n")); err != nil {
+				return count, err
+			}
+		}
+		if readErr == io.EOF {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/yiblet/rem/internal/queue"
+)
+
+// Exit codes used by the rem binary
+const (
+	ExitSuccess  = 0 // also used when stdout's reader closed it early
+	ExitError    = 1 // any failure not covered below
+	ExitNotFound = 2 // the requested item does not exist
+	ExitNoIndex  = 3 // the index given is past the end of the queue
+	ExitStale    = 4 // --expect-generation named a generation the queue has moved past
+
+	ExitInterrupted = 130 // a maintenance run stopped by Ctrl+C
+)
+
+// ErrNotFound is wrapped by errors for lookups that matched no item
+var ErrNotFound = errors.New("not found")
+
+// ErrQueueChanged is wrapped by the error for a stale --expect-generation
+var ErrQueueChanged = errors.New("queue changed since you listed it")
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if errors.Is(err, ErrOutputClosed) || isBrokenPipe(err) {
+		return ExitSuccess
+	}
+	if errors.Is(err, ErrNotFound) {
+		return ExitNotFound
+	}
+	if errors.Is(err, queue.ErrIndexOutOfRange) {
+		return ExitNoIndex
+	}
+	if errors.Is(err, ErrQueueChanged) {
+		return ExitStale
+	}
+	if errors.Is(err, ErrInterrupted) {
+		return ExitInterrupted
+	}
+	return ExitError
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// defaultJSONMaxBytes is the largest content 'rem get --json' embeds by default
+const defaultJSONMaxBytes int64 = 1024 * 1024
+
+// itemMetaJSON is an item's metadata as printed by 'rem list --json'
+type itemMetaJSON struct {
+	ID        uint      `json:"id"`
+	Ref       string    `json:"ref"`
+	Index     int       `json:"index"`
+	Title     string    `json:"title"`
+	Note      string    `json:"note,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+	IsBinary  bool      `json:"is_binary"`
+
+	Meta       map[string]string `json:"meta,omitempty"`
+	Generation *uint64           `json:"generation,omitempty"` // the queue's, for --expect-generation
+	Sort       string            `json:"sort,omitempty"`       // the order rem list printed items in
+	Reverse    bool              `json:"reverse,omitempty"`
+	Preview    string            `json:"preview,omitempty"` // first line of content, with rem list --preview
+}
+
+// newItemMetaJSON returns the metadata of item at index
+func newItemMetaJSON(item *store.HistoryItem, index int) itemMetaJSON {
+	return itemMetaJSON{
+		ID:        item.ID,
+		Ref:       "@" + ref.Encode(item.ID),
+		Index:     index,
+		Title:     item.Title,
+		Note:      item.Note,
+		Timestamp: item.Timestamp,
+		CreatedAt: item.CreatedAt,
+		UpdatedAt: item.UpdatedAt,
+		Size:      item.Size,
+		SHA256:    item.SHA256,
+		IsBinary:  item.IsBinary,
+	}
+}
+
+// itemJSON is the metadata part of the 'rem get --json' object
+type itemJSON struct {
+	itemMetaJSON
+	Truncated bool `json:"truncated"`
+}
+
+// writeItemJSON writes item as a single JSON object followed by a newline.
+// Text content is embedded as "content" and binary content as
+// "content_base64"; content larger than maxBytes is omitted and "truncated"
+// is set. Binary content is base64-encoded as it streams from r.
+func writeItemJSON(w io.Writer, item *store.HistoryItem, index int, r io.Reader, maxBytes int64) error {
+	meta := itemJSON{
+		itemMetaJSON: newItemMetaJSON(item, index),
+		Truncated:    item.Size > maxBytes,
+	}
+	head, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode item: %w", err)
+	}
+
+	// Leave the object open so the content field can be appended
+	if _, err := w.Write(head[:len(head)-1]); err != nil {
+		return err
+	}
+
+	if !meta.Truncated {
+		if item.IsBinary {
+			err = writeBase64Field(w, "content_base64", r)
+		} else {
+			err = writeTextField(w, "content", r, maxBytes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+// writeTextField writes `,"name":"<content>"` with r's content JSON-escaped
+func writeTextField(w io.Writer, name string, r io.Reader, maxBytes int64) error {
+	content, err := io.ReadAll(io.LimitReader(r, maxBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	value, err := json.Marshal(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to encode content: %w", err)
+	}
+	_, err = fmt.Fprintf(w, `,%q:%s`, name, value)
+	return err
+}
+
+// writeBase64Field writes `,"name":"<base64>"`, encoding r as it is read
+func writeBase64Field(w io.Writer, name string, r io.Reader) error {
+	if _, err := fmt.Fprintf(w, `,%q:"`, name); err != nil {
+		return err
+	}
+	encoder := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := io.Copy(encoder, r); err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, `"`)
+	return err
+}
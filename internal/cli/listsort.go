@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// sortInMemoryMax is the most items rem list sorts itself. A larger history
+// is sorted by the store instead when it can do that with an index.
+var sortInMemoryMax = 1000
+
+// listedItem is an item rem list prints, with its queue index
+type listedItem struct {
+	item  *store.HistoryItem
+	index int
+}
+
+// listInOrder returns the queue in order. Each item keeps its queue index,
+// so a sorted listing still prints the indexes other commands take.
+func (c *CLI) listInOrder(order store.OrderSpec) ([]listedItem, error) {
+	if lister, ok := c.store.History().(store.OrderedLister); ok && order != (store.OrderSpec{Key: store.SortAge}) {
+		count, err := c.store.History().Count()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count items: %w", err)
+		}
+		if count > sortInMemoryMax {
+			return c.listOrdered(lister, order)
+		}
+	}
+
+	items, err := c.queueManager.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	listed := make([]listedItem, len(items))
+	for index, item := range items {
+		listed[index] = listedItem{item, index}
+	}
+	if order != (store.OrderSpec{Key: store.SortAge}) {
+		slices.SortFunc(listed, func(a, b listedItem) int {
+			switch {
+			case order.Less(a.item, b.item):
+				return -1
+			case order.Less(b.item, a.item):
+				return 1
+			}
+			return 0
+		})
+	}
+	return listed, nil
+}
+
+// listOrdered has the store sort the queue in one query. Its positions are
+// queue indexes; items past the history limit aren't in the queue.
+func (c *CLI) listOrdered(lister store.OrderedLister, order store.OrderSpec) ([]listedItem, error) {
+	ordered, err := lister.ListOrdered(order, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	listed := make([]listedItem, 0, len(ordered))
+	for _, item := range ordered {
+		if item.Position < c.queueManager.GetHistoryLimit() {
+			listed = append(listed, listedItem{item.Item, item.Position})
+		}
+	}
+	return listed, nil
+}
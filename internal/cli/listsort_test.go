@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/queue"
+)
+
+func TestListCommand_Sort(t *testing.T) {
+	// Oldest first; indexes are the reverse of this
+	specs := []struct {
+		title string
+		size  int
+	}{{"medium", 500}, {"huge", 3000}, {"tiny", 10}, {"big", 1500}}
+
+	type listCase struct {
+		cmd  ListCmd
+		want []string // "index title"
+	}
+	cases := []listCase{
+		{ListCmd{}, []string{"0 big", "1 tiny", "2 huge", "3 medium"}},
+		{ListCmd{Reverse: true}, []string{"3 medium", "2 huge", "1 tiny", "0 big"}},
+		{ListCmd{Sort: stringPtr("size")}, []string{"2 huge", "0 big", "3 medium", "1 tiny"}},
+		{ListCmd{Sort: stringPtr("size"), Reverse: true}, []string{"1 tiny", "3 medium", "0 big", "2 huge"}},
+		{ListCmd{Sort: stringPtr("title")}, []string{"0 big", "2 huge", "3 medium", "1 tiny"}},
+		{ListCmd{Sort: stringPtr("size"), MinSize: stringPtr("1KB")}, []string{"2 huge", "0 big"}},
+	}
+
+	run := func(t *testing.T, cli *CLI) {
+		t.Helper()
+		for _, spec := range specs {
+			cli.queueManager.Enqueue(strings.NewReader(strings.Repeat("x", spec.size)), spec.title)
+		}
+		for _, tc := range cases {
+			output := withStdout(t, func() {
+				if err := cli.executeList(&tc.cmd); err != nil {
+					t.Fatalf("list %+v failed: %v", tc.cmd, err)
+				}
+			})
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+				fields := strings.Split(line, "\t")
+				got = append(got, fields[0]+" "+fields[len(fields)-1])
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("list %+v = %v, want %v", tc.cmd, got, tc.want)
+			}
+		}
+
+		// Sizes are right-aligned, and JSON records the order applied
+		output := withStdout(t, func() { cli.executeList(&ListCmd{Size: true}) })
+		if !strings.Contains(output, "\t   1.5 KB\tbig\n") || !strings.Contains(output, "\t     10 B\ttiny\n") {
+			t.Errorf("Expected an aligned size column, got %q", output)
+		}
+		output = withStdout(t, func() { cli.executeList(&ListCmd{JSON: true, Sort: stringPtr("size"), Reverse: true}) })
+		var listed struct {
+			Title   string `json:"title"`
+			Sort    string `json:"sort"`
+			Reverse bool   `json:"reverse"`
+		}
+		if err := json.Unmarshal([]byte(strings.SplitN(output, "\n", 2)[0]), &listed); err != nil {
+			t.Fatalf("Invalid JSON %q: %v", output, err)
+		}
+		if listed.Title != "tiny" || listed.Sort != "size" || !listed.Reverse {
+			t.Errorf("Expected tiny first with the sort recorded, got %+v", listed)
+		}
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "test.db")
+		cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+		if err != nil {
+			t.Fatalf("Failed to create CLI: %v", err)
+		}
+		defer cli.store.Close()
+		run(t, cli)
+
+		// Sorting in SQL gives the same listings
+		defer func(max int) { sortInMemoryMax = max }(sortInMemoryMax)
+		for _, tc := range cases {
+			sortInMemoryMax = len(specs)
+			inMemory := withStdout(t, func() { cli.executeList(&tc.cmd) })
+			sortInMemoryMax = 0
+			inSQL := withStdout(t, func() { cli.executeList(&tc.cmd) })
+			if inSQL != inMemory {
+				t.Errorf("list %+v sorted in SQL = %q, in memory %q", tc.cmd, inSQL, inMemory)
+			}
+		}
+
+		// Items past the history limit aren't in the queue either way
+		limited, err := queue.NewQueueManagerWithConfig(cli.store, 2)
+		if err != nil {
+			t.Fatalf("Failed to create queue manager: %v", err)
+		}
+		cli.queueManager = limited
+		for _, max := range []int{len(specs), 0} {
+			sortInMemoryMax = max
+			output := withStdout(t, func() { cli.executeList(&ListCmd{Sort: stringPtr("size")}) })
+			if strings.Count(output, "\n") != 2 || !strings.Contains(output, "\tbig\n") || !strings.Contains(output, "\ttiny\n") {
+				t.Errorf("list --sort size with a history limit of 2 (sorting up to %d in memory) = %q", max, output)
+			}
+		}
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		memory := MemoryDBPath
+		cli, err := NewWithArgs(&Args{DBPath: &memory})
+		if err != nil {
+			t.Fatalf("Failed to create CLI: %v", err)
+		}
+		defer cli.store.Close()
+		run(t, cli)
+	})
+
+	for _, cmd := range []*ListCmd{{Sort: stringPtr("usage")}, {MinSize: stringPtr("lots")}} {
+		if err := cmd.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", cmd)
+		}
+	}
+}
+
+func TestListCommand_Preview(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	cli.queueManager.Enqueue(strings.NewReader("\n\n  first line\nsecond line\n"+strings.Repeat("x", 100000)), "notes")
+	cli.queueManager.Enqueue(strings.NewReader("\x00\x01\x02binary"), "blob")
+
+	output := withStdout(t, func() {
+		if err := cli.executeList(&ListCmd{Preview: true}); err != nil {
+			t.Fatalf("list --preview failed: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 4 || !strings.HasSuffix(lines[0], "\tblob") || lines[1] != "    [binary content]" ||
+		!strings.HasSuffix(lines[2], "\tnotes") || lines[3] != "    first line" {
+		t.Errorf("list --preview = %q", output)
+	}
+
+	output = withStdout(t, func() { cli.executeList(&ListCmd{Preview: true, JSON: true}) })
+	if !strings.Contains(output, `"preview":"first line"`) {
+		t.Errorf("list --preview --json = %q, want a preview field", output)
+	}
+}
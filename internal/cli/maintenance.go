@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// executeStats handles the 'rem stats' command
+func (c *CLI) executeStats(cmd *StatsCmd) error {
+	db, err := c.sqliteStore()
+	if err != nil {
+		return err
+	}
+	stats, err := db.Stats()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Items:          %d (%d compressed)\n", stats.Items, stats.CompressedItems)
+	fmt.Printf("Logical bytes:  %d\n", stats.LogicalBytes)
+	fmt.Printf("Physical bytes: %d", stats.PhysicalBytes)
+	if stats.LogicalBytes > 0 {
+		fmt.Printf(" (%.0f%% of logical)", 100*float64(stats.PhysicalBytes)/float64(stats.LogicalBytes))
+	}
+	fmt.Println()
+	return nil
+}
+
+// executeMaintenance handles the 'rem maintenance' command
+func (c *CLI) executeMaintenance(cmd *MaintenanceCmd) error {
+	switch {
+	case cmd.Recompress != nil:
+		return c.executeRecompress(cmd.Recompress)
+	case cmd.FixPermissions != nil:
+		return c.executeFixPermissions()
+	case cmd.Verify != nil:
+		return c.executeVerify(cmd.Verify)
+	default:
+		return fmt.Errorf("no maintenance subcommand specified")
+	}
+}
+
+// executeRecompress handles the 'rem maintenance recompress' command,
+// bringing every item in line with the current compress_min_bytes
+func (c *CLI) executeRecompress(cmd *MaintenanceRecompressCmd) error {
+	db, err := c.sqliteStore()
+	if err != nil {
+		return err
+	}
+	changed := 0
+	summary, err := c.runMaintenance(cmd.ResumeFrom, db.History(), func(item *store.HistoryItem) error {
+		ok, err := db.Recompress(item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to recompress %q: %w", item.Title, err)
+		}
+		if ok {
+			changed++
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrInterrupted) {
+		return err
+	}
+	fmt.Printf("Recompressed %d of %d item(s)\n", changed, summary.Done)
+	return err
+}
+
+// maxJobs caps --jobs; past it the database, not the CPU, limits the speed
+const maxJobs = 8
+
+// defaultJobs is the --jobs used when none is given
+func defaultJobs() int {
+	return min(runtime.NumCPU(), maxJobs)
+}
+
+// executeVerify handles the 'rem maintenance verify' command, checking every
+// item's content against its recorded size and SHA256
+func (c *CLI) executeVerify(cmd *MaintenanceVerifyCmd) error {
+	jobs := cmd.Jobs
+	if jobs == 0 {
+		jobs = defaultJobs()
+	}
+	history := c.store.History()
+	var problems []string
+	summary, err := c.runMaintenanceChecks(cmd.ResumeFrom, jobs, history, verifyCheck(history, &problems))
+	if err != nil && !errors.Is(err, ErrInterrupted) {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	fmt.Printf("Verified %d item(s): %d problem(s)\n", summary.Done, len(problems))
+	if err == nil && len(problems) > 0 {
+		err = fmt.Errorf("%d item(s) failed verification", len(problems))
+	}
+	return err
+}
+
+// verifyCheck returns a check for runChecks that verifies an item and,
+// in item order, appends any problem to problems
+func verifyCheck(history store.HistoryStore, problems *[]string) func(*store.HistoryItem) func() error {
+	return func(item *store.HistoryItem) func() error {
+		problem := verifyItem(history, item)
+		return func() error {
+			if problem != "" {
+				*problems = append(*problems, problem)
+			}
+			return nil
+		}
+	}
+}
+
+// verifyItem streams item's content through SHA256 and describes how it
+// differs from the item's recorded size and hash, or returns "" if it doesn't
+func verifyItem(history store.HistoryStore, item *store.HistoryItem) string {
+	r, err := history.GetContent(item.ID)
+	if err != nil {
+		return fmt.Sprintf("item %d %q: %v", item.ID, item.Title, err)
+	}
+	defer r.Close()
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, r)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("item %d %q: failed to read content: %v", item.ID, item.Title, err)
+	case n != item.Size:
+		return fmt.Sprintf("item %d %q: content is %d bytes, expected %d", item.ID, item.Title, n, item.Size)
+	}
+	if sum := hex.EncodeToString(hasher.Sum(nil)); sum != item.SHA256 {
+		return fmt.Sprintf("item %d %q: SHA256 is %s, expected %s", item.ID, item.Title, sum, item.SHA256)
+	}
+	return ""
+}
+
+// runMaintenance runs fn over every item from source, showing progress on
+// a terminal and stopping after the current item on Ctrl+C
+func (c *CLI) runMaintenance(resumeFrom *uint, source itemSource, fn func(*store.HistoryItem) error) (runSummary, error) {
+	return c.runMaintenanceChecks(resumeFrom, 1, source, func(item *store.HistoryItem) func() error {
+		return func() error { return fn(item) }
+	})
+}
+
+// runMaintenanceChecks is runMaintenance for work split like
+// maintenanceRunner.runChecks, checking jobs items at once
+func (c *CLI) runMaintenanceChecks(resumeFrom *uint, jobs int, source itemSource, check func(*store.HistoryItem) func() error) (runSummary, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	runner := &maintenanceRunner{source: source, jobs: jobs, progress: terminalProgress(os.Stderr)}
+	if resumeFrom != nil {
+		runner.resumeFrom = *resumeFrom
+	}
+	summary, err := runner.runChecks(ctx, check)
+	finishRun(os.Stderr, runner.progress, summary, err)
+	return summary, err
+}
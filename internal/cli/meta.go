@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// executeMeta handles the 'rem meta' command
+func (c *CLI) executeMeta(cmd *MetaCmd) error {
+	switch {
+	case cmd.Set != nil:
+		item, err := c.resolveItem(cmd.Set.Index, nil)
+		if err != nil {
+			return err
+		}
+		if err := c.store.History().SetMeta(item.ID, cmd.Set.Key, cmd.Set.Value); err != nil {
+			return fmt.Errorf("failed to set metadata: %w", err)
+		}
+		fmt.Printf("Set %s=%s: %s\n", cmd.Set.Key, cmd.Set.Value, item.Title)
+		return nil
+
+	case cmd.Del != nil:
+		item, err := c.resolveItem(cmd.Del.Index, nil)
+		if err != nil {
+			return err
+		}
+		if err := c.store.History().DeleteMeta(item.ID, cmd.Del.Key); err != nil {
+			return fmt.Errorf("failed to delete metadata: %w", err)
+		}
+		fmt.Printf("Deleted %s: %s\n", cmd.Del.Key, item.Title)
+		return nil
+
+	case cmd.Get != nil:
+		item, err := c.resolveItem(cmd.Get.Index, nil)
+		if err != nil {
+			return err
+		}
+		meta, err := c.store.History().GetMeta(item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get metadata: %w", err)
+		}
+		if cmd.Get.Key != nil {
+			value, ok := meta[*cmd.Get.Key]
+			if !ok {
+				return fmt.Errorf("item %d has no metadata key %q", item.ID, *cmd.Get.Key)
+			}
+			fmt.Println(value)
+			return nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(meta)) {
+			fmt.Printf("%s\t%s\n", key, meta[key])
+		}
+		return nil
+	}
+	return fmt.Errorf("specify a meta subcommand: set, get, or del")
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yiblet/rem/internal/opener"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// maxURLBytes is the most content get --open reads looking for a URL
+const maxURLBytes = 8 * 1024
+
+// openItem opens the URL that is item's whole content, ignoring
+// surrounding whitespace
+func (c *CLI) openItem(item *store.HistoryItem, index int, content io.Reader, status statusLine) error {
+	data, err := io.ReadAll(io.LimitReader(content, maxURLBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+	url := strings.TrimSpace(string(data))
+	if len(data) > maxURLBytes || !opener.IsURL(url) {
+		return fmt.Errorf("item %d (%s) is not a single http(s) URL", index, item.Title)
+	}
+	if err := c.opener.Open(url); err != nil {
+		return err
+	}
+	return status.print(item, index, "Opened %s\n", url)
+}
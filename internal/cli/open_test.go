@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/opener"
+)
+
+func TestGetCommand_Open(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	var ran []string
+	cli.opener = opener.NewWithExec("linux", func(name string, args ...string) error {
+		ran = append(ran, fmt.Sprint(name, " ", args))
+		return nil
+	})
+
+	cli.queueManager.Enqueue(strings.NewReader("see https://example.com/a and https://example.com/b"), "two")
+	cli.queueManager.Enqueue(strings.NewReader("  https://example.com/docs?q=1&r=2\n"), "link")
+
+	output := withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), Open: true}); err != nil {
+			t.Fatalf("get --open error = %v", err)
+		}
+	})
+	if len(ran) != 1 || ran[0] != "xdg-open [https://example.com/docs?q=1&r=2]" {
+		t.Errorf("ran %q, want xdg-open of the trimmed URL", ran)
+	}
+	if output != "Opened https://example.com/docs?q=1&r=2\n" {
+		t.Errorf("output = %q", output)
+	}
+
+	err = cli.executeGet(&GetCmd{Index: indexArg(1), Open: true})
+	if err == nil || !strings.Contains(err.Error(), "not a single http(s) URL") || len(ran) != 1 {
+		t.Errorf("get --open of prose error = %v, ran %q", err, ran)
+	}
+
+	if err := (&GetCmd{Index: indexArg(0), Open: true, Clipboard: true}).Validate(); err == nil {
+		t.Error("expected --open with -c to be rejected")
+	}
+	if err := (&GetCmd{Open: true}).Validate(); err == nil {
+		t.Error("expected --open without an index to be rejected")
+	}
+}
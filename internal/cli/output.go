@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// ErrOutputClosed is wrapped by errors for output that stopped because the
+// reader of stdout went away, as in 'rem get 0 | head -1'. The reader got
+// what it wanted, so it is not a failure.
+var ErrOutputClosed = errors.New("output closed")
+
+// copyToStdout streams r to stdout, stopping with ErrOutputClosed if stdout
+// is a pipe whose reader has exited
+func copyToStdout(r io.Reader) error {
+	return copyOutput(os.Stdout, r)
+}
+
+// copyOutput streams r to w, mapping broken pipes to ErrOutputClosed
+func copyOutput(w io.Writer, r io.Reader) error {
+	if _, err := io.Copy(w, r); err != nil {
+		if isBrokenPipe(err) {
+			return fmt.Errorf("%w: %w", ErrOutputClosed, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// isBrokenPipe reports whether err is a write to a pipe with no reader
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// epipeWriter accepts limit bytes, then fails like a pipe whose reader exited
+type epipeWriter struct {
+	limit   int
+	written int
+}
+
+func (w *epipeWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		n := w.limit - w.written
+		w.written = w.limit
+		return n, &os.PathError{Op: "write", Path: "|1", Err: syscall.EPIPE}
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestCopyOutput_BrokenPipe(t *testing.T) {
+	w := &epipeWriter{limit: 10}
+	err := copyOutput(w, strings.NewReader(strings.Repeat("x", 100)))
+	if !errors.Is(err, ErrOutputClosed) || ExitCode(err) != ExitSuccess {
+		t.Errorf("copyOutput() = %v (exit %d), want ErrOutputClosed and exit 0", err, ExitCode(err))
+	}
+	if w.written != 10 {
+		t.Errorf("expected writing to stop at the broken pipe, wrote %d bytes", w.written)
+	}
+
+	// Other write errors still fail
+	readOnly, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+	if err := copyOutput(readOnly, strings.NewReader("x")); errors.Is(err, ErrOutputClosed) || ExitCode(err) != ExitError {
+		t.Errorf("copyOutput() = %v (exit %d), want a failure", err, ExitCode(err))
+	}
+}
+
+func TestGetCommand_ClosedPipe(t *testing.T) {
+	tempDir := t.TempDir()
+	dbPath := filepath.Join(tempDir, "pipe.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Much larger than a pipe buffer, so the write is still going when the
+	// reader stops
+	content := strings.Repeat("line of a large item\n", 100000)
+	if _, err := cli.queueManager.Enqueue(strings.NewReader(content), "large"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	// Like 'rem get 0 | head -c 100'
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan []byte)
+	go func() {
+		head := make([]byte, 100)
+		n, _ := io.ReadFull(r, head)
+		r.Close()
+		done <- head[:n]
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	index := 0
+	var getErr error
+	stderr := withStderr(t, func() {
+		getErr = cli.executeGet(&GetCmd{Index: indexArg(index), Verbose: true})
+	})
+	os.Stdout = stdout
+	w.Close()
+
+	if head := <-done; string(head) != content[:100] {
+		t.Errorf("expected the reader to get the start of the item, got %q", head)
+	}
+	if !errors.Is(getErr, ErrOutputClosed) || ExitCode(getErr) != ExitSuccess {
+		t.Errorf("executeGet() = %v (exit %d), want ErrOutputClosed and exit 0", getErr, ExitCode(getErr))
+	}
+	if !strings.Contains(stderr, "output closed before item 0 (large) was fully written") {
+		t.Errorf("expected a note on stderr with --verbose, got %q", stderr)
+	}
+}
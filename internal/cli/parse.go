@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alexflint/go-arg"
+)
+
+// Parse parses argv into args as arg.MustParse does, printing help or a
+// usage error and exiting, but with the errors explainParseError rephrases
+func Parse(args *Args, argv []string) *arg.Parser {
+	parser, err := arg.NewParser(arg.Config{}, args)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	args.argv = argv
+	switch err := parser.Parse(argv); {
+	case errors.Is(err, arg.ErrHelp):
+		parser.WriteHelpForSubcommand(os.Stdout, parser.SubcommandNames()...)
+		os.Exit(0)
+	case err != nil:
+		parser.FailSubcommand(explainParseError(args, err), parser.SubcommandNames()...)
+	}
+	return parser
+}
+
+// explainParseError returns the message for an error parsing args. The
+// first positional of 'rem get' is the index, so in 'rem get out.txt' a file
+// name went where the index belongs; say how to write to it instead.
+func explainParseError(args *Args, err error) string {
+	_, bad, found := strings.Cut(err.Error(), invalidIndexPrefix)
+	if args.Get == nil || !found {
+		return err.Error()
+	}
+	file := strings.Trim(bad, "'")
+	return fmt.Sprintf("%s is not an index or @reference; to write an item to a file, give the index first, as in 'rem get 0 %s', or use -o, as in 'rem get -o %s 0'",
+		bad, file, file)
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/dbstore"
+)
+
+// privateDirMode is the mode of directories holding rem's files
+const privateDirMode = 0700
+
+// privatePath is a file or directory that only its owner should access
+type privatePath struct {
+	path string
+	mode os.FileMode // the mode it should have
+}
+
+// privatePaths returns rem's files for the database at dbPath that exist:
+// the database and its journals, the backups directory and its backups, and
+// the database's directory when ownDir is set. A directory given with
+// --db-path may be shared, such as /tmp, so it is left alone.
+func privatePaths(dbPath string, ownDir bool) []privatePath {
+	var paths []privatePath
+	if ownDir {
+		paths = append(paths, privatePath{filepath.Dir(dbPath), privateDirMode})
+	}
+	for _, file := range dbstore.DatabaseFiles(dbPath) {
+		paths = append(paths, privatePath{file, dbstore.PrivateFileMode})
+	}
+
+	dir := backupDir(dbPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return paths
+	}
+	paths = append(paths, privatePath{dir, privateDirMode})
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			paths = append(paths, privatePath{filepath.Join(dir, entry.Name()), dbstore.PrivateFileMode})
+		}
+	}
+	return paths
+}
+
+// loosePaths returns the paths group or other users can access. Windows
+// doesn't report access through permission bits, so nothing is loose there.
+func loosePaths(paths []privatePath) []privatePath {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	var loose []privatePath
+	for _, p := range paths {
+		info, err := os.Stat(p.path)
+		if err == nil && info.Mode().Perm()&0077 != 0 {
+			loose = append(loose, p)
+		}
+	}
+	return loose
+}
+
+// permissionsWarning returns a warning when rem's files can be read by
+// other users, or "" if they can't or warn_permissions is false
+func permissionsWarning(st store.Store, dbPath string, ownDir bool) string {
+	if value, err := st.Config().Get("warn_permissions"); err == nil && value == "false" {
+		return ""
+	}
+	loose := loosePaths(privatePaths(dbPath, ownDir))
+	if len(loose) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %d rem file(s) can be accessed by other users, including %s; fix with 'rem maintenance fix-permissions' (or silence with 'rem config set warn_permissions false')",
+		len(loose), loose[0].path)
+}
+
+// executeFixPermissions handles the 'rem maintenance fix-permissions'
+// command, making rem's files private to their owner
+func (c *CLI) executeFixPermissions() error {
+	loose := loosePaths(privatePaths(c.dbPath, c.ownsDBDir))
+	var failed []string
+	for _, p := range loose {
+		if err := os.Chmod(p.path, p.mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to change %s: %v\n", p.path, err)
+			failed = append(failed, p.path)
+			continue
+		}
+		fmt.Printf("Changed %s to %04o\n", p.path, p.mode)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not change permissions of %s", strings.Join(failed, ", "))
+	}
+	fmt.Printf("Fixed %d path(s)\n", len(loose))
+	return nil
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// assertMode fails the test unless path has the permission bits want
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat %s: %v", path, err)
+	}
+	if got := info.Mode().Perm(); got != want {
+		t.Errorf("%s has mode %04o, want %04o", path, got, want)
+	}
+}
+
+func TestNewWithArgs_PrivatePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+	dbPath := filepath.Join(t.TempDir(), "data", "rem.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	assertMode(t, filepath.Dir(dbPath), 0700)
+	assertMode(t, dbPath, 0600)
+
+	if err := cli.executeBackup(&BackupCmd{Create: &BackupCreateCmd{}}); err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	backups, _ := filepath.Glob(filepath.Join(backupDir(dbPath), "*.db"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	assertMode(t, backupDir(dbPath), 0700)
+	assertMode(t, backups[0], 0600)
+}
+
+func TestFixPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+	dbPath := filepath.Join(t.TempDir(), "rem", "rem.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.ownsDBDir = true
+	withStdout(t, func() { cli.executeBackup(&BackupCmd{Create: &BackupCreateCmd{}}) })
+	backups, _ := filepath.Glob(filepath.Join(backupDir(dbPath), "*.db"))
+
+	if warning := permissionsWarning(cli.store, dbPath, true); warning != "" {
+		t.Errorf("expected no warning for a fresh database, got %q", warning)
+	}
+
+	// Loosen everything the way an older rem or a permissive umask left it
+	os.Chmod(filepath.Dir(dbPath), 0755)
+	os.Chmod(dbPath, 0644)
+	os.Chmod(backupDir(dbPath), 0755)
+	os.Chmod(backups[0], 0644)
+
+	warning := permissionsWarning(cli.store, dbPath, true)
+	if !strings.Contains(warning, "4 rem file(s)") || !strings.Contains(warning, "fix-permissions") {
+		t.Errorf("expected a warning naming fix-permissions, got %q", warning)
+	}
+	cli.store.Config().Set("warn_permissions", "false")
+	if warning := permissionsWarning(cli.store, dbPath, true); warning != "" {
+		t.Errorf("expected warn_permissions false to silence the warning, got %q", warning)
+	}
+
+	out := withStdout(t, func() {
+		if err := cli.executeMaintenance(&MaintenanceCmd{FixPermissions: &MaintenanceFixPermissionsCmd{}}); err != nil {
+			t.Fatalf("fix-permissions failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Fixed 4 path(s)") {
+		t.Errorf("expected four paths fixed, got %q", out)
+	}
+	assertMode(t, filepath.Dir(dbPath), 0700)
+	assertMode(t, dbPath, 0600)
+	assertMode(t, backupDir(dbPath), 0700)
+	assertMode(t, backups[0], 0600)
+
+	// A directory given with --db-path may be shared, so it is left alone
+	os.Chmod(filepath.Dir(dbPath), 0755)
+	if loose := loosePaths(privatePaths(dbPath, false)); len(loose) != 0 {
+		t.Errorf("expected a --db-path directory to be ignored, got %v", loose)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/muesli/termenv"
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// pickerPageSize is how many items the plain picker lists before asking
+// whether to show more
+const pickerPageSize = 20
+
+// tuiSupported reports whether stdout is a terminal the TUI can take over.
+// Pipes, CI logs, and terminals such as TERM=dumb (emacs shell-mode) are not.
+func tuiSupported() bool {
+	return termenv.NewOutput(os.Stdout).ColorProfile() != termenv.Ascii
+}
+
+// executePlainPicker is 'rem get' without an index when the TUI can't run:
+// it lists the items, reads the chosen index from stdin, and then gets it
+// like 'rem get <index>'. The list and prompts go to stderr, so stdout only
+// carries the item.
+func (c *CLI) executePlainPicker(cmd *GetCmd) error {
+	items, err := c.queueManager.List()
+	if err != nil {
+		return fmt.Errorf("error listing queue items: %w", err)
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "Queue is empty!")
+		return nil
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	now := timefmt.Now(c.timeLocation())
+	answer := ""
+	for start := 0; start < len(items); start += pickerPageSize {
+		end := min(start+pickerPageSize, len(items))
+		for i := start; i < end; i++ {
+			fmt.Fprintf(os.Stderr, "%3d  %-10s  %s\n", i, timefmt.Age(items[i].Timestamp, now), items[i].Title)
+		}
+		if end == len(items) {
+			break
+		}
+		fmt.Fprint(os.Stderr, "more? [y/N] ")
+		if answer, err = readAnswer(in); err != nil {
+			return err
+		}
+		if answer != "y" && answer != "yes" {
+			break
+		}
+		answer = ""
+	}
+
+	// An index typed at the "more?" prompt selects directly
+	if answer == "" || answer == "n" || answer == "no" {
+		fmt.Fprintf(os.Stderr, "Select an item [0-%d, q to quit]: ", len(items)-1)
+		if answer, err = readAnswer(in); err != nil {
+			return err
+		}
+	}
+	if answer == "" || answer == "q" {
+		fmt.Fprintln(os.Stderr, "Aborted")
+		return nil
+	}
+	index, err := strconv.Atoi(answer)
+	if err != nil {
+		return fmt.Errorf("invalid selection %q: expected an index", answer)
+	}
+
+	selected := *cmd
+	selected.Index = &ItemArg{Index: index}
+	return c.executeGet(&selected)
+}
+
+// readAnswer reads one line of input, trimmed and lower-cased. End of input
+// reads as an empty answer.
+func readAnswer(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read selection: %w", err)
+	}
+	return strings.ToLower(strings.TrimSpace(line)), nil
+}
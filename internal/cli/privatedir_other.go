@@ -0,0 +1,21 @@
+//go:build !unix
+
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkPrivateDir returns an error unless dir is a directory, not a
+// symlink; ownership and mode aren't checked on this platform
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check database directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("database directory %s is not a directory; remove it or choose another location with --db-path or %s", dir, RemDBEnv)
+	}
+	return nil
+}
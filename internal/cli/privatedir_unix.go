@@ -0,0 +1,29 @@
+//go:build unix
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkPrivateDir returns an error unless dir is a directory, not a
+// symlink, owned by this user and accessible by no one else. Another user
+// who created dir first could read or replace the database in it.
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check database directory %s: %w", dir, err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	switch {
+	case !info.IsDir():
+		return fmt.Errorf("database directory %s is not a directory; remove it or choose another location with --db-path or %s", dir, RemDBEnv)
+	case !ok || int(stat.Uid) != os.Getuid():
+		return fmt.Errorf("database directory %s is owned by another user; remove it or choose another location with --db-path or %s", dir, RemDBEnv)
+	case info.Mode().Perm() != 0700:
+		return fmt.Errorf("database directory %s has mode %04o, want 0700; fix it with chmod 700 or choose another location with --db-path or %s", dir, info.Mode().Perm(), RemDBEnv)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/yiblet/rem/internal/tui"
+)
+
+// parseRecentCopies decodes the recent_copies config value
+func parseRecentCopies(value string) ([]uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var ids []uint
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, fmt.Errorf("recent_copies must be a JSON array of item IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// recentCopiesLimit returns how many recently copied items are remembered
+func (c *CLI) recentCopiesLimit() int {
+	value, err := c.store.Config().Get("recent_copies_limit")
+	if err != nil {
+		return tui.DefaultRecentCopiesLimit
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return tui.DefaultRecentCopiesLimit
+	}
+	return limit
+}
+
+// loadRecentCopies returns the recently copied item IDs, newest first,
+// leaving out items deleted since they were copied
+func (c *CLI) loadRecentCopies() ([]uint, error) {
+	// An unset key is no copies yet
+	value, _ := c.store.Config().Get("recent_copies")
+	ids, err := parseRecentCopies(value)
+	if err != nil {
+		return nil, err
+	}
+	kept := ids[:0]
+	for _, id := range ids {
+		if _, err := c.store.History().Get(id); err == nil {
+			kept = append(kept, id)
+		}
+	}
+	return kept, nil
+}
+
+// recordCopies puts copied, newest first, at the front of recent_copies,
+// keeping recent_copies_limit of them
+func (c *CLI) recordCopies(copied []uint) error {
+	limit := c.recentCopiesLimit()
+	if limit == 0 || len(copied) == 0 {
+		return nil
+	}
+	ids, err := c.loadRecentCopies()
+	if err != nil {
+		return err
+	}
+	ids = append(append([]uint{}, copied...), ids...)
+	kept := make([]uint, 0, limit)
+	seen := map[uint]bool{}
+	for _, id := range ids {
+		if !seen[id] && len(kept) < limit {
+			seen[id] = true
+			kept = append(kept, id)
+		}
+	}
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return fmt.Errorf("failed to encode recent copies: %w", err)
+	}
+	if err := c.store.Config().Set("recent_copies", string(data)); err != nil {
+		return fmt.Errorf("failed to save recent copies: %w", err)
+	}
+	return nil
+}
+
+// clearRecentCopies forgets every recently copied item, as when item IDs
+// start from 1 again and the remembered ones would mark new items
+func (c *CLI) clearRecentCopies() error {
+	if err := c.store.Config().Set("recent_copies", "[]"); err != nil {
+		return fmt.Errorf("failed to clear recent copies: %w", err)
+	}
+	return nil
+}
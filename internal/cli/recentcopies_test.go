@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+)
+
+func TestRecentCopies_Persistence(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "recent-copies.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.clipboard = mockboard.New()
+
+	var ids []uint
+	for i := 0; i < 8; i++ {
+		item, err := cli.queueManager.Enqueue(strings.NewReader(fmt.Sprintf("snippet %d", i)), "")
+		if err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+
+	// rem get -c records each copy, newest first, keeping the limit
+	for _, index := range []int{7, 6, 5, 4, 3, 2, 7} {
+		withStdout(t, func() {
+			if err := cli.executeGet(&GetCmd{Index: indexArg(index), Clipboard: true}); err != nil {
+				t.Fatalf("get -c %d error = %v", index, err)
+			}
+		})
+	}
+	want := []uint{ids[0], ids[5], ids[4], ids[3], ids[2]}
+	got, err := cli.loadRecentCopies()
+	if err != nil {
+		t.Fatalf("loadRecentCopies() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("recent copies = %v, want %v", got, want)
+	}
+
+	// A session's copies go in front of the saved ones
+	if err := cli.recordCopies([]uint{ids[7], ids[6]}); err != nil {
+		t.Fatalf("recordCopies() error = %v", err)
+	}
+	if got, _ := cli.loadRecentCopies(); !slices.Equal(got, []uint{ids[7], ids[6], ids[0], ids[5], ids[4]}) {
+		t.Errorf("after a session, recent copies = %v", got)
+	}
+
+	// Deleted items are pruned when the list is loaded
+	for _, id := range []uint{ids[7], ids[0]} {
+		if err := cli.store.History().Delete(id); err != nil {
+			t.Fatalf("Failed to delete %d: %v", id, err)
+		}
+	}
+	if got, _ := cli.loadRecentCopies(); !slices.Equal(got, []uint{ids[6], ids[5], ids[4]}) {
+		t.Errorf("after deletes, recent copies = %v", got)
+	}
+	if err := cli.recordCopies([]uint{ids[1]}); err != nil {
+		t.Fatalf("recordCopies() error = %v", err)
+	}
+	if value, _ := cli.store.Config().Get("recent_copies"); value != fmt.Sprintf("[%d,%d,%d,%d]", ids[1], ids[6], ids[5], ids[4]) {
+		t.Errorf("saved recent_copies = %s, want the deleted IDs gone", value)
+	}
+
+	// A limit of 0 records nothing
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "recent_copies_limit", Value: "0"}); err != nil {
+		t.Fatalf("Failed to set recent_copies_limit: %v", err)
+	}
+	if err := cli.recordCopies([]uint{ids[2]}); err != nil {
+		t.Fatalf("recordCopies() error = %v", err)
+	}
+	if got, _ := cli.loadRecentCopies(); got[0] == ids[2] {
+		t.Errorf("recorded a copy with the limit at 0: %v", got)
+	}
+	if err := cli.executeConfigSet(&ConfigSetCmd{Key: "recent_copies", Value: `["x"]`}); err == nil {
+		t.Error("Expected a recent_copies that isn't a JSON array of IDs to be rejected")
+	}
+}
+
+func TestRecentCopies_ReadOnlyTUI(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "recent-copies.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("snippet"), ""); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	cli.store.Close()
+
+	cli, err = NewWithArgs(&Args{DBPath: &dbPath, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to open read-only: %v", err)
+	}
+	defer cli.store.Close()
+	cli.clipboard = mockboard.New()
+
+	defer func(run func(tea.Model) (tea.Model, error)) { runProgram = run }(runProgram)
+	runProgram = func(model tea.Model) (tea.Model, error) {
+		model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+		for _, key := range []string{"c", "/", "s", "enter"} {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			if key == "enter" {
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			}
+			model, _ = model.Update(msg)
+		}
+		return model, nil
+	}
+
+	// Copying and searching in a read-only TUI is fine; nothing is saved
+	if err := cli.launchTUI(); err != nil {
+		t.Fatalf("read-only TUI error = %v", err)
+	}
+	if value, _ := cli.store.Config().Get("recent_copies"); value != "" {
+		t.Errorf("recent_copies = %q, want nothing recorded", value)
+	}
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// restoreFile writes an item stored from a file back to its original name
+// in the current directory, then restores the file's mode and modification
+// time. An existing file of that name is overwritten.
+func restoreFile(item *store.HistoryItem, index int, content io.Reader, status statusLine) error {
+	name := filepath.Base(item.OriginalName)
+	if item.OriginalName == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		return fmt.Errorf("item %d was not stored from a file; give an output file instead", item.ID)
+	}
+
+	perm := item.OriginalMode.Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write to file: %w", err)
+	}
+
+	// OpenFile applies the umask and leaves an existing file's mode alone
+	if err := os.Chmod(name, perm); err != nil {
+		return fmt.Errorf("failed to restore mode: %w", err)
+	}
+	if !item.OriginalModTime.IsZero() {
+		if err := os.Chtimes(name, time.Now(), item.OriginalModTime); err != nil {
+			return fmt.Errorf("failed to restore modification time: %w", err)
+		}
+	}
+
+	return status.print(item, index, "Restored %s (%s): %s\n", name, perm, item.Title)
+}
+
+// executeInfo handles the 'rem info' command
+func (c *CLI) executeInfo(cmd *InfoCmd) error {
+	item, err := c.resolveItem(cmd.Index, cmd.ID)
+	if err != nil {
+		return err
+	}
+
+	kind := "text"
+	if item.IsBinary {
+		kind = "binary"
+	}
+	fmt.Printf("ID:       %d\n", item.ID)
+	fmt.Printf("Ref:      @%s\n", ref.Encode(item.ID))
+	fmt.Printf("Title:    %s\n", item.Title)
+	if item.Note != "" {
+		fmt.Printf("Note:     %s\n", item.Note)
+	}
+	fmt.Printf("Created:  %s\n", c.formatTime(item.CreatedAt, time.RFC3339))
+	fmt.Printf("Updated:  %s\n", c.formatTime(item.UpdatedAt, time.RFC3339))
+	// Bumping moves an item in the queue without changing when it was stored
+	fmt.Printf("Queued:   %s\n", c.formatTime(item.Timestamp, time.RFC3339))
+	fmt.Printf("Size:     %d bytes (%s)\n", item.Size, kind)
+	fmt.Printf("SHA256:   %s\n", item.SHA256)
+	if item.OriginalName != "" {
+		fmt.Printf("File:     %s\n", item.OriginalName)
+		fmt.Printf("Mode:     %s\n", item.OriginalMode)
+		if !item.OriginalModTime.IsZero() {
+			fmt.Printf("Modified: %s\n", c.formatTime(item.OriginalModTime, time.RFC3339))
+		}
+	}
+
+	meta, err := c.store.History().GetMeta(item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata: %w", err)
+	}
+	for i, key := range slices.Sorted(maps.Keys(meta)) {
+		label := "Meta:"
+		if i > 0 {
+			label = ""
+		}
+		fmt.Printf("%-10s%s=%s\n", label, key, meta[key])
+	}
+
+	sections, err := c.store.History().GetSections(item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get sections: %w", err)
+	}
+	for i, section := range sections {
+		label := "Sections:"
+		if i > 0 {
+			label = ""
+		}
+		fmt.Printf("%-10s%d. %s (%s)\n", label, i+1, section.Name, formatSize(section.Size()))
+	}
+	return nil
+}
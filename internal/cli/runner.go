@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// ErrInterrupted is wrapped by errors for maintenance runs stopped by
+// Ctrl+C after finishing the item in progress
+var ErrInterrupted = errors.New("interrupted")
+
+// itemSource is where a maintenance run gets its items;
+// store.HistoryStore is one
+type itemSource interface {
+	Count() (int, error)
+	Iterate(opts store.IterOptions, fn func(*store.HistoryItem) (bool, error)) error
+}
+
+// runProgress is the state of a maintenance run after an item
+type runProgress struct {
+	Done    int                // items processed so far, this run
+	Total   int                // items to process, this run
+	Bytes   int64              // content bytes processed so far
+	Current *store.HistoryItem // the item just processed
+}
+
+// runSummary is what a maintenance run got through
+type runSummary struct {
+	Done  int   // items processed
+	Total int   // items the run would process, had it not stopped
+	Bytes int64 // content bytes processed
+	Next  uint  // the first item left unprocessed, when interrupted
+}
+
+// maintenanceRunner walks every item for a maintenance command, oldest
+// first, reporting progress and stopping cleanly when ctx is cancelled
+type maintenanceRunner struct {
+	source     itemSource
+	resumeFrom uint // skip the items before this ID; 0 starts at the oldest
+	jobs       int  // items runChecks checks at once; 0 or 1 checks one at a time
+
+	// progress is called after each item; nil reports nothing
+	progress func(runProgress)
+}
+
+// run calls fn with each item. A cancelled ctx stops the run before the
+// next item, returning ErrInterrupted with the summary naming that item.
+func (r *maintenanceRunner) run(ctx context.Context, fn func(*store.HistoryItem) error) (runSummary, error) {
+	return r.runChecks(ctx, func(item *store.HistoryItem) func() error {
+		return func() error { return fn(item) }
+	})
+}
+
+// runChecks is run for work that can be split in two: check, which runs on
+// up to r.jobs items at once, and the finish func it returns, which runs one
+// item at a time in item order. A cancelled ctx stops new checks and waits
+// for those already started to finish, so the summary's Next is still the
+// first item left unprocessed.
+func (r *maintenanceRunner) runChecks(ctx context.Context, check func(*store.HistoryItem) func() error) (runSummary, error) {
+	var summary runSummary
+	total, err := r.source.Count()
+	if err != nil {
+		return summary, fmt.Errorf("failed to count items: %w", err)
+	}
+	summary.Total = total
+
+	finished := func(item *store.HistoryItem) {
+		summary.Done++
+		summary.Bytes += item.Size
+		if r.progress != nil {
+			r.progress(runProgress{Done: summary.Done, Total: summary.Total, Bytes: summary.Bytes, Current: item})
+		}
+	}
+	var pool *checkPool
+	if r.jobs > 1 {
+		pool = newCheckPool(r.jobs, check, finished)
+	}
+
+	skipping := r.resumeFrom != 0
+	err = r.source.Iterate(store.IterOptions{Order: store.OrderOldest}, func(item *store.HistoryItem) (bool, error) {
+		if skipping {
+			if item.ID != r.resumeFrom {
+				summary.Total--
+				return false, nil
+			}
+			skipping = false
+		}
+		if ctx.Err() != nil {
+			summary.Next = item.ID
+			return true, nil
+		}
+
+		if pool != nil {
+			return !pool.add(item), nil
+		}
+		if err := check(item)(); err != nil {
+			return false, err
+		}
+		finished(item)
+		return false, nil
+	})
+	if pool != nil {
+		if poolErr := pool.wait(); err == nil {
+			err = poolErr
+		}
+	}
+	if err != nil {
+		return summary, err
+	}
+	if skipping {
+		return summary, fmt.Errorf("item %d to resume from no longer exists: %w", r.resumeFrom, ErrNotFound)
+	}
+	if summary.Next != 0 {
+		return summary, ErrInterrupted
+	}
+	return summary, nil
+}
+
+// checkedItem is an item on its way through a checkPool
+type checkedItem struct {
+	seq    int
+	item   *store.HistoryItem
+	finish func() error
+}
+
+// checkPool checks items on several goroutines and finishes them on one,
+// in the order they were added. At most twice as many items as there are
+// workers are checked or waiting to finish at any time.
+type checkPool struct {
+	check    func(*store.HistoryItem) func() error
+	finished func(*store.HistoryItem) // called after each item finishes
+
+	work    chan checkedItem
+	results chan checkedItem
+	slots   chan struct{} // one per item added and not yet finished
+	failed  chan struct{} // closed when a finish fails
+	workers sync.WaitGroup
+	done    chan struct{} // closed when every result is handled
+	next    int           // seq of the next item added
+	err     error         // the first finish error
+}
+
+// newCheckPool starts jobs workers running check
+func newCheckPool(jobs int, check func(*store.HistoryItem) func() error, finished func(*store.HistoryItem)) *checkPool {
+	p := &checkPool{
+		check:    check,
+		finished: finished,
+		work:     make(chan checkedItem, jobs),
+		results:  make(chan checkedItem, 2*jobs),
+		slots:    make(chan struct{}, 2*jobs),
+		failed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	p.workers.Add(jobs)
+	for range jobs {
+		go func() {
+			defer p.workers.Done()
+			for c := range p.work {
+				c.finish = p.check(c.item)
+				p.results <- c
+			}
+		}()
+	}
+	go p.collect()
+	return p
+}
+
+// add queues item once a slot is free, or returns false once a finish has
+// failed and no more items should be added
+func (p *checkPool) add(item *store.HistoryItem) bool {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.failed:
+		return false
+	}
+	p.work <- checkedItem{seq: p.next, item: item}
+	p.next++
+	return true
+}
+
+// collect finishes the checked items in order. After a failure the rest
+// are only drained.
+func (p *checkPool) collect() {
+	defer close(p.done)
+	pending := make(map[int]checkedItem)
+	next := 0
+	for c := range p.results {
+		pending[c.seq] = c
+		for c, ok := pending[next]; ok; c, ok = pending[next] {
+			delete(pending, next)
+			next++
+			if p.err == nil {
+				if err := c.finish(); err != nil {
+					p.err = err
+					close(p.failed)
+				} else {
+					p.finished(c.item)
+				}
+			}
+			<-p.slots
+		}
+	}
+}
+
+// wait stops the workers once the items added are checked and returns the
+// first finish error
+func (p *checkPool) wait() error {
+	close(p.work)
+	p.workers.Wait()
+	close(p.results)
+	<-p.done
+	return p.err
+}
+
+// progressLine formats p as "item 123/4096 (38%) · 1.2 GB processed · current: <title>"
+func progressLine(p runProgress) string {
+	percent := 100
+	if p.Total > 0 {
+		percent = p.Done * 100 / p.Total
+	}
+	return fmt.Sprintf("item %d/%d (%d%%) · %s processed · current: %s", p.Done, p.Total, percent, formatSize(p.Bytes), p.Current.Title)
+}
+
+// isTerminal reports whether f is a terminal rather than a file, a pipe, or
+// another device such as /dev/null
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(f.Fd())
+}
+
+// terminalProgress returns a progress callback that rewrites one line of w
+// in place, or nil when w is not a terminal
+func terminalProgress(w *os.File) func(runProgress) {
+	if !isTerminal(w) {
+		return nil
+	}
+	return func(p runProgress) {
+		// \r returns to the start of the line and \x1b[K clears the rest
+		fmt.Fprintf(w, "\r\x1b[K%s", progressLine(p))
+	}
+}
+
+// finishRun ends a progress line and reports an interrupted run on w
+func finishRun(w io.Writer, progress func(runProgress), summary runSummary, err error) {
+	if progress != nil && summary.Done > 0 {
+		fmt.Fprintln(w)
+	}
+	if errors.Is(err, ErrInterrupted) {
+		fmt.Fprintf(w, "Interrupted after %d of %d item(s) (%s); resume with --resume-from %d\n",
+			summary.Done, summary.Total, formatSize(summary.Bytes), summary.Next)
+	}
+}
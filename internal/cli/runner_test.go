@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// fakeSource serves fixed items, oldest first
+type fakeSource []*store.HistoryItem
+
+func (f fakeSource) Count() (int, error) { return len(f), nil }
+
+func (f fakeSource) Iterate(opts store.IterOptions, fn func(*store.HistoryItem) (bool, error)) error {
+	for _, item := range f {
+		stop, err := fn(item)
+		if err != nil || stop {
+			return err
+		}
+	}
+	return nil
+}
+
+func newFakeSource(n int) fakeSource {
+	var source fakeSource
+	for i := 1; i <= n; i++ {
+		source = append(source, &store.HistoryItem{ID: uint(i * 10), Title: fmt.Sprintf("item %d", i), Size: 1000})
+	}
+	return source
+}
+
+func (f fakeSource) ids() []uint {
+	var ids []uint
+	for _, item := range f {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestMaintenanceRunner_Progress(t *testing.T) {
+	var progress []runProgress
+	runner := &maintenanceRunner{source: newFakeSource(3), progress: func(p runProgress) { progress = append(progress, p) }}
+	var seen []uint
+	summary, err := runner.run(context.Background(), func(item *store.HistoryItem) error {
+		seen = append(seen, item.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if fmt.Sprint(seen) != "[10 20 30]" {
+		t.Errorf("expected every item in order, got %v", seen)
+	}
+	if summary.Done != 3 || summary.Total != 3 || summary.Bytes != 3000 || summary.Next != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected a progress callback per item, got %d", len(progress))
+	}
+	if got, want := progressLine(progress[1]), "item 2/3 (66%) · 2.0 KB processed · current: item 2"; got != want {
+		t.Errorf("progressLine = %q, want %q", got, want)
+	}
+}
+
+func TestMaintenanceRunner_Interrupt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := &maintenanceRunner{source: newFakeSource(4)}
+	var seen []uint
+	summary, err := runner.run(ctx, func(item *store.HistoryItem) error {
+		seen = append(seen, item.ID)
+		if item.ID == 20 {
+			// Ctrl+C while the second item is in progress
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrInterrupted) || ExitCode(err) != ExitInterrupted {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if fmt.Sprint(seen) != "[10 20]" {
+		t.Errorf("expected the current item to finish and no more, got %v", seen)
+	}
+	if summary.Done != 2 || summary.Next != 30 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	var buf strings.Builder
+	finishRun(&buf, nil, summary, err)
+	if out := buf.String(); !strings.Contains(out, "Interrupted after 2 of 4 item(s)") || !strings.Contains(out, "--resume-from 30") {
+		t.Errorf("expected an interrupted summary, got %q", out)
+	}
+}
+
+func TestMaintenanceRunner_ResumeFrom(t *testing.T) {
+	runner := &maintenanceRunner{source: newFakeSource(4), resumeFrom: 30}
+	var seen []uint
+	summary, err := runner.run(context.Background(), func(item *store.HistoryItem) error {
+		seen = append(seen, item.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if fmt.Sprint(seen) != "[30 40]" {
+		t.Errorf("expected items before 30 skipped, got %v", seen)
+	}
+	if summary.Done != 2 || summary.Total != 2 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	runner.resumeFrom = 35
+	if _, err := runner.run(context.Background(), func(*store.HistoryItem) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound resuming from a missing item, got %v", err)
+	}
+}
+
+func TestMaintenanceRunner_JobsKeepOrder(t *testing.T) {
+	var progress []uint
+	runner := &maintenanceRunner{source: newFakeSource(20), jobs: 4, progress: func(p runProgress) { progress = append(progress, p.Current.ID) }}
+	var finished []uint
+	summary, err := runner.runChecks(context.Background(), func(item *store.HistoryItem) func() error {
+		// Later items check faster, so they're ready before earlier ones
+		time.Sleep(time.Duration(200-item.ID) * 10 * time.Microsecond)
+		return func() error {
+			finished = append(finished, item.ID)
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("runChecks failed: %v", err)
+	}
+	want := fmt.Sprint(newFakeSource(20).ids())
+	if fmt.Sprint(finished) != want || fmt.Sprint(progress) != want {
+		t.Errorf("expected items finished and reported in order, got %v and %v", finished, progress)
+	}
+	if summary.Done != 20 || summary.Bytes != 20000 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+}
+
+func TestMaintenanceRunner_JobsInterrupt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := &maintenanceRunner{source: newFakeSource(50), jobs: 2}
+	var finished []uint
+	summary, err := runner.runChecks(ctx, func(item *store.HistoryItem) func() error {
+		return func() error {
+			finished = append(finished, item.ID)
+			if item.ID == 20 {
+				cancel()
+			}
+			return nil
+		}
+	})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	// The items already being checked finish, and the run resumes after them
+	if fmt.Sprint(finished) != fmt.Sprint(newFakeSource(len(finished)).ids()) {
+		t.Errorf("expected a prefix of the items, got %v", finished)
+	}
+	if summary.Done != len(finished) || summary.Next != finished[len(finished)-1]+10 {
+		t.Errorf("summary %+v doesn't follow the %d finished item(s)", summary, len(finished))
+	}
+}
+
+func TestMaintenanceRunner_JobsError(t *testing.T) {
+	runner := &maintenanceRunner{source: newFakeSource(50), jobs: 4}
+	var finished []uint
+	_, err := runner.runChecks(context.Background(), func(item *store.HistoryItem) func() error {
+		return func() error {
+			if item.ID == 30 {
+				return errors.New("disk full")
+			}
+			finished = append(finished, item.ID)
+			return nil
+		}
+	})
+	if err == nil || err.Error() != "disk full" {
+		t.Fatalf("expected the finish error, got %v", err)
+	}
+	if fmt.Sprint(finished) != "[10 20]" {
+		t.Errorf("expected nothing finished after the failure, got %v", finished)
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/yiblet/rem/internal/tui"
+)
+
+// parseSearchHistory decodes the search_history config value
+func parseSearchHistory(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var patterns []string
+	if err := json.Unmarshal([]byte(value), &patterns); err != nil {
+		return nil, fmt.Errorf("search_history must be a JSON array of strings: %w", err)
+	}
+	return patterns, nil
+}
+
+// saveSearchHistory stores the TUI's search patterns in search_history
+func (c *CLI) saveSearchHistory(patterns []string) error {
+	if len(patterns) > tui.MaxSearchHistory {
+		patterns = patterns[len(patterns)-tui.MaxSearchHistory:]
+	}
+	if patterns == nil {
+		patterns = []string{}
+	}
+	data, err := json.Marshal(patterns)
+	if err != nil {
+		return fmt.Errorf("failed to encode search history: %w", err)
+	}
+	if err := c.store.Config().Set("search_history", string(data)); err != nil {
+		return fmt.Errorf("failed to save search history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// maxMarkerLine is how much of a line a section marker is matched against
+const maxMarkerLine = 64 * 1024
+
+// sectionStart is where a line matching the section marker begins
+type sectionStart struct {
+	name   string
+	offset int64
+}
+
+// sectionScanner passes stored content through, recording the offset of
+// each line the marker matches, so the content is stored as one item whose
+// sections can still be read back separately
+type sectionScanner struct {
+	r         io.Reader
+	marker    *regexp.Regexp
+	offset    int64  // bytes read so far
+	lineStart int64  // offset of the current line
+	line      []byte // the current line, up to maxMarkerLine bytes
+	starts    []sectionStart
+}
+
+// withSections wraps content in a sectionScanner for marker, or returns it
+// as is with a nil scanner when there is no marker
+func withSections(content io.Reader, marker *regexp.Regexp) (io.Reader, *sectionScanner) {
+	if marker == nil {
+		return content, nil
+	}
+	scanner := &sectionScanner{r: content, marker: marker}
+	return scanner, scanner
+}
+
+func (s *sectionScanner) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.scan(p[:n])
+	return n, err
+}
+
+// scan splits data into lines, matching the marker at the end of each
+func (s *sectionScanner) scan(data []byte) {
+	for len(data) > 0 {
+		end, complete := len(data), false
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			end, complete = i+1, true
+		}
+		if room := maxMarkerLine - len(s.line); room > 0 {
+			s.line = append(s.line, data[:min(end, room)]...)
+		}
+		s.offset += int64(end)
+		data = data[end:]
+		if complete {
+			s.endLine()
+		}
+	}
+}
+
+// endLine matches the marker against the line just read
+func (s *sectionScanner) endLine() {
+	line := bytes.TrimSuffix(bytes.TrimSuffix(s.line, []byte("\n")), []byte("\r"))
+	if match := s.marker.FindSubmatch(line); match != nil {
+		start := sectionStart{offset: s.lineStart}
+		if len(match) > 1 {
+			start.name = string(match[1])
+		}
+		s.starts = append(s.starts, start)
+	}
+	s.line = s.line[:0]
+	s.lineStart = s.offset
+}
+
+// sections returns the sections of the size bytes of content read. Each
+// runs from its marker line to the next marker line or the end, and is
+// named by the marker's first capture group or else its 1-based number.
+// Content before the first marker belongs to no section.
+func (s *sectionScanner) sections(size int64) ([]store.Section, error) {
+	if len(s.line) > 0 {
+		s.endLine()
+	}
+	if s.offset != size {
+		return nil, fmt.Errorf("read %d bytes of content but %d were stored", s.offset, size)
+	}
+	sections := make([]store.Section, 0, len(s.starts))
+	for i, start := range s.starts {
+		end := size
+		if i+1 < len(s.starts) {
+			end = s.starts[i+1].offset
+		}
+		name := start.name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		sections = append(sections, store.Section{Name: name, Start: start.offset, End: end})
+	}
+	return sections, nil
+}
+
+// saveSections records the sections scanner found on item; a nil scanner
+// records nothing
+func (c *CLI) saveSections(item *store.HistoryItem, scanner *sectionScanner) error {
+	if scanner == nil {
+		return nil
+	}
+	sections, err := scanner.sections(item.Size)
+	if err != nil {
+		return fmt.Errorf("failed to find sections: %w", err)
+	}
+	if err := c.store.History().SetSections(item.ID, sections); err != nil {
+		return fmt.Errorf("failed to save sections: %w", err)
+	}
+	return nil
+}
+
+// findSection returns the section of item named name, or at that 1-based
+// number
+func (c *CLI) findSection(item *store.HistoryItem, name string) (store.Section, error) {
+	sections, err := c.store.History().GetSections(item.ID)
+	if err != nil {
+		return store.Section{}, fmt.Errorf("failed to get sections: %w", err)
+	}
+	if len(sections) == 0 {
+		return store.Section{}, fmt.Errorf("item %d has no sections (store it with --section-marker)", item.ID)
+	}
+	section, ok := store.FindSection(sections, name)
+	if !ok {
+		names := make([]string, len(sections))
+		for i, section := range sections {
+			names[i] = section.Name
+		}
+		return store.Section{}, fmt.Errorf("item %d has no section %q (sections: %s)", item.ID, name, strings.Join(names, ", "))
+	}
+	return section, nil
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+func TestSectionScanner(t *testing.T) {
+	content := "preamble\n# one\nalpha\n# two\r\nbeta\n# three"
+	r, scanner := withSections(iotest.OneByteReader(strings.NewReader(content)), regexp.MustCompile(`^# (\w+)$`))
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		t.Fatal(err)
+	}
+	got, err := scanner.sections(int64(len(content)))
+	if err != nil {
+		t.Fatalf("sections() error = %v", err)
+	}
+	want := []store.Section{{Name: "one", Start: 9, End: 21}, {Name: "two", Start: 21, End: 33}, {Name: "three", Start: 33, End: 40}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("sections() = %v, want %v", got, want)
+	}
+
+	if _, err := scanner.sections(int64(len(content) + 1)); err == nil {
+		t.Error("Expected a size mismatch to fail")
+	}
+}
+
+func TestStoreAndGetSections(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Each section is larger than a chunk, so every boundary falls mid-chunk
+	names := []string{"a.conf", "b.conf", "c.conf"}
+	var content strings.Builder
+	content.WriteString("preamble\n")
+	want := map[string]string{}
+	for i, name := range names {
+		var section strings.Builder
+		fmt.Fprintf(&section, "==> %s <==\n", name)
+		for line := 0; section.Len() < 50*1024+i*7919; line++ {
+			fmt.Fprintf(&section, "%s line %d\n", name, line)
+		}
+		want[name] = section.String()
+		content.WriteString(section.String())
+	}
+
+	withStdin(t, content.String(), func() {
+		withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{SectionMarker: stringPtr("^==> (.*) <==$")}); err != nil {
+				t.Fatalf("executeStore() error = %v", err)
+			}
+		})
+	})
+
+	for i, name := range names {
+		for _, ref := range []string{name, fmt.Sprint(i + 1)} {
+			output := withStdout(t, func() {
+				if err := cli.executeGet(&GetCmd{Index: indexArg(0), Section: stringPtr(ref)}); err != nil {
+					t.Fatalf("get --section %s error = %v", ref, err)
+				}
+			})
+			if output != want[name] {
+				t.Errorf("get --section %s returned %d bytes, want the %d of %s", ref, len(output), len(want[name]), name)
+			}
+		}
+	}
+
+	err = cli.executeGet(&GetCmd{Index: indexArg(0), Section: stringPtr("d.conf")})
+	if err == nil || !strings.Contains(err.Error(), "a.conf, b.conf, c.conf") {
+		t.Errorf("missing section error = %v, want it to list the sections", err)
+	}
+
+	output := withStdout(t, func() { cli.executeInfo(&InfoCmd{Index: indexArg(0)}) })
+	if !strings.Contains(output, "Sections: 1. a.conf (") || !strings.Contains(output, "          3. c.conf (") {
+		t.Errorf("info doesn't list the sections:\n%s", output)
+	}
+}
+
+func TestStoreSections_Filtered(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Offsets are into the stored, converted content, and unnamed markers
+	// are numbered
+	withStdin(t, "----\r\nfirst\r\n----\r\nsecond\r\n", func() {
+		withStdout(t, func() {
+			cmd := &StoreCmd{SectionMarker: stringPtr("^-+$"), Filters: []string{"dos2unix"}}
+			if err := cli.executeStore(cmd); err != nil {
+				t.Fatalf("executeStore() error = %v", err)
+			}
+		})
+	})
+	output := withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), Section: stringPtr("2")}); err != nil {
+			t.Fatalf("get --section 2 error = %v", err)
+		}
+	})
+	if output != "----\nsecond\n" {
+		t.Errorf("get --section 2 = %q", output)
+	}
+
+	// Items stored without a marker have no sections
+	cli.queueManager.Enqueue(strings.NewReader("plain"), "plain")
+	if err := cli.executeGet(&GetCmd{Index: indexArg(0), Section: stringPtr("1")}); err == nil || !strings.Contains(err.Error(), "no sections") {
+		t.Errorf("get --section of an unsectioned item error = %v", err)
+	}
+}
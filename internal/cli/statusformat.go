@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// statusData is what a --format template is rendered with
+type statusData struct {
+	ID        uint
+	Index     int
+	Title     string
+	Size      int64
+	SHA256    string
+	Timestamp time.Time
+}
+
+// parseStatusFormat parses a --format template and renders it once with
+// sample data, so mistakes such as unknown fields are reported before
+// anything is stored or written
+func parseStatusFormat(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, statusData{Timestamp: time.Now()}); err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// statusLine prints the confirmation a command gives for an item it stored
+// or wrote somewhere other than stdout. Content is never printed through it.
+type statusLine struct {
+	w     io.Writer
+	tmpl  *template.Template // renders the line instead of the default; nil keeps it
+	quiet bool               // print nothing
+}
+
+// newStatusLine returns the statusLine for format and quiet, writing to w
+func newStatusLine(w io.Writer, format *string, quiet bool) (statusLine, error) {
+	status := statusLine{w: w, quiet: quiet}
+	if format != nil && !quiet {
+		tmpl, err := parseStatusFormat(*format)
+		if err != nil {
+			return statusLine{}, err
+		}
+		status.tmpl = tmpl
+	}
+	return status, nil
+}
+
+// custom reports whether the default lines are replaced or left out, so
+// lines about no one item, such as a summary, should be too
+func (s statusLine) custom() bool {
+	return s.quiet || s.tmpl != nil
+}
+
+// print prints the confirmation for item at index: the default line
+// formatted from format and args, or the --format template's, ending in a
+// newline
+func (s statusLine) print(item *store.HistoryItem, index int, format string, args ...any) error {
+	switch {
+	case s.quiet:
+		return nil
+	case s.tmpl == nil:
+		_, err := fmt.Fprintf(s.w, format, args...)
+		return err
+	}
+
+	var line strings.Builder
+	data := statusData{ID: item.ID, Index: index, Title: item.Title, Size: item.Size, SHA256: item.SHA256, Timestamp: item.Timestamp}
+	if err := s.tmpl.Execute(&line, data); err != nil {
+		return fmt.Errorf("failed to render --format: %w", err)
+	}
+	if !strings.HasSuffix(line.String(), "\n") {
+		line.WriteString("\n")
+	}
+	_, err := io.WriteString(s.w, line.String())
+	return err
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+)
+
+func TestStatusFormat_Store(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Just the ID, for capture in a shell variable
+	var output string
+	withStdin(t, "hello\n", func() {
+		output = withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{Format: stringPtr("{{.ID}}")}); err != nil {
+				t.Fatalf("executeStore() error = %v", err)
+			}
+		})
+	})
+	item, err := cli.queueManager.Get(0)
+	if err != nil {
+		t.Fatalf("Failed to get the stored item: %v", err)
+	}
+	if want := fmt.Sprintf("%d\n", item.ID); output != want {
+		t.Errorf("store --format '{{.ID}}' printed %q, want %q", output, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("some notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output = withStdout(t, func() {
+		cmd := &StoreCmd{Files: []string{file}, Title: stringPtr("notes"), Format: stringPtr("{{.Index}} {{.Title}} {{.Size}} {{len .SHA256}}\n")}
+		if err := cli.executeStore(cmd); err != nil {
+			t.Fatalf("executeStore() error = %v", err)
+		}
+	})
+	if output != "0 notes 10 64\n" {
+		t.Errorf("store --format printed %q", output)
+	}
+
+	// --quiet prints nothing but still stores
+	withStdin(t, "quiet\n", func() {
+		output = withStdout(t, func() {
+			if err := cli.executeStore(&StoreCmd{Quiet: true}); err != nil {
+				t.Fatalf("executeStore() error = %v", err)
+			}
+		})
+	})
+	if output != "" {
+		t.Errorf("store --quiet printed %q", output)
+	}
+	if item, _ := cli.queueManager.Get(0); item == nil || item.Size != 6 {
+		t.Errorf("store --quiet stored %v, want the 6 bytes of input", item)
+	}
+}
+
+func TestStatusFormat_Invalid(t *testing.T) {
+	for _, format := range []string{"{{.ID", "{{.Name}}"} {
+		if err := (&StoreCmd{Format: stringPtr(format)}).Validate(); err == nil || !strings.Contains(err.Error(), "invalid --format template") {
+			t.Errorf("store --format %q: Validate() error = %v", format, err)
+		}
+		if err := (&GetCmd{Index: indexArg(0), Format: stringPtr(format)}).Validate(); err == nil || !strings.Contains(err.Error(), "invalid --format template") {
+			t.Errorf("get --format %q: Validate() error = %v", format, err)
+		}
+	}
+	if err := (&StoreCmd{DryRun: true, Quiet: true}).Validate(); err == nil {
+		t.Error("Expected --dry-run with --quiet to be rejected")
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// Nothing is stored or written when the template is bad
+	withStdin(t, "content\n", func() {
+		if err := cli.executeStore(&StoreCmd{Format: stringPtr("{{.Name}}")}); err == nil {
+			t.Error("Expected store with a bad --format to fail")
+		}
+	})
+	if count, _ := cli.store.History().Count(); count != 0 {
+		t.Errorf("stored %d items with a bad --format", count)
+	}
+
+	cli.queueManager.Enqueue(strings.NewReader("content"), "item")
+	out := filepath.Join(t.TempDir(), "out.txt")
+	output := withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), File: &out, Format: stringPtr("{{.Name}}")}); err == nil {
+			t.Error("Expected get with a bad --format to fail")
+		}
+	})
+	if _, err := os.Stat(out); !os.IsNotExist(err) || output != "" {
+		t.Errorf("get with a bad --format wrote %s (stat error %v) and printed %q", out, err, output)
+	}
+}
+
+func TestStatusFormat_Get(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	mock := mockboard.New()
+	cli.clipboard = mock
+
+	first, _ := cli.queueManager.Enqueue(strings.NewReader("first"), "first")
+	cli.queueManager.Enqueue(strings.NewReader("second"), "second")
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	output := withStdout(t, func() {
+		cmd := &GetCmd{Index: indexArg(1), File: &out, Format: stringPtr(`{{.ID}} {{.Index}} {{.Title}} {{.Timestamp.Format "2006"}}`)}
+		if err := cli.executeGet(cmd); err != nil {
+			t.Fatalf("executeGet() error = %v", err)
+		}
+	})
+	if want := fmt.Sprintf("%d 1 first %d\n", first.ID, first.Timestamp.Year()); output != want {
+		t.Errorf("get --format printed %q, want %q", output, want)
+	}
+
+	output = withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), Clipboard: true, Quiet: true}); err != nil {
+			t.Fatalf("executeGet() error = %v", err)
+		}
+	})
+	if output != "" || string(mock.GetData()) != "second" {
+		t.Errorf("get -c --quiet printed %q and copied %q", output, mock.GetData())
+	}
+
+	// Content written to stdout is never formatted
+	output = withStdout(t, func() {
+		if err := cli.executeGet(&GetCmd{Index: indexArg(0), Format: stringPtr("{{.ID}}")}); err != nil {
+			t.Fatalf("executeGet() error = %v", err)
+		}
+	})
+	if output != "second" {
+		t.Errorf("get --format to stdout printed %q, want the content", output)
+	}
+}
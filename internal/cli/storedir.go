@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/yiblet/rem/internal/filter"
+)
+
+// defaultMaxFileSize is the largest file 'rem store --recursive' stores
+// when --max-file-size is not given
+const defaultMaxFileSize = 10 * 1000 * 1000
+
+// dirStore walks a directory for 'rem store --recursive', storing each file
+// as its own item
+type dirStore struct {
+	c           *CLI
+	cmd         *StoreCmd
+	filters     []filter.Filter
+	status      statusLine
+	maxFileSize int64
+	visited     []os.FileInfo // directories on the current path, to detect symlink loops
+
+	stored, skipped, failed int
+}
+
+// executeStoreRecursive handles 'rem store --recursive'. Every file is
+// streamed into its own item titled with its path relative to the
+// directory given; files that can't be read are reported and skipped.
+func (c *CLI) executeStoreRecursive(cmd *StoreCmd, filters []filter.Filter, status statusLine) error {
+	maxFileSize := int64(defaultMaxFileSize)
+	if cmd.MaxFileSize != nil {
+		size, err := parseSize(*cmd.MaxFileSize)
+		if err != nil {
+			return fmt.Errorf("invalid --max-file-size: %w", err)
+		}
+		maxFileSize = size
+	}
+
+	d := &dirStore{c: c, cmd: cmd, filters: filters, status: status, maxFileSize: maxFileSize}
+	for _, root := range cmd.Files {
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			if err := d.storeFile(root, filepath.Base(root), info); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.walk(root, "", info); err != nil {
+			return err
+		}
+	}
+
+	// A --format line per file is all a script parsing them should get
+	if !status.custom() {
+		fmt.Printf("Stored %d file(s), skipped %d, failed %d\n", d.stored, d.skipped, d.failed)
+	}
+	if d.failed > 0 {
+		return fmt.Errorf("%d file(s) could not be read", d.failed)
+	}
+	return nil
+}
+
+// walk stores the files under dir, whose path relative to the root is rel.
+// Only errors storing an item stop the walk.
+func (d *dirStore) walk(dir, rel string, info os.FileInfo) error {
+	for _, seen := range d.visited {
+		if os.SameFile(seen, info) {
+			d.skip(dir, "symlink loop")
+			return nil
+		}
+	}
+	d.visited = append(d.visited, info)
+	defer func() { d.visited = d.visited[:len(d.visited)-1] }()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		d.fail(dir, err)
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		entryRel := filepath.ToSlash(filepath.Join(rel, entry.Name()))
+
+		info, err := entry.Info()
+		if err != nil {
+			d.fail(path, err)
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !d.cmd.FollowSymlinks {
+				d.skip(path, "symlink")
+				continue
+			}
+			if info, err = os.Stat(path); err != nil {
+				d.fail(path, err)
+				continue
+			}
+		}
+
+		if matchesAny(d.cmd.Exclude, entryRel) {
+			continue
+		}
+		if info.IsDir() {
+			if err := d.walk(path, entryRel, info); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			d.skip(path, "not a regular file")
+			continue
+		}
+		if len(d.cmd.Include) > 0 && !matchesAny(d.cmd.Include, entryRel) {
+			continue
+		}
+		if err := d.storeFile(path, entryRel, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeFile streams one file into a new item titled title
+func (d *dirStore) storeFile(path, title string, info os.FileInfo) error {
+	if d.maxFileSize > 0 && info.Size() > d.maxFileSize {
+		d.skip(path, fmt.Sprintf("%d bytes, larger than --max-file-size", info.Size()))
+		return nil
+	}
+	file, err := d.c.readFromFile(path)
+	if err != nil {
+		d.fail(path, err)
+		return nil
+	}
+	defer file.Close()
+
+	result, err := d.c.queueManager.EnqueueFileWithResult(filter.Chain(file, d.filters), title, info)
+	if err != nil {
+		return fmt.Errorf("failed to store content from %s: %w", path, withStoreHint(err))
+	}
+	d.stored++
+	return d.status.print(result.Item, 0, "Stored from %s: %s%s\n", path, result.Item.Title, evictionNote(result.Evicted))
+}
+
+// skip reports a file left out on purpose
+func (d *dirStore) skip(path, reason string) {
+	fmt.Fprintf(os.Stderr, "Skipped %s (%s)\n", path, reason)
+	d.skipped++
+}
+
+// fail reports a file or directory that could not be read
+func (d *dirStore) fail(path string, err error) {
+	fmt.Fprintf(os.Stderr, "Failed to read %s: %v\n", path, err)
+	d.failed++
+}
+
+// matchesAny reports whether a glob in patterns matches the relative path
+// or its base name
+func matchesAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/dbstore"
+	"github.com/yiblet/rem/internal/store/memstore"
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// Policies for content sync finds already in the destination under another
+// title, note, or timestamp
+const (
+	duplicateKeep    = "keep"    // leave the destination's item alone
+	duplicateReplace = "replace" // take the incoming title, note, and timestamp
+	duplicateNewest  = "newest"  // replace when the incoming item is newer
+	duplicateAsk     = "ask"     // prompt for each conflict
+)
+
+// executeSync handles the 'rem sync' command. Items are copied oldest first
+// with their original title and timestamp; items whose content already
+// exists in the destination are skipped, or with --on-duplicate update the
+// destination's item, so repeated syncs are idempotent.
+func (c *CLI) executeSync(cmd *SyncCmd) error {
+	if cmd.OnDuplicate == duplicateAsk && !isTerminal(os.Stdin) {
+		return fmt.Errorf("--on-duplicate ask needs a terminal to prompt on; use keep, replace, or newest")
+	}
+	resolver := &duplicateResolver{policy: cmd.OnDuplicate, in: bufio.NewReader(os.Stdin), out: os.Stderr, loc: c.timeLocation()}
+	if resolver.policy == "" {
+		resolver.policy = duplicateKeep
+	}
+	return c.syncWith(cmd, resolver)
+}
+
+// syncWith is executeSync with conflicts between duplicates settled by resolver
+func (c *CLI) syncWith(cmd *SyncCmd, resolver *duplicateResolver) error {
+	other, err := openSyncTarget(cmd)
+	if err != nil {
+		return err
+	}
+	defer other.Close()
+
+	src, dst := c.store, other
+	if cmd.From != nil {
+		src, dst = dst, src
+	}
+
+	cutoff, _, err := parseTimeWindow(cmd.Since, nil, time.Now())
+	if err != nil {
+		return err
+	}
+	matches, err := cmd.matcher()
+	if err != nil {
+		return err
+	}
+
+	// A dry run writes nothing, so it remembers what the destination would
+	// hold for content it plans to copy or update, as a real run finds it
+	planned := map[string]*store.HistoryItem{}
+
+	copied, updated, skipped := 0, 0, 0
+	// Copy oldest first to preserve order
+	err = src.History().Iterate(store.IterOptions{Order: store.OrderOldest}, func(item *store.HistoryItem) (bool, error) {
+		if item.Timestamp.Before(cutoff) || !matches(item) {
+			return false, nil
+		}
+
+		existing, err := dst.History().FindBySHA256(item.SHA256)
+		if err != nil {
+			return false, fmt.Errorf("failed to check destination: %w", err)
+		}
+		if plan, ok := planned[item.SHA256]; ok {
+			existing = []*store.HistoryItem{plan}
+		}
+		if len(existing) > 0 {
+			replace, err := resolver.replace(existing[0], item)
+			if err != nil || !replace {
+				skipped++
+				return false, err
+			}
+			updated++
+			if cmd.DryRun {
+				fmt.Printf("Would update: %s\n", existing[0].Title)
+				update := *existing[0]
+				update.Title, update.Note, update.Timestamp = item.Title, item.Note, item.Timestamp
+				planned[item.SHA256] = &update
+				return false, nil
+			}
+			if err := dst.History().UpdateMetadata(existing[0].ID, item.Title, item.Note, item.Timestamp); err != nil {
+				return false, fmt.Errorf("failed to update item %q: %w", existing[0].Title, err)
+			}
+			return false, nil
+		}
+
+		if cmd.DryRun {
+			fmt.Printf("Would copy: %s\n", item.Title)
+			planned[item.SHA256] = item
+			copied++
+			return false, nil
+		}
+
+		if err := copyItem(src, dst, item); err != nil {
+			return false, err
+		}
+		copied++
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	updates := ""
+	if updated > 0 {
+		updates = fmt.Sprintf(", updated %d", updated)
+	}
+	if cmd.DryRun {
+		fmt.Printf("Would copy %d item(s)%s, skipped %d already present\n", copied, updates, skipped)
+	} else {
+		fmt.Printf("Copied %d item(s)%s, skipped %d already present\n", copied, updates, skipped)
+	}
+	return nil
+}
+
+// duplicateResolver decides, following an --on-duplicate policy, whether an
+// incoming item replaces the metadata of the destination's item with the
+// same content
+type duplicateResolver struct {
+	policy string
+	in     *bufio.Reader  // answers to ask's prompts
+	out    io.Writer      // where ask prompts
+	loc    *time.Location // zone ask shows times in; nil is the local zone
+	all    string         // "y" or "n" once answered for every later conflict
+}
+
+// replace reports whether incoming's title, note, and timestamp should
+// replace existing's. Items that already agree are never replaced.
+func (r *duplicateResolver) replace(existing, incoming *store.HistoryItem) (bool, error) {
+	if existing.Title == incoming.Title && existing.Note == incoming.Note && existing.Timestamp.Equal(incoming.Timestamp) {
+		return false, nil
+	}
+	switch r.policy {
+	case duplicateReplace:
+		return true, nil
+	case duplicateNewest:
+		return incoming.Timestamp.After(existing.Timestamp), nil
+	case duplicateAsk:
+		return r.ask(existing, incoming)
+	}
+	return false, nil
+}
+
+// ask prompts for one conflict: y replaces, n keeps, and a or s replace or
+// keep this and every later conflict without asking again
+func (r *duplicateResolver) ask(existing, incoming *store.HistoryItem) (bool, error) {
+	if r.all != "" {
+		return r.all == "y", nil
+	}
+	fmt.Fprintf(r.out, "Same content under another title, note, or timestamp:\n")
+	fmt.Fprintf(r.out, "  destination: %s  %s\n", timefmt.Format(existing.Timestamp, r.loc, timefmt.Second), describeDuplicate(existing))
+	fmt.Fprintf(r.out, "  incoming:    %s  %s\n", timefmt.Format(incoming.Timestamp, r.loc, timefmt.Second), describeDuplicate(incoming))
+	for {
+		fmt.Fprint(r.out, "Replace with incoming? [y]es, [n]o, [a]ll, [s]kip all: ")
+		answer, err := readAnswer(r.in)
+		if err != nil {
+			return false, err
+		}
+		switch answer {
+		case "y", "yes":
+			return true, nil
+		case "n", "no", "":
+			return false, nil
+		case "a", "all":
+			r.all = "y"
+			return true, nil
+		case "s", "skip":
+			r.all = "n"
+			return false, nil
+		}
+	}
+}
+
+// describeDuplicate is an item's title, and its note if it has one, for
+// ask's prompt
+func describeDuplicate(item *store.HistoryItem) string {
+	if item.Note == "" {
+		return fmt.Sprintf("%q", item.Title)
+	}
+	return fmt.Sprintf("%q (note: %q)", item.Title, item.Note)
+}
+
+// openSyncTarget opens the other database of a sync. A missing --to database
+// is created, or stood in for by an empty store on a dry run.
+func openSyncTarget(cmd *SyncCmd) (store.Store, error) {
+	path := cmd.From
+	if cmd.To != nil {
+		path = cmd.To
+	}
+
+	if _, err := os.Stat(*path); os.IsNotExist(err) {
+		if cmd.From != nil {
+			return nil, fmt.Errorf("database %s does not exist", *path)
+		}
+		if cmd.DryRun {
+			return memstore.NewMemoryStore(), nil
+		}
+		if err := os.MkdirAll(filepath.Dir(*path), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	other, err := dbstore.NewSQLiteStore(*path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", *path, err)
+	}
+	return other, nil
+}
+
+// copyItem streams a single item's content from src into dst
+func copyItem(src, dst store.Store, item *store.HistoryItem) error {
+	reader, err := src.History().GetContent(item.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read item %q: %w", item.Title, err)
+	}
+	defer reader.Close()
+
+	copied, err := dst.History().Create(&store.CreateHistoryInput{
+		Title:     item.Title,
+		Content:   reader,
+		Timestamp: item.Timestamp,
+
+		OriginalName:    item.OriginalName,
+		OriginalModTime: item.OriginalModTime,
+		OriginalMode:    item.OriginalMode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy item %q: %w", item.Title, err)
+	}
+	if item.Note != "" {
+		if err := dst.History().UpdateNote(copied.ID, item.Note); err != nil {
+			return fmt.Errorf("failed to copy note of item %q: %w", item.Title, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// tailMaxFailures is how many polls in a row may fail, such as while a
+// writer holds the database locked past the busy timeout, before 'rem tail'
+// gives up
+const tailMaxFailures = 5
+
+// tailDelimiter ends each item's content with --content
+const tailDelimiter = "---"
+
+// executeTail handles the 'rem tail' command, printing items as they are
+// stored until Ctrl+C
+func (c *CLI) executeTail(cmd *TailCmd) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	watcher, err := store.NewWatcher(c.store.History())
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	return c.tail(ctx, cmd, watcher, os.Stdout, os.Stderr)
+}
+
+// tail prints the items watcher finds to w until ctx is done. Failed polls
+// are retried at the next interval, with a warning to errw.
+func (c *CLI) tail(ctx context.Context, cmd *TailCmd, watcher *store.Watcher, w, errw io.Writer) error {
+	interval, err := cmd.interval()
+	if err != nil {
+		return err
+	}
+	re, err := cmd.pattern()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		items, err := watcher.Poll()
+		if err != nil {
+			failures++
+			if failures >= tailMaxFailures {
+				return fmt.Errorf("failed to read history: %w", err)
+			}
+			fmt.Fprintf(errw, "Warning: %v (retrying)\n", err)
+			continue
+		}
+		failures = 0
+		for _, item := range items {
+			if err := c.printTailItem(w, item, re, cmd.Content); err != nil {
+				fmt.Fprintf(errw, "Warning: item %d: %v\n", item.ID, err)
+			}
+		}
+	}
+}
+
+// printTailItem prints item's summary line, and its content if asked, unless
+// re is set and matches neither its title nor its content
+func (c *CLI) printTailItem(w io.Writer, item *store.HistoryItem, re *regexp.Regexp, content bool) error {
+	matched := re == nil || re.MatchString(item.Title)
+	var reader io.ReadSeekCloser
+	if !matched || content {
+		var err error
+		if reader, err = c.store.History().GetContent(item.ID); err != nil {
+			return err
+		}
+		defer reader.Close()
+	}
+	if !matched {
+		if !re.MatchReader(bufio.NewReader(reader)) {
+			return nil
+		}
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "%s\t%s\t%s\n", c.formatTime(item.Timestamp, timefmt.Second), formatSize(item.Size), item.Title)
+	if !content {
+		return nil
+	}
+	if item.IsBinary {
+		fmt.Fprintln(w, "(binary content not shown)")
+	} else {
+		last, err := copyTrackingLast(w, reader)
+		if err != nil {
+			return err
+		}
+		if last != '\n' && item.Size > 0 {
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintln(w, tailDelimiter)
+	return nil
+}
+
+// copyTrackingLast copies r to w and returns the last byte copied
+func copyTrackingLast(w io.Writer, r io.Reader) (byte, error) {
+	buf := make([]byte, 32*1024)
+	var last byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			last = buf[n-1]
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return last, werr
+			}
+		}
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return last, err
+		}
+	}
+}
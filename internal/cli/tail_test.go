@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/memstore"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from one goroutine while
+// another reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until cond holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// flakyHistory fails List and ListAfterID while failing is set
+type flakyHistory struct {
+	store.HistoryStore
+	mu      sync.Mutex
+	failing bool
+}
+
+func (h *flakyHistory) setFailing(failing bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failing = failing
+}
+
+func (h *flakyHistory) List(limit int) ([]*store.HistoryItem, error) {
+	h.mu.Lock()
+	failing := h.failing
+	h.mu.Unlock()
+	if failing {
+		return nil, errors.New("database is locked")
+	}
+	return h.HistoryStore.List(limit)
+}
+
+func (h *flakyHistory) ListAfterID(id uint) ([]*store.HistoryItem, error) {
+	h.mu.Lock()
+	failing := h.failing
+	h.mu.Unlock()
+	if failing {
+		return nil, errors.New("database is locked")
+	}
+	return h.HistoryStore.ListAfterID(id)
+}
+
+// flakyStore is a memory store whose history is a flakyHistory
+type flakyStore struct {
+	*memstore.MemoryStore
+	history *flakyHistory
+}
+
+func (s *flakyStore) History() store.HistoryStore { return s.history }
+
+func TestTail(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+	cli.queueManager.Enqueue(strings.NewReader("stored before tail started"), "")
+
+	// Another process stores while tail follows
+	writer, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer writer.store.Close()
+
+	interval, grep := "10ms", "keep"
+	var out, errOut syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	watcher, err := store.NewWatcher(cli.store.History())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		done <- cli.tail(ctx, &TailCmd{Interval: &interval, Grep: &grep, Content: true}, watcher, &out, &errOut)
+	}()
+
+	go func() {
+		for _, content := range []string{"keep: first\nsecond line\n", "skip this one", "title\nkeep in the body"} {
+			writer.queueManager.Enqueue(strings.NewReader(content), "")
+		}
+	}()
+	waitFor(t, "the tailed items", func() bool { return strings.Count(out.String(), tailDelimiter+"\n") == 2 })
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("tail returned %v after being interrupted", err)
+	}
+
+	output := out.String()
+	if strings.Contains(output, "before tail") || strings.Contains(output, "skip this") {
+		t.Errorf("expected only new matching items, got:\n%s", output)
+	}
+	for _, want := range []string{"\tkeep: first\nkeep: first\nsecond line\n---\n", "\ttitle\ntitle\nkeep in the body\n---\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "keep: first") > strings.Index(output, "keep in the body") {
+		t.Errorf("expected items in the order stored:\n%s", output)
+	}
+}
+
+func TestTail_Failures(t *testing.T) {
+	mem := memstore.NewMemoryStore()
+	defer mem.Close()
+	history := &flakyHistory{HistoryStore: mem.History()}
+	cli := &CLI{store: &flakyStore{MemoryStore: mem, history: history}}
+	interval := "5ms"
+
+	// A short failure is retried and the items stored meanwhile still printed
+	var out, errOut syncBuffer
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	watcher, err := store.NewWatcher(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { done <- cli.tail(ctx, &TailCmd{Interval: &interval}, watcher, &out, &errOut) }()
+
+	history.setFailing(true)
+	waitFor(t, "a warning", func() bool { return strings.Contains(errOut.String(), "database is locked (retrying)") })
+	mem.History().Create(&store.CreateHistoryInput{Title: "while locked", Content: strings.NewReader("x"), Timestamp: time.Now()})
+	history.setFailing(false)
+	waitFor(t, "the item", func() bool { return strings.Contains(out.String(), "while locked") })
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("expected a short failure to be retried, got %v", err)
+	}
+
+	// A persistent failure ends tail with an error
+	history.setFailing(true)
+	err = cli.tail(context.Background(), &TailCmd{Interval: &interval}, watcher, &out, &errOut)
+	if err == nil || !strings.Contains(err.Error(), "database is locked") {
+		t.Errorf("expected a persistent failure to be returned, got %v", err)
+	}
+
+	bad := "soon"
+	if err := (&TailCmd{Interval: &bad}).Validate(); err == nil {
+		t.Error("expected an invalid --interval to be rejected")
+	}
+	if err := (&Args{ReadOnly: true, Tail: &TailCmd{}}).Validate(); err != nil {
+		t.Errorf("expected tail to be allowed with --read-only, got %v", err)
+	}
+}
+
+func TestWatcher_AfterBump(t *testing.T) {
+	mem := memstore.NewMemoryStore()
+	defer mem.Close()
+	create := func(title string, timestamp time.Time) *store.HistoryItem {
+		item, err := mem.History().Create(&store.CreateHistoryInput{Title: title, Content: strings.NewReader(title), Timestamp: timestamp})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return item
+	}
+	now := time.Now()
+	old := create("old", now.Add(-time.Hour))
+	watcher, err := store.NewWatcher(mem.History())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An item bumped past the new ones doesn't hide them
+	create("new", now)
+	if err := mem.History().UpdateTimestamp(old.ID, now.Add(time.Minute)); err != nil {
+		t.Fatalf("UpdateTimestamp() error = %v", err)
+	}
+	created, err := watcher.Poll()
+	if err != nil || len(created) != 1 || created[0].Title != "new" {
+		t.Fatalf("Poll() = %v, %v; want the new item", created, err)
+	}
+	if created, err := watcher.Poll(); err != nil || len(created) != 0 {
+		t.Errorf("idle Poll() = %v, %v; want nothing", created, err)
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/yiblet/rem/internal/tui"
+)
+
+// resetTerminalFlag puts the terminal back after a TUI that didn't exit
+// cleanly. It is for recovery only, so it is left out of the help.
+const resetTerminalFlag = "--reset-terminal"
+
+// debugLogEnv names the file the stack of a crashed TUI is appended to
+const debugLogEnv = "REM_DEBUG_LOG"
+
+// WantsResetTerminal reports whether argv is 'rem --reset-terminal'. main
+// checks it before parsing, since the parser doesn't know the hidden flag.
+func WantsResetTerminal(argv []string) bool {
+	return len(argv) == 1 && argv[0] == resetTerminalFlag
+}
+
+// ExecuteResetTerminal handles 'rem --reset-terminal'
+func ExecuteResetTerminal(w io.Writer) error {
+	return tui.ResetTerminal(w)
+}
+
+// explainTUIError returns err from a TUI session, saying where the stack
+// went if the TUI crashed. The terminal has been restored by then.
+func explainTUIError(err error) error {
+	var crash *tui.PanicError
+	if !errors.As(err, &crash) {
+		return err
+	}
+	path := os.Getenv(debugLogEnv)
+	if path == "" {
+		return fmt.Errorf("%w (set %s to a file to record the stack)", err, debugLogEnv)
+	}
+	if logErr := appendDebugLog(path, crash); logErr != nil {
+		return fmt.Errorf("%w (failed to record the stack: %v)", err, logErr)
+	}
+	return fmt.Errorf("%w (stack recorded in %s)", err, path)
+}
+
+// appendDebugLog appends crash and its stack to the debug log at path
+func appendDebugLog(path string, crash *tui.PanicError) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s %v\n%s\n", time.Now().Format(time.RFC3339), crash, crash.Stack)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
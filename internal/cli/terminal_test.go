@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/tui"
+)
+
+func TestExplainTUIError(t *testing.T) {
+	crash := &tui.PanicError{In: "Update", Value: "boom", Stack: []byte("goroutine 1 [running]:")}
+
+	t.Setenv(debugLogEnv, "")
+	err := explainTUIError(crash)
+	if !errors.Is(err, crash) || !strings.Contains(err.Error(), "set REM_DEBUG_LOG") {
+		t.Errorf("without a debug log, error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "debug.log")
+	t.Setenv(debugLogEnv, path)
+	err = explainTUIError(crash)
+	if !errors.Is(err, crash) || !strings.Contains(err.Error(), "stack recorded in "+path) {
+		t.Errorf("with a debug log, error = %v", err)
+	}
+	logged, readErr := os.ReadFile(path)
+	if readErr != nil || !strings.Contains(string(logged), "viewer crashed in Update: boom") || !strings.Contains(string(logged), "goroutine 1") {
+		t.Errorf("debug log = %q (%v), want the crash and its stack", logged, readErr)
+	}
+
+	// Other errors pass through untouched
+	other := errors.New("clipboard unavailable")
+	if err := explainTUIError(other); err != other {
+		t.Errorf("explainTUIError(%v) = %v", other, err)
+	}
+	if explainTUIError(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+}
+
+func TestWantsResetTerminal(t *testing.T) {
+	if !WantsResetTerminal([]string{"--reset-terminal"}) {
+		t.Error("expected rem --reset-terminal to be recognized")
+	}
+	if WantsResetTerminal([]string{"store", "--reset-terminal"}) || WantsResetTerminal(nil) {
+		t.Error("expected --reset-terminal only on its own")
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeSpecExamples is shown when a --since or --until value cannot be parsed
+const timeSpecExamples = "examples: 2024-05-01, 2024-05-01T15:04:05Z, 24h, 90m, 7d"
+
+// parseTimeSpec parses a --since or --until value relative to now. It accepts
+// a date (2024-05-01, midnight local time), an RFC3339 timestamp, or a
+// positive duration ago (24h, 90m, 7d).
+func parseTimeSpec(flag, value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s value '%s' (%s)", flag, value, timeSpecExamples)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s value '%s' (%s)", flag, value, timeSpecExamples)
+		}
+	}
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid %s value '%s': durations must be positive", flag, value)
+	}
+	return now.Add(-d), nil
+}
+
+// parseTimeWindow parses optional --since and --until values. Unset bounds
+// are returned as the zero time.
+func parseTimeWindow(since, until *string, now time.Time) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if since != nil {
+		if from, err = parseTimeSpec("--since", *since, now); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if until != nil {
+		if to, err = parseTimeSpec("--until", *until, now); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--since must be earlier than --until")
+	}
+	return from, to, nil
+}
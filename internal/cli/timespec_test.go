@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimeSpec(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-05-01", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-01T12:00:00Z", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"2024-05-01T12:00:00.5+02:00", time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{" 1d ", now.Add(-24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeSpec("--since", tt.value, now)
+		if err != nil {
+			t.Errorf("parseTimeSpec(%q) error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimeSpec(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeSpec_Invalid(t *testing.T) {
+	now := time.Now()
+	for _, value := range []string{"", "soon", "-1h", "0s", "0d", "-2d", "xd", "2024-13-01", "05/01/2024"} {
+		_, err := parseTimeSpec("--until", value, now)
+		if err == nil {
+			t.Errorf("parseTimeSpec(%q) should fail", value)
+			continue
+		}
+		if !strings.Contains(err.Error(), "--until") {
+			t.Errorf("parseTimeSpec(%q) error should name the flag, got %q", value, err.Error())
+		}
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	since, until := "7d", "2024-05-09"
+
+	from, to, err := parseTimeWindow(&since, &until, now)
+	if err != nil {
+		t.Fatalf("parseTimeWindow error: %v", err)
+	}
+	if !from.Equal(now.Add(-7*24*time.Hour)) || !to.Equal(time.Date(2024, 5, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseTimeWindow = %v, %v", from, to)
+	}
+
+	if from, to, err := parseTimeWindow(nil, nil, now); err != nil || !from.IsZero() || !to.IsZero() {
+		t.Errorf("Unset bounds should be zero, got %v, %v, %v", from, to, err)
+	}
+
+	if _, _, err := parseTimeWindow(&until, &since, now); err == nil {
+		t.Error("Expected an error when --since is not before --until")
+	}
+}
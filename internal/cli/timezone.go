@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/yiblet/rem/internal/timefmt"
+)
+
+// timeLocation returns the zone times are printed in: UTC with --utc, else
+// the timezone setting, else the local zone. JSON output is not affected.
+func (c *CLI) timeLocation() *time.Location {
+	if c.location != nil {
+		return c.location
+	}
+	c.location = time.Local
+	if c.utc {
+		c.location = time.UTC
+	} else if value, err := c.store.Config().Get("timezone"); err == nil {
+		// A bad setting is refused by 'rem config set', so one that slipped
+		// in some other way falls back to the local zone
+		if loc, err := timefmt.LoadLocation(value); err == nil {
+			c.location = loc
+		}
+	}
+	return c.location
+}
+
+// formatTime formats t with layout in the zone times are printed in
+func (c *CLI) formatTime(t time.Time, layout string) string {
+	return timefmt.Format(t, c.timeLocation(), layout)
+}
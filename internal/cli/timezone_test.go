@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimezone_ListAndInfo(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	item, err := cli.queueManager.Enqueue(strings.NewReader("content"), "noted")
+	if err != nil {
+		t.Fatalf("Failed to store: %v", err)
+	}
+	stamp := time.Date(2024, 5, 1, 22, 30, 0, 0, time.UTC)
+	if err := cli.store.History().UpdateTimestamp(item.ID, stamp); err != nil {
+		t.Fatalf("Failed to set timestamp: %v", err)
+	}
+
+	// An unknown zone is refused
+	if err := cli.Execute(&Args{Config: &ConfigCmd{Set: &ConfigSetCmd{Key: "timezone", Value: "Mars/Olympus"}}}); err == nil {
+		t.Error("expected an unknown timezone to be rejected")
+	}
+	if err := cli.Execute(&Args{Config: &ConfigCmd{Set: &ConfigSetCmd{Key: "timezone", Value: "Asia/Tokyo"}}}); err != nil {
+		t.Fatalf("config set timezone failed: %v", err)
+	}
+
+	// Tokyo is nine hours ahead, which moves the item to the next day
+	output := withStdout(t, func() { cli.Execute(&Args{List: &ListCmd{}}) })
+	if !strings.Contains(output, "2024-05-02 07:30") {
+		t.Errorf("list in Asia/Tokyo = %q, want 2024-05-02 07:30", output)
+	}
+	output = withStdout(t, func() { cli.Execute(&Args{Info: &InfoCmd{Index: indexArg(0)}}) })
+	if !strings.Contains(output, "Queued:   2024-05-02T07:30:00+09:00") {
+		t.Errorf("info in Asia/Tokyo = %q, want the queued time with a +09:00 offset", output)
+	}
+
+	// --utc overrides the setting
+	output = withStdout(t, func() { cli.Execute(&Args{UTC: true, List: &ListCmd{}}) })
+	if !strings.Contains(output, "2024-05-01 22:30") {
+		t.Errorf("list --utc = %q, want 2024-05-01 22:30", output)
+	}
+	output = withStdout(t, func() { cli.Execute(&Args{UTC: true, Info: &InfoCmd{Index: indexArg(0)}}) })
+	if !strings.Contains(output, "Queued:   2024-05-01T22:30:00Z") {
+		t.Errorf("info --utc = %q, want the queued time in UTC", output)
+	}
+
+	// JSON carries the instant with its offset whatever the zone
+	output = withStdout(t, func() { cli.Execute(&Args{List: &ListCmd{JSON: true}}) })
+	var listed struct{ Timestamp time.Time }
+	if err := json.Unmarshal([]byte(strings.SplitN(output, "\n", 2)[0]), &listed); err != nil {
+		t.Fatalf("Failed to parse %q: %v", output, err)
+	}
+	if !listed.Timestamp.Equal(stamp) || !strings.Contains(output, `"2024-05-01T22:30:00Z"`) {
+		t.Errorf("JSON timestamp = %v in %q, want %v", listed.Timestamp, output, stamp)
+	}
+}
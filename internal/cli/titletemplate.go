@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/store"
+)
+
+// titlePeekSize is how much content is read ahead to find .FirstLine
+const titlePeekSize = 4096
+
+// titleData is what a title template is rendered with
+type titleData struct {
+	Now       time.Time
+	Hostname  string
+	Source    string // "stdin", "clipboard", or the file name
+	FirstLine string
+}
+
+// parseTitleTemplate parses a title template and renders it once with
+// sample data, so mistakes such as unknown fields are reported before any
+// content is read
+func parseTitleTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("title").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, titleData{Now: time.Now()}); err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// titleTemplate returns the template for items stored without --title:
+// --title-template if given, else default_title_template. It returns nil
+// if neither is set.
+func (c *CLI) titleTemplate(cmd *StoreCmd) (*template.Template, error) {
+	if cmd.Title != nil {
+		return nil, nil
+	}
+	if cmd.TitleTemplate != nil {
+		return parseTitleTemplate(*cmd.TitleTemplate)
+	}
+	value, err := c.store.Config().Get("default_title_template")
+	if err != nil || value == "" {
+		return nil, nil
+	}
+	tmpl, err := parseTitleTemplate(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default_title_template (fix with 'rem config set'): %w", err)
+	}
+	return tmpl, nil
+}
+
+// storeTitle returns the title given with --title, --title-file, or
+// --title-from-clipboard, or "" if none was
+func (c *CLI) storeTitle(cmd *StoreCmd) (string, error) {
+	switch {
+	case cmd.Title != nil:
+		return *cmd.Title, nil
+	case cmd.TitleFile != nil:
+		file, err := os.Open(*cmd.TitleFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read --title-file: %w", err)
+		}
+		defer file.Close()
+		return titleLine(file, "--title-file "+*cmd.TitleFile)
+	case cmd.TitleFromClipboard:
+		reader, err := c.clipboard.Read()
+		if err != nil {
+			return "", fmt.Errorf("failed to read clipboard: %w", err)
+		}
+		defer reader.Close()
+		return titleLine(reader, "the clipboard")
+	}
+	return "", nil
+}
+
+// titleLine returns the first non-empty line within the first titlePeekSize
+// bytes of content, which is named by what in errors
+func titleLine(content io.Reader, what string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(content, titlePeekSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", what, err)
+	}
+	if store.IsBinary(data) {
+		return "", fmt.Errorf("cannot take a title from %s: it is binary", what)
+	}
+	for line := range strings.Lines(string(data)) {
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("cannot take a title from %s: it is empty", what)
+}
+
+// templateTitle returns title, or tmpl rendered for content when title is
+// empty and there is a template, and a reader that still yields all of
+// content
+func templateTitle(content io.Reader, title string, tmpl *template.Template, source string) (string, io.Reader, error) {
+	if title != "" || tmpl == nil {
+		return title, content, nil
+	}
+	return renderTitle(tmpl, source, content)
+}
+
+// renderTitle renders tmpl for content read from source. It returns the
+// title and a reader that still yields all of content.
+func renderTitle(tmpl *template.Template, source string, content io.Reader) (string, io.Reader, error) {
+	reader := bufio.NewReaderSize(content, titlePeekSize)
+	peek, err := reader.Peek(titlePeekSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	firstLine := peek
+	if i := bytes.IndexByte(peek, '\n'); i >= 0 {
+		firstLine = peek[:i]
+	}
+
+	hostname, _ := os.Hostname()
+	var title strings.Builder
+	err = tmpl.Execute(&title, titleData{
+		Now:       time.Now(),
+		Hostname:  hostname,
+		Source:    source,
+		FirstLine: strings.TrimSpace(string(firstLine)),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render title template: %w", err)
+	}
+	return title.String(), reader, nil
+}
+
+// enqueue stores content from source, titling it with tmpl when no title is
+// given. A template that renders empty falls back to the generated title.
+func (c *CLI) enqueue(content io.Reader, title string, tmpl *template.Template, source string, info fs.FileInfo) (*queue.EnqueueResult, error) {
+	title, content, err := templateTitle(content, title, tmpl, source)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		return c.queueManager.EnqueueFileWithResult(content, title, info)
+	}
+	return c.queueManager.EnqueueWithResult(content, title)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yiblet/rem/internal/lockfile"
+)
+
+// tuiLockSuffix names the lock file an open TUI holds next to the database
+const tuiLockSuffix = ".tui.lock"
+
+// tuiAccess is how a TUI session may use the database
+type tuiAccess int
+
+const (
+	tuiReadWrite tuiAccess = iota
+	tuiReadOnly
+	tuiQuit // don't open the TUI at all
+)
+
+// lockTUI takes the TUI lock on the database, so a second TUI can't delete
+// items the first still shows. When another live TUI holds it, the user
+// chooses, reading the answer from in, to open read-only, open anyway, or
+// quit; without a terminal to ask on, the TUI opens read-only. The lock
+// returned, if any, is released when the TUI quits. Other commands don't
+// take the lock.
+func (c *CLI) lockTUI(in io.Reader, interactive bool) (*lockfile.Lock, tuiAccess) {
+	if c.readOnly() {
+		return nil, tuiReadOnly
+	}
+	if c.dbPath == "" || c.dbPath == MemoryDBPath {
+		// No other process can open this database
+		return nil, tuiReadWrite
+	}
+
+	lock, err := lockfile.Acquire(c.dbPath + tuiLockSuffix)
+	var held *lockfile.HeldError
+	switch {
+	case err == nil:
+		return lock, tuiReadWrite
+	case !errors.As(err, &held):
+		// The lock is advisory, so failing to take it doesn't stop the TUI
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil, tuiReadWrite
+	}
+	other := "Another rem TUI"
+	if held.Holder.PID > 0 {
+		other += fmt.Sprintf(" (pid %d)", held.Holder.PID)
+	}
+	if !interactive {
+		fmt.Fprintf(os.Stderr, "%s is open; opening read-only\n", other)
+		return nil, tuiReadOnly
+	}
+
+	fmt.Fprintf(os.Stderr, "%s is open — open [r]ead-only, open [a]nyway, or [q]uit? [R/a/q]: ", other)
+	response, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(response)) {
+	case "a", "anyway":
+		return nil, tuiReadWrite
+	case "q", "quit":
+		return nil, tuiQuit
+	default:
+		return nil, tuiReadOnly
+	}
+}
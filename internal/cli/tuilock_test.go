@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/lockfile"
+)
+
+func TestLockTUI(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("Failed to create CLI: %v", err)
+	}
+	defer cli.store.Close()
+
+	// The first TUI takes the lock, and releases it when it quits
+	lock, access := cli.lockTUI(strings.NewReader(""), true)
+	if lock == nil || access != tuiReadWrite {
+		t.Fatalf("lockTUI() = %v, %v; want the lock", lock, access)
+	}
+
+	// A second one asks what to do, opening read-only by default
+	tests := []struct {
+		answer      string
+		interactive bool
+		want        tuiAccess
+	}{
+		{"\n", true, tuiReadOnly},
+		{"r\n", true, tuiReadOnly},
+		{"a\n", true, tuiReadWrite},
+		{"q\n", true, tuiQuit},
+		{"a\n", false, tuiReadOnly},
+	}
+	for _, tt := range tests {
+		second, access := cli.lockTUI(strings.NewReader(tt.answer), tt.interactive)
+		if second != nil || access != tt.want {
+			t.Errorf("answer %q (interactive %v): lockTUI() = %v, %v; want %v without the lock", tt.answer, tt.interactive, second, access, tt.want)
+		}
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(dbPath + tuiLockSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file removed, got %v", err)
+	}
+
+	// Other commands neither take nor need the lock
+	held, err := lockfile.Acquire(dbPath + tuiLockSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Release()
+	output := withStdout(t, func() {
+		if err := cli.Execute(&Args{List: &ListCmd{}}); err != nil {
+			t.Errorf("list with a TUI open: %v", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("unexpected list output %q", output)
+	}
+}
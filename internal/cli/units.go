@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxHistoryLimit is the largest history_limit accepted by 'rem config set'
+const maxHistoryLimit = 100000
+
+// countSuffixes are the multipliers accepted by parseCount
+var countSuffixes = map[string]int64{
+	"":  1,
+	"k": 1000,
+	"m": 1000 * 1000,
+}
+
+// sizeSuffixes are the multipliers accepted by parseSize. SI units are
+// powers of 1000 and IEC units (KiB, MiB, ...) are powers of 1024.
+var sizeSuffixes = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseCount parses a count such as "500", "1k" or "2.5k"
+func parseCount(value string) (int64, error) {
+	return parseWithSuffix(value, countSuffixes)
+}
+
+// parseSize parses a byte size such as "4096", "10MB" or "1GiB"
+func parseSize(value string) (int64, error) {
+	return parseWithSuffix(value, sizeSuffixes)
+}
+
+// parseWithSuffix parses a non-negative number followed by an optional,
+// case-insensitive unit suffix. The result must be a whole number.
+func parseWithSuffix(value string, suffixes map[string]int64) (int64, error) {
+	s := strings.TrimSpace(value)
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+		end++
+	}
+	number, unit := s[:end], strings.ToLower(strings.TrimSpace(s[end:]))
+
+	multiplier, ok := suffixes[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if n, err := strconv.ParseInt(number, 10, 64); err == nil {
+		if n > math.MaxInt64/multiplier {
+			return 0, fmt.Errorf("value %q is too large", value)
+		}
+		return n * multiplier, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	total := f * float64(multiplier)
+	if total >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %q is too large", value)
+	}
+	if total != math.Trunc(total) {
+		return 0, fmt.Errorf("value %q is not a whole number", value)
+	}
+	return int64(total), nil
+}
+
+// parseHistoryLimit parses and bounds-checks a history_limit value
+func parseHistoryLimit(value string) (int, error) {
+	limit, err := parseCount(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("history_limit must be a positive integer such as 500 or 1k")
+	}
+	if limit > maxHistoryLimit {
+		return 0, fmt.Errorf("history_limit %d is too large (maximum %d)", limit, maxHistoryLimit)
+	}
+	return int(limit), nil
+}
+
+// parseClipboardMaxBytes parses a clipboard_max_bytes value
+func parseClipboardMaxBytes(value string) (int64, error) {
+	limit, err := parseSize(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("clipboard_max_bytes must be a positive size such as 1048576, 10MB or 1GiB")
+	}
+	return limit, nil
+}
+
+// normalizeConfigValue returns the canonical form of a config value, or
+// value unchanged if the key has no canonical form or value does not parse
+func normalizeConfigValue(key, value string) string {
+	switch key {
+	case "history_limit":
+		if limit, err := parseHistoryLimit(value); err == nil {
+			return strconv.Itoa(limit)
+		}
+	case "clipboard_max_bytes":
+		if limit, err := parseClipboardMaxBytes(value); err == nil {
+			return strconv.FormatInt(limit, 10)
+		}
+	case "backup_max_bytes", "compress_min_bytes":
+		if limit, err := parseSize(value); err == nil {
+			return strconv.FormatInt(limit, 10)
+		}
+	}
+	return value
+}
+
+// formatSize formats a byte count for people, in SI units with one decimal
+// place above a kilobyte: "512 B", "1.2 GB"
+func formatSize(n int64) string {
+	units := []string{"KB", "MB", "GB", "TB"}
+	if n < 1000 {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n) / 1000
+	unit := 0
+	for value >= 999.95 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
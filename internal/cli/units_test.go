@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"4096", 4096, false},
+		{"512B", 512, false},
+		{"10MB", 10 * 1000 * 1000, false},
+		{"10mb", 10 * 1000 * 1000, false},
+		{"1GiB", 1 << 30, false},
+		{"1.5KiB", 1536, false},
+		{" 2 KB ", 2000, false},
+		{"1.5B", 0, true},       // not a whole number of bytes
+		{"10XB", 0, true},       // unknown unit
+		{"MB", 0, true},         // no number
+		{"-1MB", 0, true},       // negative
+		{"1.2.3MB", 0, true},    // malformed number
+		{"99999999TB", 0, true}, // overflows int64
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{"500", 500, false},
+		{"1k", 1000, false},
+		{"2.5K", 2500, false},
+		{"1m", 1000000, false},
+		{"1kb", 0, true},
+		{"0.5", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCount(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCount(%q) = %d, %v; want %d (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseHistoryLimit_Bounds(t *testing.T) {
+	if limit, err := parseHistoryLimit("100k"); err != nil || limit != maxHistoryLimit {
+		t.Errorf("Expected 100k to be the largest accepted limit, got %d, %v", limit, err)
+	}
+	if _, err := parseHistoryLimit("100001"); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("Expected a too-large error, got %v", err)
+	}
+	for _, value := range []string{"0", "-5", "lots"} {
+		if _, err := parseHistoryLimit(value); err == nil {
+			t.Errorf("Expected history_limit %q to be rejected", value)
+		}
+	}
+}
+
+func TestNormalizeConfigValue(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"history_limit", "1k", "1000"},
+		{"history_limit", "1000", "1000"},
+		{"clipboard_max_bytes", "10MB", "10000000"},
+		{"clipboard_max_bytes", "1GiB", "1073741824"},
+		{"clipboard_max_bytes", "oops", "oops"}, // unparseable values pass through
+		{"show_binary", "true", "true"},
+	}
+	for _, tt := range tests {
+		got := normalizeConfigValue(tt.key, tt.value)
+		if got != tt.want {
+			t.Errorf("normalizeConfigValue(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+		// Normalizing is idempotent
+		if again := normalizeConfigValue(tt.key, got); again != got {
+			t.Errorf("normalizeConfigValue(%q, %q) is not stable: %q", tt.key, got, again)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1.0 KB"},
+		{1234567, "1.2 MB"},
+		{999960, "1.0 MB"},
+		{1200000000, "1.2 GB"},
+		{5 * 1000 * 1000 * 1000 * 1000 * 1000, "5000.0 TB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
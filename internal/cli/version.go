@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/yiblet/rem/internal/store/dbstore"
+	"github.com/yiblet/rem/internal/version"
+)
+
+// versionReport is what 'rem version' prints
+type versionReport struct {
+	version.Info
+	Database databaseReport `json:"database"`
+}
+
+// databaseReport describes the database rem would open, as far as it can
+// be read; Error says why the rest is missing
+type databaseReport struct {
+	Path          string `json:"path,omitempty"`
+	Version       string `json:"db_version,omitempty"`
+	Items         *int   `json:"items,omitempty"`
+	SQLiteVersion string `json:"sqlite_version,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ExecuteVersion handles 'rem version' and 'rem --version'. It needs no
+// CLI, so main can run it before anything else touches the database: the
+// database is opened read-only, and only to report on it.
+func ExecuteVersion(args *Args, w io.Writer) error {
+	report := versionReport{Info: version.Get(), Database: probeDatabase(args)}
+	if args.Version != nil && args.Version.JSON {
+		data, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode version report: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	fmt.Fprintf(w, "rem %s\n", report.Version)
+	if report.Commit != "" {
+		fmt.Fprintf(w, "Commit:     %s\n", report.Commit)
+	}
+	if report.BuildDate != "" {
+		fmt.Fprintf(w, "Built:      %s\n", report.BuildDate)
+	}
+	fmt.Fprintf(w, "Go:         %s\n", report.GoVersion)
+	db := report.Database
+	if db.Path != "" {
+		fmt.Fprintf(w, "Database:   %s\n", db.Path)
+	}
+	if db.Error != "" {
+		fmt.Fprintf(w, "            unavailable: %s\n", db.Error)
+		return nil
+	}
+	fmt.Fprintf(w, "DB version: %s\n", db.Version)
+	fmt.Fprintf(w, "Items:      %d\n", *db.Items)
+	fmt.Fprintf(w, "SQLite:     %s\n", db.SQLiteVersion)
+	return nil
+}
+
+// probeDatabase reports on the database args point at without creating,
+// migrating, or moving it
+func probeDatabase(args *Args) databaseReport {
+	var report databaseReport
+	path, _, err := resolveDBPath(args)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.Path = path
+	if _, err := os.Stat(report.Path); err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	db, err := dbstore.NewSQLiteStoreWithOptions(report.Path, dbstore.Options{ReadOnly: true})
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	defer db.Close()
+
+	if report.Version, err = db.Config().Get("db_version"); err != nil {
+		report.Error = fmt.Sprintf("failed to read db_version: %v", err)
+		return report
+	}
+	count, err := db.History().Count()
+	if err != nil {
+		report.Error = fmt.Sprintf("failed to count items: %v", err)
+		return report
+	}
+	report.Items = &count
+	if report.SQLiteVersion, err = db.SQLiteVersion(); err != nil {
+		report.Error = err.Error()
+	}
+	return report
+}
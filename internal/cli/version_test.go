@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store/dbstore"
+)
+
+func TestExecuteVersion_JSON(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "rem.db")
+	cli, err := NewWithArgs(&Args{DBPath: &dbPath})
+	if err != nil {
+		t.Fatalf("failed to create CLI: %v", err)
+	}
+	if _, err := cli.queueManager.Enqueue(strings.NewReader("hello"), "greeting"); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	cli.store.Close()
+
+	var out bytes.Buffer
+	if err := ExecuteVersion(&Args{DBPath: &dbPath, Version: &VersionCmd{JSON: true}}, &out); err != nil {
+		t.Fatalf("version failed: %v", err)
+	}
+	var report map[string]any
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", out.String(), err)
+	}
+	for _, key := range []string{"version", "go_version", "database"} {
+		if _, ok := report[key]; !ok {
+			t.Errorf("expected %q in %s", key, out.String())
+		}
+	}
+	db, _ := report["database"].(map[string]any)
+	if db["path"] != dbPath || db["db_version"] != strconv.Itoa(dbstore.SchemaVersion) || db["items"] != 1.0 || db["sqlite_version"] == "" {
+		t.Errorf("unexpected database report %v", db)
+	}
+}
+
+func TestExecuteVersion_NoHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	var out bytes.Buffer
+	if err := ExecuteVersion(&Args{ShowVersion: true}, &out); err != nil {
+		t.Fatalf("expected version to work without HOME, got %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "rem ") || !strings.Contains(out.String(), "unavailable") {
+		t.Errorf("expected the build info and an unavailable database, got %q", out.String())
+	}
+}
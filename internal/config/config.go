@@ -15,6 +15,9 @@ type Config struct {
 	ShowBinary      bool   `yaml:"show_binary"`
 }
 
+// Keys lists the keys config.yaml holds, in canonical form
+var Keys = []string{"history_limit", "show_binary", "history_location"}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -28,15 +31,16 @@ type ConfigManager struct {
 	configPath string
 }
 
-// NewConfigManager creates a new configuration manager
+// NewConfigManager creates a configuration manager for the default config
+// path, moving config.yaml there from the legacy location if needed
 func NewConfigManager() (*ConfigManager, error) {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := DefaultConfigPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return nil, err
+	}
+	if _, err := migrateLegacyFile("config.yaml", configPath); err != nil {
+		return nil, err
 	}
-
-	configDir := filepath.Join(homeDir, ".config", "rem")
-	configPath := filepath.Join(configDir, "config.yaml")
 
 	return &ConfigManager{
 		configPath: configPath,
@@ -84,7 +88,7 @@ func (cm *ConfigManager) Save(config *Config) error {
 
 	// Ensure config directory exists
 	configDir := filepath.Dir(cm.configPath)
-	if err := os.MkdirAll(configDir, 0755); err != nil {
+	if err := os.MkdirAll(configDir, 0700); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
@@ -93,7 +97,7 @@ func (cm *ConfigManager) Save(config *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(cm.configPath, data, 0644); err != nil {
+	if err := os.WriteFile(cm.configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -118,61 +122,63 @@ func (cm *ConfigManager) GetConfigPath() string {
 	return cm.configPath
 }
 
-// Update modifies a specific configuration value
+// Update modifies a specific configuration value. Keys may use hyphens or
+// underscores as separators.
 func (cm *ConfigManager) Update(key, value string) error {
 	config, err := cm.Load()
 	if err != nil {
 		return err
 	}
 
-	switch key {
-	case "history-limit":
+	switch CanonicalKey(key) {
+	case "history_limit":
 		var historyLimit int
 		if _, err := fmt.Sscanf(value, "%d", &historyLimit); err != nil {
-			return fmt.Errorf("invalid integer value for history-limit: %s", value)
+			return fmt.Errorf("invalid integer value for history_limit: %s", value)
 		}
 		config.HistoryLimit = historyLimit
-	case "show-binary":
+	case "show_binary":
 		switch value {
 		case "true":
 			config.ShowBinary = true
 		case "false":
 			config.ShowBinary = false
 		default:
-			return fmt.Errorf("invalid boolean value for show-binary: %s (must be 'true' or 'false')", value)
+			return fmt.Errorf("invalid value '%s' for show_binary (allowed: true, false)", value)
 		}
-	case "history-location":
+	case "history_location":
 		config.HistoryLocation = value
 	default:
-		return fmt.Errorf("unknown configuration key: %s", key)
+		return UnknownKeyError(key, Keys)
 	}
 
 	return cm.Save(config)
 }
 
-// Get returns the value for a specific configuration key
+// Get returns the value for a specific configuration key. Keys may use
+// hyphens or underscores as separators.
 func (cm *ConfigManager) Get(key string) (string, error) {
 	config, err := cm.Load()
 	if err != nil {
 		return "", err
 	}
 
-	switch key {
-	case "history-limit":
+	switch CanonicalKey(key) {
+	case "history_limit":
 		return fmt.Sprintf("%d", config.HistoryLimit), nil
-	case "show-binary":
+	case "show_binary":
 		return fmt.Sprintf("%t", config.ShowBinary), nil
-	case "history-location":
+	case "history_location":
 		if config.HistoryLocation == "" {
 			return "[default]", nil
 		}
 		return config.HistoryLocation, nil
 	default:
-		return "", fmt.Errorf("unknown configuration key: %s", key)
+		return "", UnknownKeyError(key, Keys)
 	}
 }
 
-// List returns all configuration keys and values
+// List returns all configuration keys, in canonical form, and their values
 func (cm *ConfigManager) List() (map[string]string, error) {
 	config, err := cm.Load()
 	if err != nil {
@@ -180,13 +186,13 @@ func (cm *ConfigManager) List() (map[string]string, error) {
 	}
 
 	result := map[string]string{
-		"history-limit":    fmt.Sprintf("%d", config.HistoryLimit),
-		"show-binary":      fmt.Sprintf("%t", config.ShowBinary),
-		"history-location": config.HistoryLocation,
+		"history_limit":    fmt.Sprintf("%d", config.HistoryLimit),
+		"show_binary":      fmt.Sprintf("%t", config.ShowBinary),
+		"history_location": config.HistoryLocation,
 	}
 
-	if result["history-location"] == "" {
-		result["history-location"] = "[default]"
+	if result["history_location"] == "" {
+		result["history_location"] = "[default]"
 	}
 
 	return result, nil
@@ -255,7 +255,7 @@ func TestConfigManager_List(t *testing.T) {
 		t.Fatalf("Failed to list default config: %v", err)
 	}
 
-	expectedKeys := []string{"history-limit", "show-binary", "history-location"}
+	expectedKeys := []string{"history_limit", "show_binary", "history_location"}
 	for _, key := range expectedKeys {
 		if _, exists := values[key]; !exists {
 			t.Errorf("Expected key %s to exist in list output", key)
@@ -263,12 +263,12 @@ func TestConfigManager_List(t *testing.T) {
 	}
 
 	// Verify default values
-	if values["history-limit"] != "20" {
-		t.Errorf("Expected default history-limit 20, got %s", values["history-limit"])
+	if values["history_limit"] != "20" {
+		t.Errorf("Expected default history-limit 20, got %s", values["history_limit"])
 	}
 
-	if values["history-location"] != "[default]" {
-		t.Errorf("Expected default history-location [default], got %s", values["history-location"])
+	if values["history_location"] != "[default]" {
+		t.Errorf("Expected default history-location [default], got %s", values["history_location"])
 	}
 }
 
@@ -282,6 +282,8 @@ func TestConfigManager_GetConfigPath(t *testing.T) {
 }
 
 func TestNewConfigManager(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
 	cm, err := NewConfigManager()
 	if err != nil {
 		t.Fatalf("Failed to create config manager: %v", err)
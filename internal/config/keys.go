@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CanonicalKey returns the canonical spelling of a configuration key: lower
+// case with words separated by underscores. Hyphens are accepted as
+// separators, so "history-limit" and "history_limit" name the same key.
+func CanonicalKey(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "-", "_")
+}
+
+// UnknownKeyError returns the error for a key not in valid, suggesting the
+// closest valid key when there is one
+func UnknownKeyError(key string, valid []string) error {
+	if suggestion := SuggestKey(key, valid); suggestion != "" {
+		return fmt.Errorf("unknown key '%s', did you mean '%s'?", key, suggestion)
+	}
+	return fmt.Errorf("unknown key '%s', valid keys are: %s", key, strings.Join(valid, ", "))
+}
+
+// SuggestKey returns the key in valid closest to key by edit distance, or ""
+// if none is close enough to be a likely typo
+func SuggestKey(key string, valid []string) string {
+	key = CanonicalKey(key)
+	best, bestDistance := "", -1
+	for _, candidate := range valid {
+		distance := levenshtein(key, candidate)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	// Allow about one typo per three characters, and at least two
+	if bestDistance < 0 || bestDistance > max(2, len(key)/3) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the number of single-character insertions, deletions
+// and substitutions needed to turn a into b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
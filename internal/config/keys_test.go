@@ -0,0 +1,77 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalKey(t *testing.T) {
+	for _, key := range []string{"history_limit", "history-limit", "History-Limit", " history_limit "} {
+		if got := CanonicalKey(key); got != "history_limit" {
+			t.Errorf("CanonicalKey(%q) = %q, want history_limit", key, got)
+		}
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	valid := []string{"history_limit", "show_binary", "history_location", "backup_keep"}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"history-limt", "history_limit"},
+		{"histroy_limit", "history_limit"},
+		{"show_binry", "show_binary"},
+		{"history_locaton", "history_location"},
+		{"backupkeep", "backup_keep"},
+		{"colour", ""},
+		{"x", ""},
+	}
+	for _, tt := range tests {
+		if got := SuggestKey(tt.key, valid); got != tt.want {
+			t.Errorf("SuggestKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownKeyError(t *testing.T) {
+	valid := []string{"history_limit", "show_binary"}
+	if err := UnknownKeyError("history-limt", valid); !strings.Contains(err.Error(), "did you mean 'history_limit'?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+	if err := UnknownKeyError("colour", valid); !strings.Contains(err.Error(), "history_limit, show_binary") {
+		t.Errorf("expected the valid keys to be listed, got %v", err)
+	}
+}
+
+func TestConfigManager_KeyAliases(t *testing.T) {
+	cm := NewConfigManagerWithPath(filepath.Join(t.TempDir(), "config.yaml"))
+
+	if err := cm.Update("history-limit", "42"); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	for _, key := range []string{"history_limit", "history-limit"} {
+		if value, err := cm.Get(key); err != nil || value != "42" {
+			t.Errorf("Get(%q) = %q, %v; want 42", key, value, err)
+		}
+	}
+
+	// List keys are canonical and agree with the spelling Get accepts
+	values, err := cm.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	for _, key := range Keys {
+		if _, ok := values[key]; !ok {
+			t.Errorf("List() missing canonical key %s", key)
+		}
+	}
+
+	if err := cm.Update("show_binary", "maybe"); err == nil || !strings.Contains(err.Error(), "allowed: true, false") {
+		t.Errorf("expected the allowed values in the error, got %v", err)
+	}
+	if _, err := cm.Get("show-binry"); err == nil || !strings.Contains(err.Error(), "did you mean 'show_binary'?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Overridable for tests
+var (
+	goos          = runtime.GOOS
+	userConfigDir = os.UserConfigDir
+)
+
+// movedMarkerSuffix names the file left next to a migrated database when a
+// symlink can't be created
+const movedMarkerSuffix = ".moved"
+
+// LegacyDir returns the directory rem used before paths were platform aware
+func LegacyDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "rem"), nil
+}
+
+// DataDir returns the directory holding the database: $XDG_DATA_HOME/rem when
+// set, the user config directory on Windows and macOS, and the legacy
+// directory otherwise
+func DataDir() (string, error) {
+	return platformDir("XDG_DATA_HOME")
+}
+
+// ConfigDir returns the directory holding config.yaml: $XDG_CONFIG_HOME/rem
+// when set, the user config directory on Windows and macOS, and the legacy
+// directory otherwise
+func ConfigDir() (string, error) {
+	return platformDir("XDG_CONFIG_HOME")
+}
+
+// platformDir resolves a rem directory, preferring the XDG variable xdgVar
+func platformDir(xdgVar string) (string, error) {
+	if dir := os.Getenv(xdgVar); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, "rem"), nil
+	}
+	switch goos {
+	case "windows", "darwin":
+		dir, err := userConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user config directory: %w", err)
+		}
+		return filepath.Join(dir, "rem"), nil
+	default:
+		// Linux and the BSDs keep the original location
+		return LegacyDir()
+	}
+}
+
+// DefaultDBPath returns the default database path
+func DefaultDBPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rem.db"), nil
+}
+
+// DefaultConfigPath returns the default config.yaml path
+func DefaultConfigPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// MigrateLegacyDB moves a database (and its backups directory) from the
+// legacy location to dbPath when dbPath does not exist yet. It reports
+// whether anything was moved.
+func MigrateLegacyDB(dbPath string) (bool, error) {
+	moved, err := migrateLegacyFile("rem.db", dbPath)
+	if !moved {
+		return false, err
+	}
+
+	// The journal and backups belong with the database
+	legacyDir, _ := LegacyDir()
+	legacyPath := filepath.Join(legacyDir, "rem.db")
+	for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+		os.Rename(legacyPath+suffix, dbPath+suffix)
+	}
+	backups := filepath.Join(filepath.Dir(dbPath), "backups")
+	if _, err := os.Stat(backups); os.IsNotExist(err) {
+		os.Rename(filepath.Join(legacyDir, "backups"), backups)
+	}
+	return true, err
+}
+
+// migrateLegacyFile moves the file name from the legacy directory to newPath
+// when newPath does not exist yet. A symlink to the new location is left
+// behind, or a marker file naming it where symlinks are unavailable.
+func migrateLegacyFile(name, newPath string) (bool, error) {
+	legacyDir, err := LegacyDir()
+	if err != nil {
+		return false, err
+	}
+	legacyPath := filepath.Join(legacyDir, name)
+	if filepath.Clean(legacyPath) == filepath.Clean(newPath) {
+		return false, nil
+	}
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		return false, nil
+	}
+	info, err := os.Lstat(legacyPath)
+	if err != nil || !info.Mode().IsRegular() {
+		// Nothing to migrate, or already migrated and linked
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0700); err != nil {
+		return false, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(legacyPath, newPath); err != nil {
+		return false, fmt.Errorf("failed to move %s to %s: %w", legacyPath, newPath, err)
+	}
+	if err := os.Symlink(newPath, legacyPath); err != nil {
+		if err := os.WriteFile(legacyPath+movedMarkerSuffix, []byte(newPath+"\n"), 0644); err != nil {
+			return true, fmt.Errorf("failed to record the new location of %s: %w", name, err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setPlatform overrides the detected OS and user config directory for a test
+func setPlatform(t *testing.T, platform, configDir string) {
+	t.Helper()
+	origOS, origDir := goos, userConfigDir
+	t.Cleanup(func() { goos, userConfigDir = origOS, origDir })
+	goos = platform
+	userConfigDir = func() (string, error) { return configDir, nil }
+}
+
+func TestPlatformDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacy := filepath.Join(home, ".config", "rem")
+
+	tests := []struct {
+		name       string
+		goos       string
+		dataHome   string
+		configHome string
+		wantData   string
+		wantConfig string
+	}{
+		{"linux defaults to the legacy directory", "linux", "", "", legacy, legacy},
+		{"openbsd defaults to the legacy directory", "openbsd", "", "", legacy, legacy},
+		{"xdg variables win", "linux", "/xdg/data", "/xdg/config", "/xdg/data/rem", "/xdg/config/rem"},
+		{"relative xdg variables are ignored", "linux", "data", "config", legacy, legacy},
+		{"windows uses the user config directory", "windows", "", "", "/appdata/rem", "/appdata/rem"},
+		{"macos uses the user config directory", "darwin", "", "", "/appdata/rem", "/appdata/rem"},
+		{"xdg variables win on macos", "darwin", "/xdg/data", "", "/xdg/data/rem", "/appdata/rem"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setPlatform(t, tt.goos, "/appdata")
+			t.Setenv("XDG_DATA_HOME", tt.dataHome)
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+
+			if dir, err := DataDir(); err != nil || dir != tt.wantData {
+				t.Errorf("DataDir() = %q, %v, want %q", dir, err, tt.wantData)
+			}
+			if dir, err := ConfigDir(); err != nil || dir != tt.wantConfig {
+				t.Errorf("ConfigDir() = %q, %v, want %q", dir, err, tt.wantConfig)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyDB(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacyDir := filepath.Join(home, ".config", "rem")
+	if err := os.MkdirAll(filepath.Join(legacyDir, "backups"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "rem.db"), []byte("database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, "backups", "rem-1.db"), []byte("backup"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without XDG_DATA_HOME the default is the legacy path itself
+	t.Setenv("XDG_DATA_HOME", "")
+	if moved, err := MigrateLegacyDB(filepath.Join(legacyDir, "rem.db")); moved || err != nil {
+		t.Fatalf("Expected no migration onto the legacy path, got %v, %v", moved, err)
+	}
+
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	dbPath, err := DefaultDBPath()
+	if err != nil {
+		t.Fatalf("DefaultDBPath() error = %v", err)
+	}
+	moved, err := MigrateLegacyDB(dbPath)
+	if err != nil || !moved {
+		t.Fatalf("MigrateLegacyDB() = %v, %v, want moved", moved, err)
+	}
+
+	if data, _ := os.ReadFile(dbPath); string(data) != "database" {
+		t.Errorf("Expected the database at %s, got %q", dbPath, data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(home, "data", "rem", "backups", "rem-1.db")); string(data) != "backup" {
+		t.Error("Expected backups to move with the database")
+	}
+	if target, err := os.Readlink(filepath.Join(legacyDir, "rem.db")); err != nil || target != dbPath {
+		t.Errorf("Expected a symlink to the new location, got %q, %v", target, err)
+	}
+
+	// Migration happens once
+	if moved, err := MigrateLegacyDB(dbPath); moved || err != nil {
+		t.Errorf("Expected a second migration to do nothing, got %v, %v", moved, err)
+	}
+}
+
+func TestNewConfigManager_MigratesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	legacy := filepath.Join(home, ".config", "rem", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("history_limit: 42\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cm, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("NewConfigManager() error = %v", err)
+	}
+	if want := filepath.Join(home, "xdg", "rem", "config.yaml"); cm.GetConfigPath() != want {
+		t.Errorf("Expected config path %s, got %s", want, cm.GetConfigPath())
+	}
+	config, err := cm.Load()
+	if err != nil || config.HistoryLimit != 42 {
+		t.Errorf("Expected the migrated config to load, got %+v, %v", config, err)
+	}
+}
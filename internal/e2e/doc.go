@@ -0,0 +1,4 @@
+// Package e2e runs the compiled rem binary against a scratch database, to
+// catch regressions in argument wiring and exit codes that tests of the
+// internal packages can't. It holds only tests; -short skips them.
+package e2e
@@ -0,0 +1,278 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden transcripts in testdata")
+
+// remBin is the binary built by TestMain
+var remBin string
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if testing.Short() {
+		os.Exit(m.Run())
+	}
+
+	dir, err := os.MkdirTemp("", "rem-e2e-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create build directory: %v\n", err)
+		os.Exit(1)
+	}
+	remBin = filepath.Join(dir, "rem")
+	if runtime.GOOS == "windows" {
+		remBin += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", remBin, "github.com/yiblet/rem")
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build rem: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// step is one command of a scenario: rem run with args and stdin, or, when
+// file is set, a look at that file in the scenario's directory
+type step struct {
+	args  []string
+	stdin string
+	file  string
+}
+
+// rem is a step running rem with args and no input
+func rem(args ...string) step {
+	return step{args: args}
+}
+
+// pipe is a step running rem with args, reading stdin
+func pipe(stdin string, args ...string) step {
+	return step{args: args, stdin: stdin}
+}
+
+// cat is a step printing a file the scenario wrote
+func cat(name string) step {
+	return step{file: name}
+}
+
+var scenarios = []struct {
+	name  string
+	steps []step
+}{
+	{"store_stdin", []step{
+		pipe("hello world\n", "store"),
+		pipe("second\nline", "store"),
+		rem("list"),
+	}},
+	{"get_stdout", []step{
+		pipe("first item\n", "store"),
+		pipe("second item\n", "store"),
+		rem("get", "0"),
+		rem("get", "1"),
+		rem("get", "--json", "1"),
+	}},
+	{"get_file", []step{
+		pipe("saved to a file\n", "store"),
+		rem("get", "0", "out.txt"),
+		cat("out.txt"),
+	}},
+	{"search", []step{
+		pipe("hello world\n", "store"),
+		pipe("goodbye world\n", "store"),
+		rem("search", "hello"),
+		rem("search", "--count", "world"),
+		rem("search", "nomatch"),
+	}},
+	{"config", []step{
+		rem("config", "set", "timezone", "UTC"),
+		rem("config", "get", "timezone"),
+		rem("config", "set", "timezone", "Mars/Olympus_Mons"),
+		rem("config", "get", "no_such_key"),
+	}},
+	{"clear_force", []step{
+		pipe("one\n", "store"),
+		pipe("two\n", "store"),
+		rem("clear", "--force"),
+		rem("list"),
+		rem("get", "0"),
+	}},
+	{"audit_log", []step{
+		pipe("one\n", "store"),
+		pipe("two\n", "store"),
+		rem("clear", "--force"),
+		rem("audit", "list"),
+		rem("config", "set", "audit_keep_days", "0"),
+		pipe("three\n", "store"),
+		rem("clear", "--force"),
+		rem("audit", "list", "--limit", "1"),
+	}},
+	{"index_out_of_range", []step{
+		pipe("only item\n", "store"),
+		rem("get", "9"),
+		rem("info", "5"),
+	}},
+	{"unknown_ref", []step{
+		pipe("only item\n", "store"),
+		rem("get", "@zzzz"),
+	}},
+	{"invalid_arguments", []step{
+		rem("bogus"),
+		rem("list", "--no-such-flag"),
+	}},
+	{"stale_generation", []step{
+		pipe("first\n", "store"),
+		rem("config", "get", "generation"),
+		pipe("second\n", "store"),
+		rem("--expect-generation", "1", "get", "0"),
+		rem("--expect-generation", "2", "get", "0"),
+	}},
+	{"list_json_and_title", []step{
+		pipe("untitled content\n", "store"),
+		rem("list", "--json"),
+		rem("title", "0", "Renamed"),
+		rem("list"),
+	}},
+	{"list_preview", []step{
+		pipe("\n\n  first line\nsecond line\n", "store", "--title", "notes"),
+		pipe("\x00\x01\x02binary", "store"),
+		rem("list", "--preview"),
+	}},
+}
+
+func TestScenarios(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping end-to-end tests in -short mode")
+	}
+	for _, sc := range scenarios {
+		t.Run(sc.name, func(t *testing.T) {
+			t.Parallel()
+			box := newSandbox(t)
+			var transcript strings.Builder
+			for _, s := range sc.steps {
+				box.run(t, s, &transcript)
+			}
+			checkGolden(t, sc.name, box.normalize(transcript.String()))
+		})
+	}
+}
+
+// sandbox is a scratch directory standing in for the user's home, with the
+// environment rem runs in there
+type sandbox struct {
+	dir string
+	env []string
+}
+
+func newSandbox(t *testing.T) *sandbox {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	if err := os.Mkdir(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Only what rem needs, so the developer's own config, database, and
+	// clipboard can't leak into a run
+	return &sandbox{dir: dir, env: []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + home,
+		"XDG_CONFIG_HOME=" + filepath.Join(home, ".config"),
+		"XDG_DATA_HOME=" + filepath.Join(home, ".local", "share"),
+		"REM_DB=" + filepath.Join(dir, "rem.db"),
+		"TZ=UTC",
+	}}
+}
+
+// run performs s in the sandbox, appending what it printed and its exit
+// code, if not 0, to transcript
+func (b *sandbox) run(t *testing.T, s step, transcript *strings.Builder) {
+	t.Helper()
+	if s.file != "" {
+		data, err := os.ReadFile(filepath.Join(b.dir, s.file))
+		if err != nil {
+			t.Fatalf("read %s: %v", s.file, err)
+		}
+		fmt.Fprintf(transcript, "$ cat %s\n%s", s.file, data)
+		return
+	}
+
+	fmt.Fprintf(transcript, "$ rem %s\n", strings.Join(s.args, " "))
+	if s.stdin != "" {
+		fmt.Fprintf(transcript, "[stdin %q]\n", s.stdin)
+	}
+
+	// A command that unexpectedly waits for a terminal shouldn't hang the run
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, remBin, s.args...)
+	cmd.Dir = b.dir
+	cmd.Env = b.env
+	cmd.Stdin = strings.NewReader(s.stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("rem %s: %v", strings.Join(s.args, " "), err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("rem %s: timed out", strings.Join(s.args, " "))
+	}
+
+	transcript.Write(stdout.Bytes())
+	if stderr.Len() > 0 {
+		fmt.Fprintf(transcript, "[stderr]\n%s", stderr.Bytes())
+	}
+	if code := cmd.ProcessState.ExitCode(); code != 0 {
+		fmt.Fprintf(transcript, "[exit %d]\n", code)
+	}
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d\d-\d\d[T ]\d\d:\d\d(:\d\d(\.\d+)?)?(Z|[+-]\d\d:\d\d)?`)
+	agePattern       = regexp.MustCompile(`\b\d+[smhd] ago\b|\bjust now\b`)
+)
+
+// normalize replaces what differs from run to run, the sandbox path and
+// times, with placeholders
+func (b *sandbox) normalize(s string) string {
+	s = strings.ReplaceAll(s, b.dir, "$DIR")
+	s = timestampPattern.ReplaceAllString(s, "<time>")
+	return agePattern.ReplaceAllString(s, "<age>")
+}
+
+// checkGolden compares got with testdata/name.golden, or rewrites it under
+// -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("transcript differs from %s (run with -update to accept it)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
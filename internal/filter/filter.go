@@ -0,0 +1,148 @@
+// Package filter provides streaming content transformations applied to items
+// before they are stored. Filters are looked up by name, optionally with an
+// argument ("expand-tabs=4"), and chained in the order given.
+package filter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Filter transforms a content stream
+type Filter interface {
+	// Wrap returns a reader that yields r's content transformed
+	Wrap(r io.Reader) io.Reader
+
+	// Name returns the filter's spec, including its argument if any
+	Name() string
+}
+
+// constructor builds a filter from the argument after "=" (empty if none)
+type constructor func(arg string) (Filter, error)
+
+// registry maps filter names to their constructors
+var registry = map[string]constructor{
+	"strip-ansi":    noArg("strip-ansi", func() transformer { return &stripANSI{} }),
+	"expand-tabs":   newExpandTabs,
+	"dos2unix":      noArg("dos2unix", func() transformer { return &dos2unix{} }),
+	"trim-trailing": noArg("trim-trailing", func() transformer { return &trimTrailing{} }),
+}
+
+// Names returns the names of all registered filters, sorted
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse builds a filter from a spec of the form "name" or "name=arg"
+func Parse(spec string) (Filter, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(spec), "=")
+	newFilter, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown filter '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return newFilter(arg)
+}
+
+// ParseChain builds filters from specs, skipping empty ones
+func ParseChain(specs []string) ([]Filter, error) {
+	var filters []Filter
+	for _, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		f, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// ParseList builds filters from a comma-separated list of specs, as stored
+// in the default_filters config value
+func ParseList(list string) ([]Filter, error) {
+	return ParseChain(strings.Split(list, ","))
+}
+
+// Chain wraps r with each filter in order, so the first filter sees the
+// original content
+func Chain(r io.Reader, filters []Filter) io.Reader {
+	for _, f := range filters {
+		r = f.Wrap(r)
+	}
+	return r
+}
+
+// transformer is a streaming byte-at-a-time transform. Keeping state between
+// calls lets sequences split across Read calls be handled like any other.
+type transformer interface {
+	// step appends the output for input byte b to dst
+	step(dst []byte, b byte) []byte
+
+	// flush appends any output held back when the input ends
+	flush(dst []byte) []byte
+}
+
+// transformFilter is a Filter backed by a transformer
+type transformFilter struct {
+	name        string
+	transformer func() transformer
+}
+
+// noArg returns a constructor for a filter that takes no argument
+func noArg(name string, newTransformer func() transformer) constructor {
+	return func(arg string) (Filter, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("filter '%s' does not take an argument", name)
+		}
+		return &transformFilter{name: name, transformer: newTransformer}, nil
+	}
+}
+
+func (f *transformFilter) Name() string { return f.name }
+
+func (f *transformFilter) Wrap(r io.Reader) io.Reader {
+	return &transformReader{src: r, t: f.transformer(), buf: make([]byte, 32*1024)}
+}
+
+// transformReader applies a transformer to everything read from src
+type transformReader struct {
+	src io.Reader
+	t   transformer
+	buf []byte // input read from src
+	out []byte // transformed output not yet returned
+	off int    // start of unread output in out
+	err error  // error from src, returned once out is drained
+}
+
+func (r *transformReader) Read(p []byte) (int, error) {
+	for r.off == len(r.out) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.out, r.off = r.out[:0], 0
+
+		n, err := r.src.Read(r.buf)
+		for _, b := range r.buf[:n] {
+			r.out = r.t.step(r.out, b)
+		}
+		if err != nil {
+			if err == io.EOF {
+				r.out = r.t.flush(r.out)
+			}
+			r.err = err
+		}
+	}
+
+	n := copy(p, r.out[r.off:])
+	r.off += n
+	return n, nil
+}
@@ -0,0 +1,118 @@
+package filter
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// applyAll runs f over input fed whole, one byte per Read, and split at
+// every position, and fails the test unless each yields want
+func applyAll(t *testing.T, spec, input, want string) {
+	t.Helper()
+	f, err := Parse(spec)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", spec, err)
+	}
+
+	check := func(how string, r io.Reader) {
+		t.Helper()
+		got, err := io.ReadAll(f.Wrap(r))
+		if err != nil {
+			t.Fatalf("%s %s: read error: %v", spec, how, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s %s on %q = %q, want %q", spec, how, input, got, want)
+		}
+	}
+
+	check("whole", strings.NewReader(input))
+	check("one byte at a time", iotest.OneByteReader(strings.NewReader(input)))
+	for i := 1; i < len(input); i++ {
+		check("split", io.MultiReader(strings.NewReader(input[:i]), strings.NewReader(input[i:])))
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{"plain text", "plain text"},
+		{"\x1b[31mred\x1b[0m", "red"},
+		{"\x1b[1;38;5;208mbold orange\x1b[m done", "bold orange done"},
+		{"\x1b]0;window title\x07after", "after"},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"\x1b(Bcharset", "charset"},
+		{"\x1bMreverse index", "reverse index"},
+		{"unterminated \x1b[31", "unterminated "},
+		{"café \x1b[2K✓", "café ✓"},
+	}
+	for _, tt := range tests {
+		applyAll(t, "strip-ansi", tt.input, tt.want)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	applyAll(t, "expand-tabs", "a\tb", "a       b")
+	applyAll(t, "expand-tabs=4", "\tx\n12\ty\nabcd\tz", "    x\n12  y\nabcd    z")
+	applyAll(t, "expand-tabs=4", "é\tx", "é   x")
+	applyAll(t, "expand-tabs=2", "a\r\tb", "a\r  b")
+}
+
+func TestDos2Unix(t *testing.T) {
+	applyAll(t, "dos2unix", "one\r\ntwo\r\n", "one\ntwo\n")
+	applyAll(t, "dos2unix", "lone\rcr\r\r\n", "lone\rcr\r\n")
+	applyAll(t, "dos2unix", "ends with cr\r", "ends with cr\r")
+	applyAll(t, "dos2unix", "unix\n", "unix\n")
+}
+
+func TestTrimTrailing(t *testing.T) {
+	applyAll(t, "trim-trailing", "a  \nb\t\t\nc", "a\nb\nc")
+	applyAll(t, "trim-trailing", "keep  inner \t\n", "keep  inner\n")
+	applyAll(t, "trim-trailing", "crlf   \r\nnext\r\n", "crlf\r\nnext\r\n")
+	applyAll(t, "trim-trailing", "last line   ", "last line")
+	applyAll(t, "trim-trailing", "   \n\n", "\n\n")
+}
+
+func TestParse(t *testing.T) {
+	f, err := Parse("expand-tabs=4")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if f.Name() != "expand-tabs=4" {
+		t.Errorf("Name() = %q, want expand-tabs=4", f.Name())
+	}
+
+	for _, spec := range []string{"nope", "expand-tabs=0", "expand-tabs=x", "strip-ansi=1"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+	if _, err := Parse("nope"); err == nil || !strings.Contains(err.Error(), "strip-ansi") {
+		t.Errorf("Unknown filter error should list the available filters, got %v", err)
+	}
+}
+
+func TestChain(t *testing.T) {
+	filters, err := ParseList("strip-ansi, dos2unix,,trim-trailing")
+	if err != nil {
+		t.Fatalf("ParseList error: %v", err)
+	}
+	if len(filters) != 3 {
+		t.Fatalf("ParseList returned %d filters, want 3", len(filters))
+	}
+
+	input := "\x1b[32mok\x1b[0m   \r\nnext\t\r\n"
+	got, err := io.ReadAll(Chain(iotest.OneByteReader(strings.NewReader(input)), filters))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if want := "ok\nnext\n"; string(got) != want {
+		t.Errorf("Chain = %q, want %q", got, want)
+	}
+
+	// Errors from the source are passed through
+	_, err = io.ReadAll(Chain(iotest.ErrReader(io.ErrUnexpectedEOF), filters))
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("Chain error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,176 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// stripANSI removes ANSI escape sequences: CSI (ESC [ ... final), OSC
+// (ESC ] ... BEL or ESC \), and two-byte escapes with optional
+// intermediate bytes (ESC ( B)
+type stripANSI struct {
+	state ansiState
+}
+
+type ansiState int
+
+const (
+	ansiText   ansiState = iota
+	ansiEscape           // after ESC
+	ansiCSI              // inside ESC [
+	ansiOSC              // inside ESC ]
+	ansiOSCEsc           // ESC seen inside an OSC, expecting \
+	ansiInter            // intermediate bytes of a two-byte escape
+)
+
+func (s *stripANSI) step(dst []byte, b byte) []byte {
+	switch s.state {
+	case ansiText:
+		if b == 0x1b {
+			s.state = ansiEscape
+			return dst
+		}
+		return append(dst, b)
+	case ansiEscape:
+		switch {
+		case b == '[':
+			s.state = ansiCSI
+		case b == ']':
+			s.state = ansiOSC
+		case b >= 0x20 && b <= 0x2f:
+			s.state = ansiInter
+		default:
+			s.state = ansiText
+		}
+	case ansiCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.state = ansiText
+		}
+	case ansiOSC:
+		switch b {
+		case 0x07:
+			s.state = ansiText
+		case 0x1b:
+			s.state = ansiOSCEsc
+		}
+	case ansiOSCEsc:
+		if b == '\\' {
+			s.state = ansiText
+		} else {
+			s.state = ansiOSC
+		}
+	case ansiInter:
+		if b >= 0x30 && b <= 0x7e {
+			s.state = ansiText
+		}
+	}
+	return dst
+}
+
+// flush drops an unterminated escape sequence
+func (s *stripANSI) flush(dst []byte) []byte {
+	return dst
+}
+
+// expandTabs replaces tabs with spaces up to the next multiple of width columns
+type expandTabs struct {
+	width  int
+	column int
+}
+
+// newExpandTabs builds expand-tabs[=N]; N defaults to 8
+func newExpandTabs(arg string) (Filter, error) {
+	width := 8
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("filter 'expand-tabs' needs a positive tab width, got '%s'", arg)
+		}
+		width = n
+	}
+	return &transformFilter{
+		name:        fmt.Sprintf("expand-tabs=%d", width),
+		transformer: func() transformer { return &expandTabs{width: width} },
+	}, nil
+}
+
+func (e *expandTabs) step(dst []byte, b byte) []byte {
+	switch {
+	case b == '\t':
+		spaces := e.width - e.column%e.width
+		for i := 0; i < spaces; i++ {
+			dst = append(dst, ' ')
+		}
+		e.column += spaces
+		return dst
+	case b == '\n' || b == '\r':
+		e.column = 0
+	case b&0xc0 != 0x80:
+		// Count each character once, not each UTF-8 continuation byte
+		e.column++
+	}
+	return append(dst, b)
+}
+
+func (e *expandTabs) flush(dst []byte) []byte {
+	return dst
+}
+
+// dos2unix converts CRLF line endings to LF; lone CRs are kept
+type dos2unix struct {
+	pendingCR bool
+}
+
+func (d *dos2unix) step(dst []byte, b byte) []byte {
+	if d.pendingCR {
+		d.pendingCR = false
+		if b == '\n' {
+			return append(dst, '\n')
+		}
+		dst = append(dst, '\r')
+	}
+	if b == '\r' {
+		d.pendingCR = true
+		return dst
+	}
+	return append(dst, b)
+}
+
+func (d *dos2unix) flush(dst []byte) []byte {
+	if d.pendingCR {
+		dst = append(dst, '\r')
+	}
+	return dst
+}
+
+// trimTrailing strips spaces and tabs at the end of each line. A CR before
+// the LF is kept, so CRLF files stay CRLF.
+type trimTrailing struct {
+	held []byte // whitespace (and CRs) not yet known to be trailing
+}
+
+func (t *trimTrailing) step(dst []byte, b byte) []byte {
+	switch b {
+	case ' ', '\t', '\r':
+		t.held = append(t.held, b)
+		return dst
+	case '\n':
+		if n := len(t.held); n > 0 && t.held[n-1] == '\r' {
+			dst = append(dst, '\r')
+		}
+		t.held = t.held[:0]
+		return append(dst, '\n')
+	default:
+		dst = append(dst, t.held...)
+		t.held = t.held[:0]
+		return append(dst, b)
+	}
+}
+
+// flush drops trailing whitespace on the last line
+func (t *trimTrailing) flush(dst []byte) []byte {
+	if n := len(t.held); n > 0 && t.held[n-1] == '\r' {
+		dst = append(dst, '\r')
+	}
+	return dst
+}
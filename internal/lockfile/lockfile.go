@@ -0,0 +1,120 @@
+// Package lockfile implements an advisory lock held by one process at a
+// time. The lock is an OS file lock held on the open lock file for as long
+// as the Lock is, so it ends with its process however that exits. The file
+// also records the holder's pid and start time, so a process that finds it
+// taken can say by whom.
+package lockfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Holder is the process recorded in a lock file
+type Holder struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+}
+
+// HeldError is returned by Acquire when another process holds the lock.
+// Holder is zero if the holder hadn't recorded itself yet.
+type HeldError struct {
+	Holder Holder
+}
+
+func (e *HeldError) Error() string {
+	if e.Holder.PID <= 0 {
+		return "locked by another process"
+	}
+	return fmt.Sprintf("locked by pid %d since %s", e.Holder.PID, e.Holder.Started.Format(time.DateTime))
+}
+
+// errLocked is returned by lockFile when another process holds the lock
+var errLocked = errors.New("lock is held")
+
+// Lock is a lock file held by this process
+type Lock struct {
+	path string
+	file *os.File // holds the OS lock while open
+}
+
+// Acquire locks the lock file at path, creating it if needed, and records
+// this process in it. If another process holds the lock, Acquire returns a
+// *HeldError naming that process.
+func Acquire(path string) (*Lock, error) {
+	holder := Holder{PID: os.Getpid(), Started: time.Now()}
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lock: %w", err)
+	}
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock %s: %w", path, err)
+		}
+		if err := lockFile(f); err != nil {
+			f.Close()
+			if errors.Is(err, errLocked) {
+				current, _ := Read(path)
+				return nil, &HeldError{Holder: current}
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		// The last holder may have removed the file between our open and
+		// lock, leaving us the lock on a file no one else will open
+		if current, err := os.Stat(path); err != nil || !sameFile(f, current) {
+			f.Close()
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to check lock %s: %w", path, err)
+			}
+			continue
+		}
+
+		if err := f.Truncate(0); err == nil {
+			_, err = f.WriteAt(data, 0)
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to write lock %s: %w", path, err)
+		}
+		return &Lock{path: path, file: f}, nil
+	}
+}
+
+// sameFile reports whether f is the file info describes
+func sameFile(f *os.File, info os.FileInfo) bool {
+	opened, err := f.Stat()
+	return err == nil && os.SameFile(opened, info)
+}
+
+// Read returns the process recorded in the lock file at path
+func Read(path string) (Holder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Holder{}, err
+	}
+	var holder Holder
+	if err := json.Unmarshal(data, &holder); err != nil || holder.PID <= 0 {
+		return Holder{}, fmt.Errorf("invalid lock %s", path)
+	}
+	return holder, nil
+}
+
+// Release removes the lock file and releases the lock. Releasing a lock
+// again does nothing.
+func (l *Lock) Release() error {
+	if l.file == nil {
+		return nil
+	}
+	err := removeAndUnlock(l.file, l.path)
+	l.file = nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock %s: %w", l.path, err)
+	}
+	return nil
+}
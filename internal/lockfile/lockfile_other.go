@@ -0,0 +1,18 @@
+//go:build !unix && !windows
+
+package lockfile
+
+import "os"
+
+// lockFile is not implemented on this platform, so the lock is never
+// found held
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// removeAndUnlock removes the lock file and closes f
+func removeAndUnlock(f *os.File, path string) error {
+	err := os.Remove(path)
+	f.Close()
+	return err
+}
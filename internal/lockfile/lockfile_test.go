@@ -0,0 +1,123 @@
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquire_Contention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tui.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	holder, err := Read(path)
+	if err != nil || holder.PID != os.Getpid() || holder.Started.IsZero() {
+		t.Fatalf("Read() = %+v, %v; want this process", holder, err)
+	}
+
+	// A second holder is refused with the first one's pid
+	_, err = Acquire(path)
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder.PID != os.Getpid() {
+		t.Fatalf("second Acquire() error = %v, want a HeldError naming pid %d", err, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the lock file removed, got %v", err)
+	}
+	lock, err = Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire() after Release() error = %v", err)
+	}
+	lock.Release()
+}
+
+func TestAcquire_Stale(t *testing.T) {
+	// A file left by a holder that died, or one that can't be read, holds
+	// no lock and is taken over
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"dead holder": `{"pid":4242,"started":"2024-05-01T12:00:00Z"}`,
+		"unreadable":  "not json",
+	} {
+		path := filepath.Join(dir, "tui.lock")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		lock, err := Acquire(path)
+		if err != nil {
+			t.Fatalf("%s: Acquire() error = %v, want the stale lock replaced", name, err)
+		}
+		if holder, err := Read(path); err != nil || holder.PID != os.Getpid() {
+			t.Errorf("%s: lock held by %+v (%v), want this process", name, holder, err)
+		}
+		lock.Release()
+	}
+
+	// The lock ends with its holder's open file, even without a Release
+	path := filepath.Join(dir, "crashed.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.file.Close()
+	if _, err := Acquire(path); err != nil {
+		t.Errorf("Acquire() after the holder closed its file error = %v", err)
+	}
+}
+
+func TestAcquire_HolderNotRecordedYet(t *testing.T) {
+	// A holder that has locked the file but not yet written it still holds
+	// the lock
+	path := filepath.Join(t.TempDir(), "tui.lock")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	_, err = Acquire(path)
+	var held *HeldError
+	if !errors.As(err, &held) || held.Holder.PID != 0 || err.Error() != "locked by another process" {
+		t.Fatalf("Acquire() error = %v, want a HeldError without a holder", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the held lock file kept, got %v", err)
+	}
+}
+
+func TestRelease_Twice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tui.lock")
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	other, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Release()
+
+	// A second Release leaves the new holder's lock alone
+	if err := lock.Release(); err != nil {
+		t.Fatalf("second Release() error = %v", err)
+	}
+	if holder, err := Read(path); err != nil || holder.PID != os.Getpid() {
+		t.Errorf("second Release() removed the new lock: %+v, %v", holder, err)
+	}
+	if _, err := Acquire(path); err == nil {
+		t.Error("expected the new holder to still hold the lock")
+	}
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package lockfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on f without waiting, returning
+// errLocked if another open file holds one
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// removeAndUnlock removes the lock file while still holding its lock, so a
+// process waiting on the old file finds it gone, then closes f, which
+// releases the lock
+func removeAndUnlock(f *os.File, path string) error {
+	err := os.Remove(path)
+	f.Close()
+	return err
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package lockfile
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is where the locked byte lies, past any content, so other
+// processes can still read the holder recorded at the start of the file
+const lockOffset = 1 << 32
+
+// lockFile takes an exclusive lock on a byte of f without waiting,
+// returning errLocked if another handle holds it
+func lockFile(f *os.File) error {
+	overlapped := &windows.Overlapped{Offset: lockOffset & 0xffffffff, OffsetHigh: lockOffset >> 32}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, overlapped)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errLocked
+	}
+	return err
+}
+
+// removeAndUnlock closes f, which releases the lock, then removes the lock
+// file. Windows can't remove a file that is open, so this fails, harmlessly,
+// when another process has opened it since.
+func removeAndUnlock(f *os.File, path string) error {
+	f.Close()
+	os.Remove(path)
+	return nil
+}
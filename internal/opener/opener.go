@@ -0,0 +1,106 @@
+// Package opener opens URLs with the platform's default handler: open on
+// macOS, rundll32 on Windows, and xdg-open elsewhere.
+package opener
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ExecFunc starts a command without waiting for it to finish
+type ExecFunc func(name string, args ...string) error
+
+// Opener opens URLs by running the platform's opener command
+type Opener struct {
+	goos string
+	exec ExecFunc
+}
+
+// New creates an Opener for the running platform
+func New() *Opener {
+	return NewWithExec(runtime.GOOS, start)
+}
+
+// NewWithExec creates an Opener that runs the opener command of goos
+// through exec, so tests can see the command instead of running it
+func NewWithExec(goos string, exec ExecFunc) *Opener {
+	return &Opener{goos: goos, exec: exec}
+}
+
+// Open opens url in the default handler. Only http and https URLs are
+// opened, so content can't make rem run a local file.
+func (o *Opener) Open(url string) error {
+	if !IsURL(url) {
+		return fmt.Errorf("not an http(s) URL: %q", url)
+	}
+	name, args := Command(o.goos, url)
+	if err := o.exec(name, args...); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	return nil
+}
+
+// Command returns the command that opens url on goos. Windows goes through
+// rundll32 rather than cmd's start, which would treat & in a URL as a
+// command separator.
+func Command(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	}
+	return "xdg-open", []string{url}
+}
+
+// start runs a command in the background, reaping it once it exits
+func start(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// urlPattern matches http(s) URLs conservatively: a host must follow the
+// scheme, and whitespace, quotes and angle brackets end the URL
+var urlPattern = regexp.MustCompile("https?://[A-Za-z0-9\\[][^\\s<>\"'`]*")
+
+// FindURLs returns the byte ranges of the URLs in s. Punctuation that
+// usually ends a sentence, and closing brackets without an opening one in
+// the URL, are left off the end.
+func FindURLs(s string) [][2]int {
+	var found [][2]int
+	for _, match := range urlPattern.FindAllStringIndex(s, -1) {
+		end := match[0] + len(trimURL(s[match[0]:match[1]]))
+		found = append(found, [2]int{match[0], end})
+	}
+	return found
+}
+
+// IsURL reports whether s is exactly one URL
+func IsURL(s string) bool {
+	found := FindURLs(s)
+	return len(found) == 1 && found[0] == [2]int{0, len(s)}
+}
+
+// trimURL removes trailing punctuation that is more likely prose than URL
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(".,:;!?", last) >= 0:
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+		case last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+		case last == '}' && strings.Count(url, "{") < strings.Count(url, "}"):
+		default:
+			return url
+		}
+		url = url[:len(url)-1]
+	}
+	return url
+}
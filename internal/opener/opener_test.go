@@ -0,0 +1,60 @@
+package opener
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindURLs(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"see https://example.com/a.", []string{"https://example.com/a"}},
+		{"(docs at http://example.com/wiki/Go_(language))", []string{"http://example.com/wiki/Go_(language)"}},
+		{`<a href="https://example.com/?q=1&r=2">`, []string{"https://example.com/?q=1&r=2"}},
+		{"two: https://a.example, https://b.example/x?y", []string{"https://a.example", "https://b.example/x?y"}},
+		{"not links: http:// ftp://example.com https:/x", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range FindURLs(tt.text) {
+			got = append(got, tt.text[r[0]:r[1]])
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("FindURLs(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if !IsURL("https://example.com/x") || IsURL("https://example.com/x and more") || IsURL("") {
+		t.Error("IsURL should accept exactly one URL")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for goos, want := range map[string]string{
+		"darwin":  "open [https://example.com/?a=1&b=2]",
+		"linux":   "xdg-open [https://example.com/?a=1&b=2]",
+		"windows": "rundll32 [url.dll,FileProtocolHandler https://example.com/?a=1&b=2]",
+	} {
+		var got string
+		o := NewWithExec(goos, func(name string, args ...string) error {
+			got = fmt.Sprint(name, " ", args)
+			return nil
+		})
+		if err := o.Open("https://example.com/?a=1&b=2"); err != nil {
+			t.Fatalf("%s: Open() error = %v", goos, err)
+		}
+		if got != want {
+			t.Errorf("%s: ran %q, want %q", goos, got, want)
+		}
+	}
+
+	o := NewWithExec("linux", func(string, ...string) error {
+		t.Error("expected a non-URL not to be opened")
+		return nil
+	})
+	if err := o.Open("/etc/passwd"); err == nil {
+		t.Error("expected opening a path to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package queue
+
+import "sync"
+
+// EventType is the kind of change an Event reports
+type EventType int
+
+const (
+	EventCreated EventType = iota + 1
+	EventDeleted
+	EventCleared
+	EventUpdated
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventCreated:
+		return "created"
+	case EventDeleted:
+		return "deleted"
+	case EventCleared:
+		return "cleared"
+	case EventUpdated:
+		return "updated"
+	}
+	return "unknown"
+}
+
+// Event reports a change made through a QueueManager. ID and Title are
+// those of the item changed, and are zero for EventCleared.
+type Event struct {
+	Type  EventType
+	ID    uint
+	Title string
+}
+
+// EventBufferSize is how many events a subscriber can fall behind by before
+// the oldest are dropped
+const EventBufferSize = 64
+
+// eventBus delivers events to subscribers without ever blocking the
+// publisher. The zero value has no subscribers.
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// subscribe adds a subscriber and returns its channel and a function that
+// removes it and closes the channel
+func (b *eventBus) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, EventBufferSize)
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[chan Event]struct{})
+	}
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// active reports whether anyone is subscribed, so publishers can skip the
+// work of describing a change nobody will see
+func (b *eventBus) active() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs) > 0
+}
+
+// publish sends event to every subscriber. A subscriber whose buffer is full
+// loses its oldest event to make room.
+func (b *eventBus) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		// Only publish sends, and it holds the lock, so after taking one
+		// event out there is room for this one
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel of the changes made through this QueueManager
+// from now on, and a function to stop them that also closes the channel.
+// Events are sent after the change is stored. A subscriber that falls more
+// than EventBufferSize events behind loses the oldest ones; storing never
+// waits for a subscriber. Changes made by other processes sharing the
+// database, or directly through the store, are not reported, so a caller
+// that needs to see those still has to poll.
+func (qm *QueueManager) Subscribe() (<-chan Event, func()) {
+	return qm.events.subscribe()
+}
@@ -2,8 +2,11 @@ package queue
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
 	"time"
 
 	"github.com/yiblet/rem/internal/store"
@@ -13,11 +16,38 @@ const (
 	DefaultMaxQueueSize = 255
 )
 
+// ErrIndexOutOfRange is wrapped by an IndexError for an index with no item
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// IndexError reports an index outside the queue, along with the queue's size
+type IndexError struct {
+	Index int
+	Size  int
+}
+
+func (e *IndexError) Error() string {
+	switch e.Size {
+	case 0:
+		return "queue is empty"
+	case 1:
+		return fmt.Sprintf("index %d out of range (queue has 1 item, valid index 0)", e.Index)
+	}
+	return fmt.Sprintf("index %d out of range (queue has %d items, valid indexes 0-%d)", e.Index, e.Size, e.Size-1)
+}
+
+func (e *IndexError) Unwrap() error {
+	return ErrIndexOutOfRange
+}
+
 // QueueManager manages the persistent LIFO queue using a store interface.
 // It provides business logic for queue operations, title generation, and cleanup.
 type QueueManager struct {
-	store        store.Store
-	historyLimit int
+	store            store.Store
+	historyLimit     int
+	coalesceWindow   time.Duration
+	titleStrategy    TitleStrategy
+	titleSampleBytes int
+	events           eventBus
 }
 
 // NewQueueManager creates a new queue manager with the given store.
@@ -32,51 +62,142 @@ func NewQueueManagerWithConfig(s store.Store, historyLimit int) (*QueueManager,
 	}
 
 	qm := &QueueManager{
-		store:        s,
-		historyLimit: historyLimit,
+		store:            s,
+		historyLimit:     historyLimit,
+		titleSampleBytes: DefaultTitleSampleBytes,
 	}
 
 	return qm, nil
 }
 
+// SetCoalesceWindow makes Enqueue return the newest item, rather than store
+// a copy of it, for content identical to it enqueued within window of it.
+// This absorbs a hook that runs rem store twice per command. 0 turns it off.
+func (qm *QueueManager) SetCoalesceWindow(window time.Duration) {
+	qm.coalesceWindow = window
+}
+
+// SetTitleStrategy sets which line of the content a generated title is
+// taken from
+func (qm *QueueManager) SetTitleStrategy(strategy TitleStrategy) {
+	qm.titleStrategy = strategy
+}
+
+// SetTitleSampleBytes sets how much of the content a title is generated
+// from, at most store.MaxContentPrefix. More makes the last line of longer
+// output reachable; the sample is held in memory while storing.
+func (qm *QueueManager) SetTitleSampleBytes(n int) {
+	if n > 0 {
+		qm.titleSampleBytes = min(n, store.MaxContentPrefix)
+	}
+}
+
+// EvictedInfo identifies an item removed to keep the queue within the
+// history limit
+type EvictedInfo struct {
+	ID    uint
+	Title string
+}
+
+// EnqueueResult is the outcome of storing an item: the item, and the items
+// it pushed out of the queue, oldest first
+type EnqueueResult struct {
+	Item    *store.HistoryItem
+	Evicted []EvictedInfo
+}
+
 // Enqueue adds content with an optional title to the queue.
-// If title is empty, generates title from first 4KB of content.
+// If title is empty, one is generated from the title sample, the first
+// 4KiB of content unless SetTitleSampleBytes changes it, with the strategy
+// set by SetTitleStrategy (title_sample_bytes and title_strategy in the CLI).
 // Returns the created item with generated ID and metadata.
 func (qm *QueueManager) Enqueue(content io.Reader, title string) (*store.HistoryItem, error) {
-	// 1. Peek first chunk for title generation if needed
-	var finalReader io.Reader
-	var peekBuf []byte
+	result, err := qm.enqueue(content, title, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result.Item, nil
+}
 
-	if title == "" {
-		// Buffer first 4KB for title generation
-		peekBuf = make([]byte, 4096)
-		n, err := io.ReadFull(content, peekBuf)
-		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
-			return nil, fmt.Errorf("failed to read content: %w", err)
-		}
-		peekBuf = peekBuf[:n]
+// EnqueueWithResult is Enqueue that also reports the items evicted to make
+// room for the new one.
+func (qm *QueueManager) EnqueueWithResult(content io.Reader, title string) (*EnqueueResult, error) {
+	return qm.enqueue(content, title, nil)
+}
 
-		// Detect binary before generating title
-		binary := isBinary(peekBuf)
+// EnqueueFromPath stores the file at path, recording its name, mode, and
+// modification time so that it can be restored later.
+func (qm *QueueManager) EnqueueFromPath(path string, title string) (*store.HistoryItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
 
-		// Generate title from peeked content
-		title = GenerateTitle(peekBuf, binary)
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	return qm.EnqueueFile(file, title, info)
+}
 
-		// Create reader that replays peeked content + rest of stream
-		finalReader = io.MultiReader(bytes.NewReader(peekBuf), content)
-	} else {
-		finalReader = content
+// EnqueueFile is Enqueue for content read from the file described by info,
+// whose metadata is recorded on the item. Callers that transform the file's
+// content on the way in use this rather than EnqueueFromPath.
+func (qm *QueueManager) EnqueueFile(content io.Reader, title string, info fs.FileInfo) (*store.HistoryItem, error) {
+	result, err := qm.enqueue(content, title, info)
+	if err != nil {
+		return nil, err
 	}
+	return result.Item, nil
+}
 
-	// 2. Truncate title to 80 chars
-	title = TruncateTitle(title, 80)
+// EnqueueFileWithResult is EnqueueFile that also reports the items evicted
+// to make room for the new one.
+func (qm *QueueManager) EnqueueFileWithResult(content io.Reader, title string, info fs.FileInfo) (*EnqueueResult, error) {
+	return qm.enqueue(content, title, info)
+}
 
-	// 3. Create store input (store handles chunking, hashing, binary detection)
+// enqueue stores content, recording info's metadata when it is not nil
+func (qm *QueueManager) enqueue(content io.Reader, title string, info fs.FileInfo) (*EnqueueResult, error) {
+	// 1. Generate, sanitize, and truncate the title
+	title, finalReader, err := qm.storedTitle(content, title)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Create store input (store handles chunking, hashing, binary detection)
 	input := &store.CreateHistoryInput{
 		Title:     title,
 		Content:   finalReader,
 		Timestamp: time.Now(),
 	}
+	if info != nil {
+		input.OriginalName = info.Name()
+		input.OriginalModTime = info.ModTime()
+		input.OriginalMode = info.Mode()
+	}
+
+	// 3. A repeat of the newest item within the coalesce window returns it,
+	// decided before anything is written
+	newest, err := qm.coalesceCandidate()
+	if err != nil {
+		return nil, err
+	}
+	if newest != nil && input.Timestamp.Sub(newest.Timestamp) <= qm.coalesceWindow {
+		repeat, rest, stored, err := qm.repeatOf(newest, input.Content)
+		if err != nil {
+			return nil, err
+		}
+		if repeat {
+			return &EnqueueResult{Item: newest}, nil
+		}
+		defer stored.Close()
+		input.Content = rest
+	}
 
 	// 4. Store in database (streaming into chunks)
 	item, err := qm.store.History().Create(input)
@@ -84,33 +205,203 @@ func (qm *QueueManager) Enqueue(content io.Reader, title string) (*store.History
 		return nil, fmt.Errorf("failed to store item: %w", err)
 	}
 
+	qm.events.publish(Event{Type: EventCreated, ID: item.ID, Title: item.Title})
+
 	// 5. Cleanup old items if over limit
-	if err := qm.cleanupOldItems(); err != nil {
+	evicted, err := qm.cleanupOldItems()
+	if err != nil {
 		return nil, fmt.Errorf("failed to cleanup: %w", err)
 	}
 
-	return item, nil
+	return &EnqueueResult{Item: item, Evicted: evicted}, nil
+}
+
+// storedTitle returns the title enqueue stores content under: title, or one
+// generated from the title sample when title is empty, sanitized and
+// truncated. The returned reader yields all of content.
+func (qm *QueueManager) storedTitle(content io.Reader, title string) (string, io.Reader, error) {
+	if title != "" {
+		return PrepareTitle(title), content, nil
+	}
+
+	peekBuf := make([]byte, qm.titleSampleBytes)
+	n, err := io.ReadFull(content, peekBuf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	peekBuf = peekBuf[:n]
+
+	// Detect binary before generating title
+	title = qm.generateTitle(peekBuf, store.IsBinary(peekBuf), err == nil)
+
+	// Replay the peeked content before the rest of the stream
+	return PrepareTitle(title), io.MultiReader(bytes.NewReader(peekBuf), content), nil
+}
+
+// coalesceCandidate returns the newest item, which content enqueued within
+// the coalesce window may repeat, or nil when coalescing is off
+func (qm *QueueManager) coalesceCandidate() (*store.HistoryItem, error) {
+	if qm.coalesceWindow <= 0 {
+		return nil, nil
+	}
+	items, err := qm.store.History().List(1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return items[0], nil
+}
+
+// repeatOf reads content for as long as it matches item's stored content
+// and reports whether it repeats it exactly. Otherwise it returns a reader
+// yielding all of content, with the matched part read again from the
+// store, and the stored content's reader to close once that one is done.
+func (qm *QueueManager) repeatOf(item *store.HistoryItem, content io.Reader) (bool, io.Reader, io.Closer, error) {
+	stored, err := qm.store.History().GetContent(item.ID)
+	if err != nil {
+		return false, nil, nil, fmt.Errorf("failed to read item %d: %w", item.ID, err)
+	}
+	const compareBytes = 32 * 1024
+	got, want := make([]byte, compareBytes), make([]byte, compareBytes)
+	var matched int64
+	for {
+		n, err := io.ReadFull(content, got)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			stored.Close()
+			return false, nil, nil, fmt.Errorf("failed to read content: %w", err)
+		}
+		m, err := io.ReadFull(stored, want)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			stored.Close()
+			return false, nil, nil, fmt.Errorf("failed to read item %d: %w", item.ID, err)
+		}
+		if n != m || !bytes.Equal(got[:n], want[:m]) {
+			if _, err := stored.Seek(0, io.SeekStart); err != nil {
+				stored.Close()
+				return false, nil, nil, fmt.Errorf("failed to read item %d: %w", item.ID, err)
+			}
+			return false, io.MultiReader(io.LimitReader(stored, matched), bytes.NewReader(got[:n]), content), stored, nil
+		}
+		if n < compareBytes {
+			return true, nil, nil, stored.Close()
+		}
+		matched += int64(n)
+	}
+}
+
+// coalesces reports whether content hashing to sha256 and enqueued at when
+// repeats newest closely enough to be returned instead of stored
+func (qm *QueueManager) coalesces(newest *store.HistoryItem, sha256 string, when time.Time) bool {
+	return newest != nil && newest.SHA256 == sha256 && when.Sub(newest.Timestamp) <= qm.coalesceWindow
+}
+
+// ListOptions selects the items queue indexes count. An index printed from
+// a list resolves to the same item only with the same options, so callers
+// pass one ListOptions value through rather than rebuilding it. The zero
+// value counts every item within the history limit.
+type ListOptions struct {
+	HideBinary bool // skip binary items
+	Limit      int  // count at most this many items; 0 means the history limit
 }
 
 // List returns all items (excludes content), ordered newest first (LIFO).
 func (qm *QueueManager) List() ([]*store.HistoryItem, error) {
-	return qm.store.History().List(qm.historyLimit)
+	return qm.ListWith(ListOptions{})
 }
 
-// Get returns an item by index (0 = newest).
-func (qm *QueueManager) Get(index int) (*store.HistoryItem, error) {
-	items, err := qm.List()
+// ListWith returns the items selected by opts, newest first. Position i in
+// the result is queue index i under opts.
+func (qm *QueueManager) ListWith(opts ListOptions) ([]*store.HistoryItem, error) {
+	items, err := qm.store.History().List(qm.historyLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.HideBinary {
+		visible := items[:0]
+		for _, item := range items {
+			if !item.IsBinary {
+				visible = append(visible, item)
+			}
+		}
+		items = visible
+	}
+	if opts.Limit > 0 && len(items) > opts.Limit {
+		items = items[:opts.Limit]
+	}
+	return items, nil
+}
+
+// ListPage returns up to limit of the items selected by opts that come
+// after the item at after, newest first; a nil after starts at the newest.
+// Unlike ListWith it doesn't stop at the history limit, so callers paging
+// through the queue stop once they have CountWith items.
+func (qm *QueueManager) ListPage(after *store.IterCursor, limit int, opts ListOptions) ([]*store.HistoryItem, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var items []*store.HistoryItem
+	err := qm.store.History().Iterate(store.IterOptions{After: after, BatchSize: limit}, func(item *store.HistoryItem) (bool, error) {
+		if opts.HideBinary && item.IsBinary {
+			return false, nil
+		}
+		items = append(items, item)
+		return len(items) == limit, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	return items, nil
+}
+
+// CountWith returns the number of items ListWith would return for opts:
+// those selected among the newest history-limit items
+func (qm *QueueManager) CountWith(opts ListOptions) (int, error) {
+	end := qm.historyLimit
+	if opts.Limit > 0 && opts.Limit < end {
+		end = opts.Limit
+	}
+	if !opts.HideBinary {
+		count, err := qm.store.History().Count()
+		if err != nil {
+			return 0, err
+		}
+		return min(count, end), nil
+	}
+	count, seen := 0, 0
+	err := qm.store.History().Iterate(store.IterOptions{}, func(item *store.HistoryItem) (bool, error) {
+		seen++
+		if !item.IsBinary {
+			count++
+		}
+		return count == end || seen == qm.historyLimit, nil
+	})
+	return count, err
+}
+
+// ResolveIndex returns the item at index (0 = newest) among the items
+// selected by opts. It is the one place indexes are turned into items.
+func (qm *QueueManager) ResolveIndex(index int, opts ListOptions) (*store.HistoryItem, error) {
+	items, err := qm.ListWith(opts)
 	if err != nil {
 		return nil, err
 	}
 
 	if index < 0 || index >= len(items) {
-		return nil, fmt.Errorf("index %d out of range (0-%d)", index, len(items)-1)
+		return nil, &IndexError{Index: index, Size: len(items)}
 	}
 
 	return items[index], nil
 }
 
+// Get returns an item by index (0 = newest).
+func (qm *QueueManager) Get(index int) (*store.HistoryItem, error) {
+	return qm.ResolveIndex(index, ListOptions{})
+}
+
 // GetContent returns a streaming reader for an item's content by ID.
 func (qm *QueueManager) GetContent(id uint) (io.ReadSeekCloser, error) {
 	return qm.store.History().GetContent(id)
@@ -118,16 +409,188 @@ func (qm *QueueManager) GetContent(id uint) (io.ReadSeekCloser, error) {
 
 // Delete removes an item by index.
 func (qm *QueueManager) Delete(index int) error {
-	item, err := qm.Get(index)
+	return qm.DeleteAt(index, ListOptions{})
+}
+
+// DeleteAt removes the item at index among the items selected by opts.
+func (qm *QueueManager) DeleteAt(index int, opts ListOptions) error {
+	item, err := qm.ResolveIndex(index, opts)
+	if err != nil {
+		return err
+	}
+	return qm.deleteItem(item)
+}
+
+// DeleteByID removes the item with the given ID.
+func (qm *QueueManager) DeleteByID(id uint) error {
+	if !qm.events.active() {
+		return qm.store.History().Delete(id)
+	}
+	// Subscribers are told the title, which is gone after the delete
+	item, err := qm.store.History().Get(id)
 	if err != nil {
 		return err
 	}
-	return qm.store.History().Delete(item.ID)
+	return qm.deleteItem(item)
+}
+
+// deleteItem removes item and reports it to subscribers
+func (qm *QueueManager) deleteItem(item *store.HistoryItem) error {
+	if err := qm.store.History().Delete(item.ID); err != nil {
+		return err
+	}
+	qm.events.publish(Event{Type: EventDeleted, ID: item.ID, Title: item.Title})
+	return nil
+}
+
+// ByID returns a view of the queue that addresses items by ID, for callers
+// such as the TUI that hold IDs rather than indexes.
+func (qm *QueueManager) ByID() *IDView {
+	return &IDView{qm: qm}
+}
+
+// IDView performs item operations by ID
+type IDView struct {
+	qm *QueueManager
+}
+
+// Delete removes the item with the given ID
+func (v *IDView) Delete(id uint) error {
+	return v.qm.DeleteByID(id)
+}
+
+// Content opens the content of the item with the given ID
+func (v *IDView) Content(id uint) (io.ReadSeekCloser, error) {
+	return v.qm.GetContent(id)
+}
+
+// Rename replaces the title of the item with the given ID
+func (v *IDView) Rename(id uint, title string) error {
+	_, err := v.qm.RenameByID(id, title)
+	return err
+}
+
+// SetNote replaces the note of the item with the given ID
+func (v *IDView) SetNote(id uint, note string) error {
+	_, err := v.qm.SetNoteByID(id, note)
+	return err
+}
+
+// Bump moves the item with the given ID to the top of the queue
+func (v *IDView) Bump(id uint) error {
+	_, err := v.qm.BumpByID(id)
+	return err
+}
+
+// IsDeleted reports whether err means the item was deleted from the store
+func (v *IDView) IsDeleted(err error) bool {
+	return errors.Is(err, store.ErrItemDeleted)
+}
+
+// Bump moves the item at index to the top of the queue (index 0) without
+// storing it again. Returns the bumped item.
+func (qm *QueueManager) Bump(index int) (*store.HistoryItem, error) {
+	item, err := qm.Get(index)
+	if err != nil {
+		return nil, err
+	}
+	return qm.BumpByID(item.ID)
+}
+
+// BumpByID moves the item with the given ID to the top of the queue by
+// making its Timestamp the current time. Content, SHA256 and CreatedAt are
+// unchanged, and the item is now the last to be evicted.
+func (qm *QueueManager) BumpByID(id uint) (*store.HistoryItem, error) {
+	if err := qm.store.History().UpdateTimestamp(id, time.Now()); err != nil {
+		return nil, err
+	}
+	return qm.updated(id)
+}
+
+// updated returns the item with the given ID after a change to it, and
+// reports the change to subscribers
+func (qm *QueueManager) updated(id uint) (*store.HistoryItem, error) {
+	item, err := qm.store.History().Get(id)
+	if err != nil {
+		return nil, err
+	}
+	qm.events.publish(Event{Type: EventUpdated, ID: item.ID, Title: item.Title})
+	return item, nil
+}
+
+// Rename replaces the title of the item at index (0 = newest).
+// Returns the item with its new title.
+func (qm *QueueManager) Rename(index int, title string) (*store.HistoryItem, error) {
+	item, err := qm.Get(index)
+	if err != nil {
+		return nil, err
+	}
+	return qm.RenameByID(item.ID, title)
+}
+
+// RenameByID replaces the title of the item with the given ID.
+// The title is sanitized and truncated the same way as in Enqueue.
+func (qm *QueueManager) RenameByID(id uint, title string) (*store.HistoryItem, error) {
+	title = PrepareTitle(title)
+	if title == "" {
+		return nil, fmt.Errorf("title cannot be empty")
+	}
+
+	if err := qm.store.History().UpdateTitle(id, title); err != nil {
+		return nil, err
+	}
+	return qm.updated(id)
+}
+
+// SetNoteByID replaces the note of the item with the given ID. The note is
+// sanitized like a title but not truncated; an empty note clears it.
+func (qm *QueueManager) SetNoteByID(id uint, note string) (*store.HistoryItem, error) {
+	if err := qm.store.History().UpdateNote(id, SanitizeTitle(note)); err != nil {
+		return nil, err
+	}
+	return qm.updated(id)
+}
+
+// TitleFromContent generates a title from the start of an item's content,
+// the same way Enqueue does when no title is given.
+func (qm *QueueManager) TitleFromContent(id uint) (string, error) {
+	item, err := qm.store.History().Get(id)
+	if err != nil {
+		return "", err
+	}
+
+	sample, err := qm.store.History().GetContentPrefix(id, qm.titleSampleBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to read content: %w", err)
+	}
+
+	return qm.generateTitle(sample, item.IsBinary || store.IsBinary(sample), int64(len(sample)) < item.Size), nil
+}
+
+// generateTitle generates a title from sample with the title strategy. When
+// truncated, the content goes on past sample, so a strategy other than the
+// first line leaves out its last line, likely cut short, unless nothing else
+// is left.
+func (qm *QueueManager) generateTitle(sample []byte, isBinary, truncated bool) string {
+	i := bytes.LastIndexByte(sample, '\n')
+	if truncated && qm.titleStrategy.Pick != PickFirstLine && i >= 0 && len(bytes.TrimSpace(sample[:i])) > 0 {
+		sample = sample[:i]
+	}
+	return GenerateTitle(sample, isBinary, qm.titleStrategy)
 }
 
 // Clear removes all items from the queue.
 func (qm *QueueManager) Clear() error {
-	return qm.store.History().Clear()
+	return qm.ClearWith(store.ClearOptions{})
+}
+
+// ClearWith removes all items from the queue as opts says.
+func (qm *QueueManager) ClearWith(opts store.ClearOptions) error {
+	if err := qm.store.History().Clear(opts); err != nil {
+		return err
+	}
+	qm.events.publish(Event{Type: EventCleared})
+	return nil
 }
 
 // Size returns the number of items in the queue.
@@ -145,51 +608,44 @@ func (qm *QueueManager) Close() error {
 	return qm.store.Close()
 }
 
-// cleanupOldItems removes items exceeding history limit.
-func (qm *QueueManager) cleanupOldItems() error {
+// cleanupOldItems removes items exceeding history limit and returns them,
+// oldest first.
+func (qm *QueueManager) cleanupOldItems() ([]EvictedInfo, error) {
 	count, err := qm.store.History().Count()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	if count > qm.historyLimit {
-		toDelete := count - qm.historyLimit
-		return qm.store.History().DeleteOldest(toDelete)
+	if count <= qm.historyLimit {
+		return nil, nil
 	}
 
-	return nil
-}
-
-// isBinary detects if data is binary by checking for non-printable characters
-// and invalid UTF-8 sequences.
-func isBinary(data []byte) bool {
-	if len(data) == 0 {
-		return false
+	toDelete := count - qm.historyLimit
+	evicted, err := qm.oldest(toDelete)
+	if err != nil {
+		return nil, err
 	}
-
-	// Check up to first 8KB for performance
-	sampleSize := len(data)
-	if sampleSize > 8192 {
-		sampleSize = 8192
+	if err := qm.store.History().DeleteOldest(toDelete); err != nil {
+		return nil, err
 	}
-
-	nonPrintable := 0
-	for i := 0; i < sampleSize; i++ {
-		b := data[i]
-
-		// Null byte is a strong indicator of binary content
-		if b == 0 {
-			return true
-		}
-
-		// Count non-printable characters (excluding common whitespace)
-		if b < 32 && b != '\n' && b != '\r' && b != '\t' {
-			nonPrintable++
-		}
+	for _, e := range evicted {
+		qm.events.publish(Event{Type: EventDeleted, ID: e.ID, Title: e.Title})
 	}
+	return evicted, nil
+}
 
-	// If more than 30% of characters are non-printable, consider it binary
-	return float64(nonPrintable)/float64(sampleSize) > 0.3
+// oldest returns the n oldest items. DeleteOldest removes items in the
+// same order Iterate visits them oldest first, so these are the ones it
+// would remove.
+func (qm *QueueManager) oldest(n int) ([]EvictedInfo, error) {
+	items := make([]EvictedInfo, 0, n)
+	err := qm.store.History().Iterate(store.IterOptions{Order: store.OrderOldest, BatchSize: n}, func(item *store.HistoryItem) (bool, error) {
+		items = append(items, EvictedInfo{ID: item.ID, Title: item.Title})
+		return len(items) == n, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find oldest items: %w", err)
+	}
+	return items, nil
 }
 
 // Legacy type aliases for backward compatibility
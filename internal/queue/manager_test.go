@@ -1,12 +1,20 @@
 package queue
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/rivo/uniseg"
+	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/dbstore"
 	"github.com/yiblet/rem/internal/store/memstore"
 )
 
@@ -133,13 +141,316 @@ func TestQueueManager_TitleTruncation(t *testing.T) {
 		t.Fatalf("Failed to enqueue: %v", err)
 	}
 
-	// Title should be truncated to 80 chars (77 + "...")
-	if len(item.Title) != 80 {
-		t.Errorf("Expected title length 80, got %d", len(item.Title))
+	// Title should be truncated to 80 cells (79 + "…")
+	if width := uniseg.StringWidth(item.Title); width != 80 {
+		t.Errorf("Expected title width 80, got %d", width)
 	}
 
-	if !strings.HasSuffix(item.Title, "...") {
-		t.Errorf("Expected truncated title to end with '...', got '%s'", item.Title)
+	if !strings.HasSuffix(item.Title, Ellipsis) {
+		t.Errorf("Expected truncated title to end with '%s', got '%s'", Ellipsis, item.Title)
+	}
+}
+
+func TestQueueManager_Bump(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManagerWithConfig(ms, 3)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	for _, title := range []string{"oldest", "middle", "newest"} {
+		if _, err := qm.Enqueue(strings.NewReader(title+" content"), title); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	before, _ := qm.Get(2)
+
+	bumped, err := qm.Bump(2)
+	if err != nil {
+		t.Fatalf("Bump failed: %v", err)
+	}
+	if bumped.ID != before.ID || bumped.SHA256 != before.SHA256 || !bumped.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("Expected only the timestamp to change, got %+v, was %+v", bumped, before)
+	}
+	items, _ := qm.List()
+	if got := []string{items[0].Title, items[1].Title, items[2].Title}; fmt.Sprint(got) != "[oldest newest middle]" {
+		t.Errorf("Expected the bumped item first, got %v", got)
+	}
+
+	// The bumped item is now the newest, so the next eviction skips it
+	if _, err := qm.Enqueue(strings.NewReader("fourth content"), "fourth"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	items, _ = qm.List()
+	if got := []string{items[0].Title, items[1].Title, items[2].Title}; len(items) != 3 || fmt.Sprint(got) != "[fourth oldest newest]" {
+		t.Errorf("Expected middle evicted instead of the bumped item, got %v", got)
+	}
+
+	if _, err := qm.BumpByID(9999); err == nil {
+		t.Error("Expected error bumping a missing item")
+	}
+}
+
+func TestQueueManager_CoalesceWindow(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	content := strings.Repeat("prompt hook output\n", 1000)
+
+	// Off by default: every enqueue stores an item
+	first, _ := qm.Enqueue(strings.NewReader(content), "")
+	second, _ := qm.Enqueue(strings.NewReader(content), "")
+	if first.ID == second.ID {
+		t.Fatal("Expected identical enqueues to be stored with coalescing off")
+	}
+
+	qm.SetCoalesceWindow(time.Minute)
+	third, err := qm.Enqueue(strings.NewReader(content), "retitled")
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if third.ID != second.ID || third.Title != second.Title {
+		t.Errorf("Expected the newest item back, got %+v", third)
+	}
+	if n, _ := qm.Size(); n != 2 {
+		t.Errorf("Expected 2 items after a coalesced enqueue, got %d", n)
+	}
+
+	// Only the newest item is compared, so older copies don't count
+	other, _ := qm.Enqueue(strings.NewReader("something else"), "")
+	if again, _ := qm.Enqueue(strings.NewReader(content), ""); again.ID == second.ID || again.ID == other.ID {
+		t.Errorf("Expected content matching only an older item to be stored, got item %d", again.ID)
+	}
+
+	// Nor is a newest item from outside the window
+	if err := ms.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	old, err := ms.History().Create(&store.CreateHistoryInput{Title: "old", Content: strings.NewReader(content), Timestamp: time.Now().Add(-2 * time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := qm.Enqueue(strings.NewReader(content), ""); again.ID == old.ID {
+		t.Error("Expected content repeated outside the window to be stored")
+	}
+}
+
+func TestQueueManager_CoalesceWritesNothing(t *testing.T) {
+	stores := map[string]func(t *testing.T) store.Store{
+		"memstore": func(t *testing.T) store.Store { return memstore.NewMemoryStore() },
+		"dbstore": func(t *testing.T) store.Store {
+			s, err := dbstore.NewSQLiteStore(t.TempDir() + "/rem.db")
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+			qm, err := NewQueueManager(s)
+			if err != nil {
+				t.Fatalf("Failed to create queue manager: %v", err)
+			}
+			qm.SetCoalesceWindow(time.Minute)
+			// Longer than one comparison read, so a late difference is replayed
+			content := strings.Repeat("prompt hook output\n", 5000)
+			first, err := qm.Enqueue(strings.NewReader(content), "")
+			if err != nil {
+				t.Fatalf("Enqueue failed: %v", err)
+			}
+
+			auditor := s.(store.Auditor)
+			generation, _ := s.History().Generation()
+			entries, _ := auditor.AuditLog(0)
+			if again, err := qm.Enqueue(strings.NewReader(content), ""); err != nil || again.ID != first.ID {
+				t.Fatalf("Expected the repeat to return item %d, got %+v, %v", first.ID, again, err)
+			}
+			if got, _ := s.History().Generation(); got != generation {
+				t.Errorf("Expected a coalesced repeat to leave the generation at %d, got %d", generation, got)
+			}
+			if got, _ := auditor.AuditLog(0); len(got) != len(entries) {
+				t.Errorf("Expected a coalesced repeat to add no audit entries, got %d", len(got)-len(entries))
+			}
+
+			// Content that differs only after the first read, or is longer or
+			// shorter, is stored whole, and takes the next ID
+			for i, variant := range []string{content[:len(content)-1] + "!", content + "more", content[:40000]} {
+				item, err := qm.Enqueue(strings.NewReader(variant), "")
+				if err != nil {
+					t.Fatalf("Enqueue(%d) failed: %v", i, err)
+				}
+				if item.ID != first.ID+uint(i)+1 {
+					t.Errorf("Expected variant %d to be item %d, got %d", i, first.ID+uint(i)+1, item.ID)
+				}
+				stored, err := s.History().GetContent(item.ID)
+				if err != nil {
+					t.Fatalf("GetContent failed: %v", err)
+				}
+				data, _ := io.ReadAll(stored)
+				stored.Close()
+				if string(data) != variant {
+					t.Errorf("Variant %d stored as %d bytes, want %d", i, len(data), len(variant))
+				}
+			}
+		})
+	}
+}
+
+func TestQueueManager_ListPage(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManagerWithConfig(ms, 10)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := qm.Enqueue(strings.NewReader(fmt.Sprintf("content %d", i)), fmt.Sprintf("item %d", i)); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	if _, err := qm.Enqueue(bytes.NewReader([]byte{0, 1, 2}), "binary"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	first, err := qm.ListPage(nil, 2, ListOptions{HideBinary: true})
+	if err != nil {
+		t.Fatalf("ListPage failed: %v", err)
+	}
+	last := first[len(first)-1]
+	rest, err := qm.ListPage(&store.IterCursor{Timestamp: last.Timestamp, ID: last.ID}, 10, ListOptions{HideBinary: true})
+	if err != nil {
+		t.Fatalf("ListPage failed: %v", err)
+	}
+	var titles []string
+	for _, item := range append(first, rest...) {
+		titles = append(titles, item.Title)
+	}
+	if fmt.Sprint(titles) != "[item 4 item 3 item 2 item 1 item 0]" {
+		t.Errorf("Expected the text items across two pages, got %v", titles)
+	}
+
+	for opts, want := range map[ListOptions]int{{}: 6, {HideBinary: true}: 5, {Limit: 3}: 3} {
+		if count, err := qm.CountWith(opts); err != nil || count != want {
+			t.Errorf("CountWith(%+v) = %d, %v; want %d", opts, count, err, want)
+		}
+	}
+}
+
+// TestQueueManager_CountWithHistoryLimit tests that CountWith counts within
+// the history limit like ListWith when hidden binary items are inside it
+func TestQueueManager_CountWithHistoryLimit(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManagerWithConfig(ms, 20)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	for i := 0; i < 11; i++ {
+		if _, err := qm.Enqueue(strings.NewReader(fmt.Sprintf("content %d", i)), ""); err != nil {
+			t.Fatalf("Failed to enqueue: %v", err)
+		}
+	}
+	if _, err := qm.Enqueue(bytes.NewReader([]byte{0, 1, 2}), "binary"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	// The limit is lowered below the 12 stored items
+	qm, err = NewQueueManagerWithConfig(ms, 10)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	for _, opts := range []ListOptions{{}, {HideBinary: true}, {HideBinary: true, Limit: 9}, {HideBinary: true, Limit: 5}} {
+		items, err := qm.ListWith(opts)
+		if err != nil {
+			t.Fatalf("ListWith failed: %v", err)
+		}
+		if count, err := qm.CountWith(opts); err != nil || count != len(items) {
+			t.Errorf("CountWith(%+v) = %d, %v; ListWith returned %d", opts, count, err, len(items))
+		}
+	}
+}
+
+func TestQueueManager_Rename(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+
+	first, _ := qm.Enqueue(strings.NewReader("first"), "first title")
+	if _, err := qm.Enqueue(strings.NewReader("second"), "second title"); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+
+	// By index: index 1 is the older item
+	item, err := qm.Rename(1, "renamed")
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if item.ID != first.ID || item.Title != "renamed" {
+		t.Errorf("Expected item %d titled 'renamed', got %d '%s'", first.ID, item.ID, item.Title)
+	}
+
+	// By ID, with a title that needs sanitizing and truncating
+	item, err = qm.RenameByID(first.ID, "line one\nline\ttwo  "+strings.Repeat("x", 100))
+	if err != nil {
+		t.Fatalf("RenameByID failed: %v", err)
+	}
+	if strings.ContainsAny(item.Title, "\n\t") {
+		t.Errorf("Expected sanitized title, got %q", item.Title)
+	}
+	if !strings.HasPrefix(item.Title, "line one line two x") || uniseg.StringWidth(item.Title) != MaxTitleLength {
+		t.Errorf("Expected sanitized, truncated title, got %q (width %d)", item.Title, uniseg.StringWidth(item.Title))
+	}
+
+	// Empty after sanitizing is rejected
+	if _, err := qm.Rename(0, " \n\t "); err == nil {
+		t.Error("Expected error renaming to a blank title")
+	}
+
+	if _, err := qm.Rename(5, "nope"); err == nil {
+		t.Error("Expected error for out-of-range index")
+	}
+}
+
+func TestQueueManager_TitleFromContent(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+
+	text, _ := qm.Enqueue(strings.NewReader("\n  first line  \nsecond line"), "custom")
+	binary, _ := qm.Enqueue(strings.NewReader("\x00\x01\x02binary"), "custom")
+
+	title, err := qm.TitleFromContent(text.ID)
+	if err != nil {
+		t.Fatalf("TitleFromContent failed: %v", err)
+	}
+	if title != "first line" {
+		t.Errorf("Expected 'first line', got '%s'", title)
+	}
+
+	title, err = qm.TitleFromContent(binary.ID)
+	if err != nil {
+		t.Fatalf("TitleFromContent failed: %v", err)
+	}
+	if title != "[binary content]" {
+		t.Errorf("Expected '[binary content]', got '%s'", title)
 	}
 }
 
@@ -190,6 +501,54 @@ func TestQueueManager_MaxSize(t *testing.T) {
 	}
 }
 
+func TestQueueManager_EnqueueEvictions(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+	qm, _ := NewQueueManagerWithConfig(ms, 3)
+
+	for i := 0; i < 3; i++ {
+		result, err := qm.EnqueueWithResult(strings.NewReader(fmt.Sprintf("Content %d", i)), "")
+		if err != nil {
+			t.Fatalf("Failed to enqueue item %d: %v", i, err)
+		}
+		if len(result.Evicted) != 0 {
+			t.Errorf("Item %d evicted %v before the queue was full", i, result.Evicted)
+		}
+	}
+	before, _ := qm.List()
+
+	result, err := qm.EnqueueWithResult(strings.NewReader("Content 3"), "")
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if result.Item.Title != "Content 3" {
+		t.Errorf("Expected the stored item, got %q", result.Item.Title)
+	}
+	oldest := before[len(before)-1]
+	if len(result.Evicted) != 1 || result.Evicted[0] != (EvictedInfo{ID: oldest.ID, Title: "Content 0"}) {
+		t.Fatalf("Expected Content 0 (ID %d) evicted, got %+v", oldest.ID, result.Evicted)
+	}
+	after, _ := qm.List()
+	for _, item := range after {
+		if item.ID == oldest.ID {
+			t.Errorf("Evicted item %d is still listed", oldest.ID)
+		}
+	}
+
+	// With a lower limit one store evicts several, oldest first
+	smaller, _ := NewQueueManagerWithConfig(ms, 2)
+	result, err = smaller.EnqueueWithResult(strings.NewReader("Content 4"), "")
+	if err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	if len(result.Evicted) != 2 || result.Evicted[0].Title != "Content 1" || result.Evicted[1].Title != "Content 2" {
+		t.Errorf("Expected Content 1 and 2 evicted, got %+v", result.Evicted)
+	}
+	if size, _ := smaller.Size(); size != 2 {
+		t.Errorf("Expected 2 items left, got %d", size)
+	}
+}
+
 func TestQueueManager_TitleGeneration(t *testing.T) {
 	ms := memstore.NewMemoryStore()
 	defer ms.Close()
@@ -223,7 +582,7 @@ func TestQueueManager_TitleGeneration(t *testing.T) {
 		{
 			name:     "Long text gets truncated",
 			input:    strings.Repeat("a", 100),
-			expected: strings.Repeat("a", 77) + "...",
+			expected: strings.Repeat("a", 79) + "…",
 		},
 	}
 
@@ -341,6 +700,126 @@ func TestQueueManager_Delete(t *testing.T) {
 	}
 }
 
+func TestQueueManager_ByID(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	defer qm.Close()
+
+	first, _ := qm.Enqueue(strings.NewReader("first"), "")
+	second, _ := qm.Enqueue(strings.NewReader("second"), "")
+	ops := qm.ByID()
+
+	reader, err := ops.Content(first.ID)
+	if err != nil {
+		t.Fatalf("Content failed: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != "first" {
+		t.Errorf("Expected content 'first', got %q", data)
+	}
+
+	if err := ops.Rename(second.ID, "renamed"); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+
+	// Deleting by ID leaves other items in place regardless of their index
+	if err := ops.Delete(first.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	items, err := qm.List()
+	if err != nil {
+		t.Fatalf("Failed to list items: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != second.ID || items[0].Title != "renamed" {
+		t.Errorf("Expected only the renamed second item to remain, got %+v", items)
+	}
+
+	if err := ops.Delete(first.ID); err == nil {
+		t.Error("Expected error deleting an already deleted item")
+	}
+}
+
+func TestQueueManager_ResolveIndex(t *testing.T) {
+	stores := map[string]func(t *testing.T) store.Store{
+		"memstore": func(t *testing.T) store.Store { return memstore.NewMemoryStore() },
+		"dbstore": func(t *testing.T) store.Store {
+			s, err := dbstore.NewSQLiteStore(t.TempDir() + "/rem.db")
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			s := newStore(t)
+			qm, err := NewQueueManager(s)
+			if err != nil {
+				t.Fatalf("Failed to create queue manager: %v", err)
+			}
+			defer qm.Close()
+
+			// Newest first: text-2, binary-1, text-1, binary-0, text-0
+			for i := 0; i < 3; i++ {
+				if i > 0 {
+					qm.Enqueue(strings.NewReader(fmt.Sprintf("binary-%d\x00", i-1)), fmt.Sprintf("binary-%d", i-1))
+				}
+				qm.Enqueue(strings.NewReader(fmt.Sprintf("text-%d", i)), fmt.Sprintf("text-%d", i))
+			}
+
+			opts := ListOptions{HideBinary: true, Limit: 2}
+			listed, err := qm.ListWith(opts)
+			if err != nil {
+				t.Fatalf("ListWith failed: %v", err)
+			}
+			if len(listed) != 2 || listed[0].Title != "text-2" || listed[1].Title != "text-1" {
+				t.Fatalf("Expected text-2 and text-1, got %+v", listed)
+			}
+
+			// The printed index resolves, reads and deletes the same item
+			item, err := qm.ResolveIndex(1, opts)
+			if err != nil || item.ID != listed[1].ID {
+				t.Fatalf("ResolveIndex(1) = %+v, %v, want %s", item, err, listed[1].Title)
+			}
+			reader, err := qm.GetContent(item.ID)
+			if err != nil {
+				t.Fatalf("GetContent failed: %v", err)
+			}
+			data, _ := io.ReadAll(reader)
+			reader.Close()
+			if string(data) != "text-1" {
+				t.Errorf("Expected content text-1, got %q", data)
+			}
+			var rangeErr *IndexError
+			if _, err := qm.ResolveIndex(2, opts); !errors.As(err, &rangeErr) || rangeErr.Size != 2 {
+				t.Errorf("Expected index 2 to be out of range of 2 items under the limit, got %v", err)
+			}
+
+			// Unfiltered, index 1 is a different item
+			if other, _ := qm.Get(1); other == nil || other.ID == item.ID {
+				t.Errorf("Expected unfiltered index 1 to be binary-1, got %+v", other)
+			}
+
+			if err := qm.DeleteAt(1, opts); err != nil {
+				t.Fatalf("DeleteAt failed: %v", err)
+			}
+			if _, err := s.History().Get(item.ID); err == nil {
+				t.Error("Expected DeleteAt to delete the resolved item")
+			}
+			if size, _ := qm.Size(); size != 4 {
+				t.Errorf("Expected 4 items to remain, got %d", size)
+			}
+		})
+	}
+}
+
 func TestQueueManager_BinaryDetection(t *testing.T) {
 	ms := memstore.NewMemoryStore()
 	defer ms.Close()
@@ -400,15 +879,13 @@ func TestQueueManager_LIFO_Ordering(t *testing.T) {
 	}
 	defer qm.Close()
 
-	// Add items with explicit timestamps to ensure ordering
+	// Enqueue back to back; ties in timestamp are broken by insertion order
 	for i := 0; i < 5; i++ {
 		content := strings.NewReader(fmt.Sprintf("Item %d", i))
 		_, err := qm.Enqueue(content, "")
 		if err != nil {
 			t.Fatalf("Failed to enqueue item %d: %v", i, err)
 		}
-		// Small delay to ensure different timestamps
-		time.Sleep(1 * time.Millisecond)
 	}
 
 	// List should return items in LIFO order (newest first)
@@ -527,7 +1004,7 @@ func TestIsBinary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isBinary(tt.data)
+			result := store.IsBinary(tt.data)
 			if result != tt.expected {
 				t.Errorf("isBinary() = %v, expected %v", result, tt.expected)
 			}
@@ -582,7 +1059,7 @@ func TestTitleGeneration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GenerateTitle(tt.sample, tt.isBinary)
+			result := GenerateTitle(tt.sample, tt.isBinary, TitleStrategy{})
 			if result != tt.expected {
 				t.Errorf("GenerateTitle() = %q, expected %q", result, tt.expected)
 			}
@@ -590,6 +1067,62 @@ func TestTitleGeneration(t *testing.T) {
 	}
 }
 
+func TestTitleStrategies(t *testing.T) {
+	output := []byte("$ go test ./...\n\nok  \tpkg/a\t0.1s\nFAIL\tpkg/bb\t0.25s\n\n3 tests failed\n")
+	long := strings.Repeat("word ", 30)
+	tests := []struct {
+		name     string
+		sample   []byte
+		strategy TitleStrategy
+		expected string
+	}{
+		{"first line", output, TitleStrategy{}, "$ go test ./..."},
+		{"last line", output, TitleStrategy{Pick: PickLastLine}, "3 tests failed"},
+		{"longest line", output, TitleStrategy{Pick: PickLongestLine}, "FAIL pkg/bb 0.25s"},
+		{"line 4", output, TitleStrategy{Pick: PickLine, Line: 4}, "FAIL pkg/bb 0.25s"},
+		{"empty line falls back", output, TitleStrategy{Pick: PickLine, Line: 2}, "$ go test ./..."},
+		{"line past the end falls back", output, TitleStrategy{Pick: PickLine, Line: 40}, "$ go test ./..."},
+		{"line 0 falls back", output, TitleStrategy{Pick: PickLine}, "$ go test ./..."},
+		{"single line", []byte("only"), TitleStrategy{Pick: PickLastLine}, "only"},
+		{"blank lines", []byte("  \n\t\n"), TitleStrategy{Pick: PickLongestLine}, "[empty]"},
+		{"long last line", []byte("header\n" + long), TitleStrategy{Pick: PickLastLine}, strings.TrimSpace(long)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerateTitle(tt.sample, false, tt.strategy); got != tt.expected {
+				t.Errorf("GenerateTitle() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	// A picked line longer than a title is truncated when stored
+	qm, err := NewQueueManager(memstore.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	qm.SetTitleStrategy(TitleStrategy{Pick: PickLastLine})
+	item, err := qm.Enqueue(strings.NewReader("header\n"+long+"\n"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uniseg.StringWidth(item.Title) != MaxTitleLength || !strings.HasSuffix(item.Title, Ellipsis) || !strings.HasPrefix(item.Title, "word word") {
+		t.Errorf("title = %q, want the last line truncated to %d cells", item.Title, MaxTitleLength)
+	}
+
+	// The line cut off at the end of the sample isn't taken for the last
+	qm.SetTitleSampleBytes(64)
+	item, err = qm.Enqueue(strings.NewReader("start\nBuild succeeded in 42s\n"+strings.Repeat("x", 100)+"\nend\n"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Title != "Build succeeded in 42s" {
+		t.Errorf("title = %q, want the last complete line of the sample", item.Title)
+	}
+	if title, err := qm.TitleFromContent(item.ID); err != nil || title != item.Title {
+		t.Errorf("TitleFromContent() = %q, %v; want %q", title, err, item.Title)
+	}
+}
+
 func TestTruncateTitle(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -613,13 +1146,49 @@ func TestTruncateTitle(t *testing.T) {
 			name:     "Long title, truncate with ellipsis",
 			title:    strings.Repeat("a", 100),
 			maxLen:   80,
-			expected: strings.Repeat("a", 77) + "...",
+			expected: strings.Repeat("a", 79) + "…",
 		},
 		{
 			name:     "Very short maxLen",
 			title:    "Hello",
 			maxLen:   3,
-			expected: "...",
+			expected: "He…",
+		},
+		{
+			name:     "No room for anything",
+			title:    "Hello",
+			maxLen:   0,
+			expected: "",
+		},
+		{
+			name:     "CJK counts two cells per character",
+			title:    strings.Repeat("漢", 50),
+			maxLen:   80,
+			expected: strings.Repeat("漢", 39) + "…",
+		},
+		{
+			name:     "Wide character never split across the limit",
+			title:    "ab漢字",
+			maxLen:   4,
+			expected: "ab…",
+		},
+		{
+			name:     "Emoji ZWJ sequence kept whole",
+			title:    "hi 👩‍💻👩‍💻 there",
+			maxLen:   6,
+			expected: "hi 👩‍💻…",
+		},
+		{
+			name:     "Combining characters stay with their base",
+			title:    "cafe\u0301 cafe\u0301",
+			maxLen:   5,
+			expected: "cafe\u0301…",
+		},
+		{
+			name:     "Mixed width",
+			title:    "go 言語 rocks 🚀 always",
+			maxLen:   12,
+			expected: "go 言語 roc…",
 		},
 		{
 			name:     "Trim whitespace",
@@ -635,8 +1204,11 @@ func TestTruncateTitle(t *testing.T) {
 			if result != tt.expected {
 				t.Errorf("TruncateTitle() = %q, expected %q", result, tt.expected)
 			}
-			if len(result) > tt.maxLen {
-				t.Errorf("TruncateTitle() returned length %d, expected <= %d", len(result), tt.maxLen)
+			if width := uniseg.StringWidth(result); width > tt.maxLen {
+				t.Errorf("TruncateTitle() returned width %d, expected <= %d", width, tt.maxLen)
+			}
+			if !utf8.ValidString(result) {
+				t.Errorf("TruncateTitle() returned invalid UTF-8 %q", result)
 			}
 		})
 	}
@@ -689,3 +1261,197 @@ func TestSanitizeTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestQueueManager_RapidEnqueueOrdering(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+
+	const count = 100
+	for i := 0; i < count; i++ {
+		if _, err := qm.Enqueue(strings.NewReader(fmt.Sprintf("Item %d", i)), ""); err != nil {
+			t.Fatalf("Failed to enqueue item %d: %v", i, err)
+		}
+	}
+
+	items, err := qm.List()
+	if err != nil {
+		t.Fatalf("Failed to list items: %v", err)
+	}
+	if len(items) != count {
+		t.Fatalf("Expected %d items, got %d", count, len(items))
+	}
+	for i, item := range items {
+		if want := fmt.Sprintf("Item %d", count-1-i); item.Title != want {
+			t.Fatalf("Index %d: expected %q, got %q", i, want, item.Title)
+		}
+	}
+}
+
+func TestQueueManager_EnqueueFromPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]func(t *testing.T) store.Store{
+		"memstore": func(t *testing.T) store.Store { return memstore.NewMemoryStore() },
+		"dbstore": func(t *testing.T) store.Store {
+			s, err := dbstore.NewSQLiteStore(t.TempDir() + "/rem.db")
+			if err != nil {
+				t.Fatalf("Failed to create store: %v", err)
+			}
+			return s
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			qm, err := NewQueueManager(newStore(t))
+			if err != nil {
+				t.Fatalf("Failed to create queue manager: %v", err)
+			}
+			defer qm.Close()
+
+			if _, err := qm.EnqueueFromPath(dir, ""); err == nil {
+				t.Error("Expected error storing a directory")
+			}
+			if _, err := qm.Enqueue(strings.NewReader("piped"), ""); err != nil {
+				t.Fatalf("Failed to enqueue: %v", err)
+			}
+			if _, err := qm.EnqueueFromPath(path, ""); err != nil {
+				t.Fatalf("Failed to enqueue file: %v", err)
+			}
+
+			item, err := qm.Get(0)
+			if err != nil {
+				t.Fatalf("Failed to get item: %v", err)
+			}
+			if item.OriginalName != "notes.txt" || item.OriginalMode != 0640 || !item.OriginalModTime.Equal(modTime) {
+				t.Errorf("Expected notes.txt, 0640, %v; got %q, %v, %v",
+					modTime, item.OriginalName, item.OriginalMode, item.OriginalModTime)
+			}
+			if item.Title != "file content" {
+				t.Errorf("Expected title from content, got %q", item.Title)
+			}
+
+			piped, err := qm.Get(1)
+			if err != nil {
+				t.Fatalf("Failed to get item: %v", err)
+			}
+			if piped.OriginalName != "" || piped.OriginalMode != 0 || !piped.OriginalModTime.IsZero() {
+				t.Errorf("Expected no file metadata for piped content, got %+v", piped)
+			}
+		})
+	}
+}
+
+// nextEvent returns the next event on events, failing if none arrives
+func nextEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Expected an event, got none")
+	}
+	return Event{}
+}
+
+func TestQueueManager_Subscribe(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+	qm, err := NewQueueManagerWithConfig(ms, 2)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+
+	events, unsubscribe := qm.Subscribe()
+	first, _ := qm.Enqueue(strings.NewReader("first"), "first")
+	second, _ := qm.Enqueue(strings.NewReader("second"), "second")
+	third, _ := qm.Enqueue(strings.NewReader("third"), "third")
+	qm.RenameByID(second.ID, "renamed")
+	qm.SetNoteByID(second.ID, "a note")
+	qm.BumpByID(second.ID)
+	qm.DeleteByID(third.ID)
+	qm.Clear()
+
+	want := []Event{
+		{EventCreated, first.ID, "first"},
+		{EventCreated, second.ID, "second"},
+		{EventCreated, third.ID, "third"},
+		{EventDeleted, first.ID, "first"}, // evicted by the history limit
+		{EventUpdated, second.ID, "renamed"},
+		{EventUpdated, second.ID, "renamed"},
+		{EventUpdated, second.ID, "renamed"},
+		{EventDeleted, third.ID, "third"},
+		{EventCleared, 0, ""},
+	}
+	for i, w := range want {
+		if got := nextEvent(t, events); got != w {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	// A failed change sends nothing
+	if err := qm.DeleteByID(third.ID); err == nil {
+		t.Error("Expected deleting a deleted item to fail")
+	}
+
+	unsubscribe()
+	qm.Enqueue(strings.NewReader("unseen"), "unseen")
+	if event, ok := <-events; ok {
+		t.Errorf("Expected the channel closed after unsubscribing, got %+v", event)
+	}
+	unsubscribe() // Safe to call twice
+}
+
+func TestQueueManager_SubscribeSlowConsumer(t *testing.T) {
+	ms := memstore.NewMemoryStore()
+	defer ms.Close()
+	qm, err := NewQueueManager(ms)
+	if err != nil {
+		t.Fatalf("Failed to create queue manager: %v", err)
+	}
+	events, unsubscribe := qm.Subscribe()
+	defer unsubscribe()
+
+	// Nobody reads, and storing carries on regardless
+	done := make(chan struct{})
+	var last *store.HistoryItem
+	go func() {
+		defer close(done)
+		for i := range EventBufferSize * 3 {
+			last, _ = qm.Enqueue(strings.NewReader(fmt.Sprint(i)), fmt.Sprint(i))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Enqueue blocked on a subscriber that doesn't read")
+	}
+
+	// The oldest events were dropped, leaving the newest
+	if len(events) != EventBufferSize {
+		t.Fatalf("Expected a full buffer of %d events, got %d", EventBufferSize, len(events))
+	}
+	var event Event
+	for range EventBufferSize {
+		event = <-events
+	}
+	if event.ID != last.ID {
+		t.Errorf("Expected the last event to be for item %d, got %+v", last.ID, event)
+	}
+}
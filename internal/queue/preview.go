@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// Preview is what enqueueing some content would store, found without
+// storing it
+type Preview struct {
+	Title    string
+	IsBinary bool
+	Size     int64
+	SHA256   string
+
+	// Coalesced is the newest item, which Enqueue would return rather than
+	// store a copy of it within the coalesce window; nil if it wouldn't
+	Coalesced *store.HistoryItem
+
+	// Duplicate is the newest item in the queue with the same content; nil
+	// if there is none
+	Duplicate *store.HistoryItem
+
+	// Evicted are the items storing the content would push out of the
+	// queue, oldest first
+	Evicted []EvictedInfo
+}
+
+// Preview reads content the way Enqueue does and reports what Enqueue
+// would store, without storing anything. Content is hashed and counted as
+// it streams, so input of any size is never held in memory.
+func (qm *QueueManager) Preview(content io.Reader, title string) (*Preview, error) {
+	title, reader, err := qm.storedTitle(content, title)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	sample := &sampleWriter{max: store.BinarySampleBytes}
+	size, err := io.Copy(io.MultiWriter(hasher, sample), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content: %w", err)
+	}
+	preview := &Preview{
+		Title:    title,
+		IsBinary: store.IsBinary(sample.data),
+		Size:     size,
+		SHA256:   hex.EncodeToString(hasher.Sum(nil)),
+	}
+
+	newest, err := qm.coalesceCandidate()
+	if err != nil {
+		return nil, err
+	}
+	if qm.coalesces(newest, preview.SHA256, time.Now()) {
+		preview.Coalesced = newest
+	}
+
+	copies, err := qm.store.History().FindBySHA256(preview.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find copies: %w", err)
+	}
+	if len(copies) > 0 {
+		preview.Duplicate = copies[0]
+	}
+
+	if preview.Coalesced == nil {
+		count, err := qm.store.History().Count()
+		if err != nil {
+			return nil, err
+		}
+		if over := count + 1 - qm.historyLimit; over > 0 {
+			if preview.Evicted, err = qm.oldest(over); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return preview, nil
+}
+
+// sampleWriter keeps the first max bytes written to it and discards the rest
+type sampleWriter struct {
+	data []byte
+	max  int
+}
+
+func (w *sampleWriter) Write(p []byte) (int, error) {
+	if room := w.max - len(w.data); room > 0 {
+		w.data = append(w.data, p[:min(room, len(p))]...)
+	}
+	return len(p), nil
+}
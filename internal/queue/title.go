@@ -1,14 +1,71 @@
 package queue
 
 import (
+	"fmt"
 	"strings"
 	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// MaxTitleLength is the maximum display width of a stored title, in
+// terminal cells; a CJK character or emoji takes two.
+const MaxTitleLength = 80
+
+// Ellipsis ends a truncated title; it takes one cell
+const Ellipsis = "…"
+
+// PrepareTitle sanitizes a user-supplied title and truncates it to MaxTitleLength.
+func PrepareTitle(title string) string {
+	return TruncateTitle(SanitizeTitle(title), MaxTitleLength)
+}
+
+// DefaultTitleSampleBytes is how much of the content a title is generated
+// from, unless SetTitleSampleBytes changes it
+const DefaultTitleSampleBytes = 4096
+
+// TitlePick names the line of a content sample a generated title is taken
+// from
+type TitlePick int
+
+const (
+	PickFirstLine   TitlePick = iota // the first non-empty line
+	PickLastLine                     // the last non-empty line, such as "3 tests failed"
+	PickLongestLine                  // the longest line, the first of several as long
+	PickLine                         // line TitleStrategy.Line, if it isn't empty
 )
 
+// titlePickNames are the names ParseTitlePick accepts, by TitlePick
+var titlePickNames = []string{"first-line", "last-line", "longest-line"}
+
+// TitlePickNames returns the names of the picks title_strategy can be set to
+func TitlePickNames() []string {
+	return append([]string(nil), titlePickNames...)
+}
+
+// ParseTitlePick returns the pick named name, such as "last-line"
+func ParseTitlePick(name string) (TitlePick, error) {
+	for i, n := range titlePickNames {
+		if n == name {
+			return TitlePick(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown title strategy %q (use %s)", name, strings.Join(titlePickNames, ", "))
+}
+
+// TitleStrategy chooses the line of a content sample a generated title is
+// taken from. The zero value takes the first non-empty line.
+type TitleStrategy struct {
+	Pick TitlePick
+	Line int // with PickLine, the line number, counting from 1
+}
+
 // GenerateTitle creates a title from a content sample (first few KB).
 // For binary content, returns "[binary content]".
-// For text content, uses the first non-empty line or sanitized content.
-func GenerateTitle(sample []byte, isBinary bool) string {
+// For text content, uses the line strategy picks, falling back to the first
+// non-empty line when that line is empty or past the end of the sample, and
+// to the sanitized content when every line is blank.
+func GenerateTitle(sample []byte, isBinary bool, strategy TitleStrategy) string {
 	if isBinary {
 		return "[binary content]"
 	}
@@ -17,10 +74,13 @@ func GenerateTitle(sample []byte, isBinary bool) string {
 		return "[empty]"
 	}
 
-	// Convert to string and get first non-empty line
 	text := string(sample)
 	lines := strings.Split(text, "\n")
+	if line := strategy.pick(lines); line != "" {
+		return SanitizeTitle(line)
+	}
 
+	// Fall back to the first non-empty line
 	for _, line := range lines {
 		cleaned := strings.TrimSpace(line)
 		if cleaned != "" {
@@ -38,21 +98,58 @@ func GenerateTitle(sample []byte, isBinary bool) string {
 	return sanitized
 }
 
-// TruncateTitle ensures title is at most maxLen characters.
-// If truncation is needed, appends "..." to indicate truncation.
+// pick returns the trimmed line s picks from lines, or "" if it picks none
+func (s TitleStrategy) pick(lines []string) string {
+	switch s.Pick {
+	case PickLastLine:
+		for i := len(lines) - 1; i >= 0; i-- {
+			if line := strings.TrimSpace(lines[i]); line != "" {
+				return line
+			}
+		}
+	case PickLongestLine:
+		// Measured as the title would be, with whitespace collapsed
+		longest, width := "", 0
+		for _, line := range lines {
+			if w := uniseg.StringWidth(SanitizeTitle(line)); w > width {
+				longest, width = strings.TrimSpace(line), w
+			}
+		}
+		return longest
+	case PickLine:
+		if s.Line >= 1 && s.Line <= len(lines) {
+			return strings.TrimSpace(lines[s.Line-1])
+		}
+	}
+	return ""
+}
+
+// TruncateTitle ensures title is at most maxLen display cells wide (not
+// bytes). A longer title is cut between grapheme clusters, so the result is
+// always valid UTF-8, and ends with Ellipsis.
 func TruncateTitle(title string, maxLen int) string {
 	title = strings.TrimSpace(title)
 
-	if len(title) <= maxLen {
+	if uniseg.StringWidth(title) <= maxLen {
 		return title
 	}
-
-	// Reserve 3 characters for "..."
-	if maxLen < 3 {
-		return strings.Repeat(".", maxLen)
+	if maxLen < 1 {
+		return ""
 	}
 
-	return title[:maxLen-3] + "..."
+	// Reserve a cell for the ellipsis
+	var b strings.Builder
+	width := 0
+	graphemes := uniseg.NewGraphemes(title)
+	for graphemes.Next() {
+		w := graphemes.Width()
+		if width+w > maxLen-1 {
+			break
+		}
+		b.WriteString(graphemes.Str())
+		width += w
+	}
+	return strings.TrimRightFunc(b.String(), unicode.IsSpace) + Ellipsis
 }
 
 // SanitizeTitle removes control characters and collapses whitespace.
@@ -0,0 +1,72 @@
+// Package ref converts item IDs to and from short references such as
+// "0k3f": the ID in lowercase Crockford base32, so a reference is easy to
+// read aloud or jot down and decodes back to exactly one ID.
+package ref
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// alphabet is Crockford's base32 alphabet, which leaves out i, l, o, and u
+const alphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// minLength is the length references are padded to, so IDs below 32^4
+// read as four characters
+const minLength = 4
+
+// maxLength is the length of the largest ID's reference
+var maxLength = (bits.UintSize + 4) / 5
+
+// ErrInvalid is wrapped by the errors Decode returns
+var ErrInvalid = errors.New("invalid reference")
+
+// Encode returns the reference for id
+func Encode(id uint) string {
+	var buf [(bits.UintSize + 4) / 5]byte
+	i := len(buf)
+	for id > 0 || len(buf)-i < minLength {
+		i--
+		buf[i] = alphabet[id%32]
+		id /= 32
+	}
+	return string(buf[i:])
+}
+
+// Decode returns the ID a reference encodes. Letters may be upper case, and
+// i, l, and o read as 1, 1, and 0, as Crockford's scheme allows.
+func Decode(s string) (uint, error) {
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+	trimmed := strings.TrimLeft(s, "0oO")
+	if len(trimmed) > maxLength {
+		return 0, fmt.Errorf("%w %q: too long", ErrInvalid, s)
+	}
+
+	var id uint
+	for _, r := range strings.ToLower(trimmed) {
+		digit := strings.IndexRune(alphabet, normalize(r))
+		if digit < 0 {
+			return 0, fmt.Errorf("%w %q: %q is not a base32 digit", ErrInvalid, s, r)
+		}
+		if id > (^uint(0)-uint(digit))/32 {
+			return 0, fmt.Errorf("%w %q: too large", ErrInvalid, s)
+		}
+		id = id*32 + uint(digit)
+	}
+	return id, nil
+}
+
+// normalize maps the letters Crockford's scheme reads as digits
+func normalize(r rune) rune {
+	switch r {
+	case 'i', 'l':
+		return '1'
+	case 'o':
+		return '0'
+	}
+	return r
+}
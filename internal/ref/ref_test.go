@@ -0,0 +1,89 @@
+package ref
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var ids []uint
+	for id := uint(0); id < 1<<16; id++ {
+		ids = append(ids, id)
+	}
+	// Either side of every length boundary, up to the largest IDs
+	for n := uint(32); n <= math.MaxUint/32; n *= 32 {
+		ids = append(ids, n-1, n, n+1)
+	}
+	ids = append(ids, math.MaxUint32, math.MaxUint-1, math.MaxUint)
+
+	for _, id := range ids {
+		s := Encode(id)
+		if len(s) < minLength || len(s) > maxLength {
+			t.Fatalf("Encode(%d) = %q, want %d to %d characters", id, s, minLength, maxLength)
+		}
+		got, err := Decode(s)
+		if err != nil || got != id {
+			t.Fatalf("Decode(Encode(%d) = %q) = %d, %v", id, s, got, err)
+		}
+		if got, err := Decode(strings.ToUpper(s)); err != nil || got != id {
+			t.Fatalf("Decode(%q) = %d, %v; want %d", strings.ToUpper(s), got, err, id)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		id   uint
+		want string
+	}{
+		{0, "0000"},
+		{1, "0001"},
+		{31, "000z"},
+		{32, "0010"},
+		{32*32*32*32 - 1, "zzzz"},
+		{32 * 32 * 32 * 32, "10000"},
+		{1<<30 - 1, "zzzzzz"},
+	}
+	for _, tt := range tests {
+		if got := Encode(tt.id); got != tt.want {
+			t.Errorf("Encode(%d) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+	// References sort like their IDs within a length
+	if Encode(100) >= Encode(101) {
+		t.Errorf("Encode(100) = %q sorts after Encode(101) = %q", Encode(100), Encode(101))
+	}
+}
+
+func TestDecode_Lenient(t *testing.T) {
+	tests := []struct {
+		s    string
+		want uint
+	}{
+		{"1", 1},
+		{"z", 31},
+		{"000Z", 31},
+		{"oool", 1},
+		{"OOOI", 1},
+		{"0000000000000000000010", 32},
+	}
+	for _, tt := range tests {
+		if got, err := Decode(tt.s); err != nil || got != tt.want {
+			t.Errorf("Decode(%q) = %d, %v; want %d", tt.s, got, err, tt.want)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tooLarge := "g" + strings.Repeat("0", maxLength-1)
+	if Encode(math.MaxUint)[0] >= 'g' {
+		t.Fatalf("test assumes the largest reference starts below g, got %q", Encode(math.MaxUint))
+	}
+	for _, s := range []string{"", "u", "ab-c", "0k3f!", "héllo", " 01", strings.Repeat("1", maxLength+1), tooLarge} {
+		if got, err := Decode(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Decode(%q) = %d, %v; want ErrInvalid", s, got, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"strconv"
+	"time"
+)
+
+// AuditKeepDaysKey is the config key holding how many days audit entries
+// are kept; 0 turns the audit log off
+const AuditKeepDaysKey = "audit_keep_days"
+
+// DefaultAuditKeepDays is how long audit entries are kept when
+// audit_keep_days is not set
+const DefaultAuditKeepDays = 90
+
+// Operations recorded in AuditEntry.Operation
+const (
+	AuditDelete = "delete" // Delete
+	AuditEvict  = "evict"  // DeleteOldest
+	AuditClear  = "clear"  // Clear
+)
+
+// AuditEntry records one item removed from the history
+type AuditEntry struct {
+	ID        uint
+	Timestamp time.Time
+	Operation string // AuditDelete, AuditEvict or AuditClear
+
+	// The removed item. ItemID is nil for a Clear that found no items, which
+	// is recorded all the same.
+	ItemID     *uint
+	ItemTitle  string
+	ItemSHA256 string
+
+	// Source says what removed the item, such as the command line or "tui"
+	Source string
+}
+
+// AuditKeepDays returns the audit_keep_days value in config, or
+// DefaultAuditKeepDays when it is not set or not a non-negative integer
+func AuditKeepDays(config ConfigStore) int {
+	value, err := config.Get(AuditKeepDaysKey)
+	if err != nil {
+		return DefaultAuditKeepDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return DefaultAuditKeepDays
+	}
+	return days
+}
@@ -0,0 +1,30 @@
+package store
+
+// BinarySampleBytes is how much of the content IsBinary inspects
+const BinarySampleBytes = 8192
+
+// IsBinary reports whether data looks like binary content: it contains a NUL
+// byte, or more than 30% of it is non-printable. Only the first 8KB is
+// checked. Every store and the queue use this, so content is classified the
+// same way wherever it comes from.
+func IsBinary(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	sampleSize := min(len(data), BinarySampleBytes)
+	nonPrintable := 0
+	for _, b := range data[:sampleSize] {
+		// Null byte is a strong indicator of binary content
+		if b == 0 {
+			return true
+		}
+
+		// Count non-printable characters (excluding common whitespace)
+		if b < 32 && b != '\n' && b != '\r' && b != '\t' {
+			nonPrintable++
+		}
+	}
+
+	return float64(nonPrintable)/float64(sampleSize) > 0.3
+}
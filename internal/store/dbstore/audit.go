@@ -0,0 +1,85 @@
+package dbstore
+
+import (
+	"fmt"
+
+	"github.com/yiblet/rem/internal/store"
+	"gorm.io/gorm"
+)
+
+// auditColumns are the history_items columns an audit entry records
+var auditColumns = []string{"id", "title", "sha256"}
+
+// auditBatchSize is how many audit entries are inserted per statement
+const auditBatchSize = 100
+
+// SetAuditSource sets the source recorded with later audit entries
+func (s *SQLiteStore) SetAuditSource(source string) {
+	s.auditSource.Store(source)
+}
+
+// AuditLog returns audit entries newest first; limit 0 returns all of them.
+// A database opened read-only from before the audit log has no entries.
+func (s *SQLiteStore) AuditLog(limit int) ([]*store.AuditEntry, error) {
+	if !s.hasAudit {
+		return []*store.AuditEntry{}, nil
+	}
+	query := s.db.Order("timestamp DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var models []*AuditModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+	entries := make([]*store.AuditEntry, len(models))
+	for i, model := range models {
+		entries[i] = model.ToAuditEntry()
+	}
+	return entries, nil
+}
+
+// audit records removed in the audit log. It runs after the removal has
+// committed, in a transaction of its own, so an entry that can't be written
+// is reported to OnAuditError rather than undoing the removal.
+func (s *SQLiteStore) audit(operation string, removed []HistoryItemModel) {
+	if err := s.writeAudit(operation, removed); err != nil && s.onAuditError != nil {
+		s.onAuditError(fmt.Errorf("failed to record %s in the audit log: %w", operation, err))
+	}
+}
+
+// writeAudit inserts an entry for each removed item, or one without an item
+// when there are none, and prunes entries older than audit_keep_days. It
+// writes nothing when audit_keep_days is 0.
+func (s *SQLiteStore) writeAudit(operation string, removed []HistoryItemModel) error {
+	keepDays := store.AuditKeepDays(s.Config())
+	if keepDays == 0 {
+		return nil
+	}
+	source, _ := s.auditSource.Load().(string)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		now := tx.NowFunc()
+		entries := make([]AuditModel, 0, max(len(removed), 1))
+		for _, item := range removed {
+			id := item.ID
+			entries = append(entries, AuditModel{
+				Timestamp: now, Operation: operation, ItemID: &id,
+				ItemTitle: item.Title, ItemSHA256: item.SHA256, Source: source,
+			})
+		}
+		if len(entries) == 0 {
+			entries = append(entries, AuditModel{Timestamp: now, Operation: operation, Source: source})
+		}
+		if err := tx.CreateInBatches(entries, auditBatchSize).Error; err != nil {
+			return err
+		}
+		return tx.Where("timestamp < ?", now.AddDate(0, 0, -keepDays)).Delete(&AuditModel{}).Error
+	})
+}
+
+// recordAudit records removed in the audit log, if the store keeps one
+func (s *sqliteHistoryStore) recordAudit(operation string, removed []HistoryItemModel) {
+	if s.audit != nil {
+		s.audit(operation, removed)
+	}
+}
@@ -0,0 +1,99 @@
+package dbstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+func TestAuditLog_Retention(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	st.db.NowFunc = func() time.Time { return clock }
+	deleteItem := func(title string) {
+		t.Helper()
+		item := mustCreate(t, st, title)
+		if err := st.History().Delete(item.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+	titles := func() []string {
+		t.Helper()
+		entries, err := st.AuditLog(0)
+		if err != nil {
+			t.Fatalf("AuditLog() error = %v", err)
+		}
+		var titles []string
+		for _, entry := range entries {
+			titles = append(titles, entry.ItemTitle)
+		}
+		return titles
+	}
+
+	deleteItem("old")
+	clock = clock.AddDate(0, 0, store.DefaultAuditKeepDays-1)
+	deleteItem("recent")
+	if got := titles(); len(got) != 2 {
+		t.Fatalf("entries = %q, want both within %d days", got, store.DefaultAuditKeepDays)
+	}
+
+	// The next entry prunes those past the default retention
+	clock = clock.AddDate(0, 0, 2)
+	deleteItem("new")
+	if got := titles(); len(got) != 2 || got[0] != "new" || got[1] != "recent" {
+		t.Errorf("entries = %q, want new and recent", got)
+	}
+
+	// And past a shorter audit_keep_days
+	if err := st.Config().Set(store.AuditKeepDaysKey, "1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	clock = clock.Add(36 * time.Hour)
+	deleteItem("latest")
+	if got := titles(); len(got) != 1 || got[0] != "latest" {
+		t.Errorf("entries = %q, want only latest", got)
+	}
+}
+
+// TestAuditLog_Ordering tests that the entry is written after the removal,
+// in a transaction of its own: a failed removal records nothing, and a
+// failed entry leaves the removal in place
+func TestAuditLog_Ordering(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+	var reported []error
+	st.onAuditError = func(err error) { reported = append(reported, err) }
+
+	// The removal fails: no entry
+	if err := st.History().Delete(999); err == nil {
+		t.Fatal("expected deleting a missing item to fail")
+	}
+	if entries, _ := st.AuditLog(0); len(entries) != 0 {
+		t.Errorf("expected no entry for a failed delete, got %d", len(entries))
+	}
+
+	// The entry fails: the removal stands and the failure is reported
+	kept := mustCreate(t, st, "kept")
+	deleted := mustCreate(t, st, "deleted")
+	if err := st.db.Exec("DROP TABLE audit_log").Error; err != nil {
+		t.Fatalf("failed to drop audit_log: %v", err)
+	}
+	if err := st.History().Delete(deleted.ID); err != nil {
+		t.Fatalf("Delete() error = %v, want the delete to succeed without its entry", err)
+	}
+	if _, err := st.History().Get(deleted.ID); err == nil {
+		t.Error("expected the item deleted despite the audit failure")
+	}
+	if err := st.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v, want the clear to succeed without its entries", err)
+	}
+	if _, err := st.History().Get(kept.ID); err == nil {
+		t.Error("expected the history cleared despite the audit failure")
+	}
+	if len(reported) != 2 {
+		t.Errorf("expected both audit failures reported, got %v", reported)
+	}
+}
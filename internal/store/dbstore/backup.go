@@ -0,0 +1,254 @@
+package dbstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Defaults for automatic backups, overridden by the backup_keep and
+// backup_max_bytes config values
+const (
+	DefaultBackupKeep     = 3
+	DefaultBackupMaxBytes = 256 << 20 // 256MiB
+)
+
+// autoBackupThreshold is the number of items DeleteOldest may remove
+// without taking a backup first
+const autoBackupThreshold = 10
+
+// backupTimeFormat names backup files so they sort oldest to newest
+const backupTimeFormat = "20060102-150405.000000"
+
+// BackupPolicy configures the automatic backups taken before clearing
+// history, deleting many old items, and migrating the schema
+type BackupPolicy struct {
+	Dir      string // directory backups are written to; empty disables automatic backups
+	Keep     int    // newest backups to keep; 0 disables automatic backups
+	MaxBytes int64  // databases larger than this are not backed up automatically; 0 means no limit
+}
+
+// BackupFile is a database backup on disk
+type BackupFile struct {
+	Path    string
+	Size    int64
+	Created time.Time
+}
+
+// Backup writes a consistent copy of the database to dest, which must not exist
+func (s *SQLiteStore) Backup(dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	if err := s.db.Exec("VACUUM INTO ?", dest).Error; err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Chmod(dest, PrivateFileMode); err != nil {
+		return fmt.Errorf("failed to restrict backup permissions: %w", err)
+	}
+	return nil
+}
+
+// BackupPath returns the path of a backup in dir taken at t
+func BackupPath(dir string, t time.Time) string {
+	return filepath.Join(dir, "rem-"+t.Format(backupTimeFormat)+".db")
+}
+
+// CreateBackup backs the database up into dir under a timestamped name and
+// prunes dir to the newest keep backups. It returns the new backup's path.
+func (s *SQLiteStore) CreateBackup(dir string, keep int) (string, error) {
+	dest := BackupPath(dir, time.Now())
+	if err := s.Backup(dest); err != nil {
+		return "", err
+	}
+	if err := PruneBackups(dir, keep); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// autoBackup takes a backup before a destructive operation if the policy and
+// the database size allow it
+func (s *SQLiteStore) autoBackup() error {
+	policy := s.backupPolicy()
+	if policy.Dir == "" || policy.Keep <= 0 || s.readOnly {
+		return nil
+	}
+	if policy.MaxBytes > 0 {
+		if info, err := os.Stat(s.dbPath); err == nil && info.Size() > policy.MaxBytes {
+			return nil
+		}
+	}
+	_, err := s.CreateBackup(policy.Dir, policy.Keep)
+	return err
+}
+
+// backupPolicy returns the configured policy with config overrides applied
+func (s *SQLiteStore) backupPolicy() BackupPolicy {
+	policy := s.backup
+	config := s.Config()
+	if value, err := config.Get("backup_keep"); err == nil {
+		if keep, err := strconv.Atoi(value); err == nil && keep >= 0 {
+			policy.Keep = keep
+		}
+	}
+	if value, err := config.Get("backup_max_bytes"); err == nil {
+		if limit, err := strconv.ParseInt(value, 10, 64); err == nil && limit >= 0 {
+			policy.MaxBytes = limit
+		}
+	}
+	return policy
+}
+
+// ListBackups returns the backups in dir, newest first. A missing directory
+// has no backups.
+func ListBackups(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []BackupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "rem-") || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, "rem-"), ".db")
+		created, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat backup: %w", err)
+		}
+		backups = append(backups, BackupFile{Path: filepath.Join(dir, name), Size: info.Size(), Created: created})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+	return backups, nil
+}
+
+// PruneBackups removes all but the newest keep backups in dir
+func PruneBackups(dir string, keep int) error {
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(max(keep, 0), len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the database at dbPath with the backup at src. The
+// database must not be open, in this process or another; see DatabaseInUse.
+func RestoreBackup(src, dbPath string) error {
+	// Refuse files that aren't rem databases before touching dbPath
+	check, err := NewSQLiteStoreWithOptions(src, Options{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%s is not a usable rem database: %w", src, err)
+	}
+	check.Close()
+
+	inUse, err := DatabaseInUse(dbPath)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return fmt.Errorf("database %s is in use by another rem process; close it and try again", dbPath)
+	}
+
+	// Copy next to the database, then rename over it so a failed copy
+	// leaves the current database in place
+	tmp := dbPath + ".restore"
+	if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	return nil
+}
+
+// DatabaseInUse reports whether another connection appears to hold the
+// database at dbPath. It is a heuristic: a hot journal or WAL file, or a
+// failure to take an exclusive lock without waiting, means it is in use.
+// Idle connections hold no lock and are not detected.
+func DatabaseInUse(dbPath string) (bool, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	for _, suffix := range []string{"-journal", "-wal"} {
+		if _, err := os.Stat(dbPath + suffix); err == nil {
+			return true, nil
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return false, fmt.Errorf("failed to open database: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false, err
+	}
+	defer sqlDB.Close()
+
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA busy_timeout = 0"); err != nil {
+		return false, fmt.Errorf("failed to set busy timeout: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		return true, nil
+	}
+	conn.ExecContext(ctx, "ROLLBACK")
+	return false, nil
+}
+
+// copyFile copies src to dest, creating or truncating dest
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,256 @@
+package dbstore
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// setupBackupDB creates a store that backs up into a temporary directory
+func setupBackupDB(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	backupDir := filepath.Join(tmpDir, "backups")
+
+	st, err := NewSQLiteStoreWithOptions(filepath.Join(tmpDir, "test.db"), Options{
+		Backup: BackupPolicy{Dir: backupDir, Keep: DefaultBackupKeep, MaxBytes: DefaultBackupMaxBytes},
+	})
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st, backupDir
+}
+
+// mustCreate stores content with a title, failing the test on error
+func mustCreate(t *testing.T, st *SQLiteStore, content string) *store.HistoryItem {
+	t.Helper()
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: content, Content: strings.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return item
+}
+
+// readContent returns an item's content, failing the test on error
+func readContent(t *testing.T, st *SQLiteStore, id uint) string {
+	t.Helper()
+	reader, err := st.History().GetContent(id)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(data)
+}
+
+func TestHistoryStore_ClearBacksUp(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+	item := mustCreate(t, st, "precious")
+
+	if err := st.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+
+	backups, err := ListBackups(backupDir)
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup, got %d", len(backups))
+	}
+
+	if got := readContent(t, openBackup(t, backups[0].Path), item.ID); got != "precious" {
+		t.Errorf("expected backup to hold the cleared item, got %q", got)
+	}
+
+	// Clearing an empty history has nothing to back up
+	if err := st.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if backups, _ := ListBackups(backupDir); len(backups) != 1 {
+		t.Errorf("expected no backup of an empty history, got %d backups", len(backups))
+	}
+}
+
+func TestHistoryStore_BackupRetention(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+
+	var paths []string
+	for i := 0; i < 5; i++ {
+		mustCreate(t, st, "item")
+		if err := st.History().Clear(store.ClearOptions{}); err != nil {
+			t.Fatalf("Clear() error = %v", err)
+		}
+		backups, _ := ListBackups(backupDir)
+		paths = append(paths, backups[0].Path)
+	}
+
+	backups, err := ListBackups(backupDir)
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != DefaultBackupKeep {
+		t.Fatalf("expected %d backups after pruning, got %d", DefaultBackupKeep, len(backups))
+	}
+	for i, backup := range backups {
+		if want := paths[len(paths)-1-i]; backup.Path != want {
+			t.Errorf("backup %d = %s, want %s (newest kept, newest first)", i, backup.Path, want)
+		}
+	}
+
+	// backup_keep overrides the policy; 0 disables automatic backups
+	if err := st.Config().Set("backup_keep", "0"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	mustCreate(t, st, "item")
+	if err := st.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if backups, _ := ListBackups(backupDir); len(backups) != DefaultBackupKeep {
+		t.Errorf("expected backup_keep=0 to skip the backup, got %d backups", len(backups))
+	}
+}
+
+func TestHistoryStore_BackupSkipsLargeDatabase(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+	if err := st.Config().Set("backup_max_bytes", "1"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	mustCreate(t, st, "item")
+	if err := st.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if backups, _ := ListBackups(backupDir); len(backups) != 0 {
+		t.Errorf("expected databases over backup_max_bytes to be skipped, got %d backups", len(backups))
+	}
+}
+
+func TestHistoryStore_DeleteOldestBacksUp(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+	for i := 0; i < autoBackupThreshold+5; i++ {
+		mustCreate(t, st, "item")
+	}
+
+	// Small trims don't take a backup
+	if err := st.History().DeleteOldest(2); err != nil {
+		t.Fatalf("DeleteOldest() error = %v", err)
+	}
+	if backups, _ := ListBackups(backupDir); len(backups) != 0 {
+		t.Fatalf("expected no backup for a small trim, got %d", len(backups))
+	}
+
+	if err := st.History().DeleteOldest(autoBackupThreshold + 1); err != nil {
+		t.Fatalf("DeleteOldest() error = %v", err)
+	}
+	if backups, _ := ListBackups(backupDir); len(backups) != 1 {
+		t.Errorf("expected a backup before a large trim, got %d", len(backups))
+	}
+}
+
+func TestRestoreBackup_RoundTrip(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+	item := mustCreate(t, st, "original content")
+
+	path, err := st.CreateBackup(backupDir, DefaultBackupKeep)
+	if err != nil {
+		t.Fatalf("CreateBackup() error = %v", err)
+	}
+	if err := st.History().Delete(item.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	st.Close()
+
+	if err := RestoreBackup(path, st.dbPath); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+
+	restored, err := NewSQLiteStore(st.dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen restored database: %v", err)
+	}
+	defer restored.Close()
+	if got := readContent(t, restored, item.ID); got != "original content" {
+		t.Errorf("expected restored content, got %q", got)
+	}
+
+	// Files that aren't databases are refused
+	if err := RestoreBackup(filepath.Join(backupDir, "missing.db"), st.dbPath); err == nil {
+		t.Error("expected an error restoring a missing backup")
+	}
+}
+
+func TestDatabaseInUse(t *testing.T) {
+	st, _ := setupBackupDB(t)
+	mustCreate(t, st, "item")
+
+	inUse, err := DatabaseInUse(st.dbPath)
+	if err != nil || inUse {
+		t.Fatalf("expected an idle database not to be in use, got %v, %v", inUse, err)
+	}
+
+	// Hold a write lock from another connection
+	sqlDB, _ := st.db.DB()
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("BEGIN IMMEDIATE error = %v", err)
+	}
+	defer conn.ExecContext(context.Background(), "ROLLBACK")
+
+	inUse, err = DatabaseInUse(st.dbPath)
+	if err != nil || !inUse {
+		t.Errorf("expected a locked database to be in use, got %v, %v", inUse, err)
+	}
+}
+
+func TestNewSQLiteStore_BacksUpBeforeMigrating(t *testing.T) {
+	st, backupDir := setupBackupDB(t)
+	mustCreate(t, st, "item")
+	for _, stmt := range []string{
+		"ALTER TABLE history_items DROP COLUMN note",
+		"DELETE FROM schema_migrations",
+		"UPDATE config SET value = '1' WHERE key = 'db_version'",
+	} {
+		if err := st.db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	st.Close()
+
+	reopened, err := NewSQLiteStoreWithOptions(st.dbPath, Options{Backup: st.backup})
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer reopened.Close()
+
+	backups, err := ListBackups(backupDir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected 1 backup before migrating, got %d (%v)", len(backups), err)
+	}
+	version, _, err := readSchemaVersion(openBackup(t, backups[0].Path).db)
+	if err != nil || version != 1 {
+		t.Errorf("expected the backup to keep schema version 1, got %d (%v)", version, err)
+	}
+}
+
+// openBackup opens a backup read-only for inspection
+func openBackup(t *testing.T, path string) *SQLiteStore {
+	t.Helper()
+	backup, err := NewSQLiteStoreWithOptions(path, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	t.Cleanup(func() { backup.Close() })
+	return backup
+}
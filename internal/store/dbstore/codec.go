@@ -0,0 +1,148 @@
+package dbstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// ChunkCodec transforms each chunk's bytes on their way into and out of the
+// database, such as to compress them. seq is the chunk's sequence number.
+type ChunkCodec interface {
+	Encode(seq int, plain []byte) ([]byte, error)
+	Decode(seq int, stored []byte) ([]byte, error)
+	// Name identifies the codec in the chain recorded on each item
+	Name() string
+}
+
+// PassthroughCodec stores chunks as they are
+var PassthroughCodec ChunkCodec = passthroughCodec{}
+
+// GzipCodec gzip-compresses each chunk
+var GzipCodec ChunkCodec = gzipCodec{}
+
+// builtinCodecs are the codecs every store can decode
+var builtinCodecs = []ChunkCodec{PassthroughCodec, GzipCodec}
+
+type passthroughCodec struct{}
+
+func (passthroughCodec) Name() string                                { return "none" }
+func (passthroughCodec) Encode(_ int, plain []byte) ([]byte, error)  { return plain, nil }
+func (passthroughCodec) Decode(_ int, stored []byte) ([]byte, error) { return stored, nil }
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string { return CompressionGzip }
+
+func (gzipCodec) Encode(_ int, plain []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(plain); err != nil {
+		return nil, fmt.Errorf("failed to compress chunk: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress chunk: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) Decode(_ int, stored []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(stored))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress chunk: %w", err)
+	}
+	// Never inflate past a chunk, even for a corrupt stream
+	plain, err := io.ReadAll(io.LimitReader(r, ChunkSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress chunk: %w", err)
+	}
+	if len(plain) > ChunkSize {
+		return nil, fmt.Errorf("decompressed chunk is over %d bytes", ChunkSize)
+	}
+	return plain, nil
+}
+
+// codecChain is the codecs applied to an item's chunks, in encoding order
+type codecChain []ChunkCodec
+
+// String returns the chain as recorded in an item's compression column:
+// comma-separated names with passthrough codecs left out, so content
+// stored as is records as empty
+func (c codecChain) String() string {
+	var names []string
+	for _, codec := range c {
+		if codec != PassthroughCodec {
+			names = append(names, codec.Name())
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// encode runs plain through every codec in order
+func (c codecChain) encode(seq int, plain []byte) ([]byte, error) {
+	data := plain
+	for _, codec := range c {
+		var err error
+		if data, err = codec.Encode(seq, data); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// decode undoes encode. rawSize, when known, is the length the data must
+// decode to.
+func (c codecChain) decode(seq int, stored []byte, rawSize int) ([]byte, error) {
+	data := stored
+	for i := len(c) - 1; i >= 0; i-- {
+		var err error
+		if data, err = c[i].Decode(seq, data); err != nil {
+			return nil, err
+		}
+	}
+	if len(c) > 0 && rawSize > 0 && len(data) != rawSize {
+		return nil, fmt.Errorf("decoded chunk is %d bytes, expected %d", len(data), rawSize)
+	}
+	return data, nil
+}
+
+// codecRegistry holds the codecs a store can decode and those it applies to
+// every chunk it writes
+type codecRegistry struct {
+	byName map[string]ChunkCodec
+	extra  codecChain // applied after compression
+}
+
+// newCodecRegistry returns a registry of the builtin codecs and extra, which
+// new chunks are encoded with
+func newCodecRegistry(extra []ChunkCodec) *codecRegistry {
+	r := &codecRegistry{byName: map[string]ChunkCodec{}, extra: extra}
+	for _, codec := range append(slices.Clone(builtinCodecs), extra...) {
+		r.byName[codec.Name()] = codec
+	}
+	return r
+}
+
+// chain returns the chain to write new chunks with, after compression
+func (r *codecRegistry) chain(compression ChunkCodec) codecChain {
+	return append(codecChain{compression}, r.extra...)
+}
+
+// lookup parses a chain recorded on an item
+func (r *codecRegistry) lookup(recorded string) (codecChain, error) {
+	if recorded == "" {
+		return nil, nil
+	}
+	var chain codecChain
+	for _, name := range strings.Split(recorded, ",") {
+		codec, ok := r.byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown chunk codec %q", name)
+		}
+		chain = append(chain, codec)
+	}
+	return chain, nil
+}
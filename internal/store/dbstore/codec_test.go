@@ -0,0 +1,163 @@
+package dbstore
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// xorCodec flips each byte with a key that depends on the chunk, standing in
+// for a cipher
+type xorCodec struct{}
+
+func (xorCodec) Name() string { return "xor" }
+
+func (xorCodec) Encode(seq int, plain []byte) ([]byte, error) {
+	out := make([]byte, len(plain))
+	for i, b := range plain {
+		out[i] = b ^ byte(seq+1)
+	}
+	return out, nil
+}
+
+func (c xorCodec) Decode(seq int, stored []byte) ([]byte, error) { return c.Encode(seq, stored) }
+
+// readAllAt reads content from reader after seeking to offset
+func readAllAt(t *testing.T, reader io.ReadSeeker, offset int64) []byte {
+	t.Helper()
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		t.Fatalf("Seek(%d) error = %v", offset, err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return data
+}
+
+func TestCodecChain_RoundTrip(t *testing.T) {
+	plain := compressibleContent(ChunkSize)
+	for _, chain := range []codecChain{nil, {PassthroughCodec}, {GzipCodec}, {GzipCodec, xorCodec{}}, {PassthroughCodec, xorCodec{}}} {
+		stored, err := chain.encode(3, plain)
+		if err != nil {
+			t.Fatalf("%q: encode() error = %v", chain, err)
+		}
+		got, err := chain.decode(3, stored, len(plain))
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%q: expected the chunk to round-trip, got %d bytes (%v)", chain, len(got), err)
+		}
+		if _, err := chain.decode(3, stored, len(plain)-1); len(chain) > 0 && err == nil {
+			t.Errorf("%q: expected an error for the wrong raw size", chain)
+		}
+	}
+
+	if got := (codecChain{PassthroughCodec, GzipCodec, xorCodec{}}).String(); got != "gzip,xor" {
+		t.Errorf("String() = %q, want passthrough left out", got)
+	}
+	registry := newCodecRegistry([]ChunkCodec{xorCodec{}})
+	if chain, err := registry.lookup("gzip,xor"); err != nil || chain.String() != "gzip,xor" {
+		t.Errorf("lookup() = %q, %v", chain, err)
+	}
+	if _, err := newCodecRegistry(nil).lookup("gzip,xor"); err == nil || !strings.Contains(err.Error(), `"xor"`) {
+		t.Errorf("expected an unknown codec error, got %v", err)
+	}
+}
+
+func TestChunkedReader_VariableChunks(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Chunks of uneven plain length, each stored in a different encoded size
+	parts := []string{"first chunk\n", strings.Repeat("b", 5000), "", "c", strings.Repeat("dd\n", 700)}
+	content := strings.Join(parts, "")
+	item := &HistoryItemModel{Title: "uneven", Size: int64(len(content)), Compression: "gzip"}
+	if err := st.db.Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, part := range parts {
+		data, err := GzipCodec.Encode(i, []byte(part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := st.db.Create(&FileChunkModel{HistoryID: item.ID, Sequence: i, Data: data, RawSize: len(part)}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := st.History().GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	for _, offset := range []int64{0, 5, 12, 13, 5011, 5012, 5013, 6000, int64(len(content)) - 1, int64(len(content))} {
+		if got := readAllAt(t, reader, offset); string(got) != content[offset:] {
+			t.Errorf("offset %d: read %d bytes, want %d", offset, len(got), len(content)-int(offset))
+		}
+	}
+}
+
+func TestCodecs_MixedItems(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := compressibleContent(3*ChunkSize + 99)
+	st.Config().Set("compress_min_bytes", "0")
+	plain, err := st.History().Create(&store.CreateHistoryInput{Title: "old", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	st.Config().Set("compress_min_bytes", "1024")
+	gzipped, err := st.History().Create(&store.CreateHistoryInput{Title: "new", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	st.Close()
+
+	// Reopened with an extra codec, new items use it and old ones still read
+	st, err = NewSQLiteStoreWithOptions(dbPath, Options{Codecs: []ChunkCodec{xorCodec{}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	xored, err := st.History().Create(&store.CreateHistoryInput{Title: "xor", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for id, want := range map[uint]string{plain.ID: "", gzipped.ID: "gzip", xored.ID: "gzip,xor"} {
+		if got := itemCompression(t, st, id); got != want {
+			t.Errorf("item %d: codecs %q, want %q", id, got, want)
+		}
+		reader, err := st.History().GetContent(id)
+		if err != nil {
+			t.Fatalf("GetContent(%d) error = %v", id, err)
+		}
+		offset := int64(2*ChunkSize + 17)
+		if got := readAllAt(t, reader, offset); !bytes.Equal(got, content[offset:]) {
+			t.Errorf("item %d: expected the content after seeking to match", id)
+		}
+		reader.Close()
+	}
+	results, err := st.History().Search(&store.SearchQuery{Pattern: `"line": 1500,`, SearchContent: true})
+	if err != nil || len(results) != 3 {
+		t.Errorf("expected search to read all three items, got %d results (%v)", len(results), err)
+	}
+	st.Close()
+
+	// Without the codec, its items can't be read but the others still can
+	st, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := st.History().GetContent(xored.ID); err == nil || !strings.Contains(err.Error(), "unknown chunk codec") {
+		t.Errorf("expected an unknown codec error, got %v", err)
+	}
+	if _, err := st.History().GetContent(gzipped.ID); err != nil {
+		t.Errorf("GetContent() error = %v", err)
+	}
+}
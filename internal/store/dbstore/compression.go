@@ -0,0 +1,121 @@
+package dbstore
+
+import (
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// CompressionGzip is the name GzipCodec records on an item whose chunks
+// are each gzip-compressed
+const CompressionGzip = "gzip"
+
+// DefaultCompressMinBytes is the size above which text items are compressed
+// when compress_min_bytes is not configured
+const DefaultCompressMinBytes = 64 * 1024
+
+// Stats summarizes the space used by stored items
+type Stats struct {
+	Items           int
+	CompressedItems int
+	LogicalBytes    int64 // uncompressed content size
+	PhysicalBytes   int64 // size of the stored chunk data
+}
+
+// compressMinBytes returns the configured compression threshold; 0 disables
+// compression
+func compressMinBytes(db *gorm.DB) int64 {
+	if value, err := (&sqliteConfigStore{db: db}).Get("compress_min_bytes"); err == nil {
+		if limit, err := strconv.ParseInt(value, 10, 64); err == nil && limit >= 0 {
+			return limit
+		}
+	}
+	return DefaultCompressMinBytes
+}
+
+// Stats reports how much content is stored and how much space it takes
+func (s *SQLiteStore) Stats() (Stats, error) {
+	var stats Stats
+	var items struct {
+		Count      int
+		Compressed int
+		Logical    int64
+	}
+	if err := s.db.Model(&HistoryItemModel{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(',' || compression || ',' LIKE '%,gzip,%'), 0) AS compressed, COALESCE(SUM(size), 0) AS logical").
+		Scan(&items).Error; err != nil {
+		return stats, fmt.Errorf("failed to sum item sizes: %w", err)
+	}
+	if err := s.db.Model(&FileChunkModel{}).
+		Select("COALESCE(SUM(length(data)), 0)").
+		Scan(&stats.PhysicalBytes).Error; err != nil {
+		return stats, fmt.Errorf("failed to sum chunk sizes: %w", err)
+	}
+	stats.Items = items.Count
+	stats.CompressedItems = items.Compressed
+	stats.LogicalBytes = items.Logical
+	return stats, nil
+}
+
+// Recompress rewrites an item's chunks to match the current compression
+// setting and the store's codecs, reporting whether anything changed. Chunk boundaries, Size and
+// SHA256 are unchanged, since they describe the uncompressed content.
+func (s *SQLiteStore) Recompress(id uint) (bool, error) {
+	changed := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var item HistoryItemModel
+		if err := tx.Select("id", "is_binary", "size", "compression").First(&item, id).Error; err != nil {
+			return fmt.Errorf("item not found: %d", id)
+		}
+		compression := PassthroughCodec
+		if minBytes := compressMinBytes(tx); !item.IsBinary && minBytes > 0 && item.Size > minBytes {
+			compression = GzipCodec
+		}
+		want := s.codecs.chain(compression)
+		if want.String() == item.Compression {
+			return nil
+		}
+		have, err := s.codecs.lookup(item.Compression)
+		if err != nil {
+			return fmt.Errorf("item %d: %w", id, err)
+		}
+
+		if err := recodeChunks(tx, id, have, want); err != nil {
+			return fmt.Errorf("item %d: %w", id, err)
+		}
+		if err := tx.Model(&HistoryItemModel{}).Where("id = ?", id).Update("compression", want.String()).Error; err != nil {
+			return fmt.Errorf("failed to update compression: %w", err)
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// recodeChunks rewrites the chunks of item id from the have codec chain to
+// want, one chunk at a time
+func recodeChunks(tx *gorm.DB, id uint, have, want codecChain) error {
+	for sequence := 0; ; sequence++ {
+		var chunk FileChunkModel
+		err := tx.Where("history_id = ? AND sequence = ?", id, sequence).Limit(1).Find(&chunk).Error
+		if err != nil {
+			return fmt.Errorf("failed to load chunk %d: %w", sequence, err)
+		}
+		if chunk.ID == 0 {
+			return nil
+		}
+		raw, err := have.decode(chunk.Sequence, chunk.Data, chunk.RawSize)
+		if err != nil {
+			return fmt.Errorf("chunk %d: %w", chunk.Sequence, err)
+		}
+		data, err := want.encode(chunk.Sequence, raw)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&FileChunkModel{}).Where("id = ?", chunk.ID).
+			Updates(map[string]any{"data": data, "raw_size": len(raw)}).Error; err != nil {
+			return fmt.Errorf("failed to rewrite chunk: %w", err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package dbstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+	"gorm.io/gorm"
+)
+
+// compressibleContent returns size bytes of repetitive JSON-like text
+func compressibleContent(size int) []byte {
+	var b bytes.Buffer
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "{\"line\": %d, \"level\": \"info\", \"msg\": \"request served\"}\n", i)
+	}
+	return b.Bytes()[:size]
+}
+
+// itemCompression returns the stored compression of an item
+func itemCompression(t *testing.T, st *SQLiteStore, id uint) string {
+	t.Helper()
+	var model HistoryItemModel
+	if err := st.db.Select("compression").First(&model, id).Error; err != nil {
+		t.Fatalf("failed to load item: %v", err)
+	}
+	return model.Compression
+}
+
+func TestCompression_RoundTrip(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	content := compressibleContent(5*ChunkSize + 1234)
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "log", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := itemCompression(t, st, item.ID); got != CompressionGzip {
+		t.Fatalf("expected a large text item to be compressed, got %q", got)
+	}
+
+	// Size and SHA256 describe the uncompressed content
+	sum := sha256.Sum256(content)
+	if item.Size != int64(len(content)) || item.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("expected uncompressed size and hash, got %d and %s", item.Size, item.SHA256)
+	}
+
+	reader, err := st.History().GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || !bytes.Equal(data, content) {
+		t.Fatalf("expected content to round-trip, got %d bytes (%v)", len(data), err)
+	}
+
+	// Seek into the middle of a compressed chunk
+	offset := int64(2*ChunkSize + 100)
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	buf := make([]byte, 1000)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		t.Fatalf("ReadFull() error = %v", err)
+	}
+	if !bytes.Equal(buf, content[offset:offset+1000]) {
+		t.Error("expected the read after seeking to match the content at that offset")
+	}
+
+	// Compressed chunks take less space and record their raw length
+	var chunks []FileChunkModel
+	st.db.Where("history_id = ?", item.ID).Order("sequence").Find(&chunks)
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if chunk.RawSize != ChunkSize || len(chunk.Data) >= ChunkSize {
+			t.Errorf("chunk %d: expected %d raw bytes stored in fewer, got raw %d stored %d", chunk.Sequence, ChunkSize, chunk.RawSize, len(chunk.Data))
+		}
+	}
+
+	// Search reads compressed content
+	results, err := st.History().Search(&store.SearchQuery{Pattern: `"line": 2000,`, SearchContent: true})
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected search to find the compressed item, got %d results (%v)", len(results), err)
+	}
+}
+
+// TestGetContentPrefix_ReadsLeadingChunks tests that a prefix is read from
+// only the chunks holding it, in one query, compressed or not
+func TestGetContentPrefix_ReadsLeadingChunks(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Count the chunk queries and the rows they return
+	var queries, rows int
+	st.db.Callback().Query().After("gorm:query").Register("test:count_chunks", func(db *gorm.DB) {
+		if db.Statement.Table == "file_chunks" {
+			queries++
+			rows += int(db.Statement.RowsAffected)
+		}
+	})
+
+	content := compressibleContent(store.MaxContentPrefix + 5*ChunkSize)
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "log", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := itemCompression(t, st, item.ID); got != CompressionGzip {
+		t.Fatalf("expected the item compressed, got %q", got)
+	}
+
+	tests := []struct {
+		n, want, chunks int
+	}{
+		{100, 100, 1},
+		{ChunkSize, ChunkSize, 1},
+		{ChunkSize + 1, ChunkSize + 1, 2},
+		{1 << 30, store.MaxContentPrefix, store.MaxContentPrefix / ChunkSize},
+	}
+	for _, tt := range tests {
+		queries, rows = 0, 0
+		prefix, err := st.History().GetContentPrefix(item.ID, tt.n)
+		if err != nil {
+			t.Fatalf("GetContentPrefix(%d) error = %v", tt.n, err)
+		}
+		if !bytes.Equal(prefix, content[:tt.want]) {
+			t.Errorf("GetContentPrefix(%d) = %d bytes, want the first %d", tt.n, len(prefix), tt.want)
+		}
+		if queries != 1 || rows != tt.chunks {
+			t.Errorf("GetContentPrefix(%d) read %d chunks in %d queries, want %d in one", tt.n, rows, queries, tt.chunks)
+		}
+	}
+}
+
+func TestCompression_Threshold(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	create := func(content []byte) uint {
+		item, err := st.History().Create(&store.CreateHistoryInput{Title: "item", Content: bytes.NewReader(content)})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return item.ID
+	}
+
+	small := create(compressibleContent(DefaultCompressMinBytes))
+	binary := create(append([]byte{0, 1, 2}, make([]byte, 3*ChunkSize)...))
+	st.Config().Set("compress_min_bytes", "0")
+	disabled := create(compressibleContent(3 * ChunkSize))
+
+	for name, id := range map[string]uint{"small": small, "binary": binary, "disabled": disabled} {
+		if got := itemCompression(t, st, id); got != "" {
+			t.Errorf("%s: expected no compression, got %q", name, got)
+		}
+	}
+}
+
+func TestRecompress(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Store uncompressed, then turn compression on and migrate
+	st.Config().Set("compress_min_bytes", "0")
+	content := compressibleContent(3*ChunkSize + 7)
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "dump", Content: bytes.NewReader(content)})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	before, _ := st.Stats()
+
+	st.Config().Set("compress_min_bytes", "1024")
+	if changed, err := st.Recompress(item.ID); err != nil || !changed {
+		t.Fatalf("Recompress() = %v, %v; want a change", changed, err)
+	}
+	if changed, _ := st.Recompress(item.ID); changed {
+		t.Error("expected a second Recompress to change nothing")
+	}
+
+	after, err := st.Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if after.LogicalBytes != int64(len(content)) || after.PhysicalBytes >= before.PhysicalBytes || after.CompressedItems != 1 {
+		t.Errorf("expected the same logical bytes in less space, got %+v (was %+v)", after, before)
+	}
+
+	reader, err := st.History().GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != item.SHA256 {
+		t.Error("expected recompressed content to match the stored hash")
+	}
+
+	// Raising the threshold decompresses again
+	st.Config().Set("compress_min_bytes", strings.Repeat("9", 9))
+	if changed, err := st.Recompress(item.ID); err != nil || !changed || itemCompression(t, st, item.ID) != "" {
+		t.Errorf("expected Recompress to decompress, got changed=%v err=%v", changed, err)
+	}
+}
@@ -0,0 +1,52 @@
+package dbstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// GenerationKey is the config row counting changes to the history
+const GenerationKey = "generation"
+
+// mutate runs fn in a transaction that also bumps the generation, so a
+// change and the generation it makes are committed together
+func (s *sqliteHistoryStore) mutate(fn func(tx *gorm.DB) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return bumpGeneration(tx)
+	})
+}
+
+// bumpGeneration adds one to the generation, starting it at 1
+func bumpGeneration(tx *gorm.DB) error {
+	now := tx.NowFunc()
+	err := tx.Exec(`INSERT INTO config (key, value, created_at, updated_at) VALUES (?, '1', ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = CAST(value AS INTEGER) + 1, updated_at = excluded.updated_at`,
+		GenerationKey, now, now).Error
+	if err != nil {
+		return fmt.Errorf("failed to update generation: %w", err)
+	}
+	return nil
+}
+
+// Generation returns the number of changes made to the history; a database
+// no change has been recorded in is at 0
+func (s *sqliteHistoryStore) Generation() (uint64, error) {
+	var model ConfigItemModel
+	if err := s.db.First(&model, "key = ?", GenerationKey).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get generation: %w", err)
+	}
+	generation, err := strconv.ParseUint(model.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid generation %q: %w", model.Value, err)
+	}
+	return generation, nil
+}
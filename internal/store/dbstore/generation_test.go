@@ -0,0 +1,35 @@
+package dbstore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+func TestGeneration_PersistsAcrossReopen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "rem.db")
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	if generation, err := st.History().Generation(); err != nil || generation != 0 {
+		t.Errorf("Generation() of a new database = %d, %v; want 0", generation, err)
+	}
+	for _, content := range []string{"one", "two", "three"} {
+		if _, err := st.History().Create(&store.CreateHistoryInput{Content: strings.NewReader(content)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	st.Close()
+
+	st, err = NewSQLiteStoreWithOptions(dbPath, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("reopening error = %v", err)
+	}
+	defer st.Close()
+	if generation, err := st.History().Generation(); err != nil || generation != 3 {
+		t.Errorf("Generation() after reopening = %d, %v; want 3", generation, err)
+	}
+}
@@ -0,0 +1,58 @@
+package dbstore
+
+import "fmt"
+
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// reports, or nil if the database is intact
+func (s *SQLiteStore) IntegrityCheck() ([]string, error) {
+	var rows []string
+	if err := s.db.Raw("PRAGMA integrity_check").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	if len(rows) == 1 && rows[0] == "ok" {
+		return nil, nil
+	}
+	return rows, nil
+}
+
+// ForeignKeysEnabled reports whether SQLite enforces foreign keys on the
+// store's connection, which deleting an item relies on to remove its chunks
+func (s *SQLiteStore) ForeignKeysEnabled() (bool, error) {
+	var enabled int
+	if err := s.db.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+		return false, fmt.Errorf("failed to read foreign_keys: %w", err)
+	}
+	return enabled == 1, nil
+}
+
+// OrphanedChunks counts the chunk rows whose item no longer exists, such as
+// those left by a store interrupted before its item was written
+func (s *SQLiteStore) OrphanedChunks() (int64, error) {
+	var count int64
+	err := s.db.Model(&FileChunkModel{}).
+		Where("history_id NOT IN (?)", s.db.Model(&HistoryItemModel{}).Select("id")).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count orphaned chunks: %w", err)
+	}
+	return count, nil
+}
+
+// StaleAuditEntries counts the audit entries recording the removal of an
+// item that is still in the history, as a removal rolled back after its entry
+// was written, or a database restored around the log, would leave. An item
+// created after the entry, under an ID reused since, doesn't count.
+func (s *SQLiteStore) StaleAuditEntries() (int64, error) {
+	if !s.hasAudit {
+		return 0, nil
+	}
+	var count int64
+	err := s.db.Model(&AuditModel{}).
+		Joins("JOIN history_items ON history_items.id = audit_log.item_id").
+		Where("history_items.created_at <= audit_log.timestamp").
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stale audit entries: %w", err)
+	}
+	return count, nil
+}
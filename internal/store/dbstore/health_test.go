@@ -0,0 +1,73 @@
+package dbstore
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+func TestHealthChecks(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if _, err := st.History().Create(&store.CreateHistoryInput{Title: "kept", Content: strings.NewReader("content")}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if problems, err := st.IntegrityCheck(); err != nil || problems != nil {
+		t.Errorf("IntegrityCheck() = %v, %v; want no problems", problems, err)
+	}
+	if enabled, err := st.ForeignKeysEnabled(); err != nil || !enabled {
+		t.Errorf("ForeignKeysEnabled() = %v, %v; want true", enabled, err)
+	}
+	if count, err := st.OrphanedChunks(); err != nil || count != 0 {
+		t.Errorf("OrphanedChunks() = %d, %v; want 0", count, err)
+	}
+
+	// A chunk written without its item, as an interrupted store leaves
+	if err := st.db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		t.Fatalf("failed to disable foreign keys: %v", err)
+	}
+	if err := st.db.Create(&FileChunkModel{HistoryID: 999, Data: []byte("lost")}).Error; err != nil {
+		t.Fatalf("failed to insert chunk: %v", err)
+	}
+	if enabled, _ := st.ForeignKeysEnabled(); enabled {
+		t.Error("ForeignKeysEnabled() = true after disabling them")
+	}
+	if count, err := st.OrphanedChunks(); err != nil || count != 1 {
+		t.Errorf("OrphanedChunks() = %d, %v; want 1", count, err)
+	}
+}
+
+func TestStaleAuditEntries(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	deleted := mustCreate(t, st, "deleted")
+	if err := st.History().Delete(deleted.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if count, err := st.StaleAuditEntries(); err != nil || count != 0 {
+		t.Errorf("StaleAuditEntries() = %d, %v; want 0", count, err)
+	}
+
+	// An entry for an item still there, as a removal rolled back after its
+	// entry was written leaves
+	kept := mustCreate(t, st, "kept")
+	entry := AuditModel{Timestamp: time.Now().UTC().Add(time.Minute), Operation: store.AuditDelete, ItemID: &kept.ID, ItemTitle: kept.Title}
+	if err := st.db.Create(&entry).Error; err != nil {
+		t.Fatalf("failed to insert audit entry: %v", err)
+	}
+	if count, err := st.StaleAuditEntries(); err != nil || count != 1 {
+		t.Errorf("StaleAuditEntries() = %d, %v; want 1", count, err)
+	}
+
+	// A later item reusing an audited ID is not the one removed
+	if err := st.db.Model(&entry).Update("timestamp", kept.CreatedAt.Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("failed to update audit entry: %v", err)
+	}
+	if count, err := st.StaleAuditEntries(); err != nil || count != 0 {
+		t.Errorf("StaleAuditEntries() for a reused ID = %d, %v; want 0", count, err)
+	}
+}
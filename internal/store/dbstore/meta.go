@@ -0,0 +1,88 @@
+package dbstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yiblet/rem/internal/store"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// itemExists returns an error unless the item with id exists
+func itemExists(db *gorm.DB, id uint) error {
+	var item HistoryItemModel
+	if err := db.Select("id").First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("item not found: %d", id)
+		}
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+	return nil
+}
+
+// SetMeta sets one of an item's metadata values, replacing any it had
+func (s *sqliteHistoryStore) SetMeta(id uint, key, value string) error {
+	if err := store.ValidateMeta(key, value); err != nil {
+		return err
+	}
+	return s.mutate(func(tx *gorm.DB) error {
+		if err := itemExists(tx, id); err != nil {
+			return err
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "history_id"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value"}),
+		}).Create(&ItemMetaModel{HistoryID: id, Key: key, Value: value}).Error
+		if err != nil {
+			return fmt.Errorf("failed to set metadata: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetMeta returns an item's metadata. Databases from before metadata, opened
+// read-only, have none.
+func (s *sqliteHistoryStore) GetMeta(id uint) (map[string]string, error) {
+	if err := itemExists(s.db, id); err != nil {
+		return nil, err
+	}
+	meta := map[string]string{}
+	if !s.hasMeta {
+		return meta, nil
+	}
+	var rows []ItemMetaModel
+	if err := s.db.Where("history_id = ?", id).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get metadata: %w", err)
+	}
+	for _, row := range rows {
+		meta[row.Key] = row.Value
+	}
+	return meta, nil
+}
+
+// DeleteMeta removes one of an item's metadata keys
+func (s *sqliteHistoryStore) DeleteMeta(id uint, key string) error {
+	return s.mutate(func(tx *gorm.DB) error {
+		if err := itemExists(tx, id); err != nil {
+			return err
+		}
+		result := tx.Where("history_id = ? AND key = ?", id, key).Delete(&ItemMetaModel{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete metadata: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("item %d has no metadata key %q", id, key)
+		}
+		return nil
+	})
+}
+
+// whereMetaEquals narrows query to items with each key in meta set to its
+// value
+func whereMetaEquals(query *gorm.DB, meta map[string]string) *gorm.DB {
+	for key, value := range meta {
+		query = query.Where("EXISTS (SELECT 1 FROM item_meta WHERE item_meta.history_id = history_items.id AND item_meta.key = ? AND item_meta.value = ?)", key, value)
+	}
+	return query
+}
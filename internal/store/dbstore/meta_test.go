@@ -0,0 +1,87 @@
+package dbstore
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// metaRows counts the item_meta rows left for an item
+func metaRows(t *testing.T, st *SQLiteStore, id uint) int64 {
+	t.Helper()
+	var count int64
+	if err := st.db.Model(&ItemMetaModel{}).Where("history_id = ?", id).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count metadata: %v", err)
+	}
+	return count
+}
+
+func TestMeta_CascadeOnDelete(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "ticket", Content: strings.NewReader("body")})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	st.History().SetMeta(item.ID, "ticket", "OPS-12")
+	st.History().SetMeta(item.ID, "lang", "go")
+	if err := st.History().Delete(item.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n := metaRows(t, st, item.ID); n != 0 {
+		t.Errorf("expected deleting the item to delete its metadata, %d row(s) left", n)
+	}
+}
+
+func TestMeta_MigratesVersion4(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "v4.db")
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "kept", Content: strings.NewReader("survives migration")})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP TABLE item_meta",
+		"DELETE FROM schema_migrations WHERE version = 5",
+		"UPDATE config SET value = '4' WHERE key = 'db_version'",
+	} {
+		if err := st.db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	st.Close()
+
+	// Read-only, a version 4 database has no metadata
+	ro, err := NewSQLiteStoreWithOptions(dbPath, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("read-only open failed: %v", err)
+	}
+	if meta, err := ro.History().GetMeta(item.ID); err != nil || len(meta) != 0 {
+		t.Errorf("GetMeta() = %v, %v; want no metadata", meta, err)
+	}
+	if results, err := ro.History().Search(&store.SearchQuery{MetaEquals: map[string]string{"a": "b"}}); err != nil || len(results) != 0 {
+		t.Errorf("Search() = %d results, %v; want none", len(results), err)
+	}
+	ro.Close()
+
+	st, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer st.Close()
+	if err := st.History().SetMeta(item.ID, "source", "https://example.com"); err != nil {
+		t.Fatalf("SetMeta() after migrating error = %v", err)
+	}
+	if err := st.History().Delete(item.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n := metaRows(t, st, item.ID); n != 0 {
+		t.Errorf("expected the migrated table to cascade deletes, %d row(s) left", n)
+	}
+}
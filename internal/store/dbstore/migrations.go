@@ -0,0 +1,242 @@
+package dbstore
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SchemaVersion is the database schema version this binary reads and writes.
+// Bump it together with a new entry in migrations.
+const SchemaVersion = 10
+
+// ErrNewerSchema is returned when a database was written by a newer rem
+var ErrNewerSchema = errors.New("database was created by a newer version of rem")
+
+// ErrNotSQLite is returned when the database path names a file that isn't
+// a SQLite database
+var ErrNotSQLite = errors.New("not a SQLite database")
+
+// migration upgrades the schema from version-1 to version
+type migration struct {
+	version int
+	name    string
+	up      func(tx *gorm.DB) error
+}
+
+// migrations lists every schema upgrade in ascending version order.
+// Version 1 is the original schema created by AutoMigrate.
+var migrations = []migration{
+	{
+		version: 2,
+		name:    "add_history_note",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&HistoryItemModel{}, "note") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&HistoryItemModel{}, "Note")
+		},
+	},
+	{
+		version: 3,
+		name:    "add_chunk_compression",
+		up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&HistoryItemModel{}, "compression") {
+				if err := tx.Migrator().AddColumn(&HistoryItemModel{}, "Compression"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasColumn(&FileChunkModel{}, "raw_size") {
+				if err := tx.Migrator().AddColumn(&FileChunkModel{}, "RawSize"); err != nil {
+					return err
+				}
+			}
+			// Existing chunks are uncompressed
+			return tx.Exec("UPDATE file_chunks SET raw_size = length(data) WHERE raw_size = 0").Error
+		},
+	},
+	{
+		version: 4,
+		name:    "add_original_file_metadata",
+		up: func(tx *gorm.DB) error {
+			for _, field := range []string{"OriginalName", "OriginalModTime", "OriginalMode"} {
+				if tx.Migrator().HasColumn(&HistoryItemModel{}, field) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&HistoryItemModel{}, field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version: 5,
+		name:    "add_item_meta",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&ItemMetaModel{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&ItemMetaModel{})
+		},
+	},
+	{
+		version: 6,
+		name:    "add_item_sections",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&ItemSectionModel{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&ItemSectionModel{})
+		},
+	},
+	{
+		version: 7,
+		name:    "add_history_size_index",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasIndex(&HistoryItemModel{}, "Size") {
+				return nil
+			}
+			return tx.Migrator().CreateIndex(&HistoryItemModel{}, "Size")
+		},
+	},
+	{
+		version: 8,
+		name:    "unique_chunk_sequence",
+		up: func(tx *gorm.DB) error {
+			// Two chunks at one position would be read twice; keep the one
+			// written first, as that write had the position to itself
+			err := tx.Exec(`DELETE FROM file_chunks WHERE id NOT IN
+				(SELECT MIN(id) FROM file_chunks GROUP BY history_id, sequence)`).Error
+			if err != nil {
+				return err
+			}
+			if tx.Migrator().HasIndex(&FileChunkModel{}, "idx_history_seq") {
+				if err := tx.Migrator().DropIndex(&FileChunkModel{}, "idx_history_seq"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateIndex(&FileChunkModel{}, "idx_history_seq")
+		},
+	},
+	{
+		version: 9,
+		name:    "utc_timestamps",
+		up: func(tx *gorm.DB) error {
+			// Times were stored with the offset of whoever wrote them, so
+			// ordering by the text mixed up items written in other zones
+			var rows []struct {
+				ID                   uint
+				Timestamp            time.Time
+				CreatedAt, UpdatedAt time.Time
+			}
+			if err := tx.Model(&HistoryItemModel{}).Select("id, timestamp, created_at, updated_at").Find(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				err := tx.Exec("UPDATE history_items SET timestamp = ?, created_at = ?, updated_at = ? WHERE id = ?",
+					row.Timestamp.UTC(), row.CreatedAt.UTC(), row.UpdatedAt.UTC(), row.ID).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version: 10,
+		name:    "add_audit_log",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&AuditModel{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&AuditModel{})
+		},
+	},
+}
+
+// SchemaMigrationModel records a migration that has been applied
+type SchemaMigrationModel struct {
+	Version   int       `gorm:"primaryKey"`
+	Name      string    `gorm:"size:100;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table name for SchemaMigrationModel
+func (SchemaMigrationModel) TableName() string {
+	return "schema_migrations"
+}
+
+// readSchemaVersion returns the db_version stored in the database.
+// exists is false for a brand-new database with no tables yet.
+func readSchemaVersion(db *gorm.DB) (version int, exists bool, err error) {
+	if !db.Migrator().HasTable(&HistoryItemModel{}) {
+		return 0, false, nil
+	}
+	if !db.Migrator().HasTable(&ConfigItemModel{}) {
+		// History without config predates versioning
+		return 1, true, nil
+	}
+
+	var model ConfigItemModel
+	err = db.Where("key = ?", "db_version").Limit(1).Find(&model).Error
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to read db_version: %w", err)
+	}
+	if model.Key == "" {
+		return 1, true, nil
+	}
+
+	version, err = strconv.Atoi(model.Value)
+	if err != nil {
+		return 0, true, fmt.Errorf("invalid db_version %q: %w", model.Value, err)
+	}
+	return version, true, nil
+}
+
+// runMigrations applies every migration newer than from. Each migration runs
+// in its own transaction together with its bookkeeping row and the db_version
+// bump, so a failure leaves the database at the last completed version.
+func runMigrations(db *gorm.DB, from int) error {
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return recordMigration(tx, m)
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+// markMigrationsApplied records every migration as applied, for new databases
+// whose schema AutoMigrate created at the current version
+func markMigrationsApplied(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range migrations {
+			if err := recordMigration(tx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// recordMigration stores the migration row and bumps db_version to its version
+func recordMigration(tx *gorm.DB, m migration) error {
+	row := &SchemaMigrationModel{Version: m.version, Name: m.name, AppliedAt: tx.NowFunc()}
+	if err := tx.Save(row).Error; err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return (&sqliteConfigStore{db: tx}).Set("db_version", strconv.Itoa(m.version))
+}
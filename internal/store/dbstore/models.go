@@ -1,6 +1,7 @@
 package dbstore
 
 import (
+	"io/fs"
 	"time"
 
 	"github.com/yiblet/rem/internal/store"
@@ -12,17 +13,27 @@ const ChunkSize = 32 * 1024
 // HistoryItemModel represents a history item in the database.
 // Content is stored separately in chunks, not in this table.
 type HistoryItemModel struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement"`
-	Title     string    `gorm:"size:80;not null;index"` // User-provided or auto-generated title
-	Timestamp time.Time `gorm:"not null;index"`         // Creation timestamp for LIFO ordering
-	IsBinary  bool      `gorm:"not null;default:false"` // Binary content flag
-	Size      int64     `gorm:"not null"`               // Total content size in bytes
-	SHA256    string    `gorm:"size:64"`                // SHA256 hash (computed during write)
-	CreatedAt time.Time `gorm:"autoCreateTime"`         // GORM managed timestamp
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`         // GORM managed timestamp
-
-	// One-to-many relationship with file chunks
-	Chunks []FileChunkModel `gorm:"foreignKey:HistoryID;constraint:OnDelete:CASCADE"`
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	Title       string    `gorm:"size:80;not null;index"`        // User-provided or auto-generated title
+	Timestamp   time.Time `gorm:"not null;index"`                // Creation timestamp for LIFO ordering
+	IsBinary    bool      `gorm:"not null;default:false"`        // Binary content flag
+	Size        int64     `gorm:"not null;index"`                // Total content size in bytes, indexed for ListOrdered (schema version 7)
+	SHA256      string    `gorm:"size:64;index"`                 // SHA256 hash (computed during write)
+	Note        string    `gorm:"type:text;not null;default:''"` // Free-form description (schema version 2)
+	Compression string    `gorm:"size:16;not null;default:''"`   // Codec chain applied to every chunk; empty for none (schema version 3)
+
+	// The file the content was stored from, if any (schema version 4)
+	OriginalName    string     `gorm:"type:text;not null;default:''"`
+	OriginalModTime *time.Time // NULL when not stored from a file
+	OriginalMode    uint32     `gorm:"not null;default:0"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"` // GORM managed timestamp
+	UpdatedAt time.Time `gorm:"autoUpdateTime"` // GORM managed timestamp
+
+	// One-to-many relationships with file chunks, metadata, and sections
+	Chunks   []FileChunkModel   `gorm:"foreignKey:HistoryID;constraint:OnDelete:CASCADE"`
+	Meta     []ItemMetaModel    `gorm:"foreignKey:HistoryID;constraint:OnDelete:CASCADE"`
+	Sections []ItemSectionModel `gorm:"foreignKey:HistoryID;constraint:OnDelete:CASCADE"`
 }
 
 // TableName returns the table name for HistoryItemModel
@@ -32,25 +43,34 @@ func (HistoryItemModel) TableName() string {
 
 // ToHistoryItem converts the GORM model to a store.HistoryItem
 func (m *HistoryItemModel) ToHistoryItem() *store.HistoryItem {
-	return &store.HistoryItem{
+	item := &store.HistoryItem{
 		ID:        m.ID,
 		Title:     m.Title,
+		Note:      m.Note,
 		Timestamp: m.Timestamp,
 		IsBinary:  m.IsBinary,
 		Size:      m.Size,
 		SHA256:    m.SHA256,
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
+
+		OriginalName: m.OriginalName,
+		OriginalMode: fs.FileMode(m.OriginalMode),
+	}
+	if m.OriginalModTime != nil {
+		item.OriginalModTime = *m.OriginalModTime
 	}
+	return item
 }
 
 // FileChunkModel represents a single chunk of file content.
 // Content is split into 32KB chunks for streaming support.
 type FileChunkModel struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement"`
-	HistoryID uint      `gorm:"not null;index:idx_history_seq"` // Foreign key to history
-	Sequence  int       `gorm:"not null;index:idx_history_seq"` // Chunk order (0, 1, 2, ...)
-	Data      []byte    `gorm:"type:blob;not null"`             // Chunk data (max 32KB)
+	HistoryID uint      `gorm:"not null;uniqueIndex:idx_history_seq"` // Foreign key to history
+	Sequence  int       `gorm:"not null;uniqueIndex:idx_history_seq"` // Chunk order (0, 1, 2, ...), unique per item (schema version 8)
+	Data      []byte    `gorm:"type:blob;not null"`                   // Chunk data (max 32KB uncompressed)
+	RawSize   int       `gorm:"not null;default:0"`                   // Uncompressed length of Data (schema version 3)
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
 
@@ -59,6 +79,66 @@ func (FileChunkModel) TableName() string {
 	return "file_chunks"
 }
 
+// ItemMetaModel is one key-value pair of an item's metadata (schema version 5)
+type ItemMetaModel struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	HistoryID uint   `gorm:"not null;uniqueIndex:idx_item_meta_key"`
+	Key       string `gorm:"size:64;not null;uniqueIndex:idx_item_meta_key"`
+	Value     string `gorm:"type:text;not null"`
+}
+
+// TableName returns the table name for ItemMetaModel
+func (ItemMetaModel) TableName() string {
+	return "item_meta"
+}
+
+// ItemSectionModel is one named byte range of an item's content (schema
+// version 6)
+type ItemSectionModel struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	HistoryID   uint   `gorm:"not null;index:idx_item_sections_order"`
+	Ordinal     int    `gorm:"not null;index:idx_item_sections_order"`
+	Name        string `gorm:"type:text;not null"`
+	StartOffset int64  `gorm:"not null"`
+	EndOffset   int64  `gorm:"not null"`
+}
+
+// TableName returns the table name for ItemSectionModel
+func (ItemSectionModel) TableName() string {
+	return "item_sections"
+}
+
+// AuditModel records an item removed from the history, kept after the item
+// is gone (schema version 10). It has no foreign key, since it outlives the
+// item; ItemID is NULL for a Clear that found no items.
+type AuditModel struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	Timestamp  time.Time `gorm:"not null;index"`
+	Operation  string    `gorm:"size:16;not null"`
+	ItemID     *uint     `gorm:"index"`
+	ItemTitle  string    `gorm:"size:80;not null;default:''"`
+	ItemSHA256 string    `gorm:"size:64;not null;default:''"`
+	Source     string    `gorm:"type:text;not null;default:''"`
+}
+
+// TableName returns the table name for AuditModel
+func (AuditModel) TableName() string {
+	return "audit_log"
+}
+
+// ToAuditEntry converts the GORM model to a store.AuditEntry
+func (m *AuditModel) ToAuditEntry() *store.AuditEntry {
+	return &store.AuditEntry{
+		ID:         m.ID,
+		Timestamp:  m.Timestamp,
+		Operation:  m.Operation,
+		ItemID:     m.ItemID,
+		ItemTitle:  m.ItemTitle,
+		ItemSHA256: m.ItemSHA256,
+		Source:     m.Source,
+	}
+}
+
 // ConfigItemModel represents a configuration key-value pair
 type ConfigItemModel struct {
 	Key       string    `gorm:"primaryKey;size:100"`
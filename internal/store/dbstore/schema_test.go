@@ -0,0 +1,139 @@
+package dbstore
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// tableColumns returns the columns of table, from PRAGMA table_info
+func tableColumns(t *testing.T, st *SQLiteStore, table string) []string {
+	t.Helper()
+	var columns []struct{ Name string }
+	if err := st.db.Raw("SELECT name FROM pragma_table_info(?)", table).Scan(&columns).Error; err != nil {
+		t.Fatalf("table_info(%s): %v", table, err)
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// indexedColumns returns the columns of each index on table by index name,
+// and whether each index is unique
+func indexedColumns(t *testing.T, st *SQLiteStore, table string) (map[string]string, map[string]bool) {
+	t.Helper()
+	var indexes []struct {
+		Name   string
+		Unique bool
+	}
+	if err := st.db.Raw(`SELECT name, "unique" FROM pragma_index_list(?)`, table).Scan(&indexes).Error; err != nil {
+		t.Fatalf("index_list(%s): %v", table, err)
+	}
+	columns, unique := map[string]string{}, map[string]bool{}
+	for _, index := range indexes {
+		var names []string
+		if err := st.db.Raw("SELECT name FROM pragma_index_info(?) ORDER BY seqno", index.Name).Scan(&names).Error; err != nil {
+			t.Fatalf("index_info(%s): %v", index.Name, err)
+		}
+		columns[index.Name] = strings.Join(names, ",")
+		unique[index.Name] = index.Unique
+	}
+	return columns, unique
+}
+
+// chunkIndexUnique reports whether a unique index covers each chunk's
+// item and position
+func chunkIndexUnique(t *testing.T, st *SQLiteStore) bool {
+	t.Helper()
+	columns, unique := indexedColumns(t, st, "file_chunks")
+	for name, cols := range columns {
+		if cols == "history_id,sequence" && unique[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSchema_ForeignKeys(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	for _, table := range []string{"file_chunks", "item_meta", "item_sections"} {
+		var keys []struct {
+			Table    string
+			From     string
+			To       string
+			OnDelete string
+		}
+		if err := st.db.Raw(`SELECT "table", "from", "to", on_delete FROM pragma_foreign_key_list(?)`, table).Scan(&keys).Error; err != nil {
+			t.Fatalf("foreign_key_list(%s): %v", table, err)
+		}
+		if len(keys) != 1 || keys[0].Table != "history_items" || keys[0].From != "history_id" || keys[0].To != "id" || keys[0].OnDelete != "CASCADE" {
+			t.Errorf("%s foreign keys = %+v, want history_id -> history_items(id) ON DELETE CASCADE", table, keys)
+		}
+	}
+}
+
+func TestSchema_Indexes(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	if !chunkIndexUnique(t, st) {
+		t.Error("expected a unique index on file_chunks (history_id, sequence)")
+	}
+
+	columns, _ := indexedColumns(t, st, "history_items")
+	for _, want := range []string{"timestamp", "size", "title", "sha256"} {
+		found := false
+		for _, cols := range columns {
+			found = found || cols == want
+		}
+		if !found {
+			t.Errorf("expected an index on history_items (%s), have %v", want, columns)
+		}
+	}
+}
+
+// TestSchema_SelectedColumns tests that the column lists selected by name
+// match the tables, so a model field added without updating them fails here
+// rather than going unread
+func TestSchema_SelectedColumns(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	columns := tableColumns(t, st, "history_items")
+	if got, want := slices.Sorted(slices.Values(itemColumns)), slices.Sorted(slices.Values(columns)); !slices.Equal(got, want) {
+		t.Errorf("itemColumns = %v, want the history_items columns %v", got, want)
+	}
+	for _, column := range addedColumns {
+		if !slices.Contains(itemColumns, column) {
+			t.Errorf("added column %s is not in itemColumns", column)
+		}
+	}
+
+	// Columns other queries select by name
+	for table, selected := range map[string][]string{
+		"history_items": {"id", "is_binary", "size", "compression"},
+		"file_chunks":   {"history_id", "sequence", "data", "raw_size"},
+	} {
+		columns := tableColumns(t, st, table)
+		for _, column := range selected {
+			if !slices.Contains(columns, column) {
+				t.Errorf("%s has no column %s, which queries select", table, column)
+			}
+		}
+	}
+}
+
+func TestSchema_DuplicateChunkRejected(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item := mustCreate(t, st, "content")
+	chunk := FileChunkModel{HistoryID: item.ID, Sequence: 0, Data: []byte("again"), RawSize: 5}
+	if err := st.db.Create(&chunk).Error; err == nil {
+		t.Error("expected a second chunk at the same sequence to be rejected")
+	}
+}
@@ -0,0 +1,59 @@
+package dbstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yiblet/rem/internal/store"
+	"gorm.io/gorm"
+)
+
+// SetSections replaces an item's sections
+func (s *sqliteHistoryStore) SetSections(id uint, sections []store.Section) error {
+	return s.mutate(func(tx *gorm.DB) error {
+		var item HistoryItemModel
+		if err := tx.Select("id", "size").First(&item, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("item not found: %d", id)
+			}
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		if err := store.ValidateSections(sections, item.Size); err != nil {
+			return err
+		}
+		if err := tx.Where("history_id = ?", id).Delete(&ItemSectionModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete sections: %w", err)
+		}
+		if len(sections) == 0 {
+			return nil
+		}
+		rows := make([]ItemSectionModel, len(sections))
+		for i, section := range sections {
+			rows[i] = ItemSectionModel{HistoryID: id, Ordinal: i, Name: section.Name, StartOffset: section.Start, EndOffset: section.End}
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("failed to save sections: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetSections returns an item's sections in content order. Databases from
+// before sections, opened read-only, have none.
+func (s *sqliteHistoryStore) GetSections(id uint) ([]store.Section, error) {
+	if err := itemExists(s.db, id); err != nil {
+		return nil, err
+	}
+	sections := []store.Section{}
+	if !s.hasSections {
+		return sections, nil
+	}
+	var rows []ItemSectionModel
+	if err := s.db.Where("history_id = ?", id).Order("ordinal").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sections: %w", err)
+	}
+	for _, row := range rows {
+		sections = append(sections, store.Section{Name: row.Name, Start: row.StartOffset, End: row.EndOffset})
+	}
+	return sections, nil
+}
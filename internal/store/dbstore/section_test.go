@@ -0,0 +1,31 @@
+package dbstore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+func TestSections_CascadeOnDelete(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	item, err := st.History().Create(&store.CreateHistoryInput{Title: "configs", Content: strings.NewReader("a\nb\n")})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := st.History().SetSections(item.ID, []store.Section{{Name: "1", Start: 0, End: 2}, {Name: "2", Start: 2, End: 4}}); err != nil {
+		t.Fatalf("SetSections() error = %v", err)
+	}
+	if err := st.History().Delete(item.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	var count int64
+	if err := st.db.Model(&ItemSectionModel{}).Where("history_id = ?", item.ID).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count sections: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected deleting the item to delete its sections, %d row(s) left", count)
+	}
+}
@@ -6,57 +6,235 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"regexp"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/textnorm"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
 // SQLiteStore is a SQLite-backed implementation of store.Store
 type SQLiteStore struct {
-	db     *gorm.DB
-	dbPath string
+	db          *gorm.DB
+	dbPath      string
+	readOnly    bool
+	backup      BackupPolicy
+	columns     []string // history_items columns loaded for metadata
+	hasMeta     bool     // whether the item_meta table exists
+	hasSections bool     // whether the item_sections table exists
+	hasAudit    bool     // whether the audit_log table exists
+	codecs      *codecRegistry
+
+	auditSource  atomic.Value // string recorded as each audit entry's source
+	onAuditError func(error)
+}
+
+// itemColumns are the history_items columns holding item metadata
+var itemColumns = []string{"id", "title", "note", "timestamp", "is_binary", "size", "sha256", "compression",
+	"original_name", "original_mod_time", "original_mode", "created_at", "updated_at"}
+
+// addedColumns are the history_items columns added by migrations, which
+// older databases opened read-only may lack
+var addedColumns = []string{"note", "compression", "original_name", "original_mod_time", "original_mode"}
+
+// windowSlackDays widens time-window bounds in SQL by one second, in days,
+// to absorb julianday rounding
+const windowSlackDays = 1.0 / 86400
+
+// busyTimeout is how long SQLite waits for a lock held by another connection
+const busyTimeout = 5 * time.Second
+
+// Options configures how a SQLite store is opened
+type Options struct {
+	// ReadOnly opens the database without writing to it: no schema
+	// migration and no default config. This also allows reading a
+	// database created by a newer version of rem.
+	ReadOnly bool
+
+	// Backup configures automatic backups before destructive operations.
+	// The zero value disables them.
+	Backup BackupPolicy
+
+	// Codecs are applied, in order, to every chunk written after the
+	// automatic compression. Items written with a codec can only be read by
+	// a store opened with it.
+	Codecs []ChunkCodec
+
+	// OnAuditError is called when removed items can't be recorded in the
+	// audit log. The removal itself has already succeeded. Nil ignores
+	// such errors.
+	OnAuditError func(error)
 }
 
 // NewSQLiteStore creates a new SQLite-backed store at the specified path.
 // It initializes the database schema and sets up default configuration.
 func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	return NewSQLiteStoreWithOptions(dbPath, Options{})
+}
+
+// NewSQLiteStoreWithOptions creates a SQLite-backed store at the specified path.
+// Unless opened read-only, older schemas are migrated to SchemaVersion and
+// databases with a newer schema are refused with ErrNewerSchema. A file that
+// isn't a SQLite database is refused with ErrNotSQLite, untouched.
+func NewSQLiteStoreWithOptions(dbPath string, opts Options) (*SQLiteStore, error) {
+	if err := checkSQLiteHeader(dbPath); err != nil {
+		return nil, err
+	}
+	dsn := dbPath
+	if opts.ReadOnly {
+		dsn = "file:" + dbPath + "?mode=ro"
+	}
+	_, statErr := os.Stat(dbPath)
+	created := os.IsNotExist(statErr) && !opts.ReadOnly
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
+		// Times are stored as text with their offset; keeping them all in
+		// UTC keeps text order the same as time order
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	store := &SQLiteStore{
+		db:          db,
+		dbPath:      dbPath,
+		readOnly:    opts.ReadOnly,
+		backup:      opts.Backup,
+		columns:     itemColumns,
+		hasMeta:     true,
+		hasSections: true,
+		hasAudit:    true,
+		codecs:      newCodecRegistry(opts.Codecs),
+
+		onAuditError: opts.OnAuditError,
+	}
+
+	if err := store.init(); err != nil {
+		store.Close()
+		return nil, err
+	}
+	if created {
+		// SQLite creates files with 0644 less the umask; history is private
+		if err := ChmodDatabase(dbPath); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// checkSQLiteHeader returns ErrNotSQLite if the file at path exists and
+// is neither empty nor a SQLite database
+func checkSQLiteHeader(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil // a missing file is created; others fail to open below
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		return nil
+	}
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(f, header)
+	if n == 0 && err == io.EOF {
+		return nil // SQLite treats an empty file as an empty database
+	}
+	if string(header[:n]) != sqliteHeader {
+		return fmt.Errorf("%w: %s", ErrNotSQLite, path)
+	}
+	return nil
+}
+
+// init checks the schema version and brings the schema up to date
+func (s *SQLiteStore) init() error {
 	// Enable foreign key constraints in SQLite
-	if err := db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	if err := s.db.Exec("PRAGMA foreign_keys = ON").Error; err != nil {
+		return fmt.Errorf("failed to enable foreign keys: %w", err)
+	}
+
+	// Wait for locks held by other rem processes instead of failing immediately
+	if err := s.db.Exec(fmt.Sprintf("PRAGMA busy_timeout = %d", busyTimeout.Milliseconds())).Error; err != nil {
+		return fmt.Errorf("failed to set busy timeout: %w", err)
+	}
+
+	// Check the version before touching the schema
+	version, exists, err := readSchemaVersion(s.db)
+	if err != nil {
+		return err
+	}
+	if version > SchemaVersion && !s.readOnly {
+		return fmt.Errorf("%w (database version %d, supported version %d); upgrade rem or open it with --read-only", ErrNewerSchema, version, SchemaVersion)
+	}
+	if s.readOnly {
+		if !exists {
+			return fmt.Errorf("cannot open a new database read-only: %s", s.dbPath)
+		}
+		// Older databases lack the columns later migrations added
+		s.columns = slices.DeleteFunc(slices.Clone(itemColumns), func(c string) bool {
+			return slices.Contains(addedColumns, c) && !s.db.Migrator().HasColumn(&HistoryItemModel{}, c)
+		})
+		s.hasMeta = s.db.Migrator().HasTable(&ItemMetaModel{})
+		s.hasSections = s.db.Migrator().HasTable(&ItemSectionModel{})
+		s.hasAudit = s.db.Migrator().HasTable(&AuditModel{})
+		return nil
+	}
+
+	// Bring an existing database up to date with ordered migrations
+	if err := s.db.AutoMigrate(&ConfigItemModel{}, &SchemaMigrationModel{}); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	if exists && version < SchemaVersion {
+		if err := s.autoBackup(); err != nil {
+			return fmt.Errorf("failed to back up before migrating: %w", err)
+		}
+		if err := runMigrations(s.db, version); err != nil {
+			return err
+		}
 	}
 
 	// Run auto-migration for all models
-	if err := db.AutoMigrate(&HistoryItemModel{}, &FileChunkModel{}, &ConfigItemModel{}); err != nil {
-		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	if err := s.db.AutoMigrate(&HistoryItemModel{}, &FileChunkModel{}, &ItemMetaModel{}, &ItemSectionModel{}, &AuditModel{}); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	store := &SQLiteStore{
-		db:     db,
-		dbPath: dbPath,
+	// A new database starts at the current version
+	if !exists {
+		if err := markMigrationsApplied(s.db); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
 	}
 
 	// Initialize default config
-	if err := store.initDefaultConfig(); err != nil {
-		return nil, fmt.Errorf("failed to init config: %w", err)
+	if err := s.initDefaultConfig(); err != nil {
+		return fmt.Errorf("failed to init config: %w", err)
 	}
 
-	return store, nil
+	return nil
+}
+
+// ReadOnly reports whether the store was opened read-only
+func (s *SQLiteStore) ReadOnly() bool {
+	return s.readOnly
 }
 
 // History returns the history store
 func (s *SQLiteStore) History() store.HistoryStore {
-	return &sqliteHistoryStore{db: s.db}
+	return &sqliteHistoryStore{db: s.db, backup: s.autoBackup, audit: s.audit, columns: s.columns, hasMeta: s.hasMeta, hasSections: s.hasSections, codecs: s.codecs}
 }
 
 // Config returns the config store
@@ -64,6 +242,44 @@ func (s *SQLiteStore) Config() store.ConfigStore {
 	return &sqliteConfigStore{db: s.db}
 }
 
+// PrivateFileMode is the mode of database and backup files
+const PrivateFileMode = 0600
+
+// databaseSuffixes name the files SQLite keeps next to a database
+var databaseSuffixes = []string{"", "-journal", "-wal", "-shm"}
+
+// ChmodDatabase makes the database at dbPath and the journal files beside it
+// readable only by their owner
+func ChmodDatabase(dbPath string) error {
+	for _, suffix := range databaseSuffixes {
+		if err := os.Chmod(dbPath+suffix, PrivateFileMode); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to restrict permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// DatabaseFiles returns the database at dbPath and those of its journal
+// files that exist
+func DatabaseFiles(dbPath string) []string {
+	var files []string
+	for _, suffix := range databaseSuffixes {
+		if _, err := os.Stat(dbPath + suffix); err == nil {
+			files = append(files, dbPath+suffix)
+		}
+	}
+	return files
+}
+
+// SQLiteVersion returns the version of the SQLite library in use
+func (s *SQLiteStore) SQLiteVersion() (string, error) {
+	var version string
+	if err := s.db.Raw("SELECT sqlite_version()").Scan(&version).Error; err != nil {
+		return "", fmt.Errorf("failed to read sqlite version: %w", err)
+	}
+	return version, nil
+}
+
 // Close closes the database connection
 func (s *SQLiteStore) Close() error {
 	sqlDB, err := s.db.DB()
@@ -76,9 +292,14 @@ func (s *SQLiteStore) Close() error {
 // initDefaultConfig sets up default configuration values
 func (s *SQLiteStore) initDefaultConfig() error {
 	defaults := map[string]string{
-		"history_limit": "255",
-		"show_binary":   "false",
-		"db_version":    "1",
+		"history_limit":       "255",
+		"show_binary":         "false",
+		"clipboard_max_bytes": "67108864", // 64MB
+		"low_space_warn_mb":   "100",
+		"backup_keep":         strconv.Itoa(DefaultBackupKeep),
+		"backup_max_bytes":    strconv.Itoa(DefaultBackupMaxBytes),
+		"compress_min_bytes":  strconv.Itoa(DefaultCompressMinBytes),
+		"db_version":          strconv.Itoa(SchemaVersion),
 	}
 
 	configStore := s.Config()
@@ -96,73 +317,141 @@ func (s *SQLiteStore) initDefaultConfig() error {
 
 // sqliteHistoryStore implements store.HistoryStore using SQLite with chunked storage
 type sqliteHistoryStore struct {
-	db *gorm.DB
+	db          *gorm.DB
+	backup      func() error // takes an automatic backup; nil skips it
+	columns     []string     // metadata columns to load
+	hasMeta     bool         // whether the item_meta table exists
+	hasSections bool         // whether the item_sections table exists
+	codecs      *codecRegistry
+
+	// audit records the items a removal removed; nil skips it
+	audit func(operation string, removed []HistoryItemModel)
 }
 
-// Create stores a new history item with chunked content streaming
+// Create stores a new history item with chunked content streaming. The item
+// and its chunks are written in one transaction, so a failure part way
+// through leaves nothing behind.
 func (s *sqliteHistoryStore) Create(input *store.CreateHistoryInput) (*store.HistoryItem, error) {
+	var item *HistoryItemModel
+	err := s.mutate(func(tx *gorm.DB) error {
+		var err error
+		item, err = createItem(tx, input, s.codecs)
+		return err
+	})
+	if err != nil {
+		if isDiskFull(err) {
+			return nil, store.ErrDiskFull
+		}
+		return nil, err
+	}
+	return item.ToHistoryItem(), nil
+}
+
+// isDiskFull reports whether err means the database's filesystem is full
+func isDiskFull(err error) bool {
+	// SQLITE_FULL surfaces from the driver as "database or disk is full"
+	return errors.Is(err, syscall.ENOSPC) || strings.Contains(err.Error(), "database or disk is full")
+}
+
+// createItem writes the item row and its content chunks using tx
+func createItem(tx *gorm.DB, input *store.CreateHistoryInput, codecs *codecRegistry) (*HistoryItemModel, error) {
 	// 1. Create history item record (without size/SHA256 yet)
 	item := &HistoryItemModel{
 		Title:     input.Title,
-		Timestamp: input.Timestamp,
+		Timestamp: input.Timestamp.UTC(),
 		IsBinary:  false, // Determined from first chunk
+
+		OriginalName: input.OriginalName,
+		OriginalMode: uint32(input.OriginalMode),
 	}
-	if err := s.db.Create(item).Error; err != nil {
+	if !input.OriginalModTime.IsZero() {
+		modTime := input.OriginalModTime.UTC()
+		item.OriginalModTime = &modTime
+	}
+	if err := tx.Create(item).Error; err != nil {
 		return nil, fmt.Errorf("failed to create history item: %w", err)
 	}
 
 	// 2. Stream content into chunks
+	if err := writeContent(tx, item, input.Content, codecs); err != nil {
+		return nil, err
+	}
+
+	// 3. Update item with final size and hash
+	if err := tx.Save(item).Error; err != nil {
+		return nil, fmt.Errorf("failed to update item metadata: %w", err)
+	}
+
+	return item, nil
+}
+
+// writeContent streams content into chunks of item using tx, setting the
+// item's Size, SHA256, IsBinary and Compression without saving them. Each
+// chunk is encoded with the codec chain recorded in Compression. Chunks are
+// written as they are read; once text grows past compress_min_bytes, those
+// already written are compressed in place and the rest follow compressed.
+func writeContent(tx *gorm.DB, item *HistoryItemModel, content io.Reader, codecs *codecRegistry) error {
 	hasher := sha256.New()
-	reader := io.TeeReader(input.Content, hasher) // Hash while reading
+	reader := io.TeeReader(content, hasher) // Hash while reading
+	minBytes := compressMinBytes(tx)
+	chain := codecs.chain(PassthroughCodec)
+	item.Compression = chain.String()
 
 	buffer := make([]byte, ChunkSize)
 	sequence := 0
 	totalSize := int64(0)
 	firstChunk := true
+	compressed := false
 
 	for {
 		n, err := io.ReadFull(reader, buffer)
 		if n > 0 {
-			// Detect binary from first chunk
+			data := buffer[:n]
+			totalSize += int64(n)
+
+			// Detect binary from first chunk; binary content isn't compressed
 			if firstChunk {
-				item.IsBinary = isBinary(buffer[:n])
+				item.IsBinary = store.IsBinary(data)
 				firstChunk = false
 			}
 
-			// Store chunk
+			if !compressed && !item.IsBinary && minBytes > 0 && totalSize > minBytes {
+				want := codecs.chain(GzipCodec)
+				if err := recodeChunks(tx, item.ID, chain, want); err != nil {
+					return err
+				}
+				chain = want
+				item.Compression = chain.String()
+				compressed = true
+			}
+
+			stored, err := chain.encode(sequence, data)
+			if err != nil {
+				return err
+			}
 			chunk := &FileChunkModel{
 				HistoryID: item.ID,
 				Sequence:  sequence,
-				Data:      append([]byte(nil), buffer[:n]...), // Copy slice
+				Data:      stored,
+				RawSize:   n,
 			}
-			if err := s.db.Create(chunk).Error; err != nil {
-				// Rollback: delete item (CASCADE will delete chunks)
-				s.db.Delete(item)
-				return nil, fmt.Errorf("failed to create chunk: %w", err)
+			if err := tx.Create(chunk).Error; err != nil {
+				return fmt.Errorf("failed to create chunk: %w", err)
 			}
-
 			sequence++
-			totalSize += int64(n)
 		}
 
 		if err == io.EOF || err == io.ErrUnexpectedEOF {
 			break
 		}
 		if err != nil {
-			// Rollback: delete item (CASCADE will delete chunks)
-			s.db.Delete(item)
-			return nil, fmt.Errorf("failed to read content: %w", err)
+			return fmt.Errorf("failed to read content: %w", err)
 		}
 	}
 
-	// 3. Update item with final size and hash
 	item.Size = totalSize
 	item.SHA256 = hex.EncodeToString(hasher.Sum(nil))
-	if err := s.db.Save(item).Error; err != nil {
-		return nil, fmt.Errorf("failed to update item metadata: %w", err)
-	}
-
-	return item.ToHistoryItem(), nil
+	return nil
 }
 
 // List returns items ordered by timestamp (newest first), excluding content
@@ -170,8 +459,8 @@ func (s *sqliteHistoryStore) List(limit int) ([]*store.HistoryItem, error) {
 	var models []*HistoryItemModel
 
 	query := s.db.
-		Select("id", "title", "timestamp", "is_binary", "size", "sha256", "created_at", "updated_at").
-		Order("timestamp DESC")
+		Select(s.columns).
+		Order("timestamp DESC, id DESC")
 
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -189,12 +478,109 @@ func (s *sqliteHistoryStore) List(limit int) ([]*store.HistoryItem, error) {
 	return items, nil
 }
 
+// orderColumns are the columns each sort key orders by, with whether each
+// sorts descending in the key's own order
+var orderColumns = map[store.SortKey][]struct {
+	column string
+	desc   bool
+}{
+	store.SortAge:   {{"timestamp", true}, {"id", true}},
+	store.SortSize:  {{"size", true}, {"timestamp", true}, {"id", true}},
+	store.SortTitle: {{"title", false}, {"timestamp", true}, {"id", true}},
+}
+
+// ListOrdered sorts in the query, leading with an indexed column, so the
+// order of a large history costs no more than listing it. Positions come
+// from a window over List's order in the same query.
+func (s *sqliteHistoryStore) ListOrdered(order store.OrderSpec, limit int) ([]store.OrderedItem, error) {
+	columns, ok := orderColumns[order.Key]
+	if !ok {
+		return nil, fmt.Errorf("cannot order items by %q", order.Key)
+	}
+	clauses := make([]string, len(columns))
+	for i, c := range columns {
+		direction := "ASC"
+		if c.desc != order.Reverse {
+			direction = "DESC"
+		}
+		clauses[i] = c.column + " " + direction
+	}
+
+	selected := append(slices.Clone(s.columns), "ROW_NUMBER() OVER (ORDER BY timestamp DESC, id DESC) - 1 AS position")
+	query := s.db.Model(&HistoryItemModel{}).Select(selected).Order(strings.Join(clauses, ", "))
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var rows []struct {
+		HistoryItemModel `gorm:"embedded"`
+		Position         int
+	}
+	if err := query.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	items := make([]store.OrderedItem, len(rows))
+	for i, row := range rows {
+		items[i] = store.OrderedItem{Item: row.ToHistoryItem(), Position: row.Position}
+	}
+	return items, nil
+}
+
+// ListAfterID returns items with an ID above id in ID order, excluding content
+func (s *sqliteHistoryStore) ListAfterID(id uint) ([]*store.HistoryItem, error) {
+	var models []*HistoryItemModel
+	if err := s.db.Select(s.columns).Where("id > ?", id).Order("id ASC").Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	items := make([]*store.HistoryItem, len(models))
+	for i, model := range models {
+		items[i] = model.ToHistoryItem()
+	}
+	return items, nil
+}
+
+// Iterate pages through items with keyset pagination on (timestamp, id), so
+// each batch starts after the last item seen regardless of inserts and deletes
+func (s *sqliteHistoryStore) Iterate(opts store.IterOptions, fn func(*store.HistoryItem) (bool, error)) error {
+	order, after := "timestamp DESC, id DESC", "timestamp < ? OR (timestamp = ? AND id < ?)"
+	if opts.Order.Oldest() {
+		order, after = "timestamp ASC, id ASC", "timestamp > ? OR (timestamp = ? AND id > ?)"
+	}
+
+	var last *HistoryItemModel
+	if opts.After != nil {
+		last = &HistoryItemModel{ID: opts.After.ID, Timestamp: opts.After.Timestamp.UTC()}
+	}
+	for {
+		query := s.db.Select(s.columns).Order(order).Limit(opts.Batch())
+		if last != nil {
+			query = query.Where(after, last.Timestamp, last.Timestamp, last.ID)
+		}
+		var models []*HistoryItemModel
+		if err := query.Find(&models).Error; err != nil {
+			return fmt.Errorf("failed to list items: %w", err)
+		}
+
+		for _, model := range models {
+			stop, err := fn(model.ToHistoryItem())
+			if err != nil || stop {
+				return err
+			}
+		}
+		if len(models) < opts.Batch() {
+			return nil
+		}
+		last = models[len(models)-1]
+	}
+}
+
 // Get retrieves a single item by ID, excluding content
 func (s *sqliteHistoryStore) Get(id uint) (*store.HistoryItem, error) {
 	var model HistoryItemModel
 
 	if err := s.db.
-		Select("id", "title", "timestamp", "is_binary", "size", "sha256", "created_at", "updated_at").
+		Select(s.columns).
 		First(&model, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("item not found: %d", id)
@@ -207,62 +593,227 @@ func (s *sqliteHistoryStore) Get(id uint) (*store.HistoryItem, error) {
 
 // GetContent returns a streaming reader for an item's content
 func (s *sqliteHistoryStore) GetContent(id uint) (io.ReadSeekCloser, error) {
-	// Get item size
-	var item HistoryItemModel
-	if err := s.db.Select("size").First(&item, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("item not found: %d", id)
-		}
-		return nil, fmt.Errorf("failed to get item: %w", err)
+	item, chain, err := s.contentInfo(id)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ChunkedReader{
 		db:        s.db,
 		historyID: id,
 		totalSize: item.Size,
-		chunkSeq:  0,
-		chunkPos:  0,
+		codecs:    chain,
+		// Chunk lengths are recorded alongside compression
+		rawSizes: slices.Contains(s.columns, "compression"),
+		chunkSeq: 0,
+		chunkPos: 0,
 	}, nil
 }
 
-// Delete removes an item by ID (CASCADE deletes chunks)
-func (s *sqliteHistoryStore) Delete(id uint) error {
-	result := s.db.Delete(&HistoryItemModel{}, id)
-	if result.Error != nil {
-		return fmt.Errorf("failed to delete item: %w", result.Error)
+// contentInfo returns the item's size and compression, and the codecs its
+// chunks are decoded with
+func (s *sqliteHistoryStore) contentInfo(id uint) (*HistoryItemModel, codecChain, error) {
+	columns := []string{"size"}
+	if slices.Contains(s.columns, "compression") {
+		columns = append(columns, "compression")
 	}
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("item not found: %d", id)
+	var item HistoryItemModel
+	if err := s.db.Select(columns).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("item not found: %d (%w)", id, store.ErrItemDeleted)
+		}
+		return nil, nil, fmt.Errorf("failed to get item: %w", err)
 	}
+	chain, err := s.codecs.lookup(item.Compression)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read item %d: %w", id, err)
+	}
+	return &item, chain, nil
+}
+
+// GetContentPrefix returns up to n bytes from the start of an item's
+// content. Every chunk but the last holds ChunkSize bytes, so the first
+// ceil(n/ChunkSize) chunks are fetched in one query; more are fetched only
+// if those come up short.
+func (s *sqliteHistoryStore) GetContentPrefix(id uint, n int) ([]byte, error) {
+	item, chain, err := s.contentInfo(id)
+	if err != nil {
+		return nil, err
+	}
+	n = int(max(min(int64(n), store.MaxContentPrefix, item.Size), 0))
+
+	prefix := make([]byte, 0, n)
+	next := 0 // the lowest sequence not read yet
+	for len(prefix) < n {
+		var chunks []FileChunkModel
+		err := s.db.Where("history_id = ? AND sequence >= ?", id, next).
+			Order("sequence ASC").
+			Limit((n - len(prefix) + ChunkSize - 1) / ChunkSize).
+			Find(&chunks).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chunks: %w", err)
+		}
+		if len(chunks) == 0 {
+			// The chunks hold less than the item's size
+			return nil, io.ErrUnexpectedEOF
+		}
+		for _, chunk := range chunks {
+			data, err := chain.decode(chunk.Sequence, chunk.Data, chunk.RawSize)
+			if err != nil {
+				return nil, err
+			}
+			prefix = append(prefix, data[:min(len(data), n-len(prefix))]...)
+			next = chunk.Sequence + 1
+		}
+	}
+	return prefix, nil
+}
+
+// Delete removes an item by ID (CASCADE deletes chunks) and records it in
+// the audit log
+func (s *sqliteHistoryStore) Delete(id uint) error {
+	var item HistoryItemModel
+	err := s.mutate(func(tx *gorm.DB) error {
+		if err := tx.Select(auditColumns).Limit(1).Find(&item, id).Error; err != nil {
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		result := tx.Delete(&HistoryItemModel{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete item: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("item not found: %d", id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.recordAudit(store.AuditDelete, []HistoryItemModel{item})
 	return nil
 }
 
-// DeleteOldest removes the N oldest items based on timestamp
+// updateItem applies updates to the item with id, failing with what when
+// the update does
+func (s *sqliteHistoryStore) updateItem(id uint, what string, updates map[string]interface{}) error {
+	return s.mutate(func(tx *gorm.DB) error {
+		result := tx.Model(&HistoryItemModel{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update %s: %w", what, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("item not found: %d", id)
+		}
+		return nil
+	})
+}
+
+// UpdateTitle replaces an item's title
+func (s *sqliteHistoryStore) UpdateTitle(id uint, title string) error {
+	return s.updateItem(id, "title", map[string]interface{}{"title": title})
+}
+
+// UpdateNote replaces an item's note
+func (s *sqliteHistoryStore) UpdateNote(id uint, note string) error {
+	return s.updateItem(id, "note", map[string]interface{}{"note": note})
+}
+
+// UpdateTimestamp replaces an item's ordering timestamp
+func (s *sqliteHistoryStore) UpdateTimestamp(id uint, timestamp time.Time) error {
+	return s.updateItem(id, "timestamp", map[string]interface{}{"timestamp": timestamp.UTC()})
+}
+
+// ReplaceContent replaces an item's chunks with content in one transaction,
+// so a failure part way through leaves the old content in place
+func (s *sqliteHistoryStore) ReplaceContent(id uint, content io.Reader) error {
+	err := s.mutate(func(tx *gorm.DB) error {
+		var item HistoryItemModel
+		if err := tx.Select("id").First(&item, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("item not found: %d", id)
+			}
+			return fmt.Errorf("failed to get item: %w", err)
+		}
+		if err := tx.Where("history_id = ?", id).Delete(&FileChunkModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete chunks: %w", err)
+		}
+		if err := tx.Where("history_id = ?", id).Delete(&ItemSectionModel{}).Error; err != nil {
+			return fmt.Errorf("failed to delete sections: %w", err)
+		}
+		if err := writeContent(tx, &item, content, s.codecs); err != nil {
+			return err
+		}
+		err := tx.Model(&HistoryItemModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"size": item.Size, "sha256": item.SHA256, "is_binary": item.IsBinary, "compression": item.Compression,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update item metadata: %w", err)
+		}
+		return nil
+	})
+	if err != nil && isDiskFull(err) {
+		return store.ErrDiskFull
+	}
+	return err
+}
+
+// UpdateMetadata replaces an item's title, note and timestamp
+func (s *sqliteHistoryStore) UpdateMetadata(id uint, title, note string, timestamp time.Time) error {
+	return s.updateItem(id, "metadata", map[string]interface{}{"title": title, "note": note, "timestamp": timestamp.UTC()})
+}
+
+// DeleteOldest removes the N oldest items based on timestamp, recording
+// them in the audit log. Removing more than autoBackupThreshold items takes
+// an automatic backup first.
 func (s *sqliteHistoryStore) DeleteOldest(count int) error {
-	// Get IDs of oldest items
-	var ids []uint
-	err := s.db.Model(&HistoryItemModel{}).
-		Select("id").
-		Order("timestamp ASC").
+	if count > autoBackupThreshold {
+		if err := s.takeBackup(); err != nil {
+			return fmt.Errorf("failed to back up before deleting: %w", err)
+		}
+	}
+
+	// Get the oldest items
+	var items []HistoryItemModel
+	err := s.db.
+		Select(auditColumns).
+		Order("timestamp ASC, id ASC").
 		Limit(count).
-		Pluck("id", &ids).Error
+		Find(&items).Error
 
 	if err != nil {
 		return fmt.Errorf("failed to find oldest items: %w", err)
 	}
 
-	if len(ids) == 0 {
+	if len(items) == 0 {
 		return nil
 	}
 
 	// Delete by IDs (CASCADE deletes chunks)
-	if err := s.db.Delete(&HistoryItemModel{}, ids).Error; err != nil {
-		return fmt.Errorf("failed to delete items: %w", err)
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
 	}
-
+	err = s.mutate(func(tx *gorm.DB) error {
+		if err := tx.Delete(&HistoryItemModel{}, ids).Error; err != nil {
+			return fmt.Errorf("failed to delete items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.recordAudit(store.AuditEvict, items)
 	return nil
 }
 
+// takeBackup runs the automatic backup, if any
+func (s *sqliteHistoryStore) takeBackup() error {
+	if s.backup == nil {
+		return nil
+	}
+	return s.backup()
+}
+
 // Count returns the total number of items
 func (s *sqliteHistoryStore) Count() (int, error) {
 	var count int64
@@ -272,29 +823,65 @@ func (s *sqliteHistoryStore) Count() (int, error) {
 	return int(count), nil
 }
 
-// Clear removes all items
-func (s *sqliteHistoryStore) Clear() error {
-	if err := s.db.Session(&gorm.Session{AllowGlobalUpdate: true}).
-		Delete(&HistoryItemModel{}).Error; err != nil {
-		return fmt.Errorf("failed to clear history: %w", err)
+// Clear removes all items, taking an automatic backup first, and records
+// them in the audit log
+func (s *sqliteHistoryStore) Clear(opts store.ClearOptions) error {
+	if count, err := s.Count(); err == nil && count > 0 {
+		if err := s.takeBackup(); err != nil {
+			return fmt.Errorf("failed to back up before clearing: %w", err)
+		}
 	}
+	var items []HistoryItemModel
+	err := s.mutate(func(tx *gorm.DB) error {
+		if err := tx.Select(auditColumns).Order("timestamp ASC, id ASC").Find(&items).Error; err != nil {
+			return fmt.Errorf("failed to list items: %w", err)
+		}
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Delete(&HistoryItemModel{}).Error; err != nil {
+			return fmt.Errorf("failed to clear history: %w", err)
+		}
+		if opts.ResetIDs {
+			// The table is AUTOINCREMENT, so SQLite remembers the largest
+			// ID it handed out here
+			if err := tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", HistoryItemModel{}.TableName()).Error; err != nil {
+				return fmt.Errorf("failed to reset item IDs: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.recordAudit(store.AuditClear, items)
 	return nil
 }
 
+// FindBySHA256 returns items whose content hash matches, newest first
+func (s *sqliteHistoryStore) FindBySHA256(hash string) ([]*store.HistoryItem, error) {
+	var models []*HistoryItemModel
+	if err := s.db.
+		Select(s.columns).
+		Where("sha256 = ?", hash).
+		Order("timestamp DESC, id DESC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to find items by hash: %w", err)
+	}
+
+	items := make([]*store.HistoryItem, len(models))
+	for i, model := range models {
+		items[i] = model.ToHistoryItem()
+	}
+	return items, nil
+}
+
 // Search finds items matching a pattern in title or content using regex
 func (s *sqliteHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryItem, error) {
-	if query.Pattern == "" {
+	if query.Pattern == "" && !query.HasFilter() {
 		return []*store.HistoryItem{}, nil
 	}
 
-	// Compile regex pattern
-	var re *regexp.Regexp
-	var err error
-	if query.CaseSensitive {
-		re, err = regexp.Compile(query.Pattern)
-	} else {
-		re, err = regexp.Compile("(?i)" + query.Pattern)
-	}
+	// Compile regex pattern; an empty pattern matches every item in the window
+	re, err := store.CompilePattern(query)
 	if err != nil {
 		return nil, fmt.Errorf("invalid regex pattern: %w", err)
 	}
@@ -302,17 +889,39 @@ func (s *sqliteHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryI
 	// Determine what to search
 	searchTitle := query.SearchTitle
 	searchContent := query.SearchContent
-	// If both are false, search both (default behavior)
-	if !searchTitle && !searchContent {
+	searchNotes := query.SearchNotes
+	// If none are set, search all of them (default behavior)
+	if !searchTitle && !searchContent && !searchNotes {
 		searchTitle = true
 		searchContent = true
+		searchNotes = true
 	}
 
-	// Get all items (ordered by timestamp DESC - newest first)
+	// Get all items in result order (newest first unless asked otherwise)
+	order := "timestamp DESC, id DESC"
+	if query.OrderBy.Oldest() {
+		order = "timestamp ASC, id ASC"
+	}
 	var models []*HistoryItemModel
 	dbQuery := s.db.
-		Select("id", "title", "timestamp", "is_binary", "size", "sha256", "created_at", "updated_at").
-		Order("timestamp DESC")
+		Select(s.columns).
+		Order(order)
+
+	// Narrow to the time window in SQL so chunks outside it are never loaded.
+	// julianday is only millisecond-accurate, so the bounds are widened here
+	// and applied exactly below.
+	if !query.Since.IsZero() {
+		dbQuery = dbQuery.Where("julianday(timestamp) >= julianday(?) - ?", query.Since, windowSlackDays)
+	}
+	if !query.Until.IsZero() {
+		dbQuery = dbQuery.Where("julianday(timestamp) < julianday(?) + ?", query.Until, windowSlackDays)
+	}
+	if len(query.MetaEquals) > 0 {
+		if !s.hasMeta {
+			return []*store.HistoryItem{}, nil
+		}
+		dbQuery = whereMetaEquals(dbQuery, query.MetaEquals)
+	}
 
 	if err := dbQuery.Find(&models).Error; err != nil {
 		return nil, fmt.Errorf("failed to list items for search: %w", err)
@@ -322,15 +931,23 @@ func (s *sqliteHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryI
 
 	// Search through items
 	for _, model := range models {
-		matched := false
-
-		// Search in title if requested
-		if searchTitle && re.MatchString(model.Title) {
-			matched = true
+		if !store.InTimeWindow(model.Timestamp, query.Since, query.Until) {
+			continue
 		}
 
-		// Search in content if requested and not yet matched
-		if !matched && searchContent {
+		// A title or note match counts once toward MatchCount; match
+		// details need every field checked
+		titleMatched := searchTitle && re.MatchString(store.MatchText(query, model.Title))
+		noteMatched := searchNotes && model.Note != "" && (!titleMatched || query.MatchDetails) &&
+			re.MatchString(store.MatchText(query, model.Note))
+		metaMatched := titleMatched || noteMatched
+		contentMatched := false
+		matchingLines := 0
+		var snippets []string
+
+		// Search in content if requested and not yet matched; counting
+		// matches and match details need the content even when the title matched
+		if searchContent && (!metaMatched || query.CountMatches || query.MatchDetails) {
 			// Load all chunks for this item and search
 			var chunks []FileChunkModel
 			err := s.db.Where("history_id = ?", model.ID).
@@ -340,20 +957,49 @@ func (s *sqliteHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryI
 				return nil, fmt.Errorf("failed to load chunks for item %d: %w", model.ID, err)
 			}
 
-			// Reconstruct content from chunks and search
-			var contentBuilder strings.Builder
+			// Reconstruct content from chunks and search, normalizing
+			// chunk by chunk if asked
+			var contentBuilder interface {
+				io.Writer
+				String() string
+			} = &strings.Builder{}
+			// Snippets are cut from the content as stored
+			var original strings.Builder
+			if query.Normalize {
+				contentBuilder = textnorm.New(!query.CaseSensitive)
+			}
+			chain, err := s.codecs.lookup(model.Compression)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read item %d: %w", model.ID, err)
+			}
 			for _, chunk := range chunks {
-				contentBuilder.Write(chunk.Data)
+				data, err := chain.decode(chunk.Sequence, chunk.Data, chunk.RawSize)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read item %d: %w", model.ID, err)
+				}
+				contentBuilder.Write(data)
+				if query.Normalize && query.MatchDetails {
+					original.Write(data)
+				}
 			}
 			contentStr := contentBuilder.String()
-
-			if re.MatchString(contentStr) {
-				matched = true
+			originalStr := contentStr
+			if query.Normalize && query.MatchDetails {
+				originalStr = original.String()
 			}
+			contentMatched, matchingLines, snippets = store.MatchContent(re, query, contentStr, originalStr)
 		}
 
-		if matched {
-			results = append(results, model.ToHistoryItem())
+		if metaMatched || contentMatched {
+			item := model.ToHistoryItem()
+			if query.CountMatches {
+				item.MatchCount = store.SearchMatchCount(metaMatched, matchingLines)
+			}
+			if query.MatchDetails {
+				item.MatchedIn = store.MatchedFields(titleMatched, contentMatched, noteMatched)
+				item.Snippets = snippets
+			}
+			results = append(results, item)
 
 			// Check limit
 			if query.Limit > 0 && len(results) >= query.Limit {
@@ -370,16 +1016,23 @@ func (s *sqliteHistoryStore) Close() error {
 	return nil // No-op, parent store handles DB closing
 }
 
-// ChunkedReader implements io.ReadSeekCloser for chunked content
+// ChunkedReader implements io.ReadSeekCloser for chunked content. Positions
+// map to chunks by the plain length recorded for each, so encoded chunks of
+// any size can be read and seeked into.
 type ChunkedReader struct {
 	db        *gorm.DB
 	historyID uint
-	totalSize int64
+	totalSize int64      // plain size
+	codecs    codecChain // applied to every chunk
+	rawSizes  bool       // whether chunks record their plain length
+
+	starts []int64 // plain offset of each chunk, then totalSize; loaded on first use
+	seqs   []int   // sequence of each chunk
 
 	position int64  // Current read position
-	chunkBuf []byte // Current chunk buffer
-	chunkSeq int    // Current chunk sequence
-	chunkPos int    // Position within current chunk
+	chunkBuf []byte // Loaded chunk, or nil
+	chunkSeq int    // Sequence of the loaded chunk
+	chunkPos int    // Position within the loaded chunk
 }
 
 // Read reads data from the chunked content
@@ -391,16 +1044,29 @@ func (c *ChunkedReader) Read(p []byte) (int, error) {
 	totalRead := 0
 
 	for totalRead < len(p) && c.position < c.totalSize {
-		// Load next chunk if needed
+		// Load the chunk holding position if needed. It is derived from
+		// position rather than the last chunk loaded, which a Seek may have
+		// left either fully read or not yet started.
 		if c.chunkBuf == nil || c.chunkPos >= len(c.chunkBuf) {
-			if err := c.loadChunk(c.chunkSeq); err != nil {
+			sequence, offset, err := c.chunkAt(c.position)
+			if err == nil {
+				err = c.loadChunk(sequence)
+			}
+			if err != nil {
 				if totalRead > 0 {
 					return totalRead, nil
 				}
 				return 0, err
 			}
-			c.chunkSeq++
-			c.chunkPos = 0
+			c.chunkPos = offset
+			if c.chunkPos >= len(c.chunkBuf) {
+				// A chunk shorter than recorded before the end
+				c.chunkBuf = nil
+				if totalRead > 0 {
+					return totalRead, nil
+				}
+				return 0, io.ErrUnexpectedEOF
+			}
 		}
 
 		// Copy from current chunk
@@ -434,11 +1100,19 @@ func (c *ChunkedReader) Seek(offset int64, whence int) (int64, error) {
 		newPos = c.totalSize
 	}
 
-	// Calculate which chunk and offset within chunk
-	chunkSeq := int(newPos / ChunkSize)
-	chunkOffset := int(newPos % ChunkSize)
+	// The end of the content needs no chunk (and has none when it falls on
+	// a chunk boundary, as it always does for empty content)
+	if newPos == c.totalSize {
+		c.chunkBuf = nil
+		c.position = newPos
+		return newPos, nil
+	}
 
-	// Load the target chunk if different from current
+	// Load the target chunk if different from the loaded one
+	chunkSeq, chunkPos, err := c.chunkAt(newPos)
+	if err != nil {
+		return 0, err
+	}
 	if chunkSeq != c.chunkSeq || c.chunkBuf == nil {
 		if err := c.loadChunk(chunkSeq); err != nil {
 			return 0, err
@@ -446,12 +1120,45 @@ func (c *ChunkedReader) Seek(offset int64, whence int) (int64, error) {
 	}
 
 	c.position = newPos
-	c.chunkSeq = chunkSeq
-	c.chunkPos = chunkOffset
+	c.chunkPos = chunkPos
 
 	return newPos, nil
 }
 
+// chunkAt returns the sequence of the chunk holding the plain offset pos and
+// pos's offset within that chunk
+func (c *ChunkedReader) chunkAt(pos int64) (int, int, error) {
+	if !c.rawSizes {
+		// Older databases wrote every chunk but the last full
+		return int(pos / ChunkSize), int(pos % ChunkSize), nil
+	}
+	if c.starts == nil {
+		var chunks []FileChunkModel
+		err := c.db.Select("sequence", "raw_size").
+			Where("history_id = ?", c.historyID).
+			Order("sequence ASC").
+			Find(&chunks).Error
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to load chunk sizes: %w", err)
+		}
+		c.starts = make([]int64, 0, len(chunks)+1)
+		c.seqs = make([]int, 0, len(chunks))
+		start := int64(0)
+		for _, chunk := range chunks {
+			c.starts = append(c.starts, start)
+			c.seqs = append(c.seqs, chunk.Sequence)
+			start += int64(chunk.RawSize)
+		}
+		c.starts = append(c.starts, start)
+	}
+	i := sort.Search(len(c.seqs), func(i int) bool { return c.starts[i+1] > pos })
+	if i == len(c.seqs) {
+		// The chunks hold less than the item's size
+		return 0, 0, c.missing(io.ErrUnexpectedEOF)
+	}
+	return c.seqs[i], int(pos - c.starts[i]), nil
+}
+
 // loadChunk loads a specific chunk from the database
 func (c *ChunkedReader) loadChunk(sequence int) error {
 	var chunk FileChunkModel
@@ -459,14 +1166,33 @@ func (c *ChunkedReader) loadChunk(sequence int) error {
 		First(&chunk).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return io.EOF
+			return c.missing(io.EOF)
 		}
 		return fmt.Errorf("failed to load chunk: %w", err)
 	}
-	c.chunkBuf = chunk.Data
+	data, err := c.codecs.decode(sequence, chunk.Data, chunk.RawSize)
+	if err != nil {
+		return err
+	}
+	c.chunkBuf = data
+	c.chunkSeq = sequence
 	return nil
 }
 
+// missing returns the error for a chunk that should hold content but isn't
+// there: store.ErrItemDeleted when the item itself is gone, since deleting
+// it deletes its chunks, and otherwise orphan, as the content is short
+func (c *ChunkedReader) missing(orphan error) error {
+	var count int64
+	if err := c.db.Model(&HistoryItemModel{}).Where("id = ?", c.historyID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check item: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("item %d: %w", c.historyID, store.ErrItemDeleted)
+	}
+	return orphan
+}
+
 // Close releases the chunk buffer
 func (c *ChunkedReader) Close() error {
 	c.chunkBuf = nil
@@ -480,27 +1206,34 @@ type sqliteConfigStore struct {
 
 // Get retrieves a configuration value by key
 func (s *sqliteConfigStore) Get(key string) (string, error) {
+	value, _, err := s.GetWithMeta(key)
+	return value, err
+}
+
+// GetWithMeta retrieves a configuration value and when it was last set
+func (s *sqliteConfigStore) GetWithMeta(key string) (string, time.Time, error) {
 	var model ConfigItemModel
 	if err := s.db.First(&model, "key = ?", key).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", fmt.Errorf("config key not found: %s", key)
+			return "", time.Time{}, fmt.Errorf("config key not found: %s", key)
 		}
-		return "", fmt.Errorf("failed to get config: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to get config: %w", err)
 	}
-	return model.Value, nil
+	return model.Value, model.UpdatedAt, nil
 }
 
-// Set stores a configuration value (upsert)
+// Set stores a configuration value (upsert). GORM sets both timestamps on
+// insert; updating an existing key takes only the new updated_at.
 func (s *sqliteConfigStore) Set(key, value string) error {
 	model := &ConfigItemModel{
 		Key:   key,
 		Value: value,
 	}
 
-	// Upsert: update if exists, insert if not
-	result := s.db.Where("key = ?", key).
-		Assign(map[string]interface{}{"value": value, "updated_at": s.db.NowFunc()}).
-		FirstOrCreate(model)
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(model)
 
 	if result.Error != nil {
 		return fmt.Errorf("failed to set config: %w", result.Error)
@@ -540,28 +1273,3 @@ func (s *sqliteConfigStore) Delete(key string) error {
 func (s *sqliteConfigStore) Close() error {
 	return nil // No-op, parent store handles DB closing
 }
-
-// isBinary detects if content is binary based on the first chunk
-func isBinary(data []byte) bool {
-	// Check for null bytes or high concentration of non-printable characters
-	nullCount := 0
-	nonPrintable := 0
-	sampleSize := min(len(data), 512)
-
-	for i := range sampleSize {
-		b := data[i]
-		if b == 0 {
-			nullCount++
-		}
-		if b < 32 && b != '\n' && b != '\r' && b != '\t' {
-			nonPrintable++
-		}
-	}
-
-	// If more than 10% null bytes or 30% non-printable, consider binary
-	if nullCount > sampleSize/10 || nonPrintable > sampleSize*3/10 {
-		return true
-	}
-
-	return false
-}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/storetest"
+	"gorm.io/gorm"
 )
 
 // setupTestDB creates a temporary database for testing
@@ -64,8 +71,8 @@ func TestNewSQLiteStore(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to get db_version: %v", err)
 	}
-	if dbVersion != "1" {
-		t.Errorf("expected db_version=1, got %s", dbVersion)
+	if dbVersion != strconv.Itoa(SchemaVersion) {
+		t.Errorf("expected db_version=%d, got %s", SchemaVersion, dbVersion)
 	}
 }
 
@@ -402,6 +409,90 @@ func TestChunkedReader_Seek(t *testing.T) {
 	}
 }
 
+// TestChunkedReader_SeekThenRead seeks to offsets around every chunk
+// boundary, from each whence, and checks that reading to the end with
+// various buffer sizes matches the content from that offset
+func TestChunkedReader_SeekThenRead(t *testing.T) {
+	// Bytes that don't repeat with the chunk size, so shifted or duplicated
+	// reads are caught
+	content := make([]byte, 4*ChunkSize+ChunkSize/2)
+	for i := range content {
+		content[i] = byte('a' + i%23)
+	}
+	size := int64(len(content))
+
+	var offsets []int64
+	for boundary := int64(0); boundary <= size; boundary += ChunkSize {
+		for _, delta := range []int64{-1, 0, 1, ChunkSize / 2} {
+			if offset := boundary + delta; offset >= 0 && offset <= size {
+				offsets = append(offsets, offset)
+			}
+		}
+	}
+	offsets = append(offsets, size-1, size)
+	readSizes := []int{1, 7, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3 * ChunkSize}
+
+	for _, compress := range []string{"0", "1"} {
+		t.Run("compress_min_bytes="+compress, func(t *testing.T) {
+			st, cleanup := setupTestDB(t)
+			defer cleanup()
+			if err := st.Config().Set("compress_min_bytes", compress); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			item, err := st.History().Create(&store.CreateHistoryInput{
+				Title:     "Boundaries",
+				Content:   bytes.NewReader(content),
+				Timestamp: time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			for _, offset := range offsets {
+				for _, whence := range []int{io.SeekStart, io.SeekCurrent, io.SeekEnd} {
+					for _, readSize := range readSizes {
+						reader, err := st.History().GetContent(item.ID)
+						if err != nil {
+							t.Fatalf("GetContent() error = %v", err)
+						}
+
+						// Start SeekCurrent from a loaded chunk elsewhere
+						from := (offset + ChunkSize + 3) % size
+						reader.Seek(from, io.SeekStart)
+						reader.Read(make([]byte, 1))
+						current, _ := reader.Seek(0, io.SeekCurrent)
+
+						arg := map[int]int64{io.SeekStart: offset, io.SeekCurrent: offset - current, io.SeekEnd: offset - size}[whence]
+						pos, err := reader.Seek(arg, whence)
+						if err != nil || pos != offset {
+							t.Fatalf("Seek(%d, %d) = %d, %v; want %d", arg, whence, pos, err, offset)
+						}
+
+						var got []byte
+						buf := make([]byte, readSize)
+						for {
+							n, err := reader.Read(buf)
+							got = append(got, buf[:n]...)
+							if err == io.EOF {
+								break
+							}
+							if err != nil {
+								t.Fatalf("Read() error = %v", err)
+							}
+						}
+						reader.Close()
+
+						if !bytes.Equal(got, content[offset:]) {
+							t.Fatalf("offset %d, whence %d, read size %d: got %d bytes, want %d matching the content",
+								offset, whence, readSize, len(got), size-offset)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
 // TestHistoryStore_Delete tests deleting items
 func TestHistoryStore_Delete(t *testing.T) {
 	st, cleanup := setupTestDB(t)
@@ -550,7 +641,7 @@ func TestHistoryStore_Clear(t *testing.T) {
 	}
 
 	// Clear
-	err := st.History().Clear()
+	err := st.History().Clear(store.ClearOptions{})
 	if err != nil {
 		t.Fatalf("Clear() error = %v", err)
 	}
@@ -606,6 +697,39 @@ func TestConfigStore_GetSet(t *testing.T) {
 	}
 }
 
+// TestConfigStore_Timestamps tests that updating a key keeps its creation
+// time and advances its update time, both from the store's clock
+func TestConfigStore_Timestamps(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	st.db.NowFunc = func() time.Time { return clock }
+	created := clock
+
+	if err := st.Config().Set("test_key", "first"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	for _, value := range []string{"second", "third"} {
+		clock = clock.Add(time.Hour)
+		if err := st.Config().Set("test_key", value); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+
+		var model ConfigItemModel
+		if err := st.db.First(&model, "key = ?", "test_key").Error; err != nil {
+			t.Fatal(err)
+		}
+		if !model.CreatedAt.Equal(created) || !model.UpdatedAt.Equal(clock) {
+			t.Errorf("after setting %q: created_at %v, updated_at %v; want %v, %v", value, model.CreatedAt, model.UpdatedAt, created, clock)
+		}
+		got, updatedAt, err := st.Config().GetWithMeta("test_key")
+		if err != nil || got != value || !updatedAt.Equal(clock) {
+			t.Errorf("GetWithMeta() = %q, %v, %v; want %q, %v", got, updatedAt, err, value, clock)
+		}
+	}
+}
+
 // TestConfigStore_List tests listing all config values
 func TestConfigStore_List(t *testing.T) {
 	st, cleanup := setupTestDB(t)
@@ -624,8 +748,8 @@ func TestConfigStore_List(t *testing.T) {
 	if configs["show_binary"] != "false" {
 		t.Errorf("expected show_binary=false, got %s", configs["show_binary"])
 	}
-	if configs["db_version"] != "1" {
-		t.Errorf("expected db_version=1, got %s", configs["db_version"])
+	if configs["db_version"] != strconv.Itoa(SchemaVersion) {
+		t.Errorf("expected db_version=%d, got %s", SchemaVersion, configs["db_version"])
 	}
 }
 
@@ -695,7 +819,7 @@ func TestIsBinary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isBinary(tt.data)
+			got := store.IsBinary(tt.data)
 			if got != tt.want {
 				t.Errorf("isBinary() = %v, want %v", got, tt.want)
 			}
@@ -1258,3 +1382,463 @@ func TestHistoryStore_SearchWithChunkedContent(t *testing.T) {
 		t.Errorf("Search() result title = %q, want %q", results[0].Title, "Large Item")
 	}
 }
+
+// TestHistoryStore_CreateDiskFull tests that a disk-full error mid-write
+// rolls back the item and is reported as store.ErrDiskFull
+func TestHistoryStore_CreateDiskFull(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	// Fail the second chunk write the way SQLite reports SQLITE_FULL
+	chunkWrites := 0
+	err := st.db.Callback().Create().Before("gorm:create").Register("test:disk_full", func(tx *gorm.DB) {
+		if tx.Statement.Table == "file_chunks" {
+			chunkWrites++
+			if chunkWrites == 2 {
+				tx.AddError(errors.New("database or disk is full"))
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+
+	h := st.History()
+	_, err = h.Create(&store.CreateHistoryInput{
+		Title:   "too big",
+		Content: strings.NewReader(strings.Repeat("x", 3*ChunkSize)),
+	})
+	if !errors.Is(err, store.ErrDiskFull) {
+		t.Fatalf("Create() error = %v, want ErrDiskFull", err)
+	}
+	if err.Error() != "disk full: freed nothing, item not stored" {
+		t.Errorf("Create() error message = %q", err.Error())
+	}
+
+	if count, _ := h.Count(); count != 0 {
+		t.Errorf("Count() = %d after failed create, want 0", count)
+	}
+	var chunks int64
+	if err := st.db.Model(&FileChunkModel{}).Count(&chunks).Error; err != nil {
+		t.Fatalf("failed to count chunks: %v", err)
+	}
+	if chunks != 0 {
+		t.Errorf("found %d orphan chunks after failed create", chunks)
+	}
+}
+
+func TestIsDiskFull(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("database or disk is full"), true},
+		{fmt.Errorf("write: %w", syscall.ENOSPC), true},
+		{errors.New("database is locked"), false},
+	}
+	for _, tt := range tests {
+		if got := isDiskFull(tt.err); got != tt.want {
+			t.Errorf("isDiskFull(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestConformance runs the shared store behavior suite against the SQLite store.
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		st, _ := setupTestDB(t)
+		return st
+	})
+}
+
+// setDBVersion overwrites db_version in the database at dbPath
+func setDBVersion(t *testing.T, dbPath, version string) {
+	t.Helper()
+
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	defer st.Close()
+	if err := st.Config().Set("db_version", version); err != nil {
+		t.Fatalf("failed to set db_version: %v", err)
+	}
+}
+
+// TestNewSQLiteStore_RefusesNewerSchema tests that a database from a newer rem is not migrated
+func TestNewSQLiteStore_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "newer.db")
+	setDBVersion(t, dbPath, strconv.Itoa(SchemaVersion+1))
+
+	_, err := NewSQLiteStore(dbPath)
+	if !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("expected ErrNewerSchema, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "--read-only") {
+		t.Errorf("expected error to mention --read-only, got %v", err)
+	}
+
+	// Read-only access is still allowed
+	st, err := NewSQLiteStoreWithOptions(dbPath, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("read-only open failed: %v", err)
+	}
+	defer st.Close()
+
+	version, err := st.Config().Get("db_version")
+	if err != nil {
+		t.Fatalf("failed to get db_version: %v", err)
+	}
+	if version != strconv.Itoa(SchemaVersion+1) {
+		t.Errorf("expected db_version to be left at %d, got %s", SchemaVersion+1, version)
+	}
+	if err := st.Config().Set("history_limit", "10"); err == nil {
+		t.Error("expected writes to fail on a read-only store")
+	}
+}
+
+// TestNewSQLiteStore_NotSQLite tests that other files are refused untouched
+func TestNewSQLiteStore_NotSQLite(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{"{\"history\": []}\n", "SQLite"} {
+		path := filepath.Join(dir, "fake.db")
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewSQLiteStore(path); !errors.Is(err, ErrNotSQLite) {
+			t.Errorf("%q: expected ErrNotSQLite, got %v", content, err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("%q: expected the file untouched, got %q", content, data)
+		}
+	}
+
+	// An empty file becomes a new database
+	empty := filepath.Join(dir, "empty.db")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	st, err := NewSQLiteStore(empty)
+	if err != nil {
+		t.Fatalf("expected an empty file to open, got %v", err)
+	}
+	st.Close()
+	if _, err := NewSQLiteStore(empty); err != nil {
+		t.Errorf("expected the new database to reopen, got %v", err)
+	}
+}
+
+// TestNewSQLiteStore_ReadOnlyRequiresExistingDB tests that read-only never creates a database
+func TestNewSQLiteStore_ReadOnlyRequiresExistingDB(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "missing.db")
+	if _, err := NewSQLiteStoreWithOptions(dbPath, Options{ReadOnly: true}); err == nil {
+		t.Fatal("expected read-only open of a missing database to fail")
+	}
+}
+
+// TestNewSQLiteStore_MigratesOlderSchema tests forward migration from version 1
+func TestNewSQLiteStore_MigratesOlderSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "v1.db")
+
+	// Build a version 1 database: no note column, no migration rows
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	item, err := st.History().Create(&store.CreateHistoryInput{
+		Title:   "kept",
+		Content: strings.NewReader("survives migration"),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, stmt := range []string{
+		"ALTER TABLE history_items DROP COLUMN note",
+		"DROP INDEX idx_history_items_size",
+		"DROP INDEX idx_history_seq",
+		"CREATE INDEX idx_history_seq ON file_chunks (history_id, sequence)",
+		"INSERT INTO file_chunks (history_id, sequence, data, raw_size, created_at) SELECT history_id, sequence, 'duplicate', 9, created_at FROM file_chunks",
+		"DROP TABLE audit_log",
+		"DELETE FROM schema_migrations",
+		"UPDATE config SET value = '1' WHERE key = 'db_version'",
+	} {
+		if err := st.db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	st.Close()
+
+	// Read-only access lists items without the note column
+	ro, err := NewSQLiteStoreWithOptions(dbPath, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("read-only open failed: %v", err)
+	}
+	if items, err := ro.History().List(0); err != nil || len(items) != 1 {
+		t.Errorf("expected read-only List() of a version 1 database to work, got %d items (%v)", len(items), err)
+	}
+	if entries, err := ro.AuditLog(0); err != nil || len(entries) != 0 {
+		t.Errorf("expected read-only AuditLog() of a version 1 database to be empty, got %d entries (%v)", len(entries), err)
+	}
+	ro.Close()
+
+	// Reopening migrates to the current version
+	st, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer st.Close()
+
+	if !st.db.Migrator().HasColumn(&HistoryItemModel{}, "note") {
+		t.Error("expected note column to be added by migration")
+	}
+	if !st.db.Migrator().HasIndex(&HistoryItemModel{}, "Size") {
+		t.Error("expected size index to be added by migration")
+	}
+	if !chunkIndexUnique(t, st) {
+		t.Error("expected the chunk index to be made unique by migration")
+	}
+	if !st.db.Migrator().HasTable(&AuditModel{}) {
+		t.Error("expected audit_log table to be added by migration")
+	}
+	version, err := st.Config().Get("db_version")
+	if err != nil {
+		t.Fatalf("failed to get db_version: %v", err)
+	}
+	if version != strconv.Itoa(SchemaVersion) {
+		t.Errorf("expected db_version=%d, got %s", SchemaVersion, version)
+	}
+
+	var applied []SchemaMigrationModel
+	if err := st.db.Order("version").Find(&applied).Error; err != nil {
+		t.Fatalf("failed to read schema_migrations: %v", err)
+	}
+	if len(applied) != len(migrations) || applied[len(applied)-1].Version != SchemaVersion {
+		t.Errorf("expected %d applied migrations ending at %d, got %+v", len(migrations), SchemaVersion, applied)
+	}
+
+	// Existing content is untouched
+	reader, err := st.History().GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	if string(data) != "survives migration" {
+		t.Errorf("expected content to survive migration, got %q", data)
+	}
+}
+
+// TestTimestampsStoredInUTC tests that times from any zone are stored in
+// UTC, so the newest item sorts first, and that migration 9 converts the
+// times written with an offset before it
+func TestTimestampsStoredInUTC(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "utc.db")
+	st, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	// Tokyo's 09:00 is an hour after New York's 19:00 the day before, but
+	// sorts first as text
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork := time.FixedZone("EDT", -4*60*60)
+	older := time.Date(2024, 4, 30, 19, 0, 0, 0, newYork)
+	newer := time.Date(2024, 5, 1, 9, 0, 0, 0, tokyo)
+	for _, spec := range []struct {
+		title string
+		at    time.Time
+	}{{"older", older}, {"newer", newer}} {
+		_, err := st.History().Create(&store.CreateHistoryInput{Title: spec.title, Content: strings.NewReader(spec.title), Timestamp: spec.at})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	var stored []string
+	if err := st.db.Raw("SELECT CAST(timestamp AS TEXT) FROM history_items UNION ALL SELECT CAST(created_at AS TEXT) FROM history_items").Scan(&stored).Error; err != nil {
+		t.Fatalf("failed to read timestamps: %v", err)
+	}
+	for _, text := range stored {
+		if !strings.HasSuffix(text, "+00:00") {
+			t.Errorf("stored time %q, want it in UTC", text)
+		}
+	}
+	items, err := st.History().List(0)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("List() = %d items, %v", len(items), err)
+	}
+	if items[0].Title != "newer" || !items[0].Timestamp.Equal(newer) || !items[1].Timestamp.Equal(older) {
+		t.Errorf("List() = %s at %v, %s at %v; want newer first at the times stored", items[0].Title, items[0].Timestamp, items[1].Title, items[1].Timestamp)
+	}
+
+	// A database from before migration 9 has the writer's offset
+	for _, stmt := range []string{
+		"UPDATE history_items SET timestamp = '2024-05-01 09:00:00+09:00' WHERE title = 'newer'",
+		"UPDATE history_items SET timestamp = '2024-04-30 19:00:00-04:00' WHERE title = 'older'",
+		"UPDATE config SET value = '8' WHERE key = 'db_version'",
+	} {
+		if err := st.db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	st.Close()
+
+	st, err = NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer st.Close()
+	stored = nil
+	if err := st.db.Raw("SELECT CAST(timestamp AS TEXT) FROM history_items ORDER BY timestamp DESC").Scan(&stored).Error; err != nil {
+		t.Fatalf("failed to read timestamps: %v", err)
+	}
+	if len(stored) != 2 || !strings.HasPrefix(stored[0], "2024-05-01 00:00:00") || !strings.HasPrefix(stored[1], "2024-04-30 23:00:00") {
+		t.Errorf("migrated timestamps = %q, want them in UTC, newest first", stored)
+	}
+}
+
+// TestListOrdered tests that sorting in SQL agrees with sorting in memory,
+// ties included, and that positions are List's
+func TestListOrdered(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, spec := range []struct {
+		title string
+		size  int
+		age   int
+	}{
+		{"beta", 300, 0}, {"alpha", 10, 1}, {"beta", 300, 2}, {"Gamma", 10, 2}, {"delta", 5000, 3}, {"alpha", 0, 4},
+	} {
+		_, err := st.History().Create(&store.CreateHistoryInput{
+			Title:     spec.title,
+			Content:   strings.NewReader(strings.Repeat("x", spec.size)),
+			Timestamp: base.Add(-time.Duration(spec.age) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("Create(%d) error = %v", i, err)
+		}
+	}
+
+	all, err := st.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	ids := func(items []*store.HistoryItem) []uint {
+		out := make([]uint, len(items))
+		for i, item := range items {
+			out[i] = item.ID
+		}
+		return out
+	}
+	orderedIDs := func(items []store.OrderedItem) []uint {
+		out := make([]uint, len(items))
+		for i, item := range items {
+			out[i] = item.Item.ID
+		}
+		return out
+	}
+	lister := st.History().(store.OrderedLister)
+	for _, key := range []store.SortKey{store.SortAge, store.SortSize, store.SortTitle} {
+		for _, reverse := range []bool{false, true} {
+			order := store.OrderSpec{Key: key, Reverse: reverse}
+			want := slices.Clone(all)
+			store.SortItems(want, order)
+
+			got, err := lister.ListOrdered(order, 0)
+			if err != nil {
+				t.Fatalf("ListOrdered(%+v) error = %v", order, err)
+			}
+			if !slices.Equal(orderedIDs(got), ids(want)) {
+				t.Errorf("ListOrdered(%+v) = %v, want %v", order, orderedIDs(got), ids(want))
+			}
+			for _, listed := range got {
+				if all[listed.Position].ID != listed.Item.ID {
+					t.Errorf("ListOrdered(%+v) put item %d at position %d, List has it at %d", order, listed.Item.ID, listed.Position, slices.Index(ids(all), listed.Item.ID))
+				}
+			}
+			if limited, _ := lister.ListOrdered(order, 2); !slices.Equal(orderedIDs(limited), ids(want)[:2]) {
+				t.Errorf("ListOrdered(%+v, 2) = %v, want %v", order, orderedIDs(limited), ids(want)[:2])
+			}
+		}
+	}
+	if got, _ := lister.ListOrdered(store.OrderSpec{Key: store.SortSize}, 1); got[0].Item.Title != "delta" {
+		t.Errorf("expected the largest item first, got %q", got[0].Item.Title)
+	}
+	if _, err := lister.ListOrdered(store.OrderSpec{Key: "usage"}, 0); err == nil {
+		t.Error("expected an unknown sort key to fail")
+	}
+}
+
+// TestNewSQLiteStore_NewDatabaseAtCurrentVersion tests that fresh databases need no migration
+func TestNewSQLiteStore_NewDatabaseAtCurrentVersion(t *testing.T) {
+	st, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	var count int64
+	if err := st.db.Model(&SchemaMigrationModel{}).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count schema_migrations: %v", err)
+	}
+	if int(count) != len(migrations) {
+		t.Errorf("expected %d migration rows, got %d", len(migrations), count)
+	}
+}
+
+// benchmarkItems is the number of synthetic items the list benchmarks walk
+const benchmarkItems = 50000
+
+// setupBenchmarkDB creates a database holding benchmarkItems items' metadata.
+// Chunks are left out, since listing never reads them.
+func setupBenchmarkDB(b *testing.B) *SQLiteStore {
+	b.Helper()
+
+	st, err := NewSQLiteStore(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("failed to create store: %v", err)
+	}
+	b.Cleanup(func() { st.Close() })
+
+	base := time.Now().Add(-benchmarkItems * time.Second)
+	models := make([]HistoryItemModel, benchmarkItems)
+	for i := range models {
+		models[i] = HistoryItemModel{
+			Title:     fmt.Sprintf("synthetic item %d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Size:      int64(i),
+			SHA256:    fmt.Sprintf("%064x", i),
+		}
+	}
+	if err := st.db.CreateInBatches(models, 1000).Error; err != nil {
+		b.Fatalf("failed to create items: %v", err)
+	}
+	return st
+}
+
+func BenchmarkHistoryList(b *testing.B) {
+	st := setupBenchmarkDB(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items, err := st.History().List(0)
+		if err != nil || len(items) != benchmarkItems {
+			b.Fatalf("List() = %d items, %v", len(items), err)
+		}
+	}
+}
+
+func BenchmarkHistoryIterate(b *testing.B) {
+	st := setupBenchmarkDB(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		count := 0
+		err := st.History().Iterate(store.IterOptions{}, func(*store.HistoryItem) (bool, error) {
+			count++
+			return false, nil
+		})
+		if err != nil || count != benchmarkItems {
+			b.Fatalf("Iterate() visited %d items, %v", count, err)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SearchMatchCount returns the MatchCount for an item whose title and content
+// matched as given: the matching content lines, or 1 for a title-only match
+func SearchMatchCount(titleMatched bool, contentLines int) int {
+	if contentLines == 0 && titleMatched {
+		return 1
+	}
+	return contentLines
+}
+
+// MatchContent matches re against searched, content in the form query's
+// pattern is matched against. As query asks, it also counts the matching
+// lines and takes snippets of them from original, the content as stored,
+// in the same scan.
+func MatchContent(re *regexp.Regexp, query *SearchQuery, searched, original string) (matched bool, lines int, snippets []string) {
+	if !query.CountMatches && !query.MatchDetails {
+		return re.MatchString(searched), 0, nil
+	}
+	n := 0
+	if query.MatchDetails {
+		n = MaxSnippets
+	}
+	lines, snippets = scanMatchingLines(re, searched, original, query.CountMatches, n)
+	if query.CountMatches {
+		return lines > 0, lines, snippets
+	}
+	// A match spanning lines has no matching line to show
+	return lines > 0 || re.MatchString(searched), lines, snippets
+}
+
+// MatchedFields returns the MatchedIn of an item whose fields matched as given
+func MatchedFields(title, content, note bool) []string {
+	var fields []string
+	if title {
+		fields = append(fields, "title")
+	}
+	if content {
+		fields = append(fields, "content")
+	}
+	if note {
+		fields = append(fields, "note")
+	}
+	return fields
+}
+
+// MaxSnippets is the number of content snippets a search with MatchDetails
+// keeps for each item
+const MaxSnippets = 3
+
+// snippetWidth is the most characters of a line a snippet shows
+const snippetWidth = 120
+
+// scanMatchingLines scans searched, content in the form a pattern is
+// matched against, for lines matching re. It counts every matching line if
+// count is set and otherwise stops after the snippets'th, taking a snippet
+// of each of the first snippets lines from the same line of original, the
+// content as stored. Normalizing never joins or splits lines, so the lines
+// of searched are the lines of original.
+func scanMatchingLines(re *regexp.Regexp, searched, original string, count bool, snippets int) (int, []string) {
+	lines := 0
+	var found []string
+	for searched != "" && (count || lines < snippets) {
+		line, originalLine := searched, original
+		if i := strings.IndexByte(searched, '\n'); i >= 0 {
+			line, searched = searched[:i], searched[i+1:]
+		} else {
+			searched = ""
+		}
+		if i := strings.IndexByte(original, '\n'); i >= 0 {
+			originalLine, original = original[:i], original[i+1:]
+		} else {
+			original = ""
+		}
+		if !re.MatchString(line) {
+			continue
+		}
+		lines++
+		if len(found) < snippets {
+			found = append(found, snippet(re, originalLine))
+		}
+	}
+	return lines, found
+}
+
+// snippet returns line trimmed of surrounding space and cut to about
+// snippetWidth characters around the first match of re, which is marked
+// «like this». A line whose match normalizing accounted for has no match
+// to mark, so it is only cut.
+func snippet(re *regexp.Regexp, line string) string {
+	line = strings.TrimSpace(line)
+	loc := re.FindStringIndex(line)
+	if loc == nil || loc[0] == loc[1] {
+		return cutRunes(line, snippetWidth)
+	}
+	before := []rune(line[:loc[0]])
+	match := []rune(line[loc[0]:loc[1]])
+	after := []rune(line[loc[1]:])
+
+	if len(match) > snippetWidth {
+		match = append(match[:snippetWidth-1], '…')
+	}
+	// Up to a third of what's left goes before the match, the rest after
+	room := snippetWidth - len(match)
+	keepBefore := min(len(before), max(room/3, room-len(after)))
+	keepAfter := min(len(after), room-keepBefore)
+
+	var b strings.Builder
+	if keepBefore < len(before) {
+		b.WriteString("…")
+		keepBefore = max(keepBefore-1, 0)
+	}
+	b.WriteString(string(before[len(before)-keepBefore:]))
+	b.WriteString("«" + string(match) + "»")
+	if keepAfter < len(after) {
+		b.WriteString(string(after[:max(keepAfter-1, 0)]) + "…")
+	} else {
+		b.WriteString(string(after))
+	}
+	return b.String()
+}
+
+// cutRunes cuts s to at most n characters, ending a cut string with "…"
+func cutRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,82 @@
+package memstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// memoryAuditLog records the items removed from a memory store's history.
+type memoryAuditLog struct {
+	mu      sync.Mutex
+	entries []*store.AuditEntry // oldest first
+	nextID  uint
+	source  string
+	config  store.ConfigStore // holds audit_keep_days
+	now     func() time.Time  // clock for the timestamps
+}
+
+// newMemoryAuditLog creates an audit log kept for config's audit_keep_days.
+func newMemoryAuditLog(config store.ConfigStore) *memoryAuditLog {
+	return &memoryAuditLog{nextID: 1, config: config, now: time.Now}
+}
+
+// record adds an entry for each removed item, or one without an item when
+// there are none, and prunes entries older than audit_keep_days. It records
+// nothing when audit_keep_days is 0.
+func (a *memoryAuditLog) record(operation string, removed []*store.HistoryItem) {
+	keepDays := store.AuditKeepDays(a.config)
+	if keepDays == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	add := func(entry *store.AuditEntry) {
+		entry.ID, entry.Timestamp, entry.Operation, entry.Source = a.nextID, now, operation, a.source
+		a.entries = append(a.entries, entry)
+		a.nextID++
+	}
+	for _, item := range removed {
+		id := item.ID
+		add(&store.AuditEntry{ItemID: &id, ItemTitle: item.Title, ItemSHA256: item.SHA256})
+	}
+	if len(removed) == 0 {
+		add(&store.AuditEntry{})
+	}
+
+	cutoff := now.AddDate(0, 0, -keepDays)
+	kept := a.entries[:0]
+	for _, entry := range a.entries {
+		if !entry.Timestamp.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	a.entries = kept
+}
+
+// SetAuditSource sets the source recorded with later audit entries.
+func (m *MemoryStore) SetAuditSource(source string) {
+	m.audit.mu.Lock()
+	defer m.audit.mu.Unlock()
+	m.audit.source = source
+}
+
+// AuditLog returns copies of the audit entries, newest first; limit 0
+// returns all of them.
+func (m *MemoryStore) AuditLog(limit int) ([]*store.AuditEntry, error) {
+	m.audit.mu.Lock()
+	defer m.audit.mu.Unlock()
+
+	entries := []*store.AuditEntry{}
+	for i := len(m.audit.entries) - 1; i >= 0; i-- {
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+		entry := *m.audit.entries[i]
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
@@ -6,9 +6,10 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
+	"maps"
 	"sort"
 	"sync"
 	"time"
@@ -16,22 +17,47 @@ import (
 	"github.com/yiblet/rem/internal/store"
 )
 
+// ErrStoreFull is returned by Create and ReplaceContent when content would
+// take a store past its limit. Nothing is changed or evicted.
+var ErrStoreFull = errors.New("memory store full: item not stored")
+
 // MemoryStore is an in-memory implementation of store.Store.
 // It uses maps for storage and is thread-safe via mutexes.
 // Data is not persisted and exists only for the lifetime of the process.
 type MemoryStore struct {
 	history *memoryHistoryStore
 	config  *memoryConfigStore
+	audit   *memoryAuditLog
 }
 
 // NewMemoryStore creates a new in-memory store for testing.
+// Its content is unlimited; see NewMemoryStoreWithLimit.
 func NewMemoryStore() *MemoryStore {
+	return NewMemoryStoreWithLimit(0)
+}
+
+// NewMemoryStoreWithLimit creates an in-memory store holding at most
+// maxTotalBytes of content across all items, or unlimited content if
+// maxTotalBytes is 0 or less.
+func NewMemoryStoreWithLimit(maxTotalBytes int64) *MemoryStore {
+	config := newMemoryConfigStore()
+	history := newMemoryHistoryStore()
+	history.maxBytes = max(maxTotalBytes, 0)
+	history.audit = newMemoryAuditLog(config)
 	return &MemoryStore{
-		history: newMemoryHistoryStore(),
-		config:  newMemoryConfigStore(),
+		history: history,
+		config:  config,
+		audit:   history.audit,
 	}
 }
 
+// TotalSize returns the bytes of content the store holds.
+func (m *MemoryStore) TotalSize() int64 {
+	m.history.mu.RLock()
+	defer m.history.mu.RUnlock()
+	return m.history.totalBytes
+}
+
 // History returns the history store.
 func (m *MemoryStore) History() store.HistoryStore {
 	return m.history
@@ -49,15 +75,22 @@ func (m *MemoryStore) Close() error {
 
 // memoryHistoryStore implements store.HistoryStore using in-memory maps.
 type memoryHistoryStore struct {
-	mu     sync.RWMutex
-	items  map[uint]*historyEntry
-	nextID uint
+	mu         sync.RWMutex
+	items      map[uint]*historyEntry
+	nextID     uint
+	generation uint64 // changes made so far
+	maxBytes   int64  // limit on totalBytes; 0 for none
+	totalBytes int64  // content held by items
+
+	audit *memoryAuditLog // records removed items; nil skips it
 }
 
 // historyEntry holds both the item metadata and content in memory.
 type historyEntry struct {
-	item    *store.HistoryItem
-	content []byte
+	item     *store.HistoryItem
+	content  []byte
+	meta     map[string]string
+	sections []store.Section
 }
 
 // newMemoryHistoryStore creates a new in-memory history store.
@@ -68,24 +101,52 @@ func newMemoryHistoryStore() *memoryHistoryStore {
 	}
 }
 
+// readContent reads content into memory, hashing it as it goes. With a
+// limit, it stops reading past maxBytes, so a huge reader fails before it
+// can use more memory than the store may hold at all.
+func (m *memoryHistoryStore) readContent(content io.Reader) ([]byte, string, error) {
+	hash := sha256.New()
+	reader := io.TeeReader(content, hash)
+	if m.maxBytes > 0 {
+		reader = io.LimitReader(reader, m.maxBytes+1)
+	}
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read content: %w", err)
+	}
+	if m.maxBytes > 0 && n > m.maxBytes {
+		return nil, "", fmt.Errorf("%w (content is over the %d byte limit)", ErrStoreFull, m.maxBytes)
+	}
+	return buf.Bytes(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reserve checks that size more bytes of content fit in the store.
+// Callers hold the write lock.
+func (m *memoryHistoryStore) reserve(size int64) error {
+	if m.maxBytes > 0 && m.totalBytes+size > m.maxBytes {
+		return fmt.Errorf("%w (%d of %d bytes used, %d more needed)", ErrStoreFull, m.totalBytes, m.maxBytes, size)
+	}
+	return nil
+}
+
 // Create stores a new history item by reading the entire content into memory.
 func (m *memoryHistoryStore) Create(input *store.CreateHistoryInput) (*store.HistoryItem, error) {
-	// Read entire content into memory
-	content, err := io.ReadAll(input.Content)
+	content, sha256Hash, err := m.readContent(input.Content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
+		return nil, err
 	}
 
-	// Calculate SHA256 hash
-	hash := sha256.Sum256(content)
-	sha256Hash := hex.EncodeToString(hash[:])
-
 	// Detect binary content
-	isBinary := isBinary(content)
+	isBinary := store.IsBinary(content)
 
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if err := m.reserve(int64(len(content))); err != nil {
+		return nil, err
+	}
+
 	id := m.nextID
 	m.nextID++
 
@@ -105,12 +166,18 @@ func (m *memoryHistoryStore) Create(input *store.CreateHistoryInput) (*store.His
 		SHA256:    sha256Hash,
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		OriginalName:    input.OriginalName,
+		OriginalModTime: input.OriginalModTime,
+		OriginalMode:    input.OriginalMode,
 	}
 
 	m.items[id] = &historyEntry{
 		item:    item,
 		content: content,
 	}
+	m.totalBytes += item.Size
+	m.generation++
 
 	return item, nil
 }
@@ -128,7 +195,7 @@ func (m *memoryHistoryStore) List(limit int) ([]*store.HistoryItem, error) {
 
 	// Sort by timestamp descending (newest first - LIFO)
 	sort.Slice(items, func(i, j int) bool {
-		return items[i].Timestamp.After(items[j].Timestamp)
+		return newerThan(items[i], items[j])
 	})
 
 	// Apply limit
@@ -139,6 +206,74 @@ func (m *memoryHistoryStore) List(limit int) ([]*store.HistoryItem, error) {
 	return items, nil
 }
 
+// ListAfterID returns items with an ID above id in ID order, excluding content
+func (m *memoryHistoryStore) ListAfterID(id uint) ([]*store.HistoryItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := []*store.HistoryItem{}
+	for _, entry := range m.items {
+		if entry.item.ID > id {
+			items = append(items, entry.item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+	return items, nil
+}
+
+// newerThan reports whether a comes before b in queue order: newer timestamps
+// first, with the later-inserted (higher ID) item first on a tie
+func newerThan(a, b *store.HistoryItem) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.After(b.Timestamp)
+	}
+	return a.ID > b.ID
+}
+
+// Iterate walks items in queue order a batch at a time. Each batch is the
+// items ordered after the last one seen, so fn may modify the store.
+func (m *memoryHistoryStore) Iterate(opts store.IterOptions, fn func(*store.HistoryItem) (bool, error)) error {
+	before := newerThan
+	if opts.Order.Oldest() {
+		before = func(a, b *store.HistoryItem) bool { return newerThan(b, a) }
+	}
+
+	var last *store.HistoryItem
+	if opts.After != nil {
+		last = &store.HistoryItem{ID: opts.After.ID, Timestamp: opts.After.Timestamp}
+	}
+	for {
+		m.mu.RLock()
+		batch := make([]*store.HistoryItem, 0, len(m.items))
+		for _, entry := range m.items {
+			if last == nil || before(last, entry.item) {
+				batch = append(batch, entry.item)
+			}
+		}
+		m.mu.RUnlock()
+
+		sort.Slice(batch, func(i, j int) bool {
+			return before(batch[i], batch[j])
+		})
+		if len(batch) > opts.Batch() {
+			batch = batch[:opts.Batch()]
+		}
+
+		for _, item := range batch {
+			stop, err := fn(item)
+			if err != nil || stop {
+				return err
+			}
+		}
+		if len(batch) < opts.Batch() {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
 // Get retrieves a single item by ID (without content).
 func (m *memoryHistoryStore) Get(id uint) (*store.HistoryItem, error) {
 	m.mu.RLock()
@@ -159,26 +294,244 @@ func (m *memoryHistoryStore) GetContent(id uint) (io.ReadSeekCloser, error) {
 
 	entry, exists := m.items[id]
 	if !exists {
-		return nil, fmt.Errorf("item not found: %d", id)
+		return nil, fmt.Errorf("item not found: %d (%w)", id, store.ErrItemDeleted)
+	}
+
+	return &bytesReadSeekCloser{reader: bytes.NewReader(entry.content), id: id, items: m}, nil
+}
+
+// GetContentPrefix returns a copy of up to n bytes from the start of an
+// item's content.
+func (m *memoryHistoryStore) GetContentPrefix(id uint, n int) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return nil, fmt.Errorf("item not found: %d (%w)", id, store.ErrItemDeleted)
 	}
+	n = max(min(n, store.MaxContentPrefix, len(entry.content)), 0)
+	return bytes.Clone(entry.content[:n]), nil
+}
 
-	return &bytesReadSeekCloser{reader: bytes.NewReader(entry.content)}, nil
+// exists reports whether the item with id is stored
+func (m *memoryHistoryStore) exists(id uint) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.items[id]
+	return ok
 }
 
-// Delete removes an item by ID.
+// Delete removes an item by ID and records it in the audit log.
 func (m *memoryHistoryStore) Delete(id uint) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.items[id]; !exists {
+	entry, exists := m.items[id]
+	if !exists {
 		return fmt.Errorf("item not found: %d", id)
 	}
 
 	delete(m.items, id)
+	m.totalBytes -= entry.item.Size
+	m.generation++
+	m.recordAudit(store.AuditDelete, []*store.HistoryItem{entry.item})
+	return nil
+}
+
+// recordAudit records removed in the audit log, if the store keeps one.
+func (m *memoryHistoryStore) recordAudit(operation string, removed []*store.HistoryItem) {
+	if m.audit != nil {
+		m.audit.record(operation, removed)
+	}
+}
+
+// updated returns a copy of item changed by change, with UpdatedAt set.
+// Items already handed out keep the values they were read with, as they do
+// from the database.
+func updated(item *store.HistoryItem, change func(*store.HistoryItem)) *store.HistoryItem {
+	copied := *item
+	change(&copied)
+	copied.UpdatedAt = time.Now()
+	return &copied
+}
+
+// UpdateTitle replaces an item's title.
+func (m *memoryHistoryStore) UpdateTitle(id uint, title string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+
+	entry.item = updated(entry.item, func(item *store.HistoryItem) { item.Title = title })
+	m.generation++
+	return nil
+}
+
+// UpdateNote replaces an item's note.
+func (m *memoryHistoryStore) UpdateNote(id uint, note string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+
+	entry.item = updated(entry.item, func(item *store.HistoryItem) { item.Note = note })
+	m.generation++
+	return nil
+}
+
+// UpdateTimestamp replaces an item's ordering timestamp.
+func (m *memoryHistoryStore) UpdateTimestamp(id uint, timestamp time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+
+	entry.item = updated(entry.item, func(item *store.HistoryItem) { item.Timestamp = timestamp })
+	m.generation++
+	return nil
+}
+
+// ReplaceContent replaces an item's content, recomputing its size, hash and
+// binary flag.
+func (m *memoryHistoryStore) ReplaceContent(id uint, content io.Reader) error {
+	data, hash, err := m.readContent(content)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+	if err := m.reserve(int64(len(data)) - entry.item.Size); err != nil {
+		return err
+	}
+
+	m.totalBytes += int64(len(data)) - entry.item.Size
+	entry.item = updated(entry.item, func(item *store.HistoryItem) {
+		item.Size = int64(len(data))
+		item.SHA256 = hash
+		item.IsBinary = store.IsBinary(data)
+	})
+	entry.content = data
+	entry.sections = nil
+	m.generation++
+	return nil
+}
+
+// UpdateMetadata replaces an item's title, note and timestamp.
+func (m *memoryHistoryStore) UpdateMetadata(id uint, title, note string, timestamp time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+
+	entry.item = updated(entry.item, func(item *store.HistoryItem) {
+		item.Title, item.Note, item.Timestamp = title, note, timestamp
+	})
+	m.generation++
+	return nil
+}
+
+// SetMeta sets one of an item's metadata values.
+func (m *memoryHistoryStore) SetMeta(id uint, key, value string) error {
+	if err := store.ValidateMeta(key, value); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+	if entry.meta == nil {
+		entry.meta = make(map[string]string)
+	}
+	entry.meta[key] = value
+	m.generation++
+	return nil
+}
+
+// GetMeta returns a copy of an item's metadata.
+func (m *memoryHistoryStore) GetMeta(id uint) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return nil, fmt.Errorf("item not found: %d", id)
+	}
+	meta := make(map[string]string, len(entry.meta))
+	maps.Copy(meta, entry.meta)
+	return meta, nil
+}
+
+// SetSections replaces an item's sections.
+func (m *memoryHistoryStore) SetSections(id uint, sections []store.Section) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+	if err := store.ValidateSections(sections, entry.item.Size); err != nil {
+		return err
+	}
+	entry.sections = append([]store.Section(nil), sections...)
+	m.generation++
+	return nil
+}
+
+// GetSections returns a copy of an item's sections.
+func (m *memoryHistoryStore) GetSections(id uint) ([]store.Section, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return nil, fmt.Errorf("item not found: %d", id)
+	}
+	return append([]store.Section{}, entry.sections...), nil
+}
+
+// DeleteMeta removes one of an item's metadata keys.
+func (m *memoryHistoryStore) DeleteMeta(id uint, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.items[id]
+	if !exists {
+		return fmt.Errorf("item not found: %d", id)
+	}
+	if _, ok := entry.meta[key]; !ok {
+		return fmt.Errorf("item %d has no metadata key %q", id, key)
+	}
+	delete(entry.meta, key)
+	m.generation++
 	return nil
 }
 
-// DeleteOldest removes the N oldest items by timestamp.
+// DeleteOldest removes the N oldest items by timestamp, recording them in
+// the audit log.
 func (m *memoryHistoryStore) DeleteOldest(count int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -190,7 +543,7 @@ func (m *memoryHistoryStore) DeleteOldest(count int) error {
 	}
 
 	sort.Slice(items, func(i, j int) bool {
-		return items[i].Timestamp.Before(items[j].Timestamp)
+		return newerThan(items[j], items[i])
 	})
 
 	// Delete oldest N items
@@ -201,6 +554,11 @@ func (m *memoryHistoryStore) DeleteOldest(count int) error {
 
 	for i := 0; i < toDelete; i++ {
 		delete(m.items, items[i].ID)
+		m.totalBytes -= items[i].Size
+	}
+	if toDelete > 0 {
+		m.generation++
+		m.recordAudit(store.AuditEvict, items[:toDelete])
 	}
 
 	return nil
@@ -213,28 +571,61 @@ func (m *memoryHistoryStore) Count() (int, error) {
 	return len(m.items), nil
 }
 
-// Clear removes all items.
-func (m *memoryHistoryStore) Clear() error {
+// Clear removes all items, keeping the ID counter unless opts resets it,
+// and records them in the audit log.
+func (m *memoryHistoryStore) Clear(opts store.ClearOptions) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	removed := make([]*store.HistoryItem, 0, len(m.items))
+	for _, entry := range m.items {
+		removed = append(removed, entry.item)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return newerThan(removed[j], removed[i])
+	})
 	m.items = make(map[uint]*historyEntry)
+	m.totalBytes = 0
+	m.generation++
+	if opts.ResetIDs {
+		m.nextID = 1
+	}
+	m.recordAudit(store.AuditClear, removed)
 	return nil
 }
 
+// Generation returns the number of changes made so far.
+func (m *memoryHistoryStore) Generation() (uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.generation, nil
+}
+
+// FindBySHA256 returns items whose content hash matches, newest first.
+func (m *memoryHistoryStore) FindBySHA256(hash string) ([]*store.HistoryItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := []*store.HistoryItem{}
+	for _, entry := range m.items {
+		if entry.item.SHA256 == hash {
+			results = append(results, entry.item)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return newerThan(results[i], results[j])
+	})
+	return results, nil
+}
+
 // Search finds items matching the query pattern using regex.
 func (m *memoryHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryItem, error) {
-	if query.Pattern == "" {
+	if query.Pattern == "" && !query.HasFilter() {
 		return []*store.HistoryItem{}, nil
 	}
 
-	// Compile regex pattern
-	var re *regexp.Regexp
-	var err error
-	if query.CaseSensitive {
-		re, err = regexp.Compile(query.Pattern)
-	} else {
-		re, err = regexp.Compile("(?i)" + query.Pattern)
-	}
+	// Compile regex pattern; an empty pattern matches every item in the window
+	re, err := store.CompilePattern(query)
 	if err != nil {
 		return nil, fmt.Errorf("invalid regex pattern: %w", err)
 	}
@@ -245,46 +636,72 @@ func (m *memoryHistoryStore) Search(query *store.SearchQuery) ([]*store.HistoryI
 	// Determine what to search
 	searchTitle := query.SearchTitle
 	searchContent := query.SearchContent
-	// If both are false, search both (default behavior)
-	if !searchTitle && !searchContent {
+	searchNotes := query.SearchNotes
+	// If none are set, search all of them (default behavior)
+	if !searchTitle && !searchContent && !searchNotes {
 		searchTitle = true
 		searchContent = true
+		searchNotes = true
 	}
 
 	var results []*store.HistoryItem
 
 	// Search through all items
 	for _, entry := range m.items {
-		matched := false
-
-		// Search in title if requested
-		if searchTitle && re.MatchString(entry.item.Title) {
-			matched = true
+		if !store.InTimeWindow(entry.item.Timestamp, query.Since, query.Until) ||
+			!store.MatchesMeta(entry.meta, query.MetaEquals) {
+			continue
 		}
 
-		// Search in content if requested and not yet matched
-		if !matched && searchContent {
-			contentStr := string(entry.content)
-			if re.MatchString(contentStr) {
-				matched = true
-			}
+		// A title or note match counts once toward MatchCount; match
+		// details need every field checked
+		titleMatched := searchTitle && re.MatchString(store.MatchText(query, entry.item.Title))
+		noteMatched := searchNotes && entry.item.Note != "" && (!titleMatched || query.MatchDetails) &&
+			re.MatchString(store.MatchText(query, entry.item.Note))
+		metaMatched := titleMatched || noteMatched
+		contentMatched := false
+		matchingLines := 0
+		var snippets []string
+
+		// Search in content if requested and not yet matched; counting
+		// matches and match details need the content even when the title matched
+		if searchContent && (!metaMatched || query.CountMatches || query.MatchDetails) {
+			content := string(entry.content)
+			contentMatched, matchingLines, snippets = store.MatchContent(re, query, store.MatchText(query, content), content)
 		}
 
-		if matched {
-			results = append(results, entry.item)
-
-			// Check limit
-			if query.Limit > 0 && len(results) >= query.Limit {
-				break
+		if metaMatched || contentMatched {
+			if query.CountMatches || query.MatchDetails {
+				// Copy so the stored item is not modified
+				item := *entry.item
+				if query.CountMatches {
+					item.MatchCount = store.SearchMatchCount(metaMatched, matchingLines)
+				}
+				if query.MatchDetails {
+					item.MatchedIn = store.MatchedFields(titleMatched, contentMatched, noteMatched)
+					item.Snippets = snippets
+				}
+				results = append(results, &item)
+			} else {
+				results = append(results, entry.item)
 			}
 		}
 	}
 
-	// Sort results by timestamp descending (newest first - LIFO)
+	// Sort results before applying the limit; map iteration order is random
+	oldest := query.OrderBy.Oldest()
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].Timestamp.After(results[j].Timestamp)
+		if oldest {
+			return newerThan(results[j], results[i])
+		}
+		return newerThan(results[i], results[j])
 	})
 
+	// Apply limit
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
 	return results, nil
 }
 
@@ -296,35 +713,58 @@ func (m *memoryHistoryStore) Close() error {
 // memoryConfigStore implements store.ConfigStore using an in-memory map.
 type memoryConfigStore struct {
 	mu     sync.RWMutex
-	config map[string]string
+	config map[string]configEntry
+	now    func() time.Time // clock for the timestamps
+}
+
+// configEntry is a stored configuration value and its timestamps, kept as
+// the SQLite store keeps them.
+type configEntry struct {
+	value     string
+	createdAt time.Time
+	updatedAt time.Time
 }
 
 // newMemoryConfigStore creates a new in-memory config store.
 func newMemoryConfigStore() *memoryConfigStore {
 	return &memoryConfigStore{
-		config: make(map[string]string),
+		config: make(map[string]configEntry),
+		now:    time.Now,
 	}
 }
 
 // Get retrieves a configuration value by key.
 func (m *memoryConfigStore) Get(key string) (string, error) {
+	value, _, err := m.GetWithMeta(key)
+	return value, err
+}
+
+// GetWithMeta retrieves a configuration value and when it was last set.
+func (m *memoryConfigStore) GetWithMeta(key string) (string, time.Time, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	value, exists := m.config[key]
+	entry, exists := m.config[key]
 	if !exists {
-		return "", fmt.Errorf("config key not found: %s", key)
+		return "", time.Time{}, fmt.Errorf("config key not found: %s", key)
 	}
 
-	return value, nil
+	return entry.value, entry.updatedAt, nil
 }
 
-// Set stores a configuration value.
+// Set stores a configuration value. A new key's timestamps are the same;
+// an existing key keeps its creation time.
 func (m *memoryConfigStore) Set(key, value string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.config[key] = value
+	now := m.now()
+	entry, exists := m.config[key]
+	if !exists {
+		entry.createdAt = now
+	}
+	entry.value, entry.updatedAt = value, now
+	m.config[key] = entry
 	return nil
 }
 
@@ -335,8 +775,8 @@ func (m *memoryConfigStore) List() (map[string]string, error) {
 
 	// Return copy to prevent external modification
 	result := make(map[string]string, len(m.config))
-	for k, v := range m.config {
-		result[k] = v
+	for k, entry := range m.config {
+		result[k] = entry.value
 	}
 
 	return result, nil
@@ -361,12 +801,19 @@ func (m *memoryConfigStore) Close() error {
 }
 
 // bytesReadSeekCloser wraps bytes.Reader to implement io.ReadSeekCloser.
+// It holds the content itself, but fails reads once its item is deleted,
+// as the SQLite store's reader does.
 type bytesReadSeekCloser struct {
 	reader *bytes.Reader
+	id     uint
+	items  *memoryHistoryStore // nil for a reader on its own
 }
 
 // Read implements io.Reader.
 func (b *bytesReadSeekCloser) Read(p []byte) (int, error) {
+	if b.items != nil && b.reader.Len() > 0 && !b.items.exists(b.id) {
+		return 0, fmt.Errorf("item %d: %w", b.id, store.ErrItemDeleted)
+	}
 	return b.reader.Read(p)
 }
 
@@ -379,36 +826,3 @@ func (b *bytesReadSeekCloser) Seek(offset int64, whence int) (int64, error) {
 func (b *bytesReadSeekCloser) Close() error {
 	return nil
 }
-
-// isBinary detects if content contains binary data.
-// Uses a simple heuristic: if content contains null bytes or has high ratio
-// of non-printable characters, it's considered binary.
-func isBinary(content []byte) bool {
-	if len(content) == 0 {
-		return false
-	}
-
-	// Check up to first 8KB for performance
-	sampleSize := len(content)
-	if sampleSize > 8192 {
-		sampleSize = 8192
-	}
-
-	nonPrintable := 0
-	for i := 0; i < sampleSize; i++ {
-		b := content[i]
-
-		// Null byte is a strong indicator of binary content
-		if b == 0 {
-			return true
-		}
-
-		// Count non-printable characters (excluding common whitespace)
-		if b < 32 && b != '\n' && b != '\r' && b != '\t' {
-			nonPrintable++
-		}
-	}
-
-	// If more than 30% of characters are non-printable, consider it binary
-	return float64(nonPrintable)/float64(sampleSize) > 0.3
-}
@@ -2,6 +2,7 @@ package memstore
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"strings"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/yiblet/rem/internal/store"
+	"github.com/yiblet/rem/internal/store/storetest"
 )
 
 // TestMemoryStore_Basic tests basic store creation and interface compliance.
@@ -331,7 +333,7 @@ func TestHistoryStore_Clear(t *testing.T) {
 	}
 
 	// Clear
-	if err := h.Clear(); err != nil {
+	if err := h.Clear(store.ClearOptions{}); err != nil {
 		t.Fatalf("Clear() error: %v", err)
 	}
 
@@ -436,6 +438,68 @@ func TestConfigStore_GetAndSet(t *testing.T) {
 	}
 }
 
+// TestConfigStore_Timestamps tests that updating a key keeps its creation
+// time and advances its update time, as the SQLite store does.
+func TestConfigStore_Timestamps(t *testing.T) {
+	s := NewMemoryStore()
+	defer s.Close()
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.config.now = func() time.Time { return clock }
+	created := clock
+
+	if err := s.Config().Set("key1", "value1"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	for _, value := range []string{"value2", "value3"} {
+		clock = clock.Add(time.Hour)
+		if err := s.Config().Set("key1", value); err != nil {
+			t.Fatalf("Set() error: %v", err)
+		}
+
+		entry := s.config.config["key1"]
+		if !entry.createdAt.Equal(created) || !entry.updatedAt.Equal(clock) {
+			t.Errorf("after setting %q: created %v, updated %v; want %v, %v", value, entry.createdAt, entry.updatedAt, created, clock)
+		}
+		got, updatedAt, err := s.Config().GetWithMeta("key1")
+		if err != nil || got != value || !updatedAt.Equal(clock) {
+			t.Errorf("GetWithMeta() = %q, %v, %v; want %q, %v", got, updatedAt, err, value, clock)
+		}
+	}
+}
+
+func TestAuditLog_Retention(t *testing.T) {
+	s := NewMemoryStore()
+	defer s.Close()
+
+	clock := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.audit.now = func() time.Time { return clock }
+	deleteItem := func(title string) {
+		t.Helper()
+		item, err := s.History().Create(&store.CreateHistoryInput{Title: title, Content: strings.NewReader(title)})
+		if err != nil {
+			t.Fatalf("Create() error: %v", err)
+		}
+		if err := s.History().Delete(item.ID); err != nil {
+			t.Fatalf("Delete() error: %v", err)
+		}
+	}
+
+	deleteItem("old")
+	clock = clock.AddDate(0, 0, store.DefaultAuditKeepDays+1)
+	deleteItem("new")
+	entries, _ := s.AuditLog(0)
+	if len(entries) != 1 || entries[0].ItemTitle != "new" {
+		t.Errorf("expected only the new entry kept, got %d entries", len(entries))
+	}
+
+	// Entries handed out are copies
+	entries[0].ItemTitle = "changed"
+	if entries, _ := s.AuditLog(0); entries[0].ItemTitle != "new" {
+		t.Error("expected changing a returned entry to leave the log alone")
+	}
+}
+
 // TestConfigStore_List tests listing all config values.
 func TestConfigStore_List(t *testing.T) {
 	s := NewMemoryStore()
@@ -730,7 +794,7 @@ func TestIsBinary(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isBinary(tt.content)
+			got := store.IsBinary(tt.content)
 			if got != tt.want {
 				t.Errorf("isBinary() = %v, want %v", got, tt.want)
 			}
@@ -1253,3 +1317,91 @@ func TestHistoryStore_SearchNoMatches(t *testing.T) {
 		t.Errorf("Search() with no matches returned %d results, want 0", len(results))
 	}
 }
+
+// TestConformance runs the shared store behavior suite against the memory store.
+// countingReader yields endless zeros, counting the bytes read
+type countingReader struct{ n int64 }
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	clear(p)
+	r.n += int64(len(p))
+	return len(p), nil
+}
+
+// TestHistoryStore_Limit tests that a limited store refuses content past its
+// limit without evicting anything, and keeps count across changes.
+func TestHistoryStore_Limit(t *testing.T) {
+	s := NewMemoryStoreWithLimit(100)
+	defer s.Close()
+	h := s.History()
+
+	create := func(size int) (*store.HistoryItem, error) {
+		return h.Create(&store.CreateHistoryInput{Title: "item", Content: strings.NewReader(strings.Repeat("x", size))})
+	}
+
+	// Filling the store exactly is allowed; one byte more is not
+	first, err := create(60)
+	if err != nil {
+		t.Fatalf("Create(60) error = %v", err)
+	}
+	if _, err := create(40); err != nil {
+		t.Fatalf("Create(40) up to the limit error = %v", err)
+	}
+	if _, err := create(1); !errors.Is(err, ErrStoreFull) {
+		t.Fatalf("Create(1) past the limit error = %v, want ErrStoreFull", err)
+	}
+	if count, _ := h.Count(); count != 2 || s.TotalSize() != 100 {
+		t.Errorf("Expected the failed Create to change nothing, got %d items, %d bytes", count, s.TotalSize())
+	}
+
+	// Deleting frees room, and replacing content counts the difference
+	if err := h.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if s.TotalSize() != 40 {
+		t.Errorf("TotalSize() after Delete = %d, want 40", s.TotalSize())
+	}
+	third, err := create(50)
+	if err != nil {
+		t.Fatalf("Create(50) after Delete error = %v", err)
+	}
+	if err := h.ReplaceContent(third.ID, strings.NewReader(strings.Repeat("y", 61))); !errors.Is(err, ErrStoreFull) {
+		t.Errorf("ReplaceContent past the limit error = %v, want ErrStoreFull", err)
+	}
+	if err := h.ReplaceContent(third.ID, strings.NewReader("short")); err != nil {
+		t.Fatalf("ReplaceContent() error = %v", err)
+	}
+	if s.TotalSize() != 45 {
+		t.Errorf("TotalSize() after ReplaceContent = %d, want 45", s.TotalSize())
+	}
+	if err := h.DeleteOldest(1); err != nil || s.TotalSize() != 5 {
+		t.Errorf("TotalSize() after DeleteOldest = %d (%v), want 5", s.TotalSize(), err)
+	}
+	if err := h.Clear(store.ClearOptions{}); err != nil || s.TotalSize() != 0 {
+		t.Errorf("TotalSize() after Clear = %d (%v), want 0", s.TotalSize(), err)
+	}
+
+	// An endless reader is cut off just past the limit
+	endless := &countingReader{}
+	if _, err := h.Create(&store.CreateHistoryInput{Title: "endless", Content: endless}); !errors.Is(err, ErrStoreFull) {
+		t.Errorf("Create(endless) error = %v, want ErrStoreFull", err)
+	}
+	if endless.n > 101 {
+		t.Errorf("Expected at most 101 bytes read, got %d", endless.n)
+	}
+
+	// The default store has no limit
+	unlimited := NewMemoryStore()
+	if _, err := unlimited.History().Create(&store.CreateHistoryInput{Title: "big", Content: strings.NewReader(strings.Repeat("x", 1<<20))}); err != nil {
+		t.Errorf("Create() on an unlimited store error = %v", err)
+	}
+	if unlimited.TotalSize() != 1<<20 {
+		t.Errorf("TotalSize() = %d, want %d", unlimited.TotalSize(), 1<<20)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return NewMemoryStore()
+	})
+}
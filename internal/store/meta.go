@@ -0,0 +1,42 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaxMetaValueBytes is the longest metadata value an item can hold
+const MaxMetaValueBytes = 4096
+
+// metaKeyPattern is the form of a metadata key: lowercase letters, digits,
+// underscores, dots and hyphens
+var metaKeyPattern = regexp.MustCompile(`^[a-z0-9_.-]{1,64}$`)
+
+// ValidateMetaKey checks that key can name an item's metadata
+func ValidateMetaKey(key string) error {
+	if !metaKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q: use 1 to 64 lowercase letters, digits, '_', '.' or '-'", key)
+	}
+	return nil
+}
+
+// ValidateMeta checks a metadata key and the value to set it to
+func ValidateMeta(key, value string) error {
+	if err := ValidateMetaKey(key); err != nil {
+		return err
+	}
+	if len(value) > MaxMetaValueBytes {
+		return fmt.Errorf("metadata value for %q is %d bytes, over the %d byte limit", key, len(value), MaxMetaValueBytes)
+	}
+	return nil
+}
+
+// MatchesMeta reports whether meta has every key in want set to its value
+func MatchesMeta(meta, want map[string]string) bool {
+	for key, value := range want {
+		if got, ok := meta[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
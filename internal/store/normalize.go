@@ -0,0 +1,30 @@
+package store
+
+import (
+	"regexp"
+
+	"github.com/yiblet/rem/internal/textnorm"
+)
+
+// CompilePattern compiles the query's pattern. Matching is case-insensitive
+// unless CaseSensitive is set. With Normalize, a plain pattern is normalized
+// like the text it is matched against; a pattern with regex metacharacters
+// is used as written, since normalizing it could change its meaning.
+func CompilePattern(query *SearchQuery) (*regexp.Regexp, error) {
+	pattern := query.Pattern
+	if query.Normalize && regexp.QuoteMeta(pattern) == pattern {
+		pattern = textnorm.Normalize(pattern, !query.CaseSensitive)
+	}
+	if !query.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// MatchText returns the form of text a query's pattern is matched against
+func MatchText(query *SearchQuery, text string) string {
+	if !query.Normalize {
+		return text
+	}
+	return textnorm.Normalize(text, !query.CaseSensitive)
+}
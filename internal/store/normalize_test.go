@@ -0,0 +1,35 @@
+package store
+
+import (
+	"testing"
+)
+
+func TestCompilePattern_Normalize(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		query   SearchQuery
+		want    bool
+	}{
+		{"uber", "Über Eats", SearchQuery{Normalize: true}, true},
+		{"Über", "uber eats", SearchQuery{Normalize: true}, true},
+		{"file", "ﬁle.txt", SearchQuery{Normalize: true}, true},
+		{"uber", "Über Eats", SearchQuery{}, false},
+		{"Uber", "Über", SearchQuery{Normalize: true, CaseSensitive: true}, true},
+		{"uber", "Über", SearchQuery{Normalize: true, CaseSensitive: true}, false},
+		// Regex patterns aren't normalized, but the text still is
+		{"ub.r", "Über", SearchQuery{Normalize: true}, true},
+		{"Üb.r", "Über", SearchQuery{Normalize: true}, false},
+	}
+	for _, tt := range tests {
+		query := tt.query
+		query.Pattern = tt.pattern
+		re, err := CompilePattern(&query)
+		if err != nil {
+			t.Fatalf("CompilePattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(MatchText(&query, tt.text)); got != tt.want {
+			t.Errorf("%q matching %q with %+v = %v, want %v", tt.pattern, tt.text, tt.query, got, tt.want)
+		}
+	}
+}
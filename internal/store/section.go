@@ -0,0 +1,46 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Section is a named byte range of an item's content, such as one of the
+// files concatenated into it
+type Section struct {
+	Name  string
+	Start int64 // offset of the section's first byte
+	End   int64 // offset just past its last byte
+}
+
+// Size returns the section's length in bytes
+func (s Section) Size() int64 {
+	return s.End - s.Start
+}
+
+// ValidateSections checks that sections are in content order, don't
+// overlap, and lie within size bytes of content
+func ValidateSections(sections []Section, size int64) error {
+	var end int64
+	for i, section := range sections {
+		if section.Start < end || section.End < section.Start || section.End > size {
+			return fmt.Errorf("invalid section %d (%q): bytes %d-%d of %d", i+1, section.Name, section.Start, section.End, size)
+		}
+		end = section.End
+	}
+	return nil
+}
+
+// FindSection returns the section named name or, failing that, the section
+// at that 1-based ordinal
+func FindSection(sections []Section, name string) (Section, bool) {
+	for _, section := range sections {
+		if section.Name == name {
+			return section, true
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(sections) {
+		return sections[n-1], true
+	}
+	return Section{}, false
+}
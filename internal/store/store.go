@@ -4,9 +4,27 @@
 package store
 
 import (
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrDiskFull is returned by Create when the filesystem holding the store is
+// full. The partially written item is rolled back.
+var ErrDiskFull = errors.New("disk full: freed nothing, item not stored")
+
+// ErrItemDeleted is returned by a content reader's Read when its item was
+// deleted, by this or another process, before the content was read to the
+// end. Content already read before the delete is still returned; a reader
+// at the end of its content returns io.EOF as usual. GetContent and
+// GetContentPrefix wrap it in their error for an item that doesn't exist,
+// so reopening a deleted item reports it the same way.
+var ErrItemDeleted = errors.New("item was deleted")
+
+// MaxContentPrefix is the most GetContentPrefix returns; read more with
+// GetContent
+const MaxContentPrefix = 1 << 20
+
 // HistoryStore manages queue item persistence.
 // It provides methods for creating, listing, retrieving, and deleting
 // clipboard history items. Content is stored separately from metadata
@@ -22,6 +40,11 @@ type HistoryStore interface {
 	// If limit is 0, all items are returned. If limit > 0, at most limit items are returned.
 	List(limit int) ([]*HistoryItem, error)
 
+	// ListAfterID returns the items with an ID greater than id, lowest ID
+	// first: those created since the item with that ID, whatever their
+	// timestamps. Content is excluded.
+	ListAfterID(id uint) ([]*HistoryItem, error)
+
 	// Get retrieves a single item by ID.
 	// Content is excluded - use GetContent to retrieve it.
 	Get(id uint) (*HistoryItem, error)
@@ -31,10 +54,42 @@ type HistoryStore interface {
 	// Caller is responsible for closing the reader.
 	GetContent(id uint) (io.ReadSeekCloser, error)
 
+	// GetContentPrefix returns up to n bytes from the start of an item's
+	// content, for callers such as title generation that only look at the
+	// beginning. n is capped at MaxContentPrefix.
+	// Returns an error if the item does not exist.
+	GetContentPrefix(id uint, n int) ([]byte, error)
+
 	// Delete removes an item by ID.
 	// Returns an error if the item does not exist.
 	Delete(id uint) error
 
+	// UpdateTitle replaces an item's title.
+	// The title is stored as given; callers sanitize and truncate it.
+	// Returns an error if the item does not exist.
+	UpdateTitle(id uint, title string) error
+
+	// UpdateNote replaces an item's note. An empty note clears it.
+	// Returns an error if the item does not exist.
+	UpdateNote(id uint, note string) error
+
+	// UpdateTimestamp moves an item in the queue order by replacing its
+	// Timestamp. Content, SHA256 and CreatedAt are unchanged.
+	// Returns an error if the item does not exist.
+	UpdateTimestamp(id uint, timestamp time.Time) error
+
+	// ReplaceContent replaces an item's content, recomputing its Size,
+	// SHA256 and IsBinary, and removes its sections, which no longer fit.
+	// Title, note and Timestamp, and so the item's place in the queue, are
+	// unchanged.
+	// Returns an error if the item does not exist.
+	ReplaceContent(id uint, content io.Reader) error
+
+	// UpdateMetadata replaces an item's title, note and timestamp together,
+	// as when another copy of its content brings better ones.
+	// Returns an error if the item does not exist.
+	UpdateMetadata(id uint, title, note string, timestamp time.Time) error
+
 	// DeleteOldest removes the N oldest items based on timestamp.
 	// If count exceeds the number of items, all items are deleted.
 	DeleteOldest(count int) error
@@ -42,17 +97,92 @@ type HistoryStore interface {
 	// Count returns the total number of items in the store.
 	Count() (int, error)
 
-	// Clear removes all items from the store.
-	Clear() error
+	// Clear removes all items from the store. Items created afterwards get
+	// IDs larger than any used before, unless opts.ResetIDs starts them
+	// from 1 again.
+	Clear(opts ClearOptions) error
+
+	// FindBySHA256 returns all items whose content has the given SHA256 hash,
+	// newest first. Returns an empty slice if there are none.
+	FindBySHA256(hash string) ([]*HistoryItem, error)
+
+	// SetMeta sets an item's metadata key to value, replacing any value the
+	// key had. Keys and values are checked with ValidateMeta.
+	// Returns an error if the item does not exist.
+	SetMeta(id uint, key, value string) error
+
+	// GetMeta returns an item's metadata, empty if it has none.
+	// Returns an error if the item does not exist.
+	GetMeta(id uint) (map[string]string, error)
+
+	// DeleteMeta removes one of an item's metadata keys.
+	// Returns an error if the item or the key does not exist.
+	DeleteMeta(id uint, key string) error
+
+	// SetSections replaces an item's sections, which are checked with
+	// ValidateSections against its size. An empty slice removes them.
+	// Returns an error if the item does not exist.
+	SetSections(id uint, sections []Section) error
+
+	// GetSections returns an item's sections in content order, empty if it
+	// has none. Returns an error if the item does not exist.
+	GetSections(id uint) ([]Section, error)
+
+	// Generation returns a counter that every change made to the history
+	// increases, so a caller holding indexes can tell whether the queue has
+	// changed since it read them. It persists with the history.
+	Generation() (uint64, error)
 
 	// Search finds items matching the query pattern.
 	// Returns matching items with optional match snippets.
 	Search(query *SearchQuery) ([]*HistoryItem, error)
 
+	// Iterate calls fn with each item in timestamp order (newest first
+	// unless opts asks otherwise), loading opts.BatchSize items at a time.
+	// Iteration ends when fn returns stop or an error, which is returned.
+	// Items created or deleted during iteration never cause an item to be
+	// skipped or visited twice; fn may modify the store.
+	Iterate(opts IterOptions, fn func(*HistoryItem) (stop bool, err error)) error
+
 	// Close releases any resources (DB connections, file handles, etc.).
 	Close() error
 }
 
+// OrderedLister is implemented by history stores that can list items in an
+// OrderSpec themselves, as with an index, rather than leaving callers to
+// load and sort every item.
+type OrderedLister interface {
+	// ListOrdered returns items in order, as SortItems would sort them,
+	// each with its position in List's order, so callers can tell queue
+	// indexes without listing the items again.
+	// If limit is 0, all items are returned. If limit > 0, at most limit
+	// items are returned.
+	ListOrdered(order OrderSpec, limit int) ([]OrderedItem, error)
+}
+
+// OrderedItem is an item listed by ListOrdered
+type OrderedItem struct {
+	Item *HistoryItem
+
+	// Position is the item's index in List's newest-first order
+	Position int
+}
+
+// Auditor is implemented by stores that record every item Delete,
+// DeleteOldest and Clear remove in an audit log, keeping entries for
+// audit_keep_days (see AuditKeepDays). Recording is best-effort: a
+// removal succeeds even when its entry can't be written.
+type Auditor interface {
+	// SetAuditSource sets what later entries say removed their items, such
+	// as a summary of the command line or "tui".
+	SetAuditSource(source string)
+
+	// AuditLog returns entries newest first.
+	// If limit is 0, all entries are returned. If limit > 0, at most limit
+	// entries are returned.
+	AuditLog(limit int) ([]*AuditEntry, error)
+}
+
 // ConfigStore manages configuration persistence.
 // Configuration is stored as key-value pairs.
 type ConfigStore interface {
@@ -60,6 +190,10 @@ type ConfigStore interface {
 	// Returns an error if the key does not exist.
 	Get(key string) (string, error)
 
+	// GetWithMeta retrieves a configuration value and when it was last set.
+	// Returns an error if the key does not exist.
+	GetWithMeta(key string) (value string, updatedAt time.Time, err error)
+
 	// Set stores a configuration value.
 	// If the key already exists, its value is updated.
 	Set(key, value string) error
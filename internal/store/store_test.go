@@ -148,6 +148,10 @@ func (m *mockHistoryStore) List(limit int) ([]*HistoryItem, error) {
 	return nil, nil
 }
 
+func (m *mockHistoryStore) ListAfterID(id uint) ([]*HistoryItem, error) {
+	return nil, nil
+}
+
 func (m *mockHistoryStore) Get(id uint) (*HistoryItem, error) {
 	return nil, nil
 }
@@ -156,10 +160,34 @@ func (m *mockHistoryStore) GetContent(id uint) (io.ReadSeekCloser, error) {
 	return nil, nil
 }
 
+func (m *mockHistoryStore) GetContentPrefix(id uint, n int) ([]byte, error) {
+	return nil, nil
+}
+
 func (m *mockHistoryStore) Delete(id uint) error {
 	return nil
 }
 
+func (m *mockHistoryStore) UpdateTitle(id uint, title string) error {
+	return nil
+}
+
+func (m *mockHistoryStore) UpdateNote(id uint, note string) error {
+	return nil
+}
+
+func (m *mockHistoryStore) UpdateTimestamp(id uint, timestamp time.Time) error {
+	return nil
+}
+
+func (m *mockHistoryStore) ReplaceContent(id uint, content io.Reader) error {
+	return nil
+}
+
+func (m *mockHistoryStore) UpdateMetadata(id uint, title, note string, timestamp time.Time) error {
+	return nil
+}
+
 func (m *mockHistoryStore) DeleteOldest(count int) error {
 	return nil
 }
@@ -168,14 +196,46 @@ func (m *mockHistoryStore) Count() (int, error) {
 	return 0, nil
 }
 
-func (m *mockHistoryStore) Clear() error {
+func (m *mockHistoryStore) Clear(opts ClearOptions) error {
 	return nil
 }
 
+func (m *mockHistoryStore) FindBySHA256(hash string) ([]*HistoryItem, error) {
+	return nil, nil
+}
+
+func (m *mockHistoryStore) SetMeta(id uint, key, value string) error {
+	return nil
+}
+
+func (m *mockHistoryStore) GetMeta(id uint) (map[string]string, error) {
+	return nil, nil
+}
+
+func (m *mockHistoryStore) DeleteMeta(id uint, key string) error {
+	return nil
+}
+
+func (m *mockHistoryStore) SetSections(id uint, sections []Section) error {
+	return nil
+}
+
+func (m *mockHistoryStore) GetSections(id uint) ([]Section, error) {
+	return nil, nil
+}
+
+func (m *mockHistoryStore) Generation() (uint64, error) {
+	return 0, nil
+}
+
 func (m *mockHistoryStore) Search(query *SearchQuery) ([]*HistoryItem, error) {
 	return nil, nil
 }
 
+func (m *mockHistoryStore) Iterate(opts IterOptions, fn func(*HistoryItem) (bool, error)) error {
+	return nil
+}
+
 func (m *mockHistoryStore) Close() error {
 	return nil
 }
@@ -186,6 +246,10 @@ func (m *mockConfigStore) Get(key string) (string, error) {
 	return "", nil
 }
 
+func (m *mockConfigStore) GetWithMeta(key string) (string, time.Time, error) {
+	return "", time.Time{}, nil
+}
+
 func (m *mockConfigStore) Set(key, value string) error {
 	return nil
 }
@@ -0,0 +1,1449 @@
+// Package storetest provides a conformance suite for store.Store implementations.
+// Each backend runs the same tests from its own test file so that behavior such
+// as result ordering stays identical across backends:
+//
+//	func TestConformance(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) store.Store { return NewMemoryStore() })
+//	}
+package storetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yiblet/rem/internal/store"
+)
+
+// Factory creates a new, empty store for a single test.
+// The suite closes the store when the test finishes.
+type Factory func(t *testing.T) store.Store
+
+// Run runs every conformance test against stores created by newStore.
+func Run(t *testing.T, newStore Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s store.Store)
+	}{
+		{"ListNewestFirst", testListNewestFirst},
+		{"SearchNewestFirst", testSearchNewestFirst},
+		{"SearchOldestFirst", testSearchOldestFirst},
+		{"SearchLimitAfterOrdering", testSearchLimitAfterOrdering},
+		{"SearchNoDuplicates", testSearchNoDuplicates},
+		{"UpdateTitle", testUpdateTitle},
+		{"UpdateNote", testUpdateNote},
+		{"UpdateTimestamp", testUpdateTimestamp},
+		{"UpdateMetadata", testUpdateMetadata},
+		{"ReplaceContent", testReplaceContent},
+		{"MutationsSetUpdatedAt", testMutationsSetUpdatedAt},
+		{"FindBySHA256", testFindBySHA256},
+		{"EmptyContent", testEmptyContent},
+		{"ContentPrefix", testContentPrefix},
+		{"ReadAfterDelete", testReadAfterDelete},
+		{"ConfigGetWithMeta", testConfigGetWithMeta},
+		{"ClearKeepsIDs", testClearKeepsIDs},
+		{"ClearResetIDs", testClearResetIDs},
+		{"AuditLog", testAuditLog},
+		{"AuditDisabled", testAuditDisabled},
+		{"TimestampTieOrder", testTimestampTieOrder},
+		{"TimestampPrecision", testTimestampPrecision},
+		{"SearchCountMatches", testSearchCountMatches},
+		{"SearchTimeWindow", testSearchTimeWindow},
+		{"SearchNormalize", testSearchNormalize},
+		{"SearchMatchDetails", testSearchMatchDetails},
+		{"Meta", testMeta},
+		{"SearchMetaEquals", testSearchMetaEquals},
+		{"Sections", testSections},
+		{"Generation", testGeneration},
+		{"Iterate", testIterate},
+		{"IterateWhileModifying", testIterateWhileModifying},
+		{"ListAfterID", testListAfterID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+			tt.fn(t, s)
+		})
+	}
+}
+
+// seedItem describes an item created by seed.
+type seedItem struct {
+	title   string
+	content string
+}
+
+// seedItems is the shared fixture: items are created oldest first, one
+// minute apart, so every backend sees identical timestamps.
+var seedItems = []seedItem{
+	{"alpha note", "first match here"},
+	{"beta", "nothing to see"},
+	{"gamma note", "second match here"},
+	{"delta", "match in content only"},
+	{"epsilon", "unrelated"},
+}
+
+// seedBase is the timestamp of the oldest seeded item.
+var seedBase = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// seed creates seedItems in s and returns their titles, oldest first.
+func seed(t *testing.T, s store.Store) []string {
+	t.Helper()
+
+	titles := make([]string, 0, len(seedItems))
+	for i, item := range seedItems {
+		_, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     item.title,
+			Content:   strings.NewReader(item.content),
+			Timestamp: seedBase.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("Create(%q) error = %v", item.title, err)
+		}
+		titles = append(titles, item.title)
+	}
+	return titles
+}
+
+// titlesOf returns the titles of items in order.
+func titlesOf(items []*store.HistoryItem) []string {
+	titles := make([]string, len(items))
+	for i, item := range items {
+		titles[i] = item.Title
+	}
+	return titles
+}
+
+// readContent returns the content of the item with the given ID.
+func readContent(t *testing.T, s store.Store, id uint) string {
+	t.Helper()
+
+	r, err := s.History().GetContent(id)
+	if err != nil {
+		t.Fatalf("GetContent(%d) error = %v", id, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading item %d error = %v", id, err)
+	}
+	return string(data)
+}
+
+// assertTitles fails the test if got does not match want exactly.
+func assertTitles(t *testing.T, got []*store.HistoryItem, want ...string) {
+	t.Helper()
+
+	gotTitles := titlesOf(got)
+	if strings.Join(gotTitles, ",") != strings.Join(want, ",") {
+		t.Errorf("got titles %q, want %q", gotTitles, want)
+	}
+}
+
+func testListNewestFirst(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "epsilon", "delta", "gamma note", "beta", "alpha note")
+
+	// Content must still be readable for listed items
+	reader, err := s.History().GetContent(items[0].ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(data) != "unrelated" {
+		t.Errorf("GetContent() = %q, want %q", data, "unrelated")
+	}
+}
+
+func testSearchNewestFirst(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	for _, order := range []store.SearchOrder{"", store.OrderNewest, store.OrderRelevance} {
+		results, err := s.History().Search(&store.SearchQuery{Pattern: "match", OrderBy: order})
+		if err != nil {
+			t.Fatalf("Search(order=%q) error = %v", order, err)
+		}
+		assertTitles(t, results, "delta", "gamma note", "alpha note")
+	}
+}
+
+func testSearchOldestFirst(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "match", OrderBy: store.OrderOldest})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "alpha note", "gamma note", "delta")
+}
+
+func testSearchLimitAfterOrdering(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	// A limited search must return the first matches in result order,
+	// not whichever matches the backend happened to visit first
+	for i := 0; i < 10; i++ {
+		results, err := s.History().Search(&store.SearchQuery{Pattern: "note", Limit: 1})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		assertTitles(t, results, "gamma note")
+	}
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "match", Limit: 2, OrderBy: store.OrderOldest})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "alpha note", "gamma note")
+}
+
+func testSearchNoDuplicates(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	// "note" matches both title and content of these items; each appears once
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "note to self",
+		Content:   strings.NewReader("note note note"),
+		Timestamp: seedBase.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "note"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "note to self", "gamma note", "alpha note")
+}
+
+func testUpdateTitle(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	target := items[2]
+
+	if err := s.History().UpdateTitle(target.ID, "renamed"); err != nil {
+		t.Fatalf("UpdateTitle() error = %v", err)
+	}
+
+	got, err := s.History().Get(target.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "renamed" {
+		t.Errorf("Get().Title = %q, want %q", got.Title, "renamed")
+	}
+	if !got.Timestamp.Equal(target.Timestamp) {
+		t.Errorf("UpdateTitle changed timestamp from %v to %v", target.Timestamp, got.Timestamp)
+	}
+
+	// Renaming keeps the item's position in the queue
+	items, err = s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "epsilon", "delta", "renamed", "beta", "alpha note")
+
+	if err := s.History().UpdateTitle(9999, "missing"); err == nil {
+		t.Error("UpdateTitle() on a missing item should fail")
+	}
+}
+
+func testUpdateNote(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	target := items[1]
+
+	if err := s.History().UpdateNote(target.ID, "zebra crossing"); err != nil {
+		t.Fatalf("UpdateNote() error = %v", err)
+	}
+	got, err := s.History().Get(target.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Note != "zebra crossing" || got.Title != target.Title {
+		t.Errorf("Get() = %q with note %q, want %q with note %q", got.Title, got.Note, target.Title, "zebra crossing")
+	}
+	if items, _ := s.History().List(0); items[1].Note != "zebra crossing" {
+		t.Errorf("List() note = %q, want %q", items[1].Note, "zebra crossing")
+	}
+
+	// Notes are searched by default and with SearchNotes, but not when only
+	// titles or content are searched
+	for _, tt := range []struct {
+		query *store.SearchQuery
+		want  []string
+	}{
+		{&store.SearchQuery{Pattern: "zebra"}, []string{target.Title}},
+		{&store.SearchQuery{Pattern: "zebra", SearchNotes: true}, []string{target.Title}},
+		{&store.SearchQuery{Pattern: "zebra", SearchTitle: true, SearchContent: true}, nil},
+	} {
+		results, err := s.History().Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		assertTitles(t, results, tt.want...)
+	}
+
+	// An empty note clears it
+	if err := s.History().UpdateNote(target.ID, ""); err != nil {
+		t.Fatalf("UpdateNote() error = %v", err)
+	}
+	if got, _ := s.History().Get(target.ID); got.Note != "" {
+		t.Errorf("expected the note cleared, got %q", got.Note)
+	}
+
+	if err := s.History().UpdateNote(9999, "missing"); err == nil {
+		t.Error("UpdateNote() on a missing item should fail")
+	}
+}
+
+func testMeta(t *testing.T, s store.Store) {
+	seed(t, s)
+	items, _ := s.History().List(0)
+	target := items[2]
+
+	if meta, err := s.History().GetMeta(target.ID); err != nil || len(meta) != 0 {
+		t.Fatalf("GetMeta() = %v, %v; want empty metadata", meta, err)
+	}
+	for key, value := range map[string]string{"ticket": "OPS-12", "source.url": "https://example.com/a", "lang": "go"} {
+		if err := s.History().SetMeta(target.ID, key, value); err != nil {
+			t.Fatalf("SetMeta(%q) error = %v", key, err)
+		}
+	}
+	if err := s.History().SetMeta(target.ID, "lang", "rust"); err != nil {
+		t.Fatalf("SetMeta() replacing error = %v", err)
+	}
+	meta, err := s.History().GetMeta(target.ID)
+	if err != nil {
+		t.Fatalf("GetMeta() error = %v", err)
+	}
+	if fmt.Sprint(meta) != "map[lang:rust source.url:https://example.com/a ticket:OPS-12]" {
+		t.Errorf("GetMeta() = %v", meta)
+	}
+	// Other items and the returned map are separate
+	meta["lang"] = "changed"
+	if got, _ := s.History().GetMeta(target.ID); got["lang"] != "rust" {
+		t.Errorf("modifying the returned map changed the stored value to %q", got["lang"])
+	}
+	if other, _ := s.History().GetMeta(items[0].ID); len(other) != 0 {
+		t.Errorf("expected other items to have no metadata, got %v", other)
+	}
+
+	if err := s.History().DeleteMeta(target.ID, "ticket"); err != nil {
+		t.Fatalf("DeleteMeta() error = %v", err)
+	}
+	if got, _ := s.History().GetMeta(target.ID); len(got) != 2 || got["ticket"] != "" {
+		t.Errorf("expected ticket deleted, got %v", got)
+	}
+	if err := s.History().DeleteMeta(target.ID, "ticket"); err == nil {
+		t.Error("DeleteMeta() of a missing key should fail")
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"Upper", "x"}, {"has space", "x"}, {"", "x"}, {strings.Repeat("k", 65), "x"}, {"big", strings.Repeat("v", store.MaxMetaValueBytes+1)},
+	} {
+		if err := s.History().SetMeta(target.ID, tt.key, tt.value); err == nil {
+			t.Errorf("SetMeta(%q, %d bytes) should fail", tt.key, len(tt.value))
+		}
+	}
+	if err := s.History().SetMeta(target.ID, strings.Repeat("k", 64), strings.Repeat("v", store.MaxMetaValueBytes)); err != nil {
+		t.Errorf("SetMeta() at the limits error = %v", err)
+	}
+
+	if err := s.History().SetMeta(9999, "lang", "go"); err == nil {
+		t.Error("SetMeta() on a missing item should fail")
+	}
+	if _, err := s.History().GetMeta(9999); err == nil {
+		t.Error("GetMeta() on a missing item should fail")
+	}
+	if err := s.History().DeleteMeta(9999, "lang"); err == nil {
+		t.Error("DeleteMeta() on a missing item should fail")
+	}
+
+	// Deleting the item deletes its metadata
+	if err := s.History().Delete(target.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.History().GetMeta(target.ID); err == nil {
+		t.Error("GetMeta() on a deleted item should fail")
+	}
+}
+
+func testGeneration(t *testing.T, s store.Store) {
+	h := s.History()
+	last, err := h.Generation()
+	if err != nil {
+		t.Fatalf("Generation() error = %v", err)
+	}
+	// changed checks that the generation went up since the last check
+	changed := func(what string) {
+		t.Helper()
+		generation, err := h.Generation()
+		if err != nil {
+			t.Fatalf("Generation() error = %v", err)
+		}
+		if generation <= last {
+			t.Errorf("%s left the generation at %d", what, generation)
+		}
+		last = generation
+	}
+
+	item, _ := h.Create(&store.CreateHistoryInput{Title: "a", Content: strings.NewReader("alpha"), Timestamp: time.Now()})
+	changed("Create")
+	h.Create(&store.CreateHistoryInput{Title: "b", Content: strings.NewReader("beta"), Timestamp: time.Now()})
+	changed("Create")
+	h.UpdateTitle(item.ID, "renamed")
+	changed("UpdateTitle")
+	h.UpdateNote(item.ID, "note")
+	changed("UpdateNote")
+	h.UpdateTimestamp(item.ID, time.Now())
+	changed("UpdateTimestamp")
+	h.UpdateMetadata(item.ID, "t", "n", time.Now())
+	changed("UpdateMetadata")
+	h.ReplaceContent(item.ID, strings.NewReader("gamma"))
+	changed("ReplaceContent")
+	h.SetMeta(item.ID, "k", "v")
+	changed("SetMeta")
+	h.DeleteMeta(item.ID, "k")
+	changed("DeleteMeta")
+	h.SetSections(item.ID, []store.Section{{Name: "all", Start: 0, End: 5}})
+	changed("SetSections")
+	h.DeleteOldest(1)
+	changed("DeleteOldest")
+	h.Delete(item.ID)
+	changed("Delete")
+	h.Clear(store.ClearOptions{})
+	changed("Clear")
+
+	// Reads and failed changes leave it alone
+	h.List(0)
+	h.Count()
+	if err := h.Delete(9999); err == nil {
+		t.Error("Delete() of a missing item should fail")
+	}
+	h.UpdateTitle(9999, "x")
+	if generation, _ := h.Generation(); generation != last {
+		t.Errorf("reads and failed changes moved the generation from %d to %d", last, generation)
+	}
+}
+
+func testSections(t *testing.T, s store.Store) {
+	item, err := s.History().Create(&store.CreateHistoryInput{Title: "configs", Content: strings.NewReader("# a\nx=1\n# b\ny=2\n"), Timestamp: time.Now()})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if sections, err := s.History().GetSections(item.ID); err != nil || len(sections) != 0 {
+		t.Fatalf("GetSections() = %v, %v; want no sections", sections, err)
+	}
+
+	want := []store.Section{{Name: "a", Start: 0, End: 8}, {Name: "b", Start: 8, End: 16}}
+	if err := s.History().SetSections(item.ID, want); err != nil {
+		t.Fatalf("SetSections() error = %v", err)
+	}
+	got, err := s.History().GetSections(item.ID)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSections() = %v, %v; want %v", got, err, want)
+	}
+
+	for _, bad := range [][]store.Section{
+		{{Name: "past the end", Start: 8, End: 17}},
+		{{Name: "backwards", Start: 5, End: 2}},
+		{{Name: "b", Start: 8, End: 16}, {Name: "a", Start: 0, End: 8}},
+		{{Name: "a", Start: 0, End: 10}, {Name: "b", Start: 8, End: 16}},
+	} {
+		if err := s.History().SetSections(item.ID, bad); err == nil {
+			t.Errorf("SetSections(%v) should fail", bad)
+		}
+	}
+	if got, _ := s.History().GetSections(item.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("a rejected SetSections() changed the sections to %v", got)
+	}
+	if err := s.History().SetSections(9999, want); err == nil {
+		t.Error("SetSections() on a missing item should fail")
+	}
+
+	// New content invalidates the offsets
+	if err := s.History().ReplaceContent(item.ID, strings.NewReader("other")); err != nil {
+		t.Fatalf("ReplaceContent() error = %v", err)
+	}
+	if got, _ := s.History().GetSections(item.ID); len(got) != 0 {
+		t.Errorf("expected ReplaceContent() to drop the sections, got %v", got)
+	}
+
+	s.History().SetSections(item.ID, []store.Section{{Name: "all", Start: 0, End: 5}})
+	if err := s.History().SetSections(item.ID, nil); err != nil {
+		t.Fatalf("SetSections(nil) error = %v", err)
+	}
+	if got, _ := s.History().GetSections(item.ID); len(got) != 0 {
+		t.Errorf("expected SetSections(nil) to remove the sections, got %v", got)
+	}
+
+	if err := s.History().Delete(item.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.History().GetSections(item.ID); err == nil {
+		t.Error("GetSections() on a deleted item should fail")
+	}
+}
+
+func testSearchMetaEquals(t *testing.T, s store.Store) {
+	seed(t, s)
+	items, _ := s.History().List(0) // epsilon, delta, gamma note, beta, alpha note
+	s.History().SetMeta(items[0].ID, "lang", "go")
+	s.History().SetMeta(items[2].ID, "lang", "go")
+	s.History().SetMeta(items[2].ID, "ticket", "OPS-1")
+	s.History().SetMeta(items[4].ID, "lang", "rust")
+
+	for _, tt := range []struct {
+		query *store.SearchQuery
+		want  []string
+	}{
+		{&store.SearchQuery{MetaEquals: map[string]string{"lang": "go"}}, []string{"epsilon", "gamma note"}},
+		{&store.SearchQuery{MetaEquals: map[string]string{"lang": "go", "ticket": "OPS-1"}}, []string{"gamma note"}},
+		{&store.SearchQuery{Pattern: "match", MetaEquals: map[string]string{"lang": "go"}}, []string{"gamma note"}},
+		{&store.SearchQuery{Pattern: "match", MetaEquals: map[string]string{"lang": "rust"}, OrderBy: store.OrderOldest}, []string{"alpha note"}},
+		{&store.SearchQuery{MetaEquals: map[string]string{"lang": "Go"}}, nil},
+		{&store.SearchQuery{MetaEquals: map[string]string{"missing": ""}}, nil},
+	} {
+		results, err := s.History().Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", tt.query, err)
+		}
+		assertTitles(t, results, tt.want...)
+	}
+}
+
+func testUpdateTimestamp(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	target := items[3]
+
+	bumped := seedBase.Add(time.Hour)
+	if err := s.History().UpdateTimestamp(target.ID, bumped); err != nil {
+		t.Fatalf("UpdateTimestamp() error = %v", err)
+	}
+	got, err := s.History().Get(target.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.Timestamp.Equal(bumped) {
+		t.Errorf("Get().Timestamp = %v, want %v", got.Timestamp, bumped)
+	}
+	if got.SHA256 != target.SHA256 || got.Size != target.Size || !got.CreatedAt.Equal(target.CreatedAt) {
+		t.Errorf("UpdateTimestamp changed more than the timestamp: %+v, was %+v", got, target)
+	}
+
+	// The item is now the newest, and everything else keeps its order
+	items, err = s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "beta", "epsilon", "delta", "gamma note", "alpha note")
+
+	if err := s.History().UpdateTimestamp(9999, bumped); err == nil {
+		t.Error("UpdateTimestamp() on a missing item should fail")
+	}
+}
+
+func testUpdateMetadata(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	target := items[3]
+	if err := s.History().UpdateNote(target.ID, "old note"); err != nil {
+		t.Fatalf("UpdateNote() error = %v", err)
+	}
+
+	moved := seedBase.Add(time.Hour)
+	if err := s.History().UpdateMetadata(target.ID, "renamed", "", moved); err != nil {
+		t.Fatalf("UpdateMetadata() error = %v", err)
+	}
+	got, err := s.History().Get(target.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "renamed" || got.Note != "" || !got.Timestamp.Equal(moved) {
+		t.Errorf("Get() = %q, note %q, %v; want renamed, no note, %v", got.Title, got.Note, got.Timestamp, moved)
+	}
+	if got.SHA256 != target.SHA256 || got.Size != target.Size || !got.CreatedAt.Equal(target.CreatedAt) {
+		t.Errorf("UpdateMetadata changed more than the metadata: %+v, was %+v", got, target)
+	}
+
+	items, err = s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "renamed", "epsilon", "delta", "gamma note", "alpha note")
+
+	if err := s.History().UpdateMetadata(9999, "missing", "", moved); err == nil {
+		t.Error("UpdateMetadata() on a missing item should fail")
+	}
+}
+
+func testReplaceContent(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	target := items[2]
+
+	// Large enough to take several chunks in any backend that chunks
+	content := strings.Repeat("replaced line\n", 10000)
+	time.Sleep(5 * time.Millisecond)
+	if err := s.History().ReplaceContent(target.ID, strings.NewReader(content)); err != nil {
+		t.Fatalf("ReplaceContent() error = %v", err)
+	}
+	got, err := s.History().Get(target.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	hash := sha256.Sum256([]byte(content))
+	if got.Size != int64(len(content)) || got.SHA256 != hex.EncodeToString(hash[:]) || got.IsBinary {
+		t.Errorf("Get() = size %d, SHA256 %s, binary %v; want the new content's", got.Size, got.SHA256, got.IsBinary)
+	}
+	if got.Title != target.Title || !got.Timestamp.Equal(target.Timestamp) || !got.CreatedAt.Equal(target.CreatedAt) {
+		t.Errorf("ReplaceContent changed more than the content: %+v, was %+v", got, target)
+	}
+	if !got.UpdatedAt.After(target.UpdatedAt) {
+		t.Errorf("ReplaceContent left UpdatedAt at %v, was %v", got.UpdatedAt, target.UpdatedAt)
+	}
+	if read := readContent(t, s, target.ID); read != content {
+		t.Errorf("GetContent() read %d bytes, want %d", len(read), len(content))
+	}
+
+	// Shrinking leaves nothing of the longer content behind
+	if err := s.History().ReplaceContent(target.ID, strings.NewReader("short")); err != nil {
+		t.Fatalf("ReplaceContent() error = %v", err)
+	}
+	if read := readContent(t, s, target.ID); read != "short" {
+		t.Errorf("GetContent() = %q after shrinking, want %q", read, "short")
+	}
+	if matches, err := s.History().FindBySHA256(target.SHA256); err != nil || len(matches) != 0 {
+		t.Errorf("FindBySHA256(old hash) = %v, %v; want no items", matches, err)
+	}
+
+	items, err = s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "epsilon", "delta", "gamma note", "beta", "alpha note")
+
+	if err := s.History().ReplaceContent(9999, strings.NewReader("x")); err == nil {
+		t.Error("ReplaceContent() on a missing item should fail")
+	}
+}
+
+func testMutationsSetUpdatedAt(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	id := items[1].ID
+
+	mutations := []struct {
+		name string
+		fn   func() error
+	}{
+		{"UpdateTitle", func() error { return s.History().UpdateTitle(id, "renamed") }},
+		{"UpdateNote", func() error { return s.History().UpdateNote(id, "a note") }},
+		{"UpdateTimestamp", func() error { return s.History().UpdateTimestamp(id, seedBase.Add(time.Hour)) }},
+		{"UpdateMetadata", func() error { return s.History().UpdateMetadata(id, "retitled", "", seedBase.Add(2*time.Hour)) }},
+	}
+	before, err := s.History().Get(id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for _, m := range mutations {
+		// Far enough apart for every backend's clock to tell them apart
+		time.Sleep(5 * time.Millisecond)
+		if err := m.fn(); err != nil {
+			t.Fatalf("%s() error = %v", m.name, err)
+		}
+		got, err := s.History().Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if !got.UpdatedAt.After(before.UpdatedAt) {
+			t.Errorf("%s left UpdatedAt at %v, was %v", m.name, got.UpdatedAt, before.UpdatedAt)
+		}
+		if !got.CreatedAt.Equal(before.CreatedAt) || got.SHA256 != before.SHA256 {
+			t.Errorf("%s changed CreatedAt or SHA256: %+v, was %+v", m.name, got, before)
+		}
+		before = got
+	}
+}
+
+func testFindBySHA256(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	dup, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "beta again",
+		Content:   strings.NewReader("nothing to see"),
+		Timestamp: seedBase.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	found, err := s.History().FindBySHA256(dup.SHA256)
+	if err != nil {
+		t.Fatalf("FindBySHA256() error = %v", err)
+	}
+	assertTitles(t, found, "beta again", "beta")
+
+	found, err = s.History().FindBySHA256("0000")
+	if err != nil {
+		t.Fatalf("FindBySHA256() error = %v", err)
+	}
+	if found == nil || len(found) != 0 {
+		t.Errorf("FindBySHA256() for unknown hash = %v, want empty slice", found)
+	}
+}
+
+func testEmptyContent(t *testing.T, s store.Store) {
+	item, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "[empty]",
+		Content:   strings.NewReader(""),
+		Timestamp: seedBase,
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// SHA256 of zero bytes
+	const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	got, err := s.History().Get(item.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Size != 0 || got.IsBinary || got.SHA256 != emptySHA256 {
+		t.Errorf("Get() = size %d, binary %v, sha256 %q; want an empty text item", got.Size, got.IsBinary, got.SHA256)
+	}
+
+	reader, err := s.History().GetContent(item.ID)
+	if err != nil {
+		t.Fatalf("GetContent() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("GetContent() = %q, want no content", data)
+	}
+	if pos, err := reader.Seek(0, io.SeekEnd); err != nil || pos != 0 {
+		t.Errorf("Seek(0, SeekEnd) = %d, %v; want 0, nil", pos, err)
+	}
+}
+
+func testContentPrefix(t *testing.T, s store.Store) {
+	// Content spanning a few 32KB chunks, varied so an offset mistake shows
+	var builder strings.Builder
+	for i := 0; builder.Len() < 80*1024; i++ {
+		fmt.Fprintf(&builder, "line %d\n", i)
+	}
+	content := builder.String()
+	item, err := s.History().Create(&store.CreateHistoryInput{Title: "long", Content: strings.NewReader(content), Timestamp: seedBase})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, n := range []int{0, 1, 100, 32*1024 - 1, 32 * 1024, 32*1024 + 1, 64 * 1024, len(content), len(content) + 1, 1 << 30} {
+		got, err := s.History().GetContentPrefix(item.ID, n)
+		if err != nil {
+			t.Fatalf("GetContentPrefix(%d) error = %v", n, err)
+		}
+		if want := content[:min(n, len(content))]; string(got) != want {
+			t.Errorf("GetContentPrefix(%d) = %d bytes, want the first %d", n, len(got), len(want))
+		}
+	}
+
+	empty, err := s.History().Create(&store.CreateHistoryInput{Title: "[empty]", Content: strings.NewReader(""), Timestamp: seedBase})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got, err := s.History().GetContentPrefix(empty.ID, 10); err != nil || len(got) != 0 {
+		t.Errorf("GetContentPrefix() of an empty item = %q, %v", got, err)
+	}
+	if _, err := s.History().GetContentPrefix(999999, 10); err == nil {
+		t.Error("GetContentPrefix() of a missing item: expected an error")
+	}
+}
+
+func testReadAfterDelete(t *testing.T, s store.Store) {
+	// Content spanning a few 32KB chunks, so a read crosses into a chunk
+	// loaded after the delete
+	content := strings.Repeat("0123456789abcdef", 5*1024)
+
+	tests := []struct {
+		name   string
+		before int // bytes read before the delete
+	}{
+		{"start", 0},
+		{"mid-content", 100},
+		{"after full read", len(content)},
+	}
+	for _, tt := range tests {
+		item, err := s.History().Create(&store.CreateHistoryInput{Title: tt.name, Content: strings.NewReader(content), Timestamp: seedBase})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		reader, err := s.History().GetContent(item.ID)
+		if err != nil {
+			t.Fatalf("GetContent() error = %v", err)
+		}
+		if _, err := io.ReadFull(reader, make([]byte, tt.before)); err != nil {
+			t.Fatalf("%s: reading before the delete: %v", tt.name, err)
+		}
+		if err := s.History().Delete(item.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		rest, err := io.ReadAll(reader)
+		reader.Close()
+		if tt.before == len(content) {
+			// Nothing was left to read, so the reader simply ends
+			if err != nil || len(rest) != 0 {
+				t.Errorf("%s: ReadAll() = %d bytes, %v; want 0, nil", tt.name, len(rest), err)
+			}
+			continue
+		}
+		if !errors.Is(err, store.ErrItemDeleted) {
+			t.Errorf("%s: ReadAll() error = %v, want ErrItemDeleted", tt.name, err)
+		}
+		if got := content[tt.before : tt.before+len(rest)]; string(rest) != got || tt.before+len(rest) == len(content) {
+			t.Errorf("%s: read %d bytes after the delete, want a strict prefix of the rest", tt.name, len(rest))
+		}
+
+		// Reopening the deleted item reports the delete the same way
+		if _, err := s.History().GetContent(item.ID); !errors.Is(err, store.ErrItemDeleted) {
+			t.Errorf("%s: GetContent() after the delete error = %v, want ErrItemDeleted", tt.name, err)
+		}
+		if _, err := s.History().GetContentPrefix(item.ID, 10); !errors.Is(err, store.ErrItemDeleted) {
+			t.Errorf("%s: GetContentPrefix() after the delete error = %v, want ErrItemDeleted", tt.name, err)
+		}
+	}
+}
+
+// createIDs creates n items and returns their IDs.
+func createIDs(t *testing.T, s store.Store, n int) []uint {
+	t.Helper()
+
+	ids := make([]uint, n)
+	for i := range ids {
+		item, err := s.History().Create(&store.CreateHistoryInput{Title: "item", Content: strings.NewReader(fmt.Sprintf("content %d", i)), Timestamp: seedBase})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func testClearKeepsIDs(t *testing.T, s store.Store) {
+	// No ID comes back, however often the history is cleared and refilled
+	var all []uint
+	for range 3 {
+		all = append(all, createIDs(t, s, 3)...)
+		if err := s.History().Clear(store.ClearOptions{}); err != nil {
+			t.Fatalf("Clear() error = %v", err)
+		}
+	}
+	all = append(all, createIDs(t, s, 1)...)
+	for i := 1; i < len(all); i++ {
+		if all[i] <= all[i-1] {
+			t.Fatalf("IDs across clears = %v; want them increasing", all)
+		}
+	}
+}
+
+func testClearResetIDs(t *testing.T, s store.Store) {
+	first := createIDs(t, s, 3)
+	if err := s.History().Clear(store.ClearOptions{ResetIDs: true}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	again := createIDs(t, s, 3)
+	if !slices.Equal(again, first) {
+		t.Errorf("IDs after Clear with ResetIDs = %v, want %v again", again, first)
+	}
+
+	// Resetting an empty history is fine too
+	if err := s.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if err := s.History().Clear(store.ClearOptions{ResetIDs: true}); err != nil {
+		t.Fatalf("Clear() of an empty history error = %v", err)
+	}
+	if ids := createIDs(t, s, 1); ids[0] != first[0] {
+		t.Errorf("first ID after a reset = %d, want %d", ids[0], first[0])
+	}
+}
+
+// auditSummary describes audit entries as operation:title@source, with
+// "-" for an entry without an item
+func auditSummary(entries []*store.AuditEntry) string {
+	parts := make([]string, len(entries))
+	for i, entry := range entries {
+		title := entry.ItemTitle
+		if entry.ItemID == nil {
+			title = "-"
+		}
+		parts[i] = fmt.Sprintf("%s:%s@%s", entry.Operation, title, entry.Source)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func testAuditLog(t *testing.T, s store.Store) {
+	auditor, ok := s.(store.Auditor)
+	if !ok {
+		t.Skip("store keeps no audit log")
+	}
+	seed(t, s)
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	newest := items[0]
+
+	// A single delete, and one that fails, which records nothing
+	auditor.SetAuditSource("rem get")
+	if err := s.History().Delete(newest.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := s.History().Delete(newest.ID); err == nil {
+		t.Fatal("expected deleting a deleted item to fail")
+	}
+	entries, err := auditor.AuditLog(0)
+	if err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("AuditLog() = %s, want one delete", auditSummary(entries))
+	}
+	if entry := entries[0]; *entry.ItemID != newest.ID || entry.ItemSHA256 != newest.SHA256 || entry.Timestamp.IsZero() {
+		t.Errorf("delete entry = %+v, want item %d with SHA256 %s", entry, newest.ID, newest.SHA256)
+	}
+
+	// Bulk eviction, then clearing what's left and an empty history
+	auditor.SetAuditSource("rem store")
+	if err := s.History().DeleteOldest(2); err != nil {
+		t.Fatalf("DeleteOldest() error = %v", err)
+	}
+	auditor.SetAuditSource("tui")
+	for range 2 {
+		if err := s.History().Clear(store.ClearOptions{}); err != nil {
+			t.Fatalf("Clear() error = %v", err)
+		}
+	}
+
+	entries, err = auditor.AuditLog(0)
+	if err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	want := "clear:-@tui, clear:delta@tui, clear:gamma note@tui, evict:beta@rem store, evict:alpha note@rem store, delete:epsilon@rem get"
+	if got := auditSummary(entries); got != want {
+		t.Errorf("AuditLog() = %s\nwant %s", got, want)
+	}
+	if entries, err := auditor.AuditLog(2); err != nil || auditSummary(entries) != "clear:-@tui, clear:delta@tui" {
+		t.Errorf("AuditLog(2) = %s, %v; want the two newest entries", auditSummary(entries), err)
+	}
+}
+
+func testAuditDisabled(t *testing.T, s store.Store) {
+	auditor, ok := s.(store.Auditor)
+	if !ok {
+		t.Skip("store keeps no audit log")
+	}
+	if err := s.Config().Set(store.AuditKeepDaysKey, "0"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	seed(t, s)
+	items, err := s.History().List(1)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if err := s.History().Delete(items[0].ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := s.History().DeleteOldest(2); err != nil {
+		t.Fatalf("DeleteOldest() error = %v", err)
+	}
+	if err := s.History().Clear(store.ClearOptions{}); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if entries, err := auditor.AuditLog(0); err != nil || len(entries) != 0 {
+		t.Errorf("AuditLog() with audit_keep_days 0 = %s, %v; want nothing", auditSummary(entries), err)
+	}
+}
+
+func testConfigGetWithMeta(t *testing.T, s store.Store) {
+	before := time.Now().Add(-time.Second)
+	if err := s.Config().Set("meta_key", "first"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	value, first, err := s.Config().GetWithMeta("meta_key")
+	if err != nil || value != "first" || first.Before(before) {
+		t.Fatalf("GetWithMeta() = %q, %v, %v; want first, set after %v", value, first, err, before)
+	}
+
+	if err := s.Config().Set("meta_key", "second"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	value, second, err := s.Config().GetWithMeta("meta_key")
+	if err != nil || value != "second" || second.Before(first) {
+		t.Errorf("GetWithMeta() after an update = %q, %v, %v; want second, not before %v", value, second, err, first)
+	}
+
+	if _, _, err := s.Config().GetWithMeta("no_such_key"); err == nil {
+		t.Error("GetWithMeta() of a missing key: expected an error")
+	}
+}
+
+func testTimestampTieOrder(t *testing.T, s store.Store) {
+	// Items sharing a timestamp are ordered by insertion, newest first
+	for _, title := range []string{"first", "second", "third"} {
+		if _, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     title,
+			Content:   strings.NewReader("same time " + title),
+			Timestamp: seedBase,
+		}); err != nil {
+			t.Fatalf("Create(%q) error = %v", title, err)
+		}
+	}
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "third", "second", "first")
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "same time"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "third", "second", "first")
+
+	results, err = s.History().Search(&store.SearchQuery{Pattern: "same time", OrderBy: store.OrderOldest})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "first", "second", "third")
+
+	// The oldest item by insertion goes first
+	if err := s.History().DeleteOldest(1); err != nil {
+		t.Fatalf("DeleteOldest() error = %v", err)
+	}
+	items, err = s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "third", "second")
+}
+
+func testTimestampPrecision(t *testing.T, s store.Store) {
+	// Timestamps a nanosecond apart must round-trip and order correctly
+	base := seedBase.Add(123456789 * time.Nanosecond)
+	for i, title := range []string{"earlier", "later"} {
+		if _, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     title,
+			Content:   strings.NewReader(title),
+			Timestamp: base.Add(time.Duration(i) * time.Nanosecond),
+		}); err != nil {
+			t.Fatalf("Create(%q) error = %v", title, err)
+		}
+	}
+
+	items, err := s.History().List(0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	assertTitles(t, items, "later", "earlier")
+	if !items[1].Timestamp.Equal(base) {
+		t.Errorf("Timestamp = %v, want %v", items[1].Timestamp, base)
+	}
+}
+
+func testSearchCountMatches(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	// 100KB of content spans several storage chunks; the matching lines are
+	// spread across it, including one near the end
+	var big strings.Builder
+	for i := 0; i < 2000; i++ {
+		if i%250 == 0 {
+			big.WriteString("a match on this line, match again\n")
+		} else {
+			big.WriteString("filler filler filler filler filler filler\n")
+		}
+	}
+	big.WriteString("last match")
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "big",
+		Content:   strings.NewReader(big.String()),
+		Timestamp: seedBase.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "match in title",
+		Content:   strings.NewReader("nothing here"),
+		Timestamp: seedBase.Add(2 * time.Hour),
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "match", CountMatches: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "match in title", "big", "delta", "gamma note", "alpha note")
+
+	want := map[string]int{"match in title": 1, "big": 9, "delta": 1, "gamma note": 1, "alpha note": 1}
+	for _, item := range results {
+		if item.MatchCount != want[item.Title] {
+			t.Errorf("MatchCount for %q = %d, want %d", item.Title, item.MatchCount, want[item.Title])
+		}
+	}
+
+	// Counting must not leak into later results
+	results, err = s.History().Search(&store.SearchQuery{Pattern: "match"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	for _, item := range results {
+		if item.MatchCount != 0 {
+			t.Errorf("MatchCount for %q = %d without CountMatches, want 0", item.Title, item.MatchCount)
+		}
+	}
+}
+
+func testSearchTimeWindow(t *testing.T, s store.Store) {
+	seed(t, s)
+
+	search := func(query *store.SearchQuery) []*store.HistoryItem {
+		t.Helper()
+		results, err := s.History().Search(query)
+		if err != nil {
+			t.Fatalf("Search(%+v) error = %v", query, err)
+		}
+		return results
+	}
+
+	// Since is inclusive and Until exclusive
+	assertTitles(t, search(&store.SearchQuery{
+		Since: seedBase.Add(time.Minute),
+		Until: seedBase.Add(3 * time.Minute),
+	}), "gamma note", "beta")
+
+	// The window combines with the pattern
+	assertTitles(t, search(&store.SearchQuery{
+		Pattern: "note",
+		Since:   seedBase.Add(time.Minute),
+	}), "gamma note")
+
+	// Bounds in another time zone select the same instants
+	east := time.FixedZone("UTC+5", 5*60*60)
+	assertTitles(t, search(&store.SearchQuery{
+		Until: seedBase.Add(2 * time.Minute).In(east),
+	}), "beta", "alpha note")
+
+	// Boundaries are exact to the nanosecond
+	precise := seedBase.Add(10*time.Minute + 123456789*time.Nanosecond)
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "precise",
+		Content:   strings.NewReader("precise"),
+		Timestamp: precise,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	assertTitles(t, search(&store.SearchQuery{Since: precise}), "precise")
+	assertTitles(t, search(&store.SearchQuery{Since: precise.Add(time.Nanosecond)}))
+	assertTitles(t, search(&store.SearchQuery{Since: seedBase.Add(4 * time.Minute), Until: precise}), "epsilon")
+
+	// Without a window an empty pattern still matches nothing
+	assertTitles(t, search(&store.SearchQuery{}))
+}
+
+// testSearchMatchDetails checks the fields and snippets MatchDetails fills
+// in for each result.
+func testSearchMatchDetails(t *testing.T, s store.Store) {
+	long := strings.Repeat("x", 200) + "foo" + strings.Repeat("y", 200)
+	for i, item := range []seedItem{
+		{"foo in title", "nothing here"},
+		{"content", "line one\n  the foo is here  \nFOO again\nmore foo\nfoo four"},
+		{"both foo", "has foo"},
+		{"long", long},
+		{"accents", "Ein Café, bitte"},
+	} {
+		created, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     item.title,
+			Content:   strings.NewReader(item.content),
+			Timestamp: seedBase.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("Create(%q) error = %v", item.title, err)
+		}
+		if item.title == "both foo" {
+			if err := s.History().UpdateNote(created.ID, "a foo note"); err != nil {
+				t.Fatalf("UpdateNote() error = %v", err)
+			}
+		}
+	}
+
+	results, err := s.History().Search(&store.SearchQuery{Pattern: "foo", MatchDetails: true, OrderBy: store.OrderOldest})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	assertTitles(t, results, "foo in title", "content", "both foo", "long")
+	want := []struct {
+		matchedIn []string
+		snippets  []string
+	}{
+		{[]string{"title"}, nil},
+		{[]string{"content"}, []string{"the «foo» is here", "«FOO» again", "more «foo»"}},
+		{[]string{"title", "content", "note"}, []string{"has «foo»"}},
+		{[]string{"content"}, []string{"…" + strings.Repeat("x", 38) + "«foo»" + strings.Repeat("y", 77) + "…"}},
+	}
+	for i, item := range results {
+		if !slices.Equal(item.MatchedIn, want[i].matchedIn) || !slices.Equal(item.Snippets, want[i].snippets) {
+			t.Errorf("%q matched in %q with snippets %q, want %q and %q",
+				item.Title, item.MatchedIn, item.Snippets, want[i].matchedIn, want[i].snippets)
+		}
+	}
+
+	// Snippets show content as stored, even when matched accent-insensitively
+	results, err = s.History().Search(&store.SearchQuery{Pattern: "cafe", Normalize: true, MatchDetails: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || !slices.Equal(results[0].Snippets, []string{"Ein Café, bitte"}) {
+		t.Errorf("accent-insensitive Search() = %+v, want the line as stored", results)
+	}
+
+	// Without MatchDetails, no details are filled in
+	results, err = s.History().Search(&store.SearchQuery{Pattern: "foo"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	for _, item := range results {
+		if item.MatchedIn != nil || item.Snippets != nil {
+			t.Errorf("%q has match details without MatchDetails", item.Title)
+		}
+	}
+}
+
+// testSearchNormalize checks accent-insensitive matching of titles and of
+// content with a multi-byte character split across a chunk boundary.
+func testSearchNormalize(t *testing.T, s store.Store) {
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "Über receipt",
+		Content:   strings.NewReader("total"),
+		Timestamp: seedBase,
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// Ï straddles the 32KB boundary used by chunked stores
+	if _, err := s.History().Create(&store.CreateHistoryInput{
+		Title:     "big",
+		Content:   strings.NewReader(strings.Repeat("x", 32*1024-1) + "Ïberzone"),
+		Timestamp: seedBase.Add(time.Minute),
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	search := func(query *store.SearchQuery) []*store.HistoryItem {
+		t.Helper()
+		results, err := s.History().Search(query)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		return results
+	}
+	assertTitles(t, search(&store.SearchQuery{Pattern: "uber", Normalize: true}), "Über receipt")
+	assertTitles(t, search(&store.SearchQuery{Pattern: "uber"}))
+	assertTitles(t, search(&store.SearchQuery{Pattern: "xiberzone", Normalize: true}), "big")
+}
+
+// iterate collects the items Iterate visits.
+func iterate(t *testing.T, s store.Store, opts store.IterOptions) []*store.HistoryItem {
+	t.Helper()
+
+	var items []*store.HistoryItem
+	if err := s.History().Iterate(opts, func(item *store.HistoryItem) (bool, error) {
+		items = append(items, item)
+		return false, nil
+	}); err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	return items
+}
+
+func testIterate(t *testing.T, s store.Store) {
+	seed(t, s)
+	// Two more sharing the newest timestamp, to page through a tie
+	for _, title := range []string{"zeta", "eta"} {
+		if _, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     title,
+			Content:   strings.NewReader(title),
+			Timestamp: seedBase.Add(time.Hour),
+		}); err != nil {
+			t.Fatalf("Create(%q) error = %v", title, err)
+		}
+	}
+	newest := []string{"eta", "zeta", "epsilon", "delta", "gamma note", "beta", "alpha note"}
+	oldest := slices.Clone(newest)
+	slices.Reverse(oldest)
+
+	for _, batch := range []int{0, 1, 2, 7, 100} {
+		assertTitles(t, iterate(t, s, store.IterOptions{BatchSize: batch}), newest...)
+		assertTitles(t, iterate(t, s, store.IterOptions{BatchSize: batch, Order: store.OrderOldest}), oldest...)
+	}
+
+	// After resumes past an item, including one within a timestamp tie
+	all := iterate(t, s, store.IterOptions{})
+	for _, i := range []int{0, 1, 3} {
+		after := &store.IterCursor{Timestamp: all[i].Timestamp, ID: all[i].ID}
+		assertTitles(t, iterate(t, s, store.IterOptions{BatchSize: 2, After: after}), newest[i+1:]...)
+	}
+	last := all[len(all)-1]
+	if rest := iterate(t, s, store.IterOptions{After: &store.IterCursor{Timestamp: last.Timestamp, ID: last.ID}}); len(rest) != 0 {
+		t.Errorf("Iterate() after the oldest item = %d items, want none", len(rest))
+	}
+
+	// Stopping early, and errors, end the iteration
+	visited := 0
+	err := s.History().Iterate(store.IterOptions{BatchSize: 2}, func(item *store.HistoryItem) (bool, error) {
+		visited++
+		return visited == 3, nil
+	})
+	if err != nil || visited != 3 {
+		t.Errorf("Iterate() stopped after %d items with %v, want 3 and nil", visited, err)
+	}
+	wantErr := errors.New("boom")
+	visited = 0
+	err = s.History().Iterate(store.IterOptions{BatchSize: 2}, func(item *store.HistoryItem) (bool, error) {
+		visited++
+		return false, wantErr
+	})
+	if err != wantErr || visited != 1 {
+		t.Errorf("Iterate() = %v after %d items, want %v after 1", err, visited, wantErr)
+	}
+}
+
+func testIterateWhileModifying(t *testing.T, s store.Store) {
+	// Timestamps from the local clock, as rem stores them
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 10; i++ {
+		if _, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     fmt.Sprintf("item %d", i),
+			Content:   strings.NewReader(fmt.Sprintf("content %d", i)),
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// Newest first, each visit inserts an item newer than every other one,
+	// which is never visited, and deletes the item just visited
+	seen := map[string]int{}
+	inserted := 0
+	err := s.History().Iterate(store.IterOptions{BatchSize: 3}, func(item *store.HistoryItem) (bool, error) {
+		seen[item.Title]++
+		inserted++
+		if _, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     fmt.Sprintf("new %d", inserted),
+			Content:   strings.NewReader("new"),
+			Timestamp: time.Now(),
+		}); err != nil {
+			return false, err
+		}
+		return false, s.History().Delete(item.ID)
+	})
+	if err != nil {
+		t.Fatalf("Iterate() error = %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if title := fmt.Sprintf("item %d", i); seen[title] != 1 {
+			t.Errorf("%q visited %d times, want once", title, seen[title])
+		}
+	}
+	if len(seen) != 10 {
+		t.Errorf("visited %d items, want only the 10 that existed: %v", len(seen), seen)
+	}
+	if count, _ := s.History().Count(); count != 10 {
+		t.Errorf("Count() = %d, want the 10 inserted items", count)
+	}
+}
+
+// testListAfterID checks that items come back in creation order whatever
+// their timestamps, starting after the given ID.
+func testListAfterID(t *testing.T, s store.Store) {
+	var ids []uint
+	for i, title := range []string{"first", "second", "third"} {
+		item, err := s.History().Create(&store.CreateHistoryInput{
+			Title:     title,
+			Content:   strings.NewReader(title),
+			Timestamp: seedBase.Add(-time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids = append(ids, item.ID)
+	}
+	// A bump moves an item to the front without changing its ID
+	if err := s.History().UpdateTimestamp(ids[0], seedBase.Add(time.Hour)); err != nil {
+		t.Fatalf("UpdateTimestamp() error = %v", err)
+	}
+
+	items, err := s.History().ListAfterID(0)
+	if err != nil {
+		t.Fatalf("ListAfterID() error = %v", err)
+	}
+	assertTitles(t, items, "first", "second", "third")
+	items, err = s.History().ListAfterID(ids[0])
+	if err != nil {
+		t.Fatalf("ListAfterID() error = %v", err)
+	}
+	assertTitles(t, items, "second", "third")
+	if items, err := s.History().ListAfterID(ids[2]); err != nil || len(items) != 0 {
+		t.Errorf("ListAfterID(last) = %v, %v; want no items", items, err)
+	}
+}
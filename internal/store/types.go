@@ -1,7 +1,10 @@
 package store
 
 import (
+	"fmt"
 	"io"
+	"io/fs"
+	"sort"
 	"time"
 )
 
@@ -15,6 +18,9 @@ type HistoryItem struct {
 	// Title is a user-provided or auto-generated title (max 80 characters).
 	Title string
 
+	// Note is a free-form description set by the user. Empty means no note.
+	Note string
+
 	// Timestamp is the creation time used for LIFO ordering.
 	// Items with newer timestamps appear first in the queue.
 	Timestamp time.Time
@@ -30,6 +36,13 @@ type HistoryItem struct {
 	// Useful for deduplication and integrity verification.
 	SHA256 string
 
+	// OriginalName, OriginalModTime, and OriginalMode describe the file the
+	// content was stored from, so it can be restored. OriginalName is empty
+	// for content not stored from a file.
+	OriginalName    string
+	OriginalModTime time.Time
+	OriginalMode    fs.FileMode
+
 	// CreatedAt is the timestamp when the item was first stored.
 	// Managed automatically by the storage layer.
 	CreatedAt time.Time
@@ -37,6 +50,17 @@ type HistoryItem struct {
 	// UpdatedAt is the timestamp of the last update.
 	// Managed automatically by the storage layer.
 	UpdatedAt time.Time
+
+	// MatchCount is the number of matching content lines (at least 1 when
+	// only the title matched). Set only by searches with CountMatches.
+	MatchCount int
+
+	// MatchedIn names the fields that matched, in the order "title",
+	// "content", "note", and Snippets holds up to MaxSnippets matching
+	// content lines with the match marked. Set only by searches with
+	// MatchDetails.
+	MatchedIn []string
+	Snippets  []string
 }
 
 // CreateHistoryInput contains the data needed to create a new history item.
@@ -59,6 +83,12 @@ type CreateHistoryInput struct {
 	// IsBinary indicates if the content is binary.
 	// If not set, the storage layer should detect it from the first chunk.
 	IsBinary bool
+
+	// OriginalName, OriginalModTime, and OriginalMode describe the file the
+	// content is read from, if any (see HistoryItem).
+	OriginalName    string
+	OriginalModTime time.Time
+	OriginalMode    fs.FileMode
 }
 
 // SearchQuery contains parameters for searching history items.
@@ -72,12 +102,203 @@ type SearchQuery struct {
 	// SearchContent indicates whether to search in item content.
 	SearchContent bool
 
+	// SearchNotes indicates whether to search in item notes.
+	// If SearchTitle, SearchContent and SearchNotes are all false, all
+	// three are searched.
+	SearchNotes bool
+
 	// Limit is the maximum number of results to return.
 	// A value of 0 means no limit.
 	Limit int
 
 	// CaseSensitive indicates whether the search is case-sensitive.
 	CaseSensitive bool
+
+	// Normalize matches accent-insensitively: titles, content and plain
+	// patterns are compared after NFKD normalization with combining marks
+	// removed and, unless CaseSensitive is set, Unicode case folding.
+	Normalize bool
+
+	// OrderBy controls the order of results. The zero value means OrderNewest.
+	// Limit is applied after ordering, so a limited search returns the first
+	// matches in this order.
+	OrderBy SearchOrder
+
+	// CountMatches scans each item's whole content to fill in MatchCount.
+	// It is off by default so searches can stop at the first match.
+	CountMatches bool
+
+	// MatchDetails fills in MatchedIn and Snippets. Every searched field is
+	// checked, so content is scanned even when the title already matched.
+	MatchDetails bool
+
+	// Since and Until restrict results to items with Since <= Timestamp < Until.
+	// A zero value leaves that side unbounded. With a time window set, an
+	// empty Pattern matches every item in the window.
+	Since time.Time
+	Until time.Time
+
+	// MetaEquals restricts results to items whose metadata has each key
+	// set to the given value. With it set, an empty Pattern matches every
+	// item with that metadata.
+	MetaEquals map[string]string
+}
+
+// HasTimeWindow reports whether the query restricts results by timestamp.
+func (q *SearchQuery) HasTimeWindow() bool {
+	return !q.Since.IsZero() || !q.Until.IsZero()
+}
+
+// HasFilter reports whether the query restricts results by anything but
+// its pattern, so that an empty pattern matches every item it lets through.
+func (q *SearchQuery) HasFilter() bool {
+	return q.HasTimeWindow() || len(q.MetaEquals) > 0
+}
+
+// InTimeWindow reports whether t lies in [since, until). A zero since or
+// until leaves that side unbounded.
+func InTimeWindow(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && !t.Before(until) {
+		return false
+	}
+	return true
+}
+
+// SearchOrder is the ordering applied to search results.
+type SearchOrder string
+
+const (
+	// OrderNewest returns the most recent matches first.
+	OrderNewest SearchOrder = "newest"
+
+	// OrderOldest returns the oldest matches first.
+	OrderOldest SearchOrder = "oldest"
+
+	// OrderRelevance is reserved for ranked (fuzzy) search.
+	// Until ranking exists it behaves like OrderNewest.
+	OrderRelevance SearchOrder = "relevance"
+)
+
+// ParseSearchOrder converts a user-supplied order name into a SearchOrder.
+// An empty string yields OrderNewest.
+func ParseSearchOrder(s string) (SearchOrder, error) {
+	switch SearchOrder(s) {
+	case "", OrderNewest:
+		return OrderNewest, nil
+	case OrderOldest:
+		return OrderOldest, nil
+	case OrderRelevance:
+		return OrderRelevance, nil
+	default:
+		return "", fmt.Errorf("invalid search order %q (valid: newest, oldest, relevance)", s)
+	}
+}
+
+// Oldest reports whether results should be returned oldest first.
+func (o SearchOrder) Oldest() bool {
+	return o == OrderOldest
+}
+
+// DefaultIterBatchSize is the number of items Iterate loads at a time when
+// IterOptions.BatchSize is not set.
+const DefaultIterBatchSize = 500
+
+// IterOptions configures HistoryStore.Iterate.
+type IterOptions struct {
+	// Order is OrderOldest for oldest first; anything else is newest first.
+	Order SearchOrder
+
+	// BatchSize is the number of items loaded at a time.
+	// If 0, DefaultIterBatchSize is used.
+	BatchSize int
+
+	// After, if set, starts iteration just past the item with that
+	// timestamp and ID in Order, so a page can pick up where the last
+	// one ended even as newer items are added.
+	After *IterCursor
+}
+
+// ClearOptions configures HistoryStore.Clear.
+type ClearOptions struct {
+	// ResetIDs starts item IDs from 1 again. By default IDs keep
+	// increasing, so an ID (and the @reference made from it) is never
+	// reused. A Watcher made before the reset reports new items only once
+	// their IDs pass the highest it had seen.
+	ResetIDs bool
+}
+
+// IterCursor is a position in the timestamp-then-ID order Iterate uses.
+type IterCursor struct {
+	Timestamp time.Time
+	ID        uint
+}
+
+// Batch returns the batch size to use.
+func (o IterOptions) Batch() int {
+	if o.BatchSize > 0 {
+		return o.BatchSize
+	}
+	return DefaultIterBatchSize
+}
+
+// SortKey is a field items can be listed in order of.
+type SortKey string
+
+const (
+	// SortAge lists items newest first, the queue order.
+	SortAge SortKey = "age"
+
+	// SortSize lists the largest items first.
+	SortSize SortKey = "size"
+
+	// SortTitle lists items by title, A to Z, comparing bytes.
+	SortTitle SortKey = "title"
+)
+
+// ParseSortKey converts a user-supplied sort key name into a SortKey.
+// An empty string yields SortAge.
+func ParseSortKey(s string) (SortKey, error) {
+	switch SortKey(s) {
+	case "", SortAge:
+		return SortAge, nil
+	case SortSize, SortTitle:
+		return SortKey(s), nil
+	default:
+		return "", fmt.Errorf("invalid sort key %q (valid: age, size, title)", s)
+	}
+}
+
+// OrderSpec is an order to list items in: Key's, or its exact reverse.
+// Items Key doesn't tell apart stay in queue order, newest first.
+type OrderSpec struct {
+	Key     SortKey
+	Reverse bool
+}
+
+// Less reports whether a comes before b in o.
+func (o OrderSpec) Less(a, b *HistoryItem) bool {
+	if o.Reverse {
+		a, b = b, a
+	}
+	switch {
+	case o.Key == SortSize && a.Size != b.Size:
+		return a.Size > b.Size
+	case o.Key == SortTitle && a.Title != b.Title:
+		return a.Title < b.Title
+	case !a.Timestamp.Equal(b.Timestamp):
+		return a.Timestamp.After(b.Timestamp)
+	}
+	return a.ID > b.ID
+}
+
+// SortItems sorts items in place into o.
+func SortItems(items []*HistoryItem, o OrderSpec) {
+	sort.Slice(items, func(i, j int) bool {
+		return o.Less(items[i], items[j])
+	})
 }
 
 // SearchResult contains a single search result with match information.
@@ -0,0 +1,41 @@
+package store
+
+// Watcher finds the items created since it last looked, for following a
+// store that other processes write to. It tracks the highest ID seen, so
+// after a Clear with ResetIDs it misses new items until their IDs pass
+// that one; make a new Watcher then.
+type Watcher struct {
+	history HistoryStore
+	lastID  uint // highest item ID seen when last polled
+}
+
+// NewWatcher returns a Watcher that reports items created from now on
+func NewWatcher(history HistoryStore) (*Watcher, error) {
+	w := &Watcher{history: history}
+	newest, err := history.List(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(newest) > 0 {
+		w.lastID = newest[0].ID
+	}
+	// Items stored with earlier timestamps, as 'rem sync' may, can have
+	// higher IDs than the newest
+	if _, err := w.Poll(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Poll returns the items created since the last poll, in the order they
+// were created, whatever their timestamps. An idle poll loads no items.
+func (w *Watcher) Poll() ([]*HistoryItem, error) {
+	created, err := w.history.ListAfterID(w.lastID)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) > 0 {
+		w.lastID = created[len(created)-1].ID
+	}
+	return created, nil
+}
@@ -0,0 +1,77 @@
+// Package textnorm normalizes text for accent- and case-insensitive
+// matching, so searches in the CLI and the viewer find the same things.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// newTransformer returns a transformer that applies NFKD
+// normalization and strips combining marks, then applies Unicode case
+// folding if fold is set
+func newTransformer(fold bool) transform.Transformer {
+	if fold {
+		return transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), cases.Fold())
+	}
+	return transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)))
+}
+
+// Normalize returns s with compatibility characters decomposed (so "ﬁ"
+// becomes "fi"), accents removed, and, if fold is set, case folded
+func Normalize(s string, fold bool) string {
+	out, _, err := transform.String(newTransformer(fold), s)
+	if err != nil {
+		return s
+	}
+	return out
+}
+
+// Normalizer normalizes text written to it in chunks, like Normalize.
+// Chunks may be split anywhere: an incomplete UTF-8 sequence at the end of a
+// chunk is carried over and completed by the next one.
+type Normalizer struct {
+	fold    bool
+	pending []byte
+	out     strings.Builder
+}
+
+// New creates a Normalizer that case folds if fold is set
+func New(fold bool) *Normalizer {
+	return &Normalizer{fold: fold}
+}
+
+// Write normalizes p, holding back a trailing incomplete rune
+func (n *Normalizer) Write(p []byte) (int, error) {
+	buf := append(n.pending, p...)
+	cut := incompleteRuneStart(buf)
+	n.out.WriteString(Normalize(string(buf[:cut]), n.fold))
+	n.pending = append([]byte(nil), buf[cut:]...)
+	return len(p), nil
+}
+
+// String returns the normalized text written so far. Bytes of a rune that
+// was never completed are kept as they are.
+func (n *Normalizer) String() string {
+	return n.out.String() + string(n.pending)
+}
+
+// incompleteRuneStart returns the offset of a UTF-8 sequence cut off at the
+// end of b, or len(b) if b ends on a rune boundary
+func incompleteRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,52 @@
+package textnorm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		fold bool
+		want string
+	}{
+		{"Über", true, "uber"},
+		{"Über", false, "Uber"},
+		{"ﬁle ﬂow", true, "file flow"}, // ligatures decompose
+		{"Straße", true, "strasse"},    // full case folding
+		{"naïve café", true, "naive cafe"},
+		{"plain ascii", true, "plain ascii"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.fold); got != tt.want {
+			t.Errorf("Normalize(%q, %v) = %q, want %q", tt.in, tt.fold, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizer_SplitRune(t *testing.T) {
+	text := "Ünïcödé text"
+	for split := 0; split <= len(text); split++ {
+		n := New(true)
+		n.Write([]byte(text[:split]))
+		n.Write([]byte(text[split:]))
+		if got := n.String(); got != "unicode text" {
+			t.Errorf("split at %d: got %q, want %q", split, got, "unicode text")
+		}
+	}
+
+	// A rune that is never completed is kept as is
+	n := New(true)
+	n.Write([]byte("abc\xc3"))
+	if got := n.String(); got != "abc\xc3" {
+		t.Errorf("Expected the incomplete rune to be kept, got %q", got)
+	}
+}
+
+func TestNormalize_LongInput(t *testing.T) {
+	text := strings.Repeat("é", 10000)
+	if got := Normalize(text, true); got != strings.Repeat("e", 10000) {
+		t.Errorf("Expected long input to normalize fully, got %d bytes", len(got))
+	}
+}
@@ -0,0 +1,71 @@
+// Package timefmt formats timestamps for display, in the local time zone or
+// one chosen with the timezone setting or --utc. Times are stored in UTC;
+// only what is shown changes with the zone.
+package timefmt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Zones are looked up by name even where the system has no zoneinfo,
+	// as on Windows
+	_ "time/tzdata"
+)
+
+// Layouts for absolute times
+const (
+	Date   = time.DateOnly
+	Minute = "2006-01-02 15:04"
+	Second = time.DateTime
+)
+
+// LoadLocation returns the zone named by an IANA name such as
+// Europe/Berlin. "" and "Local" are the local zone.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "Local" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (use an IANA name such as Europe/Berlin, or UTC)", name)
+	}
+	return loc, nil
+}
+
+// Now returns the current time in loc; a nil loc is the local zone
+func Now(loc *time.Location) time.Time {
+	return time.Now().In(orLocal(loc))
+}
+
+// Format formats t in loc with layout; a nil loc is the local zone
+func Format(t time.Time, loc *time.Location, layout string) string {
+	return t.In(orLocal(loc)).Format(layout)
+}
+
+// Age formats how long before now t was, such as "5m ago" or "2d ago".
+// Times over a month ago are shown as a date in now's zone.
+func Age(t, now time.Time) string {
+	age := now.Sub(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age/(24*time.Hour)))
+	default:
+		return t.In(now.Location()).Format(Date)
+	}
+}
+
+// orLocal returns loc, or the local zone if loc is nil
+func orLocal(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,51 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAge(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[time.Duration]string{
+		10 * time.Second:    "just now",
+		5 * time.Minute:     "5m ago",
+		3 * time.Hour:       "3h ago",
+		49 * time.Hour:      "2d ago",
+		45 * 24 * time.Hour: "2024-03-17",
+	}
+	for age, want := range tests {
+		if got := Age(now.Add(-age), now); got != want {
+			t.Errorf("Age(-%v) = %q, want %q", age, got, want)
+		}
+	}
+
+	// The date is the one in now's zone
+	tokyo, err := LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2024, 3, 16, 20, 0, 0, 0, time.UTC)
+	if got := Age(old, now.In(tokyo)); got != "2024-03-17" {
+		t.Errorf("Age in Tokyo = %q, want 2024-03-17", got)
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	for _, name := range []string{"", "Local"} {
+		if loc, err := LoadLocation(name); err != nil || loc != time.Local {
+			t.Errorf("LoadLocation(%q) = %v, %v; want the local zone", name, loc, err)
+		}
+	}
+	loc, err := LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	stamp := time.Date(2024, 1, 15, 17, 30, 0, 0, time.UTC)
+	if got := Format(stamp, loc, Minute); got != "2024-01-15 12:30" {
+		t.Errorf("Format() = %q, want 2024-01-15 12:30", got)
+	}
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an unknown zone to be rejected")
+	}
+}
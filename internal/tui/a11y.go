@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// announceDuration is how long an announcement stays up, long enough for a
+// screen reader to reach it
+const announceDuration = 10 * time.Second
+
+// a11yState is the state whose changes accessibility mode announces
+type a11yState struct {
+	mode        UIMode
+	pane        PaneType
+	selected    int
+	item        *StackItem
+	count       int
+	flash       string
+	flashExpiry time.Time
+}
+
+// a11ySnapshot records the announced state before a message is handled
+func (a *AppModel) a11ySnapshot() a11yState {
+	return a11yState{
+		mode:        a.CurrentMode,
+		pane:        a.ActivePane,
+		selected:    a.LeftPane.Selected,
+		item:        a.selectedItem(),
+		count:       len(a.Items),
+		flash:       a.FlashMessage,
+		flashExpiry: a.FlashExpiry,
+	}
+}
+
+// announceChanges puts a plain-text description of what changed since
+// before above the status line, such as "Selected item 4 of 20: nginx config".
+// A flash message set meanwhile is part of it and stays up as long.
+func (a *AppModel) announceChanges(before a11yState) tea.Cmd {
+	var parts []string
+	flashed := a.FlashMessage != "" && (a.FlashMessage != before.flash || !a.FlashExpiry.Equal(before.flashExpiry))
+	if flashed {
+		parts = append(parts, a.FlashMessage)
+	}
+
+	if a.CurrentMode != before.mode {
+		switch {
+		case a.CurrentMode != NormalMode:
+			parts = append(parts, fmt.Sprintf("Entered %s mode", modeName(a.CurrentMode)))
+		case !flashed:
+			parts = append(parts, "Back to normal mode")
+		}
+	}
+	if a.ActivePane != before.pane {
+		if a.ActivePane == RightPane {
+			parts = append(parts, "Focused the content pane")
+		} else {
+			parts = append(parts, "Focused the list pane")
+		}
+	}
+	if item := a.selectedItem(); item != nil && (item != before.item || a.LeftPane.Selected != before.selected) {
+		parts = append(parts, fmt.Sprintf("Selected item %d of %d: %s", a.LeftPane.Selected+1, len(a.Items), item.Preview))
+	} else if item == nil && before.count > 0 {
+		parts = append(parts, "No items left")
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+	return a.setFlashMessage(strings.Join(parts, ". "), announceDuration)
+}
+
+// announcementLine returns the line above the status line: blank, or in
+// accessibility mode the current announcement
+func announcementLine(model AppModel) string {
+	if !model.A11y || model.FlashMessage == "" || !time.Now().Before(model.FlashExpiry) {
+		return ""
+	}
+	return fitStatusLine(model.FlashMessage, model.Width)
+}
+
+// modeName names a mode in announcements
+func modeName(mode UIMode) string {
+	switch mode {
+	case SearchMode:
+		return "search"
+	case HelpMode:
+		return "help"
+	case NumberInputMode:
+		return "count"
+	case DeleteMode:
+		return "delete confirmation"
+	case NoteMode:
+		return "note"
+	case OverwriteMode:
+		return "overwrite confirmation"
+	}
+	return "normal"
+}
+
+// asciiRunes replaces the box-drawing and other decorative runes the views
+// use; each replacement is one cell wide, like the rune it replaces
+var asciiRunes = map[rune]rune{
+	'─': '-', '━': '-', '═': '=',
+	'│': '|', '║': '|',
+	'┃': '#', // scrollbar thumb, distinct from its track
+	'●': '*',
+	'↑': '^', // recently copied
+}
+
+// asciiView replaces box-drawing characters in view with plain ASCII, so
+// borders read as simple punctuation rather than symbol names
+func asciiView(view string) string {
+	return strings.Map(func(r rune) rune {
+		if ascii, ok := asciiRunes[r]; ok {
+			return ascii
+		}
+		if r >= 0x2500 && r <= 0x257F { // corners, junctions, and other box drawing
+			return '+'
+		}
+		return r
+	}, view)
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newA11yTestApp(n int) *AppModel {
+	app := newPendingTestApp(n)
+	app.A11y = true
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+	return app
+}
+
+func TestA11y_Announcements(t *testing.T) {
+	app := newA11yTestApp(5)
+
+	press(app, "j")
+	if app.FlashMessage != "Selected item 2 of 5: item 1" {
+		t.Errorf("j announced %q", app.FlashMessage)
+	}
+
+	press(app, "l")
+	if app.FlashMessage != "Focused the content pane" {
+		t.Errorf("l announced %q", app.FlashMessage)
+	}
+	press(app, "/")
+	if app.FlashMessage != "Entered search mode" {
+		t.Errorf("/ announced %q", app.FlashMessage)
+	}
+	// The announcement has a line of its own, so the search prompt stays
+	view, _ := AppView(*app)
+	lines := strings.Split(view, "\n")
+	if !strings.Contains(lines[len(lines)-2], "Entered search mode") || strings.Contains(lines[len(lines)-1], "Entered search mode") {
+		t.Errorf("announcement not on the line above the status line:\n%s", view)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.FlashMessage != "Back to normal mode" {
+		t.Errorf("Esc announced %q", app.FlashMessage)
+	}
+	press(app, "h")
+
+	press(app, "c")
+	if !strings.HasPrefix(app.FlashMessage, "Copied ") {
+		t.Errorf("c announced %q", app.FlashMessage)
+	}
+
+	press(app, "d")
+	if !strings.HasPrefix(app.FlashMessage, "Entered delete confirmation mode") {
+		t.Errorf("d announced %q", app.FlashMessage)
+	}
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	app.Update(cmd())
+	if want := "Deleted item: item 1. Selected item 2 of 4: item 2"; app.FlashMessage != want {
+		t.Errorf("confirming the delete announced %q, want %q", app.FlashMessage, want)
+	}
+
+	// An earlier flash message's timer doesn't cut the announcement short
+	app.Update(flashExpiredMsg{})
+	if app.FlashMessage == "" {
+		t.Error("an earlier timer cleared the announcement")
+	}
+}
+
+func TestA11y_Off(t *testing.T) {
+	app := newPendingTestApp(5)
+	press(app, "j")
+	if app.FlashMessage != "" {
+		t.Errorf("moving announced %q without accessibility mode", app.FlashMessage)
+	}
+}
+
+func TestA11y_ASCIIView(t *testing.T) {
+	app := newA11yTestApp(5)
+	app.Items[0] = &StackItem{Content: NewStringReadSeekCloser(strings.Repeat("line\n", 100)), Preview: "long"}
+	app.RightPane.Update(UpdateContentMsg{})
+
+	plain := *app
+	plain.A11y = false
+	fancy, _ := AppView(plain)
+	view, err := AppView(*app)
+	if err != nil {
+		t.Fatalf("AppView() error = %v", err)
+	}
+
+	for _, r := range view {
+		if (r >= 0x2500 && r <= 0x257F) || r == '●' {
+			t.Fatalf("ASCII view contains %q:\n%s", r, view)
+		}
+	}
+	if !strings.Contains(view, "+---") || !strings.Contains(view, "* Queue") {
+		t.Errorf("ASCII view lacks ASCII borders or the focus marker:\n%s", view)
+	}
+
+	// Each line is as wide as in the usual view, so the panes still line up
+	lines, fancyLines := strings.Split(view, "\n"), strings.Split(fancy, "\n")
+	if len(lines) != len(fancyLines) {
+		t.Fatalf("ASCII view has %d lines, want %d", len(lines), len(fancyLines))
+	}
+	for i := range lines {
+		if w, want := lipgloss.Width(lines[i]), lipgloss.Width(fancyLines[i]); w != want {
+			t.Errorf("line %d is %d cells wide, want %d", i, w, want)
+		}
+	}
+
+	// The delete confirmation is drawn in ASCII too
+	press(app, "d")
+	modal, _ := AppView(*app)
+	if strings.ContainsAny(modal, "╭╮╰╯─│") {
+		t.Errorf("delete confirmation has box drawing:\n%s", modal)
+	}
+}
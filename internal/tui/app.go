@@ -1,15 +1,21 @@
 package tui
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/yiblet/rem/internal/clipboard"
+	"github.com/yiblet/rem/internal/timefmt"
 )
 
 // PaneType represents which pane is focused
@@ -20,6 +26,12 @@ const (
 	RightPane
 )
 
+// Smallest terminal size the panes are rendered at
+const (
+	MinWidth  = 40
+	MinHeight = 10
+)
+
 // UIMode represents the current modal state of the application
 type UIMode int
 
@@ -29,6 +41,9 @@ const (
 	HelpMode
 	NumberInputMode
 	DeleteMode
+	NoteMode
+	OverwriteMode
+	LinkMode
 )
 
 // AppMsg represents messages that the app component handles
@@ -84,14 +99,80 @@ type AppModel struct {
 	NumberBuffer string   // Accumulates digits
 	BufferPane   PaneType // Which pane the buffer applies to
 
+	// NoteInput holds the note being edited in note mode
+	NoteInput string
+
+	// Jumps records visited items for ctrl+o and ctrl+n
+	Jumps JumpList
+
+	// Preview defers loading the right pane while the cursor moves quickly
+	Preview PreviewDebounce
+
+	// IncSearch searches the selected item while a pattern is typed
+	IncSearch IncrementalSearch
+
+	// RecentCopies marks the items copied most recently, for ' to jump to
+	RecentCopies RecentCopies
+
+	// Triage shows the first lines of several items in the right pane
+	Triage Triage
+
+	// ReadOnly refuses keys that change items, such as delete and bump, for
+	// a session that mustn't write, like one beside another rem TUI
+	ReadOnly bool
+
+	// Location is the zone times are shown in; nil is the local zone
+	Location *time.Location
+	// AbsoluteTimes shows the selected item's time as a date and time
+	// rather than an age
+	AbsoluteTimes bool
+
+	// pendingSearch is set when a search pattern was given before the
+	// window size was known; the first resize applies it
+	pendingSearch bool
+
 	// Flash message for temporary notifications
 	FlashMessage string    // The message to display
 	FlashExpiry  time.Time // When the message should disappear
 
+	// ClipboardMaxBytes is the largest item that may be copied to the clipboard
+	ClipboardMaxBytes int64
+
+	// ClipboardOverwriteConfirm asks before copying over clipboard content
+	// rem didn't put there
+	ClipboardOverwriteConfirm bool
+
+	// Keys maps remappable normal-mode keys to their actions
+	Keys Keymap
+
+	// Paging loads more items as the cursor nears the end of the list
+	Paging Paging
+
+	// Pending is a key waiting for its repeat, like the first g of gg
+	Pending PendingKey
+
+	// A11y announces state changes above the status line and draws borders in
+	// plain ASCII, for screen readers
+	A11y bool
+
+	// lastCopied is the SHA256 of the content this session last copied
+	lastCopied string
+
+	// pendingOverwrite is the copy waiting on the overwrite confirmation
+	pendingOverwrite Action
+
+	// pendingLinks are the links offered by the open-link picker
+	pendingLinks []string
+
 	// Dependencies
-	clipboard clipboard.Clipboard // Clipboard for copy operations
+	clipboard clipboard.Clipboard    // Clipboard for copy operations
+	ops       ItemOps                // Persistent item operations; nil keeps changes in memory
+	opener    func(url string) error // Opens links; nil means links can't be opened
 }
 
+// DefaultClipboardMaxBytes is the clipboard size limit used when none is configured
+const DefaultClipboardMaxBytes int64 = 64 * 1024 * 1024
+
 // NewAppModel creates a new app model with all sub-models
 func NewAppModel(items []*StackItem, clip clipboard.Clipboard) AppModel {
 	// Default dimensions that will be properly set on first resize
@@ -100,7 +181,7 @@ func NewAppModel(items []*StackItem, clip clipboard.Clipboard) AppModel {
 	defaultLeftWidth := 25
 	defaultRightWidth := 90
 
-	return AppModel{
+	app := AppModel{
 		Width:       defaultWidth,
 		Height:      defaultHeight,
 		LeftWidth:   defaultLeftWidth,
@@ -112,18 +193,89 @@ func NewAppModel(items []*StackItem, clip clipboard.Clipboard) AppModel {
 		Search:      NewSearchModel(),
 		Modal:       NewModalModel(),
 		Items:       items,
-		clipboard:   clip,
+
+		IncSearch:         IncrementalSearch{Enabled: true, Delay: DefaultIncrementalSearchDelay},
+		RecentCopies:      RecentCopies{Limit: DefaultRecentCopiesLimit},
+		ClipboardMaxBytes: DefaultClipboardMaxBytes,
+		Keys:              DefaultKeymap(),
+		clipboard:         clip,
+	}
+	app.SetHints(true)
+	return app
+}
+
+// SetHints sets whether each pane shows hints for its keys in its bottom
+// row while focused. Call it again after changing Keys.
+func (a *AppModel) SetHints(show bool) {
+	a.LeftPane.Hints, a.RightPane.Hints = nil, nil
+	if show {
+		a.LeftPane.Hints = leftPaneHints(a.Keys)
+		a.RightPane.Hints = rightPaneHints(a.Keys)
 	}
 }
 
 // Update handles app-level messages and routes to appropriate sub-models
 func (a *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// An item the last render found deleted is dropped before msg acts on
+	// the list
+	if !a.A11y {
+		dropped := a.dropDeleted()
+		model, cmd := a.update(msg)
+		return model, tea.Batch(dropped, cmd)
+	}
+	if _, ok := msg.(flashExpiredMsg); ok && time.Now().Before(a.FlashExpiry) {
+		// The timer of a flash message an announcement has since extended
+		return a, nil
+	}
+	before := a.a11ySnapshot()
+	dropped := a.dropDeleted()
+	model, cmd := a.update(msg)
+	return model, tea.Batch(dropped, cmd, a.announceChanges(before))
+}
+
+// dropDeleted removes the selected item from the list once reading it found
+// it deleted by another rem, which leaves nothing more of it to show
+func (a *AppModel) dropDeleted() tea.Cmd {
+	if a.LeftPane.Selected >= len(a.Items) {
+		return nil
+	}
+	if item := a.Items[a.LeftPane.Selected]; item == nil || !item.deleted {
+		return nil
+	}
+	a.removeSelected()
+	return a.setFlashMessage("Item was deleted by another rem", 3*time.Second)
+}
+
+// update is Update without accessibility announcements
+func (a *AppModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Handle Bubble Tea messages first
 	switch m := msg.(type) {
 	case tea.WindowSizeMsg:
 		return a.handleWindowResize(m)
 	case tea.KeyMsg:
-		return a.handleKeyPress(m)
+		model, cmd := a.handleKeyPress(m)
+		if a.ActivePane == LeftPane {
+			cmd = tea.Batch(cmd, a.loadAhead())
+		}
+		return model, cmd
+	case loadMoreMsg:
+		return a, a.handleLoadMore(m)
+	case pendingExpiredMsg:
+		return a, a.handlePendingExpired(m)
+	case ModalResultMsg:
+		return a.handleModalResult(m)
+	case jumpSettledMsg:
+		a.handleJumpSettled(m)
+		return a, nil
+	case loadContentMsg:
+		a.handleLoadContent(m)
+		return a, nil
+	case previewFrameMsg:
+		a.handlePreviewFrame(m)
+		return a, nil
+	case incSearchMsg:
+		a.handleIncrementalSearch(m)
+		return a, nil
 	case flashExpiredMsg:
 		// Clear flash message when it expires
 		a.FlashMessage = ""
@@ -136,14 +288,12 @@ func (a *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleWindowResize processes window resize events
 func (a *AppModel) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
+	// Keep the real size so AppView can tell when the terminal is too small;
+	// the pane layout below is computed for at least minTotalWidth columns
 	a.Width = msg.Width
 	a.Height = msg.Height
-
-	// Ensure minimum total width of 30 characters
 	minTotalWidth := 30
-	if msg.Width < minTotalWidth {
-		a.Width = minTotalWidth
-	}
+	width := max(msg.Width, minTotalWidth)
 
 	// Calculate pane widths with proper constraints
 	minLeftWidth := 15
@@ -151,24 +301,24 @@ func (a *AppModel) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd
 	borderSpacing := 2 // Account for adjacent borders (no space separator)
 
 	// If total width is too small, split proportionally
-	if a.Width < minLeftWidth+minRightWidth+borderSpacing {
+	if width < minLeftWidth+minRightWidth+borderSpacing {
 		// Very narrow - give each pane minimum space
 		a.LeftWidth = minLeftWidth
-		a.RightWidth = max(a.Width-a.LeftWidth-borderSpacing, minRightWidth)
+		a.RightWidth = max(width-a.LeftWidth-borderSpacing, minRightWidth)
 	} else {
 		// Normal case - use preferred left width, rest goes to right
 		preferredLeftWidth := 25
-		a.LeftWidth = min(preferredLeftWidth, a.Width/3) // Don't take more than 1/3
-		a.RightWidth = a.Width - a.LeftWidth - borderSpacing
+		a.LeftWidth = min(preferredLeftWidth, width/3) // Don't take more than 1/3
+		a.RightWidth = width - a.LeftWidth - borderSpacing
 
 		// Ensure minimums are respected
 		if a.LeftWidth < minLeftWidth {
 			a.LeftWidth = minLeftWidth
-			a.RightWidth = a.Width - a.LeftWidth - borderSpacing
+			a.RightWidth = width - a.LeftWidth - borderSpacing
 		}
 		if a.RightWidth < minRightWidth {
 			a.RightWidth = minRightWidth
-			a.LeftWidth = a.Width - a.RightWidth - borderSpacing
+			a.LeftWidth = width - a.RightWidth - borderSpacing
 		}
 	}
 
@@ -178,6 +328,10 @@ func (a *AppModel) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd
 
 	// Update content in right pane when window resizes
 	a.RightPane.Update(UpdateContentMsg{})
+	if a.pendingSearch {
+		a.pendingSearch = false
+		a.syncSearchToSelection()
+	}
 
 	return a, nil
 }
@@ -186,16 +340,32 @@ func (a *AppModel) handleWindowResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd
 func (a *AppModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
+	// Only cursor movement may leave the right pane waiting to load
+	if !isCursorKey(key) {
+		a.flushContent()
+	}
+
+	// An open modal captures every key until it is dismissed
+	if a.Modal.Active {
+		return a.handleModalKeys(key)
+	}
+
 	// MODE-FIRST ARCHITECTURE: Check current mode before processing any keys
 	switch a.CurrentMode {
 	case SearchMode:
-		return a.handleSearchModeKeys(key)
+		// Each edit of the pattern restarts the incremental search debounce
+		input := a.Search.GetInput()
+		model, cmd := a.handleSearchModeKeys(key)
+		if a.CurrentMode == SearchMode && a.Search.GetInput() != input {
+			cmd = tea.Batch(cmd, a.scheduleIncrementalSearch())
+		}
+		return model, cmd
 	case HelpMode:
 		return a.handleHelpModeKeys(key)
 	case NumberInputMode:
 		return a.handleNumberInputModeKeys(key)
-	case DeleteMode:
-		return a.handleDeleteModeKeys(key)
+	case NoteMode:
+		return a.handleNoteModeKeys(key)
 	case NormalMode:
 		return a.handleNormalModeKeys(key)
 	default:
@@ -211,35 +381,45 @@ func (a *AppModel) handleSearchModeKeys(key string) (tea.Model, tea.Cmd) {
 		// Force quit is always available
 		return a, tea.Quit
 	case "esc":
-		// Cancel search and return to normal mode
+		// Leave history recall first, then cancel search and return to
+		// normal mode
+		if a.Search.History.browsing {
+			a.Search.Update(RestoreInputMsg{})
+			return a, nil
+		}
 		a.Search.Update(CancelSearchMsg{})
+		a.restoreIncrementalSearch()
 		a.CurrentMode = NormalMode
 		return a, nil
+	case "up":
+		a.Search.Update(HistoryPrevMsg{})
+		return a, nil
+	case "down":
+		a.Search.Update(HistoryNextMsg{})
+		return a, nil
+	case "ctrl+r":
+		a.Search.Update(ReverseSearchMsg{})
+		return a, nil
 	case "enter":
 		// Execute search and return to normal mode
 		a.Search.Update(ExecuteSearchMsg{})
 
-		// If search was successful and we have matches, perform the search on current item
-		if a.Search.GetError() == "" && a.LeftPane.Selected < len(a.Items) {
-			selectedItem := a.Items[a.LeftPane.Selected]
-			if selectedItem != nil && a.Search.GetPattern() != "" {
-				// Perform search on the item
-				selectedItem.performSearch(a.Search.GetPattern())
-
-				// Update search model with matches
-				a.Search.SetMatches(selectedItem.SearchMatches)
-
-				// Jump to first match if found
-				if matchLine := a.Search.GetCurrentMatchLine(); matchLine >= 0 {
-					newViewPos := scrollToMatch(a.RightPane, selectedItem, matchLine)
-					a.RightPane.ViewPos = newViewPos
-				}
-			}
+		// If the pattern is valid, search every item so the left pane can show
+		// match counts, then jump to the first match in the current item
+		if a.Search.GetError() == "" {
+			a.searchAllItems(a.Search.GetPattern())
+			a.syncSearchToSelection()
 		}
 		a.CurrentMode = NormalMode
 		return a, nil
 	case "backspace", "ctrl+h":
-		// Remove last character
+		// Remove last character, of the ctrl+r query while one is typed
+		if query, ok := a.Search.History.Searching(); ok {
+			if len(query) > 0 {
+				a.Search.Update(UpdateReverseQueryMsg{Query: query[:len(query)-1]})
+			}
+			return a, nil
+		}
 		if len(a.Search.GetInput()) > 0 {
 			newInput := a.Search.GetInput()[:len(a.Search.GetInput())-1]
 			a.Search.Update(UpdateSearchInputMsg{Input: newInput})
@@ -248,6 +428,10 @@ func (a *AppModel) handleSearchModeKeys(key string) (tea.Model, tea.Cmd) {
 	default:
 		// Add character to search input (only printable characters)
 		if len(key) == 1 && key[0] >= 32 && key[0] <= 126 {
+			if query, ok := a.Search.History.Searching(); ok {
+				a.Search.Update(UpdateReverseQueryMsg{Query: query + key})
+				return a, nil
+			}
 			newInput := a.Search.GetInput() + key
 			a.Search.Update(UpdateSearchInputMsg{Input: newInput})
 		}
@@ -255,6 +439,56 @@ func (a *AppModel) handleSearchModeKeys(key string) (tea.Model, tea.Cmd) {
 	}
 }
 
+// handleNoteModeKeys processes keys while the selected item's note is edited
+func (a *AppModel) handleNoteModeKeys(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "ctrl+c":
+		// Force quit is always available
+		return a, tea.Quit
+	case "esc":
+		// Discard the edit
+		a.NoteInput = ""
+		a.CurrentMode = NormalMode
+		return a, nil
+	case "enter":
+		note := strings.TrimSpace(a.NoteInput)
+		a.NoteInput = ""
+		a.CurrentMode = NormalMode
+		return a, a.saveNote(note)
+	case "backspace", "ctrl+h":
+		// Remove the last character
+		if _, size := utf8.DecodeLastRuneInString(a.NoteInput); size > 0 {
+			a.NoteInput = a.NoteInput[:len(a.NoteInput)-size]
+		}
+		return a, nil
+	default:
+		// Add printable characters, including non-ASCII ones
+		if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsPrint(r) {
+			a.NoteInput += key
+		}
+		return a, nil
+	}
+}
+
+// saveNote sets the selected item's note, persisting it through ItemOps
+func (a *AppModel) saveNote(note string) tea.Cmd {
+	if a.LeftPane.Selected >= len(a.Items) {
+		return a.setFlashMessage("No item selected", 2*time.Second)
+	}
+	selectedItem := a.Items[a.LeftPane.Selected]
+	if a.ops != nil {
+		if err := a.ops.SetNote(selectedItem.ID, note); err != nil {
+			return a.setFlashMessage(fmt.Sprintf("Failed to save note: %v", err), 2*time.Second)
+		}
+	}
+	selectedItem.Note = note
+	selectedItem.UpdatedAt = time.Now()
+	if note == "" {
+		return a.setFlashMessage("Note cleared", 2*time.Second)
+	}
+	return a.setFlashMessage("Note saved", 2*time.Second)
+}
+
 // handleHelpModeKeys processes keys when in help mode
 func (a *AppModel) handleHelpModeKeys(key string) (tea.Model, tea.Cmd) {
 	switch key {
@@ -304,7 +538,7 @@ func (a *AppModel) handleNumberInputModeKeys(key string) (tea.Model, tea.Cmd) {
 			}
 			a.NumberBuffer = ""
 			a.CurrentMode = NormalMode
-			return a.executeCommand(multiplier, key, a.ActivePane)
+			return a.executeCounted(multiplier, key)
 		} else {
 			// Invalid key, cancel number input
 			a.NumberBuffer = ""
@@ -314,75 +548,86 @@ func (a *AppModel) handleNumberInputModeKeys(key string) (tea.Model, tea.Cmd) {
 	}
 }
 
-// handleDeleteModeKeys processes keys when in delete confirmation mode
-func (a *AppModel) handleDeleteModeKeys(key string) (tea.Model, tea.Cmd) {
-	switch key {
-	case "ctrl+c":
+// handleModalKeys processes keys while a modal is shown. A key that
+// dismisses the modal is routed through Update as a ModalResultMsg.
+func (a *AppModel) handleModalKeys(key string) (tea.Model, tea.Cmd) {
+	if key == "ctrl+c" {
 		// Force quit is always available
 		return a, tea.Quit
-	case "y", "Y":
-		// Confirm deletion
-		if a.LeftPane.Selected < len(a.Items) && len(a.Items) > 0 {
-			deletedIndex := a.LeftPane.Selected
-			selectedItem := a.Items[deletedIndex]
-
-			// Delete from persistent storage if DeleteFunc is provided
-			if selectedItem.DeleteFunc != nil {
-				if err := selectedItem.DeleteFunc(); err != nil {
-					// Show error message and stay in delete mode
-					a.Modal.Update(ShowModalMsg{
-						Title:   "Delete Error",
-						Content: fmt.Sprintf("Failed to delete item: %v", err),
-						Options: "Press any key to continue",
-					})
-					return a, nil
-				}
-			}
-
-			// Remove item from the Items slice
-			a.Items = append(a.Items[:deletedIndex], a.Items[deletedIndex+1:]...)
-
-			// Adjust cursor position if needed
-			if len(a.Items) == 0 {
-				// Queue is now empty
-				a.LeftPane.Cursor = 0
-				a.LeftPane.Selected = 0
-			} else if a.LeftPane.Selected >= len(a.Items) {
-				// Cursor was at the last item, move it back
-				a.LeftPane.Cursor = len(a.Items) - 1
-				a.LeftPane.Selected = a.LeftPane.Cursor
-			}
-			// else: cursor stays at the same index, now pointing to the next item
+	}
+	// The second d of dd lands on the modal the first one opened; any other
+	// key ends the sequence
+	if pending := a.Pending.Key; pending != "" {
+		a.clearPending()
+		if key == pending {
+			return a, nil
+		}
+	}
+	result, done := a.Modal.HandleKey(key)
+	if !done {
+		return a, nil
+	}
+	return a.update(result)
+}
 
-			// Update the right pane content
-			a.RightPane.Update(UpdateContentMsg{})
+// handleModalResult hides the modal and hands its result to the handler for
+// the mode that opened it
+func (a *AppModel) handleModalResult(msg ModalResultMsg) (tea.Model, tea.Cmd) {
+	a.Modal.Update(HideModalMsg{})
+	switch a.CurrentMode {
+	case DeleteMode:
+		return a.handleDeleteResult(msg.Result)
+	case OverwriteMode:
+		return a.handleOverwriteResult(msg.Result)
+	case LinkMode:
+		return a, a.handleLinkResult(msg.Result)
+	}
+	a.CurrentMode = NormalMode
+	return a, nil
+}
 
-			// Show success flash message
-			flashCmd := a.setFlashMessage("Item deleted successfully", 2*time.Second)
+// handleDeleteResult acts on the delete confirmation or delete error modal
+func (a *AppModel) handleDeleteResult(result ModalResult) (tea.Model, tea.Cmd) {
+	if result != ModalDelete && result != ModalRetry {
+		a.CurrentMode = NormalMode
+		return a, nil
+	}
 
-			// Hide modal and return to normal mode
-			a.Modal.Update(HideModalMsg{})
-			a.CurrentMode = NormalMode
-			return a, flashCmd
+	if a.LeftPane.Selected < len(a.Items) && len(a.Items) > 0 {
+		title := a.Items[a.LeftPane.Selected].Preview
+		if err := a.deleteSelected(); err != nil {
+			// Stay in delete mode so the error modal can retry
+			a.Modal.Update(ShowDeleteError(err))
+			return a, nil
 		}
 
-		// Hide modal and return to normal mode
-		a.Modal.Update(HideModalMsg{})
 		a.CurrentMode = NormalMode
-		return a, nil
-	case "n", "N", "esc":
-		// Cancel deletion - hide modal and return to normal mode
-		a.Modal.Update(HideModalMsg{})
-		a.CurrentMode = NormalMode
-		return a, nil
-	default:
-		// Ignore other keys in delete mode
-		return a, nil
+		return a, a.setFlashMessage("Deleted item: "+title, 2*time.Second)
 	}
+
+	a.CurrentMode = NormalMode
+	return a, nil
 }
 
 // handleNormalModeKeys processes keys when in normal mode
 func (a *AppModel) handleNormalModeKeys(key string) (tea.Model, tea.Cmd) {
+	// A key waiting for its repeat takes the next key first
+	if a.Pending.Key != "" {
+		if handled, cmd := a.handlePendingKey(key); handled {
+			return a, cmd
+		}
+	}
+
+	if a.Triage.Active && (key == "tab" || key == "l" || key == "right") {
+		// The content pane to focus is the full viewer
+		a.toggleTriage()
+	}
+
+	// A binding to a shadowable key comes before the key's built-in command
+	if action, ok := a.Keys[key]; ok && shadowableKeys[key] {
+		return a.runBoundAction(action, key)
+	}
+
 	// Handle global keys that work in normal mode
 	switch key {
 	case "ctrl+c", "q":
@@ -393,9 +638,6 @@ func (a *AppModel) handleNormalModeKeys(key string) (tea.Model, tea.Cmd) {
 		// Enter help mode
 		a.CurrentMode = HelpMode
 		return a, nil
-	case "c":
-		// Copy content to clipboard
-		return a, a.copyToClipboard()
 	case "tab":
 		// Toggle between left and right pane
 		if a.ActivePane == LeftPane {
@@ -416,6 +658,57 @@ func (a *AppModel) handleNormalModeKeys(key string) (tea.Model, tea.Cmd) {
 			a.ActivePane = RightPane
 		}
 		return a, nil
+	case "w":
+		// Toggle wrapping of the right pane
+		a.toggleWrap()
+		return a, nil
+	case "t":
+		return a, a.toggleTriage()
+	case "enter":
+		if a.Triage.Active {
+			return a, a.toggleTriage()
+		}
+	case "ctrl+t":
+		// Toggle diff coloring for this session
+		a.RightPane.DiffColors = !a.RightPane.DiffColors
+		if a.RightPane.DiffColors {
+			return a, a.setFlashMessage("Diff colors on", 2*time.Second)
+		}
+		return a, a.setFlashMessage("Diff colors off", 2*time.Second)
+	case "T":
+		// Toggle between the selected item's age and its date and time
+		a.AbsoluteTimes = !a.AbsoluteTimes
+		if a.AbsoluteTimes {
+			return a, a.setFlashMessage("Showing dates and times", 2*time.Second)
+		}
+		return a, a.setFlashMessage("Showing ages", 2*time.Second)
+	case "ctrl+g":
+		// Toggle date headers in the left pane for this session
+		a.LeftPane.GroupByDate = !a.LeftPane.GroupByDate
+		if a.LeftPane.GroupByDate {
+			return a, a.setFlashMessage("Grouping by date", 2*time.Second)
+		}
+		return a, a.setFlashMessage("Not grouping by date", 2*time.Second)
+	case "H", "shift+left":
+		a.scrollHorizontal(-1)
+		return a, nil
+	case "L", "shift+right":
+		a.scrollHorizontal(1)
+		return a, nil
+	case "ctrl+o":
+		// Back to the previously visited item
+		return a, a.jumpBack()
+	case "ctrl+n":
+		// Forward again; ctrl+i is indistinguishable from Tab in terminals
+		return a, a.jumpForward()
+	case "'":
+		// The next recently copied item, newest first
+		return a, a.jumpRecentCopy()
+	}
+
+	// Remappable actions (copy, delete, copy+delete)
+	if action, ok := a.Keys[key]; ok {
+		return a.runBoundAction(action, key)
 	}
 
 	// Handle number input (digits 1-9, 0 only after other digits)
@@ -428,14 +721,20 @@ func (a *AppModel) handleNormalModeKeys(key string) (tea.Model, tea.Cmd) {
 
 	// Check if this is a movement command that should use any existing multiplier
 	if isMovementCommand(key) {
-		multiplier := 1
 		if a.NumberBuffer != "" && a.BufferPane == a.ActivePane {
+			multiplier := 1
 			if num, err := strconv.Atoi(a.NumberBuffer); err == nil {
 				multiplier = num
 			}
 			a.NumberBuffer = ""
+			return a.executeCounted(multiplier, key)
+		}
+		if key == "g" {
+			// A bare g waits for the second g of gg, or acts on its own
+			// once pendingTimeout passes
+			return a, a.startPending("g", false)
 		}
-		return a.executeCommand(multiplier, key, a.ActivePane)
+		return a.executeCommand(1, key, a.ActivePane)
 	}
 
 	// Handle pane-specific keys
@@ -449,23 +748,92 @@ func (a *AppModel) handleNormalModeKeys(key string) (tea.Model, tea.Cmd) {
 	return a, nil
 }
 
+// runBoundAction runs the action key is bound to
+func (a *AppModel) runBoundAction(action Action, key string) (tea.Model, tea.Cmd) {
+	model, cmd := a.runAction(action)
+	if action == ActionDelete && a.CurrentMode == DeleteMode {
+		// So the second key of dd doesn't answer the confirmation
+		cmd = tea.Batch(cmd, a.startPending(key, true))
+	}
+	return model, cmd
+}
+
 // handleLeftPaneKeys processes keys when left pane is focused in normal mode
 func (a *AppModel) handleLeftPaneKeys(key string) (tea.Model, tea.Cmd) {
 	// Only handle non-movement keys here, movement keys are handled by executeCommand
+	// and remappable actions by runAction
 	switch key {
-	case "d":
+	case "N", "b":
+		if a.ReadOnly {
+			return a, a.setFlashMessage(readOnlyMessage, 2*time.Second)
+		}
+	}
+	switch key {
+	case "N":
+		// Edit the selected item's note, starting from the current one
+		if a.LeftPane.Selected < len(a.Items) {
+			a.NoteInput = a.Items[a.LeftPane.Selected].Note
+			a.CurrentMode = NoteMode
+		}
+	case "b":
+		return a, a.bumpSelected()
+	}
+	return a, nil
+}
+
+// bumpSelected moves the selected item to the top of the queue, persisting
+// it through ItemOps, and keeps the cursor on it
+func (a *AppModel) bumpSelected() tea.Cmd {
+	item := a.selectedItem()
+	if item == nil {
+		return a.setFlashMessage("No item selected", 2*time.Second)
+	}
+	if a.ops != nil {
+		if err := a.ops.Bump(item.ID); err != nil {
+			return a.setFlashMessage(fmt.Sprintf("Failed to bump: %v", err), 2*time.Second)
+		}
+	}
+	item.Timestamp = time.Now()
+	item.UpdatedAt = item.Timestamp
+
+	// Items are newest first, so the bumped item is now the first
+	index := a.LeftPane.Selected
+	copy(a.Items[1:index+1], a.Items[:index])
+	a.Items[0] = item
+	a.LeftPane.Update(SelectItemMsg{Index: 0})
+	return a.setFlashMessage("Moved to top: "+item.Preview, 2*time.Second)
+}
+
+// readOnlyMessage is flashed when a key that would change items is pressed
+// in a read-only session
+const readOnlyMessage = "Read-only: items can't be changed in this session"
+
+// runAction executes a keymap action. Delete actions only apply to the left pane.
+func (a *AppModel) runAction(action Action) (tea.Model, tea.Cmd) {
+	if a.ReadOnly && (action == ActionDelete || action == ActionCopyDelete) {
+		return a, a.setFlashMessage(readOnlyMessage, 2*time.Second)
+	}
+	switch action {
+	case ActionCopy:
+		// Copy content to clipboard
+		return a, a.copyToClipboard()
+	case ActionDelete:
 		// Enter delete confirmation mode if there are items
-		if len(a.Items) > 0 && a.LeftPane.Selected < len(a.Items) {
+		if a.ActivePane == LeftPane && len(a.Items) > 0 && a.LeftPane.Selected < len(a.Items) {
 			a.CurrentMode = DeleteMode
 			// Show the delete confirmation modal
 			selectedItem := a.Items[a.LeftPane.Selected]
 			a.Modal.Update(ShowDeleteConfirmation(selectedItem.Preview, a.LeftPane.Selected))
 		}
 		return a, nil
-	default:
-		// Handle other left pane specific keys here if needed in the future
+	case ActionCopyDelete:
+		if a.ActivePane == LeftPane {
+			return a, a.copyAndDelete()
+		}
+		return a, nil
+	case ActionOpenURL:
+		return a, a.openLink()
 	}
-
 	return a, nil
 }
 
@@ -481,6 +849,7 @@ func (a *AppModel) handleRightPaneKeys(key string) (tea.Model, tea.Cmd) {
 	case "/", "?":
 		// Enter search mode
 		a.Search.Update(StartSearchMsg{})
+		a.startIncrementalSearch()
 		a.CurrentMode = SearchMode
 		return a, nil
 	case "n":
@@ -533,14 +902,29 @@ func (a *AppModel) Init() tea.Cmd {
 
 // AppView renders the complete application using pure functions
 func AppView(model AppModel) (string, error) {
+	view, err := appView(model)
+	if model.A11y {
+		view = asciiView(view)
+	}
+	return view, err
+}
+
+// appView is AppView before accessibility mode's ASCII pass
+func appView(model AppModel) (string, error) {
 	if model.Width == 0 {
 		return "Initializing...", nil
 	}
 
+	// Below the minimum size the panes would overlap; show a notice instead.
+	// All state is kept, so growing the terminal restores the normal view.
+	if model.Width < MinWidth || model.Height < MinHeight {
+		return renderTooSmallView(model), nil
+	}
+
 	// Show help view if in help mode
 	if model.CurrentMode == HelpMode {
 		helpView := renderHelpView(model)
-		return helpView + "\n\n" + renderStatusLine(model), nil
+		return helpView + "\n" + announcementLine(model) + "\n" + renderStatusLine(model), nil
 	}
 
 	// Render normal view first (this will be the background for modal)
@@ -568,16 +952,41 @@ func renderNormalView(model AppModel) (string, error) {
 		selectedItem = model.Items[model.LeftPane.Selected]
 	}
 
-	leftPaneView, err := LeftPaneView(model.LeftPane, model.Items, leftPaneFocused)
+	leftPane := model.LeftPane
+	leftPane.Loading = model.Paging.loading
+	leftPane.Footer = model.Paging.loadedFooter(len(model.Items))
+	leftPane.Recent = model.RecentCopies.marked()
+	leftPane.Location = model.Location
+	if model.A11y && leftPane.Indicators == IndicatorsNerd {
+		// Icon fonts' private-use glyphs mean nothing to a screen reader
+		leftPane.Indicators = IndicatorsText
+	}
+	leftPaneView, err := LeftPaneView(leftPane, model.Items, leftPaneFocused)
 	if err != nil {
 		return "", err
 	}
 
-	rightPaneView, err := RightPaneView(model.RightPane, selectedItem, model.Search, rightPaneFocused, model.LeftPane.Selected)
+	rightPane := model.RightPane
+	rightPane.Location = model.Location
+	if model.Triage.Active {
+		triageView := TriagePaneView(rightPane, model.Items, model.LeftPane.Selected, rightPaneFocused)
+		return joinPanes(model, leftPaneView, triageView), nil
+	}
+	if model.Preview.deferring(selectedItem) {
+		rightPane.Deferred = true
+		if model.Preview.slow {
+			rightPane.Placeholder = "Loading…"
+		}
+	}
+	rightPaneView, err := RightPaneView(rightPane, selectedItem, model.Search, rightPaneFocused, model.LeftPane.Selected)
 	if err != nil {
 		return "", err
 	}
+	return joinPanes(model, leftPaneView, rightPaneView), nil
+}
 
+// joinPanes puts the rendered panes side by side above the status line
+func joinPanes(model AppModel, leftPaneView, rightPaneView string) string {
 	// Join left and right panes side by side
 	leftLines := strings.Split(leftPaneView, "\n")
 	rightLines := strings.Split(rightPaneView, "\n")
@@ -603,24 +1012,63 @@ func renderNormalView(model AppModel) (string, error) {
 		result.WriteString(leftLine + rightLine + "\n")
 	}
 
-	result.WriteString("\n" + renderStatusLine(model))
+	result.WriteString(announcementLine(model) + "\n" + renderStatusLine(model))
 
-	return result.String(), nil
+	return result.String()
 }
 
-// View method for tea.Model compatibility
-func (a *AppModel) View() string {
-	view, _ := AppView(*a)
+// View method for tea.Model compatibility. A view that fails to render,
+// even by panicking, is replaced by an error screen instead of a blank one.
+func (a *AppModel) View() (view string) {
+	defer func() {
+		if r := recover(); r != nil {
+			view = errorView(*a, fmt.Errorf("%v", r))
+		}
+	}()
+	view, err := AppView(*a)
+	if err != nil {
+		return errorView(*a, err)
+	}
 	return view
 }
 
+// errorView renders the screen shown when the view can't be rendered
+func errorView(model AppModel, err error) string {
+	lines := []string{"rem can't draw this screen: " + err.Error(), "", "Press q to quit."}
+	for i, line := range lines {
+		lines[i] = fitStatusLine(line, model.Width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTooSmallView renders the notice shown when the terminal is below
+// MinWidth x MinHeight, wrapped and centered in the available space
+func renderTooSmallView(model AppModel) string {
+	notice := fmt.Sprintf("Terminal too small (need %dx%d, have %dx%d)", MinWidth, MinHeight, model.Width, model.Height)
+	if model.Width <= 0 || model.Height <= 0 {
+		return notice
+	}
+	wrapped := lipgloss.NewStyle().Width(model.Width).Align(lipgloss.Center).Render(notice)
+	return lipgloss.Place(model.Width, model.Height, lipgloss.Center, lipgloss.Center, wrapped)
+}
+
 // renderStatusLine renders the bottom status line (pure function)
 func renderStatusLine(model AppModel) string {
 	var statusLine string
 
-	// Prioritize flash message if active and not expired
-	if model.FlashMessage != "" && time.Now().Before(model.FlashExpiry) {
-		statusLine = model.FlashMessage
+	// The horizontal offset is shown while lines are not wrapped
+	var suffix string
+	if model.RightPane.NoWrap && model.NumberBuffer == "" && !model.Search.IsActive() && model.CurrentMode != NoteMode {
+		suffix = fmt.Sprintf(" | nowrap, col %d", model.RightPane.HOffset+1)
+	}
+	if model.ReadOnly {
+		suffix += " | read-only"
+	}
+
+	// Prioritize flash message if active and not expired; accessibility
+	// mode shows it on the line above instead
+	if model.FlashMessage != "" && time.Now().Before(model.FlashExpiry) && !model.A11y {
+		statusLine = fitStatusLine(model.FlashMessage, model.Width)
 		// Use green color for flash messages
 		statusStyle := lipgloss.NewStyle().
 			Width(model.Width).
@@ -631,6 +1079,15 @@ func renderStatusLine(model AppModel) string {
 	if model.NumberBuffer != "" {
 		// Show number buffer input
 		statusLine = fmt.Sprintf("%s", model.NumberBuffer)
+	} else if model.CurrentMode == NoteMode {
+		statusLine = fmt.Sprintf("Note: %s (Enter to save, empty to clear, Esc to cancel)", model.NoteInput)
+	} else if query, ok := model.Search.History.Searching(); ok && model.Search.IsActive() {
+		// Show the ctrl+r query and the pattern it found, like readline
+		failing := ""
+		if model.Search.History.match < 0 {
+			failing = "failing "
+		}
+		statusLine = fmt.Sprintf("(%sreverse-i-search)`%s': %s (Enter to search, Esc to restore)", failing, query, model.Search.GetInput())
 	} else if model.Search.IsActive() {
 		// Show search input with cursor
 		statusLine = fmt.Sprintf("/%s", model.Search.GetInput())
@@ -662,26 +1119,83 @@ func renderStatusLine(model AppModel) string {
 		case NumberInputMode:
 			statusLine = fmt.Sprintf("Number Input: %s (Enter command or Esc to cancel)", model.NumberBuffer)
 		default: // NormalMode
-			statusLine = "Press z for help, q to quit"
+			statusLine = fitSegments(normalStatusSegments(model, timefmt.Now(model.Location)), model.Width-lipgloss.Width(suffix))
 		}
 	}
+	statusLine += suffix
 
 	statusStyle := lipgloss.NewStyle().
 		Width(model.Width)
 
-	return statusStyle.Render(statusLine)
+	return statusStyle.Render(fitStatusLine(statusLine, model.Width))
+}
+
+// statusSeparator separates the segments of the normal mode status line
+const statusSeparator = " · "
+
+// normalStatusSegments returns the normal mode status line segments, most
+// important first: the selected item's position, size, age (or date and
+// time, with AbsoluteTimes), and kind, then the help hint
+func normalStatusSegments(model AppModel, now time.Time) []string {
+	hint := "Press z for help, q to quit"
+	if model.LeftPane.Selected >= len(model.Items) || model.Items[model.LeftPane.Selected] == nil {
+		return []string{hint}
+	}
+	item := model.Items[model.LeftPane.Selected]
+
+	segments := []string{
+		fmt.Sprintf("Item %d/%d", model.LeftPane.Selected+1, len(model.Items)),
+		formatBytes(item.Size),
+	}
+	switch {
+	case item.Timestamp.IsZero():
+	case model.AbsoluteTimes:
+		segments = append(segments, timefmt.Format(item.Timestamp, now.Location(), timefmt.Minute))
+	default:
+		segments = append(segments, timefmt.Age(item.Timestamp, now))
+	}
+	if item.IsBinary {
+		segments = append(segments, "binary")
+	} else {
+		segments = append(segments, "text")
+	}
+	return append(segments, hint)
+}
+
+// fitSegments joins segments, dropping them from the right until the result
+// fits in width. The first segment is always kept.
+func fitSegments(segments []string, width int) string {
+	for len(segments) > 1 && lipgloss.Width(strings.Join(segments, statusSeparator)) > width {
+		segments = segments[:len(segments)-1]
+	}
+	return strings.Join(segments, statusSeparator)
+}
+
+// fitStatusLine truncates s so the status line never wraps onto a second line
+func fitStatusLine(s string, width int) string {
+	visible, ellipsis := truncateToWidth(s, width)
+	return visible + ellipsis
 }
 
 // renderHelpView renders the help content as a single pane (pure function)
 func renderHelpView(model AppModel) string {
+	keys := model.Keys
+	if keys == nil {
+		keys = DefaultKeymap()
+	}
+
 	helpContent := `rem - Enhanced Clipboard Queue Manager
 
 NAVIGATION COMMANDS:
   j, ↓        Move down (left pane: next item, right pane: scroll down)
   k, ↑        Move up (left pane: previous item, right pane: scroll up)
-  g           Go to top (with number: go to line N)
+  gg, g       Go to top (a lone g acts after a moment)
   G           Go to bottom
+  #G, #g      Go to item or line N (e.g., 5G)
   #j, #k      Jump N lines (e.g., 10j moves down 10 lines/items)
+  Ctrl+o      Back to the previously visited item and position
+  Ctrl+n      Forward again after Ctrl+o
+  '           Next recently copied item (marked ↑), newest first
 
 PANE SWITCHING:
   Tab         Toggle between left and right panes
@@ -690,19 +1204,32 @@ PANE SWITCHING:
   z           Toggle this help screen
 
 CONTENT VIEWING:
-  /pattern    Search for text pattern in current item
+  /pattern    Search all items (match counts shown in list)
+  ↑, ↓        While typing a pattern, recall earlier patterns
+  Ctrl+r      While typing a pattern, search earlier patterns
   n           Next search match
   N           Previous search match
   Ctrl+u      Page up (right pane)
   Ctrl+d      Page down (right pane)
   Ctrl+b      Page up (full screen)
   Ctrl+f      Page down (full screen)
+  w           Toggle line wrapping
+  t           Toggle triage: the first lines of the selected item and those
+              below it (Enter returns to the full view)
+  H, L        Scroll left/right when not wrapping (also Shift+←/→)
+  Ctrl+t      Toggle coloring of items that look like diffs
+  Ctrl+g      Toggle Today/Yesterday/This week/Older headers in the list
+  T           Toggle the status line between ages and dates
 
 CLIPBOARD:
-  c           Copy current item content to clipboard
+  ` + helpKey(keys, ActionCopy) + `Copy current item content to clipboard
+  ` + helpKey(keys, ActionCopyDelete) + `Copy selected item, then delete it (left pane only)
+  ` + helpKey(keys, ActionOpenURL) + `Open the link in view, or pick one of several
 
 HISTORY MANAGEMENT:
-  d           Delete selected item (left pane only)
+  ` + helpKey(keys, ActionDelete) + `Delete selected item (left pane only)
+  N           Edit selected item's note (left pane only)
+  b           Move selected item to the top of the queue (left pane only)
 
 QUEUE BEHAVIOR:
   Index 0     Most recent item (top of queue)
@@ -726,6 +1253,11 @@ Press z again to return to normal view.`
 	return helpStyle.Render(helpContent)
 }
 
+// helpKey formats the key bound to action as a help screen key column
+func helpKey(keys Keymap, action Action) string {
+	return fmt.Sprintf("%-12s", keys.KeyFor(action))
+}
+
 // handleNumberMode processes digit input and backspace for vim-style number prefixes
 func handleNumberMode(currentBuffer string, targetPane PaneType, activePane PaneType, key string) (newBuffer string, handled bool) {
 	// Handle digit input
@@ -753,20 +1285,38 @@ func isMovementCommand(key string) bool {
 	return false
 }
 
+// executeCounted executes a movement typed after a count in the active pane.
+// NG and Ng both jump to item or line N, as does Ngg: g acts at once and
+// its repeat is swallowed.
+func (a *AppModel) executeCounted(count int, key string) (tea.Model, tea.Cmd) {
+	if key != "g" && key != "G" {
+		return a.executeCommand(count, key, a.ActivePane)
+	}
+	model, cmd := a.executeCommand(count, "g", a.ActivePane)
+	if key == "g" {
+		cmd = tea.Batch(cmd, a.startPending("g", true))
+	}
+	return model, cmd
+}
+
 // executeCommand executes a command with a number multiplier on the specified pane
 func (a *AppModel) executeCommand(multiplier int, key string, pane PaneType) (tea.Model, tea.Cmd) {
 	maxIndex := len(a.Items) - 1
 
 	if pane == LeftPane {
+		previous := a.LeftPane.Selected
+		defer a.releaseIfDeselected(previous)
+		from, ok := a.currentEntry()
+
 		switch key {
 		case "up", "k":
 			newCursor := max(a.LeftPane.Cursor-multiplier, 0)
 			a.LeftPane.Update(JumpToIndexMsg{Index: newCursor, MaxIndex: maxIndex})
-			a.RightPane.Update(UpdateContentMsg{})
+			return a, tea.Batch(a.startCursorMove(from, ok), a.deferContent())
 		case "down", "j":
 			newCursor := min(a.LeftPane.Cursor+multiplier, maxIndex)
 			a.LeftPane.Update(JumpToIndexMsg{Index: newCursor, MaxIndex: maxIndex})
-			a.RightPane.Update(UpdateContentMsg{})
+			return a, tea.Batch(a.startCursorMove(from, ok), a.deferContent())
 		case "g":
 			if multiplier > 1 {
 				jumpIndex := min(max(multiplier-1, 0), maxIndex)
@@ -775,9 +1325,18 @@ func (a *AppModel) executeCommand(multiplier int, key string, pane PaneType) (te
 				a.LeftPane.Update(GoToTopMsg{})
 			}
 			a.RightPane.Update(UpdateContentMsg{})
+			a.syncSearchToSelection()
+			a.recordJump(from, ok)
 		case "G":
 			a.LeftPane.Update(GoToBottomMsg{MaxIndex: maxIndex})
 			a.RightPane.Update(UpdateContentMsg{})
+			a.syncSearchToSelection()
+			a.recordJump(from, ok)
+			if a.Paging.more() {
+				// The rest of the pages load first; handleLoadMore lands on the last
+				a.Paging.toBottom = true
+				return a, a.loadMore()
+			}
 		}
 	} else { // RightPane
 		var maxScroll int
@@ -802,6 +1361,13 @@ func (a *AppModel) executeCommand(multiplier int, key string, pane PaneType) (te
 				a.RightPane.Update(ScrollToTopMsg{})
 			}
 		case "G":
+			// The end of the content may not have been wrapped yet
+			if a.LeftPane.Selected < len(a.Items) {
+				selectedItem := a.Items[a.LeftPane.Selected]
+				if err := selectedItem.scanToEnd(); err == nil {
+					maxScroll = getMaxScroll(a.RightPane, selectedItem)
+				}
+			}
 			a.RightPane.Update(ScrollToBottomMsg{MaxScroll: maxScroll})
 		}
 	}
@@ -809,6 +1375,126 @@ func (a *AppModel) executeCommand(multiplier int, key string, pane PaneType) (te
 	return a, nil
 }
 
+// toggleWrap switches the right pane between wrapped and nowrap display,
+// keeping the top visible line in view and re-resolving search matches
+// against the new line layout
+func (a *AppModel) toggleWrap() {
+	if a.LeftPane.Selected >= len(a.Items) || a.Items[a.LeftPane.Selected] == nil {
+		a.RightPane.Update(ToggleWrapMsg{})
+		return
+	}
+	item := a.Items[a.LeftPane.Selected]
+	if item.IsBinary {
+		a.RightPane.Update(ToggleWrapMsg{})
+		return
+	}
+
+	// Remember where the top line starts in the content
+	availableHeight := max(a.RightPane.contentHeight(), 1)
+	item.ViewPos = a.RightPane.ViewPos
+	item.UpdateWrappedLines(a.RightPane.wrapWidth(), availableHeight)
+	offset, hasOffset := item.offsetAt(a.RightPane.ViewPos)
+
+	a.RightPane.Update(ToggleWrapMsg{})
+	if err := item.UpdateWrappedLines(a.RightPane.wrapWidth(), availableHeight); err != nil {
+		return
+	}
+	if hasOffset {
+		if line, err := item.displayLineForOffset(offset); err == nil {
+			a.RightPane.ViewPos = min(line, getMaxScroll(a.RightPane, item))
+		}
+	}
+	if a.Search.GetPattern() != "" {
+		current := a.Search.CurrentMatch
+		a.Search.SetMatches(item.SearchMatches)
+		if current >= 0 && current < len(item.SearchMatches) {
+			a.Search.CurrentMatch = current
+		}
+	}
+}
+
+// scrollHorizontal scrolls the right pane by direction steps in nowrap mode
+func (a *AppModel) scrollHorizontal(direction int) {
+	if !a.RightPane.NoWrap || a.LeftPane.Selected >= len(a.Items) {
+		return
+	}
+	step := a.RightPane.HScrollStep
+	if step <= 0 {
+		step = DefaultHScrollStep
+	}
+	maxOffset := getMaxHOffset(a.RightPane, a.Items[a.LeftPane.Selected])
+	a.RightPane.Update(ScrollHorizontalMsg{Cells: direction * step, MaxOffset: maxOffset})
+}
+
+// searchAllItems runs the search pattern against every text item, recording
+// per-item match counts. An empty pattern clears all previous results.
+func (a *AppModel) searchAllItems(pattern string) {
+	for i, item := range a.Items {
+		if item == nil || (item.IsBinary && pattern != "") {
+			continue
+		}
+		item.setTabWidth(a.RightPane.TabWidth)
+		item.setLatin1(a.RightPane.Latin1)
+		item.performSearch(pattern)
+		a.releaseIfDeselected(i)
+	}
+}
+
+// releaseIfDeselected closes the reader of the item at index unless it is
+// selected; reopenable items are opened again on demand
+func (a *AppModel) releaseIfDeselected(index int) {
+	if index == a.LeftPane.Selected || index < 0 || index >= len(a.Items) || a.Items[index] == nil {
+		return
+	}
+	a.Items[index].release()
+}
+
+// SetItemOps sets the operations used to delete and reopen items
+func (a *AppModel) SetItemOps(ops ItemOps) {
+	a.ops = ops
+	for _, item := range a.Items {
+		item.ops = ops
+	}
+}
+
+// Close closes the content readers of all items
+func (a *AppModel) Close() error {
+	var errs []error
+	for _, item := range a.Items {
+		if item == nil {
+			continue
+		}
+		if err := item.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// syncSearchToSelection points the search model at the selected item's
+// matches and scrolls the right pane to its first match
+func (a *AppModel) syncSearchToSelection() {
+	if a.Search.GetPattern() == "" || a.LeftPane.Selected >= len(a.Items) {
+		return
+	}
+	selectedItem := a.Items[a.LeftPane.Selected]
+	if selectedItem == nil || selectedItem.IsBinary {
+		a.Search.SetMatches(nil)
+		return
+	}
+
+	if selectedItem.SearchPattern != a.Search.GetPattern() {
+		selectedItem.setTabWidth(a.RightPane.TabWidth)
+		selectedItem.setLatin1(a.RightPane.Latin1)
+		selectedItem.performSearch(a.Search.GetPattern())
+	}
+	a.Search.SetMatches(selectedItem.SearchMatches)
+
+	if matchLine := a.Search.GetCurrentMatchLine(); matchLine >= 0 {
+		a.RightPane.ViewPos = scrollToMatch(a.RightPane, selectedItem, matchLine)
+	}
+}
+
 // setFlashMessage sets a flash message that will disappear after the specified duration
 func (a *AppModel) setFlashMessage(message string, duration time.Duration) tea.Cmd {
 	a.FlashMessage = message
@@ -818,6 +1504,75 @@ func (a *AppModel) setFlashMessage(message string, duration time.Duration) tea.C
 	})
 }
 
+// deleteSelected removes the selected item from storage and the list
+func (a *AppModel) deleteSelected() error {
+	selectedItem := a.Items[a.LeftPane.Selected]
+
+	// Delete from persistent storage if ItemOps are set
+	if a.ops != nil {
+		if err := a.ops.Delete(selectedItem.ID); err != nil {
+			return err
+		}
+	}
+	a.removeSelected()
+	return nil
+}
+
+// removeSelected removes the selected item from the list, leaving storage
+// alone
+func (a *AppModel) removeSelected() {
+	deletedIndex := a.LeftPane.Selected
+	selectedItem := a.Items[deletedIndex]
+
+	// Remove item from the Items slice; its reader is no longer needed
+	selectedItem.Close()
+	a.Items = append(a.Items[:deletedIndex], a.Items[deletedIndex+1:]...)
+	if a.Paging.Total > 0 {
+		a.Paging.Total--
+	}
+
+	// Adjust cursor position if needed
+	if len(a.Items) == 0 {
+		// Queue is now empty
+		a.LeftPane.Cursor = 0
+		a.LeftPane.Selected = 0
+	} else if a.LeftPane.Selected >= len(a.Items) {
+		// Cursor was at the last item, move it back
+		a.LeftPane.Cursor = len(a.Items) - 1
+		a.LeftPane.Selected = a.LeftPane.Cursor
+	}
+	// else: cursor stays at the same index, now pointing to the next item
+
+	// Update the right pane content
+	a.RightPane.Update(UpdateContentMsg{})
+	a.syncSearchToSelection()
+}
+
+// copyAndDelete copies the selected item to the clipboard and, only if the
+// copy succeeded, deletes it
+func (a *AppModel) copyAndDelete() tea.Cmd {
+	if a.LeftPane.Selected >= len(a.Items) || len(a.Items) == 0 {
+		return a.setFlashMessage("No item selected", 2*time.Second)
+	}
+	asked, warning := a.guardOverwrite(ActionCopyDelete)
+	if asked {
+		return nil
+	}
+	return a.copyDeleteSelected(warning)
+}
+
+// copyDeleteSelected is copyAndDelete once the clipboard may be overwritten
+func (a *AppModel) copyDeleteSelected(warning string) tea.Cmd {
+	selectedItem := a.Items[a.LeftPane.Selected]
+	if err := a.writeClipboard(selectedItem); err != nil {
+		return a.setFlashMessage(err.Error(), 2*time.Second)
+	}
+	if err := a.deleteSelected(); err != nil {
+		return a.setFlashMessage(fmt.Sprintf("Copied, but failed to delete item: %v", err), 2*time.Second)
+	}
+	return a.setFlashMessage(withWarning(fmt.Sprintf("Copied and removed %s", selectedItem.Preview), warning), 2*time.Second)
+}
+
 // copyToClipboard copies the current item's content to the clipboard
 func (a *AppModel) copyToClipboard() tea.Cmd {
 	// Get the currently selected item
@@ -830,31 +1585,47 @@ func (a *AppModel) copyToClipboard() tea.Cmd {
 		return a.setFlashMessage("No item selected", 2*time.Second)
 	}
 
-	// Save current position
-	currentPos, err := selectedItem.Content.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return a.setFlashMessage(fmt.Sprintf("Error saving position: %v", err), 2*time.Second)
+	asked, warning := a.guardOverwrite(ActionCopy)
+	if asked {
+		return nil
 	}
+	return a.copySelected(warning)
+}
 
-	// Seek to start
-	if _, err := selectedItem.Content.Seek(0, io.SeekStart); err != nil {
-		return a.setFlashMessage(fmt.Sprintf("Error seeking to start: %v", err), 2*time.Second)
+// copySelected is copyToClipboard once the clipboard may be overwritten
+func (a *AppModel) copySelected(warning string) tea.Cmd {
+	selectedItem := a.Items[a.LeftPane.Selected]
+	if err := a.writeClipboard(selectedItem); err != nil {
+		return a.setFlashMessage(err.Error(), 2*time.Second)
 	}
+	a.RecentCopies.record(selectedItem.ID)
+
+	// Show success message with size
+	return a.setFlashMessage(withWarning(fmt.Sprintf("Copied %d bytes to clipboard", selectedItem.Size), warning), 2*time.Second)
+}
 
-	// Write to clipboard - stream directly without reading into memory
-	if err := a.clipboard.Write(selectedItem.Content); err != nil {
-		// Try to restore position even if write failed
-		selectedItem.Content.Seek(currentPos, io.SeekStart)
-		return a.setFlashMessage(fmt.Sprintf("Error writing to clipboard: %v", err), 2*time.Second)
+// writeClipboard streams item's content to the clipboard through a reader of
+// its own. The error text is suitable for a flash message.
+func (a *AppModel) writeClipboard(item *StackItem) error {
+	// Clipboards can't hold arbitrarily large content
+	if a.ClipboardMaxBytes > 0 && item.Size > a.ClipboardMaxBytes {
+		return fmt.Errorf("Too large for clipboard (%s, limit %s)", formatBytes(item.Size), formatBytes(a.ClipboardMaxBytes))
 	}
 
-	// Restore position
-	if _, err := selectedItem.Content.Seek(currentPos, io.SeekStart); err != nil {
-		return a.setFlashMessage(fmt.Sprintf("Error restoring position: %v", err), 2*time.Second)
+	reader, err := item.NewReader()
+	if err != nil {
+		return fmt.Errorf("Error opening content: %w", err)
 	}
+	defer reader.Close()
 
-	// Show success message with size
-	return a.setFlashMessage(fmt.Sprintf("Copied %d bytes to clipboard", selectedItem.Size), 2*time.Second)
+	// Write to clipboard - stream directly without reading into memory,
+	// hashing on the way so a later copy knows the clipboard holds this
+	hash := sha256.New()
+	if err := a.clipboard.Write(io.TeeReader(reader, hash)); err != nil {
+		return fmt.Errorf("Error writing to clipboard: %w", err)
+	}
+	a.lastCopied = hex.EncodeToString(hash.Sum(nil))
+	return nil
 }
 
 // SetItems updates the items list in the app model
@@ -2,12 +2,17 @@ package tui
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/yiblet/rem/internal/clipboard/mockboard"
+	"github.com/yiblet/rem/internal/queue"
+	"github.com/yiblet/rem/internal/store/memstore"
 )
 
 // newTestClipboard creates a mock clipboard for testing
@@ -73,6 +78,78 @@ func TestAppModel_WindowResize(t *testing.T) {
 	}
 }
 
+func TestAppView_TooSmall(t *testing.T) {
+	sizes := []struct{ width, height int }{
+		{20, 8}, {39, 30}, {100, 9}, {1, 1}, {5, 40},
+	}
+	for _, size := range sizes {
+		items := []*StackItem{{
+			Content: NewStringReadSeekCloser("some content\nmore content"),
+			Preview: "a rather long item title that has to be truncated",
+		}}
+		app := NewAppModel(items, newTestClipboard())
+		app.Update(tea.WindowSizeMsg{Width: size.width, Height: size.height})
+
+		view, err := AppView(app)
+		if err != nil {
+			t.Fatalf("AppView at %dx%d error: %v", size.width, size.height, err)
+		}
+		// The notice may be wrapped, so compare without whitespace
+		flat := strings.Join(strings.Fields(view), "")
+		want := fmt.Sprintf("Terminaltoosmall(need40x10,have%dx%d)", size.width, size.height)
+		if !strings.Contains(flat, want) {
+			t.Errorf("AppView at %dx%d = %q, want the too-small notice", size.width, size.height, view)
+		}
+		if strings.Contains(view, "Content [") {
+			t.Errorf("AppView at %dx%d should not render the panes", size.width, size.height)
+		}
+	}
+}
+
+func TestAppView_ResizeRecovery(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("first content"), Preview: "first"},
+		{Content: NewStringReadSeekCloser("second content"), Preview: "second"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+
+	app.Update(tea.WindowSizeMsg{Width: 20, Height: 8})
+	view, _ := AppView(app)
+	if !strings.Contains(view, "too small") {
+		t.Fatalf("Expected the too-small notice, got %q", view)
+	}
+
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	view, _ = AppView(app)
+	if !strings.Contains(view, "Content [1]: second") {
+		t.Errorf("Expected the normal view with the selection kept, got %q", view)
+	}
+	if got := len(strings.Split(view, "\n")); got > 30 {
+		t.Errorf("Normal view has %d lines, want at most 30", got)
+	}
+}
+
+func TestAppView_MinimumSizeFitsStatusLine(t *testing.T) {
+	items := []*StackItem{{
+		Content: NewStringReadSeekCloser("content"),
+		Preview: "a rather long item title that has to be truncated",
+	}}
+	app := NewAppModel(items, newTestClipboard())
+	app.Update(tea.WindowSizeMsg{Width: MinWidth, Height: MinHeight})
+	app.FlashMessage = strings.Repeat("a very long flash message ", 5)
+	app.FlashExpiry = time.Now().Add(time.Minute)
+
+	status := renderStatusLine(app)
+	if strings.Contains(status, "\n") || lipgloss.Width(status) > MinWidth {
+		t.Errorf("Status line should fit on one line of %d columns, got %q", MinWidth, status)
+	}
+	if _, err := AppView(app); err != nil {
+		t.Errorf("AppView at the minimum size error: %v", err)
+	}
+}
+
 func TestAppModel_TabSwitching(t *testing.T) {
 	items := []*StackItem{{
 		Content: NewStringReadSeekCloser("Test item content"),
@@ -483,6 +560,7 @@ func TestAppModel_PaneBorderAlignment(t *testing.T) {
 		contentSize int
 		height      int
 		scrollPos   int
+		noScrollbar bool
 	}{
 		{
 			name:        "short content no scroll",
@@ -514,6 +592,13 @@ func TestAppModel_PaneBorderAlignment(t *testing.T) {
 			height:      25,
 			scrollPos:   0,
 		},
+		{
+			name:        "long content without scrollbar",
+			contentSize: 30,
+			height:      15,
+			scrollPos:   5,
+			noScrollbar: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -542,10 +627,11 @@ func TestAppModel_PaneBorderAlignment(t *testing.T) {
 			app.LeftPane.Update(ResizeLeftPaneMsg{Width: app.LeftWidth, Height: app.Height})
 			app.RightPane.Update(ResizeRightPaneMsg{Width: app.RightWidth, Height: app.Height})
 			app.RightPane.Update(UpdateContentMsg{})
+			app.RightPane.Scrollbar = !tc.noScrollbar
 
 			// Set scroll position
 			availableHeight := app.RightPane.Height - 6
-			content.UpdateWrappedLines(app.RightWidth-6, availableHeight)
+			content.UpdateWrappedLines(app.RightPane.wrapWidth(), availableHeight)
 			maxScroll := 0
 			if len(content.Lines) > availableHeight {
 				maxScroll = len(content.Lines) - availableHeight
@@ -577,6 +663,13 @@ func TestAppModel_PaneBorderAlignment(t *testing.T) {
 					len(leftLines), len(rightLines), tc.contentSize, tc.height, tc.scrollPos)
 			}
 
+			// The scrollbar column must not push the right border out
+			for i, line := range rightLines {
+				if width := lipgloss.Width(line); width != app.RightWidth {
+					t.Errorf("Right pane line %d is %d columns wide, want %d", i, width, app.RightWidth)
+				}
+			}
+
 			// Verify that the complete AppView renders properly
 			appView, err := AppView(app)
 			if err != nil {
@@ -1059,6 +1152,49 @@ func TestAppModel_DeleteConfirm(t *testing.T) {
 	}
 }
 
+func TestAppModel_DropsItemDeletedElsewhere(t *testing.T) {
+	// The item is deleted while its reader is open, or after it was
+	// released and before it is reopened
+	for _, released := range []bool{false, true} {
+		st := memstore.NewMemoryStore()
+		qm, err := queue.NewQueueManager(st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := qm.Enqueue(strings.NewReader("Item 1"), "Item 1"); err != nil {
+			t.Fatal(err)
+		}
+		gone, err := qm.Enqueue(strings.NewReader("deleted elsewhere"), "gone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		items := []*StackItem{{ID: gone.ID, Preview: "gone"}, {ID: gone.ID - 1, Preview: "Item 1"}}
+		if !released {
+			if items[0].Content, err = st.History().GetContent(gone.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		app := NewAppModel(items, newTestClipboard())
+		app.SetItemOps(qm.ByID())
+
+		// Another rem deletes the item
+		if err := st.History().Delete(gone.ID); err != nil {
+			t.Fatal(err)
+		}
+		app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+		AppView(app)
+
+		press(&app, "j")
+		if len(app.Items) != 1 || app.Items[0].Preview != "Item 1" {
+			t.Fatalf("released %v: expected the deleted item dropped, got %d items", released, len(app.Items))
+		}
+		if app.FlashMessage != "Item was deleted by another rem" {
+			t.Errorf("released %v: unexpected flash message %q", released, app.FlashMessage)
+		}
+		st.Close()
+	}
+}
+
 func TestAppModel_DeleteLastItem(t *testing.T) {
 	items := []*StackItem{
 		{Content: NewStringReadSeekCloser("Item 0"), Preview: "Item 0"},
@@ -1193,6 +1329,27 @@ func TestAppModel_CopyToClipboard(t *testing.T) {
 	}
 }
 
+func TestAppModel_CopyRefusesOversizedItem(t *testing.T) {
+	testContent := "Test clipboard content"
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser(testContent), Preview: "Test item", Size: int64(len(testContent))},
+	}
+
+	clip := newTestClipboard()
+	model := NewAppModel(items, clip)
+	model.ClipboardMaxBytes = 4
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	updatedApp := newModel.(*AppModel)
+
+	if !strings.Contains(updatedApp.FlashMessage, "Too large for clipboard") {
+		t.Errorf("Expected a too-large flash message, got '%s'", updatedApp.FlashMessage)
+	}
+	if len(clip.GetData()) != 0 {
+		t.Errorf("Expected clipboard to be untouched, got %q", clip.GetData())
+	}
+}
+
 func TestAppModel_CopyFromRightPane(t *testing.T) {
 	testContent := "Right pane test content"
 	items := []*StackItem{
@@ -1376,3 +1533,612 @@ func TestAppModel_DeleteModalRendering(t *testing.T) {
 		t.Errorf("Delete modal has %d lines, expected %d (same as normal view)", len(lines), len(normalLines))
 	}
 }
+
+func TestAppModel_SearchAnnotatesAllItems(t *testing.T) {
+	var long strings.Builder
+	for i := 0; i < 100; i++ {
+		long.WriteString(fmt.Sprintf("filler line %d\n", i))
+	}
+	long.WriteString("the needle is here\n")
+
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("nothing relevant"), Preview: "first"},
+		{Content: NewStringReadSeekCloser(long.String()), Preview: "second"},
+		{Content: NewStringReadSeekCloser("needle needle\nneedle"), Preview: "needle third"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.Init()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "needle" {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	for i, want := range []int{0, 1, 3} {
+		if got := app.Items[i].MatchCount; got != want {
+			t.Errorf("Item %d MatchCount = %d, want %d", i, got, want)
+		}
+	}
+	if app.Search.HasMatches() {
+		t.Error("Selected item has no matches; search model should report none")
+	}
+
+	view, err := LeftPaneView(app.LeftPane, app.Items, true)
+	if err != nil {
+		t.Fatalf("LeftPaneView returned error: %v", err)
+	}
+	if !strings.Contains(view, "(3)") || !strings.Contains(view, "(1)") {
+		t.Errorf("Expected match badges in left pane, got:\n%s", view)
+	}
+
+	// Selecting an annotated item jumps straight to its first match
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+
+	if !app.Search.HasMatches() {
+		t.Fatal("Expected search matches for the newly selected item")
+	}
+	matchLine := app.Search.GetCurrentMatchLine()
+	if matchLine != 100 {
+		t.Errorf("Expected first match on line 100, got %d", matchLine)
+	}
+	if app.RightPane.ViewPos == 0 || app.RightPane.ViewPos > matchLine {
+		t.Errorf("Expected right pane scrolled toward the match, ViewPos = %d", app.RightPane.ViewPos)
+	}
+
+	// Clearing the search removes the badges
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	for i, item := range app.Items {
+		if item.MatchCount != 0 {
+			t.Errorf("Item %d MatchCount = %d after clearing search", i, item.MatchCount)
+		}
+	}
+}
+
+// trackedReader is a content reader that records whether it was closed
+type trackedReader struct {
+	*StringReadSeekCloser
+	closed bool
+}
+
+func (r *trackedReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+// fakeItemOps is an in-memory ItemOps that records every call. Content
+// hands out trackedReaders so tests can check they are closed.
+type fakeItemOps struct {
+	mu        sync.Mutex // Content may be called concurrently
+	contents  map[uint]string
+	opened    []*trackedReader
+	deleted   []uint
+	renamed   map[uint]string
+	notes     map[uint]string
+	bumped    []uint
+	deleteErr error // returned by the next Delete, then cleared
+}
+
+func newFakeItemOps() *fakeItemOps {
+	return &fakeItemOps{contents: map[uint]string{}, renamed: map[uint]string{}, notes: map[uint]string{}}
+}
+
+// item adds content under id and returns a StackItem for it with an open reader
+func (f *fakeItemOps) item(id uint, content string) *StackItem {
+	f.contents[id] = content
+	reader, _ := f.Content(id)
+	return &StackItem{ID: id, Content: reader, Preview: content, Size: int64(len(content))}
+}
+
+func (f *fakeItemOps) Delete(id uint) error {
+	if err := f.deleteErr; err != nil {
+		f.deleteErr = nil
+		return err
+	}
+	if _, ok := f.contents[id]; !ok {
+		return fmt.Errorf("item %d not found", id)
+	}
+	delete(f.contents, id)
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeItemOps) Content(id uint) (io.ReadSeekCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	content, ok := f.contents[id]
+	if !ok {
+		return nil, fmt.Errorf("item %d not found", id)
+	}
+	r := &trackedReader{StringReadSeekCloser: NewStringReadSeekCloser(content)}
+	f.opened = append(f.opened, r)
+	return r, nil
+}
+
+func (f *fakeItemOps) Rename(id uint, title string) error {
+	if _, ok := f.contents[id]; !ok {
+		return fmt.Errorf("item %d not found", id)
+	}
+	f.renamed[id] = title
+	return nil
+}
+
+func (f *fakeItemOps) SetNote(id uint, note string) error {
+	if _, ok := f.contents[id]; !ok {
+		return fmt.Errorf("item %d not found", id)
+	}
+	f.notes[id] = note
+	return nil
+}
+
+func (f *fakeItemOps) Bump(id uint) error {
+	if _, ok := f.contents[id]; !ok {
+		return fmt.Errorf("item %d not found", id)
+	}
+	f.bumped = append(f.bumped, id)
+	return nil
+}
+
+func (f *fakeItemOps) IsDeleted(err error) bool {
+	return false
+}
+
+func (f *fakeItemOps) openCount() int {
+	n := 0
+	for _, r := range f.opened {
+		if !r.closed {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAppModel_ClosesReaders(t *testing.T) {
+	tracker := newFakeItemOps()
+	var items []*StackItem
+	for i, content := range []string{"alpha one", "beta two", "gamma three", "delta four"} {
+		items = append(items, tracker.item(uint(i+1), content))
+	}
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(tracker)
+	model.UpdateMockSize(120, 30)
+	model.Init()
+	model.View()
+
+	press := func(keys ...string) {
+		for _, key := range keys {
+			var msg tea.KeyMsg
+			if key == "enter" {
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			} else {
+				msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+			}
+			updated, _ := model.Update(msg)
+			model = updated.(Model)
+			model.View()
+		}
+	}
+
+	// Switching away from an item closes its reader
+	press("j", "j")
+	if !tracker.opened[0].closed || !tracker.opened[1].closed || tracker.opened[2].closed {
+		t.Errorf("Expected readers of items 0 and 1 closed and item 2 open after switching")
+	}
+
+	// A global search reads every item but releases the unselected ones
+	press("l", "/", "o", "enter", "h", "k")
+	if n := tracker.openCount(); n > 1 {
+		t.Errorf("Expected at most 1 open reader after searching, got %d", n)
+	}
+
+	// Content of a released item is reopened on demand
+	content, err := items[0].GetFullContent()
+	if err != nil || content != "alpha one" {
+		t.Errorf("GetFullContent after release = %q, %v", content, err)
+	}
+
+	press("q")
+	if err := model.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	for i, r := range tracker.opened {
+		if !r.closed {
+			t.Errorf("Reader %d was never closed", i)
+		}
+	}
+	if len(tracker.opened) <= len(items) {
+		t.Errorf("Expected released readers to be reopened, only %d opened", len(tracker.opened))
+	}
+}
+
+func TestStackItem_CloseWithoutReopen(t *testing.T) {
+	reader := &trackedReader{StringReadSeekCloser: NewStringReadSeekCloser("content")}
+	item := &StackItem{Content: reader}
+
+	if err := item.release(); err != nil || reader.closed {
+		t.Fatal("release must not close an item that cannot be reopened")
+	}
+	if err := item.Close(); err != nil || !reader.closed {
+		t.Fatal("Close should close the reader")
+	}
+	if _, err := item.GetFullContent(); err == nil {
+		t.Error("Expected an error reading a closed item without ItemOps")
+	}
+	if err := item.Close(); err != nil {
+		t.Errorf("Close should be idempotent, got %v", err)
+	}
+}
+
+func TestAppModel_CopyAndDelete(t *testing.T) {
+	ops := newFakeItemOps()
+	first, second := ops.item(7, "first content"), ops.item(9, "second content")
+	first.Preview, second.Preview = "first", "second"
+	clip := newTestClipboard()
+	app := NewAppModel([]*StackItem{first, second}, clip)
+	app.SetItemOps(ops)
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+
+	if string(clip.GetData()) != "first content" {
+		t.Errorf("Expected clipboard to hold the item, got %q", clip.GetData())
+	}
+	if len(ops.deleted) != 1 || ops.deleted[0] != 7 || len(app.Items) != 1 || app.Items[0].Preview != "second" {
+		t.Errorf("Expected the copied item to be deleted by ID, items: %d, deleted: %v", len(app.Items), ops.deleted)
+	}
+	if app.FlashMessage != "Copied and removed first" {
+		t.Errorf("Unexpected flash message %q", app.FlashMessage)
+	}
+	if app.CurrentMode != NormalMode {
+		t.Errorf("Copy+delete should not ask for confirmation, mode = %v", app.CurrentMode)
+	}
+}
+
+func TestAppModel_Bump(t *testing.T) {
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for id := uint(1); id <= 4; id++ {
+		item := ops.item(id, fmt.Sprintf("content %d", id))
+		item.Preview = fmt.Sprintf("item %d", id)
+		items = append(items, item)
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.SetItemOps(ops)
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if len(ops.bumped) != 1 || ops.bumped[0] != 3 {
+		t.Errorf("Expected item 3 bumped by ID, got %v", ops.bumped)
+	}
+	var order []uint
+	for _, item := range app.Items {
+		order = append(order, item.ID)
+	}
+	if fmt.Sprint(order) != "[3 1 2 4]" {
+		t.Errorf("Expected the bumped item first and the rest in order, got %v", order)
+	}
+	if app.LeftPane.Selected != 0 || app.LeftPane.Cursor != 0 {
+		t.Errorf("Expected the cursor to follow the item to index 0, got %d", app.LeftPane.Selected)
+	}
+	if app.FlashMessage != "Moved to top: item 3" {
+		t.Errorf("Unexpected flash message %q", app.FlashMessage)
+	}
+
+	// A failed bump leaves the order alone
+	delete(ops.contents, 4)
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if app.Items[3].ID != 4 || app.LeftPane.Selected != 3 || !strings.HasPrefix(app.FlashMessage, "Failed to bump") {
+		t.Errorf("Expected item 4 to stay put after a failed bump, flash %q", app.FlashMessage)
+	}
+}
+
+func TestAppModel_ReadOnly(t *testing.T) {
+	ops := newFakeItemOps()
+	items := []*StackItem{ops.item(1, "first"), ops.item(2, "second")}
+	clip := newTestClipboard()
+	app := NewAppModel(items, clip)
+	app.SetItemOps(ops)
+	app.ReadOnly = true
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	// Keys that change items are refused with a message
+	for _, key := range []string{"d", "x", "N", "b"} {
+		app.FlashMessage = ""
+		press(&app, key)
+		if app.CurrentMode != NormalMode || app.FlashMessage != readOnlyMessage {
+			t.Errorf("%s in read-only mode: mode %v, flash %q", key, app.CurrentMode, app.FlashMessage)
+		}
+	}
+	if len(ops.deleted) != 0 || len(ops.bumped) != 0 || len(ops.notes) != 0 || len(app.Items) != 2 {
+		t.Errorf("read-only mode changed items: deleted %v, bumped %v, notes %v", ops.deleted, ops.bumped, ops.notes)
+	}
+
+	// Copying still works, and the status line carries a badge
+	press(&app, "c")
+	if string(clip.GetData()) != "first" {
+		t.Errorf("expected c to copy in read-only mode, clipboard %q", clip.GetData())
+	}
+	app.FlashMessage = ""
+	if statusLine := renderStatusLine(app); !strings.Contains(statusLine, "read-only") {
+		t.Errorf("expected a read-only badge, got %q", statusLine)
+	}
+}
+
+func TestAppModel_CopyAndDeleteKeepsItemOnCopyFailure(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("too big"), Preview: "big", Size: 100},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.ClipboardMaxBytes = 10
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+
+	if len(app.Items) != 1 {
+		t.Error("Item must not be deleted when the copy fails")
+	}
+	if !strings.HasPrefix(app.FlashMessage, "Too large for clipboard") {
+		t.Errorf("Expected copy error flash, got %q", app.FlashMessage)
+	}
+}
+
+func TestAppModel_RemappedKeys(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("content"), Preview: "item", Size: 7},
+	}
+	clip := newTestClipboard()
+	app := NewAppModel(items, clip)
+
+	keys, err := NewKeymap(map[string]string{"key_copy": "y", "key_delete": "D"})
+	if err != nil {
+		t.Fatalf("NewKeymap failed: %v", err)
+	}
+	app.Keys = keys
+
+	// The old copy key no longer copies
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if len(clip.GetData()) != 0 {
+		t.Error("Expected 'c' to be unbound after remapping copy")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if string(clip.GetData()) != "content" {
+		t.Errorf("Expected remapped key to copy, clipboard = %q", clip.GetData())
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	if app.CurrentMode != DeleteMode {
+		t.Errorf("Expected remapped delete key to open confirmation, mode = %v", app.CurrentMode)
+	}
+
+	if help := renderHelpView(app); !strings.Contains(help, "y           Copy current item") {
+		t.Error("Expected help screen to show the remapped copy key")
+	}
+}
+
+func TestAppModel_BindingShadowsBuiltInKey(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("content"), Preview: "item", Size: 7},
+	}
+	clip := newTestClipboard()
+	app := NewAppModel(items, clip)
+
+	// t toggled nothing when copy was bound to it, so the binding wins
+	keys, err := NewKeymap(map[string]string{"key_copy": "t"})
+	if err != nil {
+		t.Fatalf("NewKeymap failed: %v", err)
+	}
+	app.Keys = keys
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if string(clip.GetData()) != "content" {
+		t.Errorf("Expected t to copy, clipboard = %q", clip.GetData())
+	}
+	if app.Triage.Active {
+		t.Error("Expected t not to toggle triage while copy is bound to it")
+	}
+}
+
+func TestAppModel_WrapToggle(t *testing.T) {
+	// Each long line wraps to several display lines in a narrow pane
+	var content strings.Builder
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&content, "line %02d %s\n", i, strings.Repeat("word ", 30))
+	}
+	content.WriteString("needle at the end\n")
+	item := &StackItem{Content: NewStringReadSeekCloser(content.String()), Preview: "wide"}
+
+	app := NewAppModel([]*StackItem{item}, newTestClipboard())
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	app.View()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	for _, r := range "needle" {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	wrappedMatch := app.Search.GetCurrentMatchLine()
+	if wrappedMatch <= 40 {
+		t.Fatalf("Expected the wrapped match below line 40, got %d", wrappedMatch)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if !app.RightPane.NoWrap {
+		t.Fatal("w should switch to nowrap mode")
+	}
+	// One display line per source line, so the match moves to line 40
+	if got := app.Search.GetCurrentMatchLine(); got != 40 {
+		t.Errorf("Expected the nowrap match on line 40, got %d", got)
+	}
+	if !strings.Contains(app.View(), "nowrap, col 1") {
+		t.Error("Status line should show the horizontal offset")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("L")})
+	if app.RightPane.HOffset != DefaultHScrollStep {
+		t.Errorf("L should scroll right by %d, got offset %d", DefaultHScrollStep, app.RightPane.HOffset)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyShiftLeft})
+	if app.RightPane.HOffset != 0 {
+		t.Errorf("Shift+left should scroll back to 0, got %d", app.RightPane.HOffset)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if app.RightPane.NoWrap || app.Search.GetCurrentMatchLine() != wrappedMatch {
+		t.Errorf("Toggling back should restore wrapped matches, got line %d", app.Search.GetCurrentMatchLine())
+	}
+}
+
+func TestAppModel_WrapToggleKeepsTopLine(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&content, "line %02d %s\n", i, strings.Repeat("word ", 30))
+	}
+	item := &StackItem{Content: NewStringReadSeekCloser(content.String()), Preview: "wide"}
+
+	app := NewAppModel([]*StackItem{item}, newTestClipboard())
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	app.View()
+
+	// In nowrap mode 10j puts source line 10 at the top
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	app.View()
+	for _, key := range "10j" {
+		app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+	}
+	if app.RightPane.ViewPos != 10 {
+		t.Fatalf("Expected to scroll to line 10, got %d", app.RightPane.ViewPos)
+	}
+	app.View()
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	view := app.View()
+	if !strings.Contains(view, "line 10") {
+		t.Error("Wrapped view should still start at source line 10")
+	}
+}
+
+func TestAppModel_EditNote(t *testing.T) {
+	ops := newFakeItemOps()
+	items := []*StackItem{ops.item(1, "alpha"), ops.item(2, "beta")}
+	items[1].Note = "old"
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(ops)
+	model.UpdateMockSize(120, 30)
+	model.Init()
+
+	press := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			updated, _ := model.Update(msg)
+			model = updated.(Model)
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	// N edits the selected item's note; Enter saves it
+	press(runes("N"), runes("h"), runes("é"), enter)
+	if got := ops.notes[1]; got != "hé" {
+		t.Errorf("expected note %q saved, got %q", "hé", got)
+	}
+	if items[0].Note != "hé" {
+		t.Errorf("expected the item's note updated, got %q", items[0].Note)
+	}
+
+	// Editing starts from the current note; Esc discards changes
+	press(runes("j"), runes("N"))
+	if model.app.NoteInput != "old" || model.app.CurrentMode != NoteMode {
+		t.Fatalf("expected note mode with the current note, got %q in mode %d", model.app.NoteInput, model.app.CurrentMode)
+	}
+	press(runes("x"), tea.KeyMsg{Type: tea.KeyEsc})
+	if items[1].Note != "old" || model.app.CurrentMode != NormalMode {
+		t.Errorf("expected Esc to keep the note, got %q", items[1].Note)
+	}
+
+	// Clearing the input clears the note
+	press(runes("N"), tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, enter)
+	if note, ok := ops.notes[2]; !ok || note != "" || items[1].Note != "" {
+		t.Errorf("expected the note cleared, got %q", items[1].Note)
+	}
+}
+
+func TestRenderStatusLine_ItemSegments(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("a"), Preview: "a", Size: 2150, Timestamp: time.Now().Add(-2*time.Hour - time.Minute)},
+		{Content: NewStringReadSeekCloser("b"), Preview: "b", Size: 10, IsBinary: true},
+		{Content: NewStringReadSeekCloser("c"), Preview: "c"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+
+	tests := []struct {
+		width   int
+		want    []string
+		dropped []string
+	}{
+		{120, []string{"Item 1/3", "2.1 KB", "2h ago", "text", "Press z for help"}, nil},
+		{40, []string{"Item 1/3 · 2.1 KB · 2h ago · text"}, []string{"Press z"}},
+		{20, []string{"Item 1/3 · 2.1 KB"}, []string{"2h ago", "text"}},
+		{8, []string{"Item 1/3"}, []string{"2.1 KB"}},
+	}
+	for _, tt := range tests {
+		app.Width = tt.width
+		statusLine := renderStatusLine(app)
+		for _, want := range tt.want {
+			if !strings.Contains(statusLine, want) {
+				t.Errorf("width %d: expected %q in %q", tt.width, want, statusLine)
+			}
+		}
+		for _, dropped := range tt.dropped {
+			if strings.Contains(statusLine, dropped) {
+				t.Errorf("width %d: expected %q to be dropped from %q", tt.width, dropped, statusLine)
+			}
+		}
+	}
+
+	// T swaps the age for the date and time, in the configured zone
+	tokyo := time.FixedZone("JST", 9*60*60)
+	app.Location = tokyo
+	app.Width = 120
+	press(&app, "T")
+	app.FlashMessage = ""
+	want := items[0].Timestamp.In(tokyo).Format("2006-01-02 15:04")
+	if statusLine := renderStatusLine(app); !strings.Contains(statusLine, want) || strings.Contains(statusLine, "ago") {
+		t.Errorf("expected %q after T, got %q", want, statusLine)
+	}
+	press(&app, "T")
+	app.FlashMessage = ""
+	if statusLine := renderStatusLine(app); !strings.Contains(statusLine, "2h ago") {
+		t.Errorf("expected the age back after a second T, got %q", statusLine)
+	}
+
+	// Items without a timestamp skip the age; binary items say so
+	app.LeftPane.Selected = 1
+	if statusLine := renderStatusLine(app); !strings.Contains(statusLine, "Item 2/3 · 10 bytes · binary") {
+		t.Errorf("expected binary item segments, got %q", statusLine)
+	}
+
+	// Flash messages and search input still take priority
+	app.FlashMessage = "Copied"
+	app.FlashExpiry = time.Now().Add(time.Minute)
+	if statusLine := renderStatusLine(app); strings.Contains(statusLine, "Item 2/3") {
+		t.Errorf("expected the flash message to replace item segments, got %q", statusLine)
+	}
+	app.FlashMessage = ""
+	app.NumberBuffer = "12"
+	if statusLine := renderStatusLine(app); strings.Contains(statusLine, "Item") {
+		t.Errorf("expected the number buffer to replace item segments, got %q", statusLine)
+	}
+
+	// An empty queue shows only the hint
+	empty := NewAppModel(nil, newTestClipboard())
+	empty.Width = 120
+	if statusLine := renderStatusLine(empty); !strings.Contains(statusLine, "Press z for help, q to quit") || strings.Contains(statusLine, "Item") {
+		t.Errorf("expected the hint for an empty queue, got %q", statusLine)
+	}
+}
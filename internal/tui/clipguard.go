@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// foreignClipboard reads the clipboard and reports its size and whether it
+// holds content rem didn't put there: anything non-empty that is neither
+// what this session last copied nor one of the loaded items, which rem
+// keeps anyway. A failed read is returned so the caller can copy regardless.
+func (a *AppModel) foreignClipboard() (int64, bool, error) {
+	r, err := a.clipboard.Read()
+	if err != nil {
+		return 0, false, err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil || size == 0 {
+		return size, false, err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if sum == a.lastCopied {
+		return size, false, nil
+	}
+	for _, item := range a.Items {
+		if item.SHA256 == sum {
+			return size, false, nil
+		}
+	}
+	return size, true, nil
+}
+
+// guardOverwrite opens the overwrite confirmation before action replaces
+// foreign clipboard content, when ClipboardOverwriteConfirm is set, and
+// reports whether it did; the action then waits for the answer. warning is
+// set when the clipboard couldn't be checked, in which case the copy goes
+// ahead.
+func (a *AppModel) guardOverwrite(action Action) (asked bool, warning string) {
+	if !a.ClipboardOverwriteConfirm {
+		return false, ""
+	}
+	size, foreign, err := a.foreignClipboard()
+	if err != nil {
+		return false, fmt.Sprintf("couldn't check the clipboard: %v", err)
+	}
+	if !foreign {
+		return false, ""
+	}
+	a.CurrentMode = OverwriteMode
+	a.pendingOverwrite = action
+	a.Modal.Update(ShowOverwriteConfirmation(size))
+	return true, ""
+}
+
+// handleOverwriteResult acts on the overwrite confirmation modal
+func (a *AppModel) handleOverwriteResult(result ModalResult) (tea.Model, tea.Cmd) {
+	a.CurrentMode = NormalMode
+	action := a.pendingOverwrite
+	a.pendingOverwrite = ""
+	if result != ModalOverwrite {
+		return a, a.setFlashMessage("Kept the clipboard", 2*time.Second)
+	}
+	if action == ActionCopyDelete {
+		return a, a.copyDeleteSelected("")
+	}
+	return a, a.copySelected("")
+}
+
+// withWarning appends warning to a flash message, if there is one
+func withWarning(message, warning string) string {
+	if warning == "" {
+		return message
+	}
+	return message + " (" + warning + ")"
+}
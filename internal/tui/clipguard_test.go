@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/yiblet/rem/internal/clipboard"
+	"github.com/yiblet/rem/internal/clipboard/mockboard"
+)
+
+// unreadableClipboard accepts writes but fails to read
+type unreadableClipboard struct{ mockboard.MockClipboard }
+
+func (*unreadableClipboard) Read() (io.ReadCloser, error) { return nil, errors.New("no display") }
+
+func newGuardTestApp(clip clipboard.Clipboard) *AppModel {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("first item"), Preview: "first", Size: 10},
+		{Content: NewStringReadSeekCloser("second item"), Preview: "second", Size: 11},
+	}
+	app := NewAppModel(items, clip)
+	app.ClipboardOverwriteConfirm = true
+	return &app
+}
+
+func TestClipboardGuard_ForeignContent(t *testing.T) {
+	clip := newTestClipboard()
+	clip.SetData([]byte("copied from a browser"))
+	app := newGuardTestApp(clip)
+
+	press(app, "c")
+	if !app.Modal.Active || app.CurrentMode != OverwriteMode {
+		t.Fatalf("expected the overwrite confirmation, mode %v", app.CurrentMode)
+	}
+	if !strings.Contains(app.Modal.Content, "21 bytes of other content") {
+		t.Errorf("modal content = %q", app.Modal.Content)
+	}
+	press(app, "n")
+	if app.Modal.Active || app.CurrentMode != NormalMode || string(clip.GetData()) != "copied from a browser" {
+		t.Errorf("declining overwrote the clipboard with %q", clip.GetData())
+	}
+
+	press(app, "c")
+	press(app, "y")
+	if string(clip.GetData()) != "first item" || app.CurrentMode != NormalMode {
+		t.Errorf("confirming copied %q", clip.GetData())
+	}
+
+	// Copy and delete waits for the answer too
+	clip.SetData([]byte("typed elsewhere"))
+	press(app, "x")
+	if !app.Modal.Active || len(app.Items) != 2 {
+		t.Fatalf("expected x to ask before copying and deleting")
+	}
+	press(app, "y")
+	if string(clip.GetData()) != "first item" || len(app.Items) != 1 {
+		t.Errorf("confirming x copied %q and left %d items", clip.GetData(), len(app.Items))
+	}
+}
+
+func TestClipboardGuard_NoPrompt(t *testing.T) {
+	clip := newTestClipboard()
+	app := newGuardTestApp(clip)
+
+	// An empty clipboard has nothing to lose
+	press(app, "c")
+	if app.Modal.Active || string(clip.GetData()) != "first item" {
+		t.Fatalf("empty clipboard: modal %v, clipboard %q", app.Modal.Active, clip.GetData())
+	}
+
+	// Nor does content this session copied
+	press(app, "j")
+	press(app, "c")
+	if app.Modal.Active || string(clip.GetData()) != "second item" {
+		t.Errorf("rem-written clipboard: modal %v, clipboard %q", app.Modal.Active, clip.GetData())
+	}
+
+	// Nor content rem holds as an item
+	clip.SetData([]byte("held elsewhere"))
+	sum := sha256.Sum256([]byte("held elsewhere"))
+	app.Items[0].SHA256 = hex.EncodeToString(sum[:])
+	press(app, "c")
+	if app.Modal.Active {
+		t.Error("content stored as an item asked before overwriting")
+	}
+
+	// Off by default
+	clip.SetData([]byte("foreign"))
+	app.ClipboardOverwriteConfirm = false
+	press(app, "c")
+	if app.Modal.Active || string(clip.GetData()) != "second item" {
+		t.Errorf("guard off: modal %v, clipboard %q", app.Modal.Active, clip.GetData())
+	}
+}
+
+func TestClipboardGuard_ReadError(t *testing.T) {
+	clip := &unreadableClipboard{}
+	app := newGuardTestApp(clip)
+
+	press(app, "c")
+	if app.Modal.Active {
+		t.Fatal("an unreadable clipboard asked before overwriting")
+	}
+	if string(clip.GetData()) != "first item" {
+		t.Errorf("expected the copy to go ahead, clipboard %q", clip.GetData())
+	}
+	if !strings.Contains(app.FlashMessage, "couldn't check the clipboard: no display") {
+		t.Errorf("flash message = %q, want a warning", app.FlashMessage)
+	}
+}
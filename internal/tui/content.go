@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+)
+
+// NewReader returns a reader of the item's content with its own position, so
+// rendering, searching and copying never disturb each other's reads. Items
+// with ItemOps get a fresh reader from storage; otherwise reads are
+// serialized over the shared Content reader. The caller closes the reader.
+func (q *StackItem) NewReader() (io.ReadSeekCloser, error) {
+	if q.ops != nil {
+		content, err := q.ops.Content(q.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open content: %w", err)
+		}
+		return content, nil
+	}
+	return &contentReader{item: q}, nil
+}
+
+// isDeleted reports whether err, from reading the item, means it was deleted
+// from storage. Only items with ItemOps come from storage.
+func (q *StackItem) isDeleted(err error) bool {
+	return err != nil && q.ops != nil && q.ops.IsDeleted(err)
+}
+
+// ensureOpen makes Content available, reopening it if needed
+func (q *StackItem) ensureOpen() error {
+	q.contentMu.Lock()
+	defer q.contentMu.Unlock()
+	return q.open()
+}
+
+// contentReader reads an item's shared Content reader at its own position.
+// Each read seeks the shared reader under the item's lock, so interleaved
+// readers see consistent content.
+type contentReader struct {
+	item *StackItem
+	pos  int64
+}
+
+// Read reads from the reader's position
+func (r *contentReader) Read(p []byte) (int, error) {
+	r.item.contentMu.Lock()
+	defer r.item.contentMu.Unlock()
+
+	if err := r.item.open(); err != nil {
+		return 0, err
+	}
+	if _, err := r.item.Content.Seek(r.pos, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := r.item.Content.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+// Seek moves the reader's position; the shared reader is not touched
+// except to find the end of the content
+func (r *contentReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		size, err := r.size()
+		if err != nil {
+			return 0, err
+		}
+		pos = size + offset
+	default:
+		return 0, fmt.Errorf("invalid whence")
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+	r.pos = pos
+	return pos, nil
+}
+
+// size returns the length of the content
+func (r *contentReader) size() (int64, error) {
+	r.item.contentMu.Lock()
+	defer r.item.contentMu.Unlock()
+
+	if err := r.item.open(); err != nil {
+		return 0, err
+	}
+	return r.item.Content.Seek(0, io.SeekEnd)
+}
+
+// Close is a no-op; the shared reader is closed by the item
+func (r *contentReader) Close() error {
+	return nil
+}
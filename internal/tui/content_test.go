@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestStackItem_ConcurrentReaders searches, copies, and scrolls one item at
+// the same time; run with -race to check the reads don't share state
+func TestStackItem_ConcurrentReaders(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&b, "line %d of the shared content\n", i)
+	}
+	content := b.String()
+	want := sha256.Sum256([]byte(content))
+
+	ops := newFakeItemOps()
+	items := map[string]*StackItem{
+		"reopenable": ops.item(1, content),
+		"shared":     {Content: NewStringReadSeekCloser(content), Size: int64(len(content))},
+	}
+	items["reopenable"].ops = ops
+
+	for name, item := range items {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			run := func(fn func() error) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 5; i++ {
+						if err := fn(); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}()
+			}
+
+			run(func() error { return item.performSearch("line 4999 ") })
+			run(func() error {
+				copied, err := item.GetFullContent()
+				if err != nil {
+					return err
+				}
+				if sha256.Sum256([]byte(copied)) != want {
+					return fmt.Errorf("copied content does not match the stored SHA256")
+				}
+				return nil
+			})
+			scroll := 0
+			run(func() error {
+				scroll = (scroll + 1000) % 5000
+				item.mu.Lock()
+				item.ViewPos = scroll
+				item.mu.Unlock()
+				return item.UpdateWrappedLines(80, 20)
+			})
+			wg.Wait()
+
+			if item.MatchCount != 1 {
+				t.Errorf("expected 1 search match, got %d", item.MatchCount)
+			}
+			line, ok := item.lineAt(item.ViewPos)
+			if !ok || line != fmt.Sprintf("line %d of the shared content", item.ViewPos) {
+				t.Errorf("display line %d = %q, want line %d", item.ViewPos, line, item.ViewPos)
+			}
+		})
+	}
+}
+
+func TestContentReader_IndependentPositions(t *testing.T) {
+	item := &StackItem{Content: NewStringReadSeekCloser("abcdef")}
+	first, _ := item.NewReader()
+	second, _ := item.NewReader()
+
+	buf := make([]byte, 3)
+	io.ReadFull(first, buf)
+	if _, err := second.Seek(-2, io.SeekEnd); err != nil {
+		t.Fatalf("Seek() error = %v", err)
+	}
+	rest, _ := io.ReadAll(first)
+	if string(buf)+string(rest) != "abcdef" {
+		t.Errorf("expected the first reader to continue where it was, got %q", string(buf)+string(rest))
+	}
+	tail, _ := io.ReadAll(second)
+	if string(tail) != "ef" {
+		t.Errorf("expected the second reader to read from its own position, got %q", tail)
+	}
+
+	// A closed item without ItemOps can't be read
+	item.Close()
+	if _, err := io.ReadAll(first); err != errContentClosed {
+		t.Errorf("expected errContentClosed after Close, got %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffDetectLines is how many leading lines are checked for diff markers
+const diffDetectLines = 50
+
+// diffLineKind classifies a line of a unified diff
+type diffLineKind int
+
+const (
+	diffContext diffLineKind = iota
+	diffAdded
+	diffRemoved
+	diffHunk
+	diffHeader // diff --git, index, ---, +++ lines
+)
+
+// Diff colors are basic ANSI colors, so they follow the terminal's palette;
+// lipgloss drops them under NO_COLOR
+var diffStyles = map[diffLineKind]lipgloss.Style{
+	diffAdded:   lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+	diffRemoved: lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+	diffHunk:    lipgloss.NewStyle().Foreground(lipgloss.Color("6")),
+	diffHeader:  lipgloss.NewStyle().Bold(true),
+}
+
+// classifyDiffLine returns the kind of a source line of a unified diff
+func classifyDiffLine(line string) diffLineKind {
+	switch {
+	case strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "),
+		strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+		return diffHeader
+	case strings.HasPrefix(line, "@@"):
+		return diffHunk
+	case strings.HasPrefix(line, "+"):
+		return diffAdded
+	case strings.HasPrefix(line, "-"):
+		return diffRemoved
+	}
+	return diffContext
+}
+
+// looksLikeDiff reports whether lines, the start of some content, look like
+// a unified diff: a diff --git line, or a ---/+++ pair followed by a hunk
+func looksLikeDiff(lines []string) bool {
+	for i, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			return true
+		}
+		if strings.HasPrefix(line, "--- ") && i+2 < len(lines) &&
+			strings.HasPrefix(lines[i+1], "+++ ") && strings.HasPrefix(lines[i+2], "@@ ") {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDiff checks once whether the item's content is a unified diff. The
+// caller holds mu and has prepared the pager.
+func (q *StackItem) detectDiff() error {
+	if q.diffChecked {
+		return nil
+	}
+	if _, err := q.pager.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var lines []string
+	for len(lines) < diffDetectLines {
+		segment, more, err := q.pager.ReadLineSegment(1024)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		lines = append(lines, strings.TrimSuffix(segment, "\n"))
+		for more {
+			// Only the start of a long line matters
+			if _, more, err = q.pager.ReadLineSegment(wrapSegmentBytes); err != nil && err != io.EOF {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	q.diffChecked = true
+	q.isDiff = looksLikeDiff(lines)
+	return nil
+}
+
+// diffKindAt returns the diff kind of display line i, and false if the item
+// isn't a diff or the line isn't loaded
+func (q *StackItem) diffKindAt(i int) (diffLineKind, bool) {
+	if !q.isDiff || i < q.LinesStart || i >= q.LinesStart+len(q.lineKinds) {
+		return diffContext, false
+	}
+	return q.lineKinds[i-q.LinesStart], true
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 3b18e51..a9c2f10 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@
+ package main
+-import "fmt"
++import "log"
+ func main() {
++	log.Println("` + "this added line is long enough to wrap onto a second display line in the pane" + `")
+`
+
+var ansiCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestClassifyDiffLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want diffLineKind
+	}{
+		{"diff --git a/x b/x", diffHeader},
+		{"index 3b18e51..a9c2f10 100644", diffHeader},
+		{"--- a/x", diffHeader},
+		{"+++ b/x", diffHeader},
+		{"@@ -1,4 +1,4 @@ func main()", diffHunk},
+		{"+added", diffAdded},
+		{"-removed", diffRemoved},
+		{" context", diffContext},
+		{"plain text", diffContext},
+	}
+	for _, tt := range tests {
+		if got := classifyDiffLine(tt.line); got != tt.want {
+			t.Errorf("classifyDiffLine(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+
+	if !looksLikeDiff(strings.Split(sampleDiff, "\n")) {
+		t.Error("expected git diff output to be detected")
+	}
+	if !looksLikeDiff([]string{"--- old.txt", "+++ new.txt", "@@ -1 +1 @@", "-a", "+b"}) {
+		t.Error("expected a plain unified diff to be detected")
+	}
+	if looksLikeDiff([]string{"--- notes ---", "+++ not a diff", "text"}) {
+		t.Error("expected text with dashes to not be detected as a diff")
+	}
+}
+
+func TestRightPaneView_DiffColors(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	model := NewRightPaneModel(60, 20)
+	item := &StackItem{Content: NewStringReadSeekCloser(sampleDiff), Preview: "patch"}
+	render := func(model RightPaneModel, search SearchModel) string {
+		t.Helper()
+		view, err := RightPaneView(model, item, search, false, 0)
+		if err != nil {
+			t.Fatalf("RightPaneView() error = %v", err)
+		}
+		return view
+	}
+
+	colored := render(model, NewSearchModel())
+	if !item.isDiff {
+		t.Fatal("expected the item to be detected as a diff")
+	}
+
+	// Continuation lines of a wrapped added line stay added
+	want := []diffLineKind{diffHeader, diffHeader, diffHeader, diffHeader, diffHunk, diffContext, diffRemoved, diffAdded, diffContext, diffAdded, diffAdded}
+	if len(item.lineKinds) != len(want) {
+		t.Fatalf("expected %d display lines, got %d: %q", len(want), len(item.lineKinds), item.Lines)
+	}
+	for i, kind := range want {
+		if item.lineKinds[i] != kind {
+			t.Errorf("display line %d (%q) kind = %v, want %v", i, item.Lines[i], item.lineKinds[i], kind)
+		}
+	}
+	if !strings.Contains(colored, diffStyles[diffAdded].Render(`+import "log"`)) ||
+		!strings.Contains(colored, diffStyles[diffRemoved].Render(`-import "fmt"`)) {
+		t.Error("expected added and removed lines to be colored")
+	}
+
+	// Coloring leaves the layout unchanged
+	model.DiffColors = false
+	plain := render(model, NewSearchModel())
+	if ansiCodes.ReplaceAllString(colored, "") != ansiCodes.ReplaceAllString(plain, "") {
+		t.Errorf("expected the same layout with and without diff colors:\n%s\n%s", colored, plain)
+	}
+	for _, line := range strings.Split(colored, "\n") {
+		if lipgloss.Width(line) != model.Width {
+			t.Errorf("expected every line %d columns wide, got %d: %q", model.Width, lipgloss.Width(line), line)
+		}
+	}
+
+	// Search highlighting keeps the line's color around the match
+	model.DiffColors = true
+	search := NewSearchModel()
+	search.Update(UpdateSearchInputMsg{Input: "log"})
+	search.Update(ExecuteSearchMsg{})
+	search.SetMatches([]int{7})
+	highlighted := render(model, search)
+	if !strings.Contains(highlighted, diffStyles[diffAdded].Render(`+import "`)) || !strings.Contains(highlighted, diffStyles[diffAdded].Render(`"`)) {
+		t.Error("expected the text around a search match to keep the diff color")
+	}
+	if !strings.Contains(highlighted, highlightSearchMatches("log", "log", true)) {
+		t.Error("expected the search match to be highlighted")
+	}
+
+	other := &StackItem{Content: NewStringReadSeekCloser("just\n+some\n-text\n")}
+	if _, err := RightPaneView(model, other, NewSearchModel(), false, 0); err != nil || other.isDiff {
+		t.Errorf("expected plain text not to be treated as a diff (err %v)", err)
+	}
+}
+
+func TestAppModel_ToggleDiffColors(t *testing.T) {
+	app := NewAppModel([]*StackItem{{Content: NewStringReadSeekCloser(sampleDiff), Preview: "patch"}}, newTestClipboard())
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if app.RightPane.DiffColors || app.FlashMessage != "Diff colors off" {
+		t.Errorf("expected ctrl+t to turn diff colors off, got %v (%q)", app.RightPane.DiffColors, app.FlashMessage)
+	}
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if !app.RightPane.DiffColors {
+		t.Error("expected ctrl+t again to turn diff colors back on")
+	}
+}
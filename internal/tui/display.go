@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
+)
+
+// DefaultTabWidth is the number of columns between tab stops in the viewer
+const DefaultTabWidth = 4
+
+// offsetShift starts a stretch of displayed bytes that map back to the
+// source from source on. In a replaced stretch, such as a tab's spaces,
+// every byte maps to the replaced character; otherwise the source advances
+// with the display.
+type offsetShift struct {
+	display, source int
+	replaced        bool
+}
+
+// sourceMap maps byte offsets in displayed text back to its source. A nil
+// map is the identity.
+type sourceMap []offsetShift
+
+// source returns the source offset of the displayed byte at offset
+func (m sourceMap) source(offset int) int {
+	i := sort.Search(len(m), func(i int) bool { return m[i].display > offset }) - 1
+	switch {
+	case i < 0:
+		return offset
+	case m[i].replaced:
+		return m[i].source
+	}
+	return m[i].source + offset - m[i].display
+}
+
+// displayText returns s, a source line or a segment of one, as the viewer
+// shows it: tabs expanded with spaces to the next multiple of tabWidth
+// columns, and other control characters replaced with their control
+// pictures, such as ␀ for NUL and ␍ for a carriage return. Bytes that
+// aren't valid UTF-8 are shown as the replacement character �, or, when
+// latin1 is set, as the Latin-1 characters they would be. Widths are
+// measured and searches matched on this form; the stored content is
+// unchanged.
+func displayText(s string, tabWidth int, latin1 bool) (string, sourceMap) {
+	if !hasControl(s) && utf8.ValidString(s) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	var m sourceMap
+	col := 0
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\t':
+			n := tabWidth - col%tabWidth
+			m = append(m, offsetShift{display: b.Len(), source: i, replaced: true})
+			b.WriteString(strings.Repeat(" ", n))
+			m = append(m, offsetShift{display: b.Len(), source: i + size})
+			col += n
+		case isControl(r):
+			m = append(m, offsetShift{display: b.Len(), source: i, replaced: true})
+			b.WriteRune(controlPicture(r))
+			m = append(m, offsetShift{display: b.Len(), source: i + size})
+			col++
+		case r == utf8.RuneError && size == 1:
+			m = append(m, offsetShift{display: b.Len(), source: i, replaced: true})
+			b.WriteRune(invalidByteRune(s[i], latin1))
+			m = append(m, offsetShift{display: b.Len(), source: i + size})
+			col++
+		default:
+			b.WriteString(s[i : i+size])
+			if r < utf8.RuneSelf {
+				col++
+			} else {
+				col += uniseg.StringWidth(s[i : i+size])
+			}
+		}
+		i += size
+	}
+	return b.String(), m
+}
+
+// invalidByteRune returns the character shown for a byte that isn't valid
+// UTF-8. Latin-1's C1 controls have no picture, so they stay replaced.
+func invalidByteRune(c byte, latin1 bool) rune {
+	if latin1 && c >= 0xa0 {
+		return rune(c)
+	}
+	return utf8.RuneError
+}
+
+// hasControl reports whether s has a byte displayText replaces
+func hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if isControl(rune(s[i])) {
+			return true
+		}
+	}
+	return false
+}
+
+// isControl reports whether r is a C0 control character or DEL
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// controlPicture returns the Unicode control picture standing for r
+func controlPicture(r rune) rune {
+	if r == 0x7f {
+		return '␡'
+	}
+	return 0x2400 + r
+}
+
+// isControlPicture reports whether r is a picture controlPicture returns
+func isControlPicture(r rune) bool {
+	return r >= 0x2400 && r <= 0x2421
+}
+
+// segmentText returns a segment from ReadLineSegment without its line
+// ending. The CR of a CRLF ending is part of the ending, so Windows text
+// doesn't show ␍ on every line.
+func segmentText(segment string) string {
+	if text, ok := strings.CutSuffix(segment, "\n"); ok {
+		return strings.TrimSuffix(text, "\r")
+	}
+	return segment
+}
+
+// controlStyle dims control pictures so they read as placeholders
+var controlStyle = lipgloss.NewStyle().Faint(true)
+
+// dimControls renders s with its control pictures dimmed and the rest
+// rendered by plain
+func dimControls(s string, plain func(string) string) string {
+	if !strings.ContainsFunc(s, isControlPicture) {
+		return plain(s)
+	}
+	var b strings.Builder
+	for s != "" {
+		i := strings.IndexFunc(s, isControlPicture)
+		if i < 0 {
+			b.WriteString(plain(s))
+			break
+		}
+		if i > 0 {
+			b.WriteString(plain(s[:i]))
+		}
+		end := i + strings.IndexFunc(s[i:], func(r rune) bool { return !isControlPicture(r) })
+		if end < i {
+			end = len(s)
+		}
+		b.WriteString(controlStyle.Render(s[i:end]))
+		s = s[end:]
+	}
+	return b.String()
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// goSource is tab-indented content like most Go files
+var goSource = strings.Repeat("func main() {\n\tif err := run(); err != nil {\n\t\tfmt.Fprintln(os.Stderr, \"error:\", err)\t// report it\n\t\tos.Exit(1)\n\t}\n}\n", 20)
+
+func TestDisplayText(t *testing.T) {
+	tests := []struct {
+		in, want string
+		tabWidth int
+		latin1   bool
+	}{
+		{"plain", "plain", 4, false},
+		{"\tx", "    x", 4, false},
+		{"ab\tc", "ab  c", 4, false},
+		{"abcd\te", "abcd    e", 4, false},
+		{"a\tb", "a       b", 8, false},
+		{"漢\tx", "漢  x", 4, false}, // a wide character takes two columns
+		{"a\x00b\rc\x1bd\x7f", "a␀b␍c␛d␡", 4, false},
+		{"caf\xe9 \xff\xfe", "caf� ��", 4, false},
+		{"caf\xe9 \x85 \u00e9", "café � é", 4, true}, // C1 controls stay replaced
+		{"a\ufffdb", "a\ufffdb", 4, false},           // a real replacement character is kept
+	}
+	for _, tt := range tests {
+		got, sources := displayText(tt.in, tt.tabWidth, tt.latin1)
+		if got != tt.want {
+			t.Errorf("displayText(%q, %d, %v) = %q, want %q", tt.in, tt.tabWidth, tt.latin1, got, tt.want)
+		}
+		// Every displayed character maps back to where it came from
+		if sources.source(len(got)) != len(tt.in) {
+			t.Errorf("displayText(%q): end maps to %d, want %d", tt.in, sources.source(len(got)), len(tt.in))
+		}
+	}
+
+	got, sources := displayText("ab\tcd", 4, false)
+	for offset, want := range []int{0, 1, 2, 2, 3, 4} {
+		if source := sources.source(offset); source != want {
+			t.Errorf("%q: offset %d maps to %d, want %d", got, offset, source, want)
+		}
+	}
+}
+
+func TestSegmentText(t *testing.T) {
+	for in, want := range map[string]string{"a\n": "a", "a\r\n": "a", "a\r": "a\r", "a": "a", "\r\n": ""} {
+		if got := segmentText(in); got != want {
+			t.Errorf("segmentText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRightPaneView_Tabs(t *testing.T) {
+	for _, noWrap := range []bool{false, true} {
+		for _, width := range []int{30, 47, 80} {
+			model := NewRightPaneModel(width, 30)
+			model.NoWrap = noWrap
+			item := &StackItem{Content: NewStringReadSeekCloser(goSource), Preview: "main.go"}
+
+			view, err := RightPaneView(model, item, NewSearchModel(), true, 0)
+			if err != nil {
+				t.Fatalf("RightPaneView returned error: %v", err)
+			}
+			if strings.Contains(view, "\t") {
+				t.Errorf("width %d, nowrap %v: view has a raw tab", width, noWrap)
+			}
+			for i, line := range strings.Split(view, "\n") {
+				if w := lipgloss.Width(line); w > width {
+					t.Errorf("width %d, nowrap %v: line %d is %d cells wide:\n%s", width, noWrap, i, w, view)
+				}
+			}
+			if !strings.Contains(view, "        os.Exit(1)") {
+				t.Errorf("width %d, nowrap %v: expected two tab stops of indentation:\n%s", width, noWrap, view)
+			}
+		}
+	}
+
+	model := NewRightPaneModel(80, 30)
+	model.TabWidth = 8
+	item := &StackItem{Content: NewStringReadSeekCloser(goSource), Preview: "main.go"}
+	view, _ := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if !strings.Contains(view, "                os.Exit(1)") {
+		t.Errorf("expected 8-column tab stops:\n%s", view)
+	}
+}
+
+func TestRightPaneView_ControlCharacters(t *testing.T) {
+	model := NewRightPaneModel(60, 12)
+	item := &StackItem{Content: NewStringReadSeekCloser("progress 10%\rprogress 99%\x00\r\nnext\r\n"), Preview: "log"}
+
+	view, err := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if err != nil {
+		t.Fatalf("RightPaneView returned error: %v", err)
+	}
+	if !strings.Contains(view, "progress 10%␍progress 99%␀") {
+		t.Errorf("expected control pictures for CR and NUL:\n%s", view)
+	}
+	// The CR of a CRLF line ending isn't shown
+	if strings.Contains(view, "next␍") || strings.Contains(view, "␀␍") {
+		t.Errorf("expected CRLF line endings hidden:\n%s", view)
+	}
+}
+
+func TestDimControls(t *testing.T) {
+	upper := func(s string) string { return strings.ToUpper(s) }
+	if got := dimControls("ab␀␀cd␍", upper); got != "AB␀␀CD␍" {
+		t.Errorf("dimControls = %q, want the text around the pictures rendered by plain", got)
+	}
+	if got := dimControls("plain", upper); got != "PLAIN" {
+		t.Errorf("dimControls = %q without pictures", got)
+	}
+}
+
+func TestSearch_MatchesExpandedTabs(t *testing.T) {
+	item := &StackItem{Content: NewStringReadSeekCloser("func f() {\n\treturn 1\n}\n")}
+	if err := item.performSearch("    return"); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	// The match is found where the tab is, as the viewer shows it
+	if len(item.SearchHits) != 1 || item.SearchHits[0].Offset != int64(len("func f() {\n")) {
+		t.Fatalf("expected one hit at the tab, got %+v", item.SearchHits)
+	}
+
+	if _, err := RightPaneView(NewRightPaneModel(60, 12), item, NewSearchModel(), true, 0); err != nil {
+		t.Fatalf("RightPaneView returned error: %v", err)
+	}
+	if line, ok := item.lineAt(item.SearchMatches[0]); !ok || line != "    return 1" {
+		t.Errorf("expected the match on the displayed line, got %q", line)
+	}
+
+	if err := item.performSearch(`\t`); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	if len(item.SearchHits) != 0 {
+		t.Errorf("expected no match for a tab, which the viewer shows as spaces, got %+v", item.SearchHits)
+	}
+}
+
+func TestAppModel_CopyKeepsTabs(t *testing.T) {
+	clip := newTestClipboard()
+	items := []*StackItem{{Content: NewStringReadSeekCloser(goSource), Preview: "main.go", Size: int64(len(goSource))}}
+	app := NewAppModel(items, clip)
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	AppView(app)
+
+	press(&app, "c")
+	if got := string(clip.GetData()); got != goSource {
+		t.Errorf("expected the copied content unchanged, with its tabs; got %q", got[:min(len(got), 80)])
+	}
+}
+
+// latin1Log is text from an old Latin-1 log, which isn't valid UTF-8
+const latin1Log = "caf\xe9 opened\nna\xefve user logged in\nclosed\n"
+
+func TestRightPaneView_NonUTF8(t *testing.T) {
+	model := NewRightPaneModel(60, 12)
+	item := &StackItem{Content: NewStringReadSeekCloser(latin1Log), Preview: "server.log"}
+	view, err := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if err != nil {
+		t.Fatalf("RightPaneView returned error: %v", err)
+	}
+	if !strings.Contains(view, "caf� opened") || !strings.Contains(view, "(non-UTF-8 content, displayed lossily)") {
+		t.Errorf("expected replacement characters and the notice:\n%s", view)
+	}
+	if !utf8.ValidString(view) {
+		t.Error("expected the rendered view to be valid UTF-8")
+	}
+
+	model.Latin1 = true
+	view, _ = RightPaneView(model, item, NewSearchModel(), true, 0)
+	if !strings.Contains(view, "café opened") || !strings.Contains(view, "naïve user") || !strings.Contains(view, "(non-UTF-8 content, shown as Latin-1)") {
+		t.Errorf("expected the Latin-1 reading and its notice:\n%s", view)
+	}
+
+	utf8Item := &StackItem{Content: NewStringReadSeekCloser("café opened\n"), Preview: "utf8.log"}
+	view, _ = RightPaneView(NewRightPaneModel(60, 12), utf8Item, NewSearchModel(), true, 0)
+	if strings.Contains(view, "non-UTF-8") {
+		t.Errorf("expected no notice for UTF-8 content:\n%s", view)
+	}
+}
+
+func TestSearch_MatchesReplacedBytes(t *testing.T) {
+	for _, latin1 := range []bool{false, true} {
+		item := &StackItem{Content: NewStringReadSeekCloser(latin1Log)}
+		item.setLatin1(latin1)
+		if err := item.performSearch("user"); err != nil {
+			t.Fatalf("performSearch failed: %v", err)
+		}
+		if len(item.SearchHits) != 1 || item.SearchHits[0].Offset != int64(strings.Index(latin1Log, "user")) {
+			t.Fatalf("latin1 %v: expected one hit at the byte offset of user, got %+v", latin1, item.SearchHits)
+		}
+
+		// The highlight covers the shown text, the same width as the source
+		model := NewRightPaneModel(60, 12)
+		model.Latin1 = latin1
+		if _, err := RightPaneView(model, item, NewSearchModel(), true, 0); err != nil {
+			t.Fatalf("RightPaneView returned error: %v", err)
+		}
+		line, _ := item.lineAt(item.SearchMatches[0])
+		if want := map[bool]string{false: "na�ve user logged in", true: "naïve user logged in"}[latin1]; line != want {
+			t.Errorf("latin1 %v: match on line %q, want %q", latin1, line, want)
+		}
+	}
+
+	// The pattern is matched on what's shown
+	item := &StackItem{Content: NewStringReadSeekCloser(latin1Log)}
+	item.setLatin1(true)
+	if err := item.performSearch("café"); err != nil || len(item.SearchHits) != 1 || item.SearchHits[0].Offset != 0 {
+		t.Errorf("expected café to match the Latin-1 reading, got %+v (%v)", item.SearchHits, err)
+	}
+}
+
+func TestAppModel_CopyKeepsInvalidUTF8(t *testing.T) {
+	clip := newTestClipboard()
+	items := []*StackItem{{Content: NewStringReadSeekCloser(latin1Log), Preview: "server.log", Size: int64(len(latin1Log))}}
+	app := NewAppModel(items, clip)
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	AppView(app)
+
+	press(&app, "c")
+	if got := string(clip.GetData()); got != latin1Log {
+		t.Errorf("expected the original bytes copied, got %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hintSeparator joins the segments of a pane's hint row
+const hintSeparator = " · "
+
+// leftPaneHints are the hint segments for the focused list pane, most
+// useful first
+func leftPaneHints(keys Keymap) []string {
+	return keyHints("j/k move", keyHint(keys, ActionDelete, "delete"), keyHint(keys, ActionCopy, "copy"), "tab content")
+}
+
+// rightPaneHints are the hint segments for the focused content pane, most
+// useful first
+func rightPaneHints(keys Keymap) []string {
+	return keyHints("/ search", "n/N next/prev", keyHint(keys, ActionCopy, "copy"), "tab list")
+}
+
+// keyHint describes the key bound to action, or returns "" if none is
+func keyHint(keys Keymap, action Action, what string) string {
+	if key := keys.KeyFor(action); key != "" {
+		return key + " " + what
+	}
+	return ""
+}
+
+// keyHints drops the empty segments
+func keyHints(segments ...string) []string {
+	hints := segments[:0]
+	for _, segment := range segments {
+		if segment != "" {
+			hints = append(hints, segment)
+		}
+	}
+	return hints
+}
+
+// fitHints joins as many segments as fit in width, dropping them from the
+// right, and dims the result
+func fitHints(segments []string, width int) string {
+	line := ""
+	for _, segment := range segments {
+		next := segment
+		if line != "" {
+			next = line + hintSeparator + segment
+		}
+		if lipgloss.Width(next) > width {
+			break
+		}
+		line = next
+	}
+	if line == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Faint(true).Render(line)
+}
+
+// withHintRow pads body to rows-1 lines and puts hint on the last of rows
+func withHintRow(body string, rows int, hint string) string {
+	lines := strings.Count(body, "\n") + 1
+	return body + strings.Repeat("\n", max(rows-lines, 1)) + hint
+}
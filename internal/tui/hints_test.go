@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFitHints(t *testing.T) {
+	segments := []string{"j/k move", "d delete", "c copy"}
+	for _, tt := range []struct {
+		width int
+		want  string
+	}{
+		{40, "j/k move · d delete · c copy"},
+		{28, "j/k move · d delete · c copy"},
+		{27, "j/k move · d delete"},
+		{10, "j/k move"},
+		{7, ""},
+	} {
+		got := fitHints(segments, tt.width)
+		if plain := strings.TrimSpace(got); plain != tt.want {
+			t.Errorf("fitHints(width %d) = %q, want %q", tt.width, plain, tt.want)
+		}
+		if lipgloss.Width(got) > tt.width {
+			t.Errorf("fitHints(width %d) is %d cells wide", tt.width, lipgloss.Width(got))
+		}
+	}
+}
+
+func TestLeftPaneView_Hints(t *testing.T) {
+	items := []*StackItem{{Preview: "Item 1"}, {Preview: "Item 2"}}
+	keys, err := NewKeymap(map[string]string{"key_delete": "D"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, width := range []int{16, 30, 60} {
+		plain := NewLeftPaneModel(width, 12)
+		hinted := plain
+		hinted.Hints = leftPaneHints(keys)
+
+		without, _ := LeftPaneView(plain, items, true)
+		focused, _ := LeftPaneView(hinted, items, true)
+		unfocused, _ := LeftPaneView(hinted, items, false)
+
+		lines := strings.Split(focused, "\n")
+		if want := len(strings.Split(without, "\n")); len(lines) != want || len(strings.Split(unfocused, "\n")) != want {
+			t.Errorf("width %d: hints changed the line count from %d", width, want)
+		}
+		hintRow := lines[len(lines)-2]
+		if !strings.Contains(hintRow, "j/k move") {
+			t.Errorf("width %d: expected hints above the bottom border, got %q", width, hintRow)
+		}
+		if width >= 30 && !strings.Contains(hintRow, "D delete") {
+			t.Errorf("width %d: expected the rebound delete key, got %q", width, hintRow)
+		}
+		for i, line := range lines {
+			if w := lipgloss.Width(line); w != width+2 {
+				t.Errorf("width %d: line %d is %d cells wide", width, i, w)
+			}
+		}
+
+		unfocusedLines := strings.Split(unfocused, "\n")
+		if row := unfocusedLines[len(unfocusedLines)-2]; strings.TrimSpace(strings.Trim(row, "│")) != "" {
+			t.Errorf("width %d: expected no hints when unfocused, got %q", width, row)
+		}
+	}
+}
+
+func TestRightPaneView_Hints(t *testing.T) {
+	var content strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	item := &StackItem{Content: NewStringReadSeekCloser(content.String()), Preview: "long"}
+
+	for _, width := range []int{24, 40, 80} {
+		model := NewRightPaneModel(width, 14)
+		without, _ := RightPaneView(model, item, NewSearchModel(), true, 0)
+		model.Hints = rightPaneHints(DefaultKeymap())
+		view, err := RightPaneView(model, item, NewSearchModel(), true, 0)
+		if err != nil {
+			t.Fatalf("RightPaneView() error = %v", err)
+		}
+		lines := strings.Split(view, "\n")
+		if want := len(strings.Split(without, "\n")); len(lines) != want {
+			t.Errorf("width %d: hints changed the line count from %d to %d", width, want, len(lines))
+		}
+		if hintRow := lines[len(lines)-2]; !strings.Contains(hintRow, "/ search") {
+			t.Errorf("width %d: expected hints above the bottom border, got %q", width, hintRow)
+		}
+		for i, line := range lines {
+			if w := lipgloss.Width(line); w != width {
+				t.Errorf("width %d: line %d is %d cells wide", width, i, w)
+			}
+		}
+
+		// Scrolled to the bottom, the last line shows above the hints
+		model.Update(ScrollToBottomMsg{MaxScroll: getMaxScroll(model, item)})
+		view, _ = RightPaneView(model, item, NewSearchModel(), true, 0)
+		lines = strings.Split(view, "\n")
+		if last := lines[len(lines)-3]; !strings.Contains(last, "line 49") {
+			t.Errorf("width %d: expected the last line above the hints, got %q", width, last)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"regexp"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultIncrementalSearchDelay is how long typing must pause before the
+// pattern typed so far is searched
+const DefaultIncrementalSearchDelay = 150 * time.Millisecond
+
+// IncrementalSearch searches the selected item as a pattern is typed, once
+// typing pauses for Delay, and jumps to the first match. Esc puts back the
+// search and scroll position from before search mode was entered. The zero
+// value leaves searching to Enter.
+type IncrementalSearch struct {
+	Enabled bool
+	Delay   time.Duration
+
+	generation int  // bumped by every edit; a search for an older one is stale
+	previewed  bool // a pattern has been searched since search mode was entered
+
+	// What esc restores
+	savedPattern string
+	savedMatches []int
+	savedCurrent int
+	savedViewPos int
+}
+
+// incSearchMsg is sent Delay after an edit of the search input; the input is
+// searched only if no edit has been made since
+type incSearchMsg struct {
+	generation int
+}
+
+func (incSearchMsg) isAppMsg() {}
+
+// startIncrementalSearch records what esc restores as search mode is entered
+func (a *AppModel) startIncrementalSearch() {
+	a.IncSearch.generation++
+	a.IncSearch.previewed = false
+	a.IncSearch.savedPattern = a.Search.Pattern
+	a.IncSearch.savedMatches = a.Search.Matches
+	a.IncSearch.savedCurrent = a.Search.CurrentMatch
+	a.IncSearch.savedViewPos = a.RightPane.ViewPos
+}
+
+// scheduleIncrementalSearch starts the debounce for an edited search input.
+// An error from an earlier pattern is cleared, so one is only shown if the
+// pattern is still invalid when typing pauses.
+func (a *AppModel) scheduleIncrementalSearch() tea.Cmd {
+	if !a.IncSearch.Enabled {
+		return nil
+	}
+	a.IncSearch.generation++
+	a.Search.Error = ""
+	generation := a.IncSearch.generation
+	return tea.Tick(a.IncSearch.Delay, func(time.Time) tea.Msg { return incSearchMsg{generation: generation} })
+}
+
+// handleIncrementalSearch searches the selected item for the input typed so
+// far, unless it has been edited since or search mode has been left
+func (a *AppModel) handleIncrementalSearch(msg incSearchMsg) {
+	if msg.generation != a.IncSearch.generation || a.CurrentMode != SearchMode {
+		return
+	}
+	input := a.Search.GetInput()
+	if input == "" {
+		a.restoreIncrementalSearch()
+		return
+	}
+	if _, err := regexp.Compile("(?i)" + input); err != nil {
+		a.Search.Error = err.Error()
+		return
+	}
+
+	a.IncSearch.previewed = true
+	a.Search.Pattern = input
+	a.Search.SetMatches(nil)
+	a.RightPane.ViewPos = a.IncSearch.savedViewPos
+	a.syncSearchToSelection()
+}
+
+// restoreIncrementalSearch puts back the search and scroll position from
+// before search mode was entered, if a typed pattern replaced them
+func (a *AppModel) restoreIncrementalSearch() {
+	if !a.IncSearch.previewed {
+		return
+	}
+	a.IncSearch.previewed = false
+	a.Search.Pattern = a.IncSearch.savedPattern
+	if item := a.selectedItem(); item != nil && !item.IsBinary {
+		item.performSearch(a.Search.Pattern)
+	}
+	a.Search.Matches = a.IncSearch.savedMatches
+	a.Search.CurrentMatch = a.IncSearch.savedCurrent
+	a.RightPane.ViewPos = a.IncSearch.savedViewPos
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newIncSearchTestApp returns an app viewing a long item with "needle" on
+// line 60, in search mode with the right pane focused
+func newIncSearchTestApp(t *testing.T) *AppModel {
+	t.Helper()
+	var content strings.Builder
+	for i := 0; i < 100; i++ {
+		if i == 60 {
+			content.WriteString("the needle\n")
+			continue
+		}
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	items := []*StackItem{{Content: NewStringReadSeekCloser(content.String()), Preview: "long"}}
+	app := NewAppModel(items, newTestClipboard())
+	app.IncSearch.Delay = time.Millisecond
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	app.ActivePane = RightPane
+	app.RightPane.ViewPos = 5
+	press(&app, "/")
+	if app.CurrentMode != SearchMode {
+		t.Fatalf("expected search mode after /, got %v", app.CurrentMode)
+	}
+	return &app
+}
+
+// typeKey presses key and returns the incremental search it schedules
+func typeKey(t *testing.T, app *AppModel, key string) incSearchMsg {
+	t.Helper()
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	if cmd == nil {
+		t.Fatalf("typing %q scheduled no search", key)
+	}
+	msg, ok := cmd().(incSearchMsg)
+	if !ok {
+		t.Fatalf("typing %q scheduled %T, want an incremental search", key, msg)
+	}
+	return msg
+}
+
+func TestIncrementalSearch_JumpsWhenTypingPauses(t *testing.T) {
+	app := newIncSearchTestApp(t)
+
+	var msgs []incSearchMsg
+	for _, key := range []string{"n", "e", "e", "d"} {
+		msgs = append(msgs, typeKey(t, app, key))
+		if app.RightPane.ViewPos != 5 || app.Search.GetPattern() != "" {
+			t.Fatalf("typing %q moved to %d with pattern %q before the debounce", key, app.RightPane.ViewPos, app.Search.GetPattern())
+		}
+	}
+
+	// Searches scheduled by earlier keystrokes are discarded
+	for _, msg := range msgs[:len(msgs)-1] {
+		app.Update(msg)
+	}
+	if app.RightPane.ViewPos != 5 || app.Search.HasMatches() {
+		t.Fatalf("a stale search moved to %d, want to stay at 5", app.RightPane.ViewPos)
+	}
+
+	app.Update(msgs[len(msgs)-1])
+	if app.Search.GetPattern() != "need" || app.Search.GetCurrentMatchLine() != 60 {
+		t.Fatalf("pattern %q at match line %d, want need at 60", app.Search.GetPattern(), app.Search.GetCurrentMatchLine())
+	}
+	if app.RightPane.ViewPos == 5 || app.RightPane.ViewPos > 60 {
+		t.Errorf("view at %d, want it moved to show line 60", app.RightPane.ViewPos)
+	}
+	if app.CurrentMode != SearchMode {
+		t.Error("expected to stay in search mode until Enter")
+	}
+
+	// Enter locks the pattern in as before
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.CurrentMode != NormalMode || app.Search.GetPattern() != "need" || !app.Search.HasMatches() {
+		t.Errorf("Enter left mode %v with pattern %q", app.CurrentMode, app.Search.GetPattern())
+	}
+}
+
+func TestIncrementalSearch_EscRestores(t *testing.T) {
+	app := newIncSearchTestApp(t)
+	app.Update(typeKey(t, app, "n"))
+	app.Update(typeKey(t, app, "e"))
+	if app.RightPane.ViewPos == 5 {
+		t.Fatal("expected the search to have jumped")
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.CurrentMode != NormalMode || app.RightPane.ViewPos != 5 {
+		t.Errorf("esc left mode %v at %d, want normal mode at 5", app.CurrentMode, app.RightPane.ViewPos)
+	}
+	if app.Search.GetPattern() != "" || app.Search.HasMatches() || app.Items[0].SearchPattern != "" {
+		t.Errorf("esc kept pattern %q, want the search from before", app.Search.GetPattern())
+	}
+}
+
+func TestIncrementalSearch_InvalidPattern(t *testing.T) {
+	app := newIncSearchTestApp(t)
+
+	// The error waits for typing to pause, and goes once the pattern is fixed
+	msg := typeKey(t, app, "(")
+	if app.Search.GetError() != "" {
+		t.Fatalf("error %q shown while typing", app.Search.GetError())
+	}
+	app.Update(msg)
+	if app.Search.GetError() == "" {
+		t.Fatal("expected an error once typing paused on an invalid pattern")
+	}
+	msg = typeKey(t, app, ")")
+	if app.Search.GetError() != "" {
+		t.Errorf("error %q kept after the pattern was edited", app.Search.GetError())
+	}
+	app.Update(msg)
+	if app.Search.GetError() != "" || app.Search.GetPattern() != "()" {
+		t.Errorf("pattern %q with error %q, want () searched", app.Search.GetPattern(), app.Search.GetError())
+	}
+}
+
+func TestIncrementalSearch_Disabled(t *testing.T) {
+	app := newIncSearchTestApp(t)
+	app.IncSearch.Enabled = false
+	if _, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); cmd != nil {
+		t.Error("expected no search scheduled while incremental search is off")
+	}
+	if app.Search.GetInput() != "n" || app.Search.GetPattern() != "" {
+		t.Errorf("input %q with pattern %q, want n typed but not searched", app.Search.GetInput(), app.Search.GetPattern())
+	}
+}
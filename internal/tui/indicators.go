@@ -0,0 +1,66 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// IndicatorSet selects the glyphs of the left pane's indicator column, which
+// shows what kind of item each row is. The zero value hides the column.
+type IndicatorSet int
+
+const (
+	IndicatorsOff  IndicatorSet = iota
+	IndicatorsText              // plain letters, for any font
+	IndicatorsNerd              // Nerd Font icons
+)
+
+// itemKind is what an item's indicator shows; plain text shows none
+type itemKind int
+
+const (
+	kindText itemKind = iota
+	kindBinary
+	kindImage
+	kindPinned
+	kindFlagged
+)
+
+// indicatorGlyphs are the one-cell glyphs of each kind, by set. Only binary
+// items are told apart so far; itemKindOf picks up the others once items
+// record them.
+var indicatorGlyphs = map[IndicatorSet]map[itemKind]string{
+	IndicatorsText: {kindText: " ", kindBinary: "B", kindImage: "I", kindPinned: "*", kindFlagged: "!"},
+	IndicatorsNerd: {kindText: " ", kindBinary: "\uf471", kindImage: "\uf03e", kindPinned: "\uf435", kindFlagged: "\uf071"},
+}
+
+// itemKindOf returns the kind of item its indicator shows
+func itemKindOf(item *StackItem) itemKind {
+	if item.IsBinary {
+		return kindBinary
+	}
+	return kindText
+}
+
+// indicatorWidth is the width of the indicator column in set, including the
+// space after it
+func indicatorWidth(set IndicatorSet) int {
+	if indicatorGlyphs[set] == nil {
+		return 0
+	}
+	return 2
+}
+
+// renderIndicator renders item's cell of the indicator column, or "" when
+// set hides the column. The glyph is dim rather than colored, so it reads
+// the same without color.
+func renderIndicator(item *StackItem, set IndicatorSet, selected bool) string {
+	glyphs := indicatorGlyphs[set]
+	if glyphs == nil {
+		return ""
+	}
+	style := lipgloss.NewStyle()
+	if selected {
+		style = style.Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230"))
+	} else {
+		style = style.Faint(true)
+	}
+	return style.Render(glyphs[itemKindOf(item)] + " ")
+}
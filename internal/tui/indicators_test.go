@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLeftPaneView_Indicators(t *testing.T) {
+	items := []*StackItem{
+		{ID: 1, Preview: "plain text notes"},
+		{ID: 2, Preview: "image.png", IsBinary: true},
+		{ID: 3, Preview: "archive.tar.gz with a long name", IsBinary: true, MatchCount: 3},
+	}
+
+	for _, width := range []int{16, 50} {
+		off, _ := LeftPaneView(NewLeftPaneModel(width, 10), items, false)
+		offLines := strings.Split(off, "\n")
+
+		for set, want := range map[IndicatorSet][]string{
+			IndicatorsText: {"  0. plain", "B 1. image", "B 2. archive"},
+			IndicatorsNerd: {"  0. plain", "\uf471 1. image", "\uf471 2. archive"},
+		} {
+			model := NewLeftPaneModel(width, 10)
+			model.Indicators = set
+			model.Cursor = 1
+			view, _ := LeftPaneView(model, items, false)
+
+			// The column takes room from the preview, never from the border
+			lines := strings.Split(view, "\n")
+			if len(lines) != len(offLines) {
+				t.Fatalf("width %d, set %d: %d lines, want %d", width, set, len(lines), len(offLines))
+			}
+			for i := range lines {
+				if w, wantW := lipgloss.Width(lines[i]), lipgloss.Width(offLines[i]); w != wantW {
+					t.Errorf("width %d, set %d: line %d is %d cells wide, want %d", width, set, i, w, wantW)
+				}
+			}
+
+			for _, prefix := range want {
+				// A narrow pane may cut the preview short, but not the prefix
+				if width < 20 {
+					prefix = prefix[:strings.Index(prefix, ".")+1]
+				}
+				if !strings.Contains(view, "│ "+prefix) {
+					t.Errorf("width %d, set %d: expected a row starting %q:\n%s", width, set, prefix, view)
+				}
+			}
+			if width == 50 && !strings.Contains(view, "(3)") {
+				t.Errorf("width %d, set %d: expected the match badge to stay:\n%s", width, set, view)
+			}
+		}
+
+		if strings.Contains(off, "B 1.") {
+			t.Errorf("width %d: expected no indicators when they're off:\n%s", width, off)
+		}
+	}
+}
+
+func TestA11y_TextIndicators(t *testing.T) {
+	app := newA11yTestApp(2)
+	app.Items[1].IsBinary = true
+	app.LeftPane.Indicators = IndicatorsNerd
+	view, _ := AppView(*app)
+	if strings.Contains(view, "\uf471") || !strings.Contains(view, "B 1.") {
+		t.Errorf("expected letters instead of icons in accessibility mode:\n%s", view)
+	}
+}
@@ -0,0 +1,29 @@
+package tui
+
+import "io"
+
+// ItemOps performs persistent operations on items by ID. The TUI calls it
+// through AppModel instead of holding per-item closures, so an item only
+// needs its ID to be deleted, reopened, renamed, annotated, or bumped.
+// Storage errors are recognized through it too, so the TUI doesn't depend
+// on the store.
+type ItemOps interface {
+	// Delete removes the item from persistent storage
+	Delete(id uint) error
+
+	// Content opens a new reader for the item's content
+	Content(id uint) (io.ReadSeekCloser, error)
+
+	// Rename replaces the item's title
+	Rename(id uint, title string) error
+
+	// SetNote replaces the item's note; an empty note clears it
+	SetNote(id uint, note string) error
+
+	// Bump moves the item to the top of the queue
+	Bump(id uint) error
+
+	// IsDeleted reports whether err, from Content or a reader it opened,
+	// means the item was deleted from storage
+	IsDeleted(err error) bool
+}
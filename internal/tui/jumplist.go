@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxJumpEntries is the number of visited items the jump list remembers
+const maxJumpEntries = 50
+
+// jumpSettleDelay is how long the cursor must rest on an item before
+// moving away from it records a jump
+const jumpSettleDelay = 300 * time.Millisecond
+
+// jumpEntry is a visited item and the right pane scroll position it was
+// left at
+type jumpEntry struct {
+	ID      uint
+	ViewPos int
+}
+
+// JumpList records the items visited in the left pane so they can be
+// revisited with back and forward, like vim's jump list
+type JumpList struct {
+	back    []jumpEntry
+	forward []jumpEntry
+
+	// pending is the item the cursor left while it is still moving; it is
+	// recorded once the cursor settles
+	pending *jumpEntry
+	seq     int
+}
+
+// jumpSettledMsg is sent jumpSettleDelay after cursor movement; seq tells
+// whether the cursor has moved again since
+type jumpSettledMsg struct {
+	seq int
+}
+
+func (jumpSettledMsg) isAppMsg() {}
+
+// push records from as left for another item, dropping the forward history
+func (j *JumpList) push(from jumpEntry) {
+	j.forward = nil
+	if n := len(j.back); n > 0 && j.back[n-1].ID == from.ID {
+		j.back[n-1] = from
+		return
+	}
+	j.back = append(j.back, from)
+	if len(j.back) > maxJumpEntries {
+		j.back = j.back[len(j.back)-maxJumpEntries:]
+	}
+}
+
+// position returns the current entry's 1-based position and the number of
+// entries, counting the current item
+func (j *JumpList) position() (int, int) {
+	return len(j.back) + 1, len(j.back) + len(j.forward) + 1
+}
+
+// currentEntry returns the jump entry for the selected item
+func (a *AppModel) currentEntry() (jumpEntry, bool) {
+	if a.LeftPane.Selected >= len(a.Items) || a.Items[a.LeftPane.Selected] == nil {
+		return jumpEntry{}, false
+	}
+	return jumpEntry{ID: a.Items[a.LeftPane.Selected].ID, ViewPos: a.RightPane.ViewPos}, true
+}
+
+// startCursorMove remembers the selected item before the cursor moves and
+// returns the command that reports when the cursor has settled
+func (a *AppModel) startCursorMove(from jumpEntry, ok bool) tea.Cmd {
+	if ok && a.Jumps.pending == nil {
+		a.Jumps.pending = &from
+	}
+	a.Jumps.seq++
+	seq := a.Jumps.seq
+	return tea.Tick(jumpSettleDelay, func(time.Time) tea.Msg {
+		return jumpSettledMsg{seq: seq}
+	})
+}
+
+// handleJumpSettled records the item the cursor left once it has rested
+// on another one
+func (a *AppModel) handleJumpSettled(msg jumpSettledMsg) {
+	if msg.seq == a.Jumps.seq {
+		a.settleJump()
+	}
+}
+
+// settleJump records the pending item if the selection has moved off it
+func (a *AppModel) settleJump() {
+	pending := a.Jumps.pending
+	a.Jumps.pending = nil
+	if pending == nil {
+		return
+	}
+	if current, ok := a.currentEntry(); ok && current.ID != pending.ID {
+		a.Jumps.push(*pending)
+	}
+}
+
+// recordJump records from as left by an explicit jump. A cursor move still
+// settling started from an earlier item, which is recorded instead.
+func (a *AppModel) recordJump(from jumpEntry, ok bool) {
+	if a.Jumps.pending != nil {
+		a.settleJump()
+		return
+	}
+	if current, currentOK := a.currentEntry(); ok && currentOK && current.ID != from.ID {
+		a.Jumps.push(from)
+	}
+}
+
+// jumpBack returns to the previously visited item
+func (a *AppModel) jumpBack() tea.Cmd {
+	return a.jump(&a.Jumps.back, &a.Jumps.forward, "back")
+}
+
+// jumpForward returns to the item jumpBack left
+func (a *AppModel) jumpForward() tea.Cmd {
+	return a.jump(&a.Jumps.forward, &a.Jumps.back, "forward")
+}
+
+// jump pops entries off from until one names an item that still exists,
+// selects it, and saves the current item on to
+func (a *AppModel) jump(from, to *[]jumpEntry, direction string) tea.Cmd {
+	a.settleJump()
+	current, ok := a.currentEntry()
+	for len(*from) > 0 {
+		entry := (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+
+		index := a.indexOfID(entry.ID)
+		if index < 0 || (ok && entry.ID == current.ID) {
+			// Deleted since it was visited, or already selected
+			continue
+		}
+		if ok {
+			*to = append(*to, current)
+		}
+		a.selectIndex(index)
+		a.restoreViewPos(a.Items[index], entry.ViewPos)
+
+		position, total := a.Jumps.position()
+		return a.setFlashMessage(fmt.Sprintf("%s %d/%d", direction, position, total), 2*time.Second)
+	}
+	return a.setFlashMessage(fmt.Sprintf("No %s history", direction), 2*time.Second)
+}
+
+// restoreViewPos scrolls the right pane back to viewPos, wrapping the item's
+// content far enough to know the position is still in range
+func (a *AppModel) restoreViewPos(item *StackItem, viewPos int) {
+	if viewPos <= 0 {
+		return
+	}
+	item.mu.Lock()
+	item.ViewPos = viewPos
+	item.mu.Unlock()
+	item.UpdateWrappedLines(a.RightPane.wrapWidth(), max(a.RightPane.contentHeight(), 1))
+	a.RightPane.ViewPos = min(viewPos, getMaxScroll(a.RightPane, item))
+}
+
+// indexOfID returns the index of the item with id, or -1
+func (a *AppModel) indexOfID(id uint) int {
+	for i, item := range a.Items {
+		if item != nil && item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// selectIndex selects the item at index and refreshes the right pane
+func (a *AppModel) selectIndex(index int) {
+	previous := a.LeftPane.Selected
+	a.LeftPane.Update(JumpToIndexMsg{Index: index, MaxIndex: len(a.Items) - 1})
+	a.RightPane.Update(UpdateContentMsg{})
+	a.syncSearchToSelection()
+	a.releaseIfDeselected(previous)
+}
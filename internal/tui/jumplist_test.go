@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAppModel_JumpList(t *testing.T) {
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for id := uint(1); id <= 5; id++ {
+		var b strings.Builder
+		for i := 0; i < 200; i++ {
+			fmt.Fprintf(&b, "item %d line %d\n", id, i)
+		}
+		items = append(items, ops.item(id, b.String()))
+	}
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(ops)
+	model.UpdateMockSize(120, 30)
+	model.Init()
+
+	// send renders after each message, as the program does, so the right
+	// pane knows how far it can scroll
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			updated, _ := model.Update(msg)
+			model = updated.(Model)
+			model.View()
+		}
+	}
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	ctrlO := tea.KeyMsg{Type: tea.KeyCtrlO}
+	ctrlN := tea.KeyMsg{Type: tea.KeyCtrlN}
+	selectedID := func() uint { return model.app.Items[model.app.LeftPane.Selected].ID }
+	// scrollTo scrolls the right pane of the selected item to line
+	scrollTo := func(line int) {
+		send(runes("l"))
+		for _, r := range fmt.Sprintf("%dg", line+1) {
+			send(runes(string(r)))
+		}
+		send(runes("h"))
+	}
+
+	// Visit 1 (scrolled to 10), 3 (scrolled to 20), then 5
+	scrollTo(10)
+	send(runes("3"), runes("g"))
+	scrollTo(20)
+	send(runes("G"))
+	if selectedID() != 5 {
+		t.Fatalf("expected item 5 selected, got %d", selectedID())
+	}
+
+	send(ctrlO)
+	if selectedID() != 3 || model.app.RightPane.ViewPos != 20 {
+		t.Errorf("expected back to item 3 at line 20, got item %d at line %d", selectedID(), model.app.RightPane.ViewPos)
+	}
+	if model.app.FlashMessage != "back 2/3" {
+		t.Errorf("expected flash %q, got %q", "back 2/3", model.app.FlashMessage)
+	}
+	send(ctrlO)
+	if selectedID() != 1 || model.app.RightPane.ViewPos != 10 {
+		t.Errorf("expected back to item 1 at line 10, got item %d at line %d", selectedID(), model.app.RightPane.ViewPos)
+	}
+	send(ctrlN)
+	if selectedID() != 3 || model.app.RightPane.ViewPos != 20 {
+		t.Errorf("expected forward to item 3 at line 20, got item %d at line %d", selectedID(), model.app.RightPane.ViewPos)
+	}
+	if model.app.FlashMessage != "forward 2/3" {
+		t.Errorf("expected flash %q, got %q", "forward 2/3", model.app.FlashMessage)
+	}
+
+	// Deleting a visited item skips it
+	send(ctrlN)
+	if selectedID() != 5 {
+		t.Fatalf("expected forward to item 5, got %d", selectedID())
+	}
+	send(runes("3"), runes("g"))
+	if err := model.app.deleteSelected(); err != nil {
+		t.Fatalf("deleteSelected() error = %v", err)
+	}
+	send(ctrlO)
+	if selectedID() != 5 {
+		t.Errorf("expected back to skip a deleted item, got item %d", selectedID())
+	}
+}
+
+func TestAppModel_JumpListSettle(t *testing.T) {
+	ops := newFakeItemOps()
+	items := []*StackItem{ops.item(1, "a"), ops.item(2, "b"), ops.item(3, "c")}
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(ops)
+	model.UpdateMockSize(120, 30)
+	model.Init()
+
+	// The settle tick is not waited for; move builds the message it sends
+	var settle tea.Msg
+	move := func(key string) {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		model = updated.(Model)
+		if cmd == nil {
+			t.Fatalf("expected a settle command after moving")
+		}
+		settle = jumpSettledMsg{seq: model.app.Jumps.seq}
+	}
+
+	// Moving through item 2 without resting records only where the move began
+	move("j")
+	stale := settle
+	move("j")
+	model.Update(stale)
+	if len(model.app.Jumps.back) != 0 {
+		t.Fatalf("expected a stale settle message to be ignored, got %v", model.app.Jumps.back)
+	}
+	model.Update(settle)
+	if len(model.app.Jumps.back) != 1 || model.app.Jumps.back[0].ID != 1 {
+		t.Errorf("expected item 1 recorded once the cursor settled, got %v", model.app.Jumps.back)
+	}
+}
+
+func TestJumpList_Cap(t *testing.T) {
+	var jumps JumpList
+	for id := uint(0); id < maxJumpEntries+10; id++ {
+		jumps.push(jumpEntry{ID: id})
+	}
+	if len(jumps.back) != maxJumpEntries || jumps.back[0].ID != 10 {
+		t.Errorf("expected the oldest entries dropped past %d, got %d starting at %d", maxJumpEntries, len(jumps.back), jumps.back[0].ID)
+	}
+}
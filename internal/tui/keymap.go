@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is a normal-mode command that can be bound to a key
+type Action string
+
+// Remappable normal-mode actions
+const (
+	ActionCopy       Action = "copy"        // copy the selected item to the clipboard
+	ActionDelete     Action = "delete"      // delete the selected item (with confirmation)
+	ActionCopyDelete Action = "copy_delete" // copy the selected item, then delete it
+	ActionOpenURL    Action = "open_url"    // open a link shown in the right pane
+)
+
+// actionConfigKeys maps each action to the config key that rebinds it
+var actionConfigKeys = map[Action]string{
+	ActionCopy:       "key_copy",
+	ActionDelete:     "key_delete",
+	ActionCopyDelete: "key_copy_delete",
+	ActionOpenURL:    "key_open_url",
+}
+
+// defaultBindings is the key bound to each action when none is configured
+var defaultBindings = map[Action]string{
+	ActionCopy:       "c",
+	ActionDelete:     "d",
+	ActionCopyDelete: "x",
+	ActionOpenURL:    "o",
+}
+
+// yieldingDefaults are default bindings added after the keys they use could
+// be configured for other actions. A configured binding to such a key takes
+// precedence, leaving the action unbound, rather than conflicting with it.
+var yieldingDefaults = map[Action]bool{
+	ActionOpenURL: true,
+}
+
+// reservedKeys are handled before the keymap is consulted and cannot be rebound
+var reservedKeys = map[string]bool{
+	"q": true, "ctrl+c": true, "esc": true, "/": true, "?": true, "z": true, "tab": true,
+	"h": true, "j": true, "k": true, "l": true, "g": true, "G": true, "n": true, "N": true,
+	"up": true, "down": true, "left": true, "right": true,
+	"ctrl+u": true, "ctrl+d": true, "ctrl+b": true, "ctrl+f": true,
+	"0": true, "1": true, "2": true, "3": true, "4": true,
+	"5": true, "6": true, "7": true, "8": true, "9": true,
+}
+
+// shadowableKeys are built-in keys added after actions could be bound to
+// any other key. A binding to one of them takes the key over, leaving its
+// built-in command unreachable, so a binding made before the key was
+// taken keeps working.
+var shadowableKeys = map[string]bool{
+	"w": true, "H": true, "L": true, "shift+left": true, "shift+right": true,
+	"ctrl+t": true, "ctrl+g": true, "b": true, "'": true, "T": true, "t": true,
+}
+
+// Keymap maps a key, as reported by tea.KeyMsg.String, to its action
+type Keymap map[string]Action
+
+// DefaultKeymap returns the built-in key bindings
+func DefaultKeymap() Keymap {
+	keymap, _ := NewKeymap(nil)
+	return keymap
+}
+
+// KeyConfigKeys returns the config keys that rebind actions, sorted
+func KeyConfigKeys() []string {
+	keys := make([]string, 0, len(actionConfigKeys))
+	for _, key := range actionConfigKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// NewKeymap builds a keymap from config values keyed by config key
+// (key_copy, key_delete, key_copy_delete, key_open_url). Missing or empty
+// values keep the default binding, unless another action is configured
+// onto a yielding default's key. Bindings to shadowable keys take them
+// over. Bindings to reserved keys or shared by two actions are rejected
+// with an error listing every conflict.
+func NewKeymap(config map[string]string) (Keymap, error) {
+	actions := make([]Action, 0, len(defaultBindings))
+	for action := range defaultBindings {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+
+	// Configured bindings first, so defaults can yield to them
+	keymap := Keymap{}
+	configured := map[Action]bool{}
+	var conflicts []string
+	for _, action := range actions {
+		configKey := actionConfigKeys[action]
+		key := strings.TrimSpace(config[configKey])
+		if key == "" {
+			continue
+		}
+		configured[action] = true
+
+		if reservedKeys[key] {
+			conflicts = append(conflicts, fmt.Sprintf("%s: '%s' is reserved", configKey, key))
+			continue
+		}
+		if other, taken := keymap[key]; taken {
+			conflicts = append(conflicts, fmt.Sprintf("%s: '%s' is already bound by %s", configKey, key, actionConfigKeys[other]))
+			continue
+		}
+		keymap[key] = action
+	}
+
+	for _, action := range actions {
+		if configured[action] {
+			continue
+		}
+		key := defaultBindings[action]
+		if other, taken := keymap[key]; taken {
+			if !yieldingDefaults[action] {
+				conflicts = append(conflicts, fmt.Sprintf("%s: '%s' is already bound by %s", actionConfigKeys[other], key, actionConfigKeys[action]))
+			}
+			continue
+		}
+		keymap[key] = action
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("invalid key bindings: %s", strings.Join(conflicts, "; "))
+	}
+	return keymap, nil
+}
+
+// KeyFor returns the key bound to action, or "" if it is unbound
+func (k Keymap) KeyFor(action Action) string {
+	for key, bound := range k {
+		if bound == action {
+			return key
+		}
+	}
+	return ""
+}
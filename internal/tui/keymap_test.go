@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultKeymap(t *testing.T) {
+	keys := DefaultKeymap()
+	want := map[string]Action{"c": ActionCopy, "d": ActionDelete, "x": ActionCopyDelete}
+	for key, action := range want {
+		if keys[key] != action {
+			t.Errorf("Default binding for %q = %q, want %q", key, keys[key], action)
+		}
+	}
+}
+
+func TestNewKeymap_Conflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   []string
+	}{
+		{
+			name:   "reserved key",
+			config: map[string]string{"key_copy": "q"},
+			want:   []string{"key_copy: 'q' is reserved"},
+		},
+		{
+			name:   "digit and search key",
+			config: map[string]string{"key_delete": "5", "key_copy_delete": "/"},
+			want:   []string{"key_delete: '5' is reserved", "key_copy_delete: '/' is reserved"},
+		},
+		{
+			name:   "two actions on one key",
+			config: map[string]string{"key_copy_delete": "c"},
+			want:   []string{"key_copy_delete: 'c' is already bound by key_copy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeymap(tt.config)
+			if err == nil {
+				t.Fatalf("Expected an error, got keymap %v", keys)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewKeymap_SwapBindings(t *testing.T) {
+	keys, err := NewKeymap(map[string]string{"key_copy": "d", "key_delete": "c"})
+	if err != nil {
+		t.Fatalf("Swapping bindings should be allowed: %v", err)
+	}
+	if keys.KeyFor(ActionCopy) != "d" || keys.KeyFor(ActionDelete) != "c" {
+		t.Errorf("Unexpected keymap %v", keys)
+	}
+}
+
+func TestNewKeymap_YieldingDefault(t *testing.T) {
+	// A key bound before open_url had a default keeps its binding
+	keys, err := NewKeymap(map[string]string{"key_copy": "o"})
+	if err != nil {
+		t.Fatalf("A binding on open_url's default key should be allowed: %v", err)
+	}
+	if keys.KeyFor(ActionCopy) != "o" || keys.KeyFor(ActionOpenURL) != "" {
+		t.Errorf("Expected copy on o and open_url unbound, got %v", keys)
+	}
+
+	// Binding open_url elsewhere too keeps both
+	keys, err = NewKeymap(map[string]string{"key_copy": "o", "key_open_url": "O"})
+	if err != nil || keys.KeyFor(ActionOpenURL) != "O" {
+		t.Errorf("Expected open_url on O, got %v (%v)", keys, err)
+	}
+}
+
+func TestNewKeymap_ShadowableKeys(t *testing.T) {
+	for _, key := range []string{"t", "T", "'", "b"} {
+		keys, err := NewKeymap(map[string]string{"key_delete": key})
+		if err != nil {
+			t.Errorf("Binding %q should take over the built-in key: %v", key, err)
+			continue
+		}
+		if keys[key] != ActionDelete {
+			t.Errorf("Expected %q bound to delete, got %v", key, keys)
+		}
+	}
+}
@@ -3,8 +3,12 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/rivo/uniseg"
+	"github.com/yiblet/rem/internal/ref"
+	"github.com/yiblet/rem/internal/timefmt"
 )
 
 // LeftPaneMsg represents messages that the left pane component handles
@@ -59,6 +63,25 @@ type LeftPaneModel struct {
 	Selected int // Currently selected item index
 	Width    int // Pane width
 	Height   int // Pane height
+
+	Title       string       // heading above the list; "" shows "Queue"
+	GroupByDate bool         // show Today/Yesterday/This week/Older headers
+	Indicators  IndicatorSet // glyphs marking binary and other kinds of item
+
+	// Location is the zone whose calendar days GroupByDate follows; nil is
+	// the local zone
+	Location *time.Location
+
+	// Recent holds the IDs of recently copied items, marked with an arrow
+	Recent map[uint]bool
+
+	// Set by the app for each render while items load a page at a time
+	Loading bool   // a page is being fetched; a placeholder row ends the list
+	Footer  string // shown on the last line, such as "200 of 9,431 loaded"
+
+	// Hints are the key hints shown in the bottom row while the pane is
+	// focused; nil leaves the row to the list
+	Hints []string
 }
 
 // NewLeftPaneModel creates a new left pane model with default values
@@ -127,42 +150,285 @@ func LeftPaneView(model LeftPaneModel, items []*StackItem, focused bool) (string
 
 	var content strings.Builder
 	title := "Queue"
+	if model.Title != "" {
+		title = model.Title
+	}
 	if focused {
 		title = "● " + title // Active indicator
 	}
-	content.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+	// A long title takes the blank line under it
+	first, rest := splitTitle(title, model.Width-4)
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(first) + "\n")
+	if visible, ellipsis := truncateToWidth(rest, model.Width-4); visible+ellipsis != "" {
+		content.WriteString(lipgloss.NewStyle().Bold(true).Render(visible + ellipsis))
+	}
+	content.WriteString("\n")
+
+	headerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	rows := leftPaneRows(items, model.GroupByDate, timefmt.Now(model.Location))
+	if model.Loading {
+		rows = append(rows, leftPaneRow{item: -1, header: "Loading more…"})
+	}
+	height := model.Height - 6
+	if model.Footer != "" {
+		height--
+	}
+	if model.Hints != nil {
+		height--
+	}
+	for _, row := range scrollRows(rows, model.Cursor, height) {
+		if row.item < 0 {
+			content.WriteString(headerStyle.Render(row.header) + "\n")
+			continue
+		}
+		selected := row.item == model.Cursor
+		indicator := renderIndicator(items[row.item], model.Indicators, selected)
+		line := renderItemLine(row.item, items[row.item], model.Width-4-indicatorWidth(model.Indicators), selected, model.Recent[items[row.item].ID])
+		content.WriteString(indicator + line + "\n")
+	}
+	if model.Footer != "" {
+		content.WriteString(headerStyle.Render(ellipsize(model.Footer, model.Width-4)) + "\n")
+	}
+
+	contentStr := strings.TrimSuffix(content.String(), "\n")
+	if model.Hints != nil {
+		hint := ""
+		if focused {
+			hint = fitHints(model.Hints, model.Width-4)
+		}
+		contentStr = withHintRow(contentStr, model.Height-4, hint)
+	}
+	return style.Render(contentStr), nil
+}
 
+// leftPaneRow is one display row of the left pane: an item, or a date
+// header when item is -1
+type leftPaneRow struct {
+	item   int // index into the items; -1 for a header
+	header string
+}
+
+// leftPaneRows lays out items as display rows, starting a header row each
+// time the date bucket changes when group is set. The cursor moves over
+// items only, so headers never take it.
+func leftPaneRows(items []*StackItem, group bool, now time.Time) []leftPaneRow {
+	rows := make([]leftPaneRow, 0, len(items))
+	bucket := ""
 	for i, item := range items {
-		// Calculate available width for preview (account for "N. " prefix and padding)
-		availableWidth := model.Width - 6 // Account for borders, padding, and "N. "
-		preview := item.Preview
+		if group {
+			if b := dateBucket(item.Timestamp, now); b != bucket {
+				rows = append(rows, leftPaneRow{item: -1, header: b})
+				bucket = b
+			}
+		}
+		rows = append(rows, leftPaneRow{item: i})
+	}
+	return rows
+}
+
+// dateBucket names the recency group of t: "Today", "Yesterday", "This week"
+// (the five days before), or "Older", by calendar day in now's location.
+// A zero t is "Older".
+func dateBucket(t, now time.Time) string {
+	if t.IsZero() {
+		return "Older"
+	}
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch t = t.In(now.Location()); {
+	case !t.Before(today):
+		return "Today"
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return "Yesterday"
+	case !t.Before(today.AddDate(0, 0, -6)):
+		return "This week"
+	default:
+		return "Older"
+	}
+}
+
+// scrollRows returns the rows that fit in height lines, scrolled just far
+// enough to show the cursor's item on the last line. Header rows count
+// toward height, and the one above the cursor's item stays in view with it,
+// as does a placeholder row ending the list. A height of 0 or less shows
+// every row.
+func scrollRows(rows []leftPaneRow, cursor, height int) []leftPaneRow {
+	if height <= 0 || len(rows) <= height {
+		return rows
+	}
+	start := 0
+	for i, row := range rows {
+		if row.item == cursor {
+			end := i + 1
+			if end == len(rows)-1 && rows[end].item < 0 {
+				end++
+			}
+			start = max(end-height, 0)
+			break
+		}
+	}
+	return rows[start : start+height]
+}
+
+// renderItemLine renders one list entry as "N. preview (matches)" within width
+// display columns, with the item's @reference dimmed at the right edge when
+// the preview leaves room for it. The badge is never truncated; the preview gives way instead,
+// and the visible part of any search match in it is highlighted. A recently
+// copied item has an arrow after its badge.
+func renderItemLine(index int, item *StackItem, width int, selected, recent bool) string {
+	prefix := fmt.Sprintf("%d. ", index)
+	badge := matchBadge(item)
+	if recent {
+		badge += recentCopyMark
+	}
 
-		// Remove any newlines from preview first
-		preview = strings.ReplaceAll(preview, "\n", " ")
+	preview := strings.ReplaceAll(item.Preview, "\n", " ")
+	previewWidth := width - lipgloss.Width(prefix) - lipgloss.Width(badge)
+	itemRef := ""
+	if item.ID != 0 {
+		itemRef = " @" + ref.Encode(item.ID)
+		if lipgloss.Width(preview) > previewWidth-len(itemRef) {
+			itemRef = ""
+		}
+	}
+	visible, ellipsis := truncateToWidth(preview, previewWidth)
 
-		// Truncate preview if too long
-		if len(preview) > availableWidth {
-			preview = preview[:availableWidth-3] + "..."
+	base := lipgloss.NewStyle()
+	if selected {
+		base = base.Background(lipgloss.Color("62")).Foreground(lipgloss.Color("230"))
+	}
+	highlight := base.Bold(true).Underline(true)
+
+	var line strings.Builder
+	line.WriteString(base.Render(prefix))
+	for _, part := range splitMatches(visible, item.SearchPattern) {
+		if part.match {
+			line.WriteString(highlight.Render(part.text))
+		} else {
+			line.WriteString(base.Render(part.text))
 		}
+	}
+	line.WriteString(base.Render(ellipsis + badge))
+
+	// Pad out to the reference, or with the selection background across the pane
+	if pad := width - lipgloss.Width(line.String()) - len(itemRef); pad > 0 && (selected || itemRef != "") {
+		line.WriteString(base.Render(strings.Repeat(" ", pad)))
+	}
+	if itemRef != "" {
+		line.WriteString(base.Faint(true).Render(itemRef))
+	}
+	return line.String()
+}
 
-		line := fmt.Sprintf("%d. %s", i, preview)
+// matchBadge returns the " (N)" match count suffix for items with search matches
+func matchBadge(item *StackItem) string {
+	switch {
+	case item.MatchCount <= 0:
+		return ""
+	case item.SearchLimitHit:
+		return fmt.Sprintf(" (%d+)", item.MatchCount)
+	default:
+		return fmt.Sprintf(" (%d)", item.MatchCount)
+	}
+}
+
+// truncateToWidth cuts s to at most width display columns, rune by rune.
+// When s is cut, the returned ellipsis ("...") is what fills the final columns.
+func truncateToWidth(s string, width int) (visible, ellipsis string) {
+	if lipgloss.Width(s) <= width {
+		return s, ""
+	}
+	if width <= 3 {
+		return "", strings.Repeat(".", max(width, 0))
+	}
+
+	used := 0
+	for i, r := range s {
+		w := lipgloss.Width(string(r))
+		if used+w > width-3 {
+			return s[:i], "..."
+		}
+		used += w
+	}
+	return s, ""
+}
 
-		// Ensure the line doesn't exceed the available width
-		if len(line) > model.Width-4 { // Account for borders and padding
-			line = line[:model.Width-7] + "..."
+// ellipsize cuts s to at most width display cells, between grapheme
+// clusters so wide and combining characters stay whole, ending a cut
+// string with "…"
+func ellipsize(s string, width int) string {
+	if uniseg.StringWidth(s) <= width {
+		return s
+	}
+	if width < 1 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	graphemes := uniseg.NewGraphemes(s)
+	for graphemes.Next() {
+		if used+graphemes.Width() > width-1 {
+			break
 		}
+		b.WriteString(graphemes.Str())
+		used += graphemes.Width()
+	}
+	return b.String() + "…"
+}
 
-		if i == model.Cursor {
-			line = lipgloss.NewStyle().
-				Background(lipgloss.Color("62")).
-				Foreground(lipgloss.Color("230")).
-				Width(model.Width - 4). // Force width constraint
-				Render(line)
+// splitTitle splits title before the last space that lets the first part
+// fit in width columns; a title that fits is returned whole
+func splitTitle(title string, width int) (first, rest string) {
+	if lipgloss.Width(title) <= width {
+		return title, ""
+	}
+	cut := -1
+	for i, r := range title {
+		if lipgloss.Width(title[:i]) > width {
+			break
 		}
+		if r == ' ' {
+			cut = i
+		}
+	}
+	if cut <= 0 {
+		visible, ellipsis := truncateToWidth(title, width)
+		return visible + ellipsis, ""
+	}
+	return title[:cut], title[cut+1:]
+}
 
-		content.WriteString(line + "\n")
+// textPart is a run of text that either matches a search pattern or not
+type textPart struct {
+	text  string
+	match bool
+}
+
+// splitMatches splits s into alternating non-matching and matching parts for
+// the case-insensitive pattern. An empty or invalid pattern yields s unchanged.
+func splitMatches(s, pattern string) []textPart {
+	if pattern == "" || s == "" {
+		return []textPart{{text: s}}
+	}
+	regex, err := compileSearch(pattern)
+	if err != nil {
+		return []textPart{{text: s}}
 	}
 
-	contentStr := strings.TrimSuffix(content.String(), "\n")
-	return style.Render(contentStr), nil
+	var parts []textPart
+	last := 0
+	for _, m := range findMatches(regex, s) {
+		if m[0] == m[1] {
+			continue
+		}
+		if m[0] > last {
+			parts = append(parts, textPart{text: s[last:m[0]]})
+		}
+		parts = append(parts, textPart{text: s[m[0]:m[1]], match: true})
+		last = m[1]
+	}
+	if last < len(s) {
+		parts = append(parts, textPart{text: s[last:]})
+	}
+	return parts
 }
@@ -1,8 +1,14 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func TestNewLeftPaneModel(t *testing.T) {
@@ -213,3 +219,199 @@ func TestLeftPaneView_EmptyItems(t *testing.T) {
 		t.Error("Expected view to contain 'Queue' title even with no items")
 	}
 }
+
+func TestLeftPaneView_MatchBadge(t *testing.T) {
+	items := []*StackItem{
+		{Preview: "first", MatchCount: 7},
+		{Preview: "second"},
+		{Preview: "third", MatchCount: 99, SearchLimitHit: true},
+	}
+	model := NewLeftPaneModel(30, 10)
+
+	view, err := LeftPaneView(model, items, false)
+	if err != nil {
+		t.Fatalf("LeftPaneView returned error: %v", err)
+	}
+
+	if !strings.Contains(view, "0. first (7)") {
+		t.Errorf("Expected badge on first item, got:\n%s", view)
+	}
+	if strings.Contains(view, "second (") {
+		t.Errorf("Expected no badge for item without matches, got:\n%s", view)
+	}
+	if !strings.Contains(view, "2. third (99+)") {
+		t.Errorf("Expected 99+ badge when search limit hit, got:\n%s", view)
+	}
+}
+
+func TestRenderItemLine_Ref(t *testing.T) {
+	item := &StackItem{ID: 42, Preview: "nginx config", MatchCount: 2}
+
+	for _, selected := range []bool{false, true} {
+		line := renderItemLine(0, item, 40, selected, false)
+		if got := lipgloss.Width(line); got != 40 {
+			t.Errorf("selected=%v: line is %d cells wide, want 40: %q", selected, got, line)
+		}
+		if !strings.HasPrefix(line, "0. nginx config (2)") || !strings.HasSuffix(line, " @001a") {
+			t.Errorf("selected=%v: expected the reference at the right edge, got %q", selected, line)
+		}
+	}
+
+	// The reference never hides any of the preview
+	if line := renderItemLine(0, item, 20, false, false); strings.Contains(line, "@") || !strings.HasPrefix(line, "0. nginx config (2)") {
+		t.Errorf("expected the preview without the reference in a narrow pane, got %q", line)
+	}
+	item.Preview = strings.Repeat("x", 60)
+	if line := renderItemLine(0, item, 40, false, false); strings.Contains(line, "@") {
+		t.Errorf("expected no reference beside a truncated preview, got %q", line)
+	}
+	// Items not yet stored have no reference
+	if line := renderItemLine(0, &StackItem{Preview: "pending"}, 40, false, false); strings.Contains(line, "@") {
+		t.Errorf("expected no reference without an ID, got %q", line)
+	}
+}
+
+func TestRenderItemLine_TruncatesPreviewBeforeBadge(t *testing.T) {
+	item := &StackItem{Preview: "a very long preview that will not fit", MatchCount: 12}
+
+	line := renderItemLine(3, item, 20, false, false)
+	if got := lipgloss.Width(line); got > 20 {
+		t.Errorf("Line width %d exceeds 20: %q", got, line)
+	}
+	if !strings.HasSuffix(line, "... (12)") {
+		t.Errorf("Expected truncated preview followed by badge, got %q", line)
+	}
+	if !strings.HasPrefix(line, "3. a very") {
+		t.Errorf("Expected index prefix and start of preview, got %q", line)
+	}
+}
+
+func TestRenderItemLine_WideRunes(t *testing.T) {
+	// Each of these runes is two columns wide
+	item := &StackItem{Preview: "日本語のテキストです", MatchCount: 2}
+
+	line := renderItemLine(0, item, 16, false, false)
+	if got := lipgloss.Width(line); got > 16 {
+		t.Errorf("Line width %d exceeds 16: %q", got, line)
+	}
+	if !utf8.ValidString(line) {
+		t.Errorf("Truncation split a rune: %q", line)
+	}
+	if !strings.HasSuffix(line, "(2)") {
+		t.Errorf("Expected badge to survive truncation, got %q", line)
+	}
+}
+
+func TestSplitMatches(t *testing.T) {
+	parts := splitMatches("Error: disk error", "error")
+	var marked strings.Builder
+	for _, p := range parts {
+		if p.match {
+			marked.WriteString("[" + p.text + "]")
+		} else {
+			marked.WriteString(p.text)
+		}
+	}
+	if got := marked.String(); got != "[Error]: disk [error]" {
+		t.Errorf("splitMatches = %q", got)
+	}
+
+	if parts := splitMatches("abc", "("); len(parts) != 1 || parts[0].match {
+		t.Errorf("Invalid pattern should leave text unmarked, got %+v", parts)
+	}
+}
+
+func TestDateBucket(t *testing.T) {
+	loc := time.FixedZone("test", -5*60*60)
+	now := time.Date(2024, 3, 14, 0, 5, 0, 0, loc) // just after midnight
+
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Date(2024, 3, 14, 0, 0, 0, 0, loc), "Today"},
+		{time.Date(2024, 3, 13, 23, 59, 59, 0, loc), "Yesterday"},
+		{time.Date(2024, 3, 13, 0, 0, 0, 0, loc), "Yesterday"},
+		{time.Date(2024, 3, 12, 23, 59, 59, 0, loc), "This week"},
+		{time.Date(2024, 3, 8, 0, 0, 0, 0, loc), "This week"},
+		{time.Date(2024, 3, 7, 23, 59, 59, 0, loc), "Older"},
+		// 04:00 UTC on the 14th is still the 13th in now's location
+		{time.Date(2024, 3, 14, 4, 0, 0, 0, time.UTC), "Yesterday"},
+		{time.Time{}, "Older"},
+	}
+	for _, tt := range tests {
+		if got := dateBucket(tt.t, now); got != tt.want {
+			t.Errorf("dateBucket(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestLeftPaneView_GroupByDate(t *testing.T) {
+	now := time.Now()
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for i, age := range []time.Duration{0, 0, 24 * time.Hour, 30 * 24 * time.Hour} {
+		item := ops.item(uint(i+1), fmt.Sprintf("content %d", i))
+		item.Preview = fmt.Sprintf("item %d", i)
+		item.Timestamp = now.Add(-age)
+		items = append(items, item)
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.SetItemOps(ops)
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	app.LeftPane.GroupByDate = true
+
+	rows := leftPaneRows(app.Items, true, now)
+	var layout []string
+	for _, row := range rows {
+		if row.item < 0 {
+			layout = append(layout, row.header)
+		} else {
+			layout = append(layout, app.Items[row.item].Preview)
+		}
+	}
+	if got := strings.Join(layout, ", "); got != "Today, item 0, item 1, Yesterday, item 2, Older, item 3" {
+		t.Errorf("unexpected rows: %s", got)
+	}
+
+	view, _ := LeftPaneView(app.LeftPane, app.Items, true)
+	for _, header := range []string{"Today", "Yesterday", "Older"} {
+		if !strings.Contains(view, header) {
+			t.Errorf("expected the %q header in the view:\n%s", header, view)
+		}
+	}
+
+	// j moves from item to item, stepping over the headers between them
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+	app.Update(j)
+	app.Update(j)
+	if app.LeftPane.Selected != 2 {
+		t.Fatalf("expected item 2 selected after two moves, got %d", app.LeftPane.Selected)
+	}
+
+	// Delete removes the selected item, not whatever row it is displayed on
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if len(ops.deleted) != 1 || ops.deleted[0] != 3 {
+		t.Errorf("expected item ID 3 deleted, got %v", ops.deleted)
+	}
+	if len(app.Items) != 3 || app.Items[2].Preview != "item 3" {
+		t.Errorf("unexpected items after delete: %d", len(app.Items))
+	}
+}
+
+func TestScrollRows(t *testing.T) {
+	rows := []leftPaneRow{{item: -1, header: "Today"}, {item: 0}, {item: 1}, {item: -1, header: "Older"}, {item: 2}, {item: 3}}
+
+	if got := scrollRows(rows, 0, 3); got[0].header != "Today" || len(got) != 3 {
+		t.Errorf("expected the first rows at the top, got %+v", got)
+	}
+	// Scrolled to the third item, its header is on the line above it
+	got := scrollRows(rows, 2, 3)
+	if len(got) != 3 || got[2].item != 2 || got[1].header != "Older" {
+		t.Errorf("expected the cursor's item last, under its header, got %+v", got)
+	}
+	if got := scrollRows(rows, 3, 0); len(got) != len(rows) {
+		t.Errorf("expected every row without a height, got %d", len(got))
+	}
+}
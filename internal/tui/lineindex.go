@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+// wrapSegmentBytes bounds how much of a single source line is wrapped at once.
+// Longer lines are wrapped segment by segment with a hard break between
+// segments, so a huge minified line is never wrapped in one shot.
+const wrapSegmentBytes = 16 * 1024
+
+// checkpointInterval is the minimum distance in bytes between wrap checkpoints
+const checkpointInterval = 16 * 1024
+
+// wrapCheckpoint marks the start of a segment and the display line it wraps to
+type wrapCheckpoint struct {
+	Offset      int64 // byte offset of the segment in the content
+	DisplayLine int   // display line of the segment's first wrapped line
+}
+
+// lineIndex maps byte offsets to wrapped display lines for a single width,
+// tab width, and reading of invalid UTF-8. It is filled in lazily as content
+// is scanned.
+type lineIndex struct {
+	width       int
+	tabWidth    int
+	latin1      bool
+	checkpoints []wrapCheckpoint // ascending by Offset and DisplayLine
+	frontier    wrapCheckpoint   // furthest position scanned so far
+	done        bool             // true once a scan reached the end of content
+}
+
+// newLineIndex creates an empty index for the given wrap and tab widths
+func newLineIndex(width, tabWidth int, latin1 bool) *lineIndex {
+	return &lineIndex{
+		width:       width,
+		tabWidth:    tabWidth,
+		latin1:      latin1,
+		checkpoints: []wrapCheckpoint{{Offset: 0, DisplayLine: 0}},
+	}
+}
+
+// record adds a checkpoint if offset is far enough past the last one
+func (idx *lineIndex) record(offset int64, displayLine int) {
+	last := idx.checkpoints[len(idx.checkpoints)-1]
+	if offset-last.Offset >= checkpointInterval {
+		idx.checkpoints = append(idx.checkpoints, wrapCheckpoint{Offset: offset, DisplayLine: displayLine})
+	}
+}
+
+// advance moves the frontier forward to offset
+func (idx *lineIndex) advance(offset int64, displayLine int, eof bool) {
+	if offset > idx.frontier.Offset {
+		idx.frontier = wrapCheckpoint{Offset: offset, DisplayLine: displayLine}
+	}
+	if eof {
+		idx.done = true
+	}
+}
+
+// checkpointForLine returns the last checkpoint at or before displayLine
+func (idx *lineIndex) checkpointForLine(displayLine int) wrapCheckpoint {
+	i := sort.Search(len(idx.checkpoints), func(i int) bool {
+		return idx.checkpoints[i].DisplayLine > displayLine
+	})
+	return idx.checkpoints[max(i-1, 0)]
+}
+
+// checkpointForOffset returns the last checkpoint at or before offset
+func (idx *lineIndex) checkpointForOffset(offset int64) wrapCheckpoint {
+	i := sort.Search(len(idx.checkpoints), func(i int) bool {
+		return idx.checkpoints[i].Offset > offset
+	})
+	return idx.checkpoints[max(i-1, 0)]
+}
+
+// wrappedSegment is a source segment after wrapping
+type wrappedSegment struct {
+	offset      int64    // byte offset of the segment in the content
+	displayLine int      // display line of the first wrapped line
+	lines       []string // wrapped lines
+	starts      []int    // offset of each wrapped line relative to offset
+	continued   bool     // true if the segment continues the previous one's source line
+	text        string   // the segment's source text
+}
+
+// wrapSegment wraps a source segment to width, or keeps it as a single
+// display line when width is noWrapWidth. The lines are in the form
+// displayText shows; their starts are offsets in text.
+func wrapSegment(text string, width, tabWidth int, latin1 bool) ([]string, []int) {
+	shown, sources := displayText(text, tabWidth, latin1)
+	var lines []string
+	var starts []int
+	if width == noWrapWidth {
+		lines, starts = []string{shown}, []int{0}
+	} else {
+		lines, starts = wrapWithOffsets(shown, width)
+	}
+	for i, start := range starts {
+		starts[i] = sources.source(start)
+	}
+	return lines, starts
+}
+
+// ensureIndex prepares the pager and a line index for width
+func (q *StackItem) ensureIndex(width int) error {
+	if err := q.ensureOpen(); err != nil {
+		return err
+	}
+	if q.pager == nil {
+		q.pager = NewPager(&contentReader{item: q})
+	}
+	if q.index == nil || q.index.width != width || q.index.tabWidth != q.tabStop() || q.index.latin1 != q.latin1 {
+		q.index = newLineIndex(width, q.tabStop(), q.latin1)
+	}
+	return q.detectDiff()
+}
+
+// scanFrom wraps content starting at cp and calls visit for each non-empty
+// segment until visit returns false or the content is exhausted. Empty source
+// lines are skipped, matching how the viewer displays content.
+func (q *StackItem) scanFrom(cp wrapCheckpoint, visit func(seg wrappedSegment) bool) error {
+	idx := q.index
+	if _, err := q.pager.Seek(cp.Offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	displayLine := cp.DisplayLine
+	continued := false
+	for {
+		offset := q.pager.Offset()
+		segment, more, err := q.pager.ReadLineSegment(wrapSegmentBytes)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		idx.record(offset, displayLine)
+
+		keepGoing := true
+		if text := segmentText(segment); text != "" {
+			if !utf8.ValidString(text) {
+				q.nonUTF8 = true
+			}
+			lines, starts := wrapSegment(text, idx.width, idx.tabWidth, idx.latin1)
+			seg := wrappedSegment{offset: offset, displayLine: displayLine, lines: lines, starts: starts, continued: continued, text: text}
+			displayLine += len(lines)
+			keepGoing = visit(seg)
+		}
+		continued = more
+		idx.advance(q.pager.Offset(), displayLine, err == io.EOF)
+
+		if err == io.EOF || !keepGoing {
+			return nil
+		}
+	}
+}
+
+// loadWindow materializes display lines [start, end) into Lines
+func (q *StackItem) loadWindow(start, end int) error {
+	q.Lines = nil
+	q.lineOffsets = nil
+	q.lineKinds = nil
+	q.links = nil
+	q.LinesStart = start
+	q.LinesEnd = start
+	q.linesAtEOF = false
+
+	reachedEOF := true
+	// Wrapped lines take the diff kind of their source line. A window that
+	// starts inside a line longer than wrapSegmentBytes can't see where the
+	// line began, so its first segment is treated as a line start.
+	kind := diffContext
+	err := q.scanFrom(q.index.checkpointForLine(start), func(seg wrappedSegment) bool {
+		if q.isDiff && !seg.continued {
+			kind = classifyDiffLine(seg.lines[0])
+		}
+		if seg.displayLine+len(seg.lines) > start {
+			q.findLinks(seg)
+		}
+		for i, line := range seg.lines {
+			n := seg.displayLine + i
+			if n >= end {
+				reachedEOF = false
+				return false
+			}
+			if n >= start {
+				q.Lines = append(q.Lines, line)
+				q.lineOffsets = append(q.lineOffsets, seg.offset+int64(seg.starts[i]))
+				if q.isDiff {
+					q.lineKinds = append(q.lineKinds, kind)
+				}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	q.LinesEnd = start + len(q.Lines)
+	q.linesAtEOF = reachedEOF
+	return nil
+}
+
+// displayLineForOffset returns the display line containing the byte at offset
+func (q *StackItem) displayLineForOffset(offset int64) (int, error) {
+	cp := q.index.checkpointForOffset(offset)
+	line := cp.DisplayLine
+	err := q.scanFrom(cp, func(seg wrappedSegment) bool {
+		for i, start := range seg.starts {
+			if seg.offset+int64(start) > offset {
+				return false
+			}
+			line = seg.displayLine + i
+		}
+		return true
+	})
+	return line, err
+}
+
+// scanToEnd wraps the remaining content so the total line count is known
+func (q *StackItem) scanToEnd() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.index == nil || q.index.done {
+		return nil
+	}
+	return q.scanFrom(q.index.frontier, func(wrappedSegment) bool { return true })
+}
+
+// knownLineCount returns the number of display lines known to exist and
+// whether that count covers the whole content
+func (q *StackItem) knownLineCount() (int, bool) {
+	if q.index == nil {
+		return q.LinesEnd, q.IsBinary
+	}
+	return max(q.index.frontier.DisplayLine, q.LinesEnd), q.index.done
+}
+
+// isEmpty reports whether the text content is known to have no display lines
+func (q *StackItem) isEmpty() bool {
+	total, known := q.knownLineCount()
+	return !q.IsBinary && known && total == 0
+}
+
+// contentSize returns the content size in bytes, preferring the size reported
+// by the pager over the stored Size
+func (q *StackItem) contentSize() int64 {
+	if q.pager != nil {
+		if size, err := q.pager.Size(); err == nil {
+			return size
+		}
+	}
+	return q.Size
+}
+
+// lineAt returns display line i if it is inside the loaded window
+func (q *StackItem) lineAt(i int) (string, bool) {
+	if i < q.LinesStart || i >= q.LinesEnd {
+		return "", false
+	}
+	return q.Lines[i-q.LinesStart], true
+}
+
+// offsetAt returns the byte offset at which display line i starts if it is
+// inside the loaded window
+func (q *StackItem) offsetAt(i int) (int64, bool) {
+	if i < q.LinesStart || i >= q.LinesStart+len(q.lineOffsets) {
+		return 0, false
+	}
+	return q.lineOffsets[i-q.LinesStart], true
+}
+
+// byteProgress returns how far into the content display line i starts, as a percentage
+func (q *StackItem) byteProgress(i int) int {
+	if q.pager == nil || i < q.LinesStart || i >= q.LinesEnd {
+		return 0
+	}
+	size, err := q.pager.Size()
+	if err != nil || size == 0 {
+		return 0
+	}
+	return int(q.lineOffsets[i-q.LinesStart] * 100 / size)
+}
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yiblet/rem/internal/opener"
+)
+
+// maxLinkChoices is how many links the open-link picker offers, one per
+// digit key
+const maxLinkChoices = 9
+
+// linkStyle marks URLs in the right pane. The underline shows links
+// without color too.
+var linkStyle = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("39"))
+
+// link is a URL found in the source content, by byte offset, so a URL
+// wrapped over several display lines is still found whole
+type link struct {
+	url        string
+	start, end int64
+}
+
+// findLinks records the URLs in seg's source text. Only segments of the
+// loaded window are searched, so detection costs no more than display.
+func (q *StackItem) findLinks(seg wrappedSegment) {
+	if !strings.Contains(seg.text, "://") {
+		return
+	}
+	for _, r := range opener.FindURLs(seg.text) {
+		q.links = append(q.links, link{
+			url:   seg.text[r[0]:r[1]],
+			start: seg.offset + int64(r[0]),
+			end:   seg.offset + int64(r[1]),
+		})
+	}
+}
+
+// lineLinks returns the byte ranges of display line i that show part of a
+// URL. A range is only returned where the line shows the URL's text, which
+// it may not where tabs or control characters before it were expanded.
+func (q *StackItem) lineLinks(i int) [][2]int {
+	offset, ok := q.offsetAt(i)
+	line, _ := q.lineAt(i)
+	if !ok || len(q.links) == 0 {
+		return nil
+	}
+	lineEnd := offset + int64(len(line))
+
+	var ranges [][2]int
+	for _, l := range q.links {
+		if l.end <= offset || l.start >= lineEnd {
+			continue
+		}
+		part := l.url[max64(offset-l.start, 0) : l.end-l.start-max64(l.end-lineEnd, 0)]
+		at := int(max64(l.start-offset, 0))
+		if !strings.HasPrefix(line[at:], part) {
+			if at = strings.Index(line, part); at < 0 {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{at, at + len(part)})
+	}
+	return ranges
+}
+
+// visibleLinks returns the URLs shown on display lines [start, end), in
+// order and without repeats, each taken whole from the source content
+func (q *StackItem) visibleLinks(start, end int) []string {
+	from, ok := q.offsetAt(start)
+	if !ok {
+		return nil
+	}
+	to, ok := q.offsetAt(end)
+	if !ok {
+		to = math.MaxInt64
+	}
+
+	var urls []string
+	seen := map[string]bool{}
+	for _, l := range q.links {
+		if l.end > from && l.start < to && !seen[l.url] {
+			seen[l.url] = true
+			urls = append(urls, l.url)
+		}
+	}
+	return urls
+}
+
+// renderLinks returns line[lo:hi] with the parts in ranges rendered as
+// links and the rest by plain, if not nil
+func renderLinks(line string, lo, hi int, ranges [][2]int, plain func(string) string) string {
+	if plain == nil {
+		plain = func(s string) string { return s }
+	}
+	var b strings.Builder
+	pos := lo
+	for _, r := range ranges {
+		start, end := max(r[0], pos), min(r[1], hi)
+		if start >= end {
+			continue
+		}
+		if start > pos {
+			b.WriteString(plain(line[pos:start]))
+		}
+		b.WriteString(linkStyle.Render(line[start:end]))
+		pos = end
+	}
+	if pos < hi {
+		b.WriteString(plain(line[pos:hi]))
+	}
+	return b.String()
+}
+
+// openLink opens the link shown in the right pane, or asks which to open
+// when several are shown
+func (a *AppModel) openLink() tea.Cmd {
+	if a.LeftPane.Selected >= len(a.Items) || a.Items[a.LeftPane.Selected] == nil {
+		return a.setFlashMessage("No item selected", 2*time.Second)
+	}
+	item := a.Items[a.LeftPane.Selected]
+	height := max(a.RightPane.contentHeight(), 1)
+	item.ViewPos = a.RightPane.ViewPos
+	item.setTabWidth(a.RightPane.TabWidth)
+	item.setLatin1(a.RightPane.Latin1)
+	if err := item.UpdateWrappedLines(a.RightPane.wrapWidth(), height); err != nil {
+		return a.setFlashMessage(fmt.Sprintf("Failed to read item: %v", err), 2*time.Second)
+	}
+
+	urls := item.visibleLinks(a.RightPane.ViewPos, a.RightPane.ViewPos+height)
+	switch len(urls) {
+	case 0:
+		return a.setFlashMessage("No links in view", 2*time.Second)
+	case 1:
+		return a.openURL(urls[0])
+	}
+	a.pendingLinks = urls[:min(len(urls), maxLinkChoices)]
+	a.CurrentMode = LinkMode
+	a.Modal.Update(ShowLinkPicker(a.pendingLinks))
+	return nil
+}
+
+// openURL opens url and flashes what happened
+func (a *AppModel) openURL(url string) tea.Cmd {
+	if a.opener == nil {
+		return a.setFlashMessage("Opening links is not available", 2*time.Second)
+	}
+	if err := a.opener(url); err != nil {
+		return a.setFlashMessage(err.Error(), 2*time.Second)
+	}
+	return a.setFlashMessage("Opened "+url, 2*time.Second)
+}
+
+// handleLinkResult opens the link picked in the open-link picker
+func (a *AppModel) handleLinkResult(result ModalResult) tea.Cmd {
+	links := a.pendingLinks
+	a.pendingLinks = nil
+	a.CurrentMode = NormalMode
+	for i, url := range links {
+		if result == linkChoice(i) {
+			return a.openURL(url)
+		}
+	}
+	return nil
+}
+
+// linkChoice is the result reported by the picker button of link i
+func linkChoice(i int) ModalResult {
+	return ModalResult(fmt.Sprintf("link%d", i+1))
+}
+
+// ShowLinkPicker creates a modal listing urls by number, each opened by
+// its digit
+func ShowLinkPicker(urls []string) ShowModalMsg {
+	var body strings.Builder
+	buttons := make([]ModalButton, 0, len(urls)+1)
+	for i, url := range urls {
+		shown, ellipsis := truncateToWidth(url, 50)
+		fmt.Fprintf(&body, "%d. %s%s\n", i+1, shown, ellipsis)
+		buttons = append(buttons, ModalButton{Key: strconv.Itoa(i + 1), Result: linkChoice(i)})
+	}
+	buttons = append(buttons, ModalButton{Label: "Cancel", Key: "c", Result: ModalCancel})
+	return ShowModal("Open Link", strings.TrimSuffix(body.String(), "\n"), buttons)
+}
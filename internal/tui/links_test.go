@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newLinkTestApp returns an app showing content, recording the links it
+// opens in opened
+func newLinkTestApp(content string, opened *[]string) *AppModel {
+	items := []*StackItem{{Content: NewStringReadSeekCloser(content), Preview: "links"}}
+	app := NewAppModel(items, newTestClipboard())
+	app.opener = func(url string) error {
+		*opened = append(*opened, url)
+		return nil
+	}
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	return &app
+}
+
+func TestOpenLink_Wrapped(t *testing.T) {
+	url := "https://example.com/" + strings.Repeat("segment/", 20) + "end"
+	var opened []string
+	app := newLinkTestApp("docs: "+url+".\nno more links\n", &opened)
+
+	view, _ := AppView(*app)
+	if strings.Contains(view, url) {
+		t.Fatalf("expected the URL to wrap:\n%s", view)
+	}
+
+	// Every wrapped piece of the URL is marked as a link
+	item := app.Items[0]
+	var marked strings.Builder
+	for i := item.LinesStart; i < item.LinesEnd; i++ {
+		line, _ := item.lineAt(i)
+		for _, r := range item.lineLinks(i) {
+			marked.WriteString(line[r[0]:r[1]])
+		}
+	}
+	if marked.String() != url {
+		t.Errorf("marked %q as links, want %q", marked.String(), url)
+	}
+
+	press(app, "o")
+	if len(opened) != 1 || opened[0] != url {
+		t.Fatalf("opened %q, want the whole URL", opened)
+	}
+	if app.FlashMessage != "Opened "+url {
+		t.Errorf("flash = %q", app.FlashMessage)
+	}
+}
+
+func TestOpenLink_Picker(t *testing.T) {
+	var opened []string
+	app := newLinkTestApp("https://a.example/1 and https://b.example/2\nagain https://a.example/1\n(https://c.example/3)\n", &opened)
+
+	press(app, "o")
+	if app.CurrentMode != LinkMode || !app.Modal.Active || len(opened) != 0 {
+		t.Fatalf("expected a picker for several links, got mode %d and opened %q", app.CurrentMode, opened)
+	}
+	view, _ := AppView(*app)
+	for _, want := range []string{"1. https://a.example/1", "2. https://b.example/2", "3. https://c.example/3"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("picker doesn't list %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "4. ") {
+		t.Errorf("expected a repeated link to be listed once:\n%s", view)
+	}
+
+	press(app, "2")
+	if len(opened) != 1 || opened[0] != "https://b.example/2" || app.CurrentMode != NormalMode || app.Modal.Active {
+		t.Errorf("picking 2 opened %q in mode %d", opened, app.CurrentMode)
+	}
+
+	press(app, "o")
+	app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(opened) != 1 || app.CurrentMode != NormalMode {
+		t.Errorf("cancelling the picker opened %q", opened)
+	}
+}
+
+func TestOpenLink_None(t *testing.T) {
+	var opened []string
+	app := newLinkTestApp("no links, just http:// and prose\n", &opened)
+	press(app, "o")
+	if len(opened) != 0 || app.FlashMessage != "No links in view" {
+		t.Errorf("opened %q with flash %q", opened, app.FlashMessage)
+	}
+}
@@ -12,11 +12,31 @@ type ModalMsg interface {
 	isModalMsg()
 }
 
+// ModalResult identifies which button dismissed a modal
+type ModalResult string
+
+// ModalCancel is the result reported when a modal is dismissed with esc
+const ModalCancel ModalResult = "cancel"
+
+// ModalButton is a button in a modal's button row. Key is a shortcut that
+// activates the button directly; it is matched case-insensitively.
+type ModalButton struct {
+	Label  string
+	Key    string
+	Result ModalResult
+}
+
+// ModalResultMsg reports the button that dismissed a modal
+type ModalResultMsg struct {
+	Result ModalResult
+}
+
 // Modal message implementations
 type ShowModalMsg struct {
 	Title   string
 	Content string
-	Options string
+	Buttons []ModalButton
+	Focus   int // index of the initially focused button
 }
 
 func (ShowModalMsg) isModalMsg() {}
@@ -30,11 +50,17 @@ type ModalModel struct {
 	Active  bool
 	Title   string
 	Content string
-	Options string
+	Buttons []ModalButton
+	Focus   int // index of the focused button
 	Width   int
 	Height  int
 }
 
+// ShowModal creates a message that opens a modal with the given buttons
+func ShowModal(title, body string, buttons []ModalButton) ShowModalMsg {
+	return ShowModalMsg{Title: title, Content: body, Buttons: buttons}
+}
+
 // NewModalModel creates a new modal model
 func NewModalModel() ModalModel {
 	return ModalModel{
@@ -51,16 +77,71 @@ func (m *ModalModel) Update(msg ModalMsg) error {
 		m.Active = true
 		m.Title = msg.Title
 		m.Content = msg.Content
-		m.Options = msg.Options
+		m.Buttons = msg.Buttons
+		m.Focus = 0
+		if msg.Focus >= 0 && msg.Focus < len(msg.Buttons) {
+			m.Focus = msg.Focus
+		}
 	case HideModalMsg:
 		m.Active = false
 		m.Title = ""
 		m.Content = ""
-		m.Options = ""
+		m.Buttons = nil
+		m.Focus = 0
 	}
 	return nil
 }
 
+// HandleKey moves focus between buttons or activates one. It returns the
+// result and true when key dismisses the modal.
+func (m *ModalModel) HandleKey(key string) (ModalResultMsg, bool) {
+	switch key {
+	case "esc":
+		return ModalResultMsg{Result: ModalCancel}, true
+	case "left", "shift+tab":
+		if len(m.Buttons) > 0 {
+			m.Focus = (m.Focus - 1 + len(m.Buttons)) % len(m.Buttons)
+		}
+		return ModalResultMsg{}, false
+	case "right", "tab":
+		if len(m.Buttons) > 0 {
+			m.Focus = (m.Focus + 1) % len(m.Buttons)
+		}
+		return ModalResultMsg{}, false
+	case "enter":
+		if m.Focus < len(m.Buttons) {
+			return ModalResultMsg{Result: m.Buttons[m.Focus].Result}, true
+		}
+		return ModalResultMsg{Result: ModalCancel}, true
+	}
+
+	for _, button := range m.Buttons {
+		if button.Key != "" && strings.EqualFold(button.Key, key) {
+			return ModalResultMsg{Result: button.Result}, true
+		}
+	}
+	return ModalResultMsg{}, false
+}
+
+// renderButtons renders the button row with the focused button highlighted
+func renderButtons(model ModalModel) string {
+	focused := lipgloss.NewStyle().Reverse(true).Bold(true)
+	labels := make([]string, len(model.Buttons))
+	for i, button := range model.Buttons {
+		label := button.Label
+		if button.Key != "" && label == "" {
+			label = fmt.Sprintf("[%s]", strings.ToUpper(button.Key))
+		} else if button.Key != "" {
+			label = fmt.Sprintf("[%s] %s", strings.ToUpper(button.Key), button.Label)
+		}
+		if i == model.Focus {
+			label = focused.Render(label)
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, "    ")
+}
+
 // ModalView renders the modal as a pure function
 func ModalView(model ModalModel, backgroundView string, windowWidth, windowHeight int) string {
 	if !model.Active {
@@ -72,8 +153,8 @@ func ModalView(model ModalModel, backgroundView string, windowWidth, windowHeigh
 	if model.Content != "" {
 		modalContent += "\n\n" + model.Content
 	}
-	if model.Options != "" {
-		modalContent += "\n\n" + model.Options
+	if len(model.Buttons) > 0 {
+		modalContent += "\n\n" + renderButtons(model)
 	}
 
 	// Calculate modal dimensions
@@ -161,14 +242,52 @@ func ModalView(model ModalModel, backgroundView string, windowWidth, windowHeigh
 	return result.String()
 }
 
-// ShowDeleteConfirmation creates a delete confirmation modal
+// Results reported by the delete confirmation and delete error modals
+const (
+	ModalDelete ModalResult = "delete"
+	ModalRetry  ModalResult = "retry"
+)
+
+// ShowDeleteConfirmation creates a delete confirmation modal. The cancel
+// button is focused so Enter does not delete by accident.
 func ShowDeleteConfirmation(itemPreview string, itemIndex int) ShowModalMsg {
-	return ShowModalMsg{
-		Title: "Delete Item?",
-		Content: fmt.Sprintf("Item: %s\nIndex: %d\n\nAre you sure you want to delete this item?",
+	msg := ShowModal("Delete Item?",
+		fmt.Sprintf("Item: %s\nIndex: %d\n\nAre you sure you want to delete this item?",
 			itemPreview, itemIndex),
-		Options: "[Y] Yes, delete    [N] No, cancel",
-	}
+		[]ModalButton{
+			{Label: "Yes, delete", Key: "y", Result: ModalDelete},
+			{Label: "No, cancel", Key: "n", Result: ModalCancel},
+		})
+	msg.Focus = 1
+	return msg
+}
+
+// ModalOverwrite is the result reported by the overwrite confirmation's
+// yes button
+const ModalOverwrite ModalResult = "overwrite"
+
+// ShowOverwriteConfirmation creates a modal asking whether to copy over
+// size bytes of clipboard content rem didn't put there. The cancel button
+// is focused so Enter keeps the clipboard.
+func ShowOverwriteConfirmation(size int64) ShowModalMsg {
+	msg := ShowModal("Overwrite Clipboard?",
+		fmt.Sprintf("Clipboard has %s of other content.\n\nOverwrite it?", formatBytes(size)),
+		[]ModalButton{
+			{Label: "Yes, overwrite", Key: "y", Result: ModalOverwrite},
+			{Label: "No, keep it", Key: "n", Result: ModalCancel},
+		})
+	msg.Focus = 1
+	return msg
+}
+
+// ShowDeleteError creates a modal reporting a failed delete
+func ShowDeleteError(err error) ShowModalMsg {
+	return ShowModal("Delete Error",
+		fmt.Sprintf("Failed to delete item: %v", err),
+		[]ModalButton{
+			{Label: "Retry", Key: "r", Result: ModalRetry},
+			{Label: "Cancel", Key: "c", Result: ModalCancel},
+		})
 }
 
 // truncateToVisualWidth truncates a styled string to the specified visual width
@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func testButtons() []ModalButton {
+	return []ModalButton{
+		{Label: "Retry", Key: "r", Result: "retry"},
+		{Label: "Skip", Key: "s", Result: "skip"},
+		{Label: "Cancel", Key: "c", Result: ModalCancel},
+	}
+}
+
+func TestModalModel_FocusCycling(t *testing.T) {
+	modal := NewModalModel()
+	modal.Update(ShowModal("Title", "Body", testButtons()))
+
+	steps := []struct {
+		key  string
+		want int
+	}{
+		{"right", 1},
+		{"tab", 2},
+		{"right", 0}, // wraps forward
+		{"left", 2},  // wraps backward
+		{"shift+tab", 1},
+	}
+	for _, step := range steps {
+		if _, done := modal.HandleKey(step.key); done {
+			t.Fatalf("%q should not dismiss the modal", step.key)
+		}
+		if modal.Focus != step.want {
+			t.Errorf("After %q focus = %d, want %d", step.key, modal.Focus, step.want)
+		}
+	}
+
+	result, done := modal.HandleKey("enter")
+	if !done || result.Result != "skip" {
+		t.Errorf("Enter should activate the focused button, got %+v (done=%v)", result, done)
+	}
+}
+
+func TestModalModel_ButtonShortcuts(t *testing.T) {
+	modal := NewModalModel()
+	modal.Update(ShowModal("Title", "Body", testButtons()))
+
+	tests := []struct {
+		key  string
+		want ModalResult
+		done bool
+	}{
+		{"r", "retry", true},
+		{"S", "skip", true}, // shortcuts ignore case
+		{"esc", ModalCancel, true},
+		{"x", "", false},
+	}
+	for _, tt := range tests {
+		result, done := modal.HandleKey(tt.key)
+		if done != tt.done || result.Result != tt.want {
+			t.Errorf("HandleKey(%q) = %+v (done=%v), want %q (done=%v)", tt.key, result, done, tt.want, tt.done)
+		}
+	}
+	if modal.Focus != 0 {
+		t.Errorf("Shortcuts should not move focus, got %d", modal.Focus)
+	}
+}
+
+func TestModalView_RendersButtons(t *testing.T) {
+	modal := NewModalModel()
+	modal.Update(ShowModal("Pick one", "Body text", testButtons()))
+
+	background := strings.Repeat(strings.Repeat(".", 80)+"\n", 23) + strings.Repeat(".", 80)
+	view := ModalView(modal, background, 80, 24)
+
+	for _, want := range []string{"Pick one", "Body text", "[R] Retry", "[S] Skip", "[C] Cancel"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Modal view missing %q", want)
+		}
+	}
+	if got := len(strings.Split(view, "\n")); got != 24 {
+		t.Errorf("Modal view has %d lines, want 24", got)
+	}
+}
+
+func TestAppModel_RoutesModalResult(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("Item 0"), Preview: "Item 0"},
+		{Content: NewStringReadSeekCloser("Item 1"), Preview: "Item 1"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+
+	// A result delivered as a message is handled like a button press
+	app.Update(ModalResultMsg{Result: ModalDelete})
+	if app.Modal.Active || app.CurrentMode != NormalMode {
+		t.Error("Delete result should close the modal and return to normal mode")
+	}
+	if len(app.Items) != 1 || app.Items[0].Preview != "Item 1" {
+		t.Errorf("Expected only 'Item 1' to remain, got %d items", len(app.Items))
+	}
+}
+
+func TestAppModel_DeleteConfirmWithFocus(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("Item 0"), Preview: "Item 0"},
+		{Content: NewStringReadSeekCloser("Item 1"), Preview: "Item 1"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+
+	// Cancel is focused by default, so Enter keeps the item
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.Items) != 2 || app.CurrentMode != NormalMode {
+		t.Fatalf("Enter on the default focus should cancel, got %d items in mode %v", len(app.Items), app.CurrentMode)
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	app.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(app.Items) != 1 {
+		t.Errorf("Enter on the focused delete button should delete, got %d items", len(app.Items))
+	}
+}
+
+func TestAppModel_DeleteErrorRetry(t *testing.T) {
+	ops := newFakeItemOps()
+	ops.deleteErr = errors.New("database is locked")
+	app := NewAppModel([]*StackItem{ops.item(1, "Item 0")}, newTestClipboard())
+	app.SetItemOps(ops)
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+
+	if !app.Modal.Active || app.Modal.Title != "Delete Error" {
+		t.Fatalf("Expected the delete error modal, got active=%v title=%q", app.Modal.Active, app.Modal.Title)
+	}
+	if !strings.Contains(app.Modal.Content, "database is locked") {
+		t.Errorf("Error modal should show the error, got %q", app.Modal.Content)
+	}
+	if len(app.Items) != 1 {
+		t.Fatalf("Failed delete should keep the item, got %d items", len(app.Items))
+	}
+
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if len(ops.deleted) != 1 || len(app.Items) != 0 {
+		t.Errorf("Retry should delete the item, got deleted %v and %d items", ops.deleted, len(app.Items))
+	}
+	if app.Modal.Active || app.CurrentMode != NormalMode {
+		t.Error("Successful retry should close the modal and return to normal mode")
+	}
+}
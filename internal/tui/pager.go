@@ -2,20 +2,27 @@ package tui
 
 import (
 	"bufio"
+	"bytes"
 	"io"
+	"unicode/utf8"
 )
 
+// pagerBufferSize is the size of the Pager's read buffer. It bounds the
+// largest segment ReadLineSegment can return.
+const pagerBufferSize = 64 * 1024
+
 // Pager provides buffered reading with seek support for streaming content display
 type Pager struct {
-	inner io.ReadSeeker
-	buf   *bufio.Reader
+	inner  io.ReadSeeker
+	buf    *bufio.Reader
+	offset int64 // byte offset of the next unread byte
 }
 
 // NewPager creates a new Pager wrapping the given ReadSeeker
 func NewPager(r io.ReadSeeker) *Pager {
 	return &Pager{
 		inner: r,
-		buf:   bufio.NewReader(r),
+		buf:   bufio.NewReaderSize(r, pagerBufferSize),
 	}
 }
 
@@ -26,22 +33,100 @@ func (p *Pager) Seek(offset int64, whence int) (int64, error) {
 		return pos, err
 	}
 	p.buf.Reset(p.inner)
+	p.offset = pos
 	return pos, nil
 }
 
+// Offset returns the byte offset of the next byte that will be read
+func (p *Pager) Offset() int64 {
+	return p.offset
+}
+
+// Size returns the total size of the underlying content in bytes.
+// The current read position is preserved.
+func (p *Pager) Size() (int64, error) {
+	size, err := p.inner.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	// Restore the underlying position to just past the buffered data
+	if _, err := p.inner.Seek(p.offset+int64(p.buf.Buffered()), io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
 // ReadLine reads a line from the pager (up to and including newline)
 func (p *Pager) ReadLine() (string, error) {
-	return p.buf.ReadString('\n')
+	line, err := p.buf.ReadString('\n')
+	p.offset += int64(len(line))
+	return line, err
+}
+
+// ReadLineSegment reads at most limit bytes of the current line.
+// The returned segment includes the trailing newline when the end of the line
+// was reached. more is true when the line continues past the segment, in which
+// case the segment is cut on a UTF-8 rune boundary. limit is capped at the
+// pager's buffer size.
+func (p *Pager) ReadLineSegment(limit int) (segment string, more bool, err error) {
+	if limit <= 0 || limit > pagerBufferSize {
+		limit = pagerBufferSize
+	}
+
+	peeked, peekErr := p.buf.Peek(limit)
+	if len(peeked) == 0 {
+		if peekErr == nil {
+			peekErr = io.EOF
+		}
+		return "", false, peekErr
+	}
+
+	take := len(peeked)
+	if i := bytes.IndexByte(peeked, '\n'); i >= 0 {
+		take = i + 1
+	} else if peekErr == nil {
+		// The line continues past this segment; avoid splitting a rune
+		more = true
+		take = runeBoundary(peeked)
+	}
+
+	segment = string(peeked[:take])
+	p.buf.Discard(take)
+	p.offset += int64(take)
+
+	if !more && take == len(peeked) && peekErr != nil && segment[len(segment)-1] != '\n' {
+		// Final segment of the content
+		return segment, false, io.EOF
+	}
+	return segment, more, nil
+}
+
+// runeBoundary returns the largest length <= len(b) that does not end in the
+// middle of a UTF-8 encoded rune.
+func runeBoundary(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) && i > 0 {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
 }
 
 // ReadRune reads a single rune (needed for regex matching)
 func (p *Pager) ReadRune() (rune, int, error) {
-	return p.buf.ReadRune()
+	r, size, err := p.buf.ReadRune()
+	p.offset += int64(size)
+	return r, size, err
 }
 
 // Read implements io.Reader interface
 func (p *Pager) Read(b []byte) (int, error) {
-	return p.buf.Read(b)
+	n, err := p.buf.Read(b)
+	p.offset += int64(n)
+	return n, err
 }
 
 // Close closes the underlying reader if it implements io.Closer
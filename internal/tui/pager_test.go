@@ -243,3 +243,77 @@ func TestPager_ReadAllThenSeekAndReadAgain(t *testing.T) {
 		t.Errorf("Content mismatch on second read: %v", lines)
 	}
 }
+
+func TestPager_ReadLineSegment(t *testing.T) {
+	content := "short\n" + strings.Repeat("x", 10) + "\nend"
+	pager := NewPager(strings.NewReader(content))
+
+	segment, more, err := pager.ReadLineSegment(4)
+	if err != nil || !more || segment != "shor" {
+		t.Fatalf("Expected (\"shor\", true, nil), got (%q, %v, %v)", segment, more, err)
+	}
+	segment, more, err = pager.ReadLineSegment(4)
+	if err != nil || more || segment != "t\n" {
+		t.Fatalf("Expected (\"t\\n\", false, nil), got (%q, %v, %v)", segment, more, err)
+	}
+	if pager.Offset() != 6 {
+		t.Errorf("Expected offset 6, got %d", pager.Offset())
+	}
+
+	// The long line comes back in pieces of at most limit bytes
+	var pieces []string
+	for {
+		segment, more, err = pager.ReadLineSegment(4)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pieces = append(pieces, segment)
+		if !more {
+			break
+		}
+	}
+	if got := strings.Join(pieces, ""); got != strings.Repeat("x", 10)+"\n" {
+		t.Errorf("Expected long line to be reassembled, got %q", got)
+	}
+
+	segment, more, err = pager.ReadLineSegment(4)
+	if err != io.EOF || more || segment != "end" {
+		t.Errorf("Expected (\"end\", false, EOF), got (%q, %v, %v)", segment, more, err)
+	}
+	if _, _, err = pager.ReadLineSegment(4); err != io.EOF {
+		t.Errorf("Expected EOF after content, got %v", err)
+	}
+}
+
+func TestPager_ReadLineSegmentRuneBoundary(t *testing.T) {
+	// "é" is two bytes; a limit of 2 must not split it after "a"
+	pager := NewPager(strings.NewReader("aé\n"))
+
+	segment, more, err := pager.ReadLineSegment(2)
+	if err != nil || !more || segment != "a" {
+		t.Errorf("Expected (\"a\", true, nil), got (%q, %v, %v)", segment, more, err)
+	}
+	segment, _, err = pager.ReadLineSegment(2)
+	if err != nil || segment != "é" {
+		t.Errorf("Expected \"é\", got %q (%v)", segment, err)
+	}
+}
+
+func TestPager_OffsetAfterSeek(t *testing.T) {
+	pager := NewPager(strings.NewReader("Line 1\nLine 2\n"))
+
+	if _, err := pager.Seek(7, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v", err)
+	}
+	if _, err := pager.ReadLine(); err != nil {
+		t.Fatalf("Failed to read line: %v", err)
+	}
+	if pager.Offset() != 14 {
+		t.Errorf("Expected offset 14, got %d", pager.Offset())
+	}
+
+	size, err := pager.Size()
+	if err != nil || size != 14 {
+		t.Errorf("Expected size 14, got %d (%v)", size, err)
+	}
+}
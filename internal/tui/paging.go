@@ -0,0 +1,155 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// LoadMoreFunc loads up to limit items stored before the item with
+// afterTimestamp and afterID, newest first. Paging by the last item's key
+// rather than an offset keeps newly stored items from shifting the pages.
+type LoadMoreFunc func(afterTimestamp time.Time, afterID uint, limit int) ([]*StackItem, error)
+
+// DefaultPageSize is the number of items loaded at a time when none is configured
+const DefaultPageSize = 200
+
+// loadAheadItems is how close the cursor gets to the last loaded item
+// before the next page is requested
+const loadAheadItems = 20
+
+// maxLoadedItems caps how many items G loads to reach the bottom
+const maxLoadedItems = 10000
+
+// Paging loads the left pane's items a page at a time. The zero value has
+// no loader, so every item is already loaded.
+type Paging struct {
+	Load     LoadMoreFunc
+	PageSize int
+	Total    int // items in the queue, loaded or not
+
+	// Position of the last item loaded; the next page starts after it
+	afterTimestamp time.Time
+	afterID        uint
+
+	loading  bool // a page is being fetched
+	done     bool // the last page has been loaded
+	toBottom bool // G is loading pages until the end
+}
+
+// loadMoreMsg carries a page fetched by LoadMoreFunc
+type loadMoreMsg struct {
+	items []*StackItem
+	err   error
+}
+
+func (loadMoreMsg) isAppMsg() {}
+
+// more reports whether there may be items left to load
+func (p Paging) more() bool {
+	return p.Load != nil && !p.done
+}
+
+// setPosition records the last item loaded so far
+func (p *Paging) setPosition(items []*StackItem) {
+	if len(items) == 0 {
+		return
+	}
+	last := items[len(items)-1]
+	p.afterTimestamp, p.afterID = last.Timestamp, last.ID
+}
+
+// loadMore starts fetching the next page unless one is in flight
+func (a *AppModel) loadMore() tea.Cmd {
+	if !a.Paging.more() || a.Paging.loading {
+		return nil
+	}
+	a.Paging.loading = true
+	load, after, afterID, limit := a.Paging.Load, a.Paging.afterTimestamp, a.Paging.afterID, a.Paging.PageSize
+	return func() tea.Msg {
+		items, err := load(after, afterID, limit)
+		return loadMoreMsg{items: items, err: err}
+	}
+}
+
+// loadAhead requests the next page once the cursor nears the end of the
+// loaded items
+func (a *AppModel) loadAhead() tea.Cmd {
+	if a.LeftPane.Cursor < len(a.Items)-loadAheadItems {
+		return nil
+	}
+	return a.loadMore()
+}
+
+// handleLoadMore appends a fetched page, skipping items already loaded,
+// and keeps going when G is waiting for the bottom
+func (a *AppModel) handleLoadMore(msg loadMoreMsg) tea.Cmd {
+	a.Paging.loading = false
+	if msg.err != nil {
+		a.Paging.toBottom = false
+		return a.setFlashMessage(fmt.Sprintf("Error loading more items: %v", msg.err), 3*time.Second)
+	}
+	if len(msg.items) < a.Paging.PageSize {
+		a.Paging.done = true
+	}
+	a.Paging.setPosition(msg.items)
+
+	loaded := make(map[uint]bool, len(a.Items))
+	for _, item := range a.Items {
+		loaded[item.ID] = true
+	}
+	for _, item := range msg.items {
+		if loaded[item.ID] {
+			continue
+		}
+		loaded[item.ID] = true
+		item.ops = a.ops
+		a.Items = append(a.Items, item)
+	}
+
+	if !a.Paging.toBottom {
+		return a.loadAhead()
+	}
+	if a.Paging.more() && len(a.Items) < maxLoadedItems {
+		return a.loadMore()
+	}
+	a.Paging.toBottom = false
+	a.goToBottom()
+	if a.Paging.more() {
+		return a.setFlashMessage(fmt.Sprintf("Stopped loading at %s items", formatCount(len(a.Items))), 3*time.Second)
+	}
+	return nil
+}
+
+// goToBottom selects the last loaded item
+func (a *AppModel) goToBottom() {
+	from, ok := a.currentEntry()
+	previous := a.LeftPane.Selected
+	a.LeftPane.Update(GoToBottomMsg{MaxIndex: len(a.Items) - 1})
+	a.releaseIfDeselected(previous)
+	a.RightPane.Update(UpdateContentMsg{})
+	a.syncSearchToSelection()
+	a.recordJump(from, ok)
+}
+
+// loadedFooter returns the left pane's "N of M loaded" line, or "" once
+// every item is loaded
+func (p Paging) loadedFooter(loaded int) string {
+	if !p.more() || p.Total <= loaded {
+		return ""
+	}
+	return fmt.Sprintf("%s of %s loaded", formatCount(loaded), formatCount(p.Total))
+}
+
+// formatCount formats n with thousands separators, like 9,431
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
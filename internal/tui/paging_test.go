@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fakeLoader pages through items stored newest first
+type fakeLoader struct {
+	items []*StackItem
+	calls []uint // afterID of each call
+	extra []*StackItem
+}
+
+// newFakeLoader returns a loader over n items, ID n newest
+func newFakeLoader(n int) *fakeLoader {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := &fakeLoader{}
+	for i := 0; i < n; i++ {
+		id := uint(n - i)
+		l.items = append(l.items, &StackItem{ID: id, Preview: fmt.Sprintf("item %d", id), Timestamp: base.Add(-time.Duration(i) * time.Minute)})
+	}
+	return l
+}
+
+func (l *fakeLoader) load(afterTimestamp time.Time, afterID uint, limit int) ([]*StackItem, error) {
+	l.calls = append(l.calls, afterID)
+	page := l.extra
+	l.extra = nil
+	for _, item := range l.items {
+		older := item.Timestamp.Before(afterTimestamp) || (item.Timestamp.Equal(afterTimestamp) && item.ID < afterID)
+		if older && len(page) < limit {
+			page = append(page, &StackItem{ID: item.ID, Preview: item.Preview, Timestamp: item.Timestamp})
+		}
+	}
+	return page, nil
+}
+
+// newPagedModel returns a viewer showing the first page of loader's items
+func newPagedModel(loader *fakeLoader, pageSize int) *Model {
+	var first []*StackItem
+	for _, item := range loader.items[:pageSize] {
+		first = append(first, &StackItem{ID: item.ID, Content: NewStringReadSeekCloser(item.Preview), Preview: item.Preview, Timestamp: item.Timestamp})
+	}
+	m := NewModel(first, newTestClipboard())
+	m.SetLoadMore(loader.load, pageSize, len(loader.items))
+	return &m
+}
+
+// finishLoad runs the page fetch the app started
+func finishLoad(t *testing.T, app *AppModel) {
+	t.Helper()
+	if !app.Paging.loading {
+		t.Fatal("no page is loading")
+	}
+	app.Paging.loading = false
+	app.Update(app.loadMore()())
+}
+
+func press(app *AppModel, keys string) {
+	app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)})
+}
+
+func TestPaging_LoadsNearTheEnd(t *testing.T) {
+	loader := newFakeLoader(250)
+	m := newPagedModel(loader, 100)
+	app := m.app
+
+	for range 79 {
+		press(app, "j")
+	}
+	if app.Paging.loading {
+		t.Fatalf("loading at cursor %d, want to wait until within %d of the end", app.LeftPane.Cursor, loadAheadItems)
+	}
+	press(app, "j")
+	finishLoad(t, app)
+
+	if len(app.Items) != 200 || len(loader.calls) != 1 || loader.calls[0] != 151 {
+		t.Fatalf("loaded %d items with calls %v, want 200 after ID 151", len(app.Items), loader.calls)
+	}
+	for i, item := range app.Items {
+		if want := uint(250 - i); item.ID != want {
+			t.Fatalf("Items[%d].ID = %d, want %d", i, item.ID, want)
+		}
+	}
+	// The cursor stays on the same item as the list grows
+	if app.LeftPane.Cursor != 80 || app.Items[app.LeftPane.Selected].ID != 170 {
+		t.Errorf("cursor = %d on ID %d, want 80 on 170", app.LeftPane.Cursor, app.Items[app.LeftPane.Selected].ID)
+	}
+	if footer := app.Paging.loadedFooter(len(app.Items)); footer != "200 of 250 loaded" {
+		t.Errorf("footer = %q, want %q", footer, "200 of 250 loaded")
+	}
+
+	// The last, short page ends paging
+	app.LeftPane.Update(JumpToIndexMsg{Index: 190, MaxIndex: 199})
+	press(app, "j")
+	finishLoad(t, app)
+	if len(app.Items) != 250 || app.Paging.more() {
+		t.Fatalf("loaded %d items, more = %v; want 250 and done", len(app.Items), app.Paging.more())
+	}
+	if footer := app.Paging.loadedFooter(len(app.Items)); footer != "" {
+		t.Errorf("footer = %q once every item is loaded, want none", footer)
+	}
+	press(app, "j")
+	if app.Paging.loading {
+		t.Error("loading after the last page")
+	}
+}
+
+func TestPaging_SkipsLoadedItems(t *testing.T) {
+	loader := newFakeLoader(150)
+	m := newPagedModel(loader, 100)
+	app := m.app
+
+	// An item bumped meanwhile comes back in the next page
+	loader.extra = []*StackItem{{ID: 150, Preview: "item 150"}}
+	app.Paging.loading = true
+	finishLoad(t, app)
+
+	if len(app.Items) != 150 {
+		t.Fatalf("loaded %d items, want 150", len(app.Items))
+	}
+	seen := map[uint]bool{}
+	for _, item := range app.Items {
+		if seen[item.ID] {
+			t.Fatalf("item %d loaded twice", item.ID)
+		}
+		seen[item.ID] = true
+	}
+}
+
+func TestPaging_GoToBottomLoadsEveryPage(t *testing.T) {
+	loader := newFakeLoader(350)
+	m := newPagedModel(loader, 100)
+	app := m.app
+
+	press(app, "G")
+	for app.Paging.loading {
+		finishLoad(t, app)
+	}
+	if len(app.Items) != 350 || app.LeftPane.Cursor != 349 {
+		t.Errorf("G loaded %d items with the cursor at %d, want 350 and 349", len(app.Items), app.LeftPane.Cursor)
+	}
+	if len(loader.calls) != 3 {
+		t.Errorf("G made %d loads, want 3", len(loader.calls))
+	}
+}
+
+func TestPaging_ShowsPlaceholderAndFooter(t *testing.T) {
+	loader := newFakeLoader(9431)
+	m := newPagedModel(loader, 200)
+	app := m.app
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 24})
+
+	view, err := renderNormalView(*app)
+	if err != nil {
+		t.Fatalf("renderNormalView() error = %v", err)
+	}
+	if !strings.Contains(view, "200 of 9,431 loaded") || strings.Contains(view, "Loading more…") {
+		t.Errorf("view doesn't show just the footer:\n%s", view)
+	}
+
+	press(app, "G")
+	view, err = renderNormalView(*app)
+	if err != nil {
+		t.Fatalf("renderNormalView() error = %v", err)
+	}
+	if !strings.Contains(view, "Loading more…") {
+		t.Errorf("view doesn't show the placeholder while loading:\n%s", view)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 9431: "9,431", 1234567: "1,234,567", -1200: "-1,200"} {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingTimeout is how long a key waits for the key that completes its
+// sequence, like the second g of gg
+const pendingTimeout = 500 * time.Millisecond
+
+// PendingKey is a normal-mode key waiting for a repeat that completes a
+// vim-style sequence: gg goes to the top, and dd deletes like d
+type PendingKey struct {
+	Key  string   // the key typed; "" when nothing is pending
+	Pane PaneType // the pane it was typed in
+	// Done is set when the key has already acted, as d does and g with a
+	// count does; its repeat is then swallowed rather than acting again
+	Done bool
+	seq  int
+}
+
+// pendingExpiredMsg is sent pendingTimeout after a key started waiting;
+// seq tells whether it is still the pending key
+type pendingExpiredMsg struct {
+	seq int
+}
+
+func (pendingExpiredMsg) isAppMsg() {}
+
+// startPending makes key wait for its repeat until pendingTimeout
+func (a *AppModel) startPending(key string, done bool) tea.Cmd {
+	seq := a.Pending.seq + 1
+	a.Pending = PendingKey{Key: key, Pane: a.ActivePane, Done: done, seq: seq}
+	return tea.Tick(pendingTimeout, func(time.Time) tea.Msg { return pendingExpiredMsg{seq: seq} })
+}
+
+// handlePendingKey completes or cancels the pending key with the next key.
+// Esc and the repeat are consumed; any other key cancels the sequence and is
+// handled as usual, so handled is false.
+func (a *AppModel) handlePendingKey(key string) (handled bool, cmd tea.Cmd) {
+	pending := a.Pending
+	a.clearPending()
+	switch key {
+	case pending.Key:
+		if !pending.Done {
+			_, cmd = a.runPending(pending)
+		}
+		return true, cmd
+	case "esc":
+		return true, nil
+	}
+	return false, nil
+}
+
+// handlePendingExpired runs a pending key that no repeat arrived for
+func (a *AppModel) handlePendingExpired(msg pendingExpiredMsg) tea.Cmd {
+	pending := a.Pending
+	if pending.Key == "" || pending.seq != msg.seq {
+		return nil
+	}
+	a.clearPending()
+	if pending.Done || a.CurrentMode != NormalMode {
+		return nil
+	}
+	_, cmd := a.runPending(pending)
+	return cmd
+}
+
+// clearPending ends the pending sequence; its timeout then does nothing
+func (a *AppModel) clearPending() {
+	a.Pending = PendingKey{seq: a.Pending.seq}
+}
+
+// runPending acts on a pending key that hasn't acted yet; only a bare g
+// waits before acting
+func (a *AppModel) runPending(pending PendingKey) (tea.Model, tea.Cmd) {
+	if pending.Key != "g" {
+		return a, nil
+	}
+	return a.executeCommand(1, "g", pending.Pane)
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newPendingTestApp(n int) *AppModel {
+	var items []*StackItem
+	for i := 0; i < n; i++ {
+		items = append(items, &StackItem{Content: NewStringReadSeekCloser(fmt.Sprintf("item %d", i)), Preview: fmt.Sprintf("item %d", i)})
+	}
+	app := NewAppModel(items, newTestClipboard())
+	return &app
+}
+
+func TestPendingKey_GG(t *testing.T) {
+	app := newPendingTestApp(10)
+	app.LeftPane.Update(JumpToIndexMsg{Index: 7, MaxIndex: 9})
+
+	press(app, "g")
+	if app.LeftPane.Cursor != 7 || app.Pending.Key != "g" {
+		t.Fatalf("a lone g moved to %d with pending %q, want it to wait at 7", app.LeftPane.Cursor, app.Pending.Key)
+	}
+	press(app, "g")
+	if app.LeftPane.Cursor != 0 || app.Pending.Key != "" {
+		t.Errorf("gg left the cursor at %d with pending %q, want 0 and none", app.LeftPane.Cursor, app.Pending.Key)
+	}
+
+	// Without a second g, the first acts once it times out
+	app.LeftPane.Update(JumpToIndexMsg{Index: 7, MaxIndex: 9})
+	press(app, "g")
+	stale := pendingExpiredMsg{seq: app.Pending.seq - 1}
+	app.Update(stale)
+	if app.LeftPane.Cursor != 7 {
+		t.Errorf("an earlier timeout moved the cursor to %d", app.LeftPane.Cursor)
+	}
+	app.Update(pendingExpiredMsg{seq: app.Pending.seq})
+	if app.LeftPane.Cursor != 0 {
+		t.Errorf("a timed-out g left the cursor at %d, want 0", app.LeftPane.Cursor)
+	}
+}
+
+func TestPendingKey_CountedG(t *testing.T) {
+	app := newPendingTestApp(10)
+
+	press(app, "5")
+	press(app, "G")
+	if app.LeftPane.Cursor != 4 {
+		t.Errorf("5G moved the cursor to %d, want 4", app.LeftPane.Cursor)
+	}
+	press(app, "G")
+	if app.LeftPane.Cursor != 9 {
+		t.Errorf("G moved the cursor to %d, want 9", app.LeftPane.Cursor)
+	}
+
+	// 3gg acts on the first g and swallows the second
+	press(app, "3")
+	press(app, "g")
+	if app.LeftPane.Cursor != 2 {
+		t.Errorf("3g moved the cursor to %d, want 2", app.LeftPane.Cursor)
+	}
+	press(app, "g")
+	app.Update(pendingExpiredMsg{seq: app.Pending.seq})
+	if app.LeftPane.Cursor != 2 {
+		t.Errorf("3gg moved the cursor to %d, want 2", app.LeftPane.Cursor)
+	}
+
+	// In the right pane a count is a line
+	app.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	app.Items[2] = &StackItem{Content: NewStringReadSeekCloser("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\no\np\nq\nr\ns\nt\nu\nv\nw\nx\ny\nz\n1\n2\n3\n4\n5\n6\n7\n8\n9\n0\n")}
+	app.RightPane.Update(UpdateContentMsg{})
+	AppView(*app)
+	press(app, "l")
+	press(app, "4")
+	press(app, "G")
+	if app.RightPane.ViewPos != 3 {
+		t.Errorf("4G scrolled to line %d, want 3", app.RightPane.ViewPos)
+	}
+}
+
+func TestPendingKey_DD(t *testing.T) {
+	app := newPendingTestApp(3)
+
+	press(app, "d")
+	press(app, "d")
+	if app.CurrentMode != DeleteMode || !app.Modal.Active {
+		t.Fatalf("dd left mode %v with modal %v, want the delete confirmation", app.CurrentMode, app.Modal.Active)
+	}
+	press(app, "y")
+	if len(app.Items) != 2 {
+		t.Errorf("confirming dd left %d items, want 2", len(app.Items))
+	}
+
+	// With delete bound to y, the second y of yy must not confirm
+	keys, err := NewKeymap(map[string]string{"key_delete": "y"})
+	if err != nil {
+		t.Fatalf("NewKeymap() error = %v", err)
+	}
+	app.Keys = keys
+	press(app, "y")
+	press(app, "y")
+	if len(app.Items) != 2 || !app.Modal.Active {
+		t.Errorf("yy deleted an item (%d left) or closed the modal (%v)", len(app.Items), app.Modal.Active)
+	}
+	press(app, "y")
+	if len(app.Items) != 1 {
+		t.Errorf("a third y left %d items, want 1", len(app.Items))
+	}
+}
+
+func TestPendingKey_Interrupted(t *testing.T) {
+	app := newPendingTestApp(10)
+	app.LeftPane.Update(JumpToIndexMsg{Index: 5, MaxIndex: 9})
+
+	// Another key cancels the g and is handled as usual
+	press(app, "g")
+	press(app, "j")
+	if app.LeftPane.Cursor != 6 || app.Pending.Key != "" {
+		t.Errorf("gj left the cursor at %d with pending %q, want 6 and none", app.LeftPane.Cursor, app.Pending.Key)
+	}
+	app.Update(pendingExpiredMsg{seq: app.Pending.seq})
+	if app.LeftPane.Cursor != 6 {
+		t.Errorf("the cancelled g's timeout moved the cursor to %d", app.LeftPane.Cursor)
+	}
+
+	// Esc cancels the g instead of quitting
+	press(app, "g")
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || app.Pending.Key != "" || app.LeftPane.Cursor != 6 {
+		t.Errorf("g Esc returned a command or moved the cursor to %d", app.LeftPane.Cursor)
+	}
+}
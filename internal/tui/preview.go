@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultPreviewDebounce is how long the cursor must rest on an item before
+// its content is loaded into the right pane, when preview_debounce_ms is not
+// configured
+const DefaultPreviewDebounce = 80 * time.Millisecond
+
+// previewFrame is how long a deferred load may take before the right pane
+// shows a "Loading…" placeholder rather than leaving the pane blank
+const previewFrame = 16 * time.Millisecond
+
+// PreviewDebounce defers loading and wrapping the selected item's content
+// while j and k move the cursor, so holding a key doesn't wrap every item
+// passed over. The zero value loads content immediately.
+type PreviewDebounce struct {
+	Delay time.Duration // how long the cursor must rest; 0 disables deferral
+
+	pending bool // the selected item's content has not been loaded yet
+	id      uint // ID of the item pending
+	slow    bool // pending for longer than a frame
+}
+
+// loadContentMsg is sent Delay after a cursor move; the content is loaded
+// only if itemID is still the selected item
+type loadContentMsg struct {
+	itemID uint
+}
+
+func (loadContentMsg) isAppMsg() {}
+
+// previewFrameMsg is sent a frame after a cursor move, to show the
+// placeholder if itemID is still pending
+type previewFrameMsg struct {
+	itemID uint
+}
+
+func (previewFrameMsg) isAppMsg() {}
+
+// deferring reports whether item's content is waiting for the cursor to settle
+func (p PreviewDebounce) deferring(item *StackItem) bool {
+	return p.pending && item != nil && item.ID == p.id
+}
+
+// deferContent resets the right pane for a newly selected item and, unless
+// deferral is disabled, postpones loading its content until the cursor has
+// rested on it for Delay
+func (a *AppModel) deferContent() tea.Cmd {
+	a.RightPane.Update(UpdateContentMsg{})
+	item := a.selectedItem()
+	if a.Preview.Delay <= 0 || item == nil {
+		a.loadContent()
+		return nil
+	}
+
+	a.Preview.pending = true
+	a.Preview.id = item.ID
+	a.Preview.slow = false
+
+	id := item.ID
+	return tea.Batch(
+		tea.Tick(previewFrame, func(time.Time) tea.Msg { return previewFrameMsg{itemID: id} }),
+		tea.Tick(a.Preview.Delay, func(time.Time) tea.Msg { return loadContentMsg{itemID: id} }),
+	)
+}
+
+// handleLoadContent loads the pending item once the cursor has settled on it
+func (a *AppModel) handleLoadContent(msg loadContentMsg) {
+	if a.Preview.deferring(a.selectedItem()) && a.Preview.id == msg.itemID {
+		a.loadContent()
+	}
+}
+
+// handlePreviewFrame shows the placeholder if the item is still pending
+func (a *AppModel) handlePreviewFrame(msg previewFrameMsg) {
+	if a.Preview.deferring(a.selectedItem()) && a.Preview.id == msg.itemID {
+		a.Preview.slow = true
+	}
+}
+
+// loadContent loads the selected item's content now, ending any deferral
+func (a *AppModel) loadContent() {
+	a.Preview.pending = false
+	a.Preview.slow = false
+	a.syncSearchToSelection()
+}
+
+// flushContent loads deferred content before a key that needs it
+func (a *AppModel) flushContent() {
+	if a.Preview.pending {
+		a.loadContent()
+	}
+}
+
+// isCursorKey reports whether key moves the cursor or is part of a count
+// for a movement, so deferred content can wait through it
+func isCursorKey(key string) bool {
+	switch key {
+	case "j", "k", "up", "down":
+		return true
+	}
+	return len(key) == 1 && key[0] >= '0' && key[0] <= '9'
+}
+
+// selectedItem returns the item selected in the left pane, or nil
+func (a *AppModel) selectedItem() *StackItem {
+	if a.LeftPane.Selected >= len(a.Items) {
+		return nil
+	}
+	return a.Items[a.LeftPane.Selected]
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAppModel_PreviewDebounce(t *testing.T) {
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for id := uint(1); id <= 6; id++ {
+		item := ops.item(id, fmt.Sprintf("content of item %d\n", id))
+		item.Preview = fmt.Sprintf("item %d", id)
+		items = append(items, item)
+	}
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(ops)
+	model.SetPreviewDebounce(80 * time.Millisecond)
+	model.UpdateMockSize(120, 30)
+	model.Init()
+	model.View()
+
+	send := func(msgs ...tea.Msg) string {
+		var view string
+		for _, msg := range msgs {
+			updated, _ := model.Update(msg)
+			model = updated.(Model)
+			view = model.View()
+		}
+		return view
+	}
+	j := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+
+	// A burst of j presses wraps none of the items passed over
+	view := send(j, j, j, j)
+	if selected := model.app.Items[model.app.LeftPane.Selected].ID; selected != 5 {
+		t.Fatalf("expected item 5 selected, got %d", selected)
+	}
+	if strings.Contains(view, "content of item 5") || strings.Contains(view, "Loading…") {
+		t.Errorf("expected a blank right pane within the first frame, got:\n%s", view)
+	}
+
+	// Loads scheduled for items the cursor has left are ignored
+	send(loadContentMsg{itemID: 2}, loadContentMsg{itemID: 3})
+	if view := send(previewFrameMsg{itemID: 5}); !strings.Contains(view, "Loading…") {
+		t.Errorf("expected the placeholder once a frame has passed, got:\n%s", view)
+	}
+
+	view = send(loadContentMsg{itemID: 5})
+	if !strings.Contains(view, "content of item 5") {
+		t.Errorf("expected item 5's content once the cursor settled, got:\n%s", view)
+	}
+	for _, item := range model.app.Items {
+		wrapped := item.CachedWidth != 0
+		if want := item.ID == 1 || item.ID == 5; wrapped != want {
+			t.Errorf("item %d: wrapped = %v, want %v", item.ID, wrapped, want)
+		}
+	}
+
+	// Any other key loads deferred content at once
+	send(j)
+	if view := send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")}); !strings.Contains(view, "content of item 6") {
+		t.Errorf("expected focusing the right pane to load item 6, got:\n%s", view)
+	}
+
+	// With the debounce disabled, moving loads content immediately
+	model.SetPreviewDebounce(0)
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if view := send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")}); !strings.Contains(view, "content of item 5") {
+		t.Errorf("expected item 5's content without a debounce, got:\n%s", view)
+	}
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultRecentCopiesLimit is how many recently copied items are marked
+// when recent_copies_limit is not configured
+const DefaultRecentCopiesLimit = 5
+
+// recentCopyMark follows the preview of a recently copied item in the list
+const recentCopyMark = " ↑"
+
+// RecentCopies remembers the items copied most recently, newest first, so
+// the left pane can mark them and ' can jump between them. A Limit of 0
+// turns it off.
+type RecentCopies struct {
+	IDs   []uint
+	Limit int
+
+	copied []uint // copied this session, newest first
+	next   int    // position in IDs the next ' starts looking from
+}
+
+// addRecentCopy moves id to the front of ids, keeping at most limit of them
+func addRecentCopy(ids []uint, id uint, limit int) []uint {
+	kept := []uint{id}
+	for _, other := range ids {
+		if other != id && len(kept) < limit {
+			kept = append(kept, other)
+		}
+	}
+	return kept
+}
+
+// record notes that the item with id was just copied
+func (r *RecentCopies) record(id uint) {
+	if r.Limit <= 0 || id == 0 {
+		return
+	}
+	r.IDs = addRecentCopy(r.IDs, id, r.Limit)
+	r.copied = addRecentCopy(r.copied, id, r.Limit)
+	r.next = 0
+}
+
+// marked returns the IDs the left pane marks, or nil when none are
+func (r *RecentCopies) marked() map[uint]bool {
+	if r.Limit <= 0 || len(r.IDs) == 0 {
+		return nil
+	}
+	marked := make(map[uint]bool, len(r.IDs))
+	for _, id := range r.IDs {
+		marked[id] = true
+	}
+	return marked
+}
+
+// jumpRecentCopy selects the next recently copied item, newest first,
+// passing over the selected item and any not loaded, and wrapping around
+func (a *AppModel) jumpRecentCopy() tea.Cmd {
+	r := &a.RecentCopies
+	current, ok := a.currentEntry()
+	for i := range r.IDs {
+		pos := (r.next + i) % len(r.IDs)
+		index := a.indexOfID(r.IDs[pos])
+		if index < 0 || (ok && r.IDs[pos] == current.ID) {
+			continue
+		}
+		r.next = (pos + 1) % len(r.IDs)
+		a.selectIndex(index)
+		a.recordJump(current, ok)
+		return a.setFlashMessage(fmt.Sprintf("Recently copied %d/%d", pos+1, len(r.IDs)), 2*time.Second)
+	}
+	return a.setFlashMessage("No other recently copied items", 2*time.Second)
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newRecentCopiesTestApp(n int) *AppModel {
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for id := uint(1); id <= uint(n); id++ {
+		items = append(items, ops.item(id, fmt.Sprintf("snippet %d", id)))
+	}
+	model := NewModel(items, newTestClipboard())
+	model.SetItemOps(ops)
+	model.SetRecentCopies([]uint{4, 2, 9}, 3)
+	return model.app
+}
+
+func TestRecentCopies_Marks(t *testing.T) {
+	app := newRecentCopiesTestApp(5)
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	view, _ := AppView(*app)
+	if strings.Count(view, "↑") != 2 {
+		t.Fatalf("expected items 2 and 4 marked:\n%s", view)
+	}
+	for _, line := range strings.Split(view, "\n") {
+		marked := strings.Contains(line, "↑")
+		want := strings.Contains(line, "snippet 2") || strings.Contains(line, "snippet 4")
+		if strings.Contains(line, "snippet") && marked != want {
+			t.Errorf("marked = %v, want %v: %q", marked, want, line)
+		}
+	}
+
+	app.RecentCopies.Limit = 0
+	if view, _ := AppView(*app); strings.Contains(view, "↑") {
+		t.Errorf("expected no marks with the limit at 0:\n%s", view)
+	}
+}
+
+func TestRecentCopies_JumpCycle(t *testing.T) {
+	app := newRecentCopiesTestApp(5)
+
+	// Newest first, passing over the unloaded 9, then around again
+	var visited []uint
+	for range 4 {
+		press(app, "'")
+		visited = append(visited, app.selectedItem().ID)
+	}
+	if fmt.Sprint(visited) != "[4 2 4 2]" {
+		t.Errorf("' visited %v, want [4 2 4 2]", visited)
+	}
+
+	// A copy goes to the front and restarts the cycle
+	app.selectIndex(0)
+	press(app, "c")
+	if fmt.Sprint(app.RecentCopies.IDs) != "[1 4 2]" || fmt.Sprint(app.RecentCopies.copied) != "[1]" {
+		t.Fatalf("after copying item 1, recent = %v copied = %v", app.RecentCopies.IDs, app.RecentCopies.copied)
+	}
+	press(app, "'")
+	if id := app.selectedItem().ID; id != 4 {
+		t.Errorf("' after a copy selected %d, want 4 (the copied item is already selected)", id)
+	}
+
+	// ctrl+o comes back from a quick jump
+	app.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if id := app.selectedItem().ID; id != 1 {
+		t.Errorf("ctrl+o selected %d, want 1", id)
+	}
+}
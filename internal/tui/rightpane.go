@@ -5,8 +5,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/yiblet/rem/internal/timefmt"
 )
 
 // RightPaneMsg represents messages that the right pane component handles
@@ -66,20 +68,90 @@ type UpdateContentMsg struct {
 
 func (UpdateContentMsg) isRightPaneMsg() {}
 
+type ToggleWrapMsg struct{}
+
+func (ToggleWrapMsg) isRightPaneMsg() {}
+
+type ScrollHorizontalMsg struct {
+	Cells     int // columns to scroll; negative scrolls left
+	MaxOffset int
+}
+
+func (ScrollHorizontalMsg) isRightPaneMsg() {}
+
+// DefaultHScrollStep is the number of columns H and L scroll in nowrap mode
+const DefaultHScrollStep = 8
+
+// noWrapWidth is the line index width used when lines are not wrapped. Each
+// source line is one display line, except that lines longer than
+// wrapSegmentBytes are split at segment boundaries.
+const noWrapWidth = -1
+
 // RightPaneModel holds the state for the right pane (content viewer)
 type RightPaneModel struct {
-	Width   int // Pane width
-	Height  int // Pane height
-	ViewPos int // Current view position (line number)
+	Width       int  // Pane width
+	Height      int  // Pane height
+	ViewPos     int  // Current view position (line number)
+	NoWrap      bool // true to truncate source lines instead of wrapping them
+	HOffset     int  // first visible column in nowrap mode
+	HScrollStep int  // columns scrolled per horizontal scroll
+	TabWidth    int  // columns between tab stops
+	Latin1      bool // true to show bytes that aren't UTF-8 as Latin-1
+	Scrollbar   bool // true to show a scrollbar in the rightmost column
+	DiffColors  bool // true to color items that look like unified diffs
+
+	// Location is the zone edit dates are shown in; nil is the local zone
+	Location *time.Location
+
+	// Deferred leaves the content unloaded while the cursor is still moving;
+	// Placeholder, if set, is shown in its place
+	Deferred    bool
+	Placeholder string
+
+	// Hints are the key hints shown in the bottom row while the pane is
+	// focused; nil leaves the row to the content
+	Hints []string
 }
 
 // NewRightPaneModel creates a new right pane model with default values
 func NewRightPaneModel(width, height int) RightPaneModel {
 	return RightPaneModel{
-		Width:   width,
-		Height:  height,
-		ViewPos: 0,
+		Width:       width,
+		Height:      height,
+		ViewPos:     0,
+		HScrollStep: DefaultHScrollStep,
+		TabWidth:    DefaultTabWidth,
+		Scrollbar:   true,
+		DiffColors:  true,
+	}
+}
+
+// contentHeight returns the number of rows available for content lines,
+// below the title and its details line and above any hint row
+func (r RightPaneModel) contentHeight() int {
+	height := r.Height - 6
+	if r.Hints != nil {
+		height--
+	}
+	return height
+}
+
+// textWidth returns the number of columns available for content. The
+// scrollbar takes one column whether or not the content scrolls, so wrapping
+// doesn't depend on the content's length.
+func (r RightPaneModel) textWidth() int {
+	if r.Scrollbar {
+		return r.Width - 7
+	}
+	return r.Width - 6
+}
+
+// wrapWidth returns the width content is wrapped to, or noWrapWidth
+func (r RightPaneModel) wrapWidth() int {
+	if r.NoWrap {
+		return noWrapWidth
 	}
+	return r.textWidth()
 }
 
 // RightPaneModel implements the Model interface for the right pane
@@ -99,11 +171,11 @@ func (r *RightPaneModel) Update(msg RightPaneMsg) error {
 		r.ViewPos = m.MaxScroll
 	case PageUpMsg:
 		// Page up (half page)
-		pageSize := (r.Height - 6) / 2
+		pageSize := r.contentHeight() / 2
 		r.ViewPos = max(r.ViewPos-pageSize, 0)
 	case PageDownMsg:
 		// Page down (half page)
-		pageSize := (r.Height - 6) / 2
+		pageSize := r.contentHeight() / 2
 		r.ViewPos = min(r.ViewPos+pageSize, m.MaxScroll)
 	case JumpMsg:
 		switch m.Direction {
@@ -117,27 +189,40 @@ func (r *RightPaneModel) Update(msg RightPaneMsg) error {
 		r.Height = m.Height
 	case UpdateContentMsg:
 		r.ViewPos = 0 // Reset view position when content changes
+		r.HOffset = 0
+	case ToggleWrapMsg:
+		r.NoWrap = !r.NoWrap
+		r.HOffset = 0
+	case ScrollHorizontalMsg:
+		r.HOffset = min(max(r.HOffset+m.Cells, 0), max(m.MaxOffset, 0))
 	}
 	return nil
 }
 
-// RightPaneView renders the right pane as a pure function
-func RightPaneView(model RightPaneModel, content *StackItem, searchModel SearchModel, focused bool, selectedIndex int) (string, error) {
-	borderColor := "62"
-	if focused {
-		borderColor = "205" // Highlight focused pane
-		if searchModel.IsActive() {
-			// Show the border in yellow when in search mode
-			borderColor = "220"
-		}
+// editDates returns "created 3d ago · modified 2h ago" for an item changed
+// more than a minute after it was stored, or "" otherwise
+func editDates(item *StackItem, now time.Time) string {
+	if item.CreatedAt.IsZero() || item.UpdatedAt.Sub(item.CreatedAt) <= time.Minute {
+		return ""
 	}
+	return fmt.Sprintf("created %s%smodified %s", timefmt.Age(item.CreatedAt, now), statusSeparator, timefmt.Age(item.UpdatedAt, now))
+}
 
-	style := lipgloss.NewStyle().
-		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color(borderColor)).
-		Padding(0, 1).
-		Width(model.Width - 2).
-		Height(model.Height - 4)
+// encodingNotice says how content that isn't valid UTF-8 is shown, or
+// returns "" for UTF-8 content
+func encodingNotice(item *StackItem, latin1 bool) string {
+	switch {
+	case !item.nonUTF8:
+		return ""
+	case latin1:
+		return "(non-UTF-8 content, shown as Latin-1)"
+	}
+	return "(non-UTF-8 content, displayed lossily)"
+}
+
+// RightPaneView renders the right pane as a pure function
+func RightPaneView(model RightPaneModel, content *StackItem, searchModel SearchModel, focused bool, selectedIndex int) (string, error) {
+	style := model.frameStyle(focused, searchModel.IsActive())
 
 	var contentBuilder strings.Builder
 
@@ -154,33 +239,65 @@ func RightPaneView(model RightPaneModel, content *StackItem, searchModel SearchM
 		// Add item title if available (truncate to fit available width)
 		if content.Preview != "" {
 			maxTitleWidth := model.Width - 20 // Account for borders, padding, and Content [N] text
-			itemTitle := content.Preview
-			if len(itemTitle) > maxTitleWidth {
-				itemTitle = itemTitle[:maxTitleWidth-3] + "..."
+			if itemTitle := ellipsize(content.Preview, maxTitleWidth); itemTitle != "" {
+				title += ": " + itemTitle
 			}
-			title += ": " + itemTitle
 		}
 
 		// Calculate available height once
-		availableHeight := model.Height - 6 // Account for borders and headers
+		availableHeight := model.contentHeight()
+
+		if model.Deferred {
+			contentBuilder.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+			if model.Placeholder != "" {
+				placeholder := lipgloss.NewStyle().Faint(true).Render(model.Placeholder)
+				contentBuilder.WriteString(lipgloss.Place(max(model.Width-6, 1), max(availableHeight, 1), lipgloss.Center, lipgloss.Center, placeholder))
+			}
+			return style.Render(model.withHints(contentBuilder.String(), focused)), nil
+		}
 
 		// Ensure lines are wrapped for current width
 		// UpdateWrappedLines is smart - it only recalculates if width changed
-		content.UpdateWrappedLines(model.Width-6, availableHeight)
+		// or the view moved outside the loaded window
+		content.ViewPos = model.ViewPos
+		content.setTabWidth(model.TabWidth)
+		content.setLatin1(model.Latin1)
+		content.UpdateWrappedLines(model.wrapWidth(), availableHeight)
 
 		maxScroll := getMaxScroll(model, content)
-		if len(content.Lines) > 0 && maxScroll > 0 {
+		if totalLines, known := content.knownLineCount(); !known && len(content.Lines) > 0 {
+			// Total line count isn't known yet (e.g. one huge line), show byte progress instead
+			title += fmt.Sprintf(" (%d%%)", content.byteProgress(model.ViewPos))
+		} else if len(content.Lines) > 0 && maxScroll > 0 {
 			// Add scroll position indicator
-			totalLines := len(content.Lines)
 			topLine := model.ViewPos + 1
 			bottomLine := min(model.ViewPos+availableHeight, totalLines)
 			title += fmt.Sprintf(" (%d-%d/%d)", topLine, bottomLine, totalLines)
 		}
-		contentBuilder.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+		contentBuilder.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n")
+
+		// The note, edit dates, and encoding notice, if any, take the blank
+		// line under the title
+		var details []string
+		for _, detail := range []string{content.Note, editDates(content, timefmt.Now(model.Location)), encodingNotice(content, model.Latin1)} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		if note, ellipsis := truncateToWidth(strings.Join(details, statusSeparator), max(model.Width-6, 0)); note+ellipsis != "" {
+			contentBuilder.WriteString(lipgloss.NewStyle().Faint(true).Render(note + ellipsis))
+		}
+		contentBuilder.WriteString("\n")
+
+		if content.isEmpty() {
+			placeholder := lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("(empty item, %d bytes)", content.contentSize()))
+			contentBuilder.WriteString(lipgloss.Place(max(model.Width-6, 1), max(availableHeight, 1), lipgloss.Center, lipgloss.Center, placeholder))
+			return style.Render(model.withHints(contentBuilder.String(), focused)), nil
+		}
 
 		// Show the visible portion based on view position
 		startLine := model.ViewPos
-		endLine := min(startLine+availableHeight, len(content.Lines))
+		endLine := startLine + availableHeight
 
 		// Create a set of match lines for quick lookup
 		matchLines := make(map[int]bool)
@@ -188,44 +305,140 @@ func RightPaneView(model RightPaneModel, content *StackItem, searchModel SearchM
 			matchLines[matchLine] = true
 		}
 
+		// The scrollbar is drawn only when there is something to scroll
+		var bar []string
+		if top, visible, total, ok := scrollPosition(model, content, availableHeight); ok && model.Scrollbar {
+			bar = renderScrollbar(availableHeight, top, visible, total)
+		}
+
 		for i := startLine; i < endLine; i++ {
-			if i < len(content.Lines) {
-				line := content.Lines[i]
+			line, ok := content.lineAt(i)
+			if !ok && bar == nil {
+				continue
+			}
+
+			// In nowrap mode only the columns from HOffset onward are shown
+			lo, hi := 0, len(line)
+			if model.NoWrap {
+				lo, hi = cellRange(line, model.HOffset, model.textWidth())
+			}
 
-				// Highlight search matches
-				if matchLines[i] && searchModel.GetPattern() != "" {
-					line = highlightSearchMatches(line, searchModel.GetPattern(), i == searchModel.GetCurrentMatchLine())
+			// Color diff lines and dim control pictures, leaving the widths
+			// measured above unchanged
+			var plain func(string) string
+			if kind, ok := content.diffKindAt(i); ok && model.DiffColors && kind != diffContext {
+				style := diffStyles[kind]
+				plain = func(s string) string { return style.Render(s) }
+			}
+			if strings.ContainsFunc(line, isControlPicture) {
+				render := plain
+				if render == nil {
+					render = func(s string) string { return s }
 				}
+				plain = func(s string) string { return dimControls(s, render) }
+			}
 
-				contentBuilder.WriteString(line + "\n")
+			// Highlight search matches, or else underline links
+			if matchLines[i] && searchModel.GetPattern() != "" {
+				line = highlightSearchMatchesStyled(line, lo, hi, searchModel.GetPattern(), i == searchModel.GetCurrentMatchLine(), plain)
+			} else if links := content.lineLinks(i); links != nil && lo < hi {
+				line = renderLinks(line, lo, hi, links, plain)
+			} else if plain != nil && lo < hi {
+				line = plain(line[lo:hi])
+			} else {
+				line = line[lo:hi]
 			}
+
+			if bar != nil {
+				// Pad every row so the scrollbar sits in the last column
+				line += strings.Repeat(" ", max(model.Width-5-lipgloss.Width(line), 0)) + bar[i-startLine]
+			}
+			contentBuilder.WriteString(line + "\n")
 		}
 	}
 
 	contentStr := strings.TrimSuffix(contentBuilder.String(), "\n")
-	return style.Render(contentStr), nil
+	return style.Render(model.withHints(contentStr, focused)), nil
+}
+
+// frameStyle returns the style of the pane's border and padding
+func (r RightPaneModel) frameStyle(focused, searching bool) lipgloss.Style {
+	borderColor := "62"
+	if focused {
+		borderColor = "205" // Highlight focused pane
+		if searching {
+			// Show the border in yellow when in search mode
+			borderColor = "220"
+		}
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color(borderColor)).
+		Padding(0, 1).
+		Width(r.Width - 2).
+		Height(r.Height - 4)
+}
+
+// withHints puts the hint row under body, blank unless the pane is focused,
+// if the pane has one
+func (r RightPaneModel) withHints(body string, focused bool) string {
+	if r.Hints == nil {
+		return body
+	}
+	hint := ""
+	if focused {
+		hint = fitHints(r.Hints, r.Width-6)
+	}
+	return withHintRow(body, r.Height-4, hint)
 }
 
 // highlightSearchMatches highlights search matches in a line (pure function)
 func highlightSearchMatches(line, pattern string, isCurrentMatch bool) string {
+	return highlightSearchMatchesIn(line, 0, len(line), pattern, isCurrentMatch)
+}
+
+// highlightSearchMatchesIn returns line[lo:hi] with search matches
+// highlighted. Matches are found in the whole line, so a match cut off by the
+// window is still highlighted up to its edge. (pure function)
+func highlightSearchMatchesIn(line string, lo, hi int, pattern string, isCurrentMatch bool) string {
+	return highlightSearchMatchesStyled(line, lo, hi, pattern, isCurrentMatch, nil)
+}
+
+// highlightSearchMatchesStyled is highlightSearchMatchesIn with the text
+// between matches rendered by plain, if not nil, so line styles such as diff
+// colors survive the highlighting. (pure function)
+func highlightSearchMatchesStyled(line string, lo, hi int, pattern string, isCurrentMatch bool, plain func(string) string) string {
+	if plain == nil {
+		plain = func(s string) string { return s }
+	}
+
 	// Compile regex for highlighting
-	regex, err := regexp.Compile("(?i)" + pattern)
+	regex, err := compileSearch(pattern)
 	if err != nil {
-		return line // Return original line if regex fails
+		return plain(line[lo:hi]) // Return original line if regex fails
 	}
 
 	// Find all matches in the line
-	matches := regex.FindAllStringIndex(line, -1)
+	matches := findMatches(regex, line)
 	if len(matches) == 0 {
-		return line
+		return plain(line[lo:hi])
 	}
 
 	var highlightedLine strings.Builder
-	lastEnd := 0
+	lastEnd := lo
 
 	for _, match := range matches {
+		// Clip the match to the visible window
+		match = []int{max(match[0], lo), min(match[1], hi)}
+		if match[0] >= match[1] {
+			continue
+		}
+
 		// Add text before match
-		highlightedLine.WriteString(line[lastEnd:match[0]])
+		if lastEnd < match[0] {
+			highlightedLine.WriteString(plain(line[lastEnd:match[0]]))
+		}
 
 		// Add highlighted match
 		matchText := line[match[0]:match[1]]
@@ -247,25 +460,124 @@ func highlightSearchMatches(line, pattern string, isCurrentMatch bool) string {
 	}
 
 	// Add remaining text after last match
-	highlightedLine.WriteString(line[lastEnd:])
+	if lastEnd < hi {
+		highlightedLine.WriteString(plain(line[lastEnd:hi]))
+	}
 	return highlightedLine.String()
 }
 
+// cellRange returns the byte range of line covering display columns
+// [offset, offset+width). A wide rune straddling either edge is left out.
+// (pure function)
+func cellRange(line string, offset, width int) (int, int) {
+	lo, hi := len(line), len(line)
+	col := 0
+	for i, r := range line {
+		if col >= offset && lo == len(line) {
+			lo = i
+		}
+		w := lipgloss.Width(string(r))
+		if col+w > offset+width {
+			hi = i
+			break
+		}
+		col += w
+	}
+	return min(lo, hi), hi
+}
+
+// scrollPosition returns the visible part of content as top, visible and
+// total units for the scrollbar: display lines when the line count is known,
+// otherwise bytes. ok is false when everything fits in the pane.
+func scrollPosition(model RightPaneModel, content *StackItem, availableHeight int) (top, visible, total int64, ok bool) {
+	if lines, known := content.knownLineCount(); known {
+		if lines <= availableHeight {
+			return 0, 0, 0, false
+		}
+		return int64(model.ViewPos), int64(availableHeight), int64(lines), true
+	}
+
+	// Total lines unknown: measure by byte offset instead
+	start, ok := content.offsetAt(model.ViewPos)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	size := content.contentSize()
+	end := size
+	if offset, ok := content.offsetAt(model.ViewPos + availableHeight); ok {
+		end = offset
+	}
+	return start, max64(end-start, 1), size, size > 0
+}
+
+// scrollbarThumb returns the first row and row count of a scrollbar thumb in
+// a track of height rows, for a view showing [top, top+visible) of total
+// units (pure function)
+func scrollbarThumb(height int, top, visible, total int64) (int, int) {
+	if height <= 0 || visible >= total {
+		return 0, max(height, 0)
+	}
+	size := max(int(int64(height)*visible/total), 1)
+	maxTop := total - visible
+	if top < 0 {
+		top = 0
+	} else if top > maxTop {
+		top = maxTop
+	}
+	start := int((top*int64(height-size) + maxTop/2) / maxTop)
+	return start, size
+}
+
+// renderScrollbar renders a scrollbar track of height rows, one cell per row
+func renderScrollbar(height int, top, visible, total int64) []string {
+	start, size := scrollbarThumb(height, top, visible, total)
+	track := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("│")
+	thumb := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Render("┃")
+
+	rows := make([]string, height)
+	for i := range rows {
+		rows[i] = track
+		if i >= start && i < start+size {
+			rows[i] = thumb
+		}
+	}
+	return rows
+}
+
+// getMaxHOffset returns the largest useful horizontal offset: the one that
+// brings the end of the longest visible line to the right edge
+func getMaxHOffset(model RightPaneModel, content *StackItem) int {
+	if content == nil || !model.NoWrap {
+		return 0
+	}
+	availableHeight := max(model.contentHeight(), 1)
+	content.ViewPos = model.ViewPos
+	if err := content.UpdateWrappedLines(model.wrapWidth(), availableHeight); err != nil {
+		return 0
+	}
+
+	longest := 0
+	for i := model.ViewPos; i < model.ViewPos+availableHeight; i++ {
+		if line, ok := content.lineAt(i); ok {
+			longest = max(longest, lipgloss.Width(line))
+		}
+	}
+	return max(longest-model.textWidth(), 0)
+}
+
 // getMaxScroll returns the maximum scroll position (pure function)
 func getMaxScroll(model RightPaneModel, content *StackItem) int {
 	if content == nil {
 		return 0
 	}
-	availableHeight := model.Height - 6 // Account for borders and headers
-	if len(content.Lines) <= availableHeight {
-		return 0
-	}
-	return len(content.Lines) - availableHeight
+	availableHeight := max(model.contentHeight(), 1)
+	totalLines, _ := content.knownLineCount()
+	return max(totalLines-availableHeight, 0)
 }
 
 // scrollToMatch calculates the view position to center a match line (pure function)
 func scrollToMatch(model RightPaneModel, content *StackItem, matchLine int) int {
-	availableHeight := model.Height - 6
+	availableHeight := max(model.contentHeight(), 1)
 	newViewPos := max(0, matchLine-availableHeight/2)
 	maxScroll := getMaxScroll(model, content)
 	return min(newViewPos, maxScroll)
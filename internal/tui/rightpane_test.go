@@ -1,8 +1,14 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 func TestNewRightPaneModel(t *testing.T) {
@@ -38,6 +44,44 @@ func TestRightPaneView(t *testing.T) {
 	}
 }
 
+func TestRightPaneView_WideTitle(t *testing.T) {
+	model := NewRightPaneModel(40, 20)
+	item := &StackItem{Content: NewStringReadSeekCloser("body"), Preview: strings.Repeat("漢字", 20)}
+
+	view, err := RightPaneView(model, item, NewSearchModel(), false, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	titleLine := strings.Split(view, "\n")[1]
+	if !strings.Contains(titleLine, "漢…") || strings.ContainsRune(titleLine, utf8.RuneError) {
+		t.Errorf("Expected the title cut between whole characters with an ellipsis, got %q", titleLine)
+	}
+	if width := lipgloss.Width(titleLine); width > 40 {
+		t.Errorf("Expected the title line within 40 columns, got %d", width)
+	}
+}
+
+func TestEllipsize(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 4, "abc…"},
+		{"漢字漢字", 5, "漢字…"},
+		{"👍🏽👍🏽👍🏽", 5, "👍🏽👍🏽…"},
+		{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		got := ellipsize(tt.s, tt.width)
+		if got != tt.want || !utf8.ValidString(got) || lipgloss.Width(got) > tt.width {
+			t.Errorf("ellipsize(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
 func TestRightPaneModel_ScrollUp(t *testing.T) {
 	model := NewRightPaneModel(80, 20)
 
@@ -234,3 +278,294 @@ func TestRightPaneView_Focused(t *testing.T) {
 		t.Error("Expected focused view to contain '● Content [0]'")
 	}
 }
+
+// countingReader counts reads so tests can tell when content is re-scanned
+type countingReader struct {
+	*StringReadSeekCloser
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return r.StringReadSeekCloser.Read(p)
+}
+
+func TestRightPaneView_NarrowTitle(t *testing.T) {
+	// Widths that leave fewer than 3 columns for the title used to panic
+	for width := 0; width <= 24; width++ {
+		model := NewRightPaneModel(width, 10)
+		item := &StackItem{Content: NewStringReadSeekCloser("content"), Preview: "a long item title"}
+		if _, err := RightPaneView(model, item, NewSearchModel(), true, 0); err != nil {
+			t.Errorf("RightPaneView at width %d error: %v", width, err)
+		}
+	}
+}
+
+func TestRightPaneView_EmptyItem(t *testing.T) {
+	model := NewRightPaneModel(80, 20)
+	reader := &countingReader{StringReadSeekCloser: NewStringReadSeekCloser("")}
+	item := &StackItem{Content: reader, Preview: "[empty]"}
+
+	view, err := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(view, "(empty item, 0 bytes)") {
+		t.Errorf("Expected empty placeholder, got:\n%s", view)
+	}
+	if strings.Contains(view, "/0)") {
+		t.Errorf("Empty item should not show a scroll indicator, got:\n%s", view)
+	}
+	if got := getMaxScroll(model, item); got != 0 {
+		t.Errorf("getMaxScroll for empty item = %d, want 0", got)
+	}
+
+	// Zero-line content is cached like any other content
+	reads := reader.reads
+	if _, err := RightPaneView(model, item, NewSearchModel(), true, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reader.reads != reads {
+		t.Errorf("Empty content was re-read on every render (%d reads, then %d)", reads, reader.reads)
+	}
+}
+
+func TestGetMaxScroll_TinyPane(t *testing.T) {
+	item := &StackItem{Content: NewStringReadSeekCloser("one\ntwo\nthree")}
+	item.UpdateWrappedLines(40, 10)
+
+	// A pane shorter than its chrome must not produce a negative or inflated scroll
+	model := NewRightPaneModel(40, 3)
+	if got := getMaxScroll(model, item); got != 2 {
+		t.Errorf("getMaxScroll = %d, want 2", got)
+	}
+	empty := &StackItem{Content: NewStringReadSeekCloser("")}
+	empty.UpdateWrappedLines(40, 10)
+	if got := getMaxScroll(model, empty); got != 0 {
+		t.Errorf("getMaxScroll for empty item = %d, want 0", got)
+	}
+}
+
+func TestCellRange(t *testing.T) {
+	tests := []struct {
+		line          string
+		offset, width int
+		want          string
+	}{
+		{"abcdefghij", 0, 4, "abcd"},
+		{"abcdefghij", 3, 4, "defg"},
+		{"abcdefghij", 8, 4, "ij"},
+		{"abc", 5, 4, ""},
+		{"日本語abc", 2, 4, "本語"}, // wide runes take two columns
+		{"日本語abc", 1, 5, "本語"}, // a rune straddling the left edge is skipped
+		{"ab日本", 0, 3, "ab"},   // and so is one straddling the right edge
+	}
+	for _, tt := range tests {
+		lo, hi := cellRange(tt.line, tt.offset, tt.width)
+		if got := tt.line[lo:hi]; got != tt.want {
+			t.Errorf("cellRange(%q, %d, %d) = %q, want %q", tt.line, tt.offset, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSearchMatchesIn_Offset(t *testing.T) {
+	// Force colors so highlighting shows up in the output
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	line := "0123456789 needle 0123456789"
+	lo, hi := cellRange(line, 13, 10) // "edle 01234"
+
+	// Only the part of the match inside the window is highlighted
+	got := highlightSearchMatchesIn(line, lo, hi, "needle", false)
+	want := highlightSearchMatches("edle", "edle", false) + " 01234"
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got == "edle 01234" {
+		t.Fatal("Expected the match to be highlighted")
+	}
+
+	// A match entirely outside the window leaves the window untouched
+	lo, hi = cellRange(line, 20, 5)
+	if got := highlightSearchMatchesIn(line, lo, hi, "needle", false); got != line[lo:hi] {
+		t.Errorf("Expected no highlighting outside the match, got %q", got)
+	}
+}
+
+func TestRightPaneView_NoWrap(t *testing.T) {
+	model := NewRightPaneModel(30, 20) // 23 text columns beside the scrollbar
+	model.NoWrap = true
+	item := &StackItem{
+		Content: NewStringReadSeekCloser("short\n" + strings.Repeat("x", 30) + "END-OF-LONG-LINE\nmid"),
+		Preview: "wide",
+	}
+
+	view, _ := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if strings.Contains(view, "END") {
+		t.Error("Nowrap view should truncate long lines at the pane width")
+	}
+	if total, _ := item.knownLineCount(); total != 3 {
+		t.Errorf("Expected one display line per source line, got %d", total)
+	}
+
+	// Clamp at the longest visible line: 46 columns in a 23 column pane
+	maxOffset := getMaxHOffset(model, item)
+	if maxOffset != 23 {
+		t.Fatalf("Expected max offset 23, got %d", maxOffset)
+	}
+	model.Update(ScrollHorizontalMsg{Cells: 100, MaxOffset: maxOffset})
+	if model.HOffset != 23 {
+		t.Errorf("Expected offset clamped to 23, got %d", model.HOffset)
+	}
+	view, _ = RightPaneView(model, item, NewSearchModel(), true, 0)
+	if !strings.Contains(view, "END-OF-LONG-LINE") {
+		t.Error("Scrolled view should show the end of the longest line")
+	}
+	model.Update(ScrollHorizontalMsg{Cells: -100, MaxOffset: maxOffset})
+	if model.HOffset != 0 {
+		t.Errorf("Expected offset clamped to 0, got %d", model.HOffset)
+	}
+}
+
+func TestScrollbarThumb(t *testing.T) {
+	tests := []struct {
+		name                string
+		top, visible, total int64
+		wantStart, wantSize int
+	}{
+		{"top", 0, 10, 100, 0, 1},
+		{"middle", 45, 10, 100, 5, 1},
+		{"bottom", 90, 10, 100, 9, 1},
+		{"half visible", 0, 50, 100, 0, 5},
+		{"half visible at bottom", 50, 50, 100, 5, 5},
+		{"past the end clamps", 500, 10, 100, 9, 1},
+		{"everything visible", 0, 100, 100, 0, 10},
+	}
+	for _, tt := range tests {
+		start, size := scrollbarThumb(10, tt.top, tt.visible, tt.total)
+		if start != tt.wantStart || size != tt.wantSize {
+			t.Errorf("%s: scrollbarThumb() = (%d, %d), want (%d, %d)", tt.name, start, size, tt.wantStart, tt.wantSize)
+		}
+	}
+}
+
+func TestRightPaneView_Scrollbar(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	item := &StackItem{Content: NewStringReadSeekCloser(strings.Join(lines, "\n")), Preview: "long"}
+
+	// thumbRow renders the pane and returns the first content row holding the thumb
+	thumbRow := func(model RightPaneModel) int {
+		t.Helper()
+		view, _ := RightPaneView(model, item, NewSearchModel(), true, 0)
+		rows := strings.Split(view, "\n")
+		for i, row := range rows[3 : len(rows)-1] { // skip the border, title and blank line
+			if strings.Contains(row, "┃") {
+				return i
+			}
+		}
+		t.Fatal("Expected a scrollbar thumb")
+		return -1
+	}
+
+	model := NewRightPaneModel(40, 16) // 10 content rows
+	maxScroll := len(lines) - 10
+	if got := thumbRow(model); got != 0 {
+		t.Errorf("Expected the thumb at the top, got row %d", got)
+	}
+	model.ViewPos = maxScroll / 2
+	if got := thumbRow(model); got != 4 && got != 5 {
+		t.Errorf("Expected the thumb in the middle, got row %d", got)
+	}
+	model.ViewPos = maxScroll
+	if got := thumbRow(model); got != 9 {
+		t.Errorf("Expected the thumb at the bottom, got row %d", got)
+	}
+
+	// Hidden scrollbars give the column back to the text
+	model.Scrollbar = false
+	if view, _ := RightPaneView(model, item, NewSearchModel(), true, 0); strings.Contains(view, "┃") {
+		t.Error("Expected no scrollbar when it is hidden")
+	}
+	if model.textWidth() != NewRightPaneModel(40, 16).textWidth()+1 {
+		t.Error("Expected hiding the scrollbar to widen the text by one column")
+	}
+
+	// Short content has nothing to scroll
+	short := &StackItem{Content: NewStringReadSeekCloser("one\ntwo"), Preview: "short"}
+	if view, _ := RightPaneView(NewRightPaneModel(40, 16), short, NewSearchModel(), true, 0); strings.Contains(view, "┃") {
+		t.Error("Expected no scrollbar for content that fits")
+	}
+}
+
+func TestRightPaneView_ScrollbarByBytes(t *testing.T) {
+	// One huge line: the line count stays unknown, so the thumb follows bytes
+	item := &StackItem{Content: NewStringReadSeekCloser(strings.Repeat("x", 200*1024)), Preview: "huge"}
+	model := NewRightPaneModel(40, 16)
+	view, _ := RightPaneView(model, item, NewSearchModel(), true, 0)
+	if _, known := item.knownLineCount(); known {
+		t.Fatal("Expected the line count to be unknown")
+	}
+	rows := strings.Split(view, "\n")
+	if !strings.Contains(rows[3], "┃") {
+		t.Errorf("Expected the thumb on the first row at the start of the content, got %q", rows[3])
+	}
+}
+
+func TestRightPaneView_Note(t *testing.T) {
+	model := NewRightPaneModel(60, 12)
+	search := NewSearchModel()
+	newItem := func(note string) *StackItem {
+		return &StackItem{Content: NewStringReadSeekCloser("line one\nline two"), Preview: "title", Note: note}
+	}
+
+	plain, err := RightPaneView(model, newItem(""), search, false, 0)
+	if err != nil {
+		t.Fatalf("RightPaneView() error = %v", err)
+	}
+	view, err := RightPaneView(model, newItem("deploy key for staging"), search, false, 0)
+	if err != nil {
+		t.Fatalf("RightPaneView() error = %v", err)
+	}
+
+	// The note takes the blank line under the title, so the height is unchanged
+	lines := strings.Split(view, "\n")
+	if len(lines) != len(strings.Split(plain, "\n")) {
+		t.Errorf("expected a note not to change the pane height")
+	}
+	if !strings.Contains(lines[2], "deploy key for staging") {
+		t.Errorf("expected the note under the title, got %q", lines[2])
+	}
+	if !strings.Contains(lines[3], "line one") {
+		t.Errorf("expected content right after the note, got %q", lines[3])
+	}
+
+	// Long notes are truncated to the pane width
+	long, _ := RightPaneView(model, newItem(strings.Repeat("note ", 40)), search, false, 0)
+	for i, line := range strings.Split(long, "\n") {
+		if w := lipgloss.Width(line); w != model.Width {
+			t.Errorf("line %d is %d cells wide, want %d", i, w, model.Width)
+		}
+	}
+}
+
+func TestEditDates(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-72 * time.Hour)
+
+	edited := &StackItem{CreatedAt: created, UpdatedAt: now.Add(-2 * time.Hour)}
+	if got, want := editDates(edited, now), "created 3d ago · modified 2h ago"; got != want {
+		t.Errorf("editDates() = %q, want %q", got, want)
+	}
+	for _, item := range []*StackItem{
+		{CreatedAt: created, UpdatedAt: created.Add(30 * time.Second)},
+		{UpdatedAt: now},
+	} {
+		if got := editDates(item, now); got != "" {
+			t.Errorf("editDates(%v, %v) = %q, want nothing for an unedited item", item.CreatedAt, item.UpdatedAt, got)
+		}
+	}
+}
@@ -2,6 +2,9 @@ package tui
 
 import (
 	"regexp"
+	"unicode/utf8"
+
+	"github.com/yiblet/rem/internal/textnorm"
 )
 
 // SearchMsg represents messages that the search component handles
@@ -48,6 +51,9 @@ type SearchModel struct {
 	Error        string // search error message
 	Matches      []int  // line numbers with matches
 	CurrentMatch int    // current match index (-1 if no matches)
+
+	// History holds executed patterns for recall with up/down and ctrl+r
+	History SearchHistory
 }
 
 // NewSearchModel creates a new search model with default values
@@ -69,8 +75,11 @@ func (s *SearchModel) Update(msg SearchMsg) error {
 		s.Active = true
 		s.Input = ""
 		s.Error = ""
+		s.History.reset()
 	case UpdateSearchInputMsg:
+		// Editing a recalled pattern keeps it
 		s.Input = m.Input
+		s.History.reset()
 	case ExecuteSearchMsg:
 		// Validate and compile the search pattern
 		if s.Input == "" {
@@ -89,11 +98,14 @@ func (s *SearchModel) Update(msg SearchMsg) error {
 			s.Pattern = s.Input
 			s.Error = ""
 			s.Active = false
+			s.History.add(s.Input)
 		}
+		s.History.reset()
 	case CancelSearchMsg:
 		s.Active = false
 		s.Input = ""
 		s.Error = ""
+		s.History.reset()
 	case NextMatchMsg:
 		if len(s.Matches) > 0 {
 			s.CurrentMatch = (s.CurrentMatch + 1) % len(s.Matches)
@@ -107,6 +119,8 @@ func (s *SearchModel) Update(msg SearchMsg) error {
 		s.Matches = nil
 		s.CurrentMatch = -1
 		s.Error = ""
+	case HistoryPrevMsg, HistoryNextMsg, ReverseSearchMsg, UpdateReverseQueryMsg, RestoreInputMsg:
+		s.updateHistory(msg)
 	}
 	return nil
 }
@@ -173,3 +187,62 @@ func (s *SearchModel) SetMatches(matches []int) {
 		s.CurrentMatch = -1
 	}
 }
+
+// compileSearch compiles a viewer search pattern, case-insensitively. A
+// plain pattern is normalized like the text it is matched against, as CLI
+// searches do; a pattern with regex metacharacters is used as written.
+func compileSearch(pattern string) (*regexp.Regexp, error) {
+	if regexp.QuoteMeta(pattern) == pattern {
+		pattern = textnorm.Normalize(pattern, true)
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// findMatches returns the byte ranges of s that a compileSearch regex
+// matches in s's normalized form, so "uber" finds "Über". A match covering
+// part of what a character normalizes to, such as the f of "ﬁ", covers the
+// whole character; matches left overlapping by that are dropped.
+func findMatches(regex *regexp.Regexp, s string) [][]int {
+	ascii := true
+	for i := 0; i < len(s) && ascii; i++ {
+		ascii = s[i] < utf8.RuneSelf
+	}
+	if ascii {
+		return regex.FindAllStringIndex(s, -1)
+	}
+
+	// Normalize rune by rune, noting the source rune of each normalized byte
+	normalized := make([]byte, 0, len(s))
+	var starts, ends []int
+	for i := 0; i < len(s); {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		n := s[i : i+size]
+		if n[0] >= utf8.RuneSelf {
+			n = textnorm.Normalize(n, true)
+		}
+		for range len(n) {
+			starts, ends = append(starts, i), append(ends, i+size)
+		}
+		normalized = append(normalized, n...)
+		i += size
+	}
+
+	var matches [][]int
+	lastEnd := -1
+	for _, m := range regex.FindAllIndex(normalized, -1) {
+		start := len(s)
+		if m[0] < len(starts) {
+			start = starts[m[0]]
+		}
+		end := start
+		if m[1] > m[0] {
+			end = ends[m[1]-1]
+		}
+		if start < lastEnd {
+			continue
+		}
+		matches = append(matches, []int{start, end})
+		lastEnd = end
+	}
+	return matches
+}
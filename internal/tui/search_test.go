@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -243,3 +244,33 @@ func TestSearchModel_NoMatches(t *testing.T) {
 		t.Errorf("Expected total matches to be 0, got %d", totalMatches)
 	}
 }
+
+func TestFindMatches_Normalized(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		want          []string
+	}{
+		{"uber", "Über and UBER", []string{"Über", "UBER"}},
+		{"Über", "uber", []string{"uber"}},
+		{"cafe", "un café", []string{"café"}},
+		{"file", "ﬁle.txt", []string{"ﬁle"}},
+		{"f", "ﬁ", []string{"ﬁ"}},
+		{"f|i", "ﬁ", []string{"ﬁ"}}, // both halves of one character match once
+		{"strasse", "Straße", []string{"Straße"}},
+		{"ub.r", "Über", []string{"Über"}},
+		{"Üb.r", "Über", nil}, // regex patterns are used as written
+	}
+	for _, tt := range tests {
+		regex, err := compileSearch(tt.pattern)
+		if err != nil {
+			t.Fatalf("compileSearch(%q) error = %v", tt.pattern, err)
+		}
+		var got []string
+		for _, m := range findMatches(regex, tt.text) {
+			got = append(got, tt.text[m[0]:m[1]])
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q in %q matched %q, want %q", tt.pattern, tt.text, got, tt.want)
+		}
+	}
+}
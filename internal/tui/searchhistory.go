@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+)
+
+// MaxSearchHistory is the number of executed search patterns remembered
+const MaxSearchHistory = 50
+
+// SearchHistory holds executed search patterns, oldest first, and the state
+// of recalling them into the search input
+type SearchHistory struct {
+	entries []string
+
+	// browsing is true while up/down or ctrl+r has replaced the input;
+	// saved is the input typed before that began
+	browsing bool
+	saved    string
+	index    int // entry shown by up/down
+
+	// reverse is true during a ctrl+r search for query; match is the index
+	// of the entry found, or -1
+	reverse bool
+	query   string
+	match   int
+}
+
+// Entries returns the remembered patterns, oldest first
+func (h *SearchHistory) Entries() []string {
+	return slices.Clone(h.entries)
+}
+
+// SetEntries replaces the remembered patterns, keeping the newest
+// MaxSearchHistory of them and only the most recent of any duplicates
+func (h *SearchHistory) SetEntries(entries []string) {
+	h.entries = nil
+	for _, entry := range entries {
+		h.add(entry)
+	}
+	h.reset()
+}
+
+// add records an executed pattern as the most recent entry
+func (h *SearchHistory) add(pattern string) {
+	if pattern == "" {
+		return
+	}
+	h.entries = slices.DeleteFunc(h.entries, func(entry string) bool { return entry == pattern })
+	h.entries = append(h.entries, pattern)
+	if len(h.entries) > MaxSearchHistory {
+		h.entries = h.entries[len(h.entries)-MaxSearchHistory:]
+	}
+}
+
+// reset ends any recall in progress
+func (h *SearchHistory) reset() {
+	h.browsing = false
+	h.saved = ""
+	h.index = len(h.entries)
+	h.reverse = false
+	h.query = ""
+	h.match = -1
+}
+
+// begin starts recalling, remembering the input to restore
+func (h *SearchHistory) begin(input string) {
+	if !h.browsing {
+		h.browsing = true
+		h.saved = input
+		h.index = len(h.entries)
+	}
+}
+
+// Searching reports whether a ctrl+r reverse search is in progress, and its
+// query
+func (h *SearchHistory) Searching() (string, bool) {
+	return h.query, h.reverse
+}
+
+// HistoryPrevMsg recalls the next older pattern into the input
+type HistoryPrevMsg struct{}
+
+func (HistoryPrevMsg) isSearchMsg() {}
+
+// HistoryNextMsg recalls the next newer pattern, ending with the input as
+// it was before recalling began
+type HistoryNextMsg struct{}
+
+func (HistoryNextMsg) isSearchMsg() {}
+
+// ReverseSearchMsg starts a ctrl+r search of the history, or finds the next
+// older match when one is in progress
+type ReverseSearchMsg struct{}
+
+func (ReverseSearchMsg) isSearchMsg() {}
+
+// UpdateReverseQueryMsg changes the ctrl+r query
+type UpdateReverseQueryMsg struct {
+	Query string
+}
+
+func (UpdateReverseQueryMsg) isSearchMsg() {}
+
+// RestoreInputMsg ends recalling and puts back the input typed before it
+type RestoreInputMsg struct{}
+
+func (RestoreInputMsg) isSearchMsg() {}
+
+// updateHistory handles the history messages
+func (s *SearchModel) updateHistory(msg SearchMsg) {
+	h := &s.History
+	switch m := msg.(type) {
+	case HistoryPrevMsg:
+		h.reverse = false
+		h.begin(s.Input)
+		if h.index > 0 {
+			h.index--
+			s.Input = h.entries[h.index]
+		}
+	case HistoryNextMsg:
+		h.reverse = false
+		if !h.browsing {
+			return
+		}
+		if h.index < len(h.entries)-1 {
+			h.index++
+			s.Input = h.entries[h.index]
+			return
+		}
+		s.Input = h.saved
+		h.reset()
+	case ReverseSearchMsg:
+		if !h.reverse {
+			h.begin(s.Input)
+			h.reverse = true
+			h.query = ""
+			h.match = h.find(len(h.entries) - 1)
+		} else if older := h.find(h.match - 1); older >= 0 {
+			h.match = older
+		}
+		s.showMatch()
+	case UpdateReverseQueryMsg:
+		h.query = m.Query
+		h.match = h.find(len(h.entries) - 1)
+		s.showMatch()
+	case RestoreInputMsg:
+		s.Input = h.saved
+		h.reset()
+	}
+}
+
+// find returns the index of the newest entry at or before from that
+// contains the query, case-insensitively, or -1
+func (h *SearchHistory) find(from int) int {
+	query := strings.ToLower(h.query)
+	for i := from; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.entries[i]), query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// showMatch puts the reverse search match, if any, in the input
+func (s *SearchModel) showMatch() {
+	if h := &s.History; h.match >= 0 {
+		s.Input = h.entries[h.match]
+		h.index = h.match
+	}
+}
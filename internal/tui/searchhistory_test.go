@@ -0,0 +1,137 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAppModel_SearchHistory(t *testing.T) {
+	model := NewModel([]*StackItem{{Content: NewStringReadSeekCloser("alpha\nbeta\ngamma\n"), Preview: "text"}}, newTestClipboard())
+	model.UpdateMockSize(120, 30)
+
+	send := func(msgs ...tea.Msg) {
+		for _, msg := range msgs {
+			updated, _ := model.Update(msg)
+			model = updated.(Model)
+		}
+	}
+	typed := func(s string) {
+		for _, r := range s {
+			send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	search := func(pattern string) {
+		typed("/" + pattern)
+		send(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+	ctrlR := tea.KeyMsg{Type: tea.KeyCtrlR}
+	input := func() string { return model.app.Search.GetInput() }
+	typed("l") // search from the right pane
+
+	search("alpha")
+	search("beta")
+	search("gamma")
+	search("beta") // collapsed into the most recent entry
+	if got := model.SearchHistory(); !slices.Equal(got, []string{"alpha", "gamma", "beta"}) {
+		t.Fatalf("expected duplicates collapsed to the most recent, got %v", got)
+	}
+
+	// Up and down cycle through the history and back to what was typed
+	typed("/al")
+	send(up)
+	if input() != "beta" {
+		t.Errorf("expected the newest pattern first, got %q", input())
+	}
+	send(up, up, up)
+	if input() != "alpha" {
+		t.Errorf("expected up to stop at the oldest pattern, got %q", input())
+	}
+	send(down)
+	if input() != "gamma" {
+		t.Errorf("expected down to go newer, got %q", input())
+	}
+	send(down, down)
+	if input() != "al" {
+		t.Errorf("expected down past the newest pattern to restore the input, got %q", input())
+	}
+
+	// Escape restores the typed input before it cancels the search
+	send(up, up)
+	send(esc)
+	if input() != "al" || model.app.CurrentMode != SearchMode {
+		t.Errorf("expected esc to restore %q and stay in search mode, got %q in mode %v", "al", input(), model.app.CurrentMode)
+	}
+
+	// A recalled pattern can be edited and executed as a new entry
+	send(up)
+	typed("2")
+	send(esc)
+	if model.app.CurrentMode != NormalMode {
+		t.Error("expected esc after editing a recalled pattern to cancel the search")
+	}
+	typed("/")
+	send(up)
+	typed("x")
+	send(tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := model.SearchHistory(); !slices.Equal(got, []string{"alpha", "gamma", "beta"}) || model.app.Search.GetPattern() != "beta" {
+		t.Errorf("expected the edited pattern to search for beta, got %q with history %v", model.app.Search.GetPattern(), got)
+	}
+
+	// Ctrl+r finds the newest match of the query; again for older matches
+	search("alphabet")
+	typed("/")
+	send(ctrlR)
+	typed("ph")
+	if input() != "alphabet" {
+		t.Errorf("expected ctrl+r to find the newest match, got %q", input())
+	}
+	send(ctrlR)
+	if input() != "alpha" {
+		t.Errorf("expected ctrl+r again to find an older match, got %q", input())
+	}
+	send(ctrlR)
+	if input() != "alpha" {
+		t.Errorf("expected the oldest match to stay selected, got %q", input())
+	}
+	if view := model.View(); !strings.Contains(view, "reverse-i-search)`ph': alpha") {
+		t.Errorf("expected the reverse search prompt in the status line")
+	}
+	typed("z")
+	if !strings.Contains(model.View(), "(failing reverse-i-search)`phz'") {
+		t.Error("expected a failing reverse search to be shown")
+	}
+	send(esc)
+	if input() != "" {
+		t.Errorf("expected esc to restore the empty input, got %q", input())
+	}
+	send(ctrlR)
+	typed("gam")
+	send(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.app.Search.GetPattern() != "gamma" || model.SearchHistory()[len(model.SearchHistory())-1] != "gamma" {
+		t.Errorf("expected enter to execute the ctrl+r match, got %q", model.app.Search.GetPattern())
+	}
+}
+
+func TestSearchHistory_Cap(t *testing.T) {
+	var history SearchHistory
+	var patterns []string
+	for i := 0; i < MaxSearchHistory+10; i++ {
+		patterns = append(patterns, fmt.Sprintf("pattern %d", i))
+	}
+	history.SetEntries(append(patterns, "pattern 20"))
+
+	got := history.Entries()
+	if len(got) != MaxSearchHistory {
+		t.Fatalf("expected %d entries, got %d", MaxSearchHistory, len(got))
+	}
+	if got[0] != "pattern 10" || got[len(got)-1] != "pattern 20" || slices.Index(got, "pattern 20") != len(got)-1 {
+		t.Errorf("expected the newest patterns with the repeat moved last, got %v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+)
+
+// resetSequences leave the alternate screen, show the cursor, reset text
+// attributes, and turn off mouse reporting and bracketed paste: what rmcup,
+// cnorm, and sgr0 send on xterm-like terminals, and what a program that
+// died in the TUI may have left on
+const resetSequences = "\x1b[?1049l" + "\x1b[?25h" + "\x1b[0m" + "\x1b[?1000l\x1b[?1002l\x1b[?1006l" + "\x1b[?2004l"
+
+// ResetTerminal writes the sequences that undo what the TUI sets up, for a
+// terminal left in the alternate screen by a TUI that didn't exit cleanly
+func ResetTerminal(w io.Writer) error {
+	_, err := io.WriteString(w, resetSequences)
+	return err
+}
+
+// PanicError is returned by Run when the model panicked
+type PanicError struct {
+	In    string // the method that panicked: Init, Update, or View
+	Value any    // what was passed to panic
+	Stack []byte // the panicking goroutine's stack
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("viewer crashed in %s: %v", e.In, e.Value)
+}
+
+// Run runs model until it quits, like tea.Program.Run. The terminal's state
+// is saved first and put back however the program ends, and a panic in the
+// model is returned as a *PanicError instead of crashing with the terminal
+// left in the alternate screen.
+func Run(model tea.Model, opts ...tea.ProgramOption) (final tea.Model, err error) {
+	if state, stateErr := term.GetState(os.Stdin.Fd()); stateErr == nil {
+		defer term.Restore(os.Stdin.Fd(), state)
+	}
+
+	guard := &panicGuard{model: model}
+	defer func() {
+		if r := recover(); r != nil {
+			ResetTerminal(os.Stdout)
+			final, err = model, &PanicError{In: "Run", Value: r, Stack: debug.Stack()}
+		}
+	}()
+	_, err = tea.NewProgram(guard, opts...).Run()
+	if guard.err != nil {
+		return guard.model, guard.err
+	}
+	return guard.model, err
+}
+
+// panicGuard wraps a model, recovering from its panics. A panic in Init or
+// Update quits the program; one in View shows the error until the next
+// message quits it, since View can't.
+type panicGuard struct {
+	model tea.Model
+	err   *PanicError // the first panic recovered
+}
+
+// recovered records the panic r, recovered in method
+func (g *panicGuard) recovered(method string, r any) {
+	if g.err == nil {
+		g.err = &PanicError{In: method, Value: r, Stack: debug.Stack()}
+	}
+}
+
+func (g *panicGuard) Init() (cmd tea.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.recovered("Init", r)
+			cmd = tea.Quit
+		}
+	}()
+	return g.model.Init()
+}
+
+func (g *panicGuard) Update(msg tea.Msg) (model tea.Model, cmd tea.Cmd) {
+	if g.err != nil {
+		return g, tea.Quit
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.recovered("Update", r)
+			model, cmd = g, tea.Quit
+		}
+	}()
+	g.model, cmd = g.model.Update(msg)
+	return g, cmd
+}
+
+func (g *panicGuard) View() (view string) {
+	if g.err != nil {
+		return g.err.Error() + "\n"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.recovered("View", r)
+			view = g.err.Error() + "\n"
+		}
+	}()
+	return g.model.View()
+}
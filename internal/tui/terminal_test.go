@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// panickyContent is item content whose reads panic, like a renderer bug
+type panickyContent struct {
+	*StringReadSeekCloser
+}
+
+func (panickyContent) Read([]byte) (int, error) {
+	panic("corrupt item")
+}
+
+// panickyModel panics in Update once it sees its first key
+type panickyModel struct{}
+
+func (panickyModel) Init() tea.Cmd { return nil }
+
+func (m panickyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		panic("boom")
+	}
+	return m, nil
+}
+
+func (panickyModel) View() string { return "ok" }
+
+func TestRun_ReturnsPanicAsError(t *testing.T) {
+	var out bytes.Buffer
+	_, err := Run(panickyModel{}, tea.WithInput(strings.NewReader("j")), tea.WithOutput(&out))
+	var crash *PanicError
+	if !errors.As(err, &crash) {
+		t.Fatalf("Run() error = %v, want a *PanicError", err)
+	}
+	if crash.In != "Update" || crash.Value != "boom" || len(crash.Stack) == 0 {
+		t.Errorf("crash = %q in %s with %d bytes of stack, want boom in Update", crash.Value, crash.In, len(crash.Stack))
+	}
+}
+
+func TestRun_ViewShowsRendererPanic(t *testing.T) {
+	items := []*StackItem{{Content: panickyContent{NewStringReadSeekCloser("x")}, Preview: "broken"}}
+	app := NewAppModel(items, newTestClipboard())
+
+	// The error fills the screen rather than leaving it blank
+	app.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	view := app.View()
+	if !strings.Contains(view, "corrupt item") || !strings.Contains(view, "Press q to quit") {
+		t.Errorf("View() = %q, want the panic and how to quit", view)
+	}
+
+	// The program still quits normally afterwards
+	var out bytes.Buffer
+	if _, err := Run(&app, tea.WithInput(strings.NewReader("q")), tea.WithOutput(&out)); err != nil {
+		t.Errorf("Run() error = %v, want a clean quit", err)
+	}
+}
+
+func TestAppModelView_NeverEmpty(t *testing.T) {
+	app := NewAppModel(nil, newTestClipboard())
+	for _, size := range []tea.WindowSizeMsg{{Width: 80, Height: 24}, {Width: 10, Height: 3}, {Width: 1, Height: 1}} {
+		app.Update(size)
+		if view := app.View(); view == "" {
+			t.Errorf("View() at %dx%d is empty", size.Width, size.Height)
+		}
+	}
+}
+
+func TestResetTerminal(t *testing.T) {
+	var out bytes.Buffer
+	if err := ResetTerminal(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, seq := range []string{"\x1b[?1049l", "\x1b[?25h", "\x1b[0m"} {
+		if !strings.Contains(out.String(), seq) {
+			t.Errorf("ResetTerminal wrote %q, missing %q", out.String(), seq)
+		}
+	}
+}
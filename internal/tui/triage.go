@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// triageContentLines is how many content lines a triage block shows under
+// its title
+const triageContentLines = 3
+
+// triageBlockHeight is the rows a triage block takes: its title and content
+// lines
+const triageBlockHeight = 1 + triageContentLines
+
+// triagePeekBytes is how much of the start of an item a triage block reads
+const triagePeekBytes = 4096
+
+// Triage shows, in place of the full viewer, a compact block for the
+// selected item and those below it, to glance at many items while moving
+// down the list
+type Triage struct {
+	Active bool
+
+	// item and viewPos are the viewer's item and scroll position when
+	// triage began, restored when it ends on the same item
+	item    *StackItem
+	viewPos int
+}
+
+// triageCount returns how many blocks, the selected item's first, fit in
+// height rows of content
+func triageCount(height int) int {
+	return max(height/triageBlockHeight, 1)
+}
+
+// toggleTriage switches the right pane between the full viewer and triage
+// blocks. The list keeps focus in triage so j/k move through the items.
+func (a *AppModel) toggleTriage() tea.Cmd {
+	var selected *StackItem
+	if a.LeftPane.Selected < len(a.Items) {
+		selected = a.Items[a.LeftPane.Selected]
+	}
+
+	if !a.Triage.Active {
+		a.Triage = Triage{Active: true, item: selected, viewPos: a.RightPane.ViewPos}
+		a.ActivePane = LeftPane
+		return a.setFlashMessage("Triage view (t or Enter for the full view)", 2*time.Second)
+	}
+
+	a.Triage.Active = false
+	if selected != nil && selected == a.Triage.item {
+		a.RightPane.ViewPos = a.Triage.viewPos
+	}
+	return nil
+}
+
+// peekLines returns the first display lines of the item's content at width,
+// or noWrapWidth, reading only its start. They are read when first needed
+// and kept until the width, tab width, or encoding changes.
+func (q *StackItem) peekLines(width int) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.peek == nil || q.peekWidth != width {
+		q.peek, q.peekWidth = q.readPeek(width), width
+	}
+	return q.peek
+}
+
+// readPeek reads the lines peekLines returns. The caller holds mu.
+func (q *StackItem) readPeek(width int) []string {
+	if q.IsBinary {
+		return []string{fmt.Sprintf("(binary, %d bytes)", q.contentSize())}
+	}
+	reader, err := q.NewReader()
+	if q.isDeleted(err) {
+		q.deleted = true
+		return []string{"(deleted)"}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("(can't read content: %v)", err)}
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, triagePeekBytes))
+	if q.isDeleted(err) {
+		q.deleted = true
+		return []string{"(deleted)"}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("(can't read content: %v)", err)}
+	}
+	if len(data) == 0 {
+		return []string{"(empty)"}
+	}
+
+	lines := []string{}
+	for _, source := range strings.SplitN(string(data), "\n", triageContentLines+1) {
+		wrapped, _ := wrapSegment(strings.TrimSuffix(source, "\r"), width, q.tabStop(), q.latin1)
+		lines = append(lines, wrapped...)
+		if len(lines) >= triageContentLines {
+			return lines[:triageContentLines]
+		}
+	}
+	return lines
+}
+
+// TriagePaneView renders the right pane in triage: a block for each of the
+// items from selected down that fits, its title and first lines (pure
+// function apart from reading and caching those lines)
+func TriagePaneView(model RightPaneModel, items []*StackItem, selected int, focused bool) string {
+	end := min(selected+triageCount(model.contentHeight()), len(items))
+	title := fmt.Sprintf("Triage [%d-%d]", selected, max(end-1, selected))
+	if focused {
+		title = "● " + title
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(title) + "\n\n")
+	if selected >= len(items) {
+		b.WriteString("No content selected")
+	}
+	width := model.textWidth()
+	for i := selected; i < end && items[i] != nil; i++ {
+		item := items[i]
+		heading := ellipsize(fmt.Sprintf("[%d] %s", i, item.Preview), width)
+		headingStyle := lipgloss.NewStyle().Bold(true)
+		if i == selected {
+			headingStyle = headingStyle.Foreground(lipgloss.Color("205"))
+		}
+		b.WriteString(headingStyle.Render(heading) + "\n")
+
+		item.setTabWidth(model.TabWidth)
+		item.setLatin1(model.Latin1)
+		lines := item.peekLines(model.wrapWidth())
+		for row := range triageContentLines {
+			if row < len(lines) {
+				line, ellipsis := truncateToWidth(lines[row], width)
+				b.WriteString(line + ellipsis)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	body := strings.TrimSuffix(b.String(), "\n")
+	return model.frameStyle(focused, false).Render(model.withHints(body, focused))
+}
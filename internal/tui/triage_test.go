@@ -0,0 +1,170 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestTriagePaneView_Blocks(t *testing.T) {
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser("first\nsecond\tline\r\nthird\nfourth"), Preview: "notes"},
+		{Content: NewStringReadSeekCloser("\x00\x01"), Preview: "image.png", IsBinary: true, Size: 2},
+		{Content: NewStringReadSeekCloser(""), Preview: "[empty]"},
+		{Content: NewStringReadSeekCloser(strings.Repeat("word ", 40)), Preview: "long"},
+	}
+	model := NewRightPaneModel(60, 30)
+	view := TriagePaneView(model, items, 0, false)
+
+	for _, want := range []string{"Triage [0-3]", "[0] notes", "first", "second  line", "third", "[1] image.png", "(binary, 2 bytes)", "[2] [empty]", "(empty)", "[3] long"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the triage view:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "fourth") {
+		t.Errorf("expected only the first %d lines of each item:\n%s", triageContentLines, view)
+	}
+
+	// A long line wraps to the pane's width, still within its block
+	if got := items[3].peekLines(model.wrapWidth()); len(got) != triageContentLines {
+		t.Errorf("expected a long line wrapped to %d lines, got %q", triageContentLines, got)
+	}
+	model.NoWrap = true
+	if got := items[3].peekLines(model.wrapWidth()); len(got) != 1 {
+		t.Errorf("expected one line without wrapping, got %q", got)
+	}
+}
+
+func TestTriageCount(t *testing.T) {
+	items := make([]*StackItem, 20)
+	for i := range items {
+		items[i] = &StackItem{Content: NewStringReadSeekCloser(fmt.Sprintf("content %d", i)), Preview: fmt.Sprintf("item %d", i)}
+	}
+
+	tests := []struct {
+		height int // pane height
+		want   int // blocks shown
+	}{
+		{10, 1},
+		{13, 1},
+		{14, 2},
+		{30, 6},
+		{60, 13},
+	}
+	for _, tt := range tests {
+		model := NewRightPaneModel(60, tt.height)
+		if got := triageCount(model.contentHeight()); got != tt.want {
+			t.Errorf("height %d: triageCount() = %d, want %d", tt.height, got, tt.want)
+		}
+		view := TriagePaneView(model, items, 2, true)
+		if got := strings.Count(view, "] item "); got != tt.want {
+			t.Errorf("height %d: %d blocks shown, want %d", tt.height, got, tt.want)
+		}
+
+		// The pane keeps the list's height, so the borders line up
+		left := NewLeftPaneModel(25, tt.height)
+		leftView, _ := LeftPaneView(left, items, false)
+		if l, r := strings.Count(leftView, "\n"), strings.Count(view, "\n"); l != r {
+			t.Errorf("height %d: left pane has %d lines, triage pane %d", tt.height, l+1, r+1)
+		}
+	}
+
+	// Near the end of the list only the remaining items are shown
+	model := NewRightPaneModel(60, 30)
+	if view := TriagePaneView(model, items, 18, false); !strings.Contains(view, "Triage [18-19]") || strings.Count(view, "] item ") != 2 {
+		t.Errorf("expected the last two items:\n%s", view)
+	}
+}
+
+func TestTriage_CachesBlocks(t *testing.T) {
+	ops := newFakeItemOps()
+	var items []*StackItem
+	for id := uint(1); id <= 12; id++ {
+		item := ops.item(id, fmt.Sprintf("content of item %d\n", id))
+		item.Preview = fmt.Sprintf("item %d", id)
+		items = append(items, item)
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.SetItemOps(ops)
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	press(&app, "t")
+	AppView(app)
+
+	// Only the blocks shown are read
+	opened := len(ops.opened)
+	if want := 12 + triageCount(app.RightPane.contentHeight()); opened != want {
+		t.Errorf("expected %d readers opened, got %d", want, opened)
+	}
+	for i, item := range items {
+		if cached := item.peek != nil; cached != (i < triageCount(app.RightPane.contentHeight())) {
+			t.Errorf("item %d: cached = %v", i, cached)
+		}
+	}
+
+	// Moving down reads only the newly shown block, and back up none
+	press(&app, "j")
+	AppView(app)
+	if got := len(ops.opened); got != opened+1 {
+		t.Errorf("expected one more reader after j, got %d more", got-opened)
+	}
+	press(&app, "k")
+	view, _ := AppView(app)
+	if got := len(ops.opened); got != opened+1 {
+		t.Errorf("expected no reads scrolling back up, got %d more", got-opened-1)
+	}
+	if !strings.Contains(view, "content of item 1") {
+		t.Errorf("expected the cached block shown:\n%s", view)
+	}
+}
+
+func TestTriage_Toggle(t *testing.T) {
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	items := []*StackItem{
+		{Content: NewStringReadSeekCloser(strings.Join(lines, "\n")), Preview: "long"},
+		{Content: NewStringReadSeekCloser("other"), Preview: "other"},
+	}
+	app := NewAppModel(items, newTestClipboard())
+	app.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	AppView(app)
+	app.ActivePane = RightPane
+	for _, key := range []string{"1", "0", "j"} {
+		press(&app, key)
+	}
+	viewPos := app.RightPane.ViewPos
+	if viewPos == 0 {
+		t.Fatal("expected the viewer scrolled")
+	}
+
+	// t shows triage with the list focused
+	press(&app, "t")
+	if !app.Triage.Active || app.ActivePane != LeftPane {
+		t.Fatalf("expected triage with the list focused, got active %v, pane %v", app.Triage.Active, app.ActivePane)
+	}
+	if view, _ := AppView(app); !strings.Contains(view, "Triage [0-1]") {
+		t.Errorf("expected the triage pane:\n%s", view)
+	}
+
+	// Leaving on the same item restores its scroll position
+	press(&app, "j")
+	press(&app, "k")
+	press(&app, "t")
+	if app.Triage.Active || app.RightPane.ViewPos != viewPos {
+		t.Errorf("expected the full view at line %d, got active %v, line %d", viewPos, app.Triage.Active, app.RightPane.ViewPos)
+	}
+
+	// Enter leaves triage for the item selected there, from the top
+	press(&app, "t")
+	press(&app, "j")
+	app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Triage.Active || app.LeftPane.Selected != 1 || app.RightPane.ViewPos != 0 {
+		t.Errorf("expected item 1's full view from the top, got active %v, item %d, line %d", app.Triage.Active, app.LeftPane.Selected, app.RightPane.ViewPos)
+	}
+	if view, _ := AppView(app); strings.Contains(view, "Triage [") || !strings.Contains(view, "Content [1]") {
+		t.Errorf("expected the full viewer:\n%s", view)
+	}
+}
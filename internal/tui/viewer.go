@@ -3,10 +3,13 @@ package tui
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yiblet/rem/internal/clipboard"
@@ -59,63 +62,161 @@ func (s *StringReadSeekCloser) Close() error {
 
 // StackItem represents an item in the rem queue
 type StackItem struct {
-	ID             string // Unique identifier for this item
+	ID             uint // ID of the item in persistent storage
 	Content        io.ReadSeekCloser
 	Preview        string
-	Lines          []string     // cached wrapped lines (viewport window)
-	LinesStart     int          // first source line number in cache
-	LinesEnd       int          // last source line number in cache
-	CachedWidth    int          // width used for cached lines (0 = not cached)
-	ViewPos        int          // current view position (line number)
-	SearchPattern  string       // current search pattern
-	SearchMatches  []int        // line numbers with matches
-	SearchIndex    int          // current match index (-1 if no search active)
-	SearchLimitHit bool         // true if search stopped at 99 matches
-	IsBinary       bool         // true if content is binary
-	Size           int64        // size in bytes (useful for binary files)
-	SHA256         string       // SHA256 hash (for binary files)
-	DeleteFunc     func() error // function to delete this item from persistent storage
-
-	pager *Pager // NEW: Streaming pager for content access
-}
-
-// GetFullContent reads the entire content from the ReadSeekCloser
-func (q *StackItem) GetFullContent() (string, error) {
-	// Save current position
-	currentPos, err := q.Content.Seek(0, io.SeekCurrent)
+	Note           string      // user note shown under the title
+	Timestamp      time.Time   // position in the queue order; bumping moves it (zero if unknown)
+	CreatedAt      time.Time   // when the item was first stored (zero if unknown)
+	UpdatedAt      time.Time   // when the item was last changed (zero if unknown)
+	Lines          []string    // cached wrapped lines (viewport window)
+	LinesStart     int         // display line number of Lines[0]
+	LinesEnd       int         // display line number just past the end of Lines
+	CachedWidth    int         // width used for cached lines (0 = not cached)
+	ViewPos        int         // current view position (line number)
+	SearchPattern  string      // current search pattern
+	SearchHits     []SearchHit // match positions in the content
+	SearchMatches  []int       // display line numbers with matches
+	SearchIndex    int         // current match index (-1 if no search active)
+	SearchLimitHit bool        // true if search stopped at 99 matches
+	MatchCount     int         // matches found by the last search (shown as a badge)
+	IsBinary       bool        // true if content is binary
+	Size           int64       // size in bytes (useful for binary files)
+	SHA256         string      // SHA256 hash (for binary files)
+
+	ops         ItemOps    // reopens content after Close; nil means a closed item stays closed
+	tabWidth    int        // columns between tab stops; 0 means DefaultTabWidth
+	latin1      bool       // true to show bytes that aren't UTF-8 as Latin-1
+	nonUTF8     bool       // true once wrapping met bytes that aren't UTF-8
+	deleted     bool       // true once a read found the item deleted from storage
+	peek        []string   // first display lines for triage, at peekWidth
+	peekWidth   int        // width peek was wrapped to
+	pager       *Pager     // streams content for display through a contentReader
+	index       *lineIndex // maps byte offsets to display lines at CachedWidth
+	lineOffsets []int64    // byte offset of each line in Lines
+	linesAtEOF  bool       // true if Lines extends to the end of content
+	links       []link     // URLs in the source of the loaded window
+
+	diffChecked bool           // true once isDiff has been determined
+	isDiff      bool           // true if the content looks like a unified diff
+	lineKinds   []diffLineKind // diff kind of each line in Lines, when isDiff
+
+	mu        sync.Mutex // held while the line cache and search results are rebuilt
+	contentMu sync.Mutex // guards Content, which readers share
+}
+
+// SearchHit records where a search match starts in the content
+type SearchHit struct {
+	SourceLine int   // zero-based source line number
+	Offset     int64 // byte offset of the match start
+}
+
+// errContentClosed is returned when a closed item without ItemOps is read
+var errContentClosed = errors.New("content reader is closed")
+
+// open makes Content available, reopening it through ItemOps after Close.
+// The caller holds contentMu.
+func (q *StackItem) open() error {
+	if q.Content != nil {
+		return nil
+	}
+	if q.ops == nil {
+		return errContentClosed
+	}
+	content, err := q.ops.Content(q.ID)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to reopen content: %w", err)
 	}
+	q.Content = content
+	return nil
+}
 
-	// Read from beginning
-	if _, err := q.Content.Seek(0, io.SeekStart); err != nil {
-		return "", err
+// Close closes the item's content reader. Cached lines and search results are
+// kept, and the reader is reopened on next use if the item has ItemOps.
+func (q *StackItem) Close() error {
+	q.contentMu.Lock()
+	defer q.contentMu.Unlock()
+
+	if q.Content == nil {
+		return nil
+	}
+	err := q.Content.Close()
+	q.Content = nil
+	return err
+}
+
+// release closes the reader of an item that can be reopened later
+func (q *StackItem) release() error {
+	if q.ops == nil {
+		return nil
 	}
+	return q.Close()
+}
 
-	content, err := io.ReadAll(q.Content)
+// GetFullContent reads the entire content through its own reader
+func (q *StackItem) GetFullContent() (string, error) {
+	reader, err := q.NewReader()
 	if err != nil {
 		return "", err
 	}
+	defer reader.Close()
 
-	// Restore position
-	if _, err := q.Content.Seek(currentPos, io.SeekStart); err != nil {
+	content, err := io.ReadAll(reader)
+	if err != nil {
 		return "", err
 	}
-
 	return string(content), nil
 }
 
+// tabStop returns the columns between tab stops
+func (q *StackItem) tabStop() int {
+	if q.tabWidth <= 0 {
+		return DefaultTabWidth
+	}
+	return q.tabWidth
+}
+
+// setTabWidth sets the columns between tab stops, discarding lines wrapped
+// with another
+func (q *StackItem) setTabWidth(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n != q.tabWidth {
+		q.tabWidth = n
+		q.CachedWidth = 0
+		q.peek = nil
+	}
+}
+
+// setLatin1 sets whether bytes that aren't UTF-8 are shown as Latin-1,
+// discarding lines wrapped the other way
+func (q *StackItem) setLatin1(latin1 bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if latin1 != q.latin1 {
+		q.latin1 = latin1
+		q.CachedWidth = 0
+		q.peek = nil
+	}
+}
+
 // UpdateWrappedLines recalculates wrapped lines based on width using streaming pager
 // Loads only viewport window + buffer for memory efficiency
-func (q *StackItem) UpdateWrappedLines(width, height int) error {
+func (q *StackItem) UpdateWrappedLines(width, height int) (err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer func() {
+		if q.isDeleted(err) {
+			q.deleted = true
+		}
+	}()
+
 	// Check if we need to recalculate
-	// Note: When height > LinesEnd, the viewport is larger than the content,
-	// so we shouldn't trigger recalc based on the bottom edge check
 	needsRecalc := q.CachedWidth != width ||
 		q.ViewPos < q.LinesStart ||
-		(q.LinesEnd > height && q.ViewPos >= q.LinesEnd-height)
+		(!q.linesAtEOF && q.ViewPos+height > q.LinesEnd)
 
-	if !needsRecalc && len(q.Lines) > 0 {
+	if !needsRecalc && (len(q.Lines) > 0 || q.linesAtEOF) {
 		// Cache is valid
 		return nil
 	}
@@ -131,69 +232,31 @@ func (q *StackItem) UpdateWrappedLines(width, height int) error {
 		}
 		// Create a formatted display for binary files
 		q.Lines = q.formatBinaryInfo()
+		q.LinesStart = 0
+		q.LinesEnd = len(q.Lines)
+		q.linesAtEOF = true
 		q.CachedWidth = width
 		return nil
 	}
 
-	// Initialize pager if not already done
-	if q.pager == nil {
-		q.pager = NewPager(q.Content)
+	widthChanged := q.CachedWidth != width
+	if err := q.ensureIndex(width); err != nil {
+		return err
 	}
 
-	// Calculate viewport window: ViewPos ± buffer
+	// Calculate viewport window: ViewPos ± buffer, in display lines
 	const bufferLines = 50
 	windowStart := max(0, q.ViewPos-bufferLines)
 	windowEnd := q.ViewPos + height + bufferLines
 
-	// Seek to start of window
-	if _, err := q.pager.Seek(0, io.SeekStart); err != nil {
+	if err := q.loadWindow(windowStart, windowEnd); err != nil {
 		return err
 	}
-
-	// Skip to windowStart line
-	for i := 0; i < windowStart; i++ {
-		_, err := q.pager.ReadLine()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	// Read and wrap lines in window
-	q.Lines = nil
-	lineNum := windowStart
-	for lineNum < windowEnd {
-		sourceLine, err := q.pager.ReadLine()
-		if err != nil && err != io.EOF {
-			return err
-		}
-
-		// Remove trailing newline for wrapping
-		sourceLine = strings.TrimSuffix(sourceLine, "\n")
-
-		// Process line if not empty
-		if sourceLine != "" {
-			// Wrap this source line
-			wrappedLines := WrapText(sourceLine, width)
-			q.Lines = append(q.Lines, wrappedLines...)
-			lineNum++
-		}
-
-		// Break after processing if we hit EOF
-		if err == io.EOF {
-			break
-		}
-	}
-
-	q.LinesStart = windowStart
-	q.LinesEnd = lineNum
 	q.CachedWidth = width
 
-	// Re-run search if active
-	if q.SearchPattern != "" {
-		q.performSearch(q.SearchPattern)
+	// Display positions of search hits depend on the wrap width
+	if widthChanged && len(q.SearchHits) > 0 {
+		return q.resolveSearchMatches()
 	}
 
 	return nil
@@ -242,104 +305,102 @@ func formatBytes(bytes int64) string {
 
 // calculateSHA256 computes the SHA256 hash of the content by streaming
 func (q *StackItem) calculateSHA256() error {
-	// Save current position
-	currentPos, err := q.Content.Seek(0, io.SeekCurrent)
+	reader, err := q.NewReader()
 	if err != nil {
 		return err
 	}
-
-	// Seek to beginning
-	if _, err := q.Content.Seek(0, io.SeekStart); err != nil {
-		return err
-	}
+	defer reader.Close()
 
 	// Calculate SHA256 by streaming (doesn't load entire file into memory)
 	hasher := sha256.New()
-	if _, err := io.Copy(hasher, q.Content); err != nil {
-		// Restore position before returning error
-		q.Content.Seek(currentPos, io.SeekStart)
+	if _, err := io.Copy(hasher, reader); err != nil {
 		return err
 	}
 
 	q.SHA256 = hex.EncodeToString(hasher.Sum(nil))
-
-	// Restore original position
-	if _, err := q.Content.Seek(currentPos, io.SeekStart); err != nil {
-		return err
-	}
-
 	return nil
 }
 
-// performSearch searches for a regex pattern using streaming, limiting to 99 matches
+// performSearch searches for a regex pattern using streaming, limiting to 99 matches.
+// Matches are recorded by byte offset and converted to display lines afterwards,
+// so long lines are never wrapped as a whole.
 func (q *StackItem) performSearch(pattern string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
 	if pattern == "" {
 		q.SearchPattern = ""
+		q.SearchHits = nil
 		q.SearchMatches = nil
 		q.SearchIndex = -1
 		q.SearchLimitHit = false
+		q.MatchCount = 0
 		return nil
 	}
 
-	regex, err := regexp.Compile("(?i)" + pattern) // Case-insensitive by default
+	regex, err := compileSearch(pattern)
 	if err != nil {
 		return err
 	}
 
 	q.SearchPattern = pattern
+	q.SearchHits = nil
 	q.SearchMatches = nil
 	q.SearchIndex = -1
 	q.SearchLimitHit = false
+	q.MatchCount = 0
 
-	// Use default width if CachedWidth not set
-	wrapWidth := q.CachedWidth
-	if wrapWidth == 0 {
-		wrapWidth = 80 // Default width for search without wrapping calculation
-	}
-
-	// Initialize pager for searching
-	if q.pager == nil {
-		q.pager = NewPager(q.Content)
-	}
-
-	// Seek to beginning
-	if _, err := q.pager.Seek(0, io.SeekStart); err != nil {
+	// Search through a reader of our own so the display pager keeps its place
+	reader, err := q.NewReader()
+	if err != nil {
 		return err
 	}
+	defer reader.Close()
+	pager := NewPager(reader)
 
-	// Stream through file, matching lines (limit to 99 matches)
+	// Stream through the content segment by segment (limit to 99 matches).
+	// The tail of the previous segment is kept so matches spanning a segment
+	// boundary of a long line are still found.
 	const maxMatches = 99
-	wrappedLineNum := 0
+	const searchOverlap = 256
+	sourceLine := 0
+	tail := ""
+	lastEnd := int64(-1)
 
 	for {
-		sourceLine, err := q.pager.ReadLine()
+		offset := pager.Offset()
+		segment, more, err := pager.ReadLineSegment(wrapSegmentBytes)
 		if err != nil && err != io.EOF {
 			return err
 		}
 
-		// Remove trailing newline for matching
-		sourceLine = strings.TrimSuffix(sourceLine, "\n")
-
-		// Process line if not empty
-		if sourceLine != "" {
-			// Check if this source line matches
-			if regex.MatchString(sourceLine) {
-				// Add all wrapped line numbers for this source line
-				wrappedLines := WrapText(sourceLine, wrapWidth)
-				for i := range wrappedLines {
-					q.SearchMatches = append(q.SearchMatches, wrappedLineNum+i)
-
-					// Check if we hit the limit
-					if len(q.SearchMatches) >= maxMatches {
-						q.SearchLimitHit = true
-						goto done
-					}
+		// Match what the viewer shows, so matches line up with the highlighting
+		text := segmentText(segment)
+		if text != "" {
+			base := offset - int64(len(tail))
+			shown, sources := displayText(tail+text, q.tabStop(), q.latin1)
+			for _, m := range findMatches(regex, shown) {
+				start := base + int64(sources.source(m[0]))
+				if start < lastEnd {
+					// Already recorded while searching the previous segment
+					continue
+				}
+				q.SearchHits = append(q.SearchHits, SearchHit{SourceLine: sourceLine, Offset: start})
+				lastEnd = max64(base+int64(sources.source(m[1])), start+1)
+
+				// Check if we hit the limit
+				if len(q.SearchHits) >= maxMatches {
+					q.SearchLimitHit = true
+					goto done
 				}
 			}
+		}
 
-			// Count wrapped lines
-			wrappedLines := WrapText(sourceLine, wrapWidth)
-			wrappedLineNum += len(wrappedLines)
+		if more {
+			tail = text[max(len(text)-searchOverlap, 0):]
+		} else {
+			tail = ""
+			sourceLine++
 		}
 
 		// Break after processing if we hit EOF
@@ -349,6 +410,11 @@ func (q *StackItem) performSearch(pattern string) error {
 	}
 
 done:
+	q.MatchCount = len(q.SearchHits)
+	if err := q.resolveSearchMatches(); err != nil {
+		return err
+	}
+
 	// Set to first match if any found
 	if len(q.SearchMatches) > 0 {
 		q.SearchIndex = 0
@@ -357,6 +423,36 @@ done:
 	return nil
 }
 
+// resolveSearchMatches converts SearchHits into display line numbers at the current width
+func (q *StackItem) resolveSearchMatches() error {
+	// Use default width if CachedWidth not set
+	wrapWidth := q.CachedWidth
+	if wrapWidth == 0 {
+		wrapWidth = 80 // Default width for search without wrapping calculation
+	}
+	if err := q.ensureIndex(wrapWidth); err != nil {
+		return err
+	}
+
+	q.SearchMatches = nil
+	for _, hit := range q.SearchHits {
+		line, err := q.displayLineForOffset(hit.Offset)
+		if err != nil {
+			return err
+		}
+		// Hits are in content order, so duplicates are adjacent
+		if n := len(q.SearchMatches); n > 0 && q.SearchMatches[n-1] == line {
+			continue
+		}
+		q.SearchMatches = append(q.SearchMatches, line)
+	}
+
+	if q.SearchIndex >= len(q.SearchMatches) {
+		q.SearchIndex = len(q.SearchMatches) - 1
+	}
+	return nil
+}
+
 // NextMatch moves to the next search match
 func (q *StackItem) NextMatch() bool {
 	if len(q.SearchMatches) == 0 {
@@ -388,6 +484,7 @@ func (q *StackItem) GetCurrentMatchLine() int {
 // ClearSearch clears the current search
 func (q *StackItem) ClearSearch() {
 	q.SearchPattern = ""
+	q.SearchHits = nil
 	q.SearchMatches = nil
 	q.SearchIndex = -1
 }
@@ -424,6 +521,177 @@ func NewModel(items []*StackItem, clip clipboard.Clipboard) Model {
 	}
 }
 
+// Close closes the content readers of all items. Call it once the program exits.
+func (m *Model) Close() error {
+	return m.app.Close()
+}
+
+// SetItemOps sets the operations used to delete and reopen items
+func (m *Model) SetItemOps(ops ItemOps) {
+	m.app.SetItemOps(ops)
+}
+
+// SetOpener sets the function that opens links in the right pane
+func (m *Model) SetOpener(open func(url string) error) {
+	m.app.opener = open
+}
+
+// SetKeymap replaces the normal-mode key bindings
+func (m *Model) SetKeymap(keys Keymap) {
+	m.app.Keys = keys
+	m.app.SetHints(m.app.LeftPane.Hints != nil)
+}
+
+// SetHints sets whether the panes show key hints in their bottom row
+func (m *Model) SetHints(show bool) {
+	m.app.SetHints(show)
+}
+
+// SetWrap sets whether the right pane wraps long lines
+func (m *Model) SetWrap(wrap bool) {
+	m.app.RightPane.NoWrap = !wrap
+}
+
+// SetScrollbar sets whether the right pane shows a scrollbar
+func (m *Model) SetScrollbar(show bool) {
+	m.app.RightPane.Scrollbar = show
+}
+
+// SetDiffColors sets whether items that look like unified diffs are colored
+func (m *Model) SetDiffColors(enabled bool) {
+	m.app.RightPane.DiffColors = enabled
+}
+
+// SetLoadMore loads the items after the ones given to NewModel a page of
+// pageSize at a time, as the cursor nears the end of the list. total is the
+// number of items in the queue, shown as "N of total loaded".
+func (m *Model) SetLoadMore(load LoadMoreFunc, pageSize, total int) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	items := m.app.Items
+	m.app.Paging = Paging{Load: load, PageSize: pageSize, Total: total, done: len(items) < pageSize || len(items) >= total}
+	m.app.Paging.setPosition(items)
+}
+
+// SetA11y sets whether state changes are announced above the status line and
+// borders drawn in plain ASCII
+func (m *Model) SetA11y(enabled bool) {
+	m.app.A11y = enabled
+}
+
+// SetReadOnly sets whether keys that change items, such as delete, note,
+// and bump, are refused with a message, and the status line says so
+func (m *Model) SetReadOnly(readOnly bool) {
+	m.app.ReadOnly = readOnly
+}
+
+// SetIndicators sets the glyphs of the left pane's indicator column;
+// IndicatorsOff hides it
+func (m *Model) SetIndicators(set IndicatorSet) {
+	m.app.LeftPane.Indicators = set
+}
+
+// SetGroupByDate sets whether the left pane groups items under date headers
+func (m *Model) SetGroupByDate(group bool) {
+	m.app.LeftPane.GroupByDate = group
+}
+
+// SetPreviewDebounce sets how long the cursor must rest on an item before
+// its content is loaded; 0 loads it immediately
+func (m *Model) SetPreviewDebounce(delay time.Duration) {
+	if delay < 0 {
+		delay = 0
+	}
+	m.app.Preview.Delay = delay
+}
+
+// SetIncrementalSearch sets whether the selected item is searched while a
+// pattern is typed, rather than only on Enter
+func (m *Model) SetIncrementalSearch(enabled bool) {
+	m.app.IncSearch.Enabled = enabled
+}
+
+// SetTabWidth sets the columns between tab stops in the content pane
+func (m *Model) SetTabWidth(n int) {
+	if n > 0 {
+		m.app.RightPane.TabWidth = n
+	}
+}
+
+// SetAssumeLatin1 sets whether content that isn't valid UTF-8 is shown as
+// Latin-1 rather than with replacement characters
+func (m *Model) SetAssumeLatin1(enabled bool) {
+	m.app.RightPane.Latin1 = enabled
+}
+
+// SetHScrollStep sets how many columns H and L scroll in nowrap mode
+func (m *Model) SetHScrollStep(n int) {
+	if n > 0 {
+		m.app.RightPane.HScrollStep = n
+	}
+}
+
+// SetSearchHistory sets the search patterns available for recall, oldest first
+func (m *Model) SetSearchHistory(patterns []string) {
+	m.app.Search.History.SetEntries(patterns)
+}
+
+// SearchHistory returns the search patterns executed so far, oldest first
+func (m *Model) SearchHistory() []string {
+	return m.app.Search.History.Entries()
+}
+
+// SetRecentCopies sets the IDs of the items copied most recently, newest
+// first, and how many are kept; a limit of 0 turns the marks off
+func (m *Model) SetRecentCopies(ids []uint, limit int) {
+	limit = max(limit, 0)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	m.app.RecentCopies = RecentCopies{IDs: ids, Limit: limit}
+}
+
+// CopiedIDs returns the IDs of the items copied this session, newest first
+func (m *Model) CopiedIDs() []uint {
+	return m.app.RecentCopies.copied
+}
+
+// SetLocation sets the zone times are shown in; nil is the local zone
+func (m *Model) SetLocation(loc *time.Location) {
+	m.app.Location = loc
+}
+
+// SetTitle sets the heading of the left pane, "Queue" by default
+func (m *Model) SetTitle(title string) {
+	m.app.LeftPane.Title = title
+}
+
+// SetSearchPattern starts the viewer with pattern already searched for, as
+// if it had been entered with /. Each item is searched when it is first
+// shown, starting once the window size is known, so n and N navigate its
+// matches at once.
+func (m *Model) SetSearchPattern(pattern string) error {
+	if _, err := regexp.Compile("(?i)" + pattern); err != nil {
+		return err
+	}
+	m.app.Search.Input = pattern
+	m.app.Search.Pattern = pattern
+	m.app.pendingSearch = true
+	return nil
+}
+
+// SetClipboardMaxBytes sets the largest item size that may be copied to the clipboard
+func (m *Model) SetClipboardMaxBytes(n int64) {
+	m.app.ClipboardMaxBytes = n
+}
+
+// SetClipboardOverwriteConfirm sets whether copying asks before replacing
+// clipboard content rem didn't put there
+func (m *Model) SetClipboardOverwriteConfirm(confirm bool) {
+	m.app.ClipboardOverwriteConfirm = confirm
+}
+
 // UpdateMockSize is a helper method for testing that simulates a window resize
 func (m *Model) UpdateMockSize(width, height int) {
 	// Update legacy fields for compatibility
@@ -504,6 +772,13 @@ func max(a, b int) int {
 	return b
 }
 
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 func wrapText(text string, width int) string {
 	if width <= 0 {
 		return text
@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLeftPaneRightBorderRendering(t *testing.T) {
@@ -181,6 +183,22 @@ func TestStackItem_PerformSearch(t *testing.T) {
 	}
 }
 
+func TestStackItem_PerformSearchNormalized(t *testing.T) {
+	item := &StackItem{Content: NewStringReadSeekCloser("plain\nÜber receipt\nthe ﬁle\n")}
+	if err := item.performSearch("uber"); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	if !slices.Equal(item.SearchMatches, []int{1}) {
+		t.Errorf("Expected \"uber\" to match Über on line 1, got %v", item.SearchMatches)
+	}
+	if err := item.performSearch("file"); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	if !slices.Equal(item.SearchMatches, []int{2}) || item.SearchHits[0].Offset != int64(len("plain\nÜber receipt\nthe ")) {
+		t.Errorf("Expected \"file\" to match the ligature on line 2, got %v %+v", item.SearchMatches, item.SearchHits)
+	}
+}
+
 func TestStackItem_PerformSearchAfterUpdateWrappedLines(t *testing.T) {
 	content := "Line 1 test\nLine 2\nLine 3 test"
 	item := &StackItem{
@@ -208,3 +226,77 @@ func TestStackItem_PerformSearchAfterUpdateWrappedLines(t *testing.T) {
 		t.Errorf("Expected 2 matches after UpdateWrappedLines, got %d", len(item.SearchMatches))
 	}
 }
+
+func TestStackItem_VeryLongSingleLine(t *testing.T) {
+	// A single 2MB line, like a minified JSON file, with a marker deep inside
+	const lineSize = 2 * 1024 * 1024
+	words := strings.Repeat("abcdefg ", lineSize/8)
+	markerAt := len(words) * 3 / 4
+	content := words[:markerAt] + "NEEDLE" + words[markerAt:]
+
+	item := &StackItem{
+		Content: NewStringReadSeekCloser(content),
+		Preview: "minified",
+	}
+
+	const width, height = 80, 20
+	start := time.Now()
+	if err := item.UpdateWrappedLines(width, height); err != nil {
+		t.Fatalf("UpdateWrappedLines failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("UpdateWrappedLines took %v for a single long line", elapsed)
+	}
+
+	// Only the viewport window should be materialized
+	if len(item.Lines) == 0 || len(item.Lines) > height+100 {
+		t.Errorf("Expected a bounded window of wrapped lines, got %d", len(item.Lines))
+	}
+	if _, known := item.knownLineCount(); known {
+		t.Errorf("Expected total line count to be unknown after loading the first window")
+	}
+
+	// Search finds the marker deep in the line
+	if err := item.performSearch("needle"); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	if len(item.SearchHits) != 1 || item.SearchHits[0].Offset != int64(markerAt) || item.SearchHits[0].SourceLine != 0 {
+		t.Fatalf("Expected one hit at offset %d on line 0, got %+v", markerAt, item.SearchHits)
+	}
+	if len(item.SearchMatches) != 1 {
+		t.Fatalf("Expected one matching display line, got %v", item.SearchMatches)
+	}
+
+	// Jumping to the match loads a window around it that shows the marker
+	matchLine := item.SearchMatches[0]
+	model := RightPaneModel{Width: width + 6, Height: height + 6}
+	model.ViewPos = scrollToMatch(model, item, matchLine)
+	if model.ViewPos == 0 {
+		t.Fatalf("Expected to scroll deep into the content, stayed at 0")
+	}
+	item.ViewPos = model.ViewPos
+	if err := item.UpdateWrappedLines(width, height); err != nil {
+		t.Fatalf("UpdateWrappedLines failed: %v", err)
+	}
+	line, ok := item.lineAt(matchLine)
+	if !ok || !strings.Contains(line, "NEEDLE") {
+		t.Errorf("Expected display line %d to contain the match, got %q (loaded %v)", matchLine, line, ok)
+	}
+	if len(item.Lines) > height+100 {
+		t.Errorf("Expected a bounded window after jumping, got %d lines", len(item.Lines))
+	}
+}
+
+func TestStackItem_SearchMatchAcrossSegments(t *testing.T) {
+	// Place a match so that it straddles the boundary between wrap segments
+	prefix := strings.Repeat("a", wrapSegmentBytes-3)
+	content := prefix + "NEEDLE" + strings.Repeat("b", 100)
+
+	item := &StackItem{Content: NewStringReadSeekCloser(content)}
+	if err := item.performSearch("needle"); err != nil {
+		t.Fatalf("performSearch failed: %v", err)
+	}
+	if len(item.SearchHits) != 1 || item.SearchHits[0].Offset != int64(len(prefix)) {
+		t.Errorf("Expected one hit at offset %d, got %+v", len(prefix), item.SearchHits)
+	}
+}
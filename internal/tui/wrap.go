@@ -3,6 +3,7 @@ package tui
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // WrapText wraps text to fit within a given width, breaking on word boundaries when possible.
@@ -38,14 +39,36 @@ func WrapText(text string, maxWidth int) []string {
 
 // wrapLine wraps a single line that is too long, breaking on word boundaries when possible
 func wrapLine(line string, maxWidth int) []string {
+	lines, _ := wrapLineWithOffsets(line, maxWidth)
+	return lines
+}
+
+// wrapWithOffsets wraps a single line (no embedded newlines) the same way
+// WrapText does and also returns the byte offset in line at which each
+// wrapped line begins.
+func wrapWithOffsets(line string, maxWidth int) ([]string, []int) {
+	if maxWidth <= 0 {
+		return []string{}, []int{}
+	}
+	if len(line) <= maxWidth {
+		return []string{line}, []int{0}
+	}
+	return wrapLineWithOffsets(line, maxWidth)
+}
+
+// wrapLineWithOffsets implements wrapLine, additionally reporting the byte
+// offset in line where each wrapped line starts
+func wrapLineWithOffsets(line string, maxWidth int) ([]string, []int) {
 	var result []string
+	var starts []int
 	var currentLine strings.Builder
 	currentWidth := 0
 
-	words := splitWords(line)
+	words, wordStarts := splitWordsWithOffsets(line)
 
 	for i, word := range words {
 		wordLen := len(word)
+		wordStart := wordStarts[i]
 
 		// If word itself is longer than maxWidth, break it forcefully
 		if wordLen > maxWidth {
@@ -57,13 +80,19 @@ func wrapLine(line string, maxWidth int) []string {
 			}
 
 			// Break the long word into chunks
-			for len(word) > 0 {
+			for pos := 0; len(word) > 0; {
 				chunkSize := maxWidth
 				if chunkSize > len(word) {
 					chunkSize = len(word)
 				}
+				// Break between runes, such as control pictures, not inside one
+				for chunkSize > 1 && chunkSize < len(word) && !utf8.RuneStart(word[chunkSize]) {
+					chunkSize--
+				}
 				result = append(result, word[:chunkSize])
+				starts = append(starts, wordStart+pos)
 				word = word[chunkSize:]
+				pos += chunkSize
 			}
 			continue
 		}
@@ -80,12 +109,16 @@ func wrapLine(line string, maxWidth int) []string {
 			currentLine.Reset()
 			currentLine.WriteString(word)
 			currentWidth = wordLen
+			starts = append(starts, wordStart)
 		} else {
 			// Add to current line
 			if currentWidth > 0 && i > 0 {
 				currentLine.WriteString(" ")
 				currentWidth++
 			}
+			if currentWidth == 0 {
+				starts = append(starts, wordStart)
+			}
 			currentLine.WriteString(word)
 			currentWidth += wordLen
 		}
@@ -96,28 +129,38 @@ func wrapLine(line string, maxWidth int) []string {
 		result = append(result, currentLine.String())
 	}
 
-	return result
+	return result, starts
 }
 
 // splitWords splits text into words, preserving spaces as part of word boundaries
 func splitWords(text string) []string {
+	words, _ := splitWordsWithOffsets(text)
+	return words
+}
+
+// splitWordsWithOffsets splits text into words and returns the byte offset
+// at which each word starts
+func splitWordsWithOffsets(text string) ([]string, []int) {
 	var words []string
-	var current strings.Builder
+	var starts []int
+	wordStart := -1
 
-	for _, r := range text {
+	for i, r := range text {
 		if unicode.IsSpace(r) {
-			if current.Len() > 0 {
-				words = append(words, current.String())
-				current.Reset()
+			if wordStart >= 0 {
+				words = append(words, text[wordStart:i])
+				starts = append(starts, wordStart)
+				wordStart = -1
 			}
-		} else {
-			current.WriteRune(r)
+		} else if wordStart < 0 {
+			wordStart = i
 		}
 	}
 
-	if current.Len() > 0 {
-		words = append(words, current.String())
+	if wordStart >= 0 {
+		words = append(words, text[wordStart:])
+		starts = append(starts, wordStart)
 	}
 
-	return words
+	return words, starts
 }
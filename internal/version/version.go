@@ -0,0 +1,46 @@
+// Package version reports how the rem binary was built. Release builds set
+// the variables below with -ldflags "-X github.com/yiblet/rem/internal/version.Version=...".
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags -X; see the release workflow
+var (
+	Version   = "" // release version, such as "v1.4.0"; "dev" when unset
+	Commit    = "" // commit hash; read from the Go build info when unset
+	BuildDate = "" // build time in RFC 3339
+)
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary. Values not set
+// with -ldflags fall back to what the Go toolchain recorded, if anything.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildDate: BuildDate, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	return info
+}
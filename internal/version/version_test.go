@@ -0,0 +1,27 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet_Defaults(t *testing.T) {
+	info := Get()
+	// Test binaries carry no module version, so nothing fills Version in
+	if info.Version != "dev" {
+		t.Errorf("expected version dev when unset, got %q", info.Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("expected go version %q, got %q", runtime.Version(), info.GoVersion)
+	}
+}
+
+func TestGet_LDFlags(t *testing.T) {
+	defer func(version, commit, date string) { Version, Commit, BuildDate = version, commit, date }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.2.3", "abc123", "2024-03-14T12:00:00Z"
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.Commit != "abc123" || info.BuildDate != "2024-03-14T12:00:00Z" {
+		t.Errorf("expected the -ldflags values, got %+v", info)
+	}
+}
@@ -3,18 +3,52 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/alexflint/go-arg"
 	"github.com/yiblet/rem/internal/cli"
 )
 
 func main() {
+	// Report writes to a closed pipe as errors instead of dying from the
+	// signal, so 'rem get 0 | head -1' can exit cleanly
+	signal.Ignore(syscall.SIGPIPE)
+
+	// Put the terminal back after a TUI that didn't exit cleanly; the flag is
+	// hidden from the parser, so it's checked first
+	if cli.WantsResetTerminal(os.Args[1:]) {
+		if err := cli.ExecuteResetTerminal(os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command-line arguments
 	var args cli.Args
-	parser := arg.MustParse(&args)
+	parser := cli.Parse(&args, os.Args[1:])
+
+	// Report versions before opening the database, so bug reports can
+	// include them even when it is broken
+	if args.ShowVersion || args.Version != nil {
+		if err := cli.ExecuteVersion(&args, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(cli.ExitCode(err))
+		}
+		return
+	}
+
+	// Diagnose before opening the database too, since it may be what's broken
+	if args.Doctor != nil {
+		if err := cli.ExecuteDoctor(&args, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(cli.ExitCode(err))
+		}
+		return
+	}
 
 	// If no subcommand provided, show help or launch TUI
-	if args.Store == nil && args.Get == nil && args.Config == nil && args.Clear == nil && args.Search == nil {
+	if !args.HasSubcommand() {
 		// Default behavior: launch TUI (same as 'rem get')
 		args.Get = &cli.GetCmd{}
 	}
@@ -23,18 +57,25 @@ func main() {
 	cliHandler, err := cli.NewWithArgs(&args)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Run 'rem --version' for environment info to include in a bug report.")
 		os.Exit(1)
 	}
 
 	// Execute the command
 	if err := cliHandler.Execute(&args); err != nil {
+		code := cli.ExitCode(err)
+		if code == cli.ExitSuccess || code == cli.ExitInterrupted {
+			// stdout's reader closed it early and has what it wanted, or
+			// an interrupted run has already printed its summary
+			os.Exit(code)
+		}
 		fmt.Printf("Error: %v\n", err)
 
 		// If it's an argument validation error, show usage
-		if args.Store != nil || args.Get != nil || args.Config != nil || args.Clear != nil || args.Search != nil {
+		if args.HasSubcommand() && code == cli.ExitError {
 			fmt.Println()
 			parser.WriteUsage(os.Stderr)
 		}
-		os.Exit(1)
+		os.Exit(code)
 	}
 }